@@ -4,23 +4,36 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/signal"
 	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"rocksdb-cli/internal/api"
+	"rocksdb-cli/internal/audit"
+	"rocksdb-cli/internal/auth"
+	"rocksdb-cli/internal/command"
+	"rocksdb-cli/internal/config"
 	"rocksdb-cli/internal/db"
 	"rocksdb-cli/internal/graphchain"
+	"rocksdb-cli/internal/inspect"
 	"rocksdb-cli/internal/jsonutil"
+	"rocksdb-cli/internal/mcp/client"
+	"rocksdb-cli/internal/mcp/tools"
+	"rocksdb-cli/internal/output"
 	"rocksdb-cli/internal/repl"
+	"rocksdb-cli/internal/schema"
 	"rocksdb-cli/internal/service"
+	"rocksdb-cli/internal/timeseries"
 	"rocksdb-cli/internal/transform"
+	"rocksdb-cli/internal/tui"
 	"rocksdb-cli/internal/util"
 
 	"github.com/spf13/cobra"
@@ -31,7 +44,14 @@ var (
 	dbPath     string
 	readOnly   bool
 	configPath string
+	cfConfig   string
+	ttlMode    string
+	auditPath  string
 	pretty     bool
+	outputName string
+
+	// format is the --output format, parsed before each command runs
+	format = output.Text
 )
 
 // Root command
@@ -60,6 +80,11 @@ QUICK START:
   # Direct commands (good for scripting)
   rocksdb-cli get --db mydb --cf users user:1001
   rocksdb-cli scan --db mydb --cf logs --limit=100
+  rocksdb-cli scan --db mydb --cf logs --limit=100 -o jsonl
+
+  # REPL commands from a script file, stdin (--script -) or the command line
+  rocksdb-cli --db mydb --script checks.rdb
+  rocksdb-cli --db mydb -e "usecf users; get user:1001"
 
   # AI-powered queries
   rocksdb-cli ai --db mydb "show me all active users"
@@ -72,6 +97,84 @@ REQUIREMENTS:
   • RocksDB database file path
 
 TIP: Use --read-only flag to safely explore production databases`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		var err error
+		format, err = output.ParseFormat(outputName)
+		if err != nil {
+			return err
+		}
+		if needsDB(cmd) && dbPath == "" {
+			return errDBRequired
+		}
+		return nil
+	},
+	Run: runScript,
+}
+
+// errDBRequired is returned when --db is missing, in the words cobra uses
+// for required flags
+var errDBRequired = errors.New(`required flag(s) "db" not set`)
+
+// needsDB reports whether cmd opens the database given with --db. The root
+// command checks it in runScript, so that it shows the help without flags,
+// audit only needs it for a log kept in a column family, and cobra's help
+// and completion commands never need it.
+func needsDB(cmd *cobra.Command) bool {
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		if !c.Parent().HasParent() {
+			return c != auditCmd && c.Name() != "help" && c.Name() != "completion"
+		}
+	}
+	return false
+}
+
+// runScript runs the REPL commands given with --script or -e and exits with
+// a non-zero code if one of them failed. Without either it shows the help.
+func runScript(cmd *cobra.Command, args []string) {
+	script, _ := cmd.Flags().GetString("script")
+	execute, _ := cmd.Flags().GetString("execute")
+	if script == "" && execute == "" {
+		cmd.Help()
+		return
+	}
+	if script != "" && execute != "" {
+		fmt.Fprintln(os.Stderr, "Error: --script and -e cannot be used together")
+		os.Exit(1)
+	}
+	if dbPath == "" {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", errDBRequired)
+		os.Exit(1)
+	}
+
+	var r io.Reader
+	name := "-e"
+	switch {
+	case execute != "":
+		r = strings.NewReader(strings.Join(command.SplitCommands(execute), "\n"))
+	case script == "-":
+		r, name = os.Stdin, "stdin"
+	default:
+		f, err := os.Open(script)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		r, name = f, script
+	}
+
+	rdb := openDatabaseFor(audit.InterfaceREPL, map[string]string{"script": name})
+	ctx, cancel := context.WithCancel(context.Background())
+	opts, shutdown := startREPLTools(ctx)
+	opts.Output = format
+
+	code := repl.RunScript(rdb, opts, r, name)
+
+	// os.Exit skips deferred calls
+	shutdown()
+	cancel()
+	rdb.Close()
+	os.Exit(code)
 }
 
 // REPL command - maintains existing interactive experience
@@ -80,11 +183,47 @@ var replCmd = &cobra.Command{
 	Short: "Start interactive REPL mode",
 	Long:  `Start an interactive Read-Eval-Print Loop for database operations`,
 	Run: func(cmd *cobra.Command, args []string) {
-		rdb := openDatabase()
+		rdb := openDatabaseFor(audit.InterfaceREPL, nil)
 		defer rdb.Close()
 
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		opts, shutdown := startREPLTools(ctx)
+		defer shutdown()
+		if noSession, _ := cmd.Flags().GetBool("no-session"); !noSession {
+			opts.DBPath = dbPath
+			opts.SessionDir, _ = cmd.Flags().GetString("session-dir")
+		}
+		// An explicit --output overrides the format saved in the session
+		if cmd.Flags().Changed("output") {
+			opts.Output = format
+		}
+
 		// Use existing REPL functionality
-		repl.Start(rdb)
+		repl.Start(rdb, opts)
+	},
+}
+
+// TUI command - full-screen browser
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Browse the database in a full-screen terminal UI",
+	Long: `Browse column families, keys and values in a full-screen terminal UI.
+
+Keys are loaded page by page as you scroll, / and ? search keys and values as
+you type, x shows a value as hex, and e and a edit values unless the database
+is opened with --read-only.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rdb := openDatabaseFor(audit.InterfaceTUI, nil)
+		defer rdb.Close()
+
+		cf, _ := cmd.Flags().GetString("cf")
+		interval, _ := cmd.Flags().GetDuration("stats-interval")
+		if err := tui.Run(rdb, tui.Options{CF: cf, StatsInterval: interval}); err != nil {
+			fmt.Printf("TUI failed: %v\n", err)
+			rdb.Close()
+			os.Exit(1)
+		}
 	},
 }
 
@@ -102,14 +241,25 @@ var getCmd = &cobra.Command{
 
 		// Use DatabaseService instead of direct DB access
 		dbService := service.NewDatabaseService(rdb)
-		value, err := dbService.GetValue(cf, key)
+		value, ttl, err := dbService.GetValueWithTTL(cf, key)
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
 
+		if structured() {
+			kv := output.Entry(key, value)
+			kv.TTL = ttl
+			writeResult(output.Value(kv))
+			return
+		}
+
 		fmt.Printf("Key: %s\n", util.FormatKey(key))
 		fmt.Printf("Value: %s\n", formatValue(value, pretty))
+		if ttl != nil {
+			fmt.Printf("Written: %s\n", ttl.WriteTime.Format(time.RFC3339))
+			fmt.Printf("TTL: %s\n", ttl)
+		}
 	},
 }
 
@@ -124,10 +274,36 @@ var putCmd = &cobra.Command{
 
 		cf := getColumnFamily(cmd)
 		key, value := args[0], args[1]
+		hexValue, _ := cmd.Flags().GetBool("hex")
+		base64Value, _ := cmd.Flags().GetBool("base64")
+
+		encoding := ""
+		switch {
+		case hexValue && base64Value:
+			fmt.Println("Error: use only one of --hex and --base64")
+			os.Exit(1)
+		case hexValue:
+			encoding = "hex"
+		case base64Value:
+			encoding = "base64"
+		}
+		value, err := util.DecodeInput(value, encoding)
+		if err != nil {
+			fmt.Printf("Error: invalid %s value: %v\n", encoding, err)
+			os.Exit(1)
+		}
+
+		var ttl time.Duration
+		if ttlFlag, _ := cmd.Flags().GetString("ttl"); ttlFlag != "" {
+			if ttl, err = db.ParseTTLDuration(ttlFlag); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
 
 		// Use DatabaseService instead of direct DB access
 		dbService := service.NewDatabaseService(rdb)
-		err := dbService.PutValue(cf, key, value)
+		err = dbService.PutValueWithTTL(cf, key, value, ttl)
 		if err != nil {
 			if err == db.ErrReadOnlyMode {
 				fmt.Println("Error: Database is in read-only mode")
@@ -137,10 +313,94 @@ var putCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		if structured() {
+			writeResult(output.Done(output.Status{Operation: "put", ColumnFamily: cf, Key: key}))
+			return
+		}
+		if encoding != "" {
+			// Show decoded binary values the way scan does
+			value, _ = util.EncodeValue([]byte(value))
+		}
 		fmt.Printf("Successfully put: %s = %s\n", util.FormatKey(key), value)
 	},
 }
 
+// Inspect command
+var inspectCmd = &cobra.Command{
+	Use:   "inspect <key>",
+	Short: "Show a hex dump of a value with candidate decodings",
+	Long: `Show the value of a key as a hex dump with offsets, followed by the ways the
+bytes could be read: UTF-8 strings, embedded JSON, protobuf fields guessed from
+the wire format, .NET ticks, varints and fixed-width integers in both byte
+orders. Sections with nothing plausible are left out.
+
+EXAMPLES:
+  rocksdb-cli inspect --db mydb --cf events 1001
+  rocksdb-cli inspect --db mydb --cf events 1001 --output json
+  rocksdb-cli put --db mydb --cf events 1002 0896011205 --hex`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rdb := openDatabase()
+		defer rdb.Close()
+
+		cf := getColumnFamily(cmd)
+		key := args[0]
+
+		dbService := service.NewDatabaseService(rdb)
+		value, err := dbService.GetValue(cf, key)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		report := inspect.Inspect([]byte(value))
+		if structured() {
+			result := output.Object(report)
+			result.Raw = report.Hexdump
+			writeResult(result)
+			return
+		}
+		fmt.Printf("Key: %s\n", util.FormatKey(key))
+		report.WriteText(os.Stdout)
+	},
+}
+
+// Merge command
+var mergeCmd = &cobra.Command{
+	Use:   "merge <key> <operand>",
+	Short: "Apply a merge operand using the column family's merge operator",
+	Long: `Apply a merge operand to a key using the merge operator configured for the
+column family (see --cf-config and 'cfoptions --plugins').
+
+EXAMPLES:
+  rocksdb-cli merge --db mydb --cf-config cf.yaml --cf counters hits 1
+  rocksdb-cli merge --db mydb --cf-config cf.yaml --cf docs user:1 '{"age":31}'`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		rdb := openDatabase()
+		defer rdb.Close()
+
+		cf := getColumnFamily(cmd)
+		key, operand := args[0], args[1]
+
+		dbService := service.NewDatabaseService(rdb)
+		if err := dbService.MergeValue(cf, key, operand); err != nil {
+			if err == db.ErrReadOnlyMode {
+				fmt.Println("Error: Database is in read-only mode")
+			} else {
+				fmt.Printf("Error: %v\n", err)
+			}
+			os.Exit(1)
+		}
+
+		if structured() {
+			writeResult(output.Done(output.Status{Operation: "merge", ColumnFamily: cf, Key: key}))
+			return
+		}
+		fmt.Printf("Successfully merged: %s <- %s\n", util.FormatKey(key), operand)
+	},
+}
+
 // Last command
 var lastCmd = &cobra.Command{
 	Use:   "last",
@@ -159,6 +419,10 @@ var lastCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		if structured() {
+			writeResult(output.Value(output.Entry(key, value)))
+			return
+		}
 		fmt.Printf("Last entry in '%s': %s = %s\n", cf, util.FormatKey(key), formatValue(value, pretty))
 	},
 }
@@ -280,6 +544,10 @@ var exportCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		if structured() {
+			writeResult(output.Done(output.Status{Operation: "export", ColumnFamily: cf, File: filePath}))
+			return
+		}
 		fmt.Printf("Exported column family '%s' to %s (sep=%q)\n", cf, filePath, sep)
 	},
 }
@@ -295,8 +563,13 @@ var watchCmd = &cobra.Command{
 		cf := getColumnFamily(cmd)
 		interval, _ := cmd.Flags().GetDuration("interval")
 
-		fmt.Printf("Watching column family '%s' for new entries (interval: %v)...\n", cf, interval)
-		fmt.Println("Press Ctrl+C to stop")
+		// Keep stdout to the entries in an output format
+		status := os.Stdout
+		if structured() {
+			status = os.Stderr
+		}
+		fmt.Fprintf(status, "Watching column family '%s' for new entries (interval: %v)...\n", cf, interval)
+		fmt.Fprintln(status, "Press Ctrl+C to stop")
 
 		// Set up signal handling for graceful shutdown
 		c := make(chan os.Signal, 1)
@@ -317,7 +590,11 @@ var watchCmd = &cobra.Command{
 		} else {
 			lastKey = key
 			lastValue = value
-			fmt.Printf("[%s] Initial: %s = %s\n", time.Now().Format("15:04:05"), util.FormatKey(key), value)
+			if structured() {
+				writeResult(output.Value(output.Entry(key, value)))
+			} else {
+				fmt.Printf("[%s] Initial: %s = %s\n", time.Now().Format("15:04:05"), util.FormatKey(key), value)
+			}
 		}
 
 		ticker := time.NewTicker(interval)
@@ -326,7 +603,7 @@ var watchCmd = &cobra.Command{
 		for {
 			select {
 			case <-c:
-				fmt.Println("\nStopping watch...")
+				fmt.Fprintln(status, "\nStopping watch...")
 				return
 			case <-ticker.C:
 				key, value, err := rdb.GetLastCF(cf)
@@ -339,7 +616,11 @@ var watchCmd = &cobra.Command{
 				}
 
 				if key != lastKey || value != lastValue {
-					fmt.Printf("[%s] New: %s = %s\n", time.Now().Format("15:04:05"), util.FormatKey(key), value)
+					if structured() {
+						writeResult(output.Value(output.Entry(key, value)))
+					} else {
+						fmt.Printf("[%s] New: %s = %s\n", time.Now().Format("15:04:05"), util.FormatKey(key), value)
+					}
 					lastKey = key
 					lastValue = value
 				}
@@ -348,6 +629,290 @@ var watchCmd = &cobra.Command{
 	},
 }
 
+// Range command
+var rangeCmd = &cobra.Command{
+	Use:   "range",
+	Short: "Show the entries of a time-keyed column family between two times",
+	Long: `Show the entries of a column family whose keys start with a time, between
+--from and --to (excluded). Keys may start with .NET ticks, Unix s/ms/us/ns
+timestamps (decimal text or 8-byte big-endian) or ISO 8601 text; the encoding
+is detected from the keys unless --encoding is set.
+
+Times are RFC 3339, 2006-01-02T15:04 (UTC), dates, now or ages like 1h or 7d.`,
+	Example: `  rocksdb-cli range --db mydb --cf events --from 2024-03-01T10:00 --to 2024-03-01T11:00
+  rocksdb-cli range --db mydb --cf events --from 1h --output jsonl
+  rocksdb-cli range --db mydb --cf events --prefix "sensor1:" --from 7d --limit 20`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rdb := openDatabase()
+		defer rdb.Close()
+
+		cf := getColumnFamily(cmd)
+		q := timeQuery(cmd, rdb, cf)
+		limit, _ := cmd.Flags().GetInt("limit")
+		reverse, _ := cmd.Flags().GetBool("reverse")
+		keysOnly, _ := cmd.Flags().GetBool("keys-only")
+		after, _ := cmd.Flags().GetString("after")
+
+		page, err := timeseries.Range(rdb, q, timeseries.Options{Limit: limit, Reverse: reverse, Values: !keysOnly, After: after})
+		if err != nil {
+			fmt.Printf("Range failed: %v\n", err)
+			os.Exit(1)
+		}
+		if structured() {
+			writeResult(output.Series(page, !keysOnly))
+			return
+		}
+		fmt.Printf("%d entries of '%s' (keys: %s)\n", len(page.Entries), cf, q.Codec)
+		printTimeEntries(page.Entries, !keysOnly)
+		if page.HasMore {
+			fmt.Printf("\nMore entries: add --after %s\n", page.NextCursor)
+		}
+	},
+}
+
+// Latest command
+var latestCmd = &cobra.Command{
+	Use:   "latest [N]",
+	Short: "Show the N newest entries of a time-keyed column family",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rdb := openDatabase()
+		defer rdb.Close()
+
+		cf := getColumnFamily(cmd)
+		n := latestArg(args)
+		q := timeQuery(cmd, rdb, cf)
+		keysOnly, _ := cmd.Flags().GetBool("keys-only")
+
+		page, err := timeseries.Latest(rdb, q, n, !keysOnly)
+		if err != nil {
+			fmt.Printf("Latest failed: %v\n", err)
+			os.Exit(1)
+		}
+		if structured() {
+			writeResult(output.Series(page, !keysOnly))
+			return
+		}
+		printTimeEntries(page.Entries, !keysOnly)
+	},
+}
+
+// Tail command
+var tailCmd = &cobra.Command{
+	Use:   "tail [N]",
+	Short: "Show the N newest entries of a time-keyed column family, oldest first, and follow new ones with -f",
+	Args:  cobra.MaximumNArgs(1),
+	Example: `  rocksdb-cli tail --db mydb --cf events 20
+  rocksdb-cli tail --db mydb --cf events -f --interval 500ms --output jsonl`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rdb := openDatabase()
+		defer rdb.Close()
+
+		cf := getColumnFamily(cmd)
+		n := latestArg(args)
+		q := timeQuery(cmd, rdb, cf)
+		keysOnly, _ := cmd.Flags().GetBool("keys-only")
+		follow, _ := cmd.Flags().GetBool("follow")
+		interval, _ := cmd.Flags().GetDuration("interval")
+
+		page, err := timeseries.Latest(rdb, q, n, !keysOnly)
+		if err != nil {
+			fmt.Printf("Tail failed: %v\n", err)
+			os.Exit(1)
+		}
+		for i, j := 0, len(page.Entries)-1; i < j; i, j = i+1, j-1 {
+			page.Entries[i], page.Entries[j] = page.Entries[j], page.Entries[i]
+		}
+		if structured() {
+			writeResult(output.Series(page, !keysOnly))
+		} else {
+			printTimeEntries(page.Entries, !keysOnly)
+		}
+		if !follow {
+			return
+		}
+
+		// Keep stdout to the entries in an output format
+		status := os.Stdout
+		if structured() {
+			status = os.Stderr
+		}
+		fmt.Fprintf(status, "Following '%s' every %v, press Ctrl+C to stop\n", cf, interval)
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		err = timeseries.Follow(ctx, rdb, q, "", interval, !keysOnly, func(kv db.KeyValue) error {
+			if structured() {
+				writeResult(output.Series(&timeseries.Page{Codec: q.Codec, Entries: []db.KeyValue{kv}}, !keysOnly))
+			} else {
+				printTimeEntries([]db.KeyValue{kv}, !keysOnly)
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("Tail failed: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// Histogram command
+var histogramCmd = &cobra.Command{
+	Use:   "histogram",
+	Short: "Count the entries of a time-keyed column family per time bucket",
+	Example: `  rocksdb-cli histogram --db mydb --cf events --bucket 15m --from 24h
+  rocksdb-cli histogram --db mydb --cf events --bucket 1d --output csv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rdb := openDatabase()
+		defer rdb.Close()
+
+		cf := getColumnFamily(cmd)
+		bucketFlag, _ := cmd.Flags().GetString("bucket")
+		bucket, err := timeseries.ParseBucket(bucketFlag)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		q := timeQuery(cmd, rdb, cf)
+
+		hist, err := timeseries.NewHistogram(rdb, q, bucket, nil)
+		if err != nil {
+			fmt.Printf("Histogram failed: %v\n", err)
+			os.Exit(1)
+		}
+		if structured() {
+			writeResult(output.Histogram(hist))
+			return
+		}
+		fmt.Printf("%d entries of '%s' in %s buckets (keys: %s)\n", hist.Total, cf, bucket, q.Codec)
+		var most int64
+		for _, b := range hist.Buckets {
+			most = max(most, b.Count)
+		}
+		for _, b := range hist.Buckets {
+			bar := ""
+			if most > 0 {
+				bar = strings.Repeat("█", int((b.Count*40+most-1)/most))
+			}
+			fmt.Printf("%s  %-40s %d\n", b.Start.Format(time.RFC3339), bar, b.Count)
+		}
+	},
+}
+
+// Schema commands
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Infer a JSON Schema from the JSON values of a column family, or check values against one",
+}
+
+var schemaInferCmd = &cobra.Command{
+	Use:   "infer",
+	Short: "Infer a JSON Schema from a sample of the JSON values of a column family",
+	Long: `Merge a uniform sample of the JSON values of a column family into one JSON Schema
+with the share of the values having each field (x-presence), the types seen at
+each path, enum candidates for fields repeating few strings and example values.
+Save it with --save to check values against it later with schema check.`,
+	Example: `  rocksdb-cli schema infer --db mydb --cf users --prefix user: --save users.schema.json
+  rocksdb-cli schema infer --db mydb --cf users --sample 5000 --output json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rdb := openDatabase()
+		defer rdb.Close()
+
+		cf := getColumnFamily(cmd)
+		prefix, _ := cmd.Flags().GetString("prefix")
+		sample, _ := cmd.Flags().GetInt("sample")
+		save, _ := cmd.Flags().GetString("save")
+
+		s, _, err := schema.InferCF(rdb, cf, prefix, sample, nil)
+		if err != nil {
+			fmt.Printf("Schema inference failed: %v\n", err)
+			os.Exit(1)
+		}
+		if save != "" {
+			data, _ := json.MarshalIndent(s, "", "  ")
+			if err := os.WriteFile(save, append(data, '\n'), 0o644); err != nil {
+				fmt.Printf("Error saving schema: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if structured() {
+			writeResult(output.Schema(s))
+			if save != "" {
+				fmt.Fprintf(os.Stderr, "Saved schema to %s\n", save)
+			}
+			return
+		}
+		fmt.Printf("Schema of '%s' from %d of %d JSON values (%d keys)\n", cf, s.Source.Sampled, s.Source.JSONValues, s.Source.Keys)
+		output.Write(os.Stdout, output.Table, output.Schema(s))
+		if save != "" {
+			fmt.Printf("Saved schema to %s\n", save)
+		}
+	},
+}
+
+var schemaCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the JSON values of a column family against a saved schema",
+	Long: `List the values that violate a schema saved by schema infer, with the path and
+the reason, and the fields of the values that the schema does not know. The
+column family and prefix default to the ones the schema was inferred from.
+Exits with status 1 when a value violates the schema.`,
+	Example: `  rocksdb-cli schema check --db mydb --schema users.schema.json
+  rocksdb-cli schema check --db mydb --schema users.schema.json --cf users_v2 --output csv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		file, _ := cmd.Flags().GetString("schema")
+		if file == "" {
+			fmt.Println("Error: --schema is required")
+			os.Exit(1)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Printf("Error reading schema: %v\n", err)
+			os.Exit(1)
+		}
+		s, err := schema.Parse(data)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		cf, prefix := "default", ""
+		if s.Source != nil {
+			cf, prefix = s.Source.ColumnFamily, s.Source.Prefix
+		}
+		if cmd.Flags().Changed("cf") {
+			cf, _ = cmd.Flags().GetString("cf")
+		}
+		if cmd.Flags().Changed("prefix") {
+			prefix, _ = cmd.Flags().GetString("prefix")
+		}
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		rdb := openDatabase()
+		defer rdb.Close()
+
+		c := schema.NewChecker(s, limit)
+		if err := schema.CheckCF(rdb, cf, prefix, c, nil); err != nil {
+			fmt.Printf("Schema check failed: %v\n", err)
+			os.Exit(1)
+		}
+		report := c.Report()
+		if structured() {
+			writeResult(output.SchemaReport(report))
+		} else {
+			fmt.Printf("Checked %d values of '%s': %d violate the schema (%d violations), %d new fields\n",
+				report.Checked, cf, report.Violating, report.ViolationCount, len(report.NewFields))
+			if len(report.Violations) > 0 || len(report.NewFields) > 0 {
+				output.Write(os.Stdout, output.Table, output.SchemaReport(report))
+			}
+			if n := int64(len(report.Violations)); report.ViolationCount > n {
+				fmt.Printf("Showing the first %d violations, raise --limit to see more\n", n)
+			}
+		}
+		if report.Violating > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
 // Stats command
 var statsCmd = &cobra.Command{
 	Use:   "stats",
@@ -357,10 +922,49 @@ var statsCmd = &cobra.Command{
 		defer rdb.Close()
 
 		cf, _ := cmd.Flags().GetString("cf")
+		ttlView, _ := cmd.Flags().GetBool("ttl")
 
 		// Use StatsService instead of direct DB access
 		statsService := service.NewStatsService(rdb)
 
+		if ttlView {
+			if cf == "" {
+				cf = "default"
+			}
+			stats, err := statsService.GetTTLStats(cf)
+			if err != nil {
+				fmt.Printf("Failed to get TTL stats for column family '%s': %v\n", cf, err)
+				os.Exit(1)
+			}
+			if structured() {
+				writeResult(output.Object(stats))
+				return
+			}
+			data, _ := json.MarshalIndent(stats, "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+
+		if structured() {
+			// The schema of the db package, shared with the REPL
+			if cf == "" {
+				stats, err := rdb.GetDatabaseStats()
+				if err != nil {
+					fmt.Printf("Failed to get database stats: %v\n", err)
+					os.Exit(1)
+				}
+				writeResult(output.DatabaseStats(stats))
+			} else {
+				stats, err := rdb.GetCFStats(cf)
+				if err != nil {
+					fmt.Printf("Failed to get stats for column family '%s': %v\n", cf, err)
+					os.Exit(1)
+				}
+				writeResult(output.Object(stats))
+			}
+			return
+		}
+
 		if cf == "" {
 			// Database-wide stats
 			stats, err := statsService.GetDatabaseStats()
@@ -425,8 +1029,12 @@ var keyformatCmd = &cobra.Command{
 
 		cf := getColumnFamily(cmd)
 
-		format, examples := rdb.GetKeyFormatInfo(cf)
-		fmt.Printf("Column family '%s' key format: %v\n", cf, format)
+		keyFormat, examples := rdb.GetKeyFormatInfo(cf)
+		if structured() {
+			writeResult(output.Object(output.NewKeyFormat(cf, keyFormat, examples)))
+			return
+		}
+		fmt.Printf("Column family '%s' key format: %v\n", cf, keyFormat)
 		fmt.Printf("Examples: %s\n", examples)
 	},
 }
@@ -456,6 +1064,10 @@ var jsonqueryCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		if structured() {
+			writeResult(output.Entries(db.ScanPageResult{ResultsV2: output.EntriesOf(result.Data)}, true))
+			return
+		}
 		if result.Count == 0 {
 			fmt.Printf("No entries found in '%s' where field '%s' = '%s'\n", cf, field, value)
 		} else {
@@ -484,6 +1096,10 @@ var listcfCmd = &cobra.Command{
 		}
 
 		sort.Strings(cfs)
+		if structured() {
+			writeResult(output.List("name", cfs))
+			return
+		}
 		fmt.Printf("Column families (%d):\n", len(cfs))
 		for i, cf := range cfs {
 			fmt.Printf("  [%d] %s\n", i+1, cf)
@@ -506,13 +1122,73 @@ var createcfCmd = &cobra.Command{
 		}
 
 		cfName := args[0]
-		err := rdb.CreateCF(cfName)
+		profile, _ := cmd.Flags().GetString("profile")
+		var err error
+		if profile != "" {
+			err = rdb.CreateCFWithProfile(cfName, profile)
+		} else {
+			err = rdb.CreateCF(cfName)
+		}
 		if err != nil {
 			fmt.Printf("Failed to create column family '%s': %v\n", cfName, err)
 			os.Exit(1)
 		}
 
-		fmt.Printf("Successfully created column family '%s'\n", cfName)
+		if structured() {
+			writeResult(output.Done(output.Status{Operation: "createcf", ColumnFamily: cfName}))
+			return
+		}
+		if profile != "" {
+			fmt.Printf("Successfully created column family '%s' with profile '%s'\n", cfName, profile)
+		} else {
+			fmt.Printf("Successfully created column family '%s'\n", cfName)
+		}
+	},
+}
+
+// Column family options command
+var cfoptionsCmd = &cobra.Command{
+	Use:   "cfoptions",
+	Short: "Show column family options loaded from the OPTIONS file",
+	Run: func(cmd *cobra.Command, args []string) {
+		if listProfiles, _ := cmd.Flags().GetBool("profiles"); listProfiles {
+			for _, p := range db.CFProfiles() {
+				fmt.Printf("%-14s %s\n", p.Name, p.Description)
+			}
+			return
+		}
+		if listPlugins, _ := cmd.Flags().GetBool("plugins"); listPlugins {
+			command.FormatPlugins(db.Comparators(), db.MergeOperators())
+			return
+		}
+
+		rdb := openDatabase()
+		defer rdb.Close()
+
+		cf := getColumnFamily(cmd)
+		opts, err := rdb.GetCFOptions(cf)
+		if err != nil {
+			fmt.Printf("Failed to get options for column family '%s': %v\n", cf, err)
+			os.Exit(1)
+		}
+
+		var mismatches []db.OptionMismatch
+		for _, m := range rdb.OptionMismatches() {
+			if m.ColumnFamily == cf || m.ColumnFamily == "*" {
+				mismatches = append(mismatches, m)
+			}
+		}
+
+		result := map[string]interface{}{
+			"options":    opts,
+			"mismatches": mismatches,
+		}
+		if structured() {
+			writeResult(output.Object(result))
+			return
+		}
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
 	},
 }
 
@@ -537,6 +1213,10 @@ var dropcfCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		if structured() {
+			writeResult(output.Done(output.Status{Operation: "dropcf", ColumnFamily: cfName}))
+			return
+		}
 		fmt.Printf("Successfully dropped column family '%s'\n", cfName)
 	},
 }
@@ -617,10 +1297,6 @@ CONTEXT VARIABLES (available in expressions):
   • key    - The entry's key (string)
   • value  - The entry's value (string)`,
 	Run: func(cmd *cobra.Command, args []string) {
-		// Open database
-		rdb := openDatabase()
-		defer rdb.Close()
-		
 		// Get flags
 		cf, _ := cmd.Flags().GetString("cf")
 		expr, _ := cmd.Flags().GetString("expr")
@@ -632,6 +1308,16 @@ CONTEXT VARIABLES (available in expressions):
 		limit, _ := cmd.Flags().GetInt("limit")
 		batchSize, _ := cmd.Flags().GetInt("batch-size")
 		verbose, _ := cmd.Flags().GetBool("verbose")
+
+		// Open database, recording the transformation with every audited write
+		details := map[string]string{}
+		for name, v := range map[string]string{"expr": expr, "key_expr": keyExpr, "value_expr": valueExpr, "filter": filterExpr, "script": scriptPath} {
+			if v != "" {
+				details[name] = v
+			}
+		}
+		rdb := openDatabaseFor(audit.InterfaceTransform, details)
+		defer rdb.Close()
 		
 		// Create transform options
 		opts := transform.TransformOptions{
@@ -650,17 +1336,23 @@ CONTEXT VARIABLES (available in expressions):
 		processor := transform.NewTransformProcessor(rdb)
 		
 		// Execute transformation
-		fmt.Printf("Transforming column family '%s'...\n", cf)
-		if dryRun {
-			fmt.Println("(DRY RUN - no changes will be made)")
+		if !structured() {
+			fmt.Printf("Transforming column family '%s'...\n", cf)
+			if dryRun {
+				fmt.Println("(DRY RUN - no changes will be made)")
+			}
+			fmt.Println()
 		}
-		fmt.Println()
 		
 		result, err := processor.Process(cf, opts)
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
+		if structured() {
+			writeResult(output.Transform(service.NewTransformResult(result)))
+			return
+		}
 		
 		// Display results
 		fmt.Printf("Transform completed in %v\n", result.Duration)
@@ -736,6 +1428,12 @@ QUICK START:
   # Read-only mode (recommended for production)
   rocksdb-cli web --db mydb --read-only
 
+  # Require API tokens, basic auth or OIDC JWTs with per-user roles
+  rocksdb-cli web --db mydb --auth-config auth.yaml
+
+  # Record writes made through the API
+  rocksdb-cli web --db mydb --audit-log audit.log
+
   Then open http://localhost:8080 in your browser
 
 ENDPOINTS:
@@ -743,13 +1441,42 @@ ENDPOINTS:
   /api/v1/health   - Health check
   /api/v1/cf       - List column families
   /api/v1/stats    - Database statistics
+  /api/v1/audit    - Audit log (admin)
   And more...`,
 	Run: func(cmd *cobra.Command, args []string) {
 		port, _ := cmd.Flags().GetString("port")
 		enableAI, _ := cmd.Flags().GetBool("enable-ai")
+		authConfig, _ := cmd.Flags().GetString("auth-config")
+
+		// Load authentication and role configuration
+		var routerOpts []api.Option
+		if authConfig != "" {
+			authManager, err := auth.NewFromFile(authConfig)
+			if err != nil {
+				log.Fatalf("Failed to load auth config: %v", err)
+			}
+			routerOpts = append(routerOpts, api.WithAuth(authManager))
+		}
+
+		// The web UI connects read-only, so the audit log must be a file
+		auditCfg, err := loadAuditConfig()
+		if err != nil {
+			log.Fatalf("Failed to load audit config: %v", err)
+		}
+		if auditCfg.Enabled() {
+			if auditCfg.ColumnFamily != "" {
+				log.Fatalf("The web server supports only file audit logs")
+			}
+			auditLog, err := audit.New(*auditCfg, nil)
+			if err != nil {
+				log.Fatalf("Failed to open audit log: %v", err)
+			}
+			defer auditLog.Close()
+			routerOpts = append(routerOpts, api.WithAuditLog(auditLog))
+		}
 
 		// Create DBManager for dynamic database management
-		dbManager := service.NewDBManager()
+		dbManager := service.NewDBManagerWithConfig(openConfig())
 
 		// Auto-connect to the database specified by flags
 		fmt.Printf("Connecting to database: %s (read-only mode enforced)\n", dbPath)
@@ -760,13 +1487,14 @@ ENDPOINTS:
 		fmt.Printf("✅ Database connected successfully (%d column families)\n", dbInfo.CFCount)
 
 		// Setup router with DBManager
-		router := api.SetupRouterWithUI(dbManager)
+		router := api.SetupRouterWithUI(dbManager, routerOpts...)
 
 		addr := ":" + port
 		fmt.Printf("\nRocksDB Web UI Server starting...\n")
 		fmt.Printf("   Database: %s\n", dbPath)
 		fmt.Printf("   Read-only: true (enforced)\n")
 		fmt.Printf("   AI Enabled: %v\n", enableAI)
+		fmt.Printf("   Auth: %v\n", authConfig != "")
 		fmt.Printf("   URL: http://localhost%s\n", addr)
 		fmt.Printf("\nOpen http://localhost%s in your browser\n\n", addr)
 
@@ -782,25 +1510,253 @@ ENDPOINTS:
 	},
 }
 
+// Audit command - query the audit log
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Show the audit log of mutating operations",
+	Long: `Show recorded put, merge, createcf and dropcf operations from every
+interface (cli, repl, tui, rest, mcp, ai, transform), most recent last.
+
+The log is read from --audit-log, or from the audit section of the --cf-config file.
+--db is only needed when the log is kept in a column family of the database.
+
+EXAMPLES:
+  rocksdb-cli audit --audit-log audit.log --since 24h
+  rocksdb-cli audit --audit-log audit.log --actor alice --op put --cf users
+  rocksdb-cli audit --db mydb --cf-config rocksdb-cli.yaml --result error --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadAuditConfig()
+		if err != nil {
+			fmt.Printf("Failed to load audit config: %v\n", err)
+			os.Exit(1)
+		}
+		if !cfg.Enabled() {
+			fmt.Println("Error: no audit log configured (use --audit-log or an audit section in --cf-config)")
+			os.Exit(1)
+		}
+
+		filter, err := auditFilterFromFlags(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		var store audit.Store
+		if cfg.ColumnFamily != "" {
+			if dbPath == "" {
+				fmt.Printf("Error: the audit log is kept in column family '%s', which needs --db\n", cfg.ColumnFamily)
+				os.Exit(1)
+			}
+			rdb := openRawDatabase()
+			defer rdb.Close()
+			store = service.AuditStore(rdb)
+		}
+		auditLog, err := audit.New(*cfg, store)
+		if err != nil {
+			fmt.Printf("Failed to open audit log: %v\n", err)
+			os.Exit(1)
+		}
+		defer auditLog.Close()
+
+		events, err := auditLog.Query(filter)
+		if err != nil {
+			fmt.Printf("Failed to read audit log: %v\n", err)
+			os.Exit(1)
+		}
+
+		if structured() {
+			writeResult(auditResult(events))
+			return
+		}
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			for i := range events {
+				enc.Encode(&events[i])
+			}
+			return
+		}
+		if len(events) == 0 {
+			fmt.Println("No audit events found")
+			return
+		}
+		for _, e := range events {
+			line := fmt.Sprintf("%s  %-10s %-9s %-8s %s", e.Time.Local().Format("2006-01-02 15:04:05"), e.Actor, e.Interface, e.Operation, e.CF)
+			if e.Key != "" {
+				line += "/" + e.Key
+			}
+			if e.Result == audit.ResultError {
+				line += "  ERROR: " + e.Error
+			}
+			fmt.Println(line)
+		}
+	},
+}
+
+func auditFilterFromFlags(cmd *cobra.Command) (audit.Filter, error) {
+	var f audit.Filter
+	f.Actor, _ = cmd.Flags().GetString("actor")
+	iface, _ := cmd.Flags().GetString("interface")
+	f.Interface = audit.Interface(iface)
+	f.Operation, _ = cmd.Flags().GetString("op")
+	f.CF, _ = cmd.Flags().GetString("cf")
+	f.Key, _ = cmd.Flags().GetString("key")
+	f.Result, _ = cmd.Flags().GetString("result")
+	f.Limit, _ = cmd.Flags().GetInt("limit")
+
+	now := time.Now()
+	if since, _ := cmd.Flags().GetString("since"); since != "" {
+		t, err := audit.ParseTime(since, now)
+		if err != nil {
+			return f, err
+		}
+		f.Since = t
+	}
+	if until, _ := cmd.Flags().GetString("until"); until != "" {
+		t, err := audit.ParseTime(until, now)
+		if err != nil {
+			return f, err
+		}
+		f.Until = t
+	}
+	return f, nil
+}
+
 // Helper functions
 func openDatabase() db.KeyValueDB {
-	var rdb db.KeyValueDB
-	var err error
+	return openDatabaseFor(audit.InterfaceCLI, nil)
+}
 
-	if readOnly {
-		rdb, err = db.OpenReadOnly(dbPath)
-	} else {
-		rdb, err = db.Open(dbPath)
+// openDatabaseFor opens the database and, when an audit log is configured,
+// records its mutations as made by the local user through iface
+func openDatabaseFor(iface audit.Interface, details map[string]string) db.KeyValueDB {
+	rdb := openRawDatabase()
+
+	cfg, err := loadAuditConfig()
+	if err != nil {
+		fmt.Printf("Failed to load audit config: %v\n", err)
+		os.Exit(1)
+	}
+	if !cfg.Enabled() {
+		return rdb
+	}
+	auditLog, err := audit.New(*cfg, service.AuditStore(rdb))
+	if err != nil {
+		fmt.Printf("Failed to open audit log: %v\n", err)
+		os.Exit(1)
+	}
+	return service.NewAuditedDBClosingLog(rdb, auditLog, service.AuditSource{
+		Actor:     audit.LocalActor(),
+		Interface: iface,
+		Database:  dbPath,
+		Details:   details,
+	})
+}
+
+// startREPLTools starts the MCP clients enabled in the mcp_clients section of
+// the --cf-config file and syncs their tools for the REPL's tools command.
+// The returned function shuts the clients down.
+func startREPLTools(ctx context.Context) (repl.Options, func()) {
+	registry := tools.NewRegistry()
+	opts := repl.Options{Tools: registry}
+	if cfConfig == "" {
+		return opts, func() {}
+	}
+	cfg, err := config.LoadConfig(cfConfig)
+	if err != nil {
+		fmt.Printf("Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	manager := client.NewManager(cfg)
+	if len(manager.ListClients()) == 0 {
+		return opts, func() {}
+	}
+	if err := manager.StartAll(ctx); err != nil {
+		fmt.Printf("Warning: some MCP clients failed to start: %v\n", err)
+	}
+	manager.Supervise(ctx)
+
+	proxy := tools.NewRemoteProxy(registry, manager)
+	if err := proxy.SyncAllTools(ctx); err != nil {
+		fmt.Printf("Warning: failed to sync remote tools: %v\n", err)
+	}
+	proxy.EnableAutoSync()
+	go proxy.Run(ctx, 30*time.Second)
+
+	opts.RemoteTools = proxy
+	return opts, func() { manager.Shutdown(context.Background()) }
+}
+
+// loadAuditConfig returns the audit config from --audit-log or the audit
+// section of the --cf-config file
+func loadAuditConfig() (*audit.Config, error) {
+	if auditPath != "" {
+		return &audit.Config{File: auditPath}, nil
+	}
+	if cfConfig == "" {
+		return nil, nil
+	}
+	cfg, err := config.LoadConfig(cfConfig)
+	if err != nil {
+		return nil, err
 	}
+	return cfg.Audit, nil
+}
 
+func openRawDatabase() db.KeyValueDB {
+	rdb, err := db.OpenWithConfig(dbPath, openConfig())
 	if err != nil {
 		fmt.Printf("Failed to open database: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Stored options that could not be applied change how data is read, so
+	// never fall back to defaults silently
+	if mismatches := rdb.OptionMismatches(); len(mismatches) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d stored column family option(s) could not be applied (see 'cfoptions'):\n", len(mismatches))
+		for _, m := range mismatches {
+			fmt.Fprintf(os.Stderr, "  - %s\n", m)
+		}
+	}
+
 	return rdb
 }
 
+// openConfig returns how the database is opened: read-only with --read-only,
+// with the plugins of the --cf-config file and the --ttl-mode
+func openConfig() db.OpenConfig {
+	plugins, err := loadCFPlugins()
+	if err != nil {
+		fmt.Printf("Failed to load column family config: %v\n", err)
+		os.Exit(1)
+	}
+
+	ttl, err := db.ParseTTLSpec(ttlMode)
+	if err != nil {
+		fmt.Printf("Invalid --ttl-mode: %v\n", err)
+		os.Exit(1)
+	}
+
+	return db.OpenConfig{ReadOnly: readOnly, Plugins: plugins, TTL: ttl}
+}
+
+// loadCFPlugins reads the per column family comparator and merge operator
+// selection from the --cf-config file, if one was given
+func loadCFPlugins() (map[string]db.CFPluginConfig, error) {
+	if cfConfig == "" {
+		return nil, nil
+	}
+	cfg, err := config.LoadConfig(cfConfig)
+	if err != nil {
+		return nil, err
+	}
+	plugins := make(map[string]db.CFPluginConfig, len(cfg.Database.ColumnFamilies))
+	for name, c := range cfg.Database.ColumnFamilies {
+		plugins[name] = db.CFPluginConfig(c)
+	}
+	return plugins, nil
+}
+
 func getColumnFamily(cmd *cobra.Command) string {
 	cf, _ := cmd.Flags().GetString("cf")
 	if cf == "" {
@@ -809,6 +1765,58 @@ func getColumnFamily(cmd *cobra.Command) string {
 	return cf
 }
 
+// structured reports whether commands write their results in an --output
+// format rather than as text
+func structured() bool {
+	return format != output.Text
+}
+
+// writeResult writes the result of a command in the --output format
+func writeResult(r output.Result) {
+	if err := output.Write(os.Stdout, format, r); err != nil {
+		fmt.Fprintf(os.Stderr, "Output error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// dbSearchResults converts search results of the service layer to the
+// result type of the db package, which defines the output schema
+func dbSearchResults(r *service.SearchResult) *db.SearchResults {
+	results := &db.SearchResults{
+		Results:    make([]db.SearchResult, 0, len(r.Results)),
+		Total:      r.Total,
+		Limited:    r.HasMore,
+		QueryTime:  r.QueryTime,
+		NextCursor: r.NextCursor,
+		HasMore:    r.HasMore,
+	}
+	for _, item := range r.Results {
+		results.Results = append(results.Results, db.SearchResult(item))
+	}
+	return results
+}
+
+// auditResult returns audit events as a result, one row per event
+func auditResult(events []audit.Event) output.Result {
+	r := output.Result{
+		Data:    events,
+		Items:   []interface{}{},
+		Columns: []string{"time", "actor", "interface", "operation", "cf", "key", "result", "error"},
+	}
+	if events == nil {
+		r.Data = []audit.Event{}
+	}
+	for _, e := range events {
+		r.Items = append(r.Items, e)
+		r.Rows = append(r.Rows, []string{
+			e.Time.Format(time.RFC3339), e.Actor, string(e.Interface), e.Operation, e.CF, e.Key, e.Result, e.Error,
+		})
+		data, _ := json.Marshal(e)
+		r.Raw = append(r.Raw, string(data))
+	}
+	return r
+}
+
 // formatValue formats a value based on pretty flag using jsonutil
 func formatValue(value string, pretty bool) string {
 	if !pretty {
@@ -817,6 +1825,64 @@ func formatValue(value string, pretty bool) string {
 	return jsonutil.PrettyPrintWithNestedExpansion(value)
 }
 
+// timeQuery reads the --from, --to, --encoding and --prefix flags of a
+// time-series command and detects the time encoding of the keys of cf
+func timeQuery(cmd *cobra.Command, rdb db.KeyValueDB, cf string) timeseries.Query {
+	q := timeseries.Query{CF: cf}
+	encFlag, _ := cmd.Flags().GetString("encoding")
+	enc, err := timeseries.ParseEncoding(encFlag)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	now := time.Now()
+	for name, target := range map[string]*time.Time{"from": &q.From, "to": &q.To} {
+		v, _ := cmd.Flags().GetString(name)
+		if v == "" {
+			continue
+		}
+		if *target, err = timeseries.ParseTime(v, now); err != nil {
+			fmt.Printf("Invalid --%s: %v\n", name, err)
+			os.Exit(1)
+		}
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		fmt.Println("Error: --from must be before --to")
+		os.Exit(1)
+	}
+
+	prefix, _ := cmd.Flags().GetString("prefix")
+	if q.Codec, err = timeseries.Detect(rdb, cf, prefix, enc); err != nil {
+		fmt.Printf("Detect time encoding failed: %v\n", err)
+		os.Exit(1)
+	}
+	return q
+}
+
+// latestArg returns the optional count argument of latest and tail
+func latestArg(args []string) int {
+	if len(args) == 0 {
+		return 10
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n <= 0 {
+		fmt.Printf("Error: invalid count %q\n", args[0])
+		os.Exit(1)
+	}
+	return n
+}
+
+// printTimeEntries prints time-series entries as "<time>  <key>: <value>"
+func printTimeEntries(entries []db.KeyValue, values bool) {
+	for _, kv := range entries {
+		if values {
+			fmt.Printf("%s  %s: %s\n", kv.Timestamp, kv.Key, formatValue(kv.Value, pretty))
+		} else {
+			fmt.Printf("%s  %s\n", kv.Timestamp, kv.Key)
+		}
+	}
+}
+
 // executeScan executes a scan operation with smart key conversion
 func executeScan(rdb db.KeyValueDB, cf string, start, end *string, limit int, reverse, keysOnly, pretty bool, keyPattern, valuePattern string, useRegex, caseSensitive, noColor bool) error {
 	// Convert pointers to strings for smart scan
@@ -850,11 +1916,23 @@ func executeScan(rdb db.KeyValueDB, cf string, start, end *string, limit int, re
 		return err
 	}
 
+	if structured() {
+		writeResult(output.Entries(db.ScanPageResult{
+			ResultsV2:  result.ResultsV2,
+			NextCursor: result.NextCursor,
+			HasMore:    result.HasMore,
+		}, !keysOnly))
+		return nil
+	}
+
 	if result.Count == 0 {
 		fmt.Printf("No entries found in column family '%s'\n", cf)
 		return nil
 	}
 
+	// In TTL mode each value carries its decoded expiry
+	expiry := command.NewTTLIndex(result.ResultsV2)
+
 	fmt.Printf("Found %d entries in column family '%s':\n", result.Count, cf)
 	i := 1
 	for k, v := range result.Data {
@@ -876,6 +1954,9 @@ func executeScan(rdb db.KeyValueDB, cf string, start, end *string, limit int, re
 				}
 			}
 			fmt.Printf("    Value: %s\n", formattedValue)
+			if info := expiry.Get(k); info != nil {
+				fmt.Printf("    TTL: %s\n", info)
+			}
 		}
 		fmt.Println()
 		i++
@@ -900,6 +1981,11 @@ func executePrefix(rdb db.KeyValueDB, cf, prefix string, pretty bool, valuePatte
 		return err
 	}
 
+	if structured() {
+		writeResult(output.Entries(db.ScanPageResult{ResultsV2: output.EntriesOf(result.Data)}, true))
+		return nil
+	}
+
 	if result.Count == 0 {
 		fmt.Printf("No entries found with prefix '%s' in column family '%s'\n", prefix, cf)
 		return nil
@@ -962,6 +2048,10 @@ func executeSearch(rdb db.KeyValueDB, cf, keyPattern, valuePattern string, useRe
 		if err != nil {
 			return err
 		}
+		if structured() {
+			writeResult(output.Done(output.Status{Operation: "export", ColumnFamily: cf, File: exportFile}))
+			return nil
+		}
 		fmt.Printf("Search results exported to %s\n", exportFile)
 		return nil
 	}
@@ -979,6 +2069,11 @@ func executeSearch(rdb db.KeyValueDB, cf, keyPattern, valuePattern string, useRe
 		return err
 	}
 
+	if structured() {
+		writeResult(output.Search(dbSearchResults(results), !keysOnly))
+		return nil
+	}
+
 	if results.Count == 0 {
 		fmt.Printf("No matches found in column family '%s'\n", cf)
 		return nil
@@ -1007,6 +2102,9 @@ func executeSearch(rdb db.KeyValueDB, cf, keyPattern, valuePattern string, useRe
 			}
 			fmt.Printf("    Value: %s\n", formattedValue)
 		}
+		if result.TTL != nil {
+			fmt.Printf("    TTL: %s\n", result.TTL)
+		}
 		if i < len(results.Results)-1 {
 			fmt.Println()
 		}
@@ -1096,7 +2194,7 @@ func runGraphChainQuery(database db.KeyValueDB, query string) {
 	}
 
 	// Cast to *db.DB (required for GraphChain Agent)
-	dbPtr, ok := database.(*db.DB)
+	dbPtr, ok := service.UnwrapDB(database).(*db.DB)
 	if !ok {
 		fmt.Printf("Error: GraphChain Agent requires a writable database connection\n")
 		return
@@ -1104,6 +2202,9 @@ func runGraphChainQuery(database db.KeyValueDB, query string) {
 
 	// Create and initialize agent
 	agent := graphchain.NewAgent(dbPtr)
+	if audited, ok := database.(*service.AuditedDB); ok {
+		agent.SetAuditedDB(audited.WithInterface(audit.InterfaceAI))
+	}
 	ctx := context.Background()
 
 	err = agent.Initialize(ctx, config)
@@ -1144,11 +2245,20 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&dbPath, "db", "", "Path to RocksDB database (required)")
 	rootCmd.PersistentFlags().BoolVar(&readOnly, "read-only", false, "Open database in read-only mode")
 	rootCmd.PersistentFlags().StringVar(&configPath, "config", "config/graphchain.yaml", "Path to GraphChain configuration file")
+	rootCmd.PersistentFlags().StringVar(&cfConfig, "cf-config", "", "Config file selecting comparators and merge operators per column family (database.column_families)")
+	rootCmd.PersistentFlags().StringVar(&auditPath, "audit-log", "", "Record every mutating operation in this JSONL audit file (rotated at 100MB)")
+	rootCmd.PersistentFlags().StringVar(&ttlMode, "ttl-mode", "", "Decode values written by DBWithTTL; TTL per CF, e.g. \"24h\" or \"24h,events=1h\" (0 = no expiry)")
 	rootCmd.PersistentFlags().BoolVar(&pretty, "pretty", false, "Pretty print JSON values")
+	rootCmd.PersistentFlags().StringVarP(&outputName, "output", "o", "text", "Output format: "+strings.Join(output.FormatNames(), ", "))
 
 	// Column family flag for commands that need it
 	getCmd.Flags().StringP("cf", "c", "default", "Column family")
 	putCmd.Flags().StringP("cf", "c", "default", "Column family")
+	putCmd.Flags().String("ttl", "", "Expire the value after this duration (\"90m\") or number of seconds instead of the CF TTL (requires --ttl-mode)")
+	putCmd.Flags().Bool("hex", false, "Decode the value from hex (spaces and 0x prefix allowed)")
+	putCmd.Flags().Bool("base64", false, "Decode the value from base64")
+	inspectCmd.Flags().StringP("cf", "c", "default", "Column family")
+	mergeCmd.Flags().StringP("cf", "c", "default", "Column family")
 	lastCmd.Flags().StringP("cf", "c", "default", "Column family")
 	scanCmd.Flags().StringP("cf", "c", "default", "Column family")
 	prefixCmd.Flags().StringP("cf", "c", "default", "Column family")
@@ -1156,6 +2266,18 @@ func init() {
 	exportCmd.Flags().StringP("cf", "c", "default", "Column family")
 	watchCmd.Flags().StringP("cf", "c", "default", "Column family")
 	keyformatCmd.Flags().StringP("cf", "c", "default", "Column family")
+	rangeCmd.Flags().StringP("cf", "c", "default", "Column family")
+	latestCmd.Flags().StringP("cf", "c", "default", "Column family")
+	tailCmd.Flags().StringP("cf", "c", "default", "Column family")
+	histogramCmd.Flags().StringP("cf", "c", "default", "Column family")
+
+	// Cfoptions command flags
+	cfoptionsCmd.Flags().StringP("cf", "c", "default", "Column family")
+	cfoptionsCmd.Flags().Bool("profiles", false, "List column family tuning profiles usable with createcf --profile")
+	cfoptionsCmd.Flags().Bool("plugins", false, "List comparators and merge operators usable in --cf-config")
+
+	// Createcf command flags
+	createcfCmd.Flags().String("profile", "", "Tuning profile for the new column family (see cfoptions --profiles)")
 	jsonqueryCmd.Flags().StringP("cf", "c", "default", "Column family")
 
 	// Prefix command specific flags
@@ -1193,8 +2315,39 @@ func init() {
 	// Watch command specific flags
 	watchCmd.Flags().Duration("interval", 1*time.Second, "Watch interval")
 
+	// Time-series command flags
+	for _, c := range []*cobra.Command{rangeCmd, latestCmd, tailCmd, histogramCmd} {
+		c.Flags().String("encoding", "", "How keys start with a time: ticks, ns, us, ms, s or iso (detected by default)")
+		c.Flags().String("prefix", "", "Read the time after this key prefix")
+	}
+	for _, c := range []*cobra.Command{rangeCmd, histogramCmd} {
+		c.Flags().String("from", "", "Start time, included (RFC 3339, 2006-01-02T15:04 in UTC, a date, now or an age like 1h or 7d)")
+		c.Flags().String("to", "", "End time, excluded")
+	}
+	for _, c := range []*cobra.Command{rangeCmd, latestCmd, tailCmd} {
+		c.Flags().Bool("keys-only", false, "Show only keys, not values")
+	}
+	rangeCmd.Flags().Int("limit", 100, "Limit number of results (0 for all)")
+	rangeCmd.Flags().Bool("reverse", false, "Newest entries first")
+	rangeCmd.Flags().String("after", "", "Continue after this cursor (pagination)")
+	tailCmd.Flags().BoolP("follow", "f", false, "Keep showing new entries until Ctrl+C")
+	tailCmd.Flags().Duration("interval", 1*time.Second, "Follow interval")
+	histogramCmd.Flags().String("bucket", "1h", "Bucket width: a duration such as 15m or 1h, or days such as 1d")
+
+	// Schema command flags
+	schemaInferCmd.Flags().StringP("cf", "c", "default", "Column family")
+	schemaInferCmd.Flags().String("prefix", "", "Only values of keys with this prefix")
+	schemaInferCmd.Flags().Int("sample", schema.DefaultSamples, "Number of JSON values sampled")
+	schemaInferCmd.Flags().String("save", "", "Save the schema to this JSON file")
+	schemaCheckCmd.Flags().String("schema", "", "Schema file saved by schema infer")
+	schemaCheckCmd.Flags().StringP("cf", "c", "", "Column family (default: the one the schema was inferred from)")
+	schemaCheckCmd.Flags().String("prefix", "", "Only values of keys with this prefix (default: the schema's prefix)")
+	schemaCheckCmd.Flags().Int("limit", schema.DefaultLimit, "List at most this many violations; all are counted")
+	schemaCmd.AddCommand(schemaInferCmd, schemaCheckCmd)
+
 	// Stats command specific flags
 	statsCmd.Flags().String("cf", "", "Column family for stats (omit for database-wide stats)")
+	statsCmd.Flags().Bool("ttl", false, "Show the expiry distribution of the column family (requires --ttl-mode)")
 
 	// JSON query command specific flags
 	jsonqueryCmd.Flags().String("field", "", "Field name for JSON query")
@@ -1215,29 +2368,59 @@ func init() {
 	// Web command specific flags
 	webCmd.Flags().String("port", "8080", "Port to listen on")
 	webCmd.Flags().Bool("enable-ai", false, "Enable AI assistant powered by GraphChain")
+	webCmd.Flags().String("auth-config", "", "Path to auth config file (tokens, basic auth, OIDC and roles)")
+
+	// Audit command specific flags
+	auditCmd.Flags().String("actor", "", "Only events by this actor")
+	auditCmd.Flags().String("interface", "", "Only events from this interface (cli, repl, tui, rest, mcp, ai, transform)")
+	auditCmd.Flags().String("op", "", "Only this operation (put, merge, createcf, dropcf)")
+	auditCmd.Flags().String("cf", "", "Only events on this column family")
+	auditCmd.Flags().String("key", "", "Only events on this key")
+	auditCmd.Flags().String("result", "", "Only events with this result (ok, error)")
+	auditCmd.Flags().String("since", "", "Only events at or after this time (RFC 3339, YYYY-MM-DD, or an age like 24h or 7d)")
+	auditCmd.Flags().String("until", "", "Only events before this time")
+	auditCmd.Flags().Int("limit", 50, "Show at most this many of the most recent events")
+	auditCmd.Flags().Bool("json", false, "Print events as JSON lines")
+
+	rootCmd.Flags().String("script", "", "Run the REPL commands of this file (- for stdin) and exit")
+	rootCmd.Flags().StringP("execute", "e", "", "Run REPL commands separated by ; and exit")
+
+	replCmd.Flags().Bool("no-session", false, "Do not restore or save history and session state")
+	replCmd.Flags().String("session-dir", "", "Directory for history and session files (default: user config dir/rocksdb-cli/sessions)")
+
+	// TUI command specific flags
+	tuiCmd.Flags().StringP("cf", "c", "", "Column family shown first (default: the first one)")
+	tuiCmd.Flags().Duration("stats-interval", 30*time.Second, "How often the column family stats are computed again (0 = only on demand with s)")
 
 	// Add all commands to root
 	rootCmd.AddCommand(replCmd)
+	rootCmd.AddCommand(tuiCmd)
 	rootCmd.AddCommand(getCmd)
 	rootCmd.AddCommand(putCmd)
+	rootCmd.AddCommand(inspectCmd)
+	rootCmd.AddCommand(mergeCmd)
 	rootCmd.AddCommand(lastCmd)
 	rootCmd.AddCommand(scanCmd)
 	rootCmd.AddCommand(prefixCmd)
 	rootCmd.AddCommand(searchCmd)
 	rootCmd.AddCommand(exportCmd)
 	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(rangeCmd)
+	rootCmd.AddCommand(latestCmd)
+	rootCmd.AddCommand(tailCmd)
+	rootCmd.AddCommand(histogramCmd)
+	rootCmd.AddCommand(schemaCmd)
 	rootCmd.AddCommand(statsCmd)
 	rootCmd.AddCommand(keyformatCmd)
 	rootCmd.AddCommand(jsonqueryCmd)
 	rootCmd.AddCommand(listcfCmd)
 	rootCmd.AddCommand(createcfCmd)
+	rootCmd.AddCommand(cfoptionsCmd)
 	rootCmd.AddCommand(dropcfCmd)
 	rootCmd.AddCommand(aiCmd)
 	rootCmd.AddCommand(transformCmd)
 	rootCmd.AddCommand(webCmd)
-
-	// Mark required flags
-	rootCmd.MarkPersistentFlagRequired("db")
+	rootCmd.AddCommand(auditCmd)
 }
 
 func main() {
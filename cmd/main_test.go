@@ -6,9 +6,11 @@ import (
 	"io"
 	"os"
 	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/keytree"
 	"rocksdb-cli/internal/util"
 	"strings"
 	"testing"
+	"time"
 )
 
 // mockDB implements db.KeyValueDB interface for testing
@@ -196,6 +198,13 @@ func (m *mockDB) ExportToCSV(cf, filePath, sep string) error {
 	return nil
 }
 
+func (m *mockDB) ExportToCSVWithOptions(cf, filePath string, opts db.ExportOptions) (*db.ExportResult, error) {
+	if err := m.ExportToCSV(cf, filePath, opts.Sep); err != nil {
+		return nil, err
+	}
+	return &db.ExportResult{}, nil
+}
+
 func (m *mockDB) ExportSearchResultsToCSV(cf, filePath, sep string, opts db.SearchOptions) error {
 	if !m.cfExists[cf] {
 		return db.ErrColumnFamilyNotFound
@@ -267,6 +276,10 @@ func (m *mockDB) GetCFStats(cf string) (*db.CFStats, error) {
 	return stats, nil
 }
 
+func (m *mockDB) GetCFStatsWithOptions(cf string, opts db.StatsOptions) (*db.CFStats, error) {
+	return m.GetCFStats(cf)
+}
+
 func (m *mockDB) GetDatabaseStats() (*db.DatabaseStats, error) {
 	cfs := []db.CFStats{}
 	for cf := range m.cfExists {
@@ -284,6 +297,20 @@ func (m *mockDB) GetDatabaseStats() (*db.DatabaseStats, error) {
 	return stats, nil
 }
 
+func (m *mockDB) GetKeyTree(cf string, opts keytree.Options) (*keytree.Node, error) {
+	if !m.cfExists[cf] {
+		return nil, db.ErrColumnFamilyNotFound
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	b := keytree.NewBuilder(opts)
+	for key, value := range m.data[cf] {
+		b.Add([]byte(key), len(value))
+	}
+	return b.Tree(), nil
+}
+
 func (m *mockDB) SearchCF(cf string, opts db.SearchOptions) (*db.SearchResults, error) {
 	if !m.cfExists[cf] {
 		return nil, db.ErrColumnFamilyNotFound
@@ -699,6 +726,41 @@ func (m *mockDB) GetKeyFormatInfo(cf string) (util.KeyFormat, string) {
 	return util.KeyFormatString, "Printable string keys"
 }
 
+func (m *mockDB) CreateCFWithProfile(cf, profile string) error {
+	return m.CreateCF(cf)
+}
+
+func (m *mockDB) GetCFOptions(cf string) (*db.CFOptions, error) {
+	if !m.cfExists[cf] {
+		return nil, db.ErrColumnFamilyNotFound
+	}
+	return &db.CFOptions{Name: cf, Comparator: db.BytewiseComparator}, nil
+}
+
+func (m *mockDB) OptionMismatches() []db.OptionMismatch {
+	return nil
+}
+
+func (m *mockDB) MergeCF(cf, key, operand string) error {
+	return errors.New("merge is not supported by mockDB")
+}
+
+func (m *mockDB) PutCFWithTTL(cf, key, value string, ttl time.Duration) error {
+	return db.ErrTTLModeDisabled
+}
+
+func (m *mockDB) GetCFWithTTL(cf, key string) (string, *db.TTLInfo, error) {
+	return "", nil, db.ErrTTLModeDisabled
+}
+
+func (m *mockDB) GetTTLStats(cf string) (*db.TTLStats, error) {
+	return nil, db.ErrTTLModeDisabled
+}
+
+func (m *mockDB) TTLMode() bool {
+	return false
+}
+
 func (m *mockDB) ScanCFPage(cf string, start, end []byte, opts db.ScanOptions) (db.ScanPageResult, error) {
 	result, err := m.ScanCF(cf, start, end, opts)
 	if err != nil {
@@ -1,16 +1,23 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 
+	"rocksdb-cli/internal/audit"
 	"rocksdb-cli/internal/config"
 	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/mcp/client"
 	mcpserver "rocksdb-cli/internal/mcp/server"
+	"rocksdb-cli/internal/mcp/tools"
+	"rocksdb-cli/internal/service"
 
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -18,13 +25,18 @@ import (
 func main() {
 	// Command line flags
 	var (
-		configPath = flag.String("config", "", "Path to configuration file")
-		dbPath     = flag.String("db", "", "Path to RocksDB database")
-		readOnly   = flag.Bool("readonly", false, "Open database in read-only mode")
-		transport  = flag.String("transport", "stdio", "Transport type (stdio, tcp, websocket, unix)")
-		host       = flag.String("host", "localhost", "Host for TCP/WebSocket transport")
-		port       = flag.Int("port", 8080, "Port for TCP/WebSocket transport")
-		socketPath = flag.String("socket", "/tmp/rocksdb-mcp.sock", "Unix socket path")
+		configPath  = flag.String("config", "", "Path to configuration file")
+		dbPath      = flag.String("db", "", "Path to RocksDB database")
+		readOnly    = flag.Bool("readonly", false, "Open database in read-only mode")
+		transport   = flag.String("transport", "stdio", "Transport type (stdio, tcp, websocket, streamable-http, unix)")
+		host        = flag.String("host", "localhost", "Host for TCP/WebSocket/HTTP transport")
+		port        = flag.Int("port", 8080, "Port for TCP/WebSocket/HTTP transport")
+		path        = flag.String("path", "", "Endpoint path for WebSocket/HTTP transport (default /ws or /mcp)")
+		origins     = flag.String("allowed-origins", "", "Comma-separated web page origins allowed to use the WebSocket/HTTP transport besides the listen host")
+		socketPath  = flag.String("socket", "/tmp/rocksdb-mcp.sock", "Unix socket path")
+		maxSessions = flag.Int("max-sessions", 0, "Maximum concurrent WebSocket/HTTP sessions (default 10)")
+		sessionTTL  = flag.Duration("session-timeout", 0, "Close WebSocket/HTTP sessions idle for this long (default 5m)")
+		gateway     = flag.Bool("gateway", false, "Re-export the tools of the configured MCP clients")
 	)
 	flag.Parse()
 
@@ -47,13 +59,35 @@ func main() {
 	}
 	if *readOnly {
 		cfg.Database.ReadOnly = true
+		if cfg.MCPServer != nil {
+			for name, database := range cfg.MCPServer.Databases {
+				database.ReadOnly = true
+				cfg.MCPServer.Databases[name] = database
+			}
+		}
 	}
 	if *transport != "stdio" && cfg.MCPServer != nil {
+		cfg.MCPServer.Enabled = true
 		cfg.MCPServer.Transport.Type = *transport
 		cfg.MCPServer.Transport.Host = *host
 		cfg.MCPServer.Transport.Port = *port
+		cfg.MCPServer.Transport.Path = *path
 		cfg.MCPServer.Transport.SocketPath = *socketPath
 	}
+	if cfg.MCPServer != nil {
+		if *maxSessions > 0 {
+			cfg.MCPServer.MaxConcurrentSessions = *maxSessions
+		}
+		if *sessionTTL > 0 {
+			cfg.MCPServer.SessionTimeout = *sessionTTL
+		}
+		if *gateway {
+			cfg.MCPServer.Gateway = true
+		}
+		if *origins != "" {
+			cfg.MCPServer.Transport.AllowedOrigins = strings.Split(*origins, ",")
+		}
+	}
 
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
@@ -68,26 +102,48 @@ func main() {
 	// Convert to server config for backward compatibility
 	serverConfig := mcpserver.NewConfigFromUnified(cfg)
 
-	// Create database directory if it doesn't exist
-	dbDir := filepath.Dir(cfg.Database.Path)
-	if err := os.MkdirAll(dbDir, 0755); err != nil {
-		log.Fatalf("Failed to create database directory: %v", err)
-	}
-
-	// Open database
-	var database db.KeyValueDB
-	if cfg.Database.ReadOnly {
-		database, err = db.OpenReadOnly(cfg.Database.Path)
-	} else {
-		database, err = db.Open(cfg.Database.Path)
-	}
+	// Open database with the comparators and merge operators selected per
+	// column family
+	database, err := openDatabase(cfg.Database)
 	if err != nil {
 		log.Fatalf("Failed to open database: %v", err)
 	}
-	defer database.Close()
 
 	log.Printf("Opened RocksDB at: %s (read-only: %v)", cfg.Database.Path, cfg.Database.ReadOnly)
 
+	// Host the databases of mcp_server.databases next to the default one,
+	// opening them when first used
+	var extraDatabases map[string]config.DatabaseConfig
+	defaultDatabase := mcpserver.DefaultDatabase
+	if cfg.MCPServer != nil {
+		extraDatabases = cfg.MCPServer.Databases
+		if cfg.MCPServer.DefaultDatabase != "" {
+			defaultDatabase = cfg.MCPServer.DefaultDatabase
+		}
+	}
+	var auditLog *audit.Log
+	databases := mcpserver.NewDatabases(defaultDatabase, func(name string) (db.KeyValueDB, error) {
+		dbConfig := extraDatabases[name]
+		database, err := openDatabase(dbConfig)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Opened RocksDB '%s' at: %s (read-only: %v)", name, dbConfig.Path, dbConfig.ReadOnly)
+		return auditedDatabase(database, auditLog, dbConfig.Path), nil
+	})
+	defer databases.Close()
+
+	// Record writes made by MCP clients in the audit log
+	if cfg.Audit.Enabled() {
+		auditLog, err = audit.New(*cfg.Audit, service.AuditStore(database))
+		if err != nil {
+			log.Fatalf("Failed to open audit log: %v", err)
+		}
+		defer auditLog.Close()
+		database = auditedDatabase(database, auditLog, cfg.Database.Path)
+		log.Printf("Audit log enabled")
+	}
+
 	// Create MCP server
 	mcpServer := server.NewMCPServer(
 		cfg.Name,
@@ -95,32 +151,70 @@ func main() {
 		server.WithToolCapabilities(true),
 		server.WithPromptCapabilities(true),
 		server.WithResourceCapabilities(true, true),
+		server.WithPaginationLimit(serverConfig.PageSize),
 	)
 
+	// Notify subscribed clients of writes made through the server
+	subscriptions := mcpserver.NewSubscriptionManager(mcpServer)
+	database = subscriptions.Watch(database)
+
+	databases.AddOpen(mcpserver.DefaultDatabase, cfg.Database.Path, database)
+	for name, dbConfig := range extraDatabases {
+		databases.Add(name, dbConfig.Path, dbConfig.ReadOnly)
+	}
+
 	// Create and register tool manager
-	toolManager := mcpserver.NewToolManager(database, serverConfig)
+	toolManager := mcpserver.NewToolManagerWithDatabases(databases, serverConfig)
 	if err := toolManager.RegisterTools(mcpServer); err != nil {
 		log.Fatalf("Failed to register tools: %v", err)
 	}
 
 	// Create and register prompt manager
-	promptManager := mcpserver.NewPromptManager(database, serverConfig)
+	promptManager := mcpserver.NewPromptManagerWithDatabases(databases, serverConfig)
 	if err := promptManager.RegisterPrompts(mcpServer); err != nil {
 		log.Fatalf("Failed to register prompts: %v", err)
 	}
 
-	// Create and register resource manager
+	// Create and register resource manager. Resources always read the
+	// default database, whose column families they list.
 	resourceManager := mcpserver.NewResourceManager(database, serverConfig)
 	if err := resourceManager.RegisterResources(mcpServer); err != nil {
 		log.Fatalf("Failed to register resources: %v", err)
 	}
+	subscriptions.OnColumnFamiliesChanged(func() {
+		if err := resourceManager.SyncColumnFamilies(); err != nil {
+			log.Printf("Failed to update column family resources: %v", err)
+		}
+	})
 
-	// Create transport manager - commented out for now
-	// transportManager := mcp.NewTransportManager(config, mcpServer)
+	// Setup graceful shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	// Setup graceful shutdown - simplified for stdio
-	// ctx, cancel := context.WithCancel(context.Background())
-	// defer cancel()
+	// Re-export the tools of the configured MCP clients
+	if serverConfig.Gateway {
+		manager := client.NewManager(cfg)
+		if err := manager.StartAll(ctx); err != nil {
+			log.Printf("Some MCP clients failed to start: %v", err)
+		}
+		defer manager.Shutdown(context.Background())
+
+		// Restart clients whose connection or process dies
+		manager.OnReconnect(func(ctx context.Context, name string) {
+			log.Printf("MCP client %s reconnected", name)
+		})
+		manager.Supervise(ctx)
+
+		registry := tools.NewRegistry()
+		proxy := tools.NewRemoteProxy(registry, manager)
+		if err := proxy.SyncAllTools(ctx); err != nil {
+			log.Printf("Failed to sync remote tools: %v", err)
+		}
+		mcpserver.NewGateway(registry, proxy).Register(mcpServer)
+		proxy.EnableAutoSync()
+		go proxy.Run(ctx, serverConfig.GatewaySyncInterval)
+		log.Printf("Gateway enabled: %d remote tools from %d clients", registry.Count(), len(manager.ListClients()))
+	}
 
 	// Handle shutdown signals
 	sigChan := make(chan os.Signal, 1)
@@ -133,26 +227,55 @@ func main() {
 	go func() {
 		<-sigChan
 		log.Println("Received shutdown signal, shutting down gracefully...")
-		// For stdio mode, the server will handle shutdown internally
+		cancel()
 	}()
 
 	// Start the server based on transport type
 	log.Printf("Starting MCP server with %s transport...", serverConfig.Transport.Type)
 
-	switch serverConfig.Transport.Type {
-	case "stdio":
-		if err := server.ServeStdio(mcpServer); err != nil {
-			log.Fatalf("STDIO server error: %v", err)
-		}
-	case "tcp", "websocket", "unix":
-		// For now, fall back to stdio for other transport types
-		log.Printf("Transport type %s not fully implemented, falling back to stdio", serverConfig.Transport.Type)
-		if err := server.ServeStdio(mcpServer); err != nil {
-			log.Fatalf("STDIO server error: %v", err)
-		}
-	default:
-		log.Fatalf("Unknown transport type: %s", serverConfig.Transport.Type)
+	transportManager := mcpserver.NewTransportManager(serverConfig, mcpServer)
+	subscriptions.Register(transportManager)
+	databases.Register(transportManager)
+	mcpserver.NewCompletionManagerWithDatabases(databases, serverConfig).Register(transportManager)
+	if serverConfig.Transport.Type != "stdio" {
+		log.Printf("Sessions: max %d, idle timeout %v", serverConfig.MaxConcurrentSessions, serverConfig.SessionTimeout)
+	}
+	if err := transportManager.StartTransport(ctx); err != nil {
+		log.Fatalf("%s server error: %v", serverConfig.Transport.Type, err)
 	}
 
 	log.Println("MCP server shutdown complete")
 }
+
+// openDatabase opens a database with the comparators, merge operators and
+// TTL mode of its configuration
+func openDatabase(dbConfig config.DatabaseConfig) (db.KeyValueDB, error) {
+	// Create database directory if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(dbConfig.Path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	plugins := make(map[string]db.CFPluginConfig, len(dbConfig.ColumnFamilies))
+	for name, c := range dbConfig.ColumnFamilies {
+		plugins[name] = db.CFPluginConfig(c)
+	}
+	ttl, err := db.ParseTTLSpec(dbConfig.TTLMode)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ttl_mode: %w", err)
+	}
+	return db.OpenWithConfig(dbConfig.Path, db.OpenConfig{
+		ReadOnly: dbConfig.ReadOnly,
+		Plugins:  plugins,
+		TTL:      ttl,
+	})
+}
+
+// auditedDatabase records the writes made by MCP clients to the database at
+// path in auditLog. A nil auditLog returns database unchanged.
+func auditedDatabase(database db.KeyValueDB, auditLog *audit.Log, path string) db.KeyValueDB {
+	return service.NewAuditedDB(database, auditLog, service.AuditSource{
+		Actor:     audit.LocalActor(),
+		Interface: audit.InterfaceMCP,
+		Database:  path,
+	})
+}
@@ -6,14 +6,18 @@ import (
 	"log"
 
 	"rocksdb-cli/internal/api"
+	"rocksdb-cli/internal/audit"
+	"rocksdb-cli/internal/auth"
 	"rocksdb-cli/internal/service"
 )
 
 var (
-	dbPath   string
-	port     string
-	readOnly bool
-	webUI    bool
+	dbPath     string
+	port       string
+	readOnly   bool
+	webUI      bool
+	authConfig string
+	auditLog   string
 )
 
 func init() {
@@ -21,6 +25,8 @@ func init() {
 	flag.StringVar(&port, "port", "8080", "Port to listen on")
 	flag.BoolVar(&readOnly, "readonly", true, "Open database in read-only mode (recommended)")
 	flag.BoolVar(&webUI, "ui", true, "Enable Web UI with dynamic database selection")
+	flag.StringVar(&authConfig, "auth-config", "", "Path to auth config file (tokens, basic auth, OIDC and roles)")
+	flag.StringVar(&auditLog, "audit-log", "", "Record writes made through the API in this JSONL file")
 }
 
 func main() {
@@ -41,14 +47,36 @@ func main() {
 	}
 	fmt.Printf("✅ Database connected successfully\n")
 
+	// Load authentication and role configuration
+	var opts []api.Option
+	if authConfig != "" {
+		authManager, err := auth.NewFromFile(authConfig)
+		if err != nil {
+			log.Fatalf("Failed to load auth config: %v", err)
+		}
+		opts = append(opts, api.WithAuth(authManager))
+	}
+
+	// Record writes in the audit log
+	if auditLog != "" {
+		l, err := audit.New(audit.Config{File: auditLog}, nil)
+		if err != nil {
+			log.Fatalf("Failed to open audit log: %v", err)
+		}
+		defer l.Close()
+		opts = append(opts, api.WithAuditLog(l))
+	}
+
 	// Setup router with UI support
-	router := api.SetupRouterWithUI(dbManager)
+	router := api.SetupRouterWithUI(dbManager, opts...)
 
 	// Start server
 	addr := ":" + port
 	fmt.Printf("\n🚀 RocksDB Web Server starting on http://localhost%s\n", addr)
 	fmt.Printf("   Database: %s\n", dbPath)
 	fmt.Printf("   Read-only: %v\n", readOnly)
+	fmt.Printf("   Auth: %v\n", authConfig != "")
+	fmt.Printf("   Audit log: %v\n", auditLog != "")
 	fmt.Printf("\n📋 API Endpoints:\n")
 	fmt.Printf("   GET  /                              - Web UI (if enabled)\n")
 	fmt.Printf("   GET  /api/v1/health                 - Health check\n")
@@ -61,6 +89,7 @@ func main() {
 	fmt.Printf("   GET  /api/v1/cf/:cf/get/:key        - Get value by key\n")
 	fmt.Printf("   POST /api/v1/cf/:cf/put             - Put key-value pair\n")
 	fmt.Printf("   POST /api/v1/cf/:cf/scan            - Scan entries\n")
+	fmt.Printf("   GET  /api/v1/audit                  - Query the audit log\n")
 	fmt.Printf("\n💡 Open in browser: http://localhost%s\n\n", addr)
 
 	if err := router.Run(addr); err != nil {
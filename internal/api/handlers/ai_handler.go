@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"time"
 
+	"rocksdb-cli/internal/api/middleware"
+	"rocksdb-cli/internal/auth"
 	"rocksdb-cli/internal/graphchain"
 
 	"github.com/gin-gonic/gin"
@@ -62,6 +64,15 @@ func (h *AIHandler) Query(c *gin.Context) {
 		return
 	}
 
+	// The agent chooses which column families to touch, so it needs access to
+	// the whole database, including write access unless it cannot write
+	if !middleware.Authorize(c, auth.RoleRead, "") {
+		return
+	}
+	if !h.agent.ReadOnly() && !middleware.Authorize(c, auth.RoleWrite, "") {
+		return
+	}
+
 	// Create context with timeout
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
 	defer cancel()
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"rocksdb-cli/internal/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandler serves the audit log of mutating operations
+type AuditHandler struct {
+	log *audit.Log
+}
+
+// NewAuditHandler creates a new AuditHandler; log may be nil when auditing
+// is not configured
+func NewAuditHandler(log *audit.Log) *AuditHandler {
+	return &AuditHandler{log: log}
+}
+
+// Query handles GET /api/v1/audit
+// @Summary Query the audit log
+// @Description List recorded mutating operations, most recent last
+// @Tags Audit
+// @Param actor query string false "Only events by this actor"
+// @Param interface query string false "Only events from this interface (cli, repl, tui, rest, mcp, ai, transform)"
+// @Param operation query string false "Only this operation (put, merge, createcf, dropcf)"
+// @Param cf query string false "Only events on this column family"
+// @Param key query string false "Only events on this key"
+// @Param result query string false "Only events with this result (ok, error)"
+// @Param since query string false "Only events at or after this time (RFC 3339, YYYY-MM-DD or an age like 24h)"
+// @Param until query string false "Only events before this time"
+// @Param limit query int false "Maximum number of events (default 100)"
+// @Success 200 {object} map[string]interface{} "success response with events"
+// @Failure 400 {object} map[string]interface{} "bad request"
+// @Failure 404 {object} map[string]interface{} "audit log not configured"
+// @Failure 500 {object} map[string]interface{} "internal server error"
+// @Router /api/v1/audit [get]
+func (h *AuditHandler) Query(c *gin.Context) {
+	if h.log == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "audit log not configured",
+			"message": "Start the server with --audit-log to record operations",
+		})
+		return
+	}
+
+	filter := audit.Filter{
+		Actor:     c.Query("actor"),
+		Interface: audit.Interface(c.Query("interface")),
+		Operation: c.Query("operation"),
+		CF:        c.Query("cf"),
+		Key:       c.Query("key"),
+		Result:    c.Query("result"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "limit must be a positive integer",
+				"message": "Invalid query parameters",
+			})
+			return
+		}
+		filter.Limit = n
+	}
+	now := time.Now()
+	for param, bound := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if v := c.Query(param); v != "" {
+			t, err := audit.ParseTime(v, now)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"success": false,
+					"error":   err.Error(),
+					"message": "Invalid query parameters",
+				})
+				return
+			}
+			*bound = t
+		}
+	}
+
+	events, err := h.log.Query(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"message": "Failed to read audit log",
+		})
+		return
+	}
+	if events == nil {
+		events = []audit.Event{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"events": events,
+			"count":  len(events),
+		},
+	})
+}
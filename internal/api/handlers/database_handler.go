@@ -1,8 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
+	"rocksdb-cli/internal/api/middleware"
+	"rocksdb-cli/internal/auth"
+	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/inspect"
 	"rocksdb-cli/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -32,6 +38,43 @@ func (h *DatabaseHandler) GetValue(c *gin.Context) {
 	cf := c.Param("cf")
 	key := c.Param("key")
 
+	value, ttl, err := h.dbService.GetValueWithTTL(cf, key)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"message": "Key not found",
+		})
+		return
+	}
+
+	data := gin.H{
+		"cf":    cf,
+		"key":   key,
+		"value": value,
+	}
+	if ttl != nil {
+		data["ttl"] = ttl
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// InspectValue handles GET /api/v1/cf/:cf/inspect/:key
+// @Summary Inspect a binary value
+// @Description Return a hex dump of the value with candidate decodings: varints, fixed-width integers, UTF-8 strings, embedded JSON, protobuf fields and .NET ticks
+// @Tags Database
+// @Param cf path string true "Column Family"
+// @Param key path string true "Key"
+// @Success 200 {object} map[string]interface{} "success response with key and decodings"
+// @Failure 404 {object} map[string]interface{} "key not found"
+// @Router /api/v1/cf/{cf}/inspect/{key} [get]
+func (h *DatabaseHandler) InspectValue(c *gin.Context) {
+	cf := c.Param("cf")
+	key := c.Param("key")
+
 	value, err := h.dbService.GetValue(cf, key)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
@@ -45,9 +88,9 @@ func (h *DatabaseHandler) GetValue(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": gin.H{
-			"cf":    cf,
-			"key":   key,
-			"value": value,
+			"cf":      cf,
+			"key":     key,
+			"inspect": inspect.Inspect([]byte(value)),
 		},
 	})
 }
@@ -69,6 +112,7 @@ func (h *DatabaseHandler) PutValue(c *gin.Context) {
 	var req struct {
 		Key   string `json:"key" binding:"required"`
 		Value string `json:"value" binding:"required"`
+		TTL   string `json:"ttl"` // optional, TTL mode only: "90m" or seconds
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -80,7 +124,21 @@ func (h *DatabaseHandler) PutValue(c *gin.Context) {
 		return
 	}
 
-	err := h.dbService.PutValue(cf, req.Key, req.Value)
+	var ttl time.Duration
+	if req.TTL != "" {
+		d, err := db.ParseTTLDuration(req.TTL)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+				"message": "Invalid 'ttl' field",
+			})
+			return
+		}
+		ttl = d
+	}
+
+	err := h.dbService.PutValueWithTTL(cf, req.Key, req.Value, ttl)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		message := "Failed to put value"
@@ -89,6 +147,9 @@ func (h *DatabaseHandler) PutValue(c *gin.Context) {
 		if err.Error() == "operation not allowed in read-only mode" {
 			statusCode = http.StatusForbidden
 			message = "Database is in read-only mode"
+		} else if errors.Is(err, db.ErrTTLModeDisabled) {
+			statusCode = http.StatusBadRequest
+			message = "Database is not opened in TTL mode"
 		}
 
 		c.JSON(statusCode, gin.H{
@@ -110,6 +171,71 @@ func (h *DatabaseHandler) PutValue(c *gin.Context) {
 	})
 }
 
+// MergeValue handles POST /api/v1/cf/:cf/merge
+// @Summary Merge a value
+// @Description Apply a merge operand to a key using the column family's merge operator
+// @Tags Database
+// @Param cf path string true "Column Family"
+// @Param body body map[string]string true "Key and merge operand"
+// @Success 200 {object} map[string]interface{} "success response"
+// @Failure 400 {object} map[string]interface{} "bad request or no merge operator"
+// @Failure 403 {object} map[string]interface{} "read-only mode"
+// @Failure 404 {object} map[string]interface{} "column family not found"
+// @Failure 500 {object} map[string]interface{} "internal server error"
+// @Router /api/v1/cf/{cf}/merge [post]
+func (h *DatabaseHandler) MergeValue(c *gin.Context) {
+	cf := c.Param("cf")
+
+	var req struct {
+		Key     string `json:"key" binding:"required"`
+		Operand string `json:"operand" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body",
+			"message": "Both 'key' and 'operand' fields are required",
+		})
+		return
+	}
+
+	err := h.dbService.MergeValue(cf, req.Key, req.Operand)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		message := "Failed to merge value"
+
+		switch {
+		case errors.Is(err, db.ErrReadOnlyMode):
+			statusCode = http.StatusForbidden
+			message = "Database is in read-only mode"
+		case errors.Is(err, db.ErrColumnFamilyNotFound):
+			statusCode = http.StatusNotFound
+			message = "Column family not found"
+		case errors.Is(err, db.ErrNoMergeOperator):
+			statusCode = http.StatusBadRequest
+			message = "Column family has no merge operator configured"
+		}
+
+		c.JSON(statusCode, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"message": message,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Merge operand applied successfully",
+		"data": gin.H{
+			"cf":      cf,
+			"key":     req.Key,
+			"operand": req.Operand,
+		},
+	})
+}
+
 // DeleteValue handles DELETE /api/v1/cf/:cf/delete/:key
 // @Summary Delete a key
 // @Description Delete a key from a column family
@@ -154,7 +280,7 @@ func (h *DatabaseHandler) DeleteValue(c *gin.Context) {
 
 // ListColumnFamilies handles GET /api/v1/cf
 // @Summary List column families
-// @Description Get a list of all column families in the database
+// @Description Get a list of the column families in the database that the caller may read
 // @Tags Database
 // @Success 200 {object} map[string]interface{} "success response with column families list"
 // @Failure 500 {object} map[string]interface{} "internal server error"
@@ -170,6 +296,14 @@ func (h *DatabaseHandler) ListColumnFamilies(c *gin.Context) {
 		return
 	}
 
+	readable := make([]string, 0, len(cfs))
+	for _, cf := range cfs {
+		if middleware.Allowed(c, auth.RoleRead, cf) {
+			readable = append(readable, cf)
+		}
+	}
+	cfs = readable
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": gin.H{
@@ -6,6 +6,8 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"rocksdb-cli/internal/api/middleware"
+	"rocksdb-cli/internal/auth"
 	"rocksdb-cli/internal/service"
 )
 
@@ -46,6 +48,7 @@ type ConnectRequest struct {
 
 // Connect handles database connection requests
 // POST /api/v1/databases/connect
+// Requires admin on both the current and the requested database.
 func (h *DBManagerHandler) Connect(c *gin.Context) {
 	var req ConnectRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -55,6 +58,13 @@ func (h *DBManagerHandler) Connect(c *gin.Context) {
 		return
 	}
 
+	if h.manager.IsConnected() && !middleware.Authorize(c, auth.RoleAdmin, "") {
+		return
+	}
+	if !middleware.AuthorizeOn(c, auth.RoleAdmin, req.Path, "") {
+		return
+	}
+
 	// Validate path first
 	if err := h.manager.ValidatePath(req.Path); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -80,7 +90,11 @@ func (h *DBManagerHandler) Connect(c *gin.Context) {
 
 // Disconnect handles database disconnection requests
 // POST /api/v1/databases/disconnect
+// Requires admin on the current database.
 func (h *DBManagerHandler) Disconnect(c *gin.Context) {
+	if h.manager.IsConnected() && !middleware.Authorize(c, auth.RoleAdmin, "") {
+		return
+	}
 	if err := h.manager.Disconnect(); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
@@ -104,6 +118,10 @@ func (h *DBManagerHandler) GetCurrent(c *gin.Context) {
 		})
 		return
 	}
+	if !h.canSee(c, info.Path) {
+		middleware.Forbid(c, auth.RoleRead, info.Path, "")
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"connected": true,
@@ -113,6 +131,7 @@ func (h *DBManagerHandler) GetCurrent(c *gin.Context) {
 
 // ListAvailable returns list of available databases from mount points
 // GET /api/v1/databases/list
+// Only databases the caller may read are listed.
 func (h *DBManagerHandler) ListAvailable(c *gin.Context) {
 	databases, err := h.manager.ListAvailableDatabases(h.mountPoints)
 	if err != nil {
@@ -122,6 +141,14 @@ func (h *DBManagerHandler) ListAvailable(c *gin.Context) {
 		return
 	}
 
+	visible := make([]string, 0, len(databases))
+	for _, path := range databases {
+		if h.canSee(c, path) {
+			visible = append(visible, path)
+		}
+	}
+	databases = visible
+
 	c.JSON(http.StatusOK, gin.H{
 		"data": gin.H{
 			"databases":   databases,
@@ -137,6 +164,7 @@ type ValidateRequest struct {
 
 // Validate validates if a path is a valid RocksDB database
 // POST /api/v1/databases/validate
+// Requires read access to some column family of the path.
 func (h *DBManagerHandler) Validate(c *gin.Context) {
 	var req ValidateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -145,6 +173,10 @@ func (h *DBManagerHandler) Validate(c *gin.Context) {
 		})
 		return
 	}
+	if !h.canSee(c, req.Path) {
+		middleware.Forbid(c, auth.RoleRead, req.Path, "")
+		return
+	}
 
 	if err := h.manager.ValidatePath(req.Path); err != nil {
 		c.JSON(http.StatusOK, gin.H{
@@ -169,10 +201,17 @@ func (h *DBManagerHandler) GetStatus(c *gin.Context) {
 	}
 
 	if connected {
-		if info, err := h.manager.GetCurrentInfo(); err == nil {
+		if info, err := h.manager.GetCurrentInfo(); err == nil && h.canSee(c, info.Path) {
 			status["database"] = info
 		}
 	}
 
 	c.JSON(http.StatusOK, status)
 }
+
+// canSee reports whether the caller may read at least one column family of
+// the database at path
+func (h *DBManagerHandler) canSee(c *gin.Context, path string) bool {
+	p := middleware.CurrentPrincipal(c)
+	return p == nil || p.AllowsAnyCF(auth.RoleRead, path)
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/schema"
+	"rocksdb-cli/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SchemaHandler handles schema inference and checks of JSON values
+type SchemaHandler struct {
+	schemaService *service.SchemaService
+}
+
+// NewSchemaHandler creates a new SchemaHandler
+func NewSchemaHandler(schemaService *service.SchemaService) *SchemaHandler {
+	return &SchemaHandler{schemaService: schemaService}
+}
+
+// InferSchema handles GET /api/v1/cf/:cf/schema
+// @Summary Infer the JSON Schema of a column family
+// @Description Merge a uniform sample of the JSON values of a column family into one JSON Schema. Besides the standard keywords, x-presence is the share of the parent objects having a field, x-type-percent the share of each type of a union and x-source the values the schema was inferred from. Strings repeating few values become enum candidates.
+// @Tags Schema
+// @Param cf path string true "Column Family"
+// @Param prefix query string false "Only values of keys with this prefix"
+// @Param sample query int false "Number of JSON values sampled (default 1000)"
+// @Success 200 {object} map[string]interface{} "success response with the schema"
+// @Failure 400 {object} map[string]interface{} "bad request or no JSON values"
+// @Failure 404 {object} map[string]interface{} "column family not found"
+// @Failure 500 {object} map[string]interface{} "internal server error"
+// @Router /api/v1/cf/{cf}/schema [get]
+func (h *SchemaHandler) InferSchema(c *gin.Context) {
+	cf := c.Param("cf")
+	n := schema.DefaultSamples
+	if v := c.Query("sample"); v != "" {
+		var err error
+		if n, err = strconv.Atoi(v); err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "sample must be a positive integer",
+				"message": "Invalid query parameters",
+			})
+			return
+		}
+	}
+
+	inferred, err := h.schemaService.Infer(cf, c.Query("prefix"), n)
+	if err != nil {
+		schemaError(c, err, "Failed to infer schema")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"cf":     cf,
+			"schema": inferred,
+			"fields": inferred.Fields(),
+		},
+	})
+}
+
+// CheckSchemaRequest is the body of a schema check
+type CheckSchemaRequest struct {
+	Schema json.RawMessage `json:"schema" binding:"required"`
+	Prefix *string         `json:"prefix"` // the schema's x-source prefix when omitted
+	Limit  int             `json:"limit"`  // violations listed, 100 when 0
+}
+
+// CheckSchema handles POST /api/v1/cf/:cf/schema/check
+// @Summary Check the JSON values of a column family against a schema
+// @Description Check every value of a column family, or of the keys with a prefix, against a JSON Schema such as one returned by GET /cf/{cf}/schema. Lists the first violations with the key, path and reason, counts them all, and lists the fields the schema does not know with the number of values having them.
+// @Tags Schema
+// @Accept json
+// @Param cf path string true "Column Family"
+// @Param request body CheckSchemaRequest true "Schema and options"
+// @Success 200 {object} map[string]interface{} "success response with the report"
+// @Failure 400 {object} map[string]interface{} "bad request"
+// @Failure 404 {object} map[string]interface{} "column family not found"
+// @Failure 500 {object} map[string]interface{} "internal server error"
+// @Router /api/v1/cf/{cf}/schema/check [post]
+func (h *SchemaHandler) CheckSchema(c *gin.Context) {
+	cf := c.Param("cf")
+	var req CheckSchemaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body",
+			"message": "The 'schema' field is required",
+		})
+		return
+	}
+	s, err := schema.Parse(req.Schema)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"message": "Invalid schema",
+		})
+		return
+	}
+	prefix := ""
+	if req.Prefix != nil {
+		prefix = *req.Prefix
+	} else if s.Source != nil {
+		prefix = s.Source.Prefix
+	}
+
+	report, err := h.schemaService.Check(cf, prefix, s, req.Limit)
+	if err != nil {
+		schemaError(c, err, "Failed to check schema")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"cf":     cf,
+			"prefix": prefix,
+			"report": report,
+		},
+	})
+}
+
+func schemaError(c *gin.Context, err error, message string) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, schema.ErrNoJSONValues), errors.Is(err, db.ErrNotBytewise):
+		statusCode = http.StatusBadRequest
+	case errors.Is(err, db.ErrColumnFamilyNotFound):
+		statusCode = http.StatusNotFound
+	}
+	c.JSON(statusCode, gin.H{
+		"success": false,
+		"error":   err.Error(),
+		"message": message,
+	})
+}
@@ -1,9 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
+	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/keytree"
 	"rocksdb-cli/internal/service"
+	"rocksdb-cli/internal/util"
 
 	"github.com/gin-gonic/gin"
 )
@@ -71,3 +76,115 @@ func (h *StatsHandler) GetColumnFamilyStats(c *gin.Context) {
 		},
 	})
 }
+
+// GetTTLStats handles GET /api/v1/cf/:cf/stats/ttl
+// @Summary Get column family expiry statistics
+// @Description Get the expiry distribution of a column family when the database is opened in TTL mode
+// @Tags Stats
+// @Param cf path string true "Column Family"
+// @Success 200 {object} map[string]interface{} "success response with TTL stats"
+// @Failure 400 {object} map[string]interface{} "database not opened in TTL mode"
+// @Failure 404 {object} map[string]interface{} "column family not found"
+// @Failure 500 {object} map[string]interface{} "internal server error"
+// @Router /api/v1/cf/{cf}/stats/ttl [get]
+func (h *StatsHandler) GetTTLStats(c *gin.Context) {
+	cf := c.Param("cf")
+
+	stats, err := h.statsService.GetTTLStats(cf)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, db.ErrTTLModeDisabled):
+			statusCode = http.StatusBadRequest
+		case errors.Is(err, db.ErrColumnFamilyNotFound):
+			statusCode = http.StatusNotFound
+		}
+		c.JSON(statusCode, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"message": "Failed to get TTL statistics",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"cf":    cf,
+			"stats": stats,
+		},
+	})
+}
+
+// GetKeyTree handles GET /api/v1/cf/:cf/keytree
+// @Summary Get the key prefix tree of a column family
+// @Description Split the keys of a column family into a tree of prefixes at delimiters or every width bytes, with key counts, key and value bytes and the approximate size on disk of each prefix. Meant for drill-down views such as a treemap.
+// @Tags Stats
+// @Param cf path string true "Column Family"
+// @Param prefix query string false "Only keys under this prefix, the root of the tree"
+// @Param encoding query string false "Encoding of prefix: hex or base64 for binary prefixes"
+// @Param delimiters query string false "Bytes a level ends with (default :/|)"
+// @Param width query int false "Split keys every width bytes instead of at delimiters"
+// @Param depth query int false "Levels below the root (default 4)"
+// @Param max_children query int false "Children kept per node, the others are counted in one (other) node (default 100)"
+// @Success 200 {object} map[string]interface{} "success response with the tree"
+// @Failure 400 {object} map[string]interface{} "bad request"
+// @Failure 404 {object} map[string]interface{} "column family not found"
+// @Failure 500 {object} map[string]interface{} "internal server error"
+// @Router /api/v1/cf/{cf}/keytree [get]
+func (h *StatsHandler) GetKeyTree(c *gin.Context) {
+	cf := c.Param("cf")
+
+	badRequest := func(err string) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err,
+			"message": "Invalid query parameters",
+		})
+	}
+
+	prefix, err := util.DecodeInput(c.Query("prefix"), c.Query("encoding"))
+	if err != nil {
+		badRequest(err.Error())
+		return
+	}
+	opts := keytree.Options{Prefix: prefix, Delimiters: c.Query("delimiters")}
+	for _, p := range []struct {
+		name   string
+		target *int
+	}{{"width", &opts.Width}, {"depth", &opts.MaxDepth}, {"max_children", &opts.MaxChildren}} {
+		if v := c.Query(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				badRequest(p.name + " must be a non-negative integer")
+				return
+			}
+			*p.target = n
+		}
+	}
+
+	tree, err := h.statsService.GetKeyTree(cf, opts)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, db.ErrColumnFamilyNotFound):
+			statusCode = http.StatusNotFound
+		case errors.Is(err, db.ErrNotBytewise):
+			statusCode = http.StatusBadRequest
+		}
+		c.JSON(statusCode, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"message": "Failed to build key tree",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"cf":   cf,
+			"tree": tree,
+		},
+	})
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/service"
+	"rocksdb-cli/internal/timeseries"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimeSeriesHandler handles requests on column families keyed by time
+type TimeSeriesHandler struct {
+	timeSeriesService *service.TimeSeriesService
+}
+
+// NewTimeSeriesHandler creates a new TimeSeriesHandler
+func NewTimeSeriesHandler(timeSeriesService *service.TimeSeriesService) *TimeSeriesHandler {
+	return &TimeSeriesHandler{timeSeriesService: timeSeriesService}
+}
+
+// GetRange handles GET /api/v1/cf/:cf/range
+// @Summary Get the entries between two times
+// @Description Read the entries of a column family whose keys start with a time (.NET ticks, Unix s/ms/us/ns as decimal text or 8-byte big-endian, or ISO 8601 text) between from and to, to excluded. Poll with after set to the next_cursor of the last response to follow new entries.
+// @Tags TimeSeries
+// @Param cf path string true "Column Family"
+// @Param from query string false "Start time, included: RFC 3339, 2006-01-02T15:04 (UTC), a date, now or an age like 1h or 7d"
+// @Param to query string false "End time, excluded, in the same formats"
+// @Param limit query int false "Maximum number of entries (default 100, 0 for all)"
+// @Param reverse query bool false "Newest entries first"
+// @Param keys_only query bool false "Return only keys, not values"
+// @Param after query string false "Cursor to continue after (next_cursor)"
+// @Param encoding query string false "Time encoding of the keys: ticks, ns, us, ms, s or iso (detected by default)"
+// @Param prefix query string false "Key prefix the time follows"
+// @Success 200 {object} map[string]interface{} "success response with the entries"
+// @Failure 400 {object} map[string]interface{} "bad request"
+// @Failure 404 {object} map[string]interface{} "column family not found"
+// @Failure 500 {object} map[string]interface{} "internal server error"
+// @Router /api/v1/cf/{cf}/range [get]
+func (h *TimeSeriesHandler) GetRange(c *gin.Context) {
+	q, ok := h.query(c)
+	if !ok {
+		return
+	}
+	opts := timeseries.Options{
+		Limit:   100,
+		Reverse: c.Query("reverse") == "true",
+		Values:  c.Query("keys_only") != "true",
+		After:   c.Query("after"),
+	}
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			badTimeQuery(c, "limit must be a non-negative integer")
+			return
+		}
+		opts.Limit = n
+	}
+
+	page, err := h.timeSeriesService.Range(q, opts)
+	if err != nil {
+		timeSeriesError(c, err, "Failed to read range")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"cf":    q.CF,
+			"range": page,
+			"count": len(page.Entries),
+		},
+	})
+}
+
+// GetLatest handles GET /api/v1/cf/:cf/latest
+// @Summary Get the newest entries
+// @Description Read the n newest entries of a column family whose keys start with a time, newest first
+// @Tags TimeSeries
+// @Param cf path string true "Column Family"
+// @Param n query int false "Number of entries (default 10)"
+// @Param keys_only query bool false "Return only keys, not values"
+// @Param encoding query string false "Time encoding of the keys: ticks, ns, us, ms, s or iso (detected by default)"
+// @Param prefix query string false "Key prefix the time follows"
+// @Success 200 {object} map[string]interface{} "success response with the entries"
+// @Failure 400 {object} map[string]interface{} "bad request"
+// @Failure 404 {object} map[string]interface{} "column family not found"
+// @Failure 500 {object} map[string]interface{} "internal server error"
+// @Router /api/v1/cf/{cf}/latest [get]
+func (h *TimeSeriesHandler) GetLatest(c *gin.Context) {
+	n := 10
+	if v := c.Query("n"); v != "" {
+		var err error
+		if n, err = strconv.Atoi(v); err != nil || n <= 0 {
+			badTimeQuery(c, "n must be a positive integer")
+			return
+		}
+	}
+	q, ok := h.query(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.timeSeriesService.Latest(q, n, c.Query("keys_only") != "true")
+	if err != nil {
+		timeSeriesError(c, err, "Failed to read latest entries")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"cf":    q.CF,
+			"range": page,
+			"count": len(page.Entries),
+		},
+	})
+}
+
+// GetHistogram handles GET /api/v1/cf/:cf/histogram
+// @Summary Get the number of entries per time bucket
+// @Description Count the entries of a column family whose keys start with a time per bucket, empty buckets included. Buckets are aligned on multiples of their width, so 1h buckets start on the hour.
+// @Tags TimeSeries
+// @Param cf path string true "Column Family"
+// @Param bucket query string false "Bucket width: a duration such as 15m or 1h, or days such as 1d (default 1h)"
+// @Param from query string false "Start time, included"
+// @Param to query string false "End time, excluded"
+// @Param encoding query string false "Time encoding of the keys: ticks, ns, us, ms, s or iso (detected by default)"
+// @Param prefix query string false "Key prefix the time follows"
+// @Success 200 {object} map[string]interface{} "success response with the histogram"
+// @Failure 400 {object} map[string]interface{} "bad request"
+// @Failure 404 {object} map[string]interface{} "column family not found"
+// @Failure 500 {object} map[string]interface{} "internal server error"
+// @Router /api/v1/cf/{cf}/histogram [get]
+func (h *TimeSeriesHandler) GetHistogram(c *gin.Context) {
+	bucket := time.Hour
+	if v := c.Query("bucket"); v != "" {
+		var err error
+		if bucket, err = timeseries.ParseBucket(v); err != nil {
+			badTimeQuery(c, err.Error())
+			return
+		}
+	}
+	q, ok := h.query(c)
+	if !ok {
+		return
+	}
+
+	hist, err := h.timeSeriesService.Histogram(q, bucket)
+	if err != nil {
+		timeSeriesError(c, err, "Failed to build histogram")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"cf":        q.CF,
+			"histogram": hist,
+		},
+	})
+}
+
+// query reads the time bounds, encoding and prefix parameters and detects
+// the time encoding of the keys, responding with an error when it fails
+func (h *TimeSeriesHandler) query(c *gin.Context) (timeseries.Query, bool) {
+	q := timeseries.Query{CF: c.Param("cf")}
+	enc, err := timeseries.ParseEncoding(c.Query("encoding"))
+	if err != nil {
+		badTimeQuery(c, err.Error())
+		return q, false
+	}
+	now := time.Now()
+	for _, p := range []struct {
+		name   string
+		target *time.Time
+	}{{"from", &q.From}, {"to", &q.To}} {
+		if v := c.Query(p.name); v != "" {
+			if *p.target, err = timeseries.ParseTime(v, now); err != nil {
+				badTimeQuery(c, p.name+": "+err.Error())
+				return q, false
+			}
+		}
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		badTimeQuery(c, "from must be before to")
+		return q, false
+	}
+
+	q.Codec, err = h.timeSeriesService.Detect(q.CF, c.Query("prefix"), enc)
+	if err != nil {
+		// Keys without a time are the caller's choice of column family
+		statusCode := http.StatusBadRequest
+		if errors.Is(err, db.ErrColumnFamilyNotFound) {
+			statusCode = http.StatusNotFound
+		}
+		c.JSON(statusCode, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"message": "Cannot detect the time encoding of the keys",
+		})
+		return q, false
+	}
+	return q, true
+}
+
+func badTimeQuery(c *gin.Context, err string) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"success": false,
+		"error":   err,
+		"message": "Invalid query parameters",
+	})
+}
+
+func timeSeriesError(c *gin.Context, err error, message string) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, timeseries.ErrInvalidCursor), errors.Is(err, db.ErrNotBytewise):
+		statusCode = http.StatusBadRequest
+	case errors.Is(err, db.ErrColumnFamilyNotFound):
+		statusCode = http.StatusNotFound
+	}
+	c.JSON(statusCode, gin.H{
+		"success": false,
+		"error":   err.Error(),
+		"message": message,
+	})
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"rocksdb-cli/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	principalKey = "auth.principal"
+	databaseKey  = "auth.database"
+)
+
+// Auth returns a middleware that authenticates every request with m and
+// stores the caller in the context. A nil manager disables authentication,
+// in which case every authorization check passes.
+func Auth(m *auth.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if m == nil {
+			c.Next()
+			return
+		}
+
+		p, err := m.Authenticate(c.Request)
+		if err != nil {
+			c.Header("WWW-Authenticate", m.Challenge())
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   err.Error(),
+				"message": "Authentication required",
+			})
+			return
+		}
+		c.Set(principalKey, p)
+		c.Next()
+	}
+}
+
+// Database returns a middleware that records the path of the database a
+// request operates on, for the authorization checks below
+func Database(path func() string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(databaseKey, path())
+		c.Next()
+	}
+}
+
+// CurrentPrincipal returns the authenticated caller, or nil when
+// authentication is disabled
+func CurrentPrincipal(c *gin.Context) *auth.Principal {
+	if v, ok := c.Get(principalKey); ok {
+		p, _ := v.(*auth.Principal)
+		return p
+	}
+	return nil
+}
+
+// DatabasePath returns the path recorded by Database
+func DatabasePath(c *gin.Context) string {
+	return c.GetString(databaseKey)
+}
+
+// Allowed reports whether the caller has role on column family cf of the
+// request's database; an empty cf means the whole database
+func Allowed(c *gin.Context, role auth.Role, cf string) bool {
+	return AllowedOn(c, role, DatabasePath(c), cf)
+}
+
+// AllowedOn is Allowed for an explicit database path
+func AllowedOn(c *gin.Context, role auth.Role, database, cf string) bool {
+	if _, enabled := c.Get(principalKey); !enabled {
+		return true
+	}
+	return CurrentPrincipal(c).Allows(role, database, cf)
+}
+
+// Authorize checks Allowed and responds with 403 Forbidden when it fails
+func Authorize(c *gin.Context, role auth.Role, cf string) bool {
+	return AuthorizeOn(c, role, DatabasePath(c), cf)
+}
+
+// AuthorizeOn is Authorize for an explicit database path
+func AuthorizeOn(c *gin.Context, role auth.Role, database, cf string) bool {
+	if AllowedOn(c, role, database, cf) {
+		return true
+	}
+	Forbid(c, role, database, cf)
+	return false
+}
+
+// Forbid responds with 403 Forbidden for a denied role on database and cf
+func Forbid(c *gin.Context, role auth.Role, database, cf string) {
+	name := "anonymous"
+	if p := CurrentPrincipal(c); p != nil {
+		name = p.Name
+	}
+	target := "database " + database
+	if cf != "" {
+		target = fmt.Sprintf("column family %s of %s", cf, target)
+	}
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"success": false,
+		"error":   fmt.Sprintf("%s access to %s denied for %s", role, target, name),
+		"message": "Forbidden",
+	})
+}
+
+// RequireRole returns a middleware that authorizes role on the column family
+// named by the :cf route parameter, or on the whole database for routes
+// without one
+func RequireRole(role auth.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Authorize(c, role, c.Param("cf")) {
+			return
+		}
+		c.Next()
+	}
+}
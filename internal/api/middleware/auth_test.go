@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"rocksdb-cli/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAuthRouter(t *testing.T, m *auth.Manager) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	api := r.Group("")
+	api.Use(Auth(m))
+	api.Use(Database(func() string { return "/data/prod" }))
+	api.GET("/cf/:cf/get/:key", RequireRole(auth.RoleRead), func(c *gin.Context) { c.Status(http.StatusOK) })
+	api.POST("/cf/:cf/put", RequireRole(auth.RoleWrite), func(c *gin.Context) { c.Status(http.StatusOK) })
+	api.GET("/stats", RequireRole(auth.RoleRead), func(c *gin.Context) { c.Status(http.StatusOK) })
+	api.POST("/connect", func(c *gin.Context) {
+		if AuthorizeOn(c, auth.RoleAdmin, c.Query("path"), "") {
+			c.Status(http.StatusOK)
+		}
+	})
+	return r
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	m, err := auth.New(&auth.Config{
+		Roles: map[string][]auth.Grant{
+			"ops":    {{Role: auth.RoleAdmin}},
+			"ingest": {{Role: auth.RoleWrite, Databases: []string{"/data/prod"}, ColumnFamilies: []string{"events"}}},
+		},
+		Tokens: []auth.TokenConfig{
+			{Name: "ops", Token: "ops-token", Roles: []string{"ops"}},
+			{Name: "ingest", Token: "ingest-token", Roles: []string{"ingest"}},
+		},
+	})
+	require.NoError(t, err)
+	r := setupAuthRouter(t, m)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"health is public", "GET", "/health", "", http.StatusOK},
+		{"missing credentials", "GET", "/cf/events/get/k", "", http.StatusUnauthorized},
+		{"bad token", "GET", "/cf/events/get/k", "nope", http.StatusUnauthorized},
+		{"ingest reads events", "GET", "/cf/events/get/k", "ingest-token", http.StatusOK},
+		{"ingest writes events", "POST", "/cf/events/put", "ingest-token", http.StatusOK},
+		{"ingest cannot write users", "POST", "/cf/users/put", "ingest-token", http.StatusForbidden},
+		{"ingest cannot read whole db", "GET", "/stats", "ingest-token", http.StatusForbidden},
+		{"ingest cannot connect", "POST", "/connect?path=/data/prod", "ingest-token", http.StatusForbidden},
+		{"ops reads whole db", "GET", "/stats", "ops-token", http.StatusOK},
+		{"ops connects anywhere", "POST", "/connect?path=/data/other", "ops-token", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code)
+			if tt.want == http.StatusUnauthorized {
+				assert.Equal(t, `Bearer realm="rocksdb-cli"`, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareDisabled(t *testing.T) {
+	r := setupAuthRouter(t, nil)
+
+	for _, path := range []string{"/cf/users/put", "/connect?path=/anywhere"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, path)
+	}
+}
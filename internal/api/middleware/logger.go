@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Logger returns a middleware that logs each request with its status,
+// latency and, when authentication is enabled, the caller
+func Logger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path
+		if raw := c.Request.URL.RawQuery; raw != "" {
+			path += "?" + raw
+		}
+
+		c.Next()
+
+		user := "-"
+		if p := CurrentPrincipal(c); p != nil {
+			user = p.Name
+		}
+		log.Printf("[API] %3d | %13v | %15s | %-20s | %-7s %s",
+			c.Writer.Status(),
+			time.Since(start),
+			c.ClientIP(),
+			user,
+			c.Request.Method,
+			path,
+		)
+	}
+}
@@ -7,6 +7,8 @@ import (
 
 	"rocksdb-cli/internal/api/handlers"
 	"rocksdb-cli/internal/api/middleware"
+	"rocksdb-cli/internal/audit"
+	"rocksdb-cli/internal/auth"
 	"rocksdb-cli/internal/db"
 	"rocksdb-cli/internal/service"
 	"rocksdb-cli/internal/webui"
@@ -14,8 +16,65 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// Option configures optional router features
+type Option func(*routerOptions)
+
+type routerOptions struct {
+	auth         *auth.Manager
+	databasePath string
+	auditLog     *audit.Log
+}
+
+// WithAuth requires every API request except /api/v1/health to authenticate
+// with m, and enforces the caller's roles on each route
+func WithAuth(m *auth.Manager) Option {
+	return func(o *routerOptions) {
+		o.auth = m
+	}
+}
+
+// WithDatabasePath sets the database path that SetupRouter matches role
+// grants against
+func WithDatabasePath(path string) Option {
+	return func(o *routerOptions) {
+		o.databasePath = path
+	}
+}
+
+// WithAuditLog records every write made through the API in l, on behalf of
+// the authenticated caller, and serves the log at GET /api/v1/audit
+func WithAuditLog(l *audit.Log) Option {
+	return func(o *routerOptions) {
+		o.auditLog = l
+	}
+}
+
+// auditedDB returns database wrapped to record the writes of the request's caller
+func (o *routerOptions) auditedDB(c *gin.Context, database db.KeyValueDB) db.KeyValueDB {
+	actor := "anonymous"
+	if p := middleware.CurrentPrincipal(c); p != nil {
+		actor = p.Name
+	}
+	return service.NewAuditedDB(database, o.auditLog, service.AuditSource{
+		Actor:     actor,
+		Remote:    c.ClientIP(),
+		Interface: audit.InterfaceREST,
+		Database:  middleware.DatabasePath(c),
+	})
+}
+
+func applyOptions(opts []Option) routerOptions {
+	var o routerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
 // SetupRouter configures and returns a Gin router with all API routes
-func SetupRouter(database db.KeyValueDB) *gin.Engine {
+func SetupRouter(database db.KeyValueDB, opts ...Option) *gin.Engine {
+	o := applyOptions(opts)
+
 	// Set Gin to release mode in production
 	// gin.SetMode(gin.ReleaseMode)
 
@@ -37,6 +96,18 @@ func SetupRouter(database db.KeyValueDB) *gin.Engine {
 	scanHandler := handlers.NewScanHandler(scanService)
 	searchHandler := handlers.NewSearchHandler(searchService)
 	statsHandler := handlers.NewStatsHandler(statsService)
+	timeSeriesHandler := handlers.NewTimeSeriesHandler(service.NewTimeSeriesService(database))
+	schemaHandler := handlers.NewSchemaHandler(service.NewSchemaService(database))
+	auditHandler := handlers.NewAuditHandler(o.auditLog)
+
+	// Writes get a handler bound to the caller for the audit log
+	writeHandler := func(c *gin.Context) *handlers.DatabaseHandler {
+		return handlers.NewDatabaseHandler(service.NewDatabaseService(o.auditedDB(c, database)))
+	}
+
+	read := middleware.RequireRole(auth.RoleRead)
+	write := middleware.RequireRole(auth.RoleWrite)
+	admin := middleware.RequireRole(auth.RoleAdmin)
 
 	// API v1 routes
 	v1 := r.Group("/api/v1")
@@ -49,29 +120,48 @@ func SetupRouter(database db.KeyValueDB) *gin.Engine {
 			})
 		})
 
+		// Everything else requires authentication when it is configured
+		authed := v1.Group("")
+		authed.Use(middleware.Auth(o.auth))
+		authed.Use(middleware.Database(func() string { return o.databasePath }))
+
 		// Database info routes
-		v1.GET("/cf", dbHandler.ListColumnFamilies)
-		v1.GET("/stats", statsHandler.GetDatabaseStats)
+		authed.GET("/cf", dbHandler.ListColumnFamilies)
+		authed.GET("/stats", read, statsHandler.GetDatabaseStats)
+		authed.GET("/audit", admin, auditHandler.Query)
 
 		// Column family routes
-		cf := v1.Group("/cf/:cf")
+		cf := authed.Group("/cf/:cf")
 		{
 			// Basic operations
-			cf.GET("/get/:key", dbHandler.GetValue)
-			cf.POST("/put", dbHandler.PutValue)
-			cf.DELETE("/delete/:key", dbHandler.DeleteValue)
-			cf.GET("/last", dbHandler.GetLastEntry)
+			cf.GET("/get/:key", read, dbHandler.GetValue)
+			cf.POST("/put", write, func(c *gin.Context) { writeHandler(c).PutValue(c) })
+			cf.POST("/merge", write, func(c *gin.Context) { writeHandler(c).MergeValue(c) })
+			cf.DELETE("/delete/:key", write, func(c *gin.Context) { writeHandler(c).DeleteValue(c) })
+			cf.GET("/last", read, dbHandler.GetLastEntry)
+			cf.GET("/inspect/:key", read, dbHandler.InspectValue)
 
 			// Scan operations
-			cf.POST("/scan", scanHandler.Scan)
-			cf.POST("/prefix", scanHandler.PrefixScan)
+			cf.POST("/scan", read, scanHandler.Scan)
+			cf.POST("/prefix", read, scanHandler.PrefixScan)
 
 			// Search operations
-			cf.POST("/search", searchHandler.Search)
-			cf.POST("/jsonquery", searchHandler.JSONQuery)
+			cf.POST("/search", read, searchHandler.Search)
+			cf.POST("/jsonquery", read, searchHandler.JSONQuery)
 
 			// Stats
-			cf.GET("/stats", statsHandler.GetColumnFamilyStats)
+			cf.GET("/stats", read, statsHandler.GetColumnFamilyStats)
+			cf.GET("/stats/ttl", read, statsHandler.GetTTLStats)
+			cf.GET("/keytree", read, statsHandler.GetKeyTree)
+
+			// Time series
+			cf.GET("/range", read, timeSeriesHandler.GetRange)
+			cf.GET("/latest", read, timeSeriesHandler.GetLatest)
+			cf.GET("/histogram", read, timeSeriesHandler.GetHistogram)
+
+			// Schema of JSON values
+			cf.GET("/schema", read, schemaHandler.InferSchema)
+			cf.POST("/schema/check", read, schemaHandler.CheckSchema)
 		}
 	}
 
@@ -88,7 +178,9 @@ func SetupRouter(database db.KeyValueDB) *gin.Engine {
 }
 
 // SetupRouterWithUI configures and returns a Gin router with API routes and embedded Web UI
-func SetupRouterWithUI(dbManager *service.DBManager) *gin.Engine {
+func SetupRouterWithUI(dbManager *service.DBManager, opts ...Option) *gin.Engine {
+	o := applyOptions(opts)
+
 	r := gin.New()
 
 	// Global middleware
@@ -99,6 +191,18 @@ func SetupRouterWithUI(dbManager *service.DBManager) *gin.Engine {
 	// Create database manager handler
 	dbManagerHandler := handlers.NewDBManagerHandler(dbManager)
 
+	auditHandler := handlers.NewAuditHandler(o.auditLog)
+
+	read := middleware.RequireRole(auth.RoleRead)
+	write := middleware.RequireRole(auth.RoleWrite)
+	admin := middleware.RequireRole(auth.RoleAdmin)
+	currentPath := func() string {
+		if info, err := dbManager.GetCurrentInfo(); err == nil && info != nil {
+			return info.Path
+		}
+		return ""
+	}
+
 	// API v1 routes
 	v1 := r.Group("/api/v1")
 	{
@@ -120,8 +224,13 @@ func SetupRouterWithUI(dbManager *service.DBManager) *gin.Engine {
 			c.JSON(200, response)
 		})
 
+		// Everything else requires authentication when it is configured
+		authed := v1.Group("")
+		authed.Use(middleware.Auth(o.auth))
+		authed.Use(middleware.Database(currentPath))
+
 		// Database management routes
-		databases := v1.Group("/databases")
+		databases := authed.Group("/databases")
 		{
 			databases.POST("/connect", dbManagerHandler.Connect)
 			databases.POST("/disconnect", dbManagerHandler.Disconnect)
@@ -131,8 +240,11 @@ func SetupRouterWithUI(dbManager *service.DBManager) *gin.Engine {
 			databases.GET("/status", dbManagerHandler.GetStatus)
 		}
 
+		// Audit log of writes made through the API
+		authed.GET("/audit", admin, auditHandler.Query)
+
 		// Tools routes (no database connection required)
-		tools := v1.Group("/tools")
+		tools := authed.Group("/tools")
 		{
 			ticksHandler := handlers.NewTicksHandler()
 			ticks := tools.Group("/ticks")
@@ -144,7 +256,7 @@ func SetupRouterWithUI(dbManager *service.DBManager) *gin.Engine {
 
 		// Database operation routes (require active connection)
 		// Use middleware to check connection
-		connected := v1.Group("")
+		connected := authed.Group("")
 		connected.Use(func(c *gin.Context) {
 			if !dbManager.IsConnected() {
 				c.JSON(http.StatusServiceUnavailable, gin.H{
@@ -169,7 +281,7 @@ func SetupRouterWithUI(dbManager *service.DBManager) *gin.Engine {
 				dbHandler.ListColumnFamilies(c)
 			})
 
-			connected.GET("/stats", func(c *gin.Context) {
+			connected.GET("/stats", read, func(c *gin.Context) {
 				rdb, _ := getCurrentDB() // Already validated by middleware
 				statsService := service.NewStatsService(rdb)
 				statsHandler := handlers.NewStatsHandler(statsService)
@@ -180,39 +292,51 @@ func SetupRouterWithUI(dbManager *service.DBManager) *gin.Engine {
 			cf := connected.Group("/cf/:cf")
 			{
 				// Basic operations
-				cf.GET("/get/:key", func(c *gin.Context) {
+				cf.GET("/get/:key", read, func(c *gin.Context) {
 					rdb, _ := getCurrentDB()
 					dbService := service.NewDatabaseService(rdb)
 					dbHandler := handlers.NewDatabaseHandler(dbService)
 					dbHandler.GetValue(c)
 				})
-				cf.POST("/put", func(c *gin.Context) {
+				cf.POST("/put", write, func(c *gin.Context) {
 					rdb, _ := getCurrentDB()
-					dbService := service.NewDatabaseService(rdb)
+					dbService := service.NewDatabaseService(o.auditedDB(c, rdb))
 					dbHandler := handlers.NewDatabaseHandler(dbService)
 					dbHandler.PutValue(c)
 				})
-				cf.DELETE("/delete/:key", func(c *gin.Context) {
+				cf.POST("/merge", write, func(c *gin.Context) {
 					rdb, _ := getCurrentDB()
-					dbService := service.NewDatabaseService(rdb)
+					dbService := service.NewDatabaseService(o.auditedDB(c, rdb))
+					dbHandler := handlers.NewDatabaseHandler(dbService)
+					dbHandler.MergeValue(c)
+				})
+				cf.DELETE("/delete/:key", write, func(c *gin.Context) {
+					rdb, _ := getCurrentDB()
+					dbService := service.NewDatabaseService(o.auditedDB(c, rdb))
 					dbHandler := handlers.NewDatabaseHandler(dbService)
 					dbHandler.DeleteValue(c)
 				})
-				cf.GET("/last", func(c *gin.Context) {
+				cf.GET("/last", read, func(c *gin.Context) {
 					rdb, _ := getCurrentDB()
 					dbService := service.NewDatabaseService(rdb)
 					dbHandler := handlers.NewDatabaseHandler(dbService)
 					dbHandler.GetLastEntry(c)
 				})
+				cf.GET("/inspect/:key", read, func(c *gin.Context) {
+					rdb, _ := getCurrentDB()
+					dbService := service.NewDatabaseService(rdb)
+					dbHandler := handlers.NewDatabaseHandler(dbService)
+					dbHandler.InspectValue(c)
+				})
 
 				// Scan operations
-				cf.POST("/scan", func(c *gin.Context) {
+				cf.POST("/scan", read, func(c *gin.Context) {
 					rdb, _ := getCurrentDB()
 					scanService := service.NewScanService(rdb)
 					scanHandler := handlers.NewScanHandler(scanService)
 					scanHandler.Scan(c)
 				})
-				cf.POST("/prefix", func(c *gin.Context) {
+				cf.POST("/prefix", read, func(c *gin.Context) {
 					rdb, _ := getCurrentDB()
 					scanService := service.NewScanService(rdb)
 					scanHandler := handlers.NewScanHandler(scanService)
@@ -220,13 +344,13 @@ func SetupRouterWithUI(dbManager *service.DBManager) *gin.Engine {
 				})
 
 				// Search operations
-				cf.POST("/search", func(c *gin.Context) {
+				cf.POST("/search", read, func(c *gin.Context) {
 					rdb, _ := getCurrentDB()
 					searchService := service.NewSearchService(rdb)
 					searchHandler := handlers.NewSearchHandler(searchService)
 					searchHandler.Search(c)
 				})
-				cf.POST("/jsonquery", func(c *gin.Context) {
+				cf.POST("/jsonquery", read, func(c *gin.Context) {
 					rdb, _ := getCurrentDB()
 					searchService := service.NewSearchService(rdb)
 					searchHandler := handlers.NewSearchHandler(searchService)
@@ -234,12 +358,41 @@ func SetupRouterWithUI(dbManager *service.DBManager) *gin.Engine {
 				})
 
 				// Stats
-				cf.GET("/stats", func(c *gin.Context) {
+				cf.GET("/stats", read, func(c *gin.Context) {
 					rdb, _ := getCurrentDB()
 					statsService := service.NewStatsService(rdb)
 					statsHandler := handlers.NewStatsHandler(statsService)
 					statsHandler.GetColumnFamilyStats(c)
 				})
+				cf.GET("/stats/ttl", read, func(c *gin.Context) {
+					rdb, _ := getCurrentDB()
+					statsService := service.NewStatsService(rdb)
+					statsHandler := handlers.NewStatsHandler(statsService)
+					statsHandler.GetTTLStats(c)
+				})
+				cf.GET("/keytree", read, func(c *gin.Context) {
+					rdb, _ := getCurrentDB()
+					statsService := service.NewStatsService(rdb)
+					statsHandler := handlers.NewStatsHandler(statsService)
+					statsHandler.GetKeyTree(c)
+				})
+
+				// Time series
+				timeSeriesHandler := func() *handlers.TimeSeriesHandler {
+					rdb, _ := getCurrentDB()
+					return handlers.NewTimeSeriesHandler(service.NewTimeSeriesService(rdb))
+				}
+				cf.GET("/range", read, func(c *gin.Context) { timeSeriesHandler().GetRange(c) })
+				cf.GET("/latest", read, func(c *gin.Context) { timeSeriesHandler().GetLatest(c) })
+				cf.GET("/histogram", read, func(c *gin.Context) { timeSeriesHandler().GetHistogram(c) })
+
+				// Schema of JSON values
+				schemaHandler := func() *handlers.SchemaHandler {
+					rdb, _ := getCurrentDB()
+					return handlers.NewSchemaHandler(service.NewSchemaService(rdb))
+				}
+				cf.GET("/schema", read, func(c *gin.Context) { schemaHandler().InferSchema(c) })
+				cf.POST("/schema/check", read, func(c *gin.Context) { schemaHandler().CheckSchema(c) })
 			}
 		}
 	}
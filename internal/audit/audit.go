@@ -0,0 +1,281 @@
+// Package audit records mutating database operations from every interface
+// (CLI, REPL, REST, MCP, AI agent and transforms) in one queryable trail.
+// Events go to a rotating JSONL file or to a column family of the database.
+package audit
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Interface identifies where an operation came from
+type Interface string
+
+const (
+	InterfaceCLI       Interface = "cli"
+	InterfaceREPL      Interface = "repl"
+	InterfaceTUI       Interface = "tui"
+	InterfaceREST      Interface = "rest"
+	InterfaceMCP       Interface = "mcp"
+	InterfaceAI        Interface = "ai"
+	InterfaceTransform Interface = "transform"
+)
+
+// Operations recorded by the audit log
+const (
+	OpPut      = "put"
+	OpMerge    = "merge"
+	OpCreateCF = "createcf"
+	OpDropCF   = "dropcf"
+)
+
+// Results recorded by the audit log
+const (
+	ResultOK    = "ok"
+	ResultError = "error"
+)
+
+// Event is one audited operation. Depending on the configured value policy
+// the old and new values are recorded as SHA-256 hashes, as (redacted) plain
+// text, or not at all. For merges the new value is the merge operand.
+type Event struct {
+	ID        string            `json:"id"`
+	Time      time.Time         `json:"time"`
+	Actor     string            `json:"actor"`
+	Remote    string            `json:"remote,omitempty"`
+	Interface Interface         `json:"interface"`
+	Operation string            `json:"operation"`
+	Database  string            `json:"database,omitempty"`
+	CF        string            `json:"cf,omitempty"`
+	Key       string            `json:"key,omitempty"`
+	OldHash   string            `json:"old_hash,omitempty"`
+	NewHash   string            `json:"new_hash,omitempty"`
+	OldValue  *string           `json:"old_value,omitempty"`
+	NewValue  *string           `json:"new_value,omitempty"`
+	Result    string            `json:"result"`
+	Error     string            `json:"error,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// Values carries the raw values of a change. Nil means there was no value,
+// e.g. no previous value for a new key.
+type Values struct {
+	Old *string
+	New *string
+}
+
+// Filter selects events in Query. Empty fields match everything.
+type Filter struct {
+	Since     time.Time
+	Until     time.Time
+	Actor     string
+	Interface Interface
+	Operation string
+	CF        string
+	Key       string
+	Result    string
+	Limit     int // most recent events to return, default 100
+}
+
+func (f *Filter) match(e *Event) bool {
+	switch {
+	case !f.Since.IsZero() && e.Time.Before(f.Since),
+		!f.Until.IsZero() && !e.Time.Before(f.Until),
+		f.Actor != "" && e.Actor != f.Actor,
+		f.Interface != "" && e.Interface != f.Interface,
+		f.Operation != "" && e.Operation != f.Operation,
+		f.CF != "" && e.CF != f.CF,
+		f.Key != "" && e.Key != f.Key,
+		f.Result != "" && e.Result != f.Result:
+		return false
+	}
+	return true
+}
+
+// Value policies for Config.Values
+const (
+	ValuesHash  = "hash"  // record SHA-256 hashes of values (default)
+	ValuesPlain = "plain" // record hashes and values, with RedactFields masked
+	ValuesOmit  = "omit"  // record nothing about values
+)
+
+// Config configures the audit log. Exactly one of File and ColumnFamily
+// selects where events are stored.
+type Config struct {
+	File       string `yaml:"file,omitempty" json:"file,omitempty"`
+	MaxSizeMB  int    `yaml:"max_size_mb,omitempty" json:"max_size_mb,omitempty"` // rotate the file past this size, default 100
+	MaxBackups int    `yaml:"max_backups,omitempty" json:"max_backups,omitempty"` // rotated files to keep, default 5
+
+	// ColumnFamily stores events in this column family of the audited
+	// database, which must be writable to record events
+	ColumnFamily string `yaml:"column_family,omitempty" json:"column_family,omitempty"`
+
+	Values               string   `yaml:"values,omitempty" json:"values,omitempty"`                                 // hash, plain or omit
+	RedactFields         []string `yaml:"redact_fields,omitempty" json:"redact_fields,omitempty"`                   // JSON fields masked in plain values
+	RedactColumnFamilies []string `yaml:"redact_column_families,omitempty" json:"redact_column_families,omitempty"` // CF globs whose values are never recorded
+}
+
+// Enabled reports whether the config selects a destination
+func (c *Config) Enabled() bool {
+	return c != nil && (c.File != "" || c.ColumnFamily != "")
+}
+
+// eventSink stores and reads back events
+type eventSink interface {
+	Write(e *Event) error
+	// Read returns the events matching f, oldest first. It may stop after
+	// the f.Limit most recent ones.
+	Read(f Filter) ([]Event, error)
+	Close() error
+}
+
+// Log records audit events. It is safe for concurrent use.
+type Log struct {
+	mu       sync.Mutex
+	sink     eventSink
+	redactor *redactor
+	cf       string
+	lastID   string
+	seq      int
+}
+
+// New opens the audit log described by cfg. store is the database that holds
+// the audit column family; it is only used when cfg.ColumnFamily is set.
+func New(cfg Config, store Store) (*Log, error) {
+	r, err := newRedactor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var sink eventSink
+	switch {
+	case cfg.File != "" && cfg.ColumnFamily != "":
+		return nil, errors.New("audit: set either file or column_family, not both")
+	case cfg.File != "":
+		sink, err = openFileSink(cfg.File, cfg.MaxSizeMB, cfg.MaxBackups)
+	case cfg.ColumnFamily != "":
+		if store == nil {
+			return nil, errors.New("audit: column_family requires a database")
+		}
+		sink, err = newCFSink(store, cfg.ColumnFamily)
+	default:
+		return nil, errors.New("audit: no file or column_family configured")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Log{sink: sink, redactor: r, cf: cfg.ColumnFamily}, nil
+}
+
+// ColumnFamily returns the column family holding the events, or "" when
+// they are stored in a file
+func (l *Log) ColumnFamily() string {
+	return l.cf
+}
+
+// Record stores e after filling in its ID and time and applying the value
+// policy to v
+func (l *Log) Record(e Event, v Values) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Time = e.Time.UTC()
+	e.ID = l.nextID(e.Time)
+	if e.Result == "" {
+		e.Result = ResultOK
+	}
+	e.Key = printable(e.Key)
+	l.redactor.apply(&e, v)
+
+	if err := l.sink.Write(&e); err != nil {
+		return fmt.Errorf("failed to write audit event: %w", err)
+	}
+	return nil
+}
+
+// nextID returns a lexically time-ordered event ID
+func (l *Log) nextID(t time.Time) string {
+	base := idTime(t)
+	if base == l.lastID {
+		l.seq++
+	} else {
+		l.lastID, l.seq = base, 0
+	}
+	return fmt.Sprintf("%s-%04d", base, l.seq)
+}
+
+// Query returns the most recent events matching f, oldest first
+func (l *Log) Query(f Filter) ([]Event, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if f.Limit <= 0 {
+		f.Limit = 100
+	}
+	events, err := l.sink.Read(f)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) > f.Limit {
+		events = events[len(events)-f.Limit:]
+	}
+	return events, nil
+}
+
+// Close closes the underlying sink
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sink.Close()
+}
+
+// ParseTime parses a filter bound: an RFC 3339 time, a date (2006-01-02), or
+// an age such as "90m", "24h" or "7d" counted back from now
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (want RFC 3339, YYYY-MM-DD or an age like 24h or 7d)", s)
+}
+
+// printable hex-encodes keys that are not valid UTF-8 so they survive JSON
+func printable(key string) string {
+	if utf8.ValidString(key) {
+		return key
+	}
+	return "0x" + hex.EncodeToString([]byte(key))
+}
+
+// LocalActor names the operating system user running this process, for
+// operations from local interfaces
+func LocalActor() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "unknown"
+}
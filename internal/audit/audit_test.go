@@ -0,0 +1,250 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func strPtr(s string) *string { return &s }
+
+// memStore is an in-memory Store
+type memStore struct {
+	readOnly bool
+	data     map[string]map[string]string
+	scanned  int // keys returned by scans
+}
+
+func newMemStore() *memStore {
+	return &memStore{data: map[string]map[string]string{"default": {}}}
+}
+
+func (m *memStore) ListCFs() ([]string, error) {
+	var cfs []string
+	for cf := range m.data {
+		cfs = append(cfs, cf)
+	}
+	return cfs, nil
+}
+
+func (m *memStore) CreateCF(cf string) error {
+	m.data[cf] = map[string]string{}
+	return nil
+}
+
+func (m *memStore) PutCF(cf, key, value string) error {
+	m.data[cf][key] = value
+	return nil
+}
+
+func (m *memStore) ScanCFReverse(cf string, start, end []byte, limit int) (Page, error) {
+	var keys []string
+	for k := range m.data[cf] {
+		if (start == nil || k >= string(start)) && (end == nil || k < string(end)) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	page := Page{Results: map[string]string{}}
+	if limit > 0 && len(keys) > limit {
+		keys, page.HasMore = keys[:limit], true
+	}
+	for _, k := range keys {
+		page.Results[k] = m.data[cf][k]
+	}
+	m.scanned += len(keys)
+	return page, nil
+}
+
+func (m *memStore) IsReadOnly() bool { return m.readOnly }
+
+func TestFileLogRecordAndQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	l, err := New(Config{File: path}, nil)
+	require.NoError(t, err)
+	defer l.Close()
+
+	require.NoError(t, l.Record(Event{Actor: "alice", Interface: InterfaceCLI, Operation: OpPut, CF: "users", Key: "u1"},
+		Values{New: strPtr("v1")}))
+	require.NoError(t, l.Record(Event{Actor: "bob", Interface: InterfaceREST, Operation: OpPut, CF: "users", Key: "u1"},
+		Values{Old: strPtr("v1"), New: strPtr("v2")}))
+	require.NoError(t, l.Record(Event{Actor: "bob", Interface: InterfaceMCP, Operation: OpDropCF, CF: "tmp",
+		Result: ResultError, Error: "read-only"}, Values{}))
+	require.NoError(t, l.Record(Event{Actor: "alice", Interface: InterfaceCLI, Operation: OpPut, CF: "bin", Key: "\xff\x00"}, Values{}))
+
+	all, err := l.Query(Filter{})
+	require.NoError(t, err)
+	require.Len(t, all, 4)
+	assert.True(t, all[0].ID < all[1].ID, "IDs are time ordered")
+	assert.Empty(t, all[0].OldHash)
+	assert.Equal(t, all[0].NewHash, all[1].OldHash, "hash of the same value matches")
+	assert.Nil(t, all[1].NewValue, "hash policy records no plain values")
+	assert.Equal(t, "0xff00", all[3].Key)
+
+	bob, err := l.Query(Filter{Actor: "bob"})
+	require.NoError(t, err)
+	assert.Len(t, bob, 2)
+
+	failed, err := l.Query(Filter{Result: ResultError})
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.Equal(t, OpDropCF, failed[0].Operation)
+
+	latest, err := l.Query(Filter{CF: "users", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, latest, 1)
+	assert.Equal(t, "bob", latest[0].Actor)
+
+	future, err := l.Query(Filter{Since: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, future)
+}
+
+func TestFileLogRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := New(Config{File: path, MaxSizeMB: 1, MaxBackups: 2, Values: ValuesPlain}, nil)
+	require.NoError(t, err)
+	defer l.Close()
+
+	big := strings.Repeat("x", 300<<10)
+	for i := 0; i < 12; i++ {
+		require.NoError(t, l.Record(Event{Actor: "a", Operation: OpPut, CF: "c", Key: "k"}, Values{New: &big}))
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		require.NoError(t, err, name)
+		assert.LessOrEqual(t, info.Size(), int64(1<<20))
+	}
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err), "only max_backups files are kept")
+
+	events, err := l.Query(Filter{Limit: 1000})
+	require.NoError(t, err)
+	assert.True(t, len(events) >= 6 && len(events) < 12, "got %d events", len(events))
+}
+
+func TestRedaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := New(Config{
+		File:                 path,
+		Values:               ValuesPlain,
+		RedactFields:         []string{"password", "Token"},
+		RedactColumnFamilies: []string{"secret*"},
+	}, nil)
+	require.NoError(t, err)
+	defer l.Close()
+
+	value := `{"user":"alice","password":"hunter2","nested":[{"token":"abc"}]}`
+	require.NoError(t, l.Record(Event{Operation: OpPut, CF: "users", Key: "k"}, Values{New: &value}))
+	require.NoError(t, l.Record(Event{Operation: OpPut, CF: "users", Key: "plain"}, Values{New: strPtr("not json")}))
+	require.NoError(t, l.Record(Event{Operation: OpPut, CF: "secrets", Key: "k"}, Values{New: &value}))
+
+	events, err := l.Query(Filter{})
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	require.NotNil(t, events[0].NewValue)
+	assert.NotContains(t, *events[0].NewValue, "hunter2")
+	assert.NotContains(t, *events[0].NewValue, "abc")
+	assert.Contains(t, *events[0].NewValue, `"user":"alice"`)
+	assert.NotEmpty(t, events[0].NewHash, "hash is of the original value")
+	assert.Equal(t, "not json", *events[1].NewValue)
+	assert.Nil(t, events[2].NewValue)
+	assert.Empty(t, events[2].NewHash)
+
+	_, err = New(Config{File: path, Values: "everything"}, nil)
+	assert.Error(t, err)
+}
+
+func TestColumnFamilyLog(t *testing.T) {
+	store := newMemStore()
+	l, err := New(Config{ColumnFamily: "__audit__"}, store)
+	require.NoError(t, err)
+
+	require.NoError(t, l.Record(Event{Actor: "alice", Operation: OpCreateCF, CF: "users"}, Values{}))
+	require.NoError(t, l.Record(Event{Actor: "alice", Operation: OpPut, CF: "users", Key: "k"}, Values{New: strPtr("v")}))
+	assert.Len(t, store.data["__audit__"], 2)
+
+	events, err := l.Query(Filter{Operation: OpPut})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "k", events[0].Key)
+
+	readOnly := newMemStore()
+	readOnly.readOnly = true
+	l, err = New(Config{ColumnFamily: "__audit__"}, readOnly)
+	require.NoError(t, err, "a read-only log can still be queried")
+	err = l.Record(Event{Operation: OpPut}, Values{})
+	assert.True(t, errors.Is(err, ErrReadOnlyStore))
+	_, err = New(Config{File: "x", ColumnFamily: "y"}, store)
+	assert.Error(t, err)
+}
+
+func TestColumnFamilyLogQueryBounds(t *testing.T) {
+	store := newMemStore()
+	l, err := New(Config{ColumnFamily: "__audit__"}, store)
+	require.NoError(t, err)
+	base := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 2500; i++ {
+		require.NoError(t, l.Record(Event{Time: base.Add(time.Duration(i) * time.Minute), Operation: OpPut, Key: fmt.Sprint(i)}, Values{}))
+	}
+
+	// The most recent events are read without scanning the whole history
+	store.scanned = 0
+	events, err := l.Query(Filter{Limit: 50})
+	require.NoError(t, err)
+	require.Len(t, events, 50)
+	assert.Equal(t, "2450", events[0].Key)
+	assert.Equal(t, "2499", events[49].Key)
+	assert.LessOrEqual(t, store.scanned, readBatch)
+
+	// Time bounds select the keys scanned
+	store.scanned = 0
+	events, err = l.Query(Filter{Since: base.Add(10 * time.Minute), Until: base.Add(20 * time.Minute)})
+	require.NoError(t, err)
+	require.Len(t, events, 10)
+	assert.Equal(t, "10", events[0].Key)
+	assert.Equal(t, 10, store.scanned)
+
+	events, err = l.Query(Filter{Since: base.Add(2400 * time.Minute), Limit: 1000})
+	require.NoError(t, err)
+	assert.Len(t, events, 100)
+	assert.Equal(t, "2400", events[0].Key)
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "2024-05-01T08:30:00Z", want: time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)},
+		{in: "90m", want: now.Add(-90 * time.Minute)},
+		{in: "7d", want: now.AddDate(0, 0, -7)},
+		{in: "yesterday", wantErr: true},
+		{in: "-1h", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.in, now)
+		if tt.wantErr {
+			assert.Error(t, err, tt.in)
+			continue
+		}
+		require.NoError(t, err, tt.in)
+		assert.True(t, tt.want.Equal(got), "%s: got %v, want %v", tt.in, got, tt.want)
+	}
+
+	date, err := ParseTime("2024-05-01", now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, date.Day())
+}
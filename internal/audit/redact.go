@@ -0,0 +1,110 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const redactedValue = "[REDACTED]"
+
+type redactor struct {
+	mode   string
+	fields map[string]bool
+	cfs    []string
+}
+
+func newRedactor(cfg Config) (*redactor, error) {
+	r := &redactor{mode: cfg.Values, fields: make(map[string]bool), cfs: cfg.RedactColumnFamilies}
+	switch r.mode {
+	case "":
+		r.mode = ValuesHash
+	case ValuesHash, ValuesPlain, ValuesOmit:
+	default:
+		return nil, fmt.Errorf("audit: unknown values policy %q (want hash, plain or omit)", cfg.Values)
+	}
+	for _, f := range cfg.RedactFields {
+		r.fields[strings.ToLower(f)] = true
+	}
+	for _, p := range r.cfs {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("audit: bad redact_column_families pattern %q: %w", p, err)
+		}
+	}
+	return r, nil
+}
+
+// apply fills the value fields of e according to the policy
+func (r *redactor) apply(e *Event, v Values) {
+	mode := r.mode
+	for _, p := range r.cfs {
+		if ok, _ := filepath.Match(p, e.CF); ok {
+			mode = ValuesOmit
+			break
+		}
+	}
+	if mode == ValuesOmit {
+		return
+	}
+
+	e.OldHash = hashValue(v.Old)
+	e.NewHash = hashValue(v.New)
+	if mode == ValuesPlain {
+		e.OldValue = r.mask(v.Old)
+		e.NewValue = r.mask(v.New)
+	}
+}
+
+func hashValue(v *string) string {
+	if v == nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(*v))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// mask replaces configured fields of JSON values, at any depth, with a
+// placeholder. Non-JSON values are recorded unchanged.
+func (r *redactor) mask(v *string) *string {
+	if v == nil || len(r.fields) == 0 {
+		return v
+	}
+	var doc interface{}
+	if err := json.Unmarshal([]byte(*v), &doc); err != nil {
+		return v
+	}
+	if !r.maskValue(doc) {
+		return v
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return v
+	}
+	s := string(out)
+	return &s
+}
+
+func (r *redactor) maskValue(doc interface{}) bool {
+	changed := false
+	switch t := doc.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			if r.fields[strings.ToLower(k)] {
+				t[k] = redactedValue
+				changed = true
+			} else if r.maskValue(child) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, child := range t {
+			if r.maskValue(child) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrReadOnlyStore is returned when recording into the column family of a
+// read-only database. Such a log can still be queried.
+var ErrReadOnlyStore = errors.New("audit column family requires a writable database")
+
+// Store is the database the column family sink keeps events in. The audit
+// package does not import db, so service.AuditStore adapts a db.KeyValueDB.
+type Store interface {
+	ListCFs() ([]string, error)
+	CreateCF(cf string) error
+	PutCF(cf, key, value string) error
+	// ScanCFReverse returns up to limit keys in [start, end) of cf, largest
+	// first. A nil start or end leaves that side unbounded.
+	ScanCFReverse(cf string, start, end []byte, limit int) (Page, error)
+	IsReadOnly() bool
+}
+
+// Page is one batch of keys and values returned by Store.ScanCFReverse
+type Page struct {
+	Results map[string]string
+	HasMore bool
+}
+
+// readBatch is the number of events read per scan of the column family
+const readBatch = 1000
+
+// cfSink stores each event as JSON under its time-ordered ID
+type cfSink struct {
+	store Store
+	cf    string
+}
+
+func newCFSink(store Store, cf string) (*cfSink, error) {
+	if store.IsReadOnly() {
+		return &cfSink{store: store, cf: cf}, nil
+	}
+	cfs, err := store.ListCFs()
+	if err != nil {
+		return nil, err
+	}
+	exists := false
+	for _, name := range cfs {
+		if name == cf {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		if err := store.CreateCF(cf); err != nil {
+			return nil, fmt.Errorf("failed to create audit column family: %w", err)
+		}
+	}
+	return &cfSink{store: store, cf: cf}, nil
+}
+
+func (s *cfSink) Write(e *Event) error {
+	if s.store.IsReadOnly() {
+		return ErrReadOnlyStore
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return s.store.PutCF(s.cf, e.ID, string(data))
+}
+
+// Read scans the IDs between f.Since and f.Until newest first, and stops
+// once f.Limit events match
+func (s *cfSink) Read(f Filter) ([]Event, error) {
+	var start, end []byte
+	if !f.Since.IsZero() {
+		start = []byte(idTime(f.Since))
+	}
+	if !f.Until.IsZero() {
+		end = []byte(idTime(f.Until))
+	}
+
+	var events []Event // newest first
+	for {
+		// Reversed scans without an end seek before their start, so the
+		// start is checked here
+		scanStart := start
+		if end == nil {
+			scanStart = nil
+		}
+		page, err := s.store.ScanCFReverse(s.cf, scanStart, end, readBatch)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(page.Results))
+		for id := range page.Results {
+			ids = append(ids, id)
+		}
+		sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+
+		for _, id := range ids {
+			if start != nil && id < string(start) {
+				return oldestFirst(events), nil
+			}
+			var e Event
+			if err := json.Unmarshal([]byte(page.Results[id]), &e); err != nil {
+				continue
+			}
+			if f.match(&e) {
+				events = append(events, e)
+				if f.Limit > 0 && len(events) == f.Limit {
+					return oldestFirst(events), nil
+				}
+			}
+		}
+		if !page.HasMore || len(ids) == 0 {
+			return oldestFirst(events), nil
+		}
+		end = []byte(ids[len(ids)-1])
+	}
+}
+
+// idTime is the start of the IDs of events recorded at t
+func idTime(t time.Time) string {
+	return fmt.Sprintf("%019d", t.UnixNano())
+}
+
+// oldestFirst reverses events read newest first
+func oldestFirst(events []Event) []Event {
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	return events
+}
+
+// Close is a no-op; the database is owned by the caller
+func (s *cfSink) Close() error {
+	return nil
+}
@@ -0,0 +1,352 @@
+// Package auth authenticates web API requests and decides what the caller
+// may do. Callers hold named roles; each role grants read, write or admin
+// access scoped to database paths and column families.
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// ErrNoCredentials means the request carries no credentials a method handles
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials means credentials were presented but rejected
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Role is an access level. Each role includes the ones below it.
+type Role int
+
+const (
+	RoleNone Role = iota
+	RoleRead
+	RoleWrite
+	RoleAdmin
+)
+
+// ParseRole parses "read", "write" or "admin"
+func ParseRole(s string) (Role, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "read":
+		return RoleRead, nil
+	case "write":
+		return RoleWrite, nil
+	case "admin":
+		return RoleAdmin, nil
+	}
+	return RoleNone, fmt.Errorf("unknown role %q (want read, write or admin)", s)
+}
+
+func (r Role) String() string {
+	switch r {
+	case RoleRead:
+		return "read"
+	case RoleWrite:
+		return "write"
+	case RoleAdmin:
+		return "admin"
+	}
+	return "none"
+}
+
+// UnmarshalYAML reads a role from its name
+func (r *Role) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	role, err := ParseRole(s)
+	if err != nil {
+		return err
+	}
+	*r = role
+	return nil
+}
+
+// MarshalYAML writes a role as its name
+func (r Role) MarshalYAML() (interface{}, error) {
+	return r.String(), nil
+}
+
+// Grant gives a role on the databases and column families matching its
+// patterns. Patterns use filepath.Match syntax; an empty list matches all.
+type Grant struct {
+	Role           Role     `yaml:"role" json:"role"`
+	Databases      []string `yaml:"databases,omitempty" json:"databases,omitempty"`
+	ColumnFamilies []string `yaml:"column_families,omitempty" json:"column_families,omitempty"`
+}
+
+// covers reports whether the grant allows role on database and cf. An empty
+// cf means every column family of the database, and an empty database an
+// unknown one; only grants unrestricted on that dimension cover them.
+func (g Grant) covers(role Role, database, cf string) bool {
+	if g.Role < role || !g.coversDatabase(database) {
+		return false
+	}
+	if cf == "" {
+		return unrestricted(g.ColumnFamilies)
+	}
+	return matchAny(g.ColumnFamilies, cf, false)
+}
+
+func (g Grant) coversDatabase(database string) bool {
+	if database == "" {
+		return unrestricted(g.Databases)
+	}
+	return matchAny(g.Databases, filepath.Clean(database), true)
+}
+
+func unrestricted(patterns []string) bool {
+	return len(patterns) == 0 || contains(patterns, "*")
+}
+
+func matchAny(patterns []string, name string, isPath bool) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if isPath && p != "*" {
+			p = filepath.Clean(p)
+		}
+		if p == "*" || p == name {
+			return true
+		}
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Principal is an authenticated caller
+type Principal struct {
+	Name   string   `json:"name"`
+	Method string   `json:"method"` // token, basic, oidc or anonymous
+	Roles  []string `json:"roles"`
+	Grants []Grant  `json:"-"`
+}
+
+// Allows reports whether any of the principal's grants covers role on
+// database and cf (see Grant for the meaning of empty values)
+func (p *Principal) Allows(role Role, database, cf string) bool {
+	if p == nil {
+		return false
+	}
+	for _, g := range p.Grants {
+		if g.covers(role, database, cf) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsAnyCF reports whether the principal has role on at least one column
+// family of database
+func (p *Principal) AllowsAnyCF(role Role, database string) bool {
+	if p == nil {
+		return false
+	}
+	for _, g := range p.Grants {
+		if g.Role >= role && g.coversDatabase(database) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator is one authentication method
+type Authenticator interface {
+	// Authenticate returns ErrNoCredentials when the request carries no
+	// credentials for this method
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Config is the web server auth configuration, usually loaded from YAML
+type Config struct {
+	// Roles maps role names, referenced by tokens, users and JWT claims, to grants
+	Roles map[string][]Grant `yaml:"roles"`
+
+	Tokens []TokenConfig `yaml:"tokens,omitempty"`
+	Basic  *BasicConfig  `yaml:"basic,omitempty"`
+	OIDC   *OIDCConfig   `yaml:"oidc,omitempty"`
+
+	// Anonymous lists roles for requests without credentials; empty
+	// rejects them
+	Anonymous []string `yaml:"anonymous,omitempty"`
+}
+
+// TokenConfig is a static API token, sent as "Authorization: Bearer <token>"
+// or "X-API-Key: <token>". Prefer TokenSHA256 (hex) to keep the token
+// itself out of the config file.
+type TokenConfig struct {
+	Name        string   `yaml:"name"`
+	Token       string   `yaml:"token,omitempty"`
+	TokenSHA256 string   `yaml:"token_sha256,omitempty"`
+	Roles       []string `yaml:"roles"`
+}
+
+// BasicConfig enables HTTP basic auth against an htpasswd file of bcrypt hashes
+type BasicConfig struct {
+	HtpasswdFile string              `yaml:"htpasswd_file"`
+	Users        map[string][]string `yaml:"users"` // user -> roles
+	Realm        string              `yaml:"realm,omitempty"`
+}
+
+// OIDCConfig enables bearer JWTs issued by an OIDC provider, verified
+// against a JWKS file on disk
+type OIDCConfig struct {
+	JWKSFile      string        `yaml:"jwks_file"`
+	Issuer        string        `yaml:"issuer,omitempty"`
+	Audience      string        `yaml:"audience,omitempty"`
+	UsernameClaim string        `yaml:"username_claim,omitempty"` // default "sub"
+	RolesClaim    string        `yaml:"roles_claim,omitempty"`    // default "roles", dots select nested claims
+	Leeway        time.Duration `yaml:"leeway,omitempty"`         // clock skew allowed for exp/nbf
+}
+
+// LoadConfig reads an auth config file
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth config: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse auth config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// Manager tries the configured authentication methods in order
+type Manager struct {
+	roles          map[string][]Grant
+	authenticators []Authenticator
+	anonymous      *Principal
+	realm          string
+}
+
+// New builds a Manager from cfg, loading the htpasswd and JWKS files it names
+func New(cfg *Config) (*Manager, error) {
+	m := &Manager{roles: cfg.Roles, realm: "rocksdb-cli"}
+	if err := m.checkRoles("anonymous", cfg.Anonymous); err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Tokens) > 0 {
+		for _, t := range cfg.Tokens {
+			if err := m.checkRoles("token "+t.Name, t.Roles); err != nil {
+				return nil, err
+			}
+		}
+		ta, err := newTokenAuthenticator(cfg.Tokens, m.grants)
+		if err != nil {
+			return nil, err
+		}
+		m.authenticators = append(m.authenticators, ta)
+	}
+	if cfg.Basic != nil {
+		for user, roles := range cfg.Basic.Users {
+			if err := m.checkRoles("user "+user, roles); err != nil {
+				return nil, err
+			}
+		}
+		ba, err := newBasicAuthenticator(cfg.Basic, m.grants)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Basic.Realm != "" {
+			m.realm = cfg.Basic.Realm
+		}
+		m.authenticators = append(m.authenticators, ba)
+	}
+	if cfg.OIDC != nil {
+		ja, err := newJWTAuthenticator(cfg.OIDC, m.grants)
+		if err != nil {
+			return nil, err
+		}
+		m.authenticators = append(m.authenticators, ja)
+	}
+
+	if len(cfg.Anonymous) > 0 {
+		m.anonymous = &Principal{Name: "anonymous", Method: "anonymous", Roles: cfg.Anonymous, Grants: m.grants(cfg.Anonymous)}
+	}
+	if len(m.authenticators) == 0 && m.anonymous == nil {
+		return nil, errors.New("auth config enables no authentication method")
+	}
+	return m, nil
+}
+
+// NewFromFile loads an auth config file and builds its Manager
+func NewFromFile(path string) (*Manager, error) {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(cfg)
+}
+
+func (m *Manager) checkRoles(owner string, roles []string) error {
+	for _, r := range roles {
+		if _, ok := m.roles[r]; !ok {
+			return fmt.Errorf("%s references undefined role %q", owner, r)
+		}
+	}
+	return nil
+}
+
+// grants resolves role names; unknown names (e.g. from JWT claims) grant nothing
+func (m *Manager) grants(roles []string) []Grant {
+	var grants []Grant
+	for _, r := range roles {
+		grants = append(grants, m.roles[r]...)
+	}
+	return grants
+}
+
+// Authenticate returns the principal for a request. Requests without
+// credentials get the anonymous principal if one is configured.
+func (m *Manager) Authenticate(r *http.Request) (*Principal, error) {
+	var rejected error
+	for _, a := range m.authenticators {
+		p, err := a.Authenticate(r)
+		if err == nil {
+			return p, nil
+		}
+		if !errors.Is(err, ErrNoCredentials) && rejected == nil {
+			rejected = err
+		}
+	}
+	if rejected != nil {
+		return nil, rejected
+	}
+	if m.anonymous != nil {
+		return m.anonymous, nil
+	}
+	return nil, ErrNoCredentials
+}
+
+// Challenge is the WWW-Authenticate header value for rejected requests
+func (m *Manager) Challenge() string {
+	for _, a := range m.authenticators {
+		if _, ok := a.(*basicAuthenticator); ok {
+			return fmt.Sprintf("Basic realm=%q", m.realm)
+		}
+	}
+	return fmt.Sprintf("Bearer realm=%q", m.realm)
+}
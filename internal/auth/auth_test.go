@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func testRoles() map[string][]Grant {
+	return map[string][]Grant{
+		"admins":  {{Role: RoleAdmin}},
+		"readers": {{Role: RoleRead, Databases: []string{"/data/*"}}},
+		"events-writer": {
+			{Role: RoleRead, Databases: []string{"/data/prod"}},
+			{Role: RoleWrite, Databases: []string{"/data/prod"}, ColumnFamilies: []string{"events", "events_*"}},
+		},
+	}
+}
+
+func TestGrantScoping(t *testing.T) {
+	m := &Manager{roles: testRoles()}
+	writer := &Principal{Grants: m.grants([]string{"events-writer"})}
+	reader := &Principal{Grants: m.grants([]string{"readers"})}
+	admin := &Principal{Grants: m.grants([]string{"admins"})}
+
+	tests := []struct {
+		name     string
+		p        *Principal
+		role     Role
+		database string
+		cf       string
+		want     bool
+	}{
+		{"writer reads any cf", writer, RoleRead, "/data/prod", "users", true},
+		{"writer writes events", writer, RoleWrite, "/data/prod", "events", true},
+		{"writer writes events glob", writer, RoleWrite, "/data/prod/", "events_2024", true},
+		{"writer cannot write users", writer, RoleWrite, "/data/prod", "users", false},
+		{"writer cannot write whole db", writer, RoleWrite, "/data/prod", "", false},
+		{"writer has no other db", writer, RoleRead, "/data/staging", "users", false},
+		{"reader reads matching db", reader, RoleRead, "/data/staging", "", true},
+		{"reader cannot write", reader, RoleWrite, "/data/staging", "default", false},
+		{"reader glob does not cross dirs", reader, RoleRead, "/data/a/b", "default", false},
+		{"admin covers everything", admin, RoleAdmin, "/anywhere", "", true},
+		{"nil principal", nil, RoleRead, "/data/prod", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.p.Allows(tt.role, tt.database, tt.cf))
+		})
+	}
+
+	assert.True(t, writer.AllowsAnyCF(RoleWrite, "/data/prod"))
+	assert.False(t, reader.AllowsAnyCF(RoleWrite, "/data/prod"))
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+roles:
+  ops:
+    - role: admin
+  viewer:
+    - role: read
+      databases: ["/data/*"]
+      column_families: ["users"]
+tokens:
+  - name: ci
+    token: secret
+    roles: [ops]
+anonymous: [viewer]
+`), 0600))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, RoleAdmin, cfg.Roles["ops"][0].Role)
+	assert.Equal(t, []string{"users"}, cfg.Roles["viewer"][0].ColumnFamilies)
+	assert.Equal(t, []string{"viewer"}, cfg.Anonymous)
+
+	require.NoError(t, os.WriteFile(path, []byte("roles:\n  bad:\n    - role: superuser\n"), 0600))
+	_, err = LoadConfig(path)
+	assert.Error(t, err)
+}
+
+func TestNewValidation(t *testing.T) {
+	_, err := New(&Config{Roles: testRoles()})
+	assert.Error(t, err, "no method enabled")
+
+	_, err = New(&Config{Roles: testRoles(), Tokens: []TokenConfig{{Name: "x", Token: "t", Roles: []string{"missing"}}}})
+	assert.ErrorContains(t, err, "undefined role")
+
+	_, err = New(&Config{Roles: testRoles(), Tokens: []TokenConfig{{Name: "x", TokenSHA256: "abc", Roles: []string{"admins"}}}})
+	assert.ErrorContains(t, err, "64 hex")
+}
+
+func TestTokenAuthentication(t *testing.T) {
+	digest := sha256.Sum256([]byte("hashed-token"))
+	m, err := New(&Config{
+		Roles: testRoles(),
+		Tokens: []TokenConfig{
+			{Name: "ci", Token: "plain-token", Roles: []string{"admins"}},
+			{Name: "dashboard", TokenSHA256: hex.EncodeToString(digest[:]), Roles: []string{"readers"}},
+		},
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer plain-token")
+	p, err := m.Authenticate(req)
+	require.NoError(t, err)
+	assert.Equal(t, "ci", p.Name)
+	assert.Equal(t, "token", p.Method)
+	assert.True(t, p.Allows(RoleAdmin, "/x", ""))
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-API-Key", "hashed-token")
+	p, err = m.Authenticate(req)
+	require.NoError(t, err)
+	assert.Equal(t, "dashboard", p.Name)
+	assert.False(t, p.Allows(RoleWrite, "/data/prod", "default"))
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	_, err = m.Authenticate(req)
+	assert.True(t, errors.Is(err, ErrInvalidCredentials))
+
+	_, err = m.Authenticate(httptest.NewRequest("GET", "/", nil))
+	assert.True(t, errors.Is(err, ErrNoCredentials))
+	assert.Contains(t, m.Challenge(), "Bearer")
+}
+
+func TestBasicAuthentication(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("hunter2"), bcrypt.MinCost)
+	require.NoError(t, err)
+	htpasswd := filepath.Join(t.TempDir(), "htpasswd")
+	require.NoError(t, os.WriteFile(htpasswd, []byte("# users\nalice:"+string(hash)+"\nbob:"+string(hash)+"\n"), 0600))
+
+	m, err := New(&Config{
+		Roles:     testRoles(),
+		Basic:     &BasicConfig{HtpasswdFile: htpasswd, Users: map[string][]string{"alice": {"events-writer"}}},
+		Anonymous: []string{"readers"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `Basic realm="rocksdb-cli"`, m.Challenge())
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.SetBasicAuth("alice", "hunter2")
+	p, err := m.Authenticate(req)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", p.Name)
+	assert.True(t, p.Allows(RoleWrite, "/data/prod", "events"))
+
+	// Known user without configured roles authenticates but may do nothing
+	req = httptest.NewRequest("GET", "/", nil)
+	req.SetBasicAuth("bob", "hunter2")
+	p, err = m.Authenticate(req)
+	require.NoError(t, err)
+	assert.False(t, p.Allows(RoleRead, "/data/prod", "events"))
+
+	for _, creds := range [][2]string{{"alice", "wrong"}, {"mallory", "hunter2"}} {
+		req = httptest.NewRequest("GET", "/", nil)
+		req.SetBasicAuth(creds[0], creds[1])
+		_, err = m.Authenticate(req)
+		assert.True(t, errors.Is(err, ErrInvalidCredentials), "%s should be rejected", creds[0])
+	}
+
+	// No credentials falls back to the anonymous roles
+	p, err = m.Authenticate(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "anonymous", p.Method)
+	assert.True(t, p.Allows(RoleRead, "/data/prod", ""))
+
+	require.NoError(t, os.WriteFile(htpasswd, []byte("carol:{SHA}abc\n"), 0600))
+	_, err = New(&Config{Roles: testRoles(), Basic: &BasicConfig{HtpasswdFile: htpasswd}})
+	assert.ErrorContains(t, err, "bcrypt")
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// basicAuthenticator checks HTTP basic credentials against bcrypt hashes
+// from an htpasswd file ("user:$2y$..." per line, as written by htpasswd -B)
+type basicAuthenticator struct {
+	hashes map[string][]byte
+	users  map[string][]string
+	grants func([]string) []Grant
+	// dummy is compared for unknown users so response time does not reveal
+	// which usernames exist
+	dummy []byte
+}
+
+func newBasicAuthenticator(cfg *BasicConfig, grants func([]string) []Grant) (*basicAuthenticator, error) {
+	if cfg.HtpasswdFile == "" {
+		return nil, fmt.Errorf("basic auth requires htpasswd_file")
+	}
+	hashes, err := loadHtpasswd(cfg.HtpasswdFile)
+	if err != nil {
+		return nil, err
+	}
+	dummy, err := bcrypt.GenerateFromPassword([]byte("rocksdb-cli"), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	return &basicAuthenticator{hashes: hashes, users: cfg.Users, grants: grants, dummy: dummy}, nil
+}
+
+func loadHtpasswd(path string) (map[string][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open htpasswd file: %w", err)
+	}
+	defer f.Close()
+
+	hashes := make(map[string][]byte)
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		user, hash, ok := strings.Cut(line, ":")
+		if !ok || user == "" {
+			return nil, fmt.Errorf("%s:%d: expected user:hash", path, lineNo)
+		}
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return nil, fmt.Errorf("%s:%d: user %s: only bcrypt hashes are supported", path, lineNo, user)
+		}
+		hashes[user] = []byte(hash)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read htpasswd file: %w", err)
+	}
+	return hashes, nil
+}
+
+func (a *basicAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	hash, known := a.hashes[user]
+	if !known {
+		hash = a.dummy
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil || !known {
+		return nil, fmt.Errorf("%w: bad username or password", ErrInvalidCredentials)
+	}
+	roles := a.users[user]
+	return &Principal{Name: user, Method: "basic", Roles: roles, Grants: a.grants(roles)}, nil
+}
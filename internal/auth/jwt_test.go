@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func b64(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+func signJWT(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signing := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signing))
+
+	var sig []byte
+	var err error
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k, digest[:])
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	}
+	require.NoError(t, err)
+	return signing + "." + b64(sig)
+}
+
+func TestJWTAuthentication(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks, _ := json.Marshal(map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": b64(ecKey.X.Bytes()), "y": b64(ecKey.Y.Bytes())},
+	}})
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(jwksFile, jwks, 0600))
+
+	m, err := New(&Config{
+		Roles: testRoles(),
+		OIDC: &OIDCConfig{
+			JWKSFile:      jwksFile,
+			Issuer:        "https://idp.example.com",
+			Audience:      "rocksdb-cli",
+			UsernameClaim: "email",
+			RolesClaim:    "realm_access.roles",
+		},
+	})
+	require.NoError(t, err)
+
+	now := time.Now().Unix()
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss":          "https://idp.example.com",
+			"aud":          []string{"other", "rocksdb-cli"},
+			"email":        "alice@example.com",
+			"exp":          now + 300,
+			"nbf":          now - 10,
+			"realm_access": map[string]interface{}{"roles": []string{"events-writer", "unknown-role"}},
+		}
+		for k, v := range overrides {
+			if v == nil {
+				delete(c, k)
+			} else {
+				c[k] = v
+			}
+		}
+		return c
+	}
+	authenticate := func(token string) (*Principal, error) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return m.Authenticate(req)
+	}
+
+	p, err := authenticate(signJWT(t, "RS256", "rsa-1", rsaKey, claims(nil)))
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", p.Name)
+	assert.Equal(t, "oidc", p.Method)
+	assert.True(t, p.Allows(RoleWrite, "/data/prod", "events"))
+	assert.False(t, p.Allows(RoleWrite, "/data/prod", "users"))
+
+	p, err = authenticate(signJWT(t, "ES256", "ec-1", ecKey, claims(map[string]interface{}{"aud": "rocksdb-cli"})))
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", p.Name)
+
+	rejected := map[string]string{
+		"wrong key":     signJWT(t, "RS256", "rsa-1", otherKey, claims(nil)),
+		"expired":       signJWT(t, "RS256", "rsa-1", rsaKey, claims(map[string]interface{}{"exp": now - 60})),
+		"no exp":        signJWT(t, "RS256", "rsa-1", rsaKey, claims(map[string]interface{}{"exp": nil})),
+		"not yet valid": signJWT(t, "RS256", "rsa-1", rsaKey, claims(map[string]interface{}{"nbf": now + 600})),
+		"wrong issuer":  signJWT(t, "RS256", "rsa-1", rsaKey, claims(map[string]interface{}{"iss": "https://evil.example.com"})),
+		"wrong aud":     signJWT(t, "RS256", "rsa-1", rsaKey, claims(map[string]interface{}{"aud": "someone-else"})),
+		"no username":   signJWT(t, "RS256", "rsa-1", rsaKey, claims(map[string]interface{}{"email": nil})),
+		"alg mismatch":  signJWT(t, "ES256", "rsa-1", rsaKey, claims(nil)),
+		"alg none":      b64([]byte(`{"alg":"none"}`)) + "." + b64([]byte(`{"email":"x","exp":9999999999}`)) + ".",
+	}
+	for name, token := range rejected {
+		t.Run(name, func(t *testing.T) {
+			_, err := authenticate(token)
+			assert.True(t, errors.Is(err, ErrInvalidCredentials), "got %v", err)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type tokenEntry struct {
+	name   string
+	hash   [sha256.Size]byte
+	roles  []string
+	grants []Grant
+}
+
+// tokenAuthenticator checks static API tokens. Tokens are kept as SHA-256
+// digests and compared in constant time.
+type tokenAuthenticator struct {
+	tokens []tokenEntry
+}
+
+func newTokenAuthenticator(tokens []TokenConfig, grants func([]string) []Grant) (*tokenAuthenticator, error) {
+	a := &tokenAuthenticator{}
+	for i, t := range tokens {
+		name := t.Name
+		if name == "" {
+			name = fmt.Sprintf("token-%d", i+1)
+		}
+		entry := tokenEntry{name: name, roles: t.Roles, grants: grants(t.Roles)}
+		switch {
+		case t.Token != "" && t.TokenSHA256 != "":
+			return nil, fmt.Errorf("token %s: set either token or token_sha256, not both", name)
+		case t.Token != "":
+			entry.hash = sha256.Sum256([]byte(t.Token))
+		case t.TokenSHA256 != "":
+			digest, err := hex.DecodeString(t.TokenSHA256)
+			if err != nil || len(digest) != sha256.Size {
+				return nil, fmt.Errorf("token %s: token_sha256 must be 64 hex characters", name)
+			}
+			copy(entry.hash[:], digest)
+		default:
+			return nil, fmt.Errorf("token %s: missing token or token_sha256", name)
+		}
+		a.tokens = append(a.tokens, entry)
+	}
+	return a, nil
+}
+
+// bearerToken returns the token from an "Authorization: Bearer" header
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
+
+func (a *tokenAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token := r.Header.Get("X-API-Key")
+	if token == "" {
+		token = bearerToken(r)
+	}
+	if token == "" {
+		return nil, ErrNoCredentials
+	}
+
+	digest := sha256.Sum256([]byte(token))
+	var match *tokenEntry
+	for i := range a.tokens {
+		// Check every entry so timing does not reveal which one matched
+		if subtle.ConstantTimeCompare(digest[:], a.tokens[i].hash[:]) == 1 {
+			match = &a.tokens[i]
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("%w: unknown API token", ErrInvalidCredentials)
+	}
+	return &Principal{Name: match.name, Method: "token", Roles: match.roles, Grants: match.grants}, nil
+}
@@ -5,9 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"rocksdb-cli/internal/db"
 	"rocksdb-cli/internal/graphchain"
+	"rocksdb-cli/internal/inspect"
 	"rocksdb-cli/internal/jsonutil"
+	"rocksdb-cli/internal/mcp/tools"
+	"rocksdb-cli/internal/output"
 	"rocksdb-cli/internal/util"
 	"sort"
 	"strconv"
@@ -29,14 +34,30 @@ func parseSep(s string) string {
 	}
 }
 
+// ReplState is the state of a REPL session. The exported fields with JSON
+// tags are saved per database and restored on the next start.
 type ReplState struct {
-	CurrentCF string
+	CurrentCF string            `json:"current_cf,omitempty"`
+	Queries   map[string]string `json:"queries,omitempty"` // named queries saved with query save
+	Pretty    bool              `json:"pretty,omitempty"`  // add --pretty to commands that support it
+	Output    string            `json:"output,omitempty"`  // default --output format of commands that support it
+
+	History         []string          `json:"-"` // commands entered, oldest first
+	Vars            map[string]string `json:"-"` // variables defined with set name=value
+	ContinueOnError bool              `json:"-"` // set with on-error continue
 }
 
 type Handler struct {
 	DB              db.KeyValueDB
-	State           interface{}       // *ReplState, used to manage the active column family
-	GraphChainAgent *graphchain.Agent // GraphChain agent for natural language queries
+	State           interface{}        // *ReplState, used to manage the active column family
+	GraphChainAgent *graphchain.Agent  // GraphChain agent for natural language queries
+	Tools           *tools.Registry    // Local and remote MCP tools for the tools command
+	RemoteTools     *tools.RemoteProxy // Executes remote tools, nil without MCP clients
+	PagerInput      io.Reader          // Keys of the scan and search pager, the terminal when nil
+
+	failed      bool          // the last command failed
+	sourceDepth int           // nesting of running source commands
+	format      output.Format // output format of the running command
 }
 
 // prettyPrintJSON formats JSON with recursive nested expansion using jsonutil
@@ -74,12 +95,31 @@ func handleError(err error, operation string, params ...string) {
 		}
 	} else if errors.Is(err, db.ErrDatabaseClosed) {
 		fmt.Println("Database is closed")
+	} else if errors.Is(err, db.ErrTTLModeDisabled) {
+		fmt.Println("Database is not opened in TTL mode (start with --ttl-mode)")
 	} else {
 		// Generic error message for unknown errors
 		fmt.Printf("%s failed: %v\n", operation, err)
 	}
 }
 
+// reportError prints err like handleError and marks the command failed
+func (h *Handler) reportError(err error, operation string, params ...string) {
+	h.failed = true
+	handleError(err, operation, params...)
+}
+
+// failf prints why a command failed and marks it failed
+func (h *Handler) failf(format string, args ...interface{}) {
+	h.failed = true
+	fmt.Printf(format, args...)
+}
+
+// Failed reports whether the last command run by Execute failed
+func (h *Handler) Failed() bool {
+	return h.failed
+}
+
 // parseTimestamp attempts to parse a key as a timestamp and return formatted UTC time
 // Supports various timestamp formats: Unix seconds, Unix milliseconds, Unix microseconds, Unix nanoseconds, .NET ticks
 func parseTimestamp(key string) string {
@@ -191,16 +231,39 @@ func parseCFAndArgs(parts []string, currentCF string) (cf string, args []string,
 }
 
 func (h *Handler) Execute(input string) bool {
+	h.failed = false
 	input = strings.TrimSpace(input)
 	if input == "" {
 		return true
 	}
 	parts := strings.Fields(input)
 	cmd := strings.ToLower(parts[0])
+	// Saved queries keep their variables until they are run
+	if !(cmd == "query" && len(parts) > 1 && parts[1] == "save") {
+		expanded, err := h.expandVars(input)
+		if err != nil {
+			h.failf("%v\n", err)
+			return true
+		}
+		if expanded != input {
+			input = strings.TrimSpace(expanded)
+			if input == "" {
+				return true
+			}
+			parts = strings.Fields(input)
+			cmd = strings.ToLower(parts[0])
+		}
+	}
+	parts, err := h.takeOutputFormat(cmd, parts)
+	if err != nil {
+		h.failf("%v\n", err)
+		return true
+	}
+	parts = h.applyOutputSettings(cmd, parts)
 	switch cmd {
 	case "usecf":
 		if len(parts) != 2 {
-			fmt.Println("Usage: usecf <cf>")
+			h.failf("Usage: usecf <cf>\n")
 			return true
 		}
 		if h.State != nil {
@@ -230,14 +293,15 @@ func (h *Handler) Execute(input string) bool {
 		// Parse column family and range
 		switch len(args) {
 		case 0: // scan (no args)
-			fmt.Println("Usage: scan [<cf>] [start] [end] [--limit=N] [--reverse] [--values=no] [--timestamp] [--pretty] [--smart=true|false]")
+			h.failf("Usage: scan [<cf>] [start] [end] [--limit=N] [--reverse] [--values=no] [--timestamp] [--pretty] [--smart=true|false] [--page]\n")
 			fmt.Println("  Use * as wildcard to scan all entries (e.g., scan * or scan * *)")
 			fmt.Println("  --pretty enables JSON pretty-printing for values")
+			fmt.Println("  --page pages through the range interactively, --limit entries per page")
 			fmt.Println("  --smart=false disables automatic key format conversion")
 			return true
 		case 1: // scan <start> (using current CF) or scan * (scan all)
 			if currentCF == "" {
-				fmt.Println("No current column family set")
+				h.failf("No current column family set\n")
 				return true
 			}
 			cf = currentCF
@@ -289,9 +353,10 @@ func (h *Handler) Execute(input string) bool {
 				endStr = args[2]
 			}
 		default:
-			fmt.Println("Usage: scan [<cf>] [start] [end] [--limit=N] [--reverse] [--values=no] [--timestamp] [--pretty] [--smart=true|false]")
+			h.failf("Usage: scan [<cf>] [start] [end] [--limit=N] [--reverse] [--values=no] [--timestamp] [--pretty] [--smart=true|false] [--page]\n")
 			fmt.Println("  Use * as wildcard to scan all entries (e.g., scan * or scan * *)")
 			fmt.Println("  --pretty enables JSON pretty-printing for values")
+			fmt.Println("  --page pages through the range interactively, --limit entries per page")
 			fmt.Println("  --smart=false disables automatic key format conversion")
 			return true
 		}
@@ -310,7 +375,7 @@ func (h *Handler) Execute(input string) bool {
 				opts.Limit = 0 // explicit unlimited
 				userSetLimit = true
 			} else {
-				fmt.Println("Invalid limit value")
+				h.failf("Invalid limit value\n")
 				return true
 			}
 		}
@@ -331,10 +396,47 @@ func (h *Handler) Execute(input string) bool {
 		// Check for timestamp flag
 		showTimestamp := flags["timestamp"] == "true"
 
+		if flags["page"] == "true" {
+			if !userSetLimit || opts.Limit == 0 {
+				opts.Limit = defaultPageSize
+			}
+			src := &scanPages{db: h.DB, cf: cf, start: startStr, end: endStr, smart: useSmart, opts: opts}
+			h.runPager("Scan", cf, src, pagerView{Reverse: opts.Reverse, Values: opts.Values})
+			return true
+		}
+
+		if h.structured() {
+			// Pages carry the cursor and the expiry of each value
+			var page db.ScanPageResult
+			var err error
+			if useSmart {
+				page, err = h.DB.SmartScanCFPage(cf, startStr, endStr, opts)
+			} else {
+				page, err = h.DB.ScanCFPage(cf, []byte(startStr), []byte(endStr), opts)
+			}
+			if err != nil {
+				h.reportError(err, "Scan", cf)
+			} else {
+				h.writeResult(output.Entries(page, opts.Values))
+			}
+			return true
+		}
+
 		var result map[string]string
+		var expiry TTLIndex
 		var err error
 
-		if useSmart {
+		if h.DB.TTLMode() && opts.Values {
+			// Paged results carry the decoded expiry of each value
+			var page db.ScanPageResult
+			if useSmart {
+				page, err = h.DB.SmartScanCFPage(cf, startStr, endStr, opts)
+			} else {
+				page, err = h.DB.ScanCFPage(cf, []byte(startStr), []byte(endStr), opts)
+			}
+			result = page.Results
+			expiry = NewTTLIndex(page.ResultsV2)
+		} else if useSmart {
 			result, err = h.DB.SmartScanCF(cf, startStr, endStr, opts)
 		} else {
 			// Convert strings to bytes for regular scan
@@ -349,7 +451,7 @@ func (h *Handler) Execute(input string) bool {
 		}
 
 		if err != nil {
-			handleError(err, "Scan", cf)
+			h.reportError(err, "Scan", cf)
 		} else {
 			// Sort keys to ensure consistent output order
 			keys := make([]string, 0, len(result))
@@ -398,6 +500,10 @@ func (h *Handler) Execute(input string) bool {
 					}
 				}
 
+				if info := expiry.Get(k); info != nil {
+					displayValue = fmt.Sprintf("%s  [%s]", displayValue, info)
+				}
+
 				if showTimestamp {
 					if timestamp := parseTimestamp(k); timestamp != "" {
 						if opts.Values {
@@ -438,7 +544,7 @@ func (h *Handler) Execute(input string) bool {
 		switch len(args) {
 		case 1: // get <key> (using current CF)
 			if currentCF == "" {
-				fmt.Println("No current column family set")
+				h.failf("No current column family set\n")
 				return true
 			}
 			cf = currentCF
@@ -447,31 +553,111 @@ func (h *Handler) Execute(input string) bool {
 			cf = args[0]
 			key = args[1]
 		default:
-			fmt.Println("Usage: get [<cf>] <key> [--pretty] [--smart=true|false]")
+			h.failf("Usage: get [<cf>] <key> [--pretty] [--smart=true|false]\n")
 			fmt.Println("  Query by key with automatic binary key conversion")
 			fmt.Println("  --smart=false disables automatic key format conversion")
 			return true
 		}
 
 		var val string
+		var info *db.TTLInfo
 		var err error
 
 		if useSmart {
-			val, err = h.DB.SmartGetCF(cf, key)
+			val, info, err = h.DB.GetCFWithTTL(cf, key)
 		} else {
 			val, err = h.DB.GetCF(cf, key)
 		}
 
 		if err != nil {
-			handleError(err, "Query", key, cf)
+			h.reportError(err, "Query", key, cf)
+		} else if h.structured() {
+			kv := output.Entry(key, val)
+			kv.TTL = info
+			h.writeResult(output.Value(kv))
 		} else {
 			if pretty {
 				fmt.Printf("%s\n", prettyPrintJSON(val))
 			} else {
 				fmt.Printf("%s\n", val)
 			}
+			if info != nil {
+				fmt.Printf("TTL: written %s, %s\n", info.WriteTime.Format(time.RFC3339), info)
+			}
+		}
+	case "inspect":
+		flags, args := parseFlags(parts[1:])
+		useSmart := flags["smart"] != "false"
+
+		currentCF := ""
+		if s, ok := h.State.(*ReplState); ok && s != nil {
+			currentCF = s.CurrentCF
+		}
+
+		var cf, key string
+		switch len(args) {
+		case 1: // inspect <key> (using current CF)
+			if currentCF == "" {
+				h.failf("No current column family set\n")
+				return true
+			}
+			cf = currentCF
+			key = args[0]
+		case 2: // inspect <cf> <key>
+			cf = args[0]
+			key = args[1]
+		default:
+			h.failf("Usage: inspect [<cf>] <key> [--smart=true|false]\n")
+			fmt.Println("  Show a hex dump of the value and its candidate decodings")
+			return true
+		}
+
+		var val string
+		var err error
+		if useSmart {
+			val, err = h.DB.SmartGetCF(cf, key)
+		} else {
+			val, err = h.DB.GetCF(cf, key)
+		}
+
+		if err != nil {
+			h.reportError(err, "Query", key, cf)
+			return true
+		}
+		report := inspect.Inspect([]byte(val))
+		if h.structured() {
+			result := output.Object(report)
+			result.Raw = report.Hexdump
+			h.writeResult(result)
+		} else {
+			report.WriteText(os.Stdout)
 		}
 	case "put":
+		// Only --ttl, --hex and --base64 are taken as flags, and only after
+		// the key and value, so values may still start with "--"
+		var ttl time.Duration
+		encoding := ""
+	trailing:
+		for n := len(parts); n > 3; n = len(parts) {
+			switch last := parts[n-1]; {
+			case strings.HasPrefix(last, "--ttl="):
+				d, err := db.ParseTTLDuration(strings.TrimPrefix(last, "--ttl="))
+				if err != nil {
+					h.failf("%v\n", err)
+					return true
+				}
+				ttl = d
+			case last == "--hex" || last == "--base64":
+				if encoding != "" {
+					h.failf("Use only one of --hex and --base64\n")
+					return true
+				}
+				encoding = strings.TrimPrefix(last, "--")
+			default:
+				break trailing
+			}
+			parts = parts[:n-1]
+		}
 		cf, key, value := "", "", ""
 		if len(parts) == 3 { // put <key> <value>
 			if s, ok := h.State.(*ReplState); ok && s != nil {
@@ -479,7 +665,7 @@ func (h *Handler) Execute(input string) bool {
 				key = parts[1]
 				value = parts[2]
 			} else {
-				fmt.Println("No current column family set")
+				h.failf("No current column family set\n")
 				return true
 			}
 		} else if len(parts) == 4 { // put <cf> <key> <value>
@@ -487,12 +673,47 @@ func (h *Handler) Execute(input string) bool {
 			key = parts[2]
 			value = parts[3]
 		} else {
-			fmt.Println("Usage: put [<cf>] <key> <value>")
+			h.failf("Usage: put [<cf>] <key> <value> [--ttl=<duration>] [--hex|--base64]\n")
+			return true
+		}
+		value, err := util.DecodeInput(value, encoding)
+		if err != nil {
+			h.failf("Invalid %s value: %v\n", encoding, err)
+			return true
+		}
+		err = h.DB.PutCFWithTTL(cf, key, value, ttl)
+		if err != nil {
+			h.reportError(err, "Write", cf)
+		} else if h.structured() {
+			h.writeResult(output.Done(output.Status{Operation: "put", ColumnFamily: cf, Key: key}))
+		} else {
+			fmt.Println("OK")
+		}
+	case "merge":
+		cf, key, operand := "", "", ""
+		if len(parts) == 3 { // merge <key> <operand>
+			if s, ok := h.State.(*ReplState); ok && s != nil {
+				cf = s.CurrentCF
+				key = parts[1]
+				operand = parts[2]
+			} else {
+				h.failf("No current column family set\n")
+				return true
+			}
+		} else if len(parts) == 4 { // merge <cf> <key> <operand>
+			cf = parts[1]
+			key = parts[2]
+			operand = parts[3]
+		} else {
+			h.failf("Usage: merge [<cf>] <key> <operand>\n")
+			fmt.Println("  Requires a merge operator on the column family (see 'cfoptions --plugins')")
 			return true
 		}
-		err := h.DB.PutCF(cf, key, value)
+		err := h.DB.MergeCF(cf, key, operand)
 		if err != nil {
-			handleError(err, "Write", cf)
+			h.reportError(err, "Merge", cf)
+		} else if h.structured() {
+			h.writeResult(output.Done(output.Status{Operation: "merge", ColumnFamily: cf, Key: key}))
 		} else {
 			fmt.Println("OK")
 		}
@@ -516,7 +737,7 @@ func (h *Handler) Execute(input string) bool {
 		switch len(args) {
 		case 1: // prefix <prefix> (using current CF)
 			if currentCF == "" {
-				fmt.Println("No current column family set")
+				h.failf("No current column family set\n")
 				return true
 			}
 			cf = currentCF
@@ -525,7 +746,7 @@ func (h *Handler) Execute(input string) bool {
 			cf = args[0]
 			prefix = args[1]
 		default:
-			fmt.Println("Usage: prefix [<cf>] <prefix> [--pretty] [--smart=true|false]")
+			h.failf("Usage: prefix [<cf>] <prefix> [--pretty] [--smart=true|false]\n")
 			fmt.Println("  Query by key prefix with automatic binary key conversion")
 			fmt.Println("  --smart=false disables automatic key format conversion")
 			return true
@@ -541,7 +762,9 @@ func (h *Handler) Execute(input string) bool {
 		}
 
 		if err != nil {
-			handleError(err, "Prefix scan", cf)
+			h.reportError(err, "Prefix scan", cf)
+		} else if h.structured() {
+			h.writeResult(output.Entries(db.ScanPageResult{ResultsV2: output.EntriesOf(result)}, true))
 		} else {
 			// Sort keys to ensure consistent output order
 			keys := make([]string, 0, len(result))
@@ -588,7 +811,9 @@ func (h *Handler) Execute(input string) bool {
 	case "listcf":
 		cfs, err := h.DB.ListCFs()
 		if err != nil {
-			handleError(err, "List column families")
+			h.reportError(err, "List column families")
+		} else if h.structured() {
+			h.writeResult(output.List("name", cfs))
 		} else {
 			fmt.Println("Column Families:")
 			for _, cf := range cfs {
@@ -596,24 +821,92 @@ func (h *Handler) Execute(input string) bool {
 			}
 		}
 	case "createcf":
-		if len(parts) != 2 {
-			fmt.Println("Usage: createcf <cf>")
+		flags, args := parseFlags(parts[1:])
+		if len(args) != 1 {
+			h.failf("Usage: createcf <cf> [--profile=<name>]\n")
+			fmt.Println("  Use 'cfoptions --profiles' to list available profiles")
 			return true
 		}
-		err := h.DB.CreateCF(parts[1])
+		var err error
+		if profile, ok := flags["profile"]; ok {
+			err = h.DB.CreateCFWithProfile(args[0], profile)
+		} else {
+			err = h.DB.CreateCF(args[0])
+		}
 		if err != nil {
-			handleError(err, "Create column family", parts[1])
+			h.reportError(err, "Create column family", args[0])
+		} else if h.structured() {
+			h.writeResult(output.Done(output.Status{Operation: "createcf", ColumnFamily: args[0]}))
 		} else {
 			fmt.Println("OK")
 		}
+	case "cfoptions":
+		flags, args := parseFlags(parts[1:])
+		if flags["profiles"] == "true" {
+			formatCFProfiles(db.CFProfiles())
+			return true
+		}
+		if flags["plugins"] == "true" {
+			FormatPlugins(db.Comparators(), db.MergeOperators())
+			return true
+		}
+
+		currentCF := ""
+		if s, ok := h.State.(*ReplState); ok && s != nil {
+			currentCF = s.CurrentCF
+		}
+		var cf string
+		switch len(args) {
+		case 0:
+			if currentCF == "" {
+				h.failf("No current column family set\n")
+				return true
+			}
+			cf = currentCF
+		case 1:
+			cf = args[0]
+		default:
+			h.failf("Usage: cfoptions [<cf>] [--raw] [--pretty] | cfoptions --profiles | cfoptions --plugins\n")
+			return true
+		}
+
+		opts, err := h.DB.GetCFOptions(cf)
+		if err != nil {
+			h.reportError(err, "Get column family options", cf)
+			return true
+		}
+		var mismatches []db.OptionMismatch
+		for _, m := range h.DB.OptionMismatches() {
+			if m.ColumnFamily == cf || m.ColumnFamily == "*" {
+				mismatches = append(mismatches, m)
+			}
+		}
+		if h.structured() {
+			h.writeResult(output.Object(map[string]interface{}{
+				"options":    opts,
+				"mismatches": mismatches,
+			}))
+			return true
+		}
+		if flags["pretty"] == "true" {
+			data, _ := json.MarshalIndent(map[string]interface{}{
+				"options":    opts,
+				"mismatches": mismatches,
+			}, "", "  ")
+			fmt.Println(string(data))
+			return true
+		}
+		formatCFOptions(opts, mismatches, flags["raw"] == "true")
 	case "dropcf":
 		if len(parts) != 2 {
-			fmt.Println("Usage: dropcf <cf>")
+			h.failf("Usage: dropcf <cf>\n")
 			return true
 		}
 		err := h.DB.DropCF(parts[1])
 		if err != nil {
-			handleError(err, "Drop column family", parts[1])
+			h.reportError(err, "Drop column family", parts[1])
+		} else if h.structured() {
+			h.writeResult(output.Done(output.Status{Operation: "dropcf", ColumnFamily: parts[1]}))
 		} else {
 			fmt.Println("OK")
 		}
@@ -625,7 +918,7 @@ func (h *Handler) Execute(input string) bool {
 				cf = s.CurrentCF
 				filePath = parts[1]
 			} else {
-				fmt.Println("No current column family set")
+				h.failf("No current column family set\n")
 				return true
 			}
 		} else if len(parts) == 3 {
@@ -633,7 +926,7 @@ func (h *Handler) Execute(input string) bool {
 			cf = parts[1]
 			filePath = parts[2]
 		} else {
-			fmt.Println("Usage: export [<cf>] <file_path> [--sep=<sep>]")
+			h.failf("Usage: export [<cf>] <file_path> [--sep=<sep>]\n")
 			fmt.Println("  Export column family data to CSV file")
 			fmt.Println("  --sep=<sep>   CSV separator (default: ,). Supports \\t for tab, ; for semicolon, etc.")
 			fmt.Println("  Example: export users users.csv --sep=\";\"")
@@ -643,7 +936,9 @@ func (h *Handler) Execute(input string) bool {
 
 		err := h.DB.ExportToCSV(cf, filePath, ",")
 		if err != nil {
-			handleError(err, "Export", cf)
+			h.reportError(err, "Export", cf)
+		} else if h.structured() {
+			h.writeResult(output.Done(output.Status{Operation: "export", ColumnFamily: cf, File: filePath}))
 		} else {
 			fmt.Printf("Successfully exported column family '%s' to '%s'\n", cf, filePath)
 		}
@@ -661,7 +956,7 @@ func (h *Handler) Execute(input string) bool {
 		if len(parts) == 1 {
 			// last - use current CF
 			if currentCF == "" {
-				fmt.Println("No current column family set")
+				h.failf("No current column family set\n")
 				return true
 			}
 			cf = currentCF
@@ -673,7 +968,7 @@ func (h *Handler) Execute(input string) bool {
 			if len(nonFlags) == 0 {
 				// last --pretty
 				if currentCF == "" {
-					fmt.Println("No current column family set")
+					h.failf("No current column family set\n")
 					return true
 				}
 				cf = currentCF
@@ -681,7 +976,7 @@ func (h *Handler) Execute(input string) bool {
 				// last <cf> or last <cf> --pretty
 				cf = nonFlags[0]
 			} else {
-				fmt.Println("Usage: last [<cf>] [--pretty]")
+				h.failf("Usage: last [<cf>] [--pretty]\n")
 				fmt.Println("  Get the last key-value pair from column family")
 				return true
 			}
@@ -689,7 +984,9 @@ func (h *Handler) Execute(input string) bool {
 
 		key, value, err := h.DB.GetLastCF(cf)
 		if err != nil {
-			handleError(err, "Get last", cf)
+			h.reportError(err, "Get last", cf)
+		} else if h.structured() {
+			h.writeResult(output.Value(output.Entry(key, value)))
 		} else {
 			formattedValue := formatValue(value, pretty)
 			fmt.Printf("Last entry in '%s': %s = %s\n", cf, util.FormatKey(key), formattedValue)
@@ -711,7 +1008,7 @@ func (h *Handler) Execute(input string) bool {
 		switch len(args) {
 		case 2: // jpath <key> <jsonpath> (using current CF)
 			if currentCF == "" {
-				fmt.Println("No current column family set")
+				h.failf("No current column family set\n")
 				return true
 			}
 			cf = currentCF
@@ -722,7 +1019,7 @@ func (h *Handler) Execute(input string) bool {
 			key = args[1]
 			jsonPathExpr = args[2]
 		default:
-			fmt.Println("Usage: jpath [<cf>] <key> <jsonpath> [--pretty] [--smart=true|false]")
+			h.failf("Usage: jpath [<cf>] <key> <jsonpath> [--pretty] [--smart=true|false]\n")
 			fmt.Println("  Query JSON value using JSONPath expression")
 			fmt.Println("  Examples:")
 			fmt.Println("    jpath user:123 \"$.name\"")
@@ -750,13 +1047,13 @@ func (h *Handler) Execute(input string) bool {
 		}
 
 		if err != nil {
-			handleError(err, "Query", key, cf)
+			h.reportError(err, "Query", key, cf)
 			return true
 		}
 
 		// Check if value is valid JSON
 		if !jsonutil.IsValidJSON(value) {
-			fmt.Printf("Error: Value for key '%s' is not valid JSON\n", key)
+			h.failf("Error: Value for key '%s' is not valid JSON\n", key)
 			fmt.Printf("Value: %s\n", value)
 			return true
 		}
@@ -764,12 +1061,14 @@ func (h *Handler) Execute(input string) bool {
 		// Query using JSONPath
 		result, err := jsonutil.QueryJSONPath(value, jsonPathExpr)
 		if err != nil {
-			fmt.Printf("JSONPath query error: %v\n", err)
+			h.failf("JSONPath query error: %v\n", err)
 			return true
 		}
 
 		// Format and display result
-		if pretty {
+		if h.structured() {
+			h.writeResult(output.JSONValue(result))
+		} else if pretty {
 			formattedResult := jsonutil.PrettyPrintWithNestedExpansion(result)
 			fmt.Printf("%s\n", formattedResult)
 		} else {
@@ -791,7 +1090,7 @@ func (h *Handler) Execute(input string) bool {
 		switch len(args) {
 		case 2: // jsonquery <field> <value> (using current CF)
 			if currentCF == "" {
-				fmt.Println("No current column family set")
+				h.failf("No current column family set\n")
 				return true
 			}
 			cf = currentCF
@@ -802,7 +1101,7 @@ func (h *Handler) Execute(input string) bool {
 			field = args[1]
 			value = args[2]
 		default:
-			fmt.Println("Usage: jsonquery [<cf>] <field> <value> [--pretty]")
+			h.failf("Usage: jsonquery [<cf>] <field> <value> [--pretty]\n")
 			fmt.Println("  Query entries by JSON field value")
 			fmt.Println("  Examples:")
 			fmt.Println("    jsonquery name \"Alice\"")
@@ -813,7 +1112,9 @@ func (h *Handler) Execute(input string) bool {
 
 		result, err := h.DB.JSONQueryCF(cf, field, value)
 		if err != nil {
-			handleError(err, "JSON query", cf)
+			h.reportError(err, "JSON query", cf)
+		} else if h.structured() {
+			h.writeResult(output.Entries(db.ScanPageResult{ResultsV2: output.EntriesOf(result)}, true))
 		} else {
 			if len(result) == 0 {
 				fmt.Printf("No entries found in '%s' where field '%s' = '%s'\n", cf, field, value)
@@ -825,6 +1126,18 @@ func (h *Handler) Execute(input string) bool {
 				}
 			}
 		}
+	case "tree":
+		h.executeTree(parts[1:])
+	case "range":
+		h.executeRange(parts[1:])
+	case "latest":
+		h.executeLatest(parts[1:])
+	case "tail":
+		h.executeTail(parts[1:])
+	case "histogram":
+		h.executeHistogram(parts[1:])
+	case "schema":
+		h.executeSchema(parts[1:])
 	case "stats":
 		// Parse flags and arguments
 		flags, args := parseFlags(parts[1:])
@@ -833,11 +1146,36 @@ func (h *Handler) Execute(input string) bool {
 
 		var cf string
 
+		if flags["ttl"] == "true" {
+			// stats [<cf>] --ttl (expiry distribution in TTL mode)
+			cf = ""
+			if len(args) == 1 {
+				cf = args[0]
+			} else if s, ok := h.State.(*ReplState); ok && s != nil {
+				cf = s.CurrentCF
+			}
+			if cf == "" || len(args) > 1 {
+				h.failf("Usage: stats [<cf>] --ttl [--pretty]\n")
+				return true
+			}
+			stats, err := h.DB.GetTTLStats(cf)
+			if err != nil {
+				h.reportError(err, "Get TTL statistics", cf)
+			} else if h.structured() {
+				h.writeResult(output.Object(stats))
+			} else {
+				formatTTLStats(stats, pretty)
+			}
+			return true
+		}
+
 		switch len(args) {
 		case 0: // stats (show database stats)
 			stats, err := h.DB.GetDatabaseStats()
 			if err != nil {
-				handleError(err, "Get database statistics")
+				h.reportError(err, "Get database statistics")
+			} else if h.structured() {
+				h.writeResult(output.DatabaseStats(stats))
 			} else {
 				h.formatDatabaseStats(stats, detailed, pretty)
 			}
@@ -845,12 +1183,14 @@ func (h *Handler) Execute(input string) bool {
 			cf = args[0]
 			stats, err := h.DB.GetCFStats(cf)
 			if err != nil {
-				handleError(err, "Get column family statistics", cf)
+				h.reportError(err, "Get column family statistics", cf)
+			} else if h.structured() {
+				h.writeResult(output.Object(stats))
 			} else {
 				h.formatCFStats(stats, detailed, pretty)
 			}
 		default:
-			fmt.Println("Usage: stats [<cf>] [--detailed] [--pretty]")
+			h.failf("Usage: stats [<cf>] [--detailed] [--ttl] [--pretty]\n")
 			fmt.Println("  Show database or column family statistics")
 			fmt.Println("  Examples:")
 			fmt.Println("    stats                    # Database overview")
@@ -858,6 +1198,7 @@ func (h *Handler) Execute(input string) bool {
 			fmt.Println("    stats --detailed         # Detailed database stats")
 			fmt.Println("    stats users --detailed   # Detailed stats for 'users' CF")
 			fmt.Println("    stats --pretty           # Pretty JSON output")
+			fmt.Println("    stats users --ttl        # Expiry distribution (TTL mode)")
 			return true
 		}
 	case "search":
@@ -883,7 +1224,7 @@ func (h *Handler) Execute(input string) bool {
 			// Either help request or search with only flags
 			if keyPattern == "" && valuePattern == "" {
 				// Show help if no patterns provided
-				fmt.Println("Usage: search [<cf>] [options]")
+				h.failf("Usage: search [<cf>] [options]\n")
 				fmt.Println("  Fuzzy search for keys and/or values in column family")
 				fmt.Println("")
 				fmt.Println("Options:")
@@ -894,6 +1235,7 @@ func (h *Handler) Execute(input string) bool {
 				fmt.Println("  --limit=N             Limit results (default: 50)")
 				fmt.Println("  --after=<key>         Start search after this key (for cursor-based pagination)")
 				fmt.Println("  --keys-only           Show only keys, not values")
+				fmt.Println("  --page                Page through the results interactively (page size: --limit, default 20)")
 				fmt.Println("  --tick                Treat keys as .NET tick times and convert to UTC string format")
 				fmt.Println("  --pretty              Pretty format JSON values")
 				fmt.Println("  --export=<file>       Export results to CSV file")
@@ -918,7 +1260,7 @@ func (h *Handler) Execute(input string) bool {
 			} else {
 				// Use current CF when only flags are provided
 				if currentCF == "" {
-					fmt.Println("No current column family set")
+					h.failf("No current column family set\n")
 					return true
 				}
 				cf = currentCF
@@ -929,7 +1271,7 @@ func (h *Handler) Execute(input string) bool {
 				cf = args[0]
 			} else {
 				if currentCF == "" {
-					fmt.Println("No current column family set")
+					h.failf("No current column family set\n")
 					return true
 				}
 				cf = currentCF
@@ -941,14 +1283,14 @@ func (h *Handler) Execute(input string) bool {
 
 		// Validate that at least one pattern is provided
 		if keyPattern == "" && valuePattern == "" {
-			fmt.Println("Error: Must specify at least --key or --value pattern")
+			h.failf("Error: Must specify at least --key or --value pattern\n")
 			return true
 		}
 
 		// If no CF specified, use current CF
 		if cf == "" {
 			if currentCF == "" {
-				fmt.Println("No current column family set")
+				h.failf("No current column family set\n")
 				return true
 			}
 			cf = currentCF
@@ -962,6 +1304,7 @@ func (h *Handler) Execute(input string) bool {
 			CaseSensitive: flags["case-sensitive"] == "true",
 			KeysOnly:      flags["keys-only"] == "true",
 			Tick:          flags["tick"] == "true",
+			After:         flags["after"],
 			Limit:         50, // Default limit
 		}
 
@@ -970,9 +1313,17 @@ func (h *Handler) Execute(input string) bool {
 			if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
 				opts.Limit = limit
 			} else {
-				fmt.Println("Invalid limit value")
+				h.failf("Invalid limit value\n")
 				return true
 			}
+		} else if flags["page"] == "true" {
+			opts.Limit = defaultPageSize
+		}
+
+		if flags["page"] == "true" {
+			src := &searchPages{db: h.DB, cf: cf, opts: opts}
+			h.runPager("Search", cf, src, pagerView{Values: !opts.KeysOnly})
+			return true
 		}
 
 		// Check for export option
@@ -989,7 +1340,9 @@ func (h *Handler) Execute(input string) bool {
 			// Export search results
 			err := h.DB.ExportSearchResultsToCSV(cf, exportFile, sep, opts)
 			if err != nil {
-				handleError(err, "Export search results", cf, exportFile)
+				h.reportError(err, "Export search results", cf, exportFile)
+			} else if h.structured() {
+				h.writeResult(output.Done(output.Status{Operation: "export", ColumnFamily: cf, File: exportFile}))
 			} else {
 				fmt.Printf("Search results exported to %s (sep=%q)\n", exportFile, sep)
 			}
@@ -999,7 +1352,9 @@ func (h *Handler) Execute(input string) bool {
 		// Execute search
 		results, err := h.DB.SearchCF(cf, opts)
 		if err != nil {
-			handleError(err, "Search", cf)
+			h.reportError(err, "Search", cf)
+		} else if h.structured() {
+			h.writeResult(output.Search(results, !opts.KeysOnly))
 		} else {
 			// Use highlighting with the search patterns
 			h.formatSearchResultsWithPattern(results, flags["pretty"] == "true",
@@ -1020,14 +1375,14 @@ func (h *Handler) Execute(input string) bool {
 		switch len(args) {
 		case 0: // keyformat (show current CF format)
 			if currentCF == "" {
-				fmt.Println("No current column family set")
+				h.failf("No current column family set\n")
 				return true
 			}
 			cf = currentCF
 		case 1: // keyformat <cf>
 			cf = args[0]
 		default:
-			fmt.Println("Usage: keyformat [<cf>]")
+			h.failf("Usage: keyformat [<cf>]\n")
 			fmt.Println("  Show the detected key format for a column family")
 			fmt.Println("  Provides examples of how to query binary keys with string inputs")
 			return true
@@ -1036,6 +1391,11 @@ func (h *Handler) Execute(input string) bool {
 		// Get key format information
 		keyFormat, description := h.DB.GetKeyFormatInfo(cf)
 
+		if h.structured() {
+			h.writeResult(output.Object(output.NewKeyFormat(cf, keyFormat, description)))
+			return true
+		}
+
 		fmt.Printf("Column Family: %s\n", cf)
 		fmt.Printf("Detected Key Format: %s\n", description)
 		fmt.Println()
@@ -1093,28 +1453,82 @@ func (h *Handler) Execute(input string) bool {
 				fmt.Printf("  %s\n", util.FormatKey(key))
 			}
 		}
+	case "tools":
+		h.executeTools(input)
+		return true
+	case "history":
+		h.showHistory(parts[1:])
+	case "query":
+		h.executeQuery(input)
+	case "set":
+		h.executeSet(input)
+	case "on-error":
+		h.executeOnError(parts[1:])
+	case "source":
+		return h.executeSource(parts[1:])
 	case "help":
 		fmt.Println("Available commands:")
 		fmt.Println("  usecf <cf>                    - Switch current column family")
 		fmt.Println("  get [<cf>] <key> [--pretty] [--smart=true|false]   - Query by key with smart binary conversion")
-		fmt.Println("  put [<cf>] <key> <value>      - Insert/Update key-value pair")
+		fmt.Println("  put [<cf>] <key> <value> [--ttl=<duration>] [--hex|--base64] - Insert/Update key-value pair")
+		fmt.Println("  inspect [<cf>] <key>          - Hex dump of a value with candidate decodings (ints, varints, protobuf, ticks...)")
+		fmt.Println("  merge [<cf>] <key> <operand>  - Apply a merge operand using the CF's merge operator")
 		fmt.Println("  prefix [<cf>] <prefix> [--pretty] [--smart=true|false] - Query by key prefix with smart conversion")
 		fmt.Println("  scan [<cf>] [start] [end]     - Scan range with options and smart conversion")
-		fmt.Println("    Options: --limit=N --reverse --values=no --timestamp --smart=true|false")
+		fmt.Println("    Options: --limit=N --reverse --values=no --timestamp --smart=true|false --page")
 		fmt.Println("    Use * as wildcard to scan all entries (e.g., scan * or scan * *)")
 		fmt.Println("  last [<cf>] [--pretty]        - Get last key-value pair from CF")
 		fmt.Println("  export [<cf>] <file_path>     - Export CF to CSV file")
 		fmt.Println("  jpath [<cf>] <key> <jsonpath> [--pretty] - Query JSON value using JSONPath")
 		fmt.Println("  jsonquery [<cf>] <field> <value> [--pretty] - Query entries by JSON field value")
 		fmt.Println("  stats [<cf>] [--detailed] [--pretty] - Show database/column family statistics")
+		fmt.Println("  stats [<cf>] --ttl            - Show expiry distribution (TTL mode)")
 		fmt.Println("  keyformat [<cf>]              - Show detected key format and conversion examples")
+		fmt.Println("  tree [<cf>] [--prefix=<p>]    - Key prefixes as a tree with counts and sizes")
+		fmt.Println("    Options: --delimiters=:/| --width=N --depth=N --max-children=N --hex")
+		fmt.Println("  range [<cf>] --from=<t> --to=<t> - Entries of a time-keyed CF between two times")
+		fmt.Println("    Options: --limit=N --reverse --after=<cursor> --values=no --encoding=ticks|ns|us|ms|s|iso --prefix=<p>")
+		fmt.Println("  latest [<cf>] [N]             - N newest entries by key time, newest first")
+		fmt.Println("  tail [<cf>] [N] [-f]          - N newest entries, then new ones as they are written")
+		fmt.Println("  histogram [<cf>] [--bucket=1h] - Entry counts per time bucket (--from, --to)")
+		fmt.Println("  schema infer [<cf>] [--save=<file>] - Infer a JSON Schema from sampled JSON values")
+		fmt.Println("    Options: --prefix=<p> --sample=N")
+		fmt.Println("  schema check [<cf>] --schema=<file> - Values violating a saved schema, and new fields")
+		fmt.Println("    Options: --prefix=<p> --limit=N")
 		fmt.Println("  listcf                        - List all column families")
-		fmt.Println("  createcf <cf>                 - Create new column family")
+		fmt.Println("  createcf <cf> [--profile=<name>] - Create new column family, optionally with a tuning profile")
+		fmt.Println("  cfoptions [<cf>] [--raw]      - Show column family options and OPTIONS file mismatches")
+		fmt.Println("  cfoptions --profiles          - List column family tuning profiles")
+		fmt.Println("  cfoptions --plugins           - List comparators and merge operators")
 		fmt.Println("  dropcf <cf>                   - Drop column family")
 		fmt.Println("  search [<cf>] [options]        - Fuzzy search for keys and/or values")
+		fmt.Println("  history [n]                   - Show the last n commands (default: 20)")
+		fmt.Println("  !n, !-n, !!, !<prefix>        - Re-run history entry n, the n-th last, the last, or the last starting with prefix")
+		fmt.Println("  query save <name> <command>   - Save a command as a named query")
+		fmt.Println("  query run <name> | query list | query delete <name> - Run, list or delete named queries")
+		fmt.Println("  set [pretty on|off]           - Show or change output settings")
+		fmt.Println("  set output <format>           - Default output format: text, table, json, jsonl, yaml, csv or raw")
+		fmt.Println("  set <name>=<value>            - Define a variable, used as ${name} in later commands")
+		fmt.Println("  source <file>                 - Run the commands of a script file")
+		fmt.Println("  on-error [stop|continue]      - Stop a script at the first failed command, or go on")
+		fmt.Println("  tools list [namespace]        - List local and remote MCP tools")
+		fmt.Println("  tools describe <name>         - Show a tool's description and arguments")
+		fmt.Println("  tools call <name> [arg=value ...] - Call a tool, e.g. tools call local.db-get key=user:1")
 		fmt.Println("  help                          - Show this help message")
 		fmt.Println("  exit/quit                     - Exit the CLI")
 		fmt.Println("")
+		fmt.Println("📖 Paging:")
+		fmt.Println("  Add --page to scan or search to page through the results, --limit entries per page:")
+		fmt.Println("    n/Enter next, p previous, g <key> go to key, r reverse, v values on/off,")
+		fmt.Println("    e <n> expand value n, q quit")
+		fmt.Println("  Piped or without a terminal, all pages are written one after another")
+		fmt.Println("")
+		fmt.Println("📄 Output Formats:")
+		fmt.Println("  Add --output=<format> to a data command to write its result for scripts, e.g.")
+		fmt.Println("    scan * --output=jsonl          # One JSON object per entry")
+		fmt.Println("    get user:1 --output=raw        # The value only")
+		fmt.Println("    stats --output=table           # One row per column family")
+		fmt.Println("")
 		fmt.Println("🔄 Smart Key Conversion:")
 		fmt.Println("  The CLI automatically detects binary key formats (uint64, hex) in column families")
 		fmt.Println("  When enabled (default), you can query binary keys using string inputs:")
@@ -1141,7 +1555,7 @@ func (h *Handler) Execute(input string) bool {
 		// Check if we're in read-only mode and show appropriate message
 		if h.DB.IsReadOnly() {
 			fmt.Println("  - Database is in READ-ONLY mode")
-			fmt.Println("  - Write operations (put, createcf, dropcf) are disabled")
+			fmt.Println("  - Write operations (put, merge, createcf, dropcf) are disabled")
 		} else {
 			fmt.Println("  - Current column family is shown in prompt: rocksdb[current_cf]>")
 		}
@@ -1163,11 +1577,160 @@ func (h *Handler) Execute(input string) bool {
 	case "exit", "quit":
 		return false
 	default:
-		fmt.Println("Unknown command. Type 'help' for available commands.")
+		h.failf("Unknown command. Type 'help' for available commands.\n")
 	}
 	return true
 }
 
+// formatCFOptions displays the options a column family runs with and any
+// stored options that could not be applied
+func formatCFOptions(opts *db.CFOptions, mismatches []db.OptionMismatch, raw bool) {
+	source := opts.Source
+	if opts.Profile != "" {
+		source = fmt.Sprintf("%s (%s)", source, opts.Profile)
+	}
+	orNone := func(v string) string {
+		if v == "" {
+			return "none"
+		}
+		return v
+	}
+
+	fmt.Printf("Column Family: %s\n", opts.Name)
+	fmt.Printf("  Source:            %s\n", source)
+	fmt.Printf("  Comparator:        %s\n", opts.Comparator)
+	fmt.Printf("  Merge operator:    %s\n", orNone(opts.MergeOperator))
+	fmt.Printf("  Prefix extractor:  %s\n", orNone(opts.PrefixExtractor))
+	fmt.Printf("  Compaction filter: %s\n", orNone(opts.CompactionFilter))
+	fmt.Printf("  Compression:       %s\n", opts.Compression)
+	fmt.Printf("  Write buffer size: %s\n", formatBytes(int64(opts.WriteBufferSize)))
+	if opts.BlockSize > 0 {
+		fmt.Printf("  Block size:        %s\n", formatBytes(int64(opts.BlockSize)))
+	}
+	fmt.Printf("  Filter policy:     %s\n", orNone(opts.FilterPolicy))
+	if opts.TTL > 0 && opts.TTL < 1<<62 {
+		fmt.Printf("  TTL:               %s\n", time.Duration(opts.TTL)*time.Second)
+	}
+
+	if raw && len(opts.Raw) > 0 {
+		fmt.Println()
+		fmt.Println("Stored options:")
+		keys := make([]string, 0, len(opts.Raw))
+		for k := range opts.Raw {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Printf("  %s=%s\n", k, opts.Raw[k])
+		}
+	}
+
+	if len(mismatches) > 0 {
+		fmt.Println()
+		fmt.Printf("⚠️  %d option mismatch(es):\n", len(mismatches))
+		for _, m := range mismatches {
+			fmt.Printf("  - %s\n", m)
+		}
+	}
+}
+
+// formatCFProfiles lists the profiles accepted by createcf --profile
+func formatCFProfiles(profiles []db.CFProfile) {
+	fmt.Println("Column family profiles:")
+	for _, p := range profiles {
+		fmt.Printf("  %-14s %s\n", p.Name, p.Description)
+		var details []string
+		if p.Compression != "" {
+			details = append(details, "compression="+p.Compression)
+		}
+		if p.BlockSize > 0 {
+			details = append(details, "block_size="+formatBytes(int64(p.BlockSize)))
+		}
+		if p.BloomBitsPerKey > 0 {
+			details = append(details, fmt.Sprintf("bloom_bits=%g", p.BloomBitsPerKey))
+		}
+		if p.TTL > 0 {
+			details = append(details, fmt.Sprintf("ttl=%s", time.Duration(p.TTL)*time.Second))
+		}
+		if len(details) > 0 {
+			fmt.Printf("  %-14s %s\n", "", strings.Join(details, " "))
+		}
+	}
+}
+
+// TTLIndex is the decoded expiry of paged scan results, by key as encoded
+// in the results
+type TTLIndex map[string]*db.TTLInfo
+
+// NewTTLIndex indexes the expiry of paged scan results
+func NewTTLIndex(results []db.KeyValue) TTLIndex {
+	expiry := make(TTLIndex, len(results))
+	for _, kv := range results {
+		if kv.TTL != nil {
+			expiry[kv.Key] = kv.TTL
+		}
+	}
+	return expiry
+}
+
+// Get returns the expiry of a raw key, or nil if it has none. The key is
+// encoded like scan results encode it, hex for binary keys and "N (0xX)"
+// for 8-byte ones.
+func (x TTLIndex) Get(key string) *db.TTLInfo {
+	encoded, _ := util.EncodeValue([]byte(key))
+	return x[encoded]
+}
+
+// formatTTLStats displays the expiry distribution of a column family
+func formatTTLStats(stats *db.TTLStats, pretty bool) {
+	if pretty {
+		if data, err := json.MarshalIndent(stats, "", "  "); err == nil {
+			fmt.Println(string(data))
+		} else {
+			fmt.Printf("Error formatting stats: %v\n", err)
+		}
+		return
+	}
+
+	fmt.Printf("=== TTL: %s ===\n", stats.Name)
+	fmt.Printf("Column family TTL: %s\n", stats.TTL)
+	fmt.Printf("Keys: %s\n", formatNumber(stats.KeyCount))
+	fmt.Printf("Expired (pending compaction): %s\n", formatNumber(stats.ExpiredCount))
+	if stats.InvalidCount > 0 {
+		fmt.Printf("Without timestamp: %s\n", formatNumber(stats.InvalidCount))
+	}
+	if stats.OldestWrite != nil {
+		fmt.Printf("Oldest write: %s\n", stats.OldestWrite.Format(time.RFC3339))
+		fmt.Printf("Newest write: %s\n", stats.NewestWrite.Format(time.RFC3339))
+	}
+	if stats.NextExpiry != nil {
+		fmt.Printf("Next expiry: %s\n", stats.NextExpiry.Format(time.RFC3339))
+	}
+
+	fmt.Println("\nExpires in:")
+	for _, bucket := range db.TTLBuckets {
+		count := stats.ExpiryDistribution[bucket]
+		if count == 0 {
+			continue
+		}
+		percentage := float64(count) / float64(stats.KeyCount) * 100
+		fmt.Printf("  %-10s %s (%.1f%%)\n", bucket, formatNumber(count), percentage)
+	}
+}
+
+// FormatPlugins lists the comparators and merge operators selectable per
+// column family in the config file
+func FormatPlugins(comparators []db.ComparatorPlugin, mergeOperators []db.MergeOperatorPlugin) {
+	fmt.Println("Comparators:")
+	for _, p := range comparators {
+		fmt.Printf("  %-18s %-36s %s\n", p.Name, p.RocksDBName, p.Description)
+	}
+	fmt.Println("Merge operators:")
+	for _, p := range mergeOperators {
+		fmt.Printf("  %-18s %-36s %s\n", p.Name, p.RocksDBName, p.Description)
+	}
+}
+
 // formatDatabaseStats formats and displays database-wide statistics
 func (h *Handler) formatDatabaseStats(stats *db.DatabaseStats, detailed, pretty bool) {
 	if pretty {
@@ -1175,7 +1738,7 @@ func (h *Handler) formatDatabaseStats(stats *db.DatabaseStats, detailed, pretty
 		if data, err := json.MarshalIndent(stats, "", "  "); err == nil {
 			fmt.Println(string(data))
 		} else {
-			fmt.Printf("Error formatting stats: %v\n", err)
+			h.failf("Error formatting stats: %v\n", err)
 		}
 		return
 	}
@@ -1211,7 +1774,7 @@ func (h *Handler) formatCFStats(stats *db.CFStats, detailed, pretty bool) {
 		if data, err := json.MarshalIndent(stats, "", "  "); err == nil {
 			fmt.Println(string(data))
 		} else {
-			fmt.Printf("Error formatting stats: %v\n", err)
+			h.failf("Error formatting stats: %v\n", err)
 		}
 		return
 	}
@@ -1378,6 +1941,9 @@ func (h *Handler) formatSearchResultsWithPattern(results *db.SearchResults, pret
 				fmt.Printf("    %s\n", line)
 			}
 		}
+		if result.TTL != nil {
+			fmt.Printf("    TTL: %s\n", result.TTL)
+		}
 
 		// Add separator between results (except for last one)
 		if i < len(results.Results)-1 {
@@ -8,15 +8,20 @@ import (
 	"io"
 	"os"
 	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/keytree"
+	"rocksdb-cli/internal/output"
 	"rocksdb-cli/internal/util"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 type mockDB struct {
 	data     map[string]map[string]string // cf -> key -> value
 	cfExists map[string]bool
+	ttl      map[string]map[string]*db.TTLInfo // cf -> key -> expiry, non-nil in TTL mode
 }
 
 func newMockDB() *mockDB {
@@ -192,6 +197,13 @@ func (m *mockDB) ExportToCSV(cf, filePath, sep string) error {
 	return nil
 }
 
+func (m *mockDB) ExportToCSVWithOptions(cf, filePath string, opts db.ExportOptions) (*db.ExportResult, error) {
+	if err := m.ExportToCSV(cf, filePath, opts.Sep); err != nil {
+		return nil, err
+	}
+	return &db.ExportResult{}, nil
+}
+
 func (m *mockDB) ExportSearchResultsToCSV(cf, filePath, sep string, opts db.SearchOptions) error {
 	if !m.cfExists[cf] {
 		return db.ErrColumnFamilyNotFound
@@ -228,7 +240,17 @@ func (m *mockDB) SearchCF(cf string, opts db.SearchOptions) (*db.SearchResults,
 		return results, nil
 	}
 
-	for key, value := range cfData {
+	keys := make([]string, 0, len(cfData))
+	for key := range cfData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := cfData[key]
+		if (opts.After != "" && key <= opts.After) || (opts.StartKey != "" && key < opts.StartKey) {
+			continue
+		}
 		var keyMatches, valueMatches bool
 		var matchedFields []string
 
@@ -278,6 +300,8 @@ func (m *mockDB) SearchCF(cf string, opts db.SearchOptions) (*db.SearchResults,
 			// Check limit
 			if opts.Limit > 0 && len(results.Results) >= opts.Limit {
 				results.Limited = true
+				results.HasMore = true
+				results.NextCursor = key
 				break
 			}
 		}
@@ -337,6 +361,37 @@ func (m *mockDB) GetCFStats(cf string) (*db.CFStats, error) {
 	return stats, nil
 }
 
+func (m *mockDB) GetCFStatsWithOptions(cf string, opts db.StatsOptions) (*db.CFStats, error) {
+	if opts.Prefix == "" && opts.JSONSamples == 0 {
+		return m.GetCFStats(cf)
+	}
+	if !m.cfExists[cf] {
+		return nil, db.ErrColumnFamilyNotFound
+	}
+	// Keys under the prefix, with the first JSON values as the sample
+	stats := &db.CFStats{Name: cf, DataTypeDistribution: make(map[db.DataType]int64)}
+	var keys []string
+	for key := range m.data[cf] {
+		if strings.HasPrefix(key, opts.Prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		stats.KeyCount++
+		value := m.data[cf][key]
+		if !strings.HasPrefix(value, "{") && !strings.HasPrefix(value, "[") {
+			stats.DataTypeDistribution[db.DataTypeString]++
+			continue
+		}
+		stats.DataTypeDistribution[db.DataTypeJSON]++
+		if len(stats.JSONSamples) < opts.JSONSamples {
+			stats.JSONSamples = append(stats.JSONSamples, value)
+		}
+	}
+	return stats, nil
+}
+
 func (m *mockDB) GetDatabaseStats() (*db.DatabaseStats, error) {
 	stats := &db.DatabaseStats{
 		ColumnFamilies:    make([]db.CFStats, 0),
@@ -355,6 +410,20 @@ func (m *mockDB) GetDatabaseStats() (*db.DatabaseStats, error) {
 	return stats, nil
 }
 
+func (m *mockDB) GetKeyTree(cf string, opts keytree.Options) (*keytree.Node, error) {
+	if !m.cfExists[cf] {
+		return nil, db.ErrColumnFamilyNotFound
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	b := keytree.NewBuilder(opts)
+	for key, value := range m.data[cf] {
+		b.Add([]byte(key), len(value))
+	}
+	return b.Tree(), nil
+}
+
 func (m *mockDB) JSONQueryCF(cf, field, value string) (map[string]string, error) {
 	if !m.cfExists[cf] {
 		return nil, db.ErrColumnFamilyNotFound
@@ -1396,7 +1465,7 @@ func TestStatsErrorHandling(t *testing.T) {
 		{
 			name:     "Invalid usage",
 			input:    "stats cf1 cf2 extra",
-			expected: "Usage: stats [<cf>] [--detailed] [--pretty]",
+			expected: "Usage: stats [<cf>] [--detailed] [--ttl] [--pretty]",
 		},
 	}
 
@@ -1603,20 +1672,340 @@ func (m *mockDB) GetKeyFormatInfo(cf string) (util.KeyFormat, string) {
 	return util.KeyFormatString, "Printable string keys"
 }
 
+func (m *mockDB) CreateCFWithProfile(cf, profile string) error {
+	if _, ok := db.GetCFProfile(profile); !ok {
+		return db.ErrUnknownProfile
+	}
+	return m.CreateCF(cf)
+}
+
+func (m *mockDB) GetCFOptions(cf string) (*db.CFOptions, error) {
+	if !m.cfExists[cf] {
+		return nil, db.ErrColumnFamilyNotFound
+	}
+	return &db.CFOptions{Name: cf, Source: "defaults", Comparator: "leveldb.BytewiseComparator", Compression: "kSnappyCompression"}, nil
+}
+
+func (m *mockDB) OptionMismatches() []db.OptionMismatch {
+	return nil
+}
+
+// MergeCF behaves like the stringappend merge operator
+func (m *mockDB) MergeCF(cf, key, operand string) error {
+	if !m.cfExists[cf] {
+		return db.ErrColumnFamilyNotFound
+	}
+	if existing, ok := m.data[cf][key]; ok {
+		operand = existing + "," + operand
+	}
+	m.data[cf][key] = operand
+	return nil
+}
+
+func (m *mockDB) PutCFWithTTL(cf, key, value string, ttl time.Duration) error {
+	if m.ttl == nil {
+		if ttl > 0 {
+			return db.ErrTTLModeDisabled
+		}
+		return m.PutCF(cf, key, value)
+	}
+	if err := m.PutCF(cf, key, value); err != nil {
+		return err
+	}
+	info := &db.TTLInfo{WriteTime: time.Now()}
+	if ttl > 0 {
+		expires := info.WriteTime.Add(ttl)
+		info.ExpiresAt = &expires
+	}
+	if m.ttl[cf] == nil {
+		m.ttl[cf] = make(map[string]*db.TTLInfo)
+	}
+	m.ttl[cf][key] = info
+	return nil
+}
+
+func (m *mockDB) GetCFWithTTL(cf, key string) (string, *db.TTLInfo, error) {
+	v, err := m.SmartGetCF(cf, key)
+	if err != nil {
+		return "", nil, err
+	}
+	return v, m.ttl[cf][key], nil
+}
+
+func (m *mockDB) GetTTLStats(cf string) (*db.TTLStats, error) {
+	if m.ttl == nil {
+		return nil, db.ErrTTLModeDisabled
+	}
+	if !m.cfExists[cf] {
+		return nil, db.ErrColumnFamilyNotFound
+	}
+	stats := &db.TTLStats{Name: cf, TTL: "none", ExpiryDistribution: make(map[string]int64)}
+	for _, info := range m.ttl[cf] {
+		stats.KeyCount++
+		if info.ExpiresAt == nil {
+			stats.ExpiryDistribution["never"]++
+		} else {
+			stats.ExpiryDistribution["< 1h"]++
+		}
+	}
+	return stats, nil
+}
+
+func (m *mockDB) TTLMode() bool {
+	return m.ttl != nil
+}
+
 // Add after ScanCF and SmartScanCF
 func (m *mockDB) ScanCFPage(cf string, start, end []byte, opts db.ScanOptions) (db.ScanPageResult, error) {
-	result, err := m.ScanCF(cf, start, end, opts)
+	limit := opts.Limit
+	opts.Limit = 0
+	all, err := m.ScanCF(cf, start, end, opts)
 	if err != nil {
 		return db.ScanPageResult{}, err
 	}
-	// Simulate cursor: just return all results, no real pagination for mock
-	return db.ScanPageResult{Results: result, NextCursor: "", HasMore: false}, nil
+
+	// Page through the sorted keys; the cursor is the plain last key
+	keys := make([]string, 0, len(all))
+	for k := range all {
+		if opts.StartAfter == "" || (!opts.Reverse && k > opts.StartAfter) || (opts.Reverse && k < opts.StartAfter) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	if opts.Reverse {
+		sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	}
+	page := db.ScanPageResult{Results: map[string]string{}, ResultsV2: []db.KeyValue{}}
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+		page.HasMore = true
+		page.NextCursor = keys[limit-1]
+	}
+	for _, k := range keys {
+		page.Results[k] = all[k]
+		entry := output.Entry(k, all[k])
+		entry.TTL = m.ttl[cf][k]
+		page.ResultsV2 = append(page.ResultsV2, entry)
+	}
+	return page, nil
 }
 
 func (m *mockDB) SmartScanCFPage(cf string, start, end string, opts db.ScanOptions) (db.ScanPageResult, error) {
-	result, err := m.SmartScanCF(cf, start, end, opts)
-	if err != nil {
-		return db.ScanPageResult{}, err
+	return m.ScanCFPage(cf, []byte(start), []byte(end), opts)
+}
+
+func TestCFOptionsCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "Current column family",
+			input:    "cfoptions",
+			expected: []string{"Column Family: default", "Comparator:        leveldb.BytewiseComparator", "Compression:       kSnappyCompression"},
+		},
+		{
+			name:     "Explicit column family",
+			input:    "cfoptions users",
+			expected: []string{"Column Family: users"},
+		},
+		{
+			name:     "Pretty JSON",
+			input:    "cfoptions users --pretty",
+			expected: []string{`"source": "defaults"`, `"mismatches": null`},
+		},
+		{
+			name:     "List profiles",
+			input:    "cfoptions --profiles",
+			expected: []string{"point-lookup", "compression=zstd", "ttl=24h0m0s"},
+		},
+		{
+			name:     "Non-existent column family",
+			input:    "cfoptions nonexistent",
+			expected: []string{"Column family 'nonexistent' does not exist"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mockDB := newTestHandler("default")
+			mockDB.CreateCF("users")
+
+			output := captureOutput(func() {
+				handler.Execute(tt.input)
+			})
+
+			for _, want := range tt.expected {
+				if !strings.Contains(output, want) {
+					t.Errorf("Expected output to contain %q, but got: %s", want, output)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateCFWithProfile(t *testing.T) {
+	handler, mockDB := newTestHandler("default")
+
+	output := captureOutput(func() {
+		handler.Execute("createcf events --profile=ephemeral")
+	})
+	if output != "OK\n" {
+		t.Errorf("Execute() output = %q, want %q", output, "OK\n")
+	}
+	if !mockDB.cfExists["events"] {
+		t.Error("Column family was not created")
+	}
+
+	output = captureOutput(func() {
+		handler.Execute("createcf other --profile=bogus")
+	})
+	if !strings.Contains(output, "unknown column family profile") {
+		t.Errorf("Expected unknown profile error, got: %s", output)
+	}
+	if mockDB.cfExists["other"] {
+		t.Error("Column family should not be created with an unknown profile")
+	}
+}
+
+func TestMergeCommand(t *testing.T) {
+	handler, mockDB := newTestHandler("default")
+	mockDB.CreateCF("tags")
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "merge in current cf", input: "merge k a", expected: "OK\n"},
+		{name: "merge with explicit cf", input: "merge tags k b", expected: "OK\n"},
+		{name: "merge again", input: "merge tags k c", expected: "OK\n"},
+		{name: "missing operand", input: "merge k", expected: "Usage: merge [<cf>] <key> <operand>"},
+		{name: "unknown cf", input: "merge nosuch k a", expected: "Column family 'nosuch' does not exist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := captureOutput(func() {
+				handler.Execute(tt.input)
+			})
+			if !strings.Contains(output, tt.expected) {
+				t.Errorf("Execute(%q) output = %q, want it to contain %q", tt.input, output, tt.expected)
+			}
+		})
+	}
+
+	if got := mockDB.data["tags"]["k"]; got != "b,c" {
+		t.Errorf("merged value = %q, want %q", got, "b,c")
+	}
+	if got := mockDB.data["default"]["k"]; got != "a" {
+		t.Errorf("merged value = %q, want %q", got, "a")
+	}
+}
+
+func TestPutEncodedAndInspect(t *testing.T) {
+	handler, mockDB := newTestHandler("default")
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "put hex", input: "put k 0x0896011205 --hex", expected: "OK\n"},
+		{name: "put base64", input: "put b aGVsbG8= --base64", expected: "OK\n"},
+		{name: "value starting with --", input: "put d --hex=no", expected: "OK\n"},
+		{name: "bad hex", input: "put k zz --hex", expected: "Invalid hex value"},
+		{name: "both encodings", input: "put k 00 --hex --base64", expected: "Use only one of --hex and --base64"},
+		{name: "inspect", input: "inspect k", expected: "00000000  08 96 01 12 05"},
+		{name: "inspect missing key", input: "inspect nosuch", expected: "not found"},
+		{name: "inspect usage", input: "inspect", expected: "Usage: inspect [<cf>] <key>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := captureOutput(func() {
+				handler.Execute(tt.input)
+			})
+			if !strings.Contains(output, tt.expected) {
+				t.Errorf("Execute(%q) output = %q, want it to contain %q", tt.input, output, tt.expected)
+			}
+		})
+	}
+
+	if got := mockDB.data["default"]["k"]; got != "\x08\x96\x01\x12\x05" {
+		t.Errorf("put --hex stored %q", got)
+	}
+	if got := mockDB.data["default"]["b"]; got != "hello" {
+		t.Errorf("put --base64 stored %q", got)
+	}
+	if got := mockDB.data["default"]["d"]; got != "--hex=no" {
+		t.Errorf("put stored %q, want the value as typed", got)
+	}
+
+	output := captureOutput(func() {
+		handler.Execute("inspect default b --output=json")
+	})
+	if !strings.Contains(output, `"size": 5`) || !strings.Contains(output, `"text": "hello"`) {
+		t.Errorf("inspect --output=json = %q, want the report", output)
+	}
+}
+
+func TestTTLCommands(t *testing.T) {
+	handler, mockDB := newTestHandler("default")
+
+	output := captureOutput(func() {
+		handler.Execute("put k v --ttl=1h")
+	})
+	if !strings.Contains(output, "not opened in TTL mode") {
+		t.Errorf("expected TTL mode error, got %q", output)
+	}
+	output = captureOutput(func() {
+		handler.Execute("stats --ttl")
+	})
+	if !strings.Contains(output, "not opened in TTL mode") {
+		t.Errorf("expected TTL mode error, got %q", output)
+	}
+
+	mockDB.ttl = make(map[string]map[string]*db.TTLInfo)
+	output = captureOutput(func() {
+		handler.Execute("put k v --ttl=1h")
+	})
+	if output != "OK\n" {
+		t.Fatalf("put --ttl output = %q, want OK", output)
+	}
+	output = captureOutput(func() {
+		handler.Execute("put k v --ttl=soon")
+	})
+	if !strings.Contains(output, "invalid TTL") {
+		t.Errorf("expected invalid TTL error, got %q", output)
+	}
+
+	output = captureOutput(func() {
+		handler.Execute("get k")
+	})
+	if !strings.Contains(output, "v\n") || !strings.Contains(output, "TTL: written") || !strings.Contains(output, "expires") {
+		t.Errorf("get output = %q, want value and expiry", output)
+	}
+
+	output = captureOutput(func() {
+		handler.Execute("stats --ttl")
+	})
+	for _, want := range []string{"=== TTL: default ===", "Keys: 1", "< 1h"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("stats --ttl output = %q, want it to contain %q", output, want)
+		}
+	}
+
+	// Scans show the expiry of binary and 8-byte keys too
+	mockDB.PutCFWithTTL("default", "\xff\x00\x01\x02", "binary", time.Hour)
+	mockDB.PutCFWithTTL("default", "\x00\x00\x00\x00\x00\x00\x00\x7b", "uint64", time.Hour)
+	output = captureOutput(func() {
+		handler.Execute("scan *")
+	})
+	for _, value := range []string{"v", "binary", "uint64"} {
+		if !strings.Contains(output, value+"  [expires") {
+			t.Errorf("scan output = %q, want the expiry of %q", output, value)
+		}
 	}
-	return db.ScanPageResult{Results: result, NextCursor: "", HasMore: false}, nil
 }
@@ -0,0 +1,260 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"rocksdb-cli/internal/output"
+)
+
+// defaultHistoryShown is how many commands history shows without a count
+const defaultHistoryShown = 20
+
+// AddHistory records a command, skipping blank lines and repeats of the
+// previous command
+func (s *ReplState) AddHistory(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+	if n := len(s.History); n > 0 && s.History[n-1] == line {
+		return
+	}
+	s.History = append(s.History, line)
+}
+
+// ExpandHistory replaces a history reference with the command it names:
+// !! is the last command, !n the n-th command as numbered by history, !-n
+// the n-th last command and !prefix the last command starting with prefix.
+// Other input is returned unchanged.
+func (s *ReplState) ExpandHistory(input string) (string, error) {
+	ref := strings.TrimSpace(input)
+	if !strings.HasPrefix(ref, "!") || len(ref) == 1 {
+		return input, nil
+	}
+	ref = ref[1:]
+
+	if ref == "!" {
+		if len(s.History) == 0 {
+			return "", fmt.Errorf("!!: history is empty")
+		}
+		return s.History[len(s.History)-1], nil
+	}
+
+	if n, err := strconv.Atoi(ref); err == nil {
+		index := n - 1
+		if n < 0 {
+			index = len(s.History) + n
+		}
+		if n == 0 || index < 0 || index >= len(s.History) {
+			return "", fmt.Errorf("!%s: event not found", ref)
+		}
+		return s.History[index], nil
+	}
+
+	for i := len(s.History) - 1; i >= 0; i-- {
+		if strings.HasPrefix(s.History[i], ref) {
+			return s.History[i], nil
+		}
+	}
+	return "", fmt.Errorf("!%s: event not found", ref)
+}
+
+// showHistory prints the last commands numbered for !n
+func (h *Handler) showHistory(args []string) {
+	s, ok := h.State.(*ReplState)
+	if !ok || s == nil {
+		h.failf("History is not available\n")
+		return
+	}
+
+	n := defaultHistoryShown
+	if len(args) > 0 {
+		parsed, err := strconv.Atoi(args[0])
+		if err != nil || parsed <= 0 {
+			h.failf("Usage: history [n]\n")
+			return
+		}
+		n = parsed
+	}
+
+	start := len(s.History) - n
+	if start < 0 {
+		start = 0
+	}
+	for i := start; i < len(s.History); i++ {
+		fmt.Printf("%5d  %s\n", i+1, s.History[i])
+	}
+}
+
+// executeQuery manages and runs named queries
+func (h *Handler) executeQuery(input string) {
+	s, ok := h.State.(*ReplState)
+	if !ok || s == nil {
+		h.failf("Named queries are not available\n")
+		return
+	}
+
+	parts := strings.Fields(input)
+	if len(parts) < 2 {
+		h.printQueryUsage()
+		return
+	}
+
+	switch parts[1] {
+	case "save":
+		if len(parts) < 4 {
+			h.failf("Usage: query save <name> <command>\n")
+			return
+		}
+		name := parts[2]
+		if s.Queries == nil {
+			s.Queries = make(map[string]string)
+		}
+		s.Queries[name] = afterFields(input, 3)
+		fmt.Printf("Saved query '%s': %s\n", name, s.Queries[name])
+	case "run":
+		if len(parts) != 3 {
+			h.failf("Usage: query run <name>\n")
+			return
+		}
+		saved, ok := s.Queries[parts[2]]
+		if !ok {
+			h.failf("Query '%s' not found. Use 'query list' to see saved queries.\n", parts[2])
+			return
+		}
+		fmt.Println(saved)
+		h.Execute(saved)
+	case "list":
+		if len(s.Queries) == 0 {
+			fmt.Println("No saved queries")
+			return
+		}
+		for _, name := range QueryNames(s) {
+			fmt.Printf("  %-20s %s\n", name, s.Queries[name])
+		}
+	case "delete":
+		if len(parts) != 3 {
+			h.failf("Usage: query delete <name>\n")
+			return
+		}
+		if _, ok := s.Queries[parts[2]]; !ok {
+			h.failf("Query '%s' not found\n", parts[2])
+			return
+		}
+		delete(s.Queries, parts[2])
+		fmt.Printf("Deleted query '%s'\n", parts[2])
+	default:
+		h.printQueryUsage()
+	}
+}
+
+func (h *Handler) printQueryUsage() {
+	h.failf("Usage: query save <name> <command> | query run <name> | query list | query delete <name>\n")
+	fmt.Println("  Example: query save admins search users --value=admin --limit=10")
+}
+
+// QueryNames returns the sorted names of the saved queries
+func QueryNames(s *ReplState) []string {
+	return sortedKeys(s.Queries)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// executeSet shows or changes output settings and defines variables
+func (h *Handler) executeSet(input string) {
+	s, ok := h.State.(*ReplState)
+	if !ok || s == nil {
+		h.failf("Settings are not available\n")
+		return
+	}
+
+	args := strings.Fields(input)[1:]
+	switch {
+	case len(args) > 0 && strings.Contains(args[0], "="):
+		h.setVar(s, afterFields(input, 1))
+	case len(args) == 0:
+		fmt.Printf("pretty: %s\n", onOff(s.Pretty))
+		fmt.Printf("output: %s\n", outputSetting(s))
+		for _, name := range sortedKeys(s.Vars) {
+			fmt.Printf("%s=%s\n", name, s.Vars[name])
+		}
+	case len(args) == 2 && args[0] == "pretty" && (args[1] == "on" || args[1] == "off"):
+		s.Pretty = args[1] == "on"
+		fmt.Printf("pretty: %s\n", onOff(s.Pretty))
+	case len(args) == 2 && args[0] == "output":
+		format, err := output.ParseFormat(args[1])
+		if err != nil {
+			h.failf("%v\n", err)
+			return
+		}
+		s.SetOutput(format)
+		fmt.Printf("output: %s\n", outputSetting(s))
+	default:
+		h.failf("Usage: set [pretty on|off] | set output <format> | set <name>=<value>\n")
+	}
+}
+
+// SetOutput sets the default output format of commands
+func (s *ReplState) SetOutput(format output.Format) {
+	s.Output = string(format)
+	if format == output.Text {
+		s.Output = ""
+	}
+}
+
+func outputSetting(s *ReplState) string {
+	if s.Output == "" {
+		return string(output.Text)
+	}
+	return s.Output
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+// applyOutputSettings adds the flags of the session's output settings to a
+// command that supports them and does not set them itself
+func (h *Handler) applyOutputSettings(cmd string, parts []string) []string {
+	s, ok := h.State.(*ReplState)
+	if !ok || s == nil || !s.Pretty {
+		return parts
+	}
+	spec, ok := LookupSpec(cmd)
+	if !ok || !spec.hasFlag("pretty") {
+		return parts
+	}
+	for _, part := range parts[1:] {
+		if part == "--pretty" || strings.HasPrefix(part, "--pretty=") {
+			return parts
+		}
+	}
+	return append(parts, "--pretty")
+}
+
+// afterFields returns input after its first n whitespace-separated fields,
+// keeping the spacing of the rest
+func afterFields(input string, n int) string {
+	rest := strings.TrimSpace(input)
+	for i := 0; i < n; i++ {
+		end := strings.IndexAny(rest, " \t")
+		if end < 0 {
+			return ""
+		}
+		rest = strings.TrimLeft(rest[end:], " \t")
+	}
+	return rest
+}
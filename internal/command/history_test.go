@@ -0,0 +1,174 @@
+package command
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAddHistory(t *testing.T) {
+	s := &ReplState{}
+	for _, line := range []string{"get a", "  ", "get a", "get b", " get a "} {
+		s.AddHistory(line)
+	}
+	want := []string{"get a", "get b", "get a"}
+	if !reflect.DeepEqual(s.History, want) {
+		t.Errorf("History = %q, want %q", s.History, want)
+	}
+}
+
+func TestExpandHistory(t *testing.T) {
+	s := &ReplState{History: []string{"usecf users", "get user:1", "scan a b", "get user:2"}}
+
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"get x", "get x", false},
+		{"!", "!", false},
+		{"!!", "get user:2", false},
+		{"!1", "usecf users", false},
+		{"!4", "get user:2", false},
+		{"!5", "", true},
+		{"!0", "", true},
+		{"!-1", "get user:2", false},
+		{"!-4", "usecf users", false},
+		{"!-5", "", true},
+		{"!sc", "scan a b", false},
+		{"!get", "get user:2", false},
+		{"!put", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := s.ExpandHistory(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandHistory(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ExpandHistory(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (&ReplState{}).ExpandHistory("!!"); err == nil {
+		t.Error("expected an error for !! with an empty history")
+	}
+}
+
+func TestHistoryCommand(t *testing.T) {
+	h, _ := newTestHandler("default")
+	state := h.State.(*ReplState)
+	for i := 1; i <= 25; i++ {
+		state.AddHistory("get key" + strings.Repeat("x", i))
+	}
+
+	out := captureOutput(func() { h.Execute("history") })
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != defaultHistoryShown {
+		t.Errorf("history printed %d lines, want %d:\n%s", len(lines), defaultHistoryShown, out)
+	}
+	if !strings.Contains(out, "   25  get key") || strings.Contains(out, "    5  get key") {
+		t.Errorf("history should show commands 6 to 25:\n%s", out)
+	}
+
+	out = captureOutput(func() { h.Execute("history 2") })
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 2 {
+		t.Errorf("history 2 printed %d lines:\n%s", len(lines), out)
+	}
+
+	out = captureOutput(func() { h.Execute("history x") })
+	if !strings.Contains(out, "Usage: history [n]") {
+		t.Errorf("expected usage, got:\n%s", out)
+	}
+}
+
+func TestQueryCommand(t *testing.T) {
+	h, mdb := newTestHandler("default")
+	mdb.PutCF("default", "user:1", "alice")
+	state := h.State.(*ReplState)
+
+	out := captureOutput(func() { h.Execute("query save one   get  user:1") })
+	if !strings.Contains(out, "Saved query 'one': get  user:1") {
+		t.Errorf("unexpected save output:\n%s", out)
+	}
+	if state.Queries["one"] != "get  user:1" {
+		t.Errorf("Queries = %v", state.Queries)
+	}
+
+	out = captureOutput(func() { h.Execute("query run one") })
+	if !strings.Contains(out, "alice") {
+		t.Errorf("query run did not run the saved command:\n%s", out)
+	}
+
+	out = captureOutput(func() { h.Execute("query list") })
+	if !strings.Contains(out, "one") || !strings.Contains(out, "get  user:1") {
+		t.Errorf("query list missing the saved query:\n%s", out)
+	}
+
+	out = captureOutput(func() { h.Execute("query run two") })
+	if !strings.Contains(out, "Query 'two' not found") {
+		t.Errorf("unexpected output for unknown query:\n%s", out)
+	}
+
+	captureOutput(func() { h.Execute("query delete one") })
+	if _, ok := state.Queries["one"]; ok {
+		t.Error("query delete did not delete the query")
+	}
+	out = captureOutput(func() { h.Execute("query list") })
+	if !strings.Contains(out, "No saved queries") {
+		t.Errorf("unexpected output after delete:\n%s", out)
+	}
+
+	out = captureOutput(func() { h.Execute("query save one") })
+	if !strings.Contains(out, "Usage: query save <name> <command>") {
+		t.Errorf("expected usage, got:\n%s", out)
+	}
+}
+
+func TestSetCommand(t *testing.T) {
+	h, _ := newTestHandler("default")
+	state := h.State.(*ReplState)
+
+	out := captureOutput(func() { h.Execute("set pretty on") })
+	if !state.Pretty || !strings.Contains(out, "pretty: on") {
+		t.Errorf("set pretty on: Pretty = %v, output:\n%s", state.Pretty, out)
+	}
+	out = captureOutput(func() { h.Execute("set") })
+	if !strings.Contains(out, "pretty: on") {
+		t.Errorf("set should show the settings:\n%s", out)
+	}
+	out = captureOutput(func() { h.Execute("set pretty maybe") })
+	if !state.Pretty || !strings.Contains(out, "Usage: set [pretty on|off]") {
+		t.Errorf("set pretty maybe: Pretty = %v, output:\n%s", state.Pretty, out)
+	}
+	captureOutput(func() { h.Execute("set pretty off") })
+	if state.Pretty {
+		t.Error("set pretty off did not clear Pretty")
+	}
+}
+
+func TestApplyOutputSettings(t *testing.T) {
+	h, _ := newTestHandler("default")
+
+	parts := []string{"get", "user:1"}
+	if got := h.applyOutputSettings("get", parts); !reflect.DeepEqual(got, parts) {
+		t.Errorf("without pretty: %q", got)
+	}
+
+	h.State.(*ReplState).Pretty = true
+	tests := []struct {
+		parts []string
+		want  []string
+	}{
+		{[]string{"get", "user:1"}, []string{"get", "user:1", "--pretty"}},
+		{[]string{"get", "user:1", "--pretty"}, []string{"get", "user:1", "--pretty"}},
+		{[]string{"put", "a", "b"}, []string{"put", "a", "b"}},
+		{[]string{"listcf"}, []string{"listcf"}},
+	}
+	for _, tt := range tests {
+		if got := h.applyOutputSettings(tt.parts[0], tt.parts); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("applyOutputSettings(%q) = %q, want %q", tt.parts, got, tt.want)
+		}
+	}
+}
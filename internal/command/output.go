@@ -0,0 +1,59 @@
+package command
+
+import (
+	"os"
+	"strings"
+
+	"rocksdb-cli/internal/output"
+)
+
+// outputFlag selects the output format of a command, see package output
+var outputFlag = Flag{Name: "output", Value: "format", Values: output.FormatNames()}
+
+// takeOutputFormat removes --output=<format> from the parts of a command
+// that supports it and selects the format for the command, by default the
+// session's output setting. put and merge take the flag only last, so their
+// values may still start with "--".
+func (h *Handler) takeOutputFormat(cmd string, parts []string) ([]string, error) {
+	h.format = output.Text
+	if s, ok := h.State.(*ReplState); ok && s != nil && s.Output != "" {
+		h.format = output.Format(s.Output)
+	}
+	spec, ok := LookupSpec(cmd)
+	if !ok || !spec.hasFlag(outputFlag.Name) {
+		h.format = output.Text
+		return parts, nil
+	}
+
+	first := 1
+	if (cmd == "put" || cmd == "merge") && len(parts) > 2 {
+		first = len(parts) - 1
+	}
+	kept := parts[:first:first]
+	for _, part := range parts[first:] {
+		name, ok := strings.CutPrefix(part, "--output=")
+		if !ok {
+			kept = append(kept, part)
+			continue
+		}
+		format, err := output.ParseFormat(name)
+		if err != nil {
+			return nil, err
+		}
+		h.format = format
+	}
+	return kept, nil
+}
+
+// structured reports whether the command writes its result in an output
+// format rather than as text
+func (h *Handler) structured() bool {
+	return h.format != "" && h.format != output.Text
+}
+
+// writeResult writes the result of a command in the selected output format
+func (h *Handler) writeResult(r output.Result) {
+	if err := output.Write(os.Stdout, h.format, r); err != nil {
+		h.failf("Output error: %v\n", err)
+	}
+}
@@ -0,0 +1,106 @@
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestOutputFormats(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"get json", "get user:1 --output=json", "{\n  \"key\": \"user:1\",\n  \"value\": \"Alice\","},
+		{"get raw", "get user:1 --output=raw", "Alice\n"},
+		{"scan jsonl", "scan * --output=jsonl", `{"key":"user:1","value":"Alice",`},
+		{"scan csv", "scan * --output=csv", "key,value\nuser:1,Alice\nuser:2,Bob\n"},
+		{"scan keys raw", "scan * --values=no --output=raw", "user:1\nuser:2\n"},
+		{"prefix table", "prefix user: --output=table", "KEY     VALUE\nuser:1  Alice\nuser:2  Bob\n"},
+		{"put yaml", "put user:3 Carol --output=yaml", "status: ok\noperation: put\ncolumn_family: default\nkey: user:3\n"},
+		{"listcf raw", "listcf --output=raw", "default\n"},
+		{"keyformat json", "keyformat --output=json", `"format": "string"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, mdb := newTestHandler("default")
+			mdb.data["default"] = map[string]string{"user:1": "Alice", "user:2": "Bob"}
+
+			out := captureOutput(func() { h.Execute(tt.input) })
+			if h.Failed() {
+				t.Fatalf("%s failed:\n%s", tt.input, out)
+			}
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("%s output:\n%s\nwant it to contain:\n%s", tt.input, out, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputSearchJSON(t *testing.T) {
+	h, mdb := newTestHandler("default")
+	mdb.data["default"] = map[string]string{"user:1": "Alice", "user:2": "Bob"}
+
+	out := captureOutput(func() { h.Execute("search --key=user --output=json") })
+	var results struct {
+		Results []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		} `json:"results"`
+		HasMore bool `json:"has_more"`
+	}
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		t.Fatalf("search --output=json is not JSON: %v\n%s", err, out)
+	}
+	if len(results.Results) != 2 || results.Results[0].Value == "" {
+		t.Errorf("unexpected search results: %+v", results)
+	}
+}
+
+func TestOutputSetting(t *testing.T) {
+	h, mdb := newTestHandler("default")
+	mdb.data["default"] = map[string]string{"user:1": "Alice"}
+	state := h.State.(*ReplState)
+
+	captureOutput(func() { h.Execute("set output raw") })
+	if state.Output != "raw" {
+		t.Fatalf("Output = %q, want raw", state.Output)
+	}
+	if out := captureOutput(func() { h.Execute("get user:1") }); out != "Alice\n" {
+		t.Errorf("get with output raw = %q", out)
+	}
+	// The flag overrides the setting
+	if out := captureOutput(func() { h.Execute("get user:1 --output=text") }); out != "Alice\n" {
+		t.Errorf("get --output=text = %q", out)
+	}
+	// Commands without formats keep their text output
+	if out := captureOutput(func() { h.Execute("usecf default") }); !strings.Contains(out, "Switched to column family") {
+		t.Errorf("usecf output = %q", out)
+	}
+
+	captureOutput(func() { h.Execute("set output text") })
+	if state.Output != "" {
+		t.Errorf("set output text should clear the setting, got %q", state.Output)
+	}
+
+	out := captureOutput(func() { h.Execute("get user:1 --output=xml") })
+	if !h.Failed() || !strings.Contains(out, `unknown output format "xml"`) {
+		t.Errorf("expected an unknown format failure, got:\n%s", out)
+	}
+	out = captureOutput(func() { h.Execute("set output xml") })
+	if !h.Failed() || state.Output != "" {
+		t.Errorf("set output xml should fail, got:\n%s", out)
+	}
+}
+
+func TestOutputPutValue(t *testing.T) {
+	h, mdb := newTestHandler("default")
+
+	// Only a trailing --output is a flag; values may start with --
+	out := captureOutput(func() { h.Execute("put k --output=json --output=raw") })
+	if out != "OK\n" || mdb.data["default"]["k"] != "--output=json" {
+		t.Errorf("put with a flag-like value: out=%q value=%q", out, mdb.data["default"]["k"])
+	}
+}
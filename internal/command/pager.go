@@ -0,0 +1,350 @@
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+
+	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/jsonutil"
+	"rocksdb-cli/internal/output"
+	"rocksdb-cli/internal/util"
+)
+
+// defaultPageSize is the page size of scan --page and search --page
+// without --limit
+const defaultPageSize = 20
+
+// pagerWidth is the number of characters of a value shown on a page;
+// e <n> shows the whole value
+const pagerWidth = 100
+
+// pagerView is what the pager shows: the direction, whether values are
+// shown and the key the pages start at after a jump
+type pagerView struct {
+	Reverse bool
+	Values  bool
+	From    string // empty to start at the start of the range
+}
+
+// pagerPage is one page of entries and the cursor of the next page
+type pagerPage struct {
+	Entries []db.KeyValue
+	Next    string
+	HasMore bool
+}
+
+// pageSource fetches the pages of a scan or a search
+type pageSource interface {
+	// fetch returns the page after cursor, the first page when it is empty
+	fetch(v pagerView, cursor string) (pagerPage, error)
+	// reversible reports whether pages can be fetched in reverse
+	reversible() bool
+}
+
+// scanPages pages through a key range with ScanCFPage
+type scanPages struct {
+	db         db.KeyValueDB
+	cf         string
+	start, end string
+	smart      bool
+	opts       db.ScanOptions
+}
+
+func (s *scanPages) fetch(v pagerView, cursor string) (pagerPage, error) {
+	opts := s.opts
+	opts.Reverse = v.Reverse
+	opts.Values = v.Values
+	opts.StartAfter = cursor
+
+	// A jump moves the edge of the range the pages start from; in reverse
+	// the range is half-open as usual, so the pages start before From. It
+	// stays within the range: a key outside it shows an empty page.
+	start, end := s.start, s.end
+	if v.From != "" {
+		from := s.key(v.From)
+		if v.Reverse {
+			if end == "" || bytes.Compare(from, s.key(end)) < 0 {
+				end = v.From
+			}
+		} else if start == "" || bytes.Compare(from, s.key(start)) > 0 {
+			start = v.From
+		}
+		if start != "" && end != "" && bytes.Compare(s.key(start), s.key(end)) >= 0 {
+			return pagerPage{}, nil
+		}
+	}
+
+	var page db.ScanPageResult
+	var err error
+	if s.smart {
+		page, err = s.db.SmartScanCFPage(s.cf, start, end, opts)
+	} else {
+		page, err = s.db.ScanCFPage(s.cf, []byte(start), []byte(end), opts)
+	}
+	return pagerPage{Entries: page.ResultsV2, Next: page.NextCursor, HasMore: page.HasMore}, err
+}
+
+// key returns the bytes a bound of the range is scanned from, converted to
+// the key format of the column family as SmartScanCFPage does
+func (s *scanPages) key(bound string) []byte {
+	if s.smart {
+		format, _ := s.db.GetKeyFormatInfo(s.cf)
+		if k, err := util.ConvertStringToKeyForScan(bound, format, false); err == nil {
+			return k
+		}
+	}
+	return []byte(bound)
+}
+
+func (s *scanPages) reversible() bool { return true }
+
+// searchPages pages through the results of a search with SearchCF
+type searchPages struct {
+	db   db.KeyValueDB
+	cf   string
+	opts db.SearchOptions
+}
+
+func (s *searchPages) fetch(v pagerView, cursor string) (pagerPage, error) {
+	opts := s.opts
+	opts.KeysOnly = !v.Values
+	opts.After = cursor
+	// A jump stays within the range like in scanPages
+	if v.From != "" {
+		from := s.key(v.From)
+		if opts.StartKey == "" || opts.StartKey == "*" || bytes.Compare(from, s.key(opts.StartKey)) > 0 {
+			opts.StartKey = v.From
+		}
+		if opts.EndKey != "" && opts.EndKey != "*" && bytes.Compare(s.key(opts.StartKey), s.key(opts.EndKey)) >= 0 {
+			return pagerPage{}, nil
+		}
+	}
+
+	results, err := s.db.SearchCF(s.cf, opts)
+	if err != nil {
+		return pagerPage{}, err
+	}
+	page := pagerPage{Next: results.NextCursor, HasMore: results.HasMore}
+	for _, sr := range results.Results {
+		page.Entries = append(page.Entries, db.KeyValue{
+			Key:           sr.Key,
+			Value:         sr.Value,
+			KeyIsBinary:   sr.KeyIsBinary,
+			ValueIsBinary: sr.ValueIsBinary,
+			Timestamp:     sr.Timestamp,
+			TTL:           sr.TTL,
+		})
+	}
+	return page, nil
+}
+
+// key returns the bytes a bound of the range is searched from, converted
+// to the key format of the column family as SearchCF does
+func (s *searchPages) key(bound string) []byte {
+	if format, _ := s.db.GetKeyFormatInfo(s.cf); format == util.KeyFormatUint64BE {
+		if k, err := util.ConvertStringToKey(bound, format); err == nil {
+			return k
+		}
+	}
+	return []byte(bound)
+}
+
+// SearchCF only iterates forward
+func (s *searchPages) reversible() bool { return false }
+
+// pagerHelp lists the keys of the interactive pager
+const pagerHelp = `  Enter, n    next page
+  p           previous page
+  g <key>     go to <key>, or to the keys before it in reverse
+  r           reverse the direction, from the other end of the range
+  v           toggle values and keys only
+  e <n>       expand the value of entry <n> of the page
+  q           quit the pager`
+
+// pagerInput returns where the interactive pager reads its keys and whether
+// it is interactive: with PagerInput set, or on a terminal. Otherwise the
+// pages are written one after another, so the output can be piped to less.
+func (h *Handler) pagerInput() (io.Reader, bool) {
+	if h.PagerInput != nil {
+		return h.PagerInput, true
+	}
+	interactive := isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stdout.Fd())
+	return os.Stdin, interactive
+}
+
+// runPager shows the pages of src one at a time and reads what to show next,
+// one command per line
+func (h *Handler) runPager(operation, cf string, src pageSource, view pagerView) {
+	in, interactive := h.pagerInput()
+	if !interactive {
+		h.writePages(operation, cf, src, view)
+		return
+	}
+
+	// cursors holds the cursor of each page up to the current one
+	cursors := []string{""}
+	page, ok := h.fetchPage(operation, cf, src, view, "")
+	if !ok {
+		return
+	}
+	lines := bufio.NewScanner(in)
+	show := true
+	for {
+		if show {
+			showPage(page, view, len(cursors))
+		}
+		show = true
+		fmt.Print("(n)ext (p)rev (g)o <key> (r)everse (v)alues (e)xpand <n> (q)uit: ")
+		if !lines.Scan() {
+			fmt.Println()
+			return
+		}
+
+		key, arg, _ := strings.Cut(strings.TrimSpace(lines.Text()), " ")
+		arg = strings.TrimSpace(arg)
+		cursor := cursors[len(cursors)-1]
+		switch key {
+		case "", "n":
+			if !page.HasMore {
+				fmt.Println("Last page")
+				show = false
+				continue
+			}
+			cursor = page.Next
+			cursors = append(cursors, cursor)
+		case "p":
+			if len(cursors) == 1 {
+				fmt.Println("First page")
+				show = false
+				continue
+			}
+			cursors = cursors[:len(cursors)-1]
+			cursor = cursors[len(cursors)-1]
+		case "g":
+			if arg == "" {
+				fmt.Println("Usage: g <key>")
+				show = false
+				continue
+			}
+			view.From = arg
+			cursors, cursor = []string{""}, ""
+		case "r":
+			if !src.reversible() {
+				fmt.Println("Search results can only be paged forward")
+				show = false
+				continue
+			}
+			view.Reverse = !view.Reverse
+			view.From = ""
+			cursors, cursor = []string{""}, ""
+		case "v":
+			view.Values = !view.Values
+		case "e":
+			expandEntry(page, view, arg)
+			show = false
+			continue
+		case "q":
+			return
+		default:
+			fmt.Println(pagerHelp)
+			show = false
+			continue
+		}
+
+		next, ok := h.fetchPage(operation, cf, src, view, cursor)
+		if !ok {
+			return
+		}
+		page = next
+	}
+}
+
+func (h *Handler) fetchPage(operation, cf string, src pageSource, view pagerView, cursor string) (pagerPage, bool) {
+	page, err := src.fetch(view, cursor)
+	if err != nil {
+		h.reportError(err, operation, cf)
+		return pagerPage{}, false
+	}
+	return page, true
+}
+
+// writePages writes every page of src without prompting, in the selected
+// output format
+func (h *Handler) writePages(operation, cf string, src pageSource, view pagerView) {
+	cursor := ""
+	for {
+		page, ok := h.fetchPage(operation, cf, src, view, cursor)
+		if !ok {
+			return
+		}
+		if h.structured() {
+			h.writeResult(output.Entries(db.ScanPageResult{
+				ResultsV2: page.Entries, NextCursor: page.Next, HasMore: page.HasMore,
+			}, view.Values))
+		} else {
+			for _, kv := range page.Entries {
+				fmt.Println(entryLine(kv, view.Values, 0))
+			}
+		}
+		if !page.HasMore {
+			return
+		}
+		cursor = page.Next
+	}
+}
+
+func showPage(page pagerPage, view pagerView, number int) {
+	direction := "forward"
+	if view.Reverse {
+		direction = "reverse"
+	}
+	fmt.Printf("-- page %d, %d entries, %s --\n", number, len(page.Entries), direction)
+	if len(page.Entries) == 0 {
+		fmt.Println("No entries")
+	}
+	for i, kv := range page.Entries {
+		fmt.Printf("%3d  %s\n", i+1, entryLine(kv, view.Values, pagerWidth))
+	}
+	if !page.HasMore {
+		fmt.Println("-- end --")
+	}
+}
+
+// entryLine formats an entry like scan does, cutting the value to width
+// characters when width is positive
+func entryLine(kv db.KeyValue, values bool, width int) string {
+	if !values {
+		return kv.Key
+	}
+	value := kv.Value
+	if r := []rune(value); width > 0 && len(r) > width {
+		value = string(r[:width]) + "..."
+	}
+	if kv.TTL != nil {
+		value = fmt.Sprintf("%s  [%s]", value, kv.TTL)
+	}
+	return fmt.Sprintf("%s: %s", kv.Key, value)
+}
+
+// expandEntry prints the value of entry n of the page with nested JSON
+// expanded
+func expandEntry(page pagerPage, view pagerView, arg string) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > len(page.Entries) {
+		fmt.Printf("Usage: e <n> with n from 1 to %d\n", len(page.Entries))
+		return
+	}
+	if !view.Values {
+		fmt.Println("Values are hidden, show them with v")
+		return
+	}
+	kv := page.Entries[n-1]
+	fmt.Printf("%s:\n%s\n", kv.Key, jsonutil.PrettyPrintWithNestedExpansion(kv.Value))
+}
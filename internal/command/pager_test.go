@@ -0,0 +1,137 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func newPagerHandler(keys int, input string) (*Handler, *mockDB) {
+	h, mdb := newTestHandler("default")
+	for i := 1; i <= keys; i++ {
+		mdb.data["default"][fmt.Sprintf("k%02d", i)] = fmt.Sprintf(`{"n":%d,"doc":"{\"nested\":true}"}`, i)
+	}
+	h.PagerInput = strings.NewReader(input)
+	return h, mdb
+}
+
+// pages returns the pages the pager showed, split at the page headers
+func pages(out string) []string {
+	parts := strings.Split(out, "-- page ")
+	return parts[1:]
+}
+
+func TestPagerScan(t *testing.T) {
+	h, _ := newPagerHandler(5, "n\nn\nn\np\np\np\nq\n")
+	out := captureOutput(func() { h.Execute("scan * --page --limit=2") })
+	if h.Failed() {
+		t.Fatalf("scan --page failed:\n%s", out)
+	}
+
+	shown := pages(out)
+	if len(shown) != 5 {
+		t.Fatalf("expected 5 pages, got %d:\n%s", len(shown), out)
+	}
+	for i, want := range []string{"k01", "k03", "k05", "k03", "k01"} {
+		if !strings.Contains(shown[i], "  1  "+want+": ") {
+			t.Errorf("page %d should start at %s:\n%s", i+1, want, shown[i])
+		}
+	}
+	if !strings.Contains(out, "Last page") || !strings.Contains(out, "First page") {
+		t.Errorf("paging past either end should be reported:\n%s", out)
+	}
+	if !strings.Contains(shown[2], "-- end --") {
+		t.Errorf("the last page should be marked:\n%s", shown[2])
+	}
+}
+
+func TestPagerNavigation(t *testing.T) {
+	h, _ := newPagerHandler(5, "g k03\nr\nn\nv\ne 1\nv\ne 1\nx\n")
+	out := captureOutput(func() { h.Execute("scan * --page --limit=2") })
+
+	shown := pages(out)
+	if len(shown) != 6 {
+		t.Fatalf("expected 6 pages, got %d:\n%s", len(shown), out)
+	}
+	if !strings.Contains(shown[1], "  1  k03: ") || !strings.Contains(shown[1], "  2  k04: ") {
+		t.Errorf("g k03 should go to k03:\n%s", shown[1])
+	}
+	if !strings.Contains(shown[2], "reverse") || !strings.Contains(shown[2], "  1  k05: ") {
+		t.Errorf("r should start over from the end in reverse:\n%s", shown[2])
+	}
+	if !strings.Contains(shown[3], "  1  k03: ") {
+		t.Errorf("n in reverse should go on backwards:\n%s", shown[3])
+	}
+	if !strings.Contains(shown[4], "  1  k03\n") {
+		t.Errorf("v should hide the values:\n%s", shown[4])
+	}
+	if !strings.Contains(shown[4], "Values are hidden") {
+		t.Errorf("e without values should say so:\n%s", shown[4])
+	}
+	if !strings.Contains(shown[5], "k03:\n{\n  \"doc\": {\n    \"nested\": true\n  },") {
+		t.Errorf("e 1 should expand the nested JSON of k03:\n%s", shown[5])
+	}
+	if !strings.Contains(out, "g <key>") {
+		t.Errorf("an unknown key should show the pager help:\n%s", out)
+	}
+}
+
+func TestPagerJumpInRange(t *testing.T) {
+	h, _ := newPagerHandler(5, "g k01\ng k05\nr\ng k05\ng k02\nq\n")
+	out := captureOutput(func() { h.Execute("scan k02 k04 --page --limit=5") })
+
+	shown := pages(out)
+	if len(shown) != 6 {
+		t.Fatalf("expected 6 pages, got %d:\n%s", len(shown), out)
+	}
+	if !strings.Contains(shown[1], "  1  k02: ") || strings.Contains(shown[1], "k01") {
+		t.Errorf("g before the range should start at its start:\n%s", shown[1])
+	}
+	if !strings.Contains(shown[2], "No entries") {
+		t.Errorf("g past the range should show an empty page:\n%s", shown[2])
+	}
+	if !strings.Contains(shown[4], "  1  k03: ") || strings.Contains(shown[4], "k04") || strings.Contains(shown[4], "k05") {
+		t.Errorf("g past the range in reverse should start at its end:\n%s", shown[4])
+	}
+	if !strings.Contains(shown[5], "No entries") {
+		t.Errorf("g before the range in reverse should show an empty page:\n%s", shown[5])
+	}
+}
+
+func TestPagerSearch(t *testing.T) {
+	h, mdb := newPagerHandler(5, "n\nr\ng k04\nq\n")
+	mdb.data["default"]["other"] = "x"
+	out := captureOutput(func() { h.Execute("search --key=k --page --limit=3") })
+	if h.Failed() {
+		t.Fatalf("search --page failed:\n%s", out)
+	}
+
+	shown := pages(out)
+	if len(shown) != 3 {
+		t.Fatalf("expected 3 pages, got %d:\n%s", len(shown), out)
+	}
+	if !strings.Contains(shown[1], "  1  k04: ") || strings.Contains(out, "other") {
+		t.Errorf("the second page should continue after the cursor:\n%s", shown[1])
+	}
+	if !strings.Contains(out, "can only be paged forward") {
+		t.Errorf("search should not reverse:\n%s", out)
+	}
+	if !strings.Contains(shown[2], "  1  k04: ") || !strings.Contains(shown[2], "  2  k05: ") {
+		t.Errorf("g k04 should start the results at k04:\n%s", shown[2])
+	}
+}
+
+func TestPagerPiped(t *testing.T) {
+	// Without a terminal the pages are written one after another
+	h, _ := newPagerHandler(5, "")
+	h.PagerInput = nil
+	out := captureOutput(func() { h.Execute("scan * --page --limit=2 --values=no") })
+	if out != "k01\nk02\nk03\nk04\nk05\n" {
+		t.Errorf("piped scan --page = %q, want every key", out)
+	}
+
+	out = captureOutput(func() { h.Execute("search --key=k --page --limit=2 --output=raw --keys-only") })
+	if out != "k01\nk02\nk03\nk04\nk05\n" {
+		t.Errorf("piped search --page --output=raw = %q, want every key", out)
+	}
+}
@@ -0,0 +1,162 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"rocksdb-cli/internal/output"
+	"rocksdb-cli/internal/schema"
+)
+
+// executeSchema handles schema infer [<cf>] and schema check [<cf>]
+func (h *Handler) executeSchema(args []string) {
+	flags, args := parseFlags(args)
+	if len(args) > 0 && args[0] == "infer" {
+		h.executeSchemaInfer(args[1:], flags)
+		return
+	}
+	if len(args) > 0 && args[0] == "check" {
+		h.executeSchemaCheck(args[1:], flags)
+		return
+	}
+	h.failf("Usage: schema infer [<cf>] [--prefix=<prefix>] [--sample=N] [--save=<file>]\n")
+	fmt.Println("       schema check [<cf>] --schema=<file> [--prefix=<prefix>] [--limit=N]")
+	fmt.Println("  infer merges a sample of the JSON values (1000 by default) into a JSON Schema with")
+	fmt.Println("  the share of the values having each field, type unions, enum candidates and examples")
+	fmt.Println("  check lists the values that violate a saved schema and the fields it does not know;")
+	fmt.Println("  the column family and prefix default to the ones the schema was inferred from")
+	fmt.Println("  Examples:")
+	fmt.Println("    schema infer users --prefix=user: --save=users.schema.json")
+	fmt.Println("    schema check --schema=users.schema.json")
+}
+
+// executeSchemaInfer handles schema infer [<cf>] [--prefix=<prefix>]
+// [--sample=N] [--save=<file>]
+func (h *Handler) executeSchemaInfer(args []string, flags map[string]string) {
+	cf := ""
+	if s, ok := h.State.(*ReplState); ok && s != nil {
+		cf = s.CurrentCF
+	}
+	if len(args) == 1 {
+		cf = args[0]
+	}
+	if cf == "" || len(args) > 1 {
+		h.failf("Usage: schema infer [<cf>] [--prefix=<prefix>] [--sample=N] [--save=<file>]\n")
+		return
+	}
+	n := schema.DefaultSamples
+	if v, ok := flags["sample"]; ok {
+		var err error
+		if n, err = strconv.Atoi(v); err != nil || n <= 0 {
+			h.failf("Invalid sample size\n")
+			return
+		}
+	}
+
+	s, _, err := schema.InferCF(h.DB, cf, flags["prefix"], n, nil)
+	if err != nil {
+		h.reportError(err, "Schema inference", cf)
+		return
+	}
+	file := flags["save"]
+	if file != "" {
+		data, _ := json.MarshalIndent(s, "", "  ")
+		if err := os.WriteFile(file, append(data, '\n'), 0o644); err != nil {
+			h.failf("Cannot save schema: %v\n", err)
+			return
+		}
+	}
+	if h.structured() {
+		h.writeResult(output.Schema(s))
+		if file != "" {
+			// Keep stdout to the schema in an output format
+			fmt.Fprintf(os.Stderr, "Saved schema to %s\n", file)
+		}
+		return
+	}
+
+	src := s.Source
+	fmt.Printf("Schema of '%s'%s from %d of %d JSON values (%d keys)\n", cf, underPrefix(src.Prefix), src.Sampled, src.JSONValues, src.Keys)
+	output.Write(os.Stdout, output.Table, output.Schema(s))
+	if file != "" {
+		fmt.Printf("Saved schema to %s\n", file)
+	}
+}
+
+// executeSchemaCheck handles schema check [<cf>] --schema=<file>
+// [--prefix=<prefix>] [--limit=N]. Values violating the schema fail the
+// command, so scripts can stop on drift.
+func (h *Handler) executeSchemaCheck(args []string, flags map[string]string) {
+	file := flags["schema"]
+	if file == "" || len(args) > 1 {
+		h.failf("Usage: schema check [<cf>] --schema=<file> [--prefix=<prefix>] [--limit=N]\n")
+		return
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		h.failf("Cannot read schema: %v\n", err)
+		return
+	}
+	s, err := schema.Parse(data)
+	if err != nil {
+		h.failf("%v\n", err)
+		return
+	}
+
+	cf, prefix := "", ""
+	if s.Source != nil {
+		cf, prefix = s.Source.ColumnFamily, s.Source.Prefix
+	} else if st, ok := h.State.(*ReplState); ok && st != nil {
+		cf = st.CurrentCF
+	}
+	if len(args) == 1 {
+		cf = args[0]
+	}
+	if v, ok := flags["prefix"]; ok {
+		prefix = v
+	}
+	if cf == "" {
+		h.failf("No column family to check: give one or use a schema saved by schema infer\n")
+		return
+	}
+	limit := schema.DefaultLimit
+	if v, ok := flags["limit"]; ok {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			h.failf("Invalid limit value\n")
+			return
+		}
+	}
+
+	c := schema.NewChecker(s, limit)
+	if err := schema.CheckCF(h.DB, cf, prefix, c, nil); err != nil {
+		h.reportError(err, "Schema check", cf)
+		return
+	}
+	report := c.Report()
+	if report.Violating > 0 {
+		h.failed = true
+	}
+	if h.structured() {
+		h.writeResult(output.SchemaReport(report))
+		return
+	}
+
+	fmt.Printf("Checked %d values of '%s'%s against %s: %d violate the schema (%d violations), %d new fields\n",
+		report.Checked, cf, underPrefix(prefix), file, report.Violating, report.ViolationCount, len(report.NewFields))
+	if len(report.Violations) > 0 || len(report.NewFields) > 0 {
+		output.Write(os.Stdout, output.Table, output.SchemaReport(report))
+	}
+	if n := int64(len(report.Violations)); report.ViolationCount > n {
+		fmt.Printf("Showing the first %d violations, add --limit=N to see more\n", n)
+	}
+}
+
+// underPrefix describes a key prefix, as in " under 'user:'"
+func underPrefix(prefix string) string {
+	if prefix == "" {
+		return ""
+	}
+	return fmt.Sprintf(" under '%s'", prefix)
+}
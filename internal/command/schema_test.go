@@ -0,0 +1,92 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSchemaCommands(t *testing.T) {
+	handler, mockDB := newTestHandler("default")
+	mockDB.CreateCF("users")
+	mockDB.PutCF("users", "user:1", `{"id": 1, "name": "Alice", "status": "active"}`)
+	mockDB.PutCF("users", "user:2", `{"id": 2, "name": "Bob", "status": "active", "email": "bob@example.com"}`)
+	mockDB.PutCF("users", "user:3", `{"id": 3, "name": "Carol", "status": "inactive"}`)
+	mockDB.PutCF("users", "user:4", `{"id": 4, "name": "Dave", "status": "active"}`)
+	mockDB.PutCF("users", "config", "plain")
+	file := filepath.Join(t.TempDir(), "users.schema.json")
+
+	steps := []struct {
+		name     string
+		input    string
+		setup    func()
+		expected []string
+		failed   bool
+	}{
+		{
+			name:  "infer",
+			input: "schema infer users --prefix=user: --save=" + file,
+			expected: []string{
+				"Schema of 'users' under 'user:' from 4 of 4 JSON values (4 keys)\n",
+				"$.email   string   25        false                           \"bob@example.com\"\n",
+				`$.status  string   100       true      "active", "inactive"`,
+				"Saved schema to " + file + "\n",
+			},
+		},
+		{name: "matching values", input: "schema check --schema=" + file, expected: []string{
+			"Checked 4 values of 'users' under 'user:' against " + file + ": 0 violate the schema (0 violations), 0 new fields\n",
+		}},
+		{
+			name:  "drift",
+			input: "schema check --schema=" + file,
+			setup: func() {
+				mockDB.PutCF("users", "user:5", `{"id": "5", "name": "Eve", "status": "archived", "phone": "555"}`)
+				mockDB.PutCF("users", "user:6", `{"id": 6, "status": "active"}`)
+			},
+			expected: []string{
+				"Checked 6 values of 'users' under 'user:' against " + file + ": 2 violate the schema (3 violations), 1 new fields\n",
+				"user:5  $.id      is string, want integer",
+				`user:5  $.status  "archived" is not one of "active", "inactive"`,
+				"user:6  $.name    required field is missing",
+				"user:5  $.phone   new field (string) in 1 documents",
+			},
+			failed: true,
+		},
+		{name: "limit", input: "schema check --schema=" + file + " --limit=1", expected: []string{
+			"Showing the first 1 violations, add --limit=N to see more\n",
+		}, failed: true},
+		{name: "other prefix", input: "schema check --schema=" + file + " --prefix=config", expected: []string{
+			"config  $     not a JSON document",
+		}, failed: true},
+		{name: "jsonl", input: "schema check --schema=" + file + " --output=jsonl", expected: []string{
+			`{"key":"user:6","path":"$.name","problem":"required field is missing"}` + "\n",
+		}, failed: true},
+		{name: "no JSON values", input: "schema infer users --prefix=config", expected: []string{"no JSON values"}, failed: true},
+		{name: "unknown cf", input: "schema infer nosuch", expected: []string{"Column family 'nosuch' does not exist"}, failed: true},
+		{name: "missing schema", input: "schema check users", expected: []string{"Usage: schema check"}, failed: true},
+		{name: "usage", input: "schema", expected: []string{"Usage: schema infer", "schema check [<cf>]"}, failed: true},
+	}
+	for _, tt := range steps {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+			output := captureOutput(func() {
+				handler.Execute(tt.input)
+			})
+			for _, want := range tt.expected {
+				if !strings.Contains(output, want) {
+					t.Errorf("Execute(%q) output = %q, want it to contain %q", tt.input, output, want)
+				}
+			}
+			if handler.Failed() != tt.failed {
+				t.Errorf("Execute(%q) failed = %v, want %v", tt.input, handler.Failed(), tt.failed)
+			}
+		})
+	}
+
+	if data, err := os.ReadFile(file); err != nil || !strings.Contains(string(data), `"x-presence": 25`) {
+		t.Errorf("saved schema = %s, %v", data, err)
+	}
+}
@@ -0,0 +1,193 @@
+package command
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// maxSourceDepth bounds nested source commands, so a script sourcing itself
+// fails instead of recursing forever
+const maxSourceDepth = 16
+
+var (
+	varName      = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	varReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+)
+
+// ScriptResult summarizes a script run
+type ScriptResult struct {
+	Commands int  // commands run
+	Failed   int  // commands that failed
+	Exited   bool // the script ended with exit or quit
+}
+
+// RunScript runs the commands read from r through Execute, one per line.
+// Blank lines and lines starting with # are skipped. Each failed command is
+// reported on stderr as name:line; with on-error stop, the default, the
+// script ends there, with on-error continue the next command runs. exit and
+// quit end the script.
+func (h *Handler) RunScript(r io.Reader, name string) (ScriptResult, error) {
+	var result ScriptResult
+	scanner := bufio.NewScanner(r)
+	// Lines may hold large values
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		input := strings.TrimSpace(scanner.Text())
+		if input == "" || strings.HasPrefix(input, "#") {
+			continue
+		}
+
+		result.Commands++
+		keep := h.Execute(input)
+		if h.Failed() {
+			result.Failed++
+			fmt.Fprintf(os.Stderr, "%s:%d: command failed: %s\n", name, line, input)
+			if !h.continueOnError() {
+				return result, nil
+			}
+		}
+		if !keep {
+			result.Exited = true
+			return result, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return result, nil
+}
+
+// SplitCommands splits a line of commands separated by semicolons, as given
+// with -e. Semicolons inside double quotes, such as in JSON values, do not
+// separate commands.
+func SplitCommands(line string) []string {
+	var commands []string
+	add := func(command string) {
+		if command = strings.TrimSpace(command); command != "" {
+			commands = append(commands, command)
+		}
+	}
+
+	start := 0
+	inQuote := false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case inQuote && line[i] == '\\':
+			i++
+		case line[i] == '"':
+			inQuote = !inQuote
+		case !inQuote && line[i] == ';':
+			add(line[start:i])
+			start = i + 1
+		}
+	}
+	add(line[start:])
+	return commands
+}
+
+// executeSource runs the commands of a script file. It returns false when
+// the script ran exit or quit.
+func (h *Handler) executeSource(args []string) bool {
+	if len(args) != 1 {
+		h.failf("Usage: source <file>\n")
+		return true
+	}
+	if h.sourceDepth >= maxSourceDepth {
+		h.failf("source: too many nested scripts (limit %d)\n", maxSourceDepth)
+		return true
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		h.failf("source: %v\n", err)
+		return true
+	}
+	defer f.Close()
+
+	h.sourceDepth++
+	result, err := h.RunScript(f, args[0])
+	h.sourceDepth--
+
+	h.failed = result.Failed > 0
+	if err != nil {
+		h.failf("source: %v\n", err)
+	}
+	return !result.Exited
+}
+
+// executeOnError shows or sets whether scripts go on after a failed command
+func (h *Handler) executeOnError(args []string) {
+	s, ok := h.State.(*ReplState)
+	if !ok || s == nil {
+		h.failf("on-error is not available\n")
+		return
+	}
+
+	switch {
+	case len(args) == 0:
+	case len(args) == 1 && (args[0] == "stop" || args[0] == "continue"):
+		s.ContinueOnError = args[0] == "continue"
+	default:
+		h.failf("Usage: on-error [stop|continue]\n")
+		return
+	}
+	if s.ContinueOnError {
+		fmt.Println("on-error: continue")
+	} else {
+		fmt.Println("on-error: stop")
+	}
+}
+
+func (h *Handler) continueOnError() bool {
+	s, ok := h.State.(*ReplState)
+	return ok && s != nil && s.ContinueOnError
+}
+
+// setVar defines a variable from name=value
+func (h *Handler) setVar(s *ReplState, assignment string) {
+	name, value, _ := strings.Cut(assignment, "=")
+	if !varName.MatchString(name) {
+		h.failf("Invalid variable name '%s': use letters, digits and _\n", name)
+		return
+	}
+	if s.Vars == nil {
+		s.Vars = make(map[string]string)
+	}
+	s.Vars[name] = value
+}
+
+// expandVars replaces each ${name} in input with the variable name or, if
+// no such variable is set, the environment variable name
+func (h *Handler) expandVars(input string) (string, error) {
+	if !strings.Contains(input, "${") {
+		return input, nil
+	}
+	var vars map[string]string
+	if s, ok := h.State.(*ReplState); ok && s != nil {
+		vars = s.Vars
+	}
+
+	var undefined string
+	expanded := varReference.ReplaceAllStringFunc(input, func(ref string) string {
+		name := ref[2 : len(ref)-1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		if value, ok := os.LookupEnv(name); ok {
+			return value
+		}
+		if undefined == "" {
+			undefined = name
+		}
+		return ref
+	})
+	if undefined != "" {
+		return "", fmt.Errorf("undefined variable: %s", undefined)
+	}
+	return expanded, nil
+}
@@ -0,0 +1,169 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunScript(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   ScriptResult
+		keys   []string // keys of default expected afterwards
+	}{
+		{
+			name:   "all succeed",
+			script: "# setup\nput a 1\n\nput b 2\n",
+			want:   ScriptResult{Commands: 2},
+			keys:   []string{"a", "b"},
+		},
+		{
+			name:   "stop at first failure",
+			script: "put a 1\nget missing\nput b 2\n",
+			want:   ScriptResult{Commands: 2, Failed: 1},
+			keys:   []string{"a"},
+		},
+		{
+			name:   "continue after failures",
+			script: "on-error continue\nget missing\nbogus\nput b 2\n",
+			want:   ScriptResult{Commands: 4, Failed: 2},
+			keys:   []string{"b"},
+		},
+		{
+			name:   "exit ends the script",
+			script: "put a 1\nexit\nput b 2\n",
+			want:   ScriptResult{Commands: 2, Exited: true},
+			keys:   []string{"a"},
+		},
+		{
+			name:   "usage errors fail",
+			script: "put a\n",
+			want:   ScriptResult{Commands: 1, Failed: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, mdb := newTestHandler("default")
+			var got ScriptResult
+			var err error
+			captureOutput(func() { got, err = h.RunScript(strings.NewReader(tt.script), "test.rdb") })
+			if err != nil {
+				t.Fatalf("RunScript: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RunScript = %+v, want %+v", got, tt.want)
+			}
+			for _, key := range tt.keys {
+				if _, ok := mdb.data["default"][key]; !ok {
+					t.Errorf("key %q was not written", key)
+				}
+			}
+			if n := len(mdb.data["default"]); n != len(tt.keys) {
+				t.Errorf("%d keys written, want %d", n, len(tt.keys))
+			}
+		})
+	}
+}
+
+func TestVariables(t *testing.T) {
+	h, mdb := newTestHandler("default")
+	t.Setenv("ROCKSDB_CLI_TEST_VALUE", "from-env")
+
+	script := strings.Join([]string{
+		"set id=user:1",
+		"set name=Alice",
+		"set greeting=hello  world",
+		"put ${id} ${name}",
+		"put env ${ROCKSDB_CLI_TEST_VALUE}",
+		"query save byid get ${id}",
+	}, "\n")
+	captureOutput(func() { h.RunScript(strings.NewReader(script), "vars.rdb") })
+
+	if got := mdb.data["default"]["user:1"]; got != "Alice" {
+		t.Errorf("user:1 = %q, want %q", got, "Alice")
+	}
+	if got := mdb.data["default"]["env"]; got != "from-env" {
+		t.Errorf("env = %q, want %q", got, "from-env")
+	}
+	state := h.State.(*ReplState)
+	if state.Vars["greeting"] != "hello  world" {
+		t.Errorf("Vars = %v", state.Vars)
+	}
+	if state.Queries["byid"] != "get ${id}" {
+		t.Errorf("saved query should keep its variables, got %q", state.Queries["byid"])
+	}
+
+	out := captureOutput(func() { h.Execute("get ${undefined}") })
+	if !h.Failed() || !strings.Contains(out, "undefined variable: undefined") {
+		t.Errorf("expected an undefined variable failure, got:\n%s", out)
+	}
+
+	out = captureOutput(func() { h.Execute("set 1x=a") })
+	if !h.Failed() || !strings.Contains(out, "Invalid variable name '1x'") {
+		t.Errorf("expected an invalid name failure, got:\n%s", out)
+	}
+
+	out = captureOutput(func() { h.Execute("set") })
+	if !strings.Contains(out, "id=user:1") || !strings.Contains(out, "greeting=hello  world") {
+		t.Errorf("set should list the variables:\n%s", out)
+	}
+}
+
+func TestSource(t *testing.T) {
+	dir := t.TempDir()
+	inner := filepath.Join(dir, "inner.rdb")
+	outer := filepath.Join(dir, "outer.rdb")
+	loop := filepath.Join(dir, "loop.rdb")
+	os.WriteFile(inner, []byte("put inner 1\n"), 0o644)
+	os.WriteFile(outer, []byte("source "+inner+"\nput outer 1\n"), 0o644)
+	os.WriteFile(loop, []byte("source "+loop+"\n"), 0o644)
+
+	h, mdb := newTestHandler("default")
+	captureOutput(func() { h.Execute("source " + outer) })
+	if h.Failed() {
+		t.Error("source of a succeeding script failed")
+	}
+	if _, ok := mdb.data["default"]["inner"]; !ok {
+		t.Error("nested source did not run")
+	}
+
+	out := captureOutput(func() { h.Execute("source " + loop) })
+	if !h.Failed() || !strings.Contains(out, "too many nested scripts") {
+		t.Errorf("recursive source should fail, got:\n%s", out)
+	}
+
+	out = captureOutput(func() { h.Execute("source " + filepath.Join(dir, "missing.rdb")) })
+	if !h.Failed() || !strings.Contains(out, "source:") {
+		t.Errorf("missing file should fail, got:\n%s", out)
+	}
+
+	os.WriteFile(loop, []byte("exit\n"), 0o644)
+	var keep bool
+	captureOutput(func() { keep = h.Execute("source " + loop) })
+	if keep || h.Failed() {
+		t.Errorf("exit in a sourced script should end the session without failing: keep=%v failed=%v", keep, h.Failed())
+	}
+}
+
+func TestSplitCommands(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"usecf users; get user:1", []string{"usecf users", "get user:1"}},
+		{"get a;;get b; ", []string{"get a", "get b"}},
+		{`put k {"a":"x;y"}; get k`, []string{`put k {"a":"x;y"}`, "get k"}},
+		{`put k {"a":"\";"}; get k`, []string{`put k {"a":"\";"}`, "get k"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := SplitCommands(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitCommands(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package command
+
+import (
+	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/output"
+)
+
+// ArgKind is the kind of value a positional command argument takes
+type ArgKind int
+
+const (
+	ArgText             ArgKind = iota // free text, not completed
+	ArgCF                              // a column family name
+	ArgKey                             // a key of the command's column family
+	ArgJSONField                       // a top-level field of the column family's JSON values
+	ArgJSONPath                        // a JSONPath into the JSON value of the preceding key
+	ArgQuerySubcommand                 // save, run, list or delete
+	ArgQueryName                       // the name of a saved query
+	ArgSetting                         // an output setting
+	ArgSettingValue                    // a value of the preceding output setting
+	ArgOnErrorMode                     // stop or continue
+	ArgSchemaSubcommand                // infer or check
+)
+
+// QuerySubcommands are the subcommands of the query command
+var QuerySubcommands = []string{"save", "run", "list", "delete"}
+
+// SchemaSubcommands are the subcommands of the schema command
+var SchemaSubcommands = []string{"infer", "check"}
+
+// Settings maps the output settings of the set command to their values
+var Settings = map[string][]string{"pretty": {"on", "off"}, "output": output.FormatNames()}
+
+// OnErrorModes are the arguments of the on-error command
+var OnErrorModes = []string{"stop", "continue"}
+
+// Flag describes a command flag
+type Flag struct {
+	Name   string   // without the leading --
+	Value  string   // placeholder for the flag's value, empty for boolean flags
+	Values []string // values suggested after --name=
+}
+
+// Spec describes a REPL command for completion
+type Spec struct {
+	Name        string
+	Description string
+	OptionalCF  bool      // a column family may precede Args, as in get [<cf>] <key>
+	Args        []ArgKind // positional arguments
+	Flags       []Flag
+}
+
+var (
+	prettyFlag = Flag{Name: "pretty"}
+	smartFlag  = Flag{Name: "smart", Value: "true|false", Values: []string{"true", "false"}}
+	regexFlags = []Flag{{Name: "regex"}, {Name: "case-sensitive"}}
+	timeFlags  = []Flag{{Name: "from", Value: "time"}, {Name: "to", Value: "time"},
+		{Name: "encoding", Value: "enc", Values: []string{"ticks", "ns", "us", "ms", "s", "iso"}}, {Name: "prefix", Value: "prefix"}}
+)
+
+// Specs lists the commands handled by Execute
+var Specs = []Spec{
+	{Name: "usecf", Description: "Switch current column family", Args: []ArgKind{ArgCF}},
+	{Name: "get", Description: "Query by key", OptionalCF: true, Args: []ArgKind{ArgKey},
+		Flags: []Flag{prettyFlag, smartFlag, outputFlag}},
+	{Name: "put", Description: "Insert/Update key-value pair", OptionalCF: true, Args: []ArgKind{ArgKey, ArgText},
+		Flags: []Flag{{Name: "ttl", Value: "duration"}, {Name: "hex"}, {Name: "base64"}, outputFlag}},
+	{Name: "inspect", Description: "Decode a binary value", OptionalCF: true, Args: []ArgKind{ArgKey},
+		Flags: []Flag{smartFlag, outputFlag}},
+	{Name: "merge", Description: "Apply a merge operand", OptionalCF: true, Args: []ArgKind{ArgKey, ArgText},
+		Flags: []Flag{outputFlag}},
+	{Name: "prefix", Description: "Query by key prefix", OptionalCF: true, Args: []ArgKind{ArgKey},
+		Flags: append([]Flag{prettyFlag, smartFlag, {Name: "value-pattern", Value: "pattern"}, outputFlag}, regexFlags...)},
+	{Name: "scan", Description: "Scan a key range", OptionalCF: true, Args: []ArgKind{ArgKey, ArgKey},
+		Flags: append([]Flag{
+			{Name: "limit", Value: "N"}, {Name: "reverse"}, {Name: "values", Value: "no", Values: []string{"no"}},
+			{Name: "timestamp"}, prettyFlag, smartFlag,
+			{Name: "key-pattern", Value: "pattern"}, {Name: "value-pattern", Value: "pattern"}, {Name: "page"}, outputFlag,
+		}, regexFlags...)},
+	{Name: "last", Description: "Get last key-value pair", OptionalCF: true, Flags: []Flag{prettyFlag, outputFlag}},
+	{Name: "export", Description: "Export column family to CSV", OptionalCF: true, Args: []ArgKind{ArgText},
+		Flags: []Flag{outputFlag}},
+	{Name: "jpath", Description: "Query JSON value using JSONPath", OptionalCF: true, Args: []ArgKind{ArgKey, ArgJSONPath},
+		Flags: []Flag{prettyFlag, smartFlag, outputFlag}},
+	{Name: "jsonpath", Description: "Query JSON value using JSONPath", OptionalCF: true, Args: []ArgKind{ArgKey, ArgJSONPath},
+		Flags: []Flag{prettyFlag, smartFlag, outputFlag}},
+	{Name: "jsonquery", Description: "Query entries by JSON field value", OptionalCF: true, Args: []ArgKind{ArgJSONField, ArgText},
+		Flags: []Flag{prettyFlag, outputFlag}},
+	{Name: "stats", Description: "Show statistics", OptionalCF: true,
+		Flags: []Flag{{Name: "detailed"}, prettyFlag, {Name: "ttl"}, outputFlag}},
+	{Name: "keyformat", Description: "Show detected key format", OptionalCF: true, Flags: []Flag{outputFlag}},
+	{Name: "tree", Description: "Show key prefixes as a tree", OptionalCF: true,
+		Flags: []Flag{{Name: "prefix", Value: "prefix"}, {Name: "hex"}, {Name: "delimiters", Value: "chars"},
+			{Name: "width", Value: "N"}, {Name: "depth", Value: "N"}, {Name: "max-children", Value: "N"}, outputFlag}},
+	{Name: "range", Description: "Show entries between two times", OptionalCF: true,
+		Flags: append([]Flag{{Name: "limit", Value: "N"}, {Name: "reverse"}, {Name: "after", Value: "cursor"},
+			{Name: "values", Value: "no", Values: []string{"no"}}, prettyFlag, outputFlag}, timeFlags...)},
+	{Name: "latest", Description: "Show the newest entries by key time", OptionalCF: true, Args: []ArgKind{ArgText},
+		Flags: append([]Flag{{Name: "values", Value: "no", Values: []string{"no"}}, prettyFlag, outputFlag}, timeFlags[2:]...)},
+	{Name: "tail", Description: "Show the newest entries and follow new ones", OptionalCF: true, Args: []ArgKind{ArgText},
+		Flags: append([]Flag{{Name: "follow"}, {Name: "interval", Value: "duration"},
+			{Name: "values", Value: "no", Values: []string{"no"}}, prettyFlag, outputFlag}, timeFlags[2:]...)},
+	{Name: "histogram", Description: "Count entries per time bucket", OptionalCF: true,
+		Flags: append([]Flag{{Name: "bucket", Value: "duration"}, outputFlag}, timeFlags...)},
+	{Name: "schema", Description: "Infer a JSON Schema or check values against one", Args: []ArgKind{ArgSchemaSubcommand, ArgCF},
+		Flags: []Flag{{Name: "prefix", Value: "prefix"}, {Name: "sample", Value: "N"}, {Name: "save", Value: "file"},
+			{Name: "schema", Value: "file"}, {Name: "limit", Value: "N"}, outputFlag}},
+	{Name: "listcf", Description: "List all column families", Flags: []Flag{outputFlag}},
+	{Name: "createcf", Description: "Create new column family", Args: []ArgKind{ArgText},
+		Flags: []Flag{{Name: "profile", Value: "name", Values: cfProfileNames()}, outputFlag}},
+	{Name: "cfoptions", Description: "Show column family options", Args: []ArgKind{ArgCF},
+		Flags: []Flag{{Name: "raw"}, {Name: "profiles"}, {Name: "plugins"}, prettyFlag, outputFlag}},
+	{Name: "dropcf", Description: "Drop column family", Args: []ArgKind{ArgCF}, Flags: []Flag{outputFlag}},
+	{Name: "search", Description: "Fuzzy search for keys and/or values", OptionalCF: true,
+		Flags: append([]Flag{
+			{Name: "key", Value: "pattern"}, {Name: "value", Value: "pattern"},
+			{Name: "limit", Value: "N"}, {Name: "after", Value: "key"}, {Name: "keys-only"}, {Name: "tick"}, prettyFlag,
+			{Name: "export", Value: "file"}, {Name: "export-sep", Value: "sep"}, {Name: "page"}, outputFlag,
+		}, regexFlags...)},
+	{Name: "history", Description: "Show command history", Args: []ArgKind{ArgText}},
+	{Name: "query", Description: "Save and run named queries", Args: []ArgKind{ArgQuerySubcommand, ArgQueryName}},
+	{Name: "set", Description: "Show or change output settings, define variables", Args: []ArgKind{ArgSetting, ArgSettingValue}},
+	{Name: "source", Description: "Run the commands of a script file", Args: []ArgKind{ArgText}},
+	{Name: "on-error", Description: "Stop or continue scripts after a failed command", Args: []ArgKind{ArgOnErrorMode}},
+	{Name: "tools", Description: "List, describe and call MCP tools"},
+	{Name: "help", Description: "Show help"},
+	{Name: "exit", Description: "Exit the CLI"},
+	{Name: "quit", Description: "Exit the CLI"},
+}
+
+// hasFlag reports whether the command takes the flag
+func (s Spec) hasFlag(name string) bool {
+	for _, flag := range s.Flags {
+		if flag.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// LookupSpec returns the spec of a command
+func LookupSpec(name string) (Spec, bool) {
+	for _, spec := range Specs {
+		if spec.Name == name {
+			return spec, true
+		}
+	}
+	return Spec{}, false
+}
+
+func cfProfileNames() []string {
+	var names []string
+	for _, profile := range db.CFProfiles() {
+		names = append(names, profile.Name)
+	}
+	return names
+}
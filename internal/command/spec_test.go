@@ -0,0 +1,28 @@
+package command
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSpecsMatchExecute checks that every command in Specs is handled by
+// Execute, so completion does not offer unknown commands
+func TestSpecsMatchExecute(t *testing.T) {
+	for _, spec := range Specs {
+		if spec.Name == "exit" || spec.Name == "quit" {
+			continue
+		}
+		h, _ := newTestHandler("default")
+		out := captureOutput(func() { h.Execute(spec.Name) })
+		if strings.Contains(out, "Unknown command") {
+			t.Errorf("command %q in Specs is not handled by Execute", spec.Name)
+		}
+	}
+
+	if _, ok := LookupSpec("scan"); !ok {
+		t.Error("LookupSpec(scan) not found")
+	}
+	if _, ok := LookupSpec("nope"); ok {
+		t.Error("LookupSpec(nope) found")
+	}
+}
@@ -0,0 +1,313 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/output"
+	"rocksdb-cli/internal/timeseries"
+)
+
+const (
+	// rangeLimit is the number of entries range shows without --limit
+	rangeLimit = 100
+	// latestCount is the number of entries latest and tail show without N
+	latestCount = 10
+	// histogramWidth is the width of the longest histogram bar
+	histogramWidth = 40
+)
+
+// timeFlagsUsage describes the flags every time-series command takes
+const timeFlagsUsage = "  --encoding=ticks|ns|us|ms|s|iso sets how keys start with a time (detected from the keys by default)\n" +
+	"  --prefix=<prefix> reads the time after a key prefix; times are RFC 3339, 2006-01-02T15:04 (UTC), dates, now or ages like 1h or 7d"
+
+// executeRange handles range [<cf>] [--from=<time>] [--to=<time>] [--limit=N]
+// [--reverse] [--after=<cursor>] [--values=no] [--pretty]
+func (h *Handler) executeRange(args []string) {
+	flags, args := parseFlags(args)
+	cf, _, ok := h.timeArgs(args, false)
+	if !ok {
+		h.failf("Usage: range [<cf>] [--from=<time>] [--to=<time>] [--limit=N] [--reverse] [--after=<cursor>] [--values=no] [--pretty] [--encoding=<enc>] [--prefix=<prefix>]\n")
+		fmt.Println("  Show the entries of a time-keyed column family between two times, --to excluded")
+		fmt.Println(timeFlagsUsage)
+		fmt.Println("  Examples:")
+		fmt.Println("    range events --from=2024-03-01T10:00 --to=2024-03-01T11:00")
+		fmt.Println("    range events --from=1h                # The last hour")
+		return
+	}
+	q, ok := h.timeQuery(cf, flags)
+	if !ok {
+		return
+	}
+
+	opts := timeseries.Options{Limit: rangeLimit, Reverse: flags["reverse"] == "true", Values: flags["values"] != "no", After: flags["after"]}
+	if v, ok := flags["limit"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			h.failf("Invalid limit value\n")
+			return
+		}
+		opts.Limit = n
+	}
+	page, err := timeseries.Range(h.DB, q, opts)
+	if err != nil {
+		h.reportError(err, "Range", cf)
+		return
+	}
+	if h.structured() {
+		h.writeResult(output.Series(page, opts.Values))
+		return
+	}
+
+	fmt.Printf("%d entries of '%s' %s (keys: %s)\n", len(page.Entries), cf, describeRange(q), q.Codec)
+	h.printTimeEntries(page.Entries, opts.Values, flags["pretty"] == "true")
+	if page.HasMore {
+		fmt.Printf("\nMore entries: add --after=%s\n", page.NextCursor)
+	}
+}
+
+// executeLatest handles latest [<cf>] [N] [--values=no] [--pretty]
+func (h *Handler) executeLatest(args []string) {
+	flags, args := parseFlags(args)
+	cf, n, ok := h.timeArgs(args, true)
+	if !ok {
+		h.failf("Usage: latest [<cf>] [N] [--values=no] [--pretty] [--encoding=<enc>] [--prefix=<prefix>]\n")
+		fmt.Println("  Show the N newest entries of a time-keyed column family, newest first (10 by default)")
+		fmt.Println(timeFlagsUsage)
+		return
+	}
+	q, ok := h.timeQuery(cf, flags)
+	if !ok {
+		return
+	}
+
+	values := flags["values"] != "no"
+	page, err := timeseries.Latest(h.DB, q, n, values)
+	if err != nil {
+		h.reportError(err, "Latest", cf)
+		return
+	}
+	if h.structured() {
+		h.writeResult(output.Series(page, values))
+		return
+	}
+	h.printTimeEntries(page.Entries, values, flags["pretty"] == "true")
+}
+
+// executeTail handles tail [<cf>] [N] [-f|--follow] [--interval=<duration>]:
+// the N newest entries oldest first, then the entries written after them
+// until interrupted
+func (h *Handler) executeTail(args []string) {
+	follow := false
+	rest := args[:0:0]
+	for _, arg := range args {
+		if arg == "-f" {
+			follow = true
+		} else {
+			rest = append(rest, arg)
+		}
+	}
+	flags, args := parseFlags(rest)
+	follow = follow || flags["follow"] == "true"
+	cf, n, ok := h.timeArgs(args, true)
+	if !ok {
+		h.failf("Usage: tail [<cf>] [N] [-f|--follow] [--interval=<duration>] [--values=no] [--pretty] [--encoding=<enc>] [--prefix=<prefix>]\n")
+		fmt.Println("  Show the N newest entries of a time-keyed column family, oldest first (10 by default)")
+		fmt.Println("  -f keeps showing new entries, checking every --interval (1s by default), until Ctrl+C")
+		fmt.Println(timeFlagsUsage)
+		return
+	}
+	interval := time.Second
+	if v, ok := flags["interval"]; ok {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			h.failf("Invalid --interval: %s\n", v)
+			return
+		}
+		interval = d
+	}
+	q, ok := h.timeQuery(cf, flags)
+	if !ok {
+		return
+	}
+
+	values, pretty := flags["values"] != "no", flags["pretty"] == "true"
+	page, err := timeseries.Latest(h.DB, q, n, values)
+	if err != nil {
+		h.reportError(err, "Tail", cf)
+		return
+	}
+	for i, j := 0, len(page.Entries)-1; i < j; i, j = i+1, j-1 {
+		page.Entries[i], page.Entries[j] = page.Entries[j], page.Entries[i]
+	}
+	if h.structured() {
+		h.writeResult(output.Series(page, values))
+	} else {
+		h.printTimeEntries(page.Entries, values, pretty)
+	}
+	if !follow {
+		return
+	}
+
+	// Keep stdout to the entries in an output format
+	status := os.Stdout
+	if h.structured() {
+		status = os.Stderr
+	}
+	fmt.Fprintf(status, "Following '%s' every %v, press Ctrl+C to stop\n", cf, interval)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	// Follow starts after the newest entry
+	err = timeseries.Follow(ctx, h.DB, q, "", interval, values, func(kv db.KeyValue) error {
+		if h.structured() {
+			h.writeResult(output.Series(&timeseries.Page{Codec: q.Codec, Entries: []db.KeyValue{kv}}, values))
+		} else {
+			h.printTimeEntries([]db.KeyValue{kv}, values, pretty)
+		}
+		return nil
+	})
+	if err != nil {
+		h.reportError(err, "Tail", cf)
+	}
+}
+
+// executeHistogram handles histogram [<cf>] [--bucket=<duration>]
+// [--from=<time>] [--to=<time>]
+func (h *Handler) executeHistogram(args []string) {
+	flags, args := parseFlags(args)
+	cf, _, ok := h.timeArgs(args, false)
+	if !ok {
+		h.failf("Usage: histogram [<cf>] [--bucket=<duration>] [--from=<time>] [--to=<time>] [--encoding=<enc>] [--prefix=<prefix>]\n")
+		fmt.Println("  Count the entries of a time-keyed column family per time bucket (1h by default)")
+		fmt.Println(timeFlagsUsage)
+		fmt.Println("  Example: histogram events --bucket=15m --from=24h")
+		return
+	}
+	bucket := time.Hour
+	if v, ok := flags["bucket"]; ok {
+		d, err := timeseries.ParseBucket(v)
+		if err != nil {
+			h.failf("%v\n", err)
+			return
+		}
+		bucket = d
+	}
+	q, ok := h.timeQuery(cf, flags)
+	if !ok {
+		return
+	}
+
+	hist, err := timeseries.NewHistogram(h.DB, q, bucket, nil)
+	if err != nil {
+		h.reportError(err, "Histogram", cf)
+		return
+	}
+	if h.structured() {
+		h.writeResult(output.Histogram(hist))
+		return
+	}
+
+	fmt.Printf("%d entries of '%s' %s in %s buckets (keys: %s)\n", hist.Total, cf, describeRange(q), bucket, q.Codec)
+	var most int64
+	for _, b := range hist.Buckets {
+		most = max(most, b.Count)
+	}
+	for _, b := range hist.Buckets {
+		bar := ""
+		if most > 0 {
+			bar = strings.Repeat("█", int((b.Count*histogramWidth+most-1)/most))
+		}
+		fmt.Printf("%s  %-*s %d\n", b.Start.Format(time.RFC3339), histogramWidth, bar, b.Count)
+	}
+}
+
+// timeArgs reads [<cf>] and, with count, [<cf>] [N] where a lone number is
+// the count
+func (h *Handler) timeArgs(args []string, count bool) (cf string, n int, ok bool) {
+	if s, ok := h.State.(*ReplState); ok && s != nil {
+		cf = s.CurrentCF
+	}
+	n = latestCount
+	if count && len(args) > 0 {
+		if v, err := strconv.Atoi(args[len(args)-1]); err == nil {
+			if v <= 0 {
+				return "", 0, false
+			}
+			n, args = v, args[:len(args)-1]
+		}
+	}
+	if len(args) == 1 {
+		cf = args[0]
+	}
+	return cf, n, cf != "" && len(args) <= 1
+}
+
+// timeQuery reads the time flags of a command and detects how the keys of
+// cf encode their time
+func (h *Handler) timeQuery(cf string, flags map[string]string) (timeseries.Query, bool) {
+	q := timeseries.Query{CF: cf}
+	enc, err := timeseries.ParseEncoding(flags["encoding"])
+	if err != nil {
+		h.failf("%v\n", err)
+		return q, false
+	}
+	now := time.Now()
+	for _, b := range []struct {
+		name   string
+		target *time.Time
+	}{{"from", &q.From}, {"to", &q.To}} {
+		v, ok := flags[b.name]
+		if !ok {
+			continue
+		}
+		t, err := timeseries.ParseTime(v, now)
+		if err != nil {
+			h.failf("Invalid --%s: %v\n", b.name, err)
+			return q, false
+		}
+		*b.target = t
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		h.failf("--from must be before --to\n")
+		return q, false
+	}
+
+	q.Codec, err = timeseries.Detect(h.DB, cf, flags["prefix"], enc)
+	if err != nil {
+		h.reportError(err, "Detect time encoding", cf)
+		return q, false
+	}
+	return q, true
+}
+
+// printTimeEntries prints entries as "<time>  <key>: <value>"
+func (h *Handler) printTimeEntries(entries []db.KeyValue, values, pretty bool) {
+	for _, kv := range entries {
+		if values {
+			fmt.Printf("%s  %s: %s\n", kv.Timestamp, kv.Key, formatValue(kv.Value, pretty))
+		} else {
+			fmt.Printf("%s  %s\n", kv.Timestamp, kv.Key)
+		}
+	}
+}
+
+// describeRange describes the bounds of q, as in "from ... to ..."
+func describeRange(q timeseries.Query) string {
+	var parts []string
+	if !q.From.IsZero() {
+		parts = append(parts, "from "+q.From.UTC().Format(time.RFC3339))
+	}
+	if !q.To.IsZero() {
+		parts = append(parts, "to "+q.To.UTC().Format(time.RFC3339))
+	}
+	if len(parts) == 0 {
+		return "at any time"
+	}
+	return strings.Join(parts, " ")
+}
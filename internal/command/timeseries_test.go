@@ -0,0 +1,95 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeSeriesCommands(t *testing.T) {
+	handler, mockDB := newTestHandler("default")
+	mockDB.CreateCF("events")
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < 6; i++ {
+		at := start.Add(time.Duration(i) * 30 * time.Minute)
+		mockDB.PutCF("events", strconv.FormatInt(at.UnixMilli(), 10), "v"+strconv.Itoa(i))
+	}
+	mockDB.CreateCF("prefixed")
+	mockDB.PutCF("prefixed", "ev:1709283600000", "first")
+	mockDB.PutCF("default", "user:1", "alice")
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:  "range",
+			input: "range events --from=2024-03-01T10:00 --to=2024-03-01T11:00",
+			expected: []string{
+				"2 entries of 'events' from 2024-03-01T10:00:00Z to 2024-03-01T11:00:00Z (keys: ms (decimal))\n" +
+					"2024-03-01T10:00:00Z  1709287200000: v2\n" +
+					"2024-03-01T10:30:00Z  1709289000000: v3\n",
+			},
+		},
+		{
+			name:     "range limit",
+			input:    "range events --limit=1 --values=no",
+			expected: []string{"2024-03-01T09:00:00Z  1709283600000\n", "More entries: add --after="},
+		},
+		{
+			name:     "range csv",
+			input:    "range events --from=2024-03-01T11:00 --output=csv",
+			expected: []string{"time,key,value\n2024-03-01T11:00:00Z,1709290800000,v4\n2024-03-01T11:30:00Z,1709292600000,v5\n"},
+		},
+		{
+			name:     "prefix",
+			input:    "latest prefixed 1 --prefix=ev:",
+			expected: []string{"2024-03-01T09:00:00Z  ev:1709283600000: first\n"},
+		},
+		{
+			name:     "latest",
+			input:    "latest events 2",
+			expected: []string{"2024-03-01T11:30:00Z  1709292600000: v5\n2024-03-01T11:00:00Z  1709290800000: v4\n"},
+		},
+		{
+			name:     "tail",
+			input:    "tail events 2",
+			expected: []string{"2024-03-01T11:00:00Z  1709290800000: v4\n2024-03-01T11:30:00Z  1709292600000: v5\n"},
+		},
+		{
+			name:  "histogram",
+			input: "histogram events --bucket=1h --to=2024-03-01T13:00",
+			expected: []string{
+				"6 entries of 'events' to 2024-03-01T13:00:00Z in 1h0m0s buckets",
+				"2024-03-01T09:00:00Z  " + strings.Repeat("█", 40) + " 2\n",
+				"2024-03-01T12:00:00Z  " + strings.Repeat(" ", 40) + " 0\n",
+			},
+		},
+		{
+			name:     "histogram jsonl",
+			input:    "histogram events --bucket=2h --output=jsonl",
+			expected: []string{`{"start":"2024-03-01T08:00:00Z","count":2}` + "\n" + `{"start":"2024-03-01T10:00:00Z","count":4}` + "\n"},
+		},
+		{name: "bad time", input: "range events --from=soon", expected: []string{"Invalid --from: invalid time \"soon\""}},
+		{name: "bad encoding", input: "range events --encoding=days", expected: []string{"unknown time encoding \"days\""}},
+		{name: "bad bucket", input: "histogram events --bucket=0s", expected: []string{"invalid bucket \"0s\""}},
+		{name: "reversed bounds", input: "range events --from=1h --to=2h", expected: []string{"--from must be before --to"}},
+		{name: "keys without time", input: "range default", expected: []string{"cannot read a time from key"}},
+		{name: "unknown cf", input: "latest nosuch", expected: []string{"Column family 'nosuch' does not exist"}},
+		{name: "usage", input: "tail a b 3", expected: []string{"Usage: tail [<cf>] [N] [-f|--follow]"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := captureOutput(func() {
+				handler.Execute(tt.input)
+			})
+			for _, want := range tt.expected {
+				if !strings.Contains(output, want) {
+					t.Errorf("Execute(%q) output = %q, want it to contain %q", tt.input, output, want)
+				}
+			}
+		})
+	}
+}
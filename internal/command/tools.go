@@ -0,0 +1,256 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"rocksdb-cli/internal/mcp/protocol"
+	"rocksdb-cli/internal/mcp/tools"
+)
+
+// ToolsSubcommands are the subcommands of the tools command
+var ToolsSubcommands = []string{"list", "describe", "call"}
+
+// executeTools runs the tools command against the handler's tool registry
+func (h *Handler) executeTools(input string) {
+	parts, err := splitArgs(input)
+	if err != nil {
+		h.failf("%v\n", err)
+		return
+	}
+	if len(parts) < 2 {
+		h.printToolsUsage()
+		return
+	}
+	if h.Tools == nil {
+		h.failf("Tools are not available in this session\n")
+		return
+	}
+
+	switch parts[1] {
+	case "list":
+		if len(parts) > 3 {
+			h.failf("Usage: tools list [namespace]\n")
+			return
+		}
+		namespace := ""
+		if len(parts) == 3 {
+			namespace = parts[2]
+		}
+		h.listTools(namespace)
+	case "describe":
+		if len(parts) != 3 {
+			h.failf("Usage: tools describe <name>\n")
+			return
+		}
+		h.describeTool(parts[2])
+	case "call":
+		if len(parts) < 3 {
+			h.failf("Usage: tools call <name> [arg=value ...]\n")
+			return
+		}
+		h.callTool(parts[2], parts[3:])
+	default:
+		h.printToolsUsage()
+	}
+}
+
+func (h *Handler) printToolsUsage() {
+	h.failf("Usage: tools list [namespace] | tools describe <name> | tools call <name> [arg=value ...]\n")
+	fmt.Println("  Local tools are in the 'local' namespace, remote tools in the namespace of their MCP client")
+	fmt.Println("  Quote values containing spaces: tools call local.db-put key=greeting value=\"hello world\"")
+}
+
+// listTools prints the tools of a namespace, or all tools
+func (h *Handler) listTools(namespace string) {
+	list := h.Tools.ListTools(namespace)
+	if len(list) == 0 {
+		if namespace != "" {
+			fmt.Printf("No tools in namespace '%s'. Namespaces: %s\n", namespace, strings.Join(ToolNamespaces(h.Tools), ", "))
+		} else {
+			fmt.Println("No tools available")
+		}
+		return
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	width := 0
+	for _, tool := range list {
+		if len(tool.Name) > width {
+			width = len(tool.Name)
+		}
+	}
+	for _, tool := range list {
+		fmt.Printf("  %-*s  %s\n", width, tool.Name, tool.Description)
+	}
+	fmt.Printf("%d tool(s)\n", len(list))
+}
+
+// describeTool prints a tool's description and arguments
+func (h *Handler) describeTool(name string) {
+	tool := h.lookupTool(name)
+	if tool == nil {
+		h.failf("Unknown tool '%s'. Use 'tools list' to see available tools.\n", name)
+		return
+	}
+
+	fmt.Printf("Tool: %s\n", tool.Name)
+	if tool.Description != "" {
+		fmt.Printf("Description: %s\n", tool.Description)
+	}
+
+	args := tools.Arguments(*tool)
+	if len(args) == 0 {
+		fmt.Println("Arguments: none")
+		return
+	}
+	fmt.Println("Arguments:")
+	for _, arg := range args {
+		var attrs []string
+		if arg.Type != "" {
+			attrs = append(attrs, arg.Type)
+		}
+		if arg.Required {
+			attrs = append(attrs, "required")
+		}
+		line := "  " + arg.Name
+		if len(attrs) > 0 {
+			line += " (" + strings.Join(attrs, ", ") + ")"
+		}
+		if arg.Description != "" {
+			line += " - " + arg.Description
+		}
+		fmt.Println(line)
+		if len(arg.Enum) > 0 {
+			values := make([]string, len(arg.Enum))
+			for i, v := range arg.Enum {
+				data, _ := json.Marshal(v)
+				values[i] = string(data)
+			}
+			fmt.Printf("      one of: %s\n", strings.Join(values, ", "))
+		}
+	}
+}
+
+// callTool parses and validates name=value arguments against the tool's
+// input schema, then executes a local tool or forwards a remote one
+func (h *Handler) callTool(name string, pairs []string) {
+	tool := h.lookupTool(name)
+	if tool == nil {
+		h.failf("Unknown tool '%s'. Use 'tools list' to see available tools.\n", name)
+		return
+	}
+
+	arguments, err := tools.ParseArguments(*tool, pairs)
+	if err == nil {
+		err = tools.ValidateArguments(*tool, arguments)
+	}
+	if err != nil {
+		h.failf("Invalid arguments for %s: %v\n", tool.Name, err)
+		fmt.Printf("Use 'tools describe %s' to see its arguments.\n", tool.Name)
+		return
+	}
+
+	ctx := context.Background()
+	var result *protocol.ToolCallResult
+	if strings.HasPrefix(tool.Name, "local.") {
+		result, err = h.Tools.Execute(ctx, tool.Name, arguments)
+	} else if h.RemoteTools == nil {
+		err = fmt.Errorf("no MCP clients are running")
+	} else {
+		result, err = h.RemoteTools.Execute(ctx, tool.Name, arguments)
+	}
+	if err != nil {
+		h.failf("Error calling %s: %v\n", tool.Name, err)
+		return
+	}
+
+	printToolResult(result)
+	h.failed = result.IsError
+}
+
+// lookupTool finds a tool by namespaced name; names without a namespace
+// are looked up among the local tools
+func (h *Handler) lookupTool(name string) *protocol.Tool {
+	if tool := h.Tools.GetTool(name); tool != nil {
+		return tool
+	}
+	return h.Tools.GetTool("local." + name)
+}
+
+// printToolResult prints the content of a tool result
+func printToolResult(result *protocol.ToolCallResult) {
+	if result.IsError {
+		fmt.Print("Error: ")
+	}
+	for _, content := range result.Content {
+		switch content.Type {
+		case "text":
+			text := content.Text
+			if json.Valid([]byte(text)) && strings.ContainsAny(text, "{[") {
+				text = prettyPrintJSON(text)
+			}
+			fmt.Println(text)
+		case "image":
+			fmt.Printf("[image %s, %d bytes base64]\n", content.MIMEType, len(content.Data))
+		default:
+			data, _ := json.Marshal(content)
+			fmt.Println(string(data))
+		}
+	}
+}
+
+// ToolNamespaces returns the sorted namespaces of the registry
+func ToolNamespaces(registry *tools.Registry) []string {
+	namespaces := registry.ListNamespaces()
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// splitArgs splits input into whitespace-separated arguments. Single or
+// double quotes group text containing spaces; inside double quotes \" and
+// \\ are unescaped.
+func splitArgs(input string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+
+	runes := []rune(input)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if quote == '"' && r == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+				i++
+				current.WriteRune(runes[i])
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,120 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"rocksdb-cli/internal/mcp/protocol"
+	"rocksdb-cli/internal/mcp/tools"
+)
+
+// newToolsTestHandler creates a handler with the local tools over its mock
+// database and the tools of a remote client named "fs"
+func newToolsTestHandler(t *testing.T) (*Handler, *mockDB) {
+	h, mdb := newTestHandler("default")
+	h.Tools = tools.NewRegistry()
+	if err := tools.NewLocalAdapter(h.Tools, mdb).RegisterAll(); err != nil {
+		t.Fatalf("RegisterAll: %v", err)
+	}
+	h.Tools.RegisterRemote("fs", []protocol.Tool{{
+		Name:        "read_file",
+		Description: "Read a file",
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"path": map[string]interface{}{"type": "string"}},
+		},
+	}})
+	return h, mdb
+}
+
+func TestToolsCommand(t *testing.T) {
+	h, mdb := newToolsTestHandler(t)
+	mdb.PutCF("default", "user:1", "alice")
+
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		notWant []string
+	}{
+		{"usage", "tools", []string{"Usage: tools list"}, nil},
+		{"list all", "tools list", []string{"local.db-get", "fs.read_file", "Read a file", "4 tool(s)"}, []string{"local.db-delete", "local.graphchain-query", "local.transform-keys"}},
+		{"list namespace", "tools list fs", []string{"fs.read_file", "1 tool(s)"}, []string{"local.db-get"}},
+		{"list unknown namespace", "tools list nope", []string{"No tools in namespace 'nope'. Namespaces: fs, local"}, nil},
+		{"describe", "tools describe local.db-get", []string{
+			"Tool: local.db-get",
+			"Description: Get a value from RocksDB by key",
+			"  key (string, required) - The key to retrieve",
+			"  column_family (string) - Column family name (optional)",
+		}, nil},
+		{"describe without namespace", "tools describe db-get", []string{"Tool: local.db-get"}, nil},
+		{"describe unknown", "tools describe nope", []string{"Unknown tool 'nope'"}, nil},
+		{"call", "tools call local.db-get key=user:1", []string{"Value: alice"}, []string{"Error"}},
+		{"call missing argument", "tools call db-get", []string{"Invalid arguments for local.db-get: missing required argument key"}, nil},
+		{"call wrong type", "tools call local.db-list limit=many", []string{`limit: "many" is not a valid integer`}, nil},
+		{"call bad pair", "tools call db-get user:1", []string{`argument "user:1" must be name=value`}, nil},
+		{"call remote without clients", "tools call fs.read_file path=/tmp", []string{"Error calling fs.read_file: no MCP clients are running"}, nil},
+		{"unterminated quote", `tools call db-put key=a value="b`, []string{"unterminated \" quote"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := captureOutput(func() { h.Execute(tt.input) })
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output missing %q:\n%s", want, out)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(out, notWant) {
+					t.Errorf("output unexpectedly contains %q:\n%s", notWant, out)
+				}
+			}
+		})
+	}
+}
+
+func TestToolsCommand_QuotedValues(t *testing.T) {
+	h, mdb := newToolsTestHandler(t)
+
+	out := captureOutput(func() { h.Execute(`tools call local.db-put key=greeting value="hello world"`) })
+	if !strings.Contains(out, "Successfully stored key 'greeting'") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	if got := mdb.data["default"]["greeting"]; got != "hello world" {
+		t.Errorf("stored %q, want %q", got, "hello world")
+	}
+}
+
+func TestToolsCommand_NotAvailable(t *testing.T) {
+	h, _ := newTestHandler("default")
+	out := captureOutput(func() { h.Execute("tools list") })
+	if !strings.Contains(out, "Tools are not available") {
+		t.Errorf("unexpected output: %s", out)
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"tools call x", []string{"tools", "call", "x"}},
+		{`a  "b c"  d`, []string{"a", "b c", "d"}},
+		{`value="hello world"`, []string{"value=hello world"}},
+		{`v='it "is"'`, []string{`v=it "is"`}},
+		{`v="say \"hi\""`, []string{`v=say "hi"`}},
+		{`v=""`, []string{"v="}},
+		{`""`, []string{""}},
+	}
+	for _, tt := range tests {
+		got, err := splitArgs(tt.input)
+		if err != nil {
+			t.Errorf("splitArgs(%q): %v", tt.input, err)
+			continue
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+			t.Errorf("splitArgs(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
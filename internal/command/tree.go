@@ -0,0 +1,126 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"rocksdb-cli/internal/keytree"
+	"rocksdb-cli/internal/output"
+	"rocksdb-cli/internal/util"
+)
+
+// treeDepth is the number of levels tree shows without --depth; deeper
+// levels are browsed with --prefix
+const treeDepth = 2
+
+// executeTree handles tree [<cf>] [--prefix=<p>] [--hex] [--delimiters=<chars>]
+// [--width=N] [--depth=N] [--max-children=N]
+func (h *Handler) executeTree(args []string) {
+	flags, args := parseFlags(args)
+
+	cf := ""
+	if s, ok := h.State.(*ReplState); ok && s != nil {
+		cf = s.CurrentCF
+	}
+	if len(args) == 1 {
+		cf = args[0]
+	}
+	if cf == "" || len(args) > 1 {
+		h.failf("Usage: tree [<cf>] [--prefix=<prefix>] [--hex] [--delimiters=<chars>] [--width=N] [--depth=N] [--max-children=N]\n")
+		fmt.Println("  Show the key prefixes of a column family as a tree, with key counts and sizes")
+		fmt.Println("  Examples:")
+		fmt.Println("    tree users                    # Prefixes split at : / and |, two levels")
+		fmt.Println("    tree users --prefix=user:1:   # Browse the keys under user:1:")
+		fmt.Println("    tree events --width=4         # Split binary keys every 4 bytes")
+		return
+	}
+
+	opts := keytree.Options{Delimiters: flags["delimiters"], MaxDepth: treeDepth}
+	prefix := flags["prefix"]
+	if flags["hex"] == "true" {
+		decoded, err := util.DecodeInput(prefix, "hex")
+		if err != nil {
+			h.failf("Invalid hex prefix: %v\n", err)
+			return
+		}
+		prefix = decoded
+	}
+	opts.Prefix = prefix
+	for _, f := range []struct {
+		name   string
+		target *int
+	}{{"width", &opts.Width}, {"depth", &opts.MaxDepth}, {"max-children", &opts.MaxChildren}} {
+		value, ok := flags[f.name]
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			h.failf("Invalid --%s: %s\n", f.name, value)
+			return
+		}
+		*f.target = n
+	}
+
+	tree, err := h.DB.GetKeyTree(cf, opts)
+	if err != nil {
+		h.reportError(err, "Build key tree", cf)
+		return
+	}
+	if h.structured() {
+		h.writeResult(output.KeyTree(tree))
+		return
+	}
+
+	root := cf
+	if tree.Prefix != "" {
+		root += " " + treeLabel(tree.Prefix)
+	}
+	fmt.Printf("%s  %s\n", root, treeStats(tree, nil))
+	writeTree(tree, "")
+	if len(tree.Children) > 0 {
+		fmt.Printf("\nBrowse a prefix with: tree %s --prefix=<prefix> (--hex for binary prefixes)\n", cf)
+	}
+}
+
+// writeTree prints the children of n under prefix lines drawn with box
+// characters
+func writeTree(n *keytree.Node, indent string) {
+	for i, child := range n.Children {
+		branch, next := "├── ", "│   "
+		if i == len(n.Children)-1 {
+			branch, next = "└── ", "    "
+		}
+		label := treeLabel(child.Label)
+		if child.Other {
+			label = keytree.OtherLabel
+		}
+		fmt.Printf("%s%s%s  %s\n", indent, branch, label, treeStats(child, n))
+		writeTree(child, indent+next)
+	}
+}
+
+// treeLabel shows a prefix, binary ones as hex
+func treeLabel(label string) string {
+	encoded, _ := keytree.Encode(label)
+	return encoded
+}
+
+// treeStats formats the key count and sizes of n, with its share of the
+// keys of parent
+func treeStats(n, parent *keytree.Node) string {
+	keys := "keys"
+	if n.Keys == 1 {
+		keys = "key"
+	}
+	parts := []string{fmt.Sprintf("%d %s", n.Keys, keys)}
+	if parent != nil && parent.Keys > 0 {
+		parts[0] += fmt.Sprintf(" (%.0f%%)", float64(n.Keys)*100/float64(parent.Keys))
+	}
+	parts = append(parts, formatBytes(n.Size()))
+	if n.ApproximateSize > 0 {
+		parts = append(parts, fmt.Sprintf("~%s on disk", formatBytes(int64(n.ApproximateSize))))
+	}
+	return strings.Join(parts, "  ")
+}
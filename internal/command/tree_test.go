@@ -0,0 +1,56 @@
+package command
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTreeCommand(t *testing.T) {
+	handler, mockDB := newTestHandler("default")
+	for _, key := range []string{"user:1:name", "user:1:email", "user:2:name", "order/2024/01", "version", "\x00\x01:x"} {
+		mockDB.PutCF("default", key, "value")
+	}
+
+	output := captureOutput(func() {
+		handler.Execute("tree")
+	})
+	want := `default  6 keys  88 B
+├── 00013a  1 key (17%)  9 B
+├── order/  1 key (17%)  18 B
+│   └── 2024/  1 key (100%)  18 B
+└── user:  3 keys (50%)  49 B
+    ├── 1:  2 keys (67%)  33 B
+    └── 2:  1 key (33%)  16 B
+`
+	if !strings.HasPrefix(output, want) {
+		t.Errorf("tree output =\n%s\nwant\n%s", output, want)
+	}
+	if !strings.Contains(output, "Browse a prefix with: tree default --prefix=<prefix>") {
+		t.Errorf("tree should say how to browse:\n%s", output)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "prefix", input: "tree --prefix=user:1:", expected: "default user:1:  2 keys  33 B\n"},
+		{name: "hex prefix", input: "tree default --prefix=00013a --hex", expected: "default 00013a  1 key"},
+		{name: "depth", input: "tree --depth=1", expected: "└── user:  3 keys (50%)  49 B\n\n"},
+		{name: "width", input: "tree --width=5 --depth=1", expected: "├── user:  3 keys"},
+		{name: "json", input: "tree --output=jsonl", expected: `{"prefix":"user:1:","keys":2,"key_bytes":23,"value_bytes":10,"approximate_size":0}`},
+		{name: "bad depth", input: "tree --depth=x", expected: "Invalid --depth: x"},
+		{name: "unknown cf", input: "tree nosuch", expected: "Column family 'nosuch' does not exist"},
+		{name: "usage", input: "tree a b", expected: "Usage: tree [<cf>]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := captureOutput(func() {
+				handler.Execute(tt.input)
+			})
+			if !strings.Contains(output, tt.expected) {
+				t.Errorf("Execute(%q) output = %q, want it to contain %q", tt.input, output, tt.expected)
+			}
+		})
+	}
+}
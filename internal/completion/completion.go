@@ -0,0 +1,142 @@
+// Package completion suggests column family names, key prefixes and JSON
+// fields and paths. It backs tab completion in the REPL and
+// completion/complete in the MCP server.
+package completion
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+
+	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/util"
+)
+
+const (
+	// MaxSuggestions caps the values returned, as MCP allows at most 100
+	MaxSuggestions = 100
+
+	// scanLimit bounds the keys read to suggest key prefixes
+	scanLimit = 1000
+)
+
+// keySeparators end a key segment; a key suggestion runs up to the first
+// separator after the typed text, so "us" suggests "user:" rather than
+// every user key
+const keySeparators = ":/._-|#"
+
+// Result holds the suggestions for one value
+type Result struct {
+	Values  []string // sorted, at most MaxSuggestions
+	Total   int      // number of suggestions found
+	HasMore bool     // more suggestions exist than Values holds
+}
+
+// ColumnFamilies suggests the column families starting with partial
+func ColumnFamilies(database db.KeyValueDB, partial string) (Result, error) {
+	cfs, err := database.ListCFs()
+	if err != nil {
+		return Result{}, err
+	}
+
+	var values []string
+	for _, cf := range cfs {
+		if strings.HasPrefix(cf, partial) {
+			values = append(values, cf)
+		}
+	}
+	return newResult(values, false), nil
+}
+
+// KeyPrefixes suggests keys of cf starting with partial, cut at the next
+// segment separator. Keys are shown in the column family's key format, so
+// uint64 keys complete as decimal numbers and binary keys as 0x-prefixed hex.
+// At most scanLimit keys are read.
+func KeyPrefixes(database db.KeyValueDB, cf, partial string) (Result, error) {
+	format, _ := database.GetKeyFormatInfo(cf)
+
+	var keys []string
+	if format == util.KeyFormatString {
+		found, err := database.PrefixScanCF(cf, partial, scanLimit)
+		if err != nil {
+			return Result{}, err
+		}
+		for key := range found {
+			keys = append(keys, key)
+		}
+	} else {
+		// Typed text does not map to a binary prefix, so match the first
+		// keys as they are displayed
+		found, err := database.ScanCF(cf, nil, nil, db.ScanOptions{Limit: scanLimit})
+		if err != nil {
+			return Result{}, err
+		}
+		for key := range found {
+			keys = append(keys, DisplayKey(key, format))
+		}
+	}
+	truncated := len(keys) >= scanLimit
+
+	seen := make(map[string]bool)
+	var values []string
+	for _, key := range keys {
+		if !strings.HasPrefix(key, partial) {
+			continue
+		}
+		suggestion := key
+		if i := strings.IndexAny(key[len(partial):], keySeparators); i >= 0 {
+			suggestion = key[:len(partial)+i+1]
+		}
+		if !seen[suggestion] {
+			seen[suggestion] = true
+			values = append(values, suggestion)
+		}
+	}
+	return newResult(values, truncated), nil
+}
+
+// Match suggests the choices starting with partial
+func Match(choices []string, partial string) Result {
+	var values []string
+	for _, choice := range choices {
+		if strings.HasPrefix(choice, partial) {
+			values = append(values, choice)
+		}
+	}
+	return newResult(values, false)
+}
+
+// DisplayKey returns key as it is typed in the given key format
+func DisplayKey(key string, format util.KeyFormat) string {
+	switch format {
+	case util.KeyFormatUint64BE:
+		if len(key) == 8 {
+			return strconv.FormatUint(binary.BigEndian.Uint64([]byte(key)), 10)
+		}
+	case util.KeyFormatHex:
+		return "0x" + hex.EncodeToString([]byte(key))
+	case util.KeyFormatMixed:
+		if len(key) == 8 {
+			return strconv.FormatUint(binary.BigEndian.Uint64([]byte(key)), 10)
+		}
+		if !util.IsPrintable([]byte(key)) {
+			return "0x" + hex.EncodeToString([]byte(key))
+		}
+	}
+	return key
+}
+
+func newResult(values []string, truncated bool) Result {
+	sort.Strings(values)
+	r := Result{Values: values, Total: len(values), HasMore: truncated}
+	if len(values) > MaxSuggestions {
+		r.Values = values[:MaxSuggestions]
+		r.HasMore = true
+	}
+	if r.Values == nil {
+		r.Values = []string{}
+	}
+	return r
+}
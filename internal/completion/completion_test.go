@@ -0,0 +1,117 @@
+package completion
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDB serves the reads completion uses from a map of column families
+type fakeDB struct {
+	db.KeyValueDB
+	cfs     map[string][]string
+	formats map[string]util.KeyFormat
+}
+
+func (f *fakeDB) ListCFs() ([]string, error) {
+	var cfs []string
+	for cf := range f.cfs {
+		cfs = append(cfs, cf)
+	}
+	return cfs, nil
+}
+
+func (f *fakeDB) GetKeyFormatInfo(cf string) (util.KeyFormat, string) {
+	return f.formats[cf], ""
+}
+
+func (f *fakeDB) PrefixScanCF(cf, prefix string, limit int) (map[string]string, error) {
+	keys, ok := f.cfs[cf]
+	if !ok {
+		return nil, db.ErrColumnFamilyNotFound
+	}
+	result := make(map[string]string)
+	for _, key := range keys {
+		if strings.HasPrefix(key, prefix) && len(result) < limit {
+			result[key] = ""
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeDB) ScanCF(cf string, start, end []byte, opts db.ScanOptions) (map[string]string, error) {
+	return f.PrefixScanCF(cf, "", opts.Limit)
+}
+
+func uint64Key(n uint64) string {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, n)
+	return string(b)
+}
+
+func TestColumnFamilies(t *testing.T) {
+	database := &fakeDB{cfs: map[string][]string{"default": nil, "users": nil, "user_events": nil, "orders": nil}}
+
+	r, err := ColumnFamilies(database, "user")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user_events", "users"}, r.Values)
+	assert.Equal(t, 2, r.Total)
+	assert.False(t, r.HasMore)
+
+	r, err = ColumnFamilies(database, "x")
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, r.Values)
+}
+
+func TestKeyPrefixes(t *testing.T) {
+	database := &fakeDB{
+		cfs: map[string][]string{
+			"default": {"user:1:name", "user:1:email", "user:2:name", "users", "order:9"},
+			"ids":     {uint64Key(1001), uint64Key(1002), uint64Key(2001)},
+		},
+		formats: map[string]util.KeyFormat{"ids": util.KeyFormatUint64BE},
+	}
+
+	r, err := KeyPrefixes(database, "default", "us")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user:", "users"}, r.Values, "suggestions stop at the next separator")
+
+	r, err = KeyPrefixes(database, "default", "user:1:")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user:1:email", "user:1:name"}, r.Values)
+
+	r, err = KeyPrefixes(database, "ids", "100")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1001", "1002"}, r.Values, "uint64 keys complete as numbers")
+
+	_, err = KeyPrefixes(database, "missing", "")
+	assert.Error(t, err)
+}
+
+func TestKeyPrefixes_Limits(t *testing.T) {
+	keys := make([]string, 0, 150)
+	for i := 0; i < 150; i++ {
+		keys = append(keys, "k"+strings.Repeat("x", i))
+	}
+	database := &fakeDB{cfs: map[string][]string{"default": keys}}
+
+	r, err := KeyPrefixes(database, "default", "k")
+	require.NoError(t, err)
+	assert.Len(t, r.Values, MaxSuggestions)
+	assert.Equal(t, 150, r.Total)
+	assert.True(t, r.HasMore)
+}
+
+func TestDisplayKey(t *testing.T) {
+	assert.Equal(t, "42", DisplayKey(uint64Key(42), util.KeyFormatUint64BE))
+	assert.Equal(t, "0x00ff", DisplayKey("\x00\xff", util.KeyFormatHex))
+	assert.Equal(t, "0x00ff", DisplayKey("\x00\xff", util.KeyFormatMixed))
+	assert.Equal(t, "plain", DisplayKey("plain", util.KeyFormatMixed))
+	assert.Equal(t, "plain", DisplayKey("plain", util.KeyFormatString))
+}
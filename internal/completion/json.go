@@ -0,0 +1,131 @@
+package completion
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"rocksdb-cli/internal/db"
+)
+
+const (
+	// sampleLimit bounds the values read to suggest JSON fields and paths
+	sampleLimit = 50
+
+	// maxPathDepth bounds how deep JSON paths are suggested
+	maxPathDepth = 5
+)
+
+// JSONFields suggests the top-level fields of the JSON object values of cf
+// starting with partial. Fields are sampled from the last sampleLimit
+// entries, the most recently added keys in the usual time-ordered key
+// schemes.
+func JSONFields(database db.KeyValueDB, cf, partial string) (Result, error) {
+	values, err := sampleValues(database, cf)
+	if err != nil {
+		return Result{}, err
+	}
+
+	seen := make(map[string]bool)
+	var fields []string
+	for _, value := range values {
+		var object map[string]interface{}
+		if json.Unmarshal([]byte(value), &object) != nil {
+			continue
+		}
+		for field := range object {
+			if !seen[field] && strings.HasPrefix(field, partial) {
+				seen[field] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+	return newResult(fields, false), nil
+}
+
+// JSONPaths suggests JSONPath expressions such as $.profile.email or
+// $.items[0] starting with partial, cut after the next path segment. Paths
+// are read from the value of key; when key is empty, missing or not JSON
+// they are sampled like JSONFields.
+func JSONPaths(database db.KeyValueDB, cf, key, partial string) (Result, error) {
+	var values []string
+	if key != "" {
+		if value, err := database.SmartGetCF(cf, key); err == nil && json.Valid([]byte(value)) {
+			values = []string{value}
+		}
+	}
+	if values == nil {
+		var err error
+		if values, err = sampleValues(database, cf); err != nil {
+			return Result{}, err
+		}
+	}
+
+	paths := make(map[string]bool)
+	for _, value := range values {
+		var data interface{}
+		if json.Unmarshal([]byte(value), &data) == nil {
+			collectPaths(data, "$", 0, paths)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var suggestions []string
+	for path := range paths {
+		if !strings.HasPrefix(path, partial) {
+			continue
+		}
+		suggestion := path
+		rest := path[len(partial):]
+		if len(rest) > 1 {
+			if i := strings.IndexAny(rest[1:], ".["); i >= 0 {
+				suggestion = path[:len(partial)+1+i]
+			}
+		}
+		if !seen[suggestion] {
+			seen[suggestion] = true
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+	return newResult(suggestions, false), nil
+}
+
+// collectPaths adds the path of data and of everything below it to paths.
+// Arrays contribute [*] and the paths of their first element as [0].
+func collectPaths(data interface{}, path string, depth int, paths map[string]bool) {
+	paths[path] = true
+	if depth >= maxPathDepth {
+		return
+	}
+
+	switch v := data.(type) {
+	case map[string]interface{}:
+		fields := make([]string, 0, len(v))
+		for field := range v {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			collectPaths(v[field], path+"."+field, depth+1, paths)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return
+		}
+		paths[path+"[*]"] = true
+		collectPaths(v[0], path+"[0]", depth+1, paths)
+	}
+}
+
+// sampleValues returns the values of the last sampleLimit entries of cf
+func sampleValues(database db.KeyValueDB, cf string) ([]string, error) {
+	found, err := database.ScanCF(cf, nil, nil, db.ScanOptions{Limit: sampleLimit, Reverse: true, Values: true})
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, 0, len(found))
+	for _, value := range found {
+		values = append(values, value)
+	}
+	return values, nil
+}
@@ -0,0 +1,82 @@
+package completion
+
+import (
+	"testing"
+
+	"rocksdb-cli/internal/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// valuesDB serves the values of one column family
+type valuesDB struct {
+	db.KeyValueDB
+	values map[string]string
+}
+
+func (v *valuesDB) ScanCF(cf string, start, end []byte, opts db.ScanOptions) (map[string]string, error) {
+	if cf != "default" {
+		return nil, db.ErrColumnFamilyNotFound
+	}
+	return v.values, nil
+}
+
+func (v *valuesDB) SmartGetCF(cf, key string) (string, error) {
+	value, ok := v.values[key]
+	if !ok {
+		return "", db.ErrKeyNotFound
+	}
+	return value, nil
+}
+
+func newValuesDB() *valuesDB {
+	return &valuesDB{values: map[string]string{
+		"user:1": `{"name":"Alice","profile":{"email":"a@example.com","age":30},"tags":["admin"]}`,
+		"user:2": `{"name":"Bob","status":"active"}`,
+		"raw":    "not json",
+	}}
+}
+
+func TestJSONFields(t *testing.T) {
+	database := newValuesDB()
+
+	r, err := JSONFields(database, "default", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name", "profile", "status", "tags"}, r.Values)
+
+	r, err = JSONFields(database, "default", "st")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"status"}, r.Values)
+
+	_, err = JSONFields(database, "missing", "")
+	assert.Error(t, err)
+}
+
+func TestJSONPaths(t *testing.T) {
+	database := newValuesDB()
+
+	tests := []struct {
+		key     string
+		partial string
+		want    []string
+	}{
+		{"user:1", "", []string{"$"}},
+		{"user:1", "$", []string{"$", "$.name", "$.profile", "$.tags"}},
+		{"user:1", "$.pro", []string{"$.profile"}},
+		{"user:1", "$.profile", []string{"$.profile", "$.profile.age", "$.profile.email"}},
+		{"user:1", "$.tags", []string{"$.tags", "$.tags[*]", "$.tags[0]"}},
+		{"user:2", "$.", []string{"$.name", "$.status"}},
+		// Without a JSON value for the key, paths are sampled from all values
+		{"raw", "$.s", []string{"$.status"}},
+		{"", "$.n", []string{"$.name"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"/"+tt.partial, func(t *testing.T) {
+			r, err := JSONPaths(database, "default", tt.key, tt.partial)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, r.Values)
+		})
+	}
+}
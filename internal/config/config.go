@@ -6,6 +6,9 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
+
+	"rocksdb-cli/internal/audit"
 
 	"gopkg.in/yaml.v2"
 )
@@ -28,6 +31,9 @@ type Config struct {
 	// GraphChain AI configuration
 	GraphChain *GraphChainConfig `yaml:"graphchain,omitempty" json:"graphchain,omitempty"`
 
+	// Audit log of mutating operations
+	Audit *audit.Config `yaml:"audit,omitempty" json:"audit,omitempty"`
+
 	// Logging configuration
 	LogLevel string `yaml:"log_level" json:"log_level"`
 }
@@ -36,6 +42,23 @@ type Config struct {
 type DatabaseConfig struct {
 	Path     string `yaml:"path" json:"path"`
 	ReadOnly bool   `yaml:"read_only" json:"read_only"`
+
+	// Per column family comparator and merge operator selection
+	ColumnFamilies map[string]ColumnFamilyConfig `yaml:"column_families,omitempty" json:"column_families,omitempty"`
+
+	// TTLMode decodes values written by DBWithTTL, e.g. "24h" or "24h,events=1h"
+	TTLMode string `yaml:"ttl_mode,omitempty" json:"ttl_mode,omitempty"`
+}
+
+// ColumnFamilyConfig selects built-in comparator and merge operator plugins
+// for a column family. The *_name fields override the name reported to
+// RocksDB, which must match the name used by the application that owns the DB.
+type ColumnFamilyConfig struct {
+	Comparator        string `yaml:"comparator,omitempty" json:"comparator,omitempty"`                   // bytewise, reverse-bytewise, uint64
+	ComparatorName    string `yaml:"comparator_name,omitempty" json:"comparator_name,omitempty"`
+	MergeOperator     string `yaml:"merge_operator,omitempty" json:"merge_operator,omitempty"`           // uint64add, stringappend, jsonmerge
+	MergeOperatorName string `yaml:"merge_operator_name,omitempty" json:"merge_operator_name,omitempty"`
+	Delimiter         string `yaml:"delimiter,omitempty" json:"delimiter,omitempty"`                     // stringappend delimiter, default ","
 }
 
 // MCPServerConfig holds MCP server configuration
@@ -46,14 +69,38 @@ type MCPServerConfig struct {
 	Description string            `yaml:"description,omitempty" json:"description,omitempty"`
 	Transport   TransportConfig   `yaml:"transport" json:"transport"`
 	Tools       ToolsConfig       `yaml:"tools,omitempty" json:"tools,omitempty"`
+
+	// Limits for the websocket and streamable-http transports
+	MaxConcurrentSessions int           `yaml:"max_concurrent_sessions,omitempty" json:"max_concurrent_sessions,omitempty"` // default 10
+	SessionTimeout        time.Duration `yaml:"session_timeout,omitempty" json:"session_timeout,omitempty"`                 // idle timeout, default 5m
+
+	// Resources per page of resources/list and of paginated resource reads
+	PageSize int `yaml:"page_size,omitempty" json:"page_size,omitempty"` // default 100
+
+	// Additional named databases, opened when first used. The database
+	// section is always hosted as "default".
+	Databases map[string]DatabaseConfig `yaml:"databases,omitempty" json:"databases,omitempty"`
+
+	// DefaultDatabase is the database of sessions that have not opened
+	// another one, "default" if empty
+	DefaultDatabase string `yaml:"default_database,omitempty" json:"default_database,omitempty"`
+
+	// Gateway re-exports the tools of the enabled mcp_clients, namespaced by
+	// client name, and forwards calls to them
+	Gateway             bool          `yaml:"gateway,omitempty" json:"gateway,omitempty"`
+	GatewaySyncInterval time.Duration `yaml:"gateway_sync_interval,omitempty" json:"gateway_sync_interval,omitempty"` // default 30s
 }
 
 // TransportConfig holds transport configuration for MCP server
 type TransportConfig struct {
-	Type       string `yaml:"type" json:"type"`
+	Type       string `yaml:"type" json:"type"` // stdio, tcp, unix, websocket, streamable-http
 	Host       string `yaml:"host,omitempty" json:"host,omitempty"`
 	Port       int    `yaml:"port,omitempty" json:"port,omitempty"`
+	Path       string `yaml:"path,omitempty" json:"path,omitempty"` // endpoint path, default /ws or /mcp
 	SocketPath string `yaml:"socket_path,omitempty" json:"socket_path,omitempty"`
+	// Web page origins allowed to use the WebSocket and Streamable HTTP
+	// endpoints besides the listen host
+	AllowedOrigins []string `yaml:"allowed_origins,omitempty" json:"allowed_origins,omitempty"`
 }
 
 // ToolsConfig holds tools configuration for MCP server
@@ -189,6 +236,21 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("MCP server name is required")
 		}
 	}
+	if c.MCPServer != nil {
+		for name, database := range c.MCPServer.Databases {
+			if name == "default" {
+				return fmt.Errorf("MCP server database name 'default' is reserved for the database section")
+			}
+			if database.Path == "" {
+				return fmt.Errorf("path is required for MCP server database '%s'", name)
+			}
+		}
+		if name := c.MCPServer.DefaultDatabase; name != "" && name != "default" {
+			if _, ok := c.MCPServer.Databases[name]; !ok {
+				return fmt.Errorf("MCP server default_database '%s' is not configured", name)
+			}
+		}
+	}
 
 	// Validate MCP clients configuration
 	for name, client := range c.MCPClients {
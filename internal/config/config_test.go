@@ -65,6 +65,39 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "database path is required",
 		},
+		{
+			name: "MCP database without path",
+			config: &Config{
+				Name:      "Test",
+				Version:   "1.0.0",
+				Database:  DatabaseConfig{Path: "/tmp/test"},
+				MCPServer: &MCPServerConfig{Databases: map[string]DatabaseConfig{"logs": {}}},
+			},
+			wantErr: true,
+			errMsg:  "path is required for MCP server database 'logs'",
+		},
+		{
+			name: "MCP database named default",
+			config: &Config{
+				Name:      "Test",
+				Version:   "1.0.0",
+				Database:  DatabaseConfig{Path: "/tmp/test"},
+				MCPServer: &MCPServerConfig{Databases: map[string]DatabaseConfig{"default": {Path: "/tmp/other"}}},
+			},
+			wantErr: true,
+			errMsg:  "reserved",
+		},
+		{
+			name: "unknown MCP default database",
+			config: &Config{
+				Name:      "Test",
+				Version:   "1.0.0",
+				Database:  DatabaseConfig{Path: "/tmp/test"},
+				MCPServer: &MCPServerConfig{DefaultDatabase: "logs"},
+			},
+			wantErr: true,
+			errMsg:  "default_database 'logs' is not configured",
+		},
 	}
 
 	for _, tt := range tests {
@@ -151,6 +184,28 @@ func TestMCPClientConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "socket path is required",
 		},
+		{
+			name: "valid streamable http config",
+			config: &MCPClientConfig{
+				Name:      "test",
+				Transport: "streamable-http",
+				Host:      "localhost",
+				Port:      8080,
+				Timeout:   30 * time.Second,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid port for streamable http",
+			config: &MCPClientConfig{
+				Name:      "test",
+				Transport: "streamable-http",
+				Host:      "localhost",
+				Timeout:   30 * time.Second,
+			},
+			wantErr: true,
+			errMsg:  "valid port number is required for Streamable HTTP transport",
+		},
 		{
 			name: "unsupported transport",
 			config: &MCPClientConfig{
@@ -176,6 +231,15 @@ func TestMCPClientConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestMCPClientConfig_DefaultPath(t *testing.T) {
+	// Each transport defaults to the path rocksdb-mcp-server serves it at
+	for transport, want := range map[string]string{"websocket": "/ws", "streamable-http": "/mcp"} {
+		c := &MCPClientConfig{Name: "test", Transport: transport, Port: 8080, Timeout: 30 * time.Second}
+		require.NoError(t, c.Validate())
+		assert.Equal(t, want, c.Path, transport)
+	}
+}
+
 func TestMCPClientConfig_IsToolEnabled(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -250,6 +314,33 @@ version: "1.0.0"
 database:
   path: "/tmp/testdb"
   read_only: true
+  column_families:
+    counters:
+      merge_operator: "uint64add"
+    events:
+      comparator: "reverse-bytewise"
+  ttl_mode: "24h,events=1h"
+
+audit:
+  file: "/var/log/rocksdb-cli/audit.log"
+  values: "plain"
+  redact_fields: ["password"]
+
+mcp_server:
+  enabled: true
+  name: "Test MCP"
+  transport:
+    type: "streamable-http"
+    port: 9090
+    path: "/rpc"
+  max_concurrent_sessions: 4
+  session_timeout: 90s
+  page_size: 25
+  default_database: "analytics"
+  databases:
+    analytics:
+      path: "/tmp/analytics"
+      read_only: true
 
 mcp_clients:
   filesystem:
@@ -271,6 +362,21 @@ mcp_clients:
 	assert.Equal(t, "1.0.0", config.Version)
 	assert.Equal(t, "/tmp/testdb", config.Database.Path)
 	assert.True(t, config.Database.ReadOnly)
+	assert.Equal(t, "uint64add", config.Database.ColumnFamilies["counters"].MergeOperator)
+	assert.Equal(t, "reverse-bytewise", config.Database.ColumnFamilies["events"].Comparator)
+	assert.Equal(t, "24h,events=1h", config.Database.TTLMode)
+	require.NotNil(t, config.Audit)
+	assert.True(t, config.Audit.Enabled())
+	assert.Equal(t, "plain", config.Audit.Values)
+	assert.Equal(t, []string{"password"}, config.Audit.RedactFields)
+	require.NotNil(t, config.MCPServer)
+	assert.Equal(t, "streamable-http", config.MCPServer.Transport.Type)
+	assert.Equal(t, "/rpc", config.MCPServer.Transport.Path)
+	assert.Equal(t, 4, config.MCPServer.MaxConcurrentSessions)
+	assert.Equal(t, 90*time.Second, config.MCPServer.SessionTimeout)
+	assert.Equal(t, 25, config.MCPServer.PageSize)
+	assert.Equal(t, "analytics", config.MCPServer.DefaultDatabase)
+	assert.Equal(t, DatabaseConfig{Path: "/tmp/analytics", ReadOnly: true}, config.MCPServer.Databases["analytics"])
 	assert.Len(t, config.MCPClients, 1)
 
 	fsClient, ok := config.MCPClients["filesystem"]
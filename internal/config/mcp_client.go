@@ -12,16 +12,16 @@ type MCPClientConfig struct {
 	Enabled bool   `yaml:"enabled" json:"enabled"`
 
 	// Transport configuration
-	Transport string `yaml:"transport" json:"transport"` // stdio, tcp, websocket, unix
+	Transport string `yaml:"transport" json:"transport"` // stdio, tcp, websocket, streamable-http, unix
 
 	// For STDIO transport
 	Command string   `yaml:"command,omitempty" json:"command,omitempty"`
 	Args    []string `yaml:"args,omitempty" json:"args,omitempty"`
 
-	// For TCP/WebSocket transport
+	// For TCP/WebSocket/Streamable HTTP transport
 	Host string `yaml:"host,omitempty" json:"host,omitempty"`
 	Port int    `yaml:"port,omitempty" json:"port,omitempty"`
-	Path string `yaml:"path,omitempty" json:"path,omitempty"` // For WebSocket
+	Path string `yaml:"path,omitempty" json:"path,omitempty"` // For WebSocket (default /ws) and Streamable HTTP (default /mcp)
 
 	// For Unix Socket transport
 	SocketPath string `yaml:"socket_path,omitempty" json:"socket_path,omitempty"`
@@ -35,6 +35,9 @@ type MCPClientConfig struct {
 	// Retry configuration
 	Retry RetryConfig `yaml:"retry,omitempty" json:"retry,omitempty"`
 
+	// How often a supervised client is pinged to detect a dead connection
+	HealthCheckInterval time.Duration `yaml:"health_check_interval,omitempty" json:"health_check_interval,omitempty"`
+
 	// Tool filtering
 	EnabledTools  []string `yaml:"enabled_tools,omitempty" json:"enabled_tools,omitempty"`
 	DisabledTools []string `yaml:"disabled_tools,omitempty" json:"disabled_tools,omitempty"`
@@ -64,6 +67,7 @@ func DefaultMCPClientConfig(name string) *MCPClientConfig {
 			InitialWait: 1 * time.Second,
 			MaxWait:     30 * time.Second,
 		},
+		HealthCheckInterval: 30 * time.Second,
 	}
 }
 
@@ -98,6 +102,16 @@ func (c *MCPClientConfig) Validate() error {
 		if c.Port <= 0 || c.Port > 65535 {
 			return fmt.Errorf("valid port number is required for WebSocket transport")
 		}
+		if c.Path == "" {
+			c.Path = "/ws"
+		}
+	case "streamable-http":
+		if c.Host == "" {
+			c.Host = "localhost"
+		}
+		if c.Port <= 0 || c.Port > 65535 {
+			return fmt.Errorf("valid port number is required for Streamable HTTP transport")
+		}
 		if c.Path == "" {
 			c.Path = "/mcp"
 		}
@@ -133,6 +147,10 @@ func (c *MCPClientConfig) Validate() error {
 		c.Retry.MaxWait = 30 * time.Second
 	}
 
+	if c.HealthCheckInterval <= 0 {
+		c.HealthCheckInterval = 30 * time.Second
+	}
+
 	return nil
 }
 
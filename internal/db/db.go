@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand/v2"
 	"os"
 	"regexp"
 	"strconv"
@@ -13,6 +14,7 @@ import (
 	"sync"
 	"time"
 
+	"rocksdb-cli/internal/keytree"
 	"rocksdb-cli/internal/util"
 
 	"encoding/binary"
@@ -28,6 +30,7 @@ var (
 	ErrReadOnlyMode         = errors.New("operation not allowed in read-only mode")
 	ErrColumnFamilyEmpty    = errors.New("column family is empty")
 	ErrDatabaseClosed       = errors.New("database is closed")
+	ErrNotBytewise          = errors.New("column family does not use the bytewise comparator")
 )
 
 // DataType represents the detected data type of a value
@@ -55,7 +58,10 @@ type CFStats struct {
 	ValueLengthDistribution map[string]int64   `json:"value_length_distribution"`
 	CommonPrefixes          map[string]int64   `json:"common_prefixes"`
 	SampleKeys              []string           `json:"sample_keys"`
+	JSONSamples             []string           `json:"json_samples,omitempty"` // JSON values sampled uniformly when StatsOptions.JSONSamples is set
 	LastUpdated             time.Time          `json:"last_updated"`
+	NextCursor              string             `json:"next_cursor,omitempty"` // Last key counted if the scan was stopped
+	HasMore                 bool               `json:"has_more,omitempty"`    // True if the scan was stopped before the end
 }
 
 // DatabaseStats contains overall database statistics
@@ -79,6 +85,8 @@ type SearchOptions struct {
 	KeysOnly      bool   `json:"keys_only"`      // Return only keys, not values
 	After         string `json:"after"`          // Cursor for pagination
 	Tick          bool   `json:"tick"`           // Whether to treat keys as .NET tick times and convert to UTC string
+
+	Progress ProgressFunc `json:"-"` // Called as keys are visited; an error stops the search
 }
 
 // SearchResult contains a single search result
@@ -89,6 +97,8 @@ type SearchResult struct {
 	ValueIsBinary bool     `json:"value_is_binary"` // true if value is base64 encoded
 	Timestamp     string   `json:"timestamp"`       // parsed timestamp if key is a timestamp
 	MatchedFields []string `json:"matched_fields"`  // Which fields matched (key, value, both)
+	TTL           *TTLInfo `json:"ttl,omitempty"`   // decoded expiry in TTL mode
+	RawKey        string   `json:"-"`               // the key as stored, which Key encodes
 }
 
 // SearchResults contains search results and metadata
@@ -103,21 +113,22 @@ type SearchResults struct {
 
 // KeyValue represents a single key-value pair with binary encoding info
 type KeyValue struct {
-	Key           string `json:"key"`
-	Value         string `json:"value"`
-	KeyIsBinary   bool   `json:"key_is_binary"`   // true if key is base64 encoded
-	ValueIsBinary bool   `json:"value_is_binary"` // true if value is base64 encoded
-	Timestamp     string `json:"timestamp"`       // parsed timestamp if key is a timestamp
+	Key           string   `json:"key"`
+	Value         string   `json:"value"`
+	KeyIsBinary   bool     `json:"key_is_binary"`   // true if key is base64 encoded
+	ValueIsBinary bool     `json:"value_is_binary"` // true if value is base64 encoded
+	Timestamp     string   `json:"timestamp"`       // parsed timestamp if key is a timestamp
+	TTL           *TTLInfo `json:"ttl,omitempty"`   // decoded expiry in TTL mode
 }
 
 // ScanPageResult contains paginated scan results
 // NextCursor is the last key in this page, or "" if no more
 // HasMore is true if more results exist
 type ScanPageResult struct {
-	Results    map[string]string // Deprecated: use ResultsV2 for binary support
-	ResultsV2  []KeyValue        // New format with binary encoding support
-	NextCursor string
-	HasMore    bool
+	Results    map[string]string `json:"-"`           // Deprecated: use ResultsV2 for binary support
+	ResultsV2  []KeyValue        `json:"results"`     // New format with binary encoding support
+	NextCursor string            `json:"next_cursor"` // Last key in this page, or "" if no more
+	HasMore    bool              `json:"has_more"`    // True if more results exist
 }
 
 // ScanOptions now supports cursor-based pagination
@@ -129,6 +140,37 @@ type ScanOptions struct {
 	StartAfter string // cursor for pagination
 }
 
+// ProgressFunc is called every progressInterval keys visited by a
+// long-running operation, with the number of keys visited so far and the
+// last key. Returning an error stops the operation, which then returns its
+// partial results with HasMore set and a NextCursor to resume from.
+type ProgressFunc func(visited int64, lastKey string) error
+
+// progressInterval is the number of keys visited between ProgressFunc calls
+const progressInterval = 1000
+
+// ExportOptions contains options for exporting a column family to CSV
+type ExportOptions struct {
+	Sep      string       // CSV separator, "," if empty
+	After    string       // Cursor to resume from; rows are appended to the file
+	Progress ProgressFunc // Called as keys are visited; an error stops the export
+}
+
+// ExportResult describes a finished or stopped export
+type ExportResult struct {
+	Rows       int64  `json:"rows"`        // Rows written by this call
+	NextCursor string `json:"next_cursor"` // Last key written, or "" if the export is complete
+	HasMore    bool   `json:"has_more"`    // True if the export was stopped before the end
+}
+
+// StatsOptions contains options for computing column family statistics
+type StatsOptions struct {
+	After       string       // Cursor to resume from; only later keys are counted
+	Progress    ProgressFunc // Called as keys are visited; an error stops the scan
+	Prefix      string       // Only keys with this prefix are counted
+	JSONSamples int          // Number of JSON values to sample into CFStats.JSONSamples
+}
+
 type KeyValueDB interface {
 	GetCF(cf, key string) (string, error)
 	PutCF(cf, key, value string) error
@@ -143,8 +185,9 @@ type KeyValueDB interface {
 	ListCFs() ([]string, error)
 	CreateCF(cf string) error
 	DropCF(cf string) error
-	GetCFStats(cf string) (*CFStats, error)    // Get statistics for a specific column family
-	GetDatabaseStats() (*DatabaseStats, error) // Get overall database statistics
+	GetCFStats(cf string) (*CFStats, error)                            // Get statistics for a specific column family
+	GetDatabaseStats() (*DatabaseStats, error)                         // Get overall database statistics
+	GetKeyTree(cf string, opts keytree.Options) (*keytree.Node, error) // Key prefixes as a tree, see keytree
 	IsReadOnly() bool
 	Close()
 
@@ -154,6 +197,22 @@ type KeyValueDB interface {
 	SmartScanCF(cf string, start, end string, opts ScanOptions) (map[string]string, error)
 	SmartScanCFPage(cf string, start, end string, opts ScanOptions) (ScanPageResult, error) // new paginated version
 	GetKeyFormatInfo(cf string) (util.KeyFormat, string)
+
+	// Column family options
+	CreateCFWithProfile(cf, profile string) error
+	GetCFOptions(cf string) (*CFOptions, error)
+	OptionMismatches() []OptionMismatch
+	MergeCF(cf, key, operand string) error // Merge operand using the CF's merge operator
+
+	// Long-running operations that report progress and can be resumed
+	ExportToCSVWithOptions(cf, filePath string, opts ExportOptions) (*ExportResult, error)
+	GetCFStatsWithOptions(cf string, opts StatsOptions) (*CFStats, error)
+
+	// TTL mode, see ttl.go
+	PutCFWithTTL(cf, key, value string, ttl time.Duration) error
+	GetCFWithTTL(cf, key string) (string, *TTLInfo, error)
+	GetTTLStats(cf string) (*TTLStats, error)
+	TTLMode() bool
 }
 
 type DB struct {
@@ -164,6 +223,16 @@ type DB struct {
 	readOnly   bool
 	keyFormats map[string]util.KeyFormat // Cache of detected key formats per CF
 	formatMux  sync.RWMutex              // Mutex for keyFormats map
+
+	// Column family options loaded from the OPTIONS file, see options.go
+	options      *loadedOptions
+	optionsFile  string
+	cfOptions    map[string]*CFOptions
+	mismatches   []OptionMismatch
+	optionsMux   sync.RWMutex
+	plugins      map[string]CFPluginConfig       // Comparator/merge operator config per CF, see plugins.go
+	mergePlugins map[string]*MergeOperatorPlugin // Merge operator installed per CF
+	ttl          *TTLConfig                      // Non-nil in TTL mode, see ttl.go
 }
 
 func Open(path string) (*DB, error) {
@@ -174,49 +243,90 @@ func OpenReadOnly(path string) (*DB, error) {
 	return OpenWithOptions(path, true)
 }
 
+// OpenWithOptions opens the database with the options stored in its latest
+// OPTIONS file. Column families whose stored options cannot be applied are
+// opened with defaults and reported by OptionMismatches.
 func OpenWithOptions(path string, readOnly bool) (*DB, error) {
+	return OpenWithPlugins(path, readOnly, nil)
+}
+
+// OpenWithPlugins is OpenWithOptions with comparators and merge operators
+// selected per column family from the plugin registry
+func OpenWithPlugins(path string, readOnly bool, plugins map[string]CFPluginConfig) (*DB, error) {
+	return OpenWithConfig(path, OpenConfig{ReadOnly: readOnly, Plugins: plugins})
+}
+
+// OpenConfig collects the optional settings for OpenWithConfig
+type OpenConfig struct {
+	ReadOnly bool
+	Plugins  map[string]CFPluginConfig // Comparator/merge operator per CF
+	TTL      *TTLConfig                // Decode values written by DBWithTTL
+}
+
+// OpenWithConfig opens the database like OpenWithOptions with plugins and
+// TTL mode applied
+func OpenWithConfig(path string, cfg OpenConfig) (*DB, error) {
+	readOnly, plugins := cfg.ReadOnly, cfg.Plugins
 	cfNames, err := grocksdb.ListColumnFamilies(grocksdb.NewDefaultOptions(), path)
 	if err != nil || len(cfNames) == 0 {
 		cfNames = []string{"default"}
 	}
-	opts := grocksdb.NewDefaultOptions()
-	opts.SetCreateIfMissing(true)
-	opts.SetCreateIfMissingColumnFamilies(true)
-	cfOpts := make([]*grocksdb.Options, len(cfNames))
-	for i := range cfNames {
-		cfOpts[i] = grocksdb.NewDefaultOptions()
+	lo, err := loadOptions(path, cfNames, plugins)
+	if err != nil {
+		lo.destroy()
+		return nil, err
 	}
 
-	var db *grocksdb.DB
-	var cfHandles []*grocksdb.ColumnFamilyHandle
-
-	if readOnly {
-		// Use read-only mode - don't create missing column families in read-only mode
-		opts.SetCreateIfMissing(false)
-		opts.SetCreateIfMissingColumnFamilies(false)
-		db, cfHandles, err = grocksdb.OpenDbForReadOnlyColumnFamilies(opts, path, cfNames, cfOpts, false)
-	} else {
-		db, cfHandles, err = grocksdb.OpenDbColumnFamilies(opts, path, cfNames, cfOpts)
+	db, cfHandles, err := openColumnFamilies(path, readOnly, lo.dbOpts, cfNames, lo.cfOpts)
+	if err != nil && lo.fromFile() {
+		// The stored options were rejected, e.g. a custom comparator this
+		// binary does not know. Retry with defaults and report why.
+		if ferr := lo.fallback(cfNames, err); ferr != nil {
+			lo.destroy()
+			return nil, ferr
+		}
+		db, cfHandles, err = openColumnFamilies(path, readOnly, lo.dbOpts, cfNames, lo.cfOpts)
 	}
-
+	lo.release()
 	if err != nil {
+		lo.destroy()
 		return nil, err
 	}
+
 	cfHandleMap := make(map[string]*grocksdb.ColumnFamilyHandle)
 	for i, name := range cfNames {
 		cfHandleMap[name] = cfHandles[i]
 	}
 	return &DB{
-		db:         db,
-		cfHandles:  cfHandleMap,
-		ro:         grocksdb.NewDefaultReadOptions(),
-		wo:         grocksdb.NewDefaultWriteOptions(),
-		readOnly:   readOnly,
-		keyFormats: make(map[string]util.KeyFormat),
-		formatMux:  sync.RWMutex{},
+		db:           db,
+		cfHandles:    cfHandleMap,
+		ro:           grocksdb.NewDefaultReadOptions(),
+		wo:           grocksdb.NewDefaultWriteOptions(),
+		readOnly:     readOnly,
+		keyFormats:   make(map[string]util.KeyFormat),
+		formatMux:    sync.RWMutex{},
+		options:      lo,
+		optionsFile:  lo.file,
+		cfOptions:    lo.describe,
+		mismatches:   lo.mismatches,
+		plugins:      plugins,
+		mergePlugins: lo.mergePlugins,
+		ttl:          cfg.TTL,
 	}, nil
 }
 
+func openColumnFamilies(path string, readOnly bool, opts *grocksdb.Options, cfNames []string, cfOpts []*grocksdb.Options) (*grocksdb.DB, []*grocksdb.ColumnFamilyHandle, error) {
+	if readOnly {
+		// Use read-only mode - don't create missing column families in read-only mode
+		opts.SetCreateIfMissing(false)
+		opts.SetCreateIfMissingColumnFamilies(false)
+		return grocksdb.OpenDbForReadOnlyColumnFamilies(opts, path, cfNames, cfOpts, false)
+	}
+	opts.SetCreateIfMissing(true)
+	opts.SetCreateIfMissingColumnFamilies(true)
+	return grocksdb.OpenDbColumnFamilies(opts, path, cfNames, cfOpts)
+}
+
 func (d *DB) Close() {
 	for _, h := range d.cfHandles {
 		h.Destroy()
@@ -224,6 +334,9 @@ func (d *DB) Close() {
 	d.db.Close()
 	d.ro.Destroy()
 	d.wo.Destroy()
+	if d.options != nil {
+		d.options.destroy()
+	}
 }
 
 func (d *DB) GetCF(cf, key string) (string, error) {
@@ -239,18 +352,11 @@ func (d *DB) GetCF(cf, key string) (string, error) {
 	if !val.Exists() {
 		return "", ErrKeyNotFound
 	}
-	return string(val.Data()), nil
+	return d.valueString(cf, val.Data()), nil
 }
 
 func (d *DB) PutCF(cf, key, value string) error {
-	if d.readOnly {
-		return ErrReadOnlyMode
-	}
-	h, ok := d.cfHandles[cf]
-	if !ok {
-		return ErrColumnFamilyNotFound
-	}
-	return d.db.PutCF(d.wo, h, []byte(key), []byte(value))
+	return d.PutCFWithTTL(cf, key, value, 0)
 }
 
 func (d *DB) PrefixScanCF(cf, prefix string, limit int) (map[string]string, error) {
@@ -269,7 +375,7 @@ func (d *DB) PrefixScanCF(cf, prefix string, limit int) (map[string]string, erro
 			v.Free()
 			break
 		}
-		result[string(k.Data())] = string(v.Data())
+		result[string(k.Data())] = d.valueString(cf, v.Data())
 		k.Free()
 		v.Free()
 		if limit > 0 && len(result) >= limit {
@@ -347,7 +453,7 @@ func (d *DB) ScanCF(cf string, start, end []byte, opts ScanOptions) (map[string]
 		// Store key-value pair
 		if opts.Values {
 			v := it.Value()
-			result[kStr] = string(v.Data())
+			result[kStr] = d.valueString(cf, v.Data())
 			v.Free()
 		} else {
 			result[kStr] = ""
@@ -382,12 +488,8 @@ func (d *DB) CreateCF(cf string) error {
 	if _, exists := d.cfHandles[cf]; exists {
 		return ErrColumnFamilyExists
 	}
-	h, err := d.db.CreateColumnFamily(grocksdb.NewDefaultOptions(), cf)
-	if err != nil {
-		return err
-	}
-	d.cfHandles[cf] = h
-	return nil
+	opts := grocksdb.NewDefaultOptions()
+	return d.createCF(cf, opts, func() *CFOptions { return describeOptions(cf, optionsSourceDefaults, opts) })
 }
 
 func (d *DB) DropCF(cf string) error {
@@ -404,6 +506,11 @@ func (d *DB) DropCF(cf string) error {
 	}
 	h.Destroy()
 	delete(d.cfHandles, cf)
+
+	d.optionsMux.Lock()
+	delete(d.cfOptions, cf)
+	delete(d.mergePlugins, cf)
+	d.optionsMux.Unlock()
 	return nil
 }
 
@@ -427,57 +534,114 @@ func (d *DB) GetLastCF(cf string) (string, string, error) {
 	defer k.Free()
 	defer v.Free()
 
-	return string(k.Data()), string(v.Data()), nil
+	return string(k.Data()), d.valueString(cf, v.Data()), nil
 }
 
 func (d *DB) ExportToCSV(cf, filePath, sep string) error {
+	_, err := d.ExportToCSVWithOptions(cf, filePath, ExportOptions{Sep: sep})
+	return err
+}
+
+// ExportToCSVWithOptions exports cf to a CSV file. With opts.After the
+// export resumes after that cursor and appends to the file without a header.
+func (d *DB) ExportToCSVWithOptions(cf, filePath string, opts ExportOptions) (*ExportResult, error) {
 	h, ok := d.cfHandles[cf]
 	if !ok {
-		return ErrColumnFamilyNotFound
-	}
-
-	file, err := os.Create(filePath)
-	if err != nil {
-		return err
+		return nil, ErrColumnFamilyNotFound
 	}
-	defer file.Close()
 
-	writer := csv.NewWriter(file)
+	sep := opts.Sep
 	if sep == "" {
 		sep = ","
 	}
 	runes := []rune(sep)
 	if len(runes) != 1 {
-		return fmt.Errorf("CSV separator must be a single character, got: %q", sep)
+		return nil, fmt.Errorf("CSV separator must be a single character, got: %q", sep)
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if opts.After != "" {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(filePath, flags, 0644)
+	if err != nil {
+		return nil, err
 	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
 	writer.Comma = runes[0]
 	defer writer.Flush()
 
 	// Write CSV header
-	err = writer.Write([]string{"Key", "Value"})
-	if err != nil {
-		return err
+	if opts.After == "" {
+		err = writer.Write([]string{"Key", "Value"})
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	it := d.db.NewIteratorCF(d.ro, h)
 	defer it.Close()
 
-	for it.SeekToFirst(); it.Valid(); it.Next() {
+	result := &ExportResult{}
+	for seekAfter(it, opts.After); it.Valid(); it.Next() {
 		k := it.Key()
 		v := it.Value()
+		keyStr := string(k.Data())
 
-		err := writer.Write([]string{util.FormatKey(string(k.Data())), string(v.Data())})
+		err := writer.Write([]string{util.FormatKey(keyStr), d.valueString(cf, v.Data())})
+		k.Free()
+		v.Free()
 		if err != nil {
-			k.Free()
-			v.Free()
-			return err
+			return nil, err
 		}
 
+		result.Rows++
+		if stopped(opts.Progress, result.Rows, keyStr) {
+			result.NextCursor = encodeCursor(keyStr)
+			result.HasMore = true
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// seekAfter positions it on the first key after cursor, or on the first key
+// if cursor is empty. The cursor is a base64 encoded key, or a plain key.
+func seekAfter(it *grocksdb.Iterator, cursor string) {
+	if cursor == "" {
+		it.SeekToFirst()
+		return
+	}
+	after := cursor
+	if decoded, err := base64.StdEncoding.DecodeString(cursor); err == nil {
+		after = string(decoded)
+	}
+	it.Seek([]byte(after))
+	if it.Valid() {
+		k := it.Key()
+		if string(k.Data()) == after {
+			it.Next()
+		}
 		k.Free()
-		v.Free()
 	}
+}
 
-	return nil
+// encodeCursor returns the cursor of key, base64 encoded to be safe for
+// binary keys
+func encodeCursor(key string) string {
+	return base64.StdEncoding.EncodeToString([]byte(key))
+}
+
+// stopped calls progress every progressInterval keys and reports whether it
+// asked to stop
+func stopped(progress ProgressFunc, visited int64, lastKey string) bool {
+	if progress == nil || visited%progressInterval != 0 {
+		return false
+	}
+	return progress(visited, lastKey) != nil
 }
 
 // ExportSearchResultsToCSV exports search results to a CSV file
@@ -560,7 +724,7 @@ func (d *DB) JSONQueryCF(cf, field, value string) (map[string]string, error) {
 		k := it.Key()
 		v := it.Value()
 		keyStr := string(k.Data())
-		valueStr := string(v.Data())
+		valueStr := d.valueString(cf, v.Data())
 
 		// Try to parse as JSON
 		var jsonData map[string]interface{}
@@ -677,6 +841,13 @@ func getKeyPrefix(key string, maxLen int) string {
 }
 
 func (d *DB) GetCFStats(cf string) (*CFStats, error) {
+	return d.GetCFStatsWithOptions(cf, StatsOptions{})
+}
+
+// GetCFStatsWithOptions computes statistics of cf. With opts.After only the
+// keys after that cursor are counted, and with opts.Prefix only the keys with
+// that prefix.
+func (d *DB) GetCFStatsWithOptions(cf string, opts StatsOptions) (*CFStats, error) {
 	h, ok := d.cfHandles[cf]
 	if !ok {
 		return nil, ErrColumnFamilyNotFound
@@ -698,12 +869,34 @@ func (d *DB) GetCFStats(cf string) (*CFStats, error) {
 	sampleCount := 0
 	const maxSamples = 10
 
-	for it.SeekToFirst(); it.Valid(); it.Next() {
+	// Reservoir sampling keeps every JSON value equally likely to be
+	// sampled; the fixed seed samples the same values of the same data
+	var jsonCount int64
+	rng := rand.New(rand.NewPCG(1, 1))
+
+	// Seeking to the prefix and stopping past it assume bytewise key order
+	prefix := []byte(opts.Prefix)
+	if len(prefix) > 0 {
+		cfOpts, _ := d.GetCFOptions(cf)
+		if err := RequireBytewise(cfOpts, cf); err != nil {
+			return nil, err
+		}
+	}
+	if opts.After == "" && len(prefix) > 0 {
+		it.Seek(prefix)
+	} else {
+		seekAfter(it, opts.After)
+	}
+	for ; it.Valid(); it.Next() {
 		k := it.Key()
+		if !hasPrefix(k.Data(), prefix) {
+			k.Free()
+			break
+		}
 		v := it.Value()
 
 		keyStr := string(k.Data())
-		valueStr := string(v.Data())
+		valueStr := d.valueString(cf, v.Data())
 
 		// Update counters
 		stats.KeyCount++
@@ -715,6 +908,14 @@ func (d *DB) GetCFStats(cf string) (*CFStats, error) {
 		// Detect data type
 		dataType := detectDataType(valueStr)
 		stats.DataTypeDistribution[dataType]++
+		if dataType == DataTypeJSON && opts.JSONSamples > 0 {
+			jsonCount++
+			if len(stats.JSONSamples) < opts.JSONSamples {
+				stats.JSONSamples = append(stats.JSONSamples, valueStr)
+			} else if i := rng.Int64N(jsonCount); i < int64(opts.JSONSamples) {
+				stats.JSONSamples[i] = valueStr
+			}
+		}
 
 		// Key length distribution (categorized)
 		keyLenCategory := categorizeLength(keyLen)
@@ -736,6 +937,12 @@ func (d *DB) GetCFStats(cf string) (*CFStats, error) {
 
 		k.Free()
 		v.Free()
+
+		if stopped(opts.Progress, stats.KeyCount, keyStr) {
+			stats.NextCursor = encodeCursor(keyStr)
+			stats.HasMore = true
+			break
+		}
 	}
 
 	// Calculate averages
@@ -767,6 +974,66 @@ func categorizeLength(length int64) string {
 	}
 }
 
+// GetKeyTree returns the tree of the key prefixes of cf split as opts says,
+// with the number and size of the keys under each prefix and the space the
+// prefix takes on disk as estimated by RocksDB
+func (d *DB) GetKeyTree(cf string, opts keytree.Options) (*keytree.Node, error) {
+	h, ok := d.cfHandles[cf]
+	if !ok {
+		return nil, ErrColumnFamilyNotFound
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	// Seeking to the prefix and the size ranges assume bytewise key order
+	cfOpts, _ := d.GetCFOptions(cf)
+	if err := RequireBytewise(cfOpts, cf); err != nil {
+		return nil, err
+	}
+
+	b := keytree.NewBuilder(opts)
+	prefix := []byte(opts.Prefix)
+	it := d.db.NewIteratorCF(d.ro, h)
+	defer it.Close()
+	for it.Seek(prefix); it.Valid(); it.Next() {
+		k := it.Key()
+		v := it.Value()
+		if !hasPrefix(k.Data(), prefix) {
+			k.Free()
+			v.Free()
+			break
+		}
+		value, _ := d.decodeValue(cf, v.Data())
+		b.Add(k.Data(), len(value))
+		k.Free()
+		v.Free()
+	}
+	tree := b.Tree()
+
+	// One call estimates all the prefix ranges; other nodes have no range
+	var nodes []*keytree.Node
+	var ranges []grocksdb.Range
+	tree.Walk(func(n *keytree.Node) {
+		end := keytree.PrefixEnd([]byte(n.Prefix))
+		if n.Other || end == nil {
+			return
+		}
+		nodes = append(nodes, n)
+		ranges = append(ranges, grocksdb.Range{Start: []byte(n.Prefix), Limit: end})
+	})
+	if sizes, err := d.db.GetApproximateSizesCF(h, ranges); err == nil {
+		for i, size := range sizes {
+			nodes[i].ApproximateSize = size
+		}
+	}
+	if opts.Prefix == "" {
+		if size, ok := d.db.GetIntPropertyCF("rocksdb.estimate-live-data-size", h); ok {
+			tree.ApproximateSize = size
+		}
+	}
+	return tree, nil
+}
+
 func (d *DB) GetDatabaseStats() (*DatabaseStats, error) {
 	cfs, err := d.ListCFs()
 	if err != nil {
@@ -899,6 +1166,8 @@ func (d *DB) SearchCF(cf string, opts SearchOptions) (*SearchResults, error) {
 
 	// Start iteration from StartKey if specified, otherwise from beginning
 	var lastKey string
+	var visited int64
+	var interrupted bool
 	if startKeyBytes != nil {
 		it.Seek(startKeyBytes)
 	} else {
@@ -956,7 +1225,8 @@ func (d *DB) SearchCF(cf string, opts SearchOptions) (*SearchResults, error) {
 		}
 
 		v := it.Value()
-		valueStr := string(v.Data())
+		vData, ttlInfo := d.decodeValue(cf, v.Data())
+		valueStr := string(vData)
 
 		var keyMatches, valueMatches bool
 		var matchedFields []string
@@ -1012,7 +1282,6 @@ func (d *DB) SearchCF(cf string, opts SearchOptions) (*SearchResults, error) {
 			var valueEncoded string
 			var valueIsBinary bool
 			if !opts.KeysOnly {
-				vData := v.Data()
 				valueEncoded, valueIsBinary = util.EncodeValue(vData)
 			}
 
@@ -1028,6 +1297,8 @@ func (d *DB) SearchCF(cf string, opts SearchOptions) (*SearchResults, error) {
 				ValueIsBinary: valueIsBinary,
 				Timestamp:     util.ParseTimestamp(keyEncoded),
 				MatchedFields: matchedFields,
+				TTL:           ttlInfo,
+				RawKey:        keyStr,
 			}
 			results.Results = append(results.Results, result)
 			lastKey = keyStr
@@ -1037,6 +1308,13 @@ func (d *DB) SearchCF(cf string, opts SearchOptions) (*SearchResults, error) {
 		}
 		k.Free()
 		v.Free()
+
+		visited++
+		if stopped(opts.Progress, visited, keyStr) {
+			lastKey = keyStr
+			interrupted = true
+			break
+		}
 	}
 
 	results.Total = len(results.Results)
@@ -1047,6 +1325,9 @@ func (d *DB) SearchCF(cf string, opts SearchOptions) (*SearchResults, error) {
 		// Encode cursor as base64 to safely handle binary keys
 		results.NextCursor = base64.StdEncoding.EncodeToString([]byte(lastKey))
 		results.HasMore = true
+	} else if interrupted {
+		results.NextCursor = encodeCursor(lastKey)
+		results.HasMore = true
 	}
 
 	return results, nil
@@ -1209,7 +1490,7 @@ func (d *DB) SmartGetCF(cf, key string) (string, error) {
 	if !val.Exists() {
 		return "", ErrKeyNotFound
 	}
-	return string(val.Data()), nil
+	return d.valueString(cf, val.Data()), nil
 }
 
 // SmartPrefixScanCF performs prefix scan with automatic key conversion
@@ -1241,7 +1522,7 @@ func (d *DB) SmartPrefixScanCF(cf, prefix string, limit int) (map[string]string,
 			v.Free()
 			break
 		}
-		result[string(k.Data())] = string(v.Data())
+		result[string(k.Data())] = d.valueString(cf, v.Data())
 		k.Free()
 		v.Free()
 		if limit > 0 && len(result) >= limit {
@@ -1406,10 +1687,12 @@ func (d *DB) ScanCFPage(cf string, start, end []byte, opts ScanOptions) (ScanPag
 
 		var valueEncoded string
 		var valueIsBinary bool
+		var ttlInfo *TTLInfo
 
 		if opts.Values {
 			v := it.Value()
-			vData := v.Data()
+			var vData []byte
+			vData, ttlInfo = d.decodeValue(cf, v.Data())
 			valueEncoded, valueIsBinary = util.EncodeValue(vData)
 			result[kStr] = string(vData) // Keep old format for compatibility
 			v.Free()
@@ -1426,6 +1709,7 @@ func (d *DB) ScanCFPage(cf string, start, end []byte, opts ScanOptions) (ScanPag
 			KeyIsBinary:   keyIsBinary,
 			ValueIsBinary: valueIsBinary,
 			Timestamp:     util.ParseTimestamp(keyEncoded),
+			TTL:           ttlInfo,
 		})
 
 		lastKey = kStr
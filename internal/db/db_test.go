@@ -693,6 +693,97 @@ func splitLines(s string) []string {
 	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
 }
 
+// TestDB_ProgressStopAndResume tests that a ProgressFunc error stops export,
+// statistics and search with a cursor to resume from
+func TestDB_ProgressStopAndResume(t *testing.T) {
+	dir := t.TempDir()
+	db, err := Open(filepath.Join(dir, "testdb"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	const total = 2500
+	for i := 0; i < total; i++ {
+		if err := db.PutCF("default", fmt.Sprintf("key%05d", i), "value"); err != nil {
+			t.Fatalf("PutCF failed: %v", err)
+		}
+	}
+	errStop := errors.New("stop")
+	stopAt := func(n int64) ProgressFunc {
+		return func(visited int64, lastKey string) error {
+			if visited >= n {
+				return errStop
+			}
+			return nil
+		}
+	}
+
+	t.Run("export", func(t *testing.T) {
+		csvPath := filepath.Join(dir, "export.csv")
+		result, err := db.ExportToCSVWithOptions("default", csvPath, ExportOptions{Progress: stopAt(progressInterval)})
+		if err != nil {
+			t.Fatalf("ExportToCSVWithOptions failed: %v", err)
+		}
+		if result.Rows != progressInterval || !result.HasMore || result.NextCursor == "" {
+			t.Fatalf("expected a stopped export after %d rows, got %+v", progressInterval, result)
+		}
+
+		resumed, err := db.ExportToCSVWithOptions("default", csvPath, ExportOptions{After: result.NextCursor})
+		if err != nil {
+			t.Fatalf("resumed export failed: %v", err)
+		}
+		if resumed.HasMore || result.Rows+resumed.Rows != total {
+			t.Fatalf("expected the remaining %d rows, got %+v", total-result.Rows, resumed)
+		}
+
+		data, err := os.ReadFile(csvPath)
+		if err != nil {
+			t.Fatalf("ReadFile failed: %v", err)
+		}
+		lines := splitLines(strings.TrimSpace(string(data)))
+		if len(lines) != total+1 {
+			t.Errorf("expected one header and %d rows, got %d lines", total, len(lines))
+		}
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		stats, err := db.GetCFStatsWithOptions("default", StatsOptions{Progress: stopAt(progressInterval)})
+		if err != nil {
+			t.Fatalf("GetCFStatsWithOptions failed: %v", err)
+		}
+		if stats.KeyCount != progressInterval || !stats.HasMore {
+			t.Fatalf("expected partial stats of %d keys, got %d (has_more=%v)", progressInterval, stats.KeyCount, stats.HasMore)
+		}
+
+		rest, err := db.GetCFStatsWithOptions("default", StatsOptions{After: stats.NextCursor})
+		if err != nil {
+			t.Fatalf("resumed stats failed: %v", err)
+		}
+		if stats.KeyCount+rest.KeyCount != total {
+			t.Errorf("expected %d keys in total, got %d", total, stats.KeyCount+rest.KeyCount)
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		results, err := db.SearchCF("default", SearchOptions{KeyPattern: "key", Progress: stopAt(2 * progressInterval)})
+		if err != nil {
+			t.Fatalf("SearchCF failed: %v", err)
+		}
+		if len(results.Results) != 2*progressInterval || !results.HasMore {
+			t.Fatalf("expected %d partial results, got %d (has_more=%v)", 2*progressInterval, len(results.Results), results.HasMore)
+		}
+
+		rest, err := db.SearchCF("default", SearchOptions{KeyPattern: "key", After: results.NextCursor})
+		if err != nil {
+			t.Fatalf("resumed SearchCF failed: %v", err)
+		}
+		if len(rest.Results) != total-2*progressInterval {
+			t.Errorf("expected the remaining %d results, got %d", total-2*progressInterval, len(rest.Results))
+		}
+	})
+}
+
 func TestDB_SearchCF_NumericKeyAfterPagination(t *testing.T) {
 	dir := t.TempDir()
 	dbPath := filepath.Join(dir, "testdb")
@@ -0,0 +1,667 @@
+package db
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/linxGnu/grocksdb"
+)
+
+// ErrUnknownProfile is returned when a column family profile name is not registered
+var ErrUnknownProfile = errors.New("unknown column family profile")
+
+const (
+	optionsSourceFile     = "options-file"
+	optionsSourceDefaults = "defaults"
+	optionsSourceProfile  = "profile"
+
+	// blockCacheSize is the block cache shared by column families loaded from the OPTIONS file
+	blockCacheSize = 64 << 20
+)
+
+// BytewiseComparator is the RocksDB name of the default comparator, which
+// orders keys like bytes.Compare
+const BytewiseComparator = "leveldb.BytewiseComparator"
+
+// RequireBytewise returns ErrNotBytewise if opts of cf name a comparator
+// other than the bytewise one, for reads that seek to prefixes or resume
+// after a key. Nil options, of a column family that could not be read, pass.
+func RequireBytewise(opts *CFOptions, cf string) error {
+	if opts == nil || opts.Comparator == "" || opts.Comparator == BytewiseComparator {
+		return nil
+	}
+	return fmt.Errorf("%w: '%s' uses %s", ErrNotBytewise, cf, opts.Comparator)
+}
+
+// CFOptions describes the options a column family is running with.
+// Source tells where they came from: the OPTIONS file written by the owning
+// application, a named profile used by createcf, or RocksDB defaults.
+type CFOptions struct {
+	Name             string            `json:"name"`
+	Source           string            `json:"source"`
+	Profile          string            `json:"profile,omitempty"`
+	Comparator       string            `json:"comparator"`
+	MergeOperator    string            `json:"merge_operator,omitempty"`
+	PrefixExtractor  string            `json:"prefix_extractor,omitempty"`
+	CompactionFilter string            `json:"compaction_filter,omitempty"`
+	Compression      string            `json:"compression"`
+	WriteBufferSize  uint64            `json:"write_buffer_size"`
+	BlockSize        int               `json:"block_size,omitempty"`
+	FilterPolicy     string            `json:"filter_policy,omitempty"`
+	TTL              uint64            `json:"ttl"`
+	Raw              map[string]string `json:"raw,omitempty"`           // [CFOptions "<name>"] section as written
+	TableOptions     map[string]string `json:"table_options,omitempty"` // [TableOptions/BlockBasedTable "<name>"] section
+}
+
+// OptionMismatch reports a difference between what the OPTIONS file asks for
+// and what the tool was able to open the column family with.
+type OptionMismatch struct {
+	ColumnFamily string `json:"column_family"`
+	Option       string `json:"option"`
+	Stored       string `json:"stored"`
+	Actual       string `json:"actual"`
+	Message      string `json:"message"`
+}
+
+func (m OptionMismatch) String() string {
+	return fmt.Sprintf("[%s] %s: stored=%q actual=%q (%s)", m.ColumnFamily, m.Option, m.Stored, m.Actual, m.Message)
+}
+
+// CFProfile is a named set of tuning options used when creating column families
+type CFProfile struct {
+	Name            string  `json:"name"`
+	Description     string  `json:"description"`
+	Compression     string  `json:"compression,omitempty"`        // none, snappy, zlib, lz4, lz4hc, zstd; empty keeps the default
+	BlockSize       int     `json:"block_size,omitempty"`         // bytes; 0 keeps the default
+	BloomBitsPerKey float64 `json:"bloom_bits_per_key,omitempty"` // 0 disables the bloom filter
+	TTL             uint64  `json:"ttl,omitempty"`                // seconds; files older than this are picked for compaction
+}
+
+var cfProfiles = map[string]CFProfile{
+	"default": {
+		Name:        "default",
+		Description: "RocksDB defaults",
+	},
+	"point-lookup": {
+		Name:            "point-lookup",
+		Description:     "Small blocks and a bloom filter for random gets",
+		Compression:     "lz4",
+		BlockSize:       4 << 10,
+		BloomBitsPerKey: 10,
+	},
+	"scan": {
+		Name:        "scan",
+		Description: "Large zstd blocks for sequential range scans",
+		Compression: "zstd",
+		BlockSize:   64 << 10,
+	},
+	"compact": {
+		Name:            "compact",
+		Description:     "zstd compression and a bloom filter to minimise disk usage",
+		Compression:     "zstd",
+		BlockSize:       16 << 10,
+		BloomBitsPerKey: 10,
+	},
+	"ephemeral": {
+		Name:            "ephemeral",
+		Description:     "lz4 compression with a one-day compaction TTL for short-lived data",
+		Compression:     "lz4",
+		BlockSize:       16 << 10,
+		BloomBitsPerKey: 10,
+		TTL:             24 * 60 * 60,
+	},
+}
+
+// CFProfiles returns all registered column family profiles sorted by name
+func CFProfiles() []CFProfile {
+	profiles := make([]CFProfile, 0, len(cfProfiles))
+	for _, p := range cfProfiles {
+		profiles = append(profiles, p)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles
+}
+
+// GetCFProfile looks up a column family profile by name
+func GetCFProfile(name string) (CFProfile, bool) {
+	p, ok := cfProfiles[name]
+	return p, ok
+}
+
+var compressionTypes = map[string]grocksdb.CompressionType{
+	"none":   grocksdb.NoCompression,
+	"snappy": grocksdb.SnappyCompression,
+	"zlib":   grocksdb.ZLibCompression,
+	"bz2":    grocksdb.Bz2Compression,
+	"lz4":    grocksdb.LZ4Compression,
+	"lz4hc":  grocksdb.LZ4HCCompression,
+	"xpress": grocksdb.XpressCompression,
+	"zstd":   grocksdb.ZSTDCompression,
+}
+
+// compressionNames uses the spelling RocksDB writes into OPTIONS files
+var compressionNames = map[grocksdb.CompressionType]string{
+	grocksdb.NoCompression:     "kNoCompression",
+	grocksdb.SnappyCompression: "kSnappyCompression",
+	grocksdb.ZLibCompression:   "kZlibCompression",
+	grocksdb.Bz2Compression:    "kBZip2Compression",
+	grocksdb.LZ4Compression:    "kLZ4Compression",
+	grocksdb.LZ4HCCompression:  "kLZ4HCCompression",
+	grocksdb.XpressCompression: "kXpressCompression",
+	grocksdb.ZSTDCompression:   "kZSTD",
+}
+
+// newOptions builds RocksDB options for the profile
+func (p CFProfile) newOptions() (*grocksdb.Options, error) {
+	opts := grocksdb.NewDefaultOptions()
+	if p.Compression != "" {
+		ct, ok := compressionTypes[strings.ToLower(p.Compression)]
+		if !ok {
+			opts.Destroy()
+			return nil, fmt.Errorf("unsupported compression %q", p.Compression)
+		}
+		opts.SetCompression(ct)
+	}
+	if p.BlockSize > 0 || p.BloomBitsPerKey > 0 {
+		bbto := grocksdb.NewDefaultBlockBasedTableOptions()
+		if p.BlockSize > 0 {
+			bbto.SetBlockSize(p.BlockSize)
+		}
+		if p.BloomBitsPerKey > 0 {
+			bbto.SetFilterPolicy(grocksdb.NewBloomFilter(p.BloomBitsPerKey))
+		}
+		opts.SetBlockBasedTableFactory(bbto)
+	}
+	if p.TTL > 0 {
+		opts.SetTTL(p.TTL)
+	}
+	return opts, nil
+}
+
+// describe returns the CFOptions a column family created from this profile runs with
+func (p CFProfile) describe(cf string, opts *grocksdb.Options) *CFOptions {
+	desc := describeOptions(cf, optionsSourceProfile, opts)
+	desc.Profile = p.Name
+	desc.BlockSize = p.BlockSize
+	if p.BloomBitsPerKey > 0 {
+		desc.FilterPolicy = fmt.Sprintf("bloomfilter:%g", p.BloomBitsPerKey)
+	}
+	return desc
+}
+
+// describeOptions captures the values that can be read back from a grocksdb.Options
+func describeOptions(cf, source string, opts *grocksdb.Options) *CFOptions {
+	compression, ok := compressionNames[opts.GetCompression()]
+	if !ok {
+		compression = fmt.Sprintf("%d", opts.GetCompression())
+	}
+	return &CFOptions{
+		Name:            cf,
+		Source:          source,
+		Comparator:      BytewiseComparator,
+		Compression:     compression,
+		WriteBufferSize: opts.GetWriteBufferSize(),
+		TTL:             opts.GetTTL(),
+	}
+}
+
+// optionsFile is the parsed content of a RocksDB OPTIONS-NNNNNN file
+type optionsFile struct {
+	Path           string
+	RocksDBVersion string
+	CFOptions      map[string]map[string]string
+	TableOptions   map[string]map[string]string
+}
+
+// latestOptionsFile returns the OPTIONS file with the highest file number in
+// dir, or "" if the database has none
+func latestOptionsFile(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	latest, latestNum := "", int64(-1)
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, "OPTIONS-") || strings.HasSuffix(name, ".dbtmp") {
+			continue
+		}
+		num, err := strconv.ParseInt(strings.TrimPrefix(name, "OPTIONS-"), 10, 64)
+		if err != nil {
+			continue
+		}
+		if num > latestNum {
+			latest, latestNum = filepath.Join(dir, name), num
+		}
+	}
+	return latest, nil
+}
+
+// parseOptionsFile reads the INI-style OPTIONS file RocksDB persists on every open
+func parseOptionsFile(path string) (*optionsFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	of := &optionsFile{
+		Path:         path,
+		CFOptions:    make(map[string]map[string]string),
+		TableOptions: make(map[string]map[string]string),
+	}
+
+	var section, version map[string]string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			kind, name := parseSectionHeader(line[1 : len(line)-1])
+			section = make(map[string]string)
+			switch {
+			case kind == "Version":
+				version = section
+			case kind == "CFOptions":
+				of.CFOptions[name] = section
+			case strings.HasPrefix(kind, "TableOptions/"):
+				section["table_factory"] = strings.TrimPrefix(kind, "TableOptions/")
+				of.TableOptions[name] = section
+			}
+			continue
+		}
+		if section == nil {
+			continue
+		}
+		if k, v, ok := strings.Cut(line, "="); ok {
+			section[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	of.RocksDBVersion = version["rocksdb_version"]
+	return of, nil
+}
+
+// parseSectionHeader splits `CFOptions "users"` into its kind and quoted name
+func parseSectionHeader(header string) (kind, name string) {
+	kind, rest, found := strings.Cut(header, " ")
+	if !found {
+		return header, ""
+	}
+	return kind, strings.Trim(strings.TrimSpace(rest), `"`)
+}
+
+// normalizeOptionValue maps the various spellings of "not set" to "" and
+// unwraps the `{id=Name;...}` form newer RocksDB versions use for customizable objects
+func normalizeOptionValue(v string) string {
+	v = strings.TrimSpace(v)
+	if v == "nullptr" || v == "NULL" || v == "{}" {
+		return ""
+	}
+	if strings.HasPrefix(v, "{") && strings.HasSuffix(v, "}") {
+		for _, part := range strings.Split(strings.Trim(v, "{}"), ";") {
+			if k, val, ok := strings.Cut(part, "="); ok && strings.TrimSpace(k) == "id" {
+				return strings.TrimSpace(val)
+			}
+		}
+	}
+	return v
+}
+
+// describeStored overlays the textual values from the OPTIONS file on top of
+// what could be read back from the loaded grocksdb options
+func (of *optionsFile) describeStored(cf string, base *CFOptions) *CFOptions {
+	raw := of.CFOptions[cf]
+	desc := *base
+	desc.Raw = raw
+	desc.TableOptions = of.TableOptions[cf]
+	if v := normalizeOptionValue(raw["comparator"]); v != "" {
+		desc.Comparator = v
+	}
+	desc.MergeOperator = normalizeOptionValue(raw["merge_operator"])
+	desc.PrefixExtractor = normalizeOptionValue(raw["prefix_extractor"])
+	desc.CompactionFilter = normalizeOptionValue(raw["compaction_filter"])
+	if desc.CompactionFilter == "" {
+		desc.CompactionFilter = normalizeOptionValue(raw["compaction_filter_factory"])
+	}
+	if v := raw["compression"]; v != "" {
+		desc.Compression = v
+	}
+	if t := desc.TableOptions; t != nil {
+		if bs, err := strconv.Atoi(t["block_size"]); err == nil {
+			desc.BlockSize = bs
+		}
+		desc.FilterPolicy = normalizeOptionValue(t["filter_policy"])
+	}
+	return &desc
+}
+
+// Comparators and merge operators RocksDB can instantiate by name from an OPTIONS file
+var (
+	builtinComparators = map[string]bool{
+		BytewiseComparator:                  true,
+		"rocksdb.ReverseBytewiseComparator": true,
+	}
+	builtinMergeOperators = map[string]bool{
+		"PutOperator":              true,
+		"UInt64AddOperator":        true,
+		"StringAppendOperator":     true,
+		"StringAppendTESTOperator": true,
+		"MaxOperator":              true,
+		"BytesXOROperator":         true,
+		"SortList":                 true,
+	}
+)
+
+// compareOptions reports the options of one column family that the tool could
+// not honour. loaded is false when the column family fell back to defaults;
+// applied names the registry plugins installed on it.
+func compareOptions(cf string, stored map[string]string, loaded bool, applied appliedPlugins) []OptionMismatch {
+	var mismatches []OptionMismatch
+	add := func(option, storedVal, actual, msg string) {
+		mismatches = append(mismatches, OptionMismatch{
+			ColumnFamily: cf,
+			Option:       option,
+			Stored:       storedVal,
+			Actual:       actual,
+			Message:      msg,
+		})
+	}
+
+	actualComparator := applied.Comparator
+	if actualComparator == "" {
+		actualComparator = BytewiseComparator
+	}
+	if v := normalizeOptionValue(stored["comparator"]); v != "" && v != actualComparator {
+		if applied.Comparator != "" || !loaded || !builtinComparators[v] {
+			add("comparator", v, actualComparator, "key ordering differs from the owning application; scans and seeks may be wrong")
+		}
+	}
+	if v := normalizeOptionValue(stored["merge_operator"]); v != "" && v != applied.MergeOperator {
+		if applied.MergeOperator != "" || !loaded || !builtinMergeOperators[v] {
+			add("merge_operator", v, applied.MergeOperator, "merge operator is not available; keys with pending merge operands cannot be read")
+		}
+	}
+	for _, option := range []string{"compaction_filter", "compaction_filter_factory"} {
+		if v := normalizeOptionValue(stored[option]); v != "" {
+			add(option, v, "", "custom compaction filters are not run by this tool")
+		}
+	}
+	if !loaded {
+		if v := normalizeOptionValue(stored["prefix_extractor"]); v != "" {
+			add("prefix_extractor", v, "", "prefix bloom filters are not used")
+		}
+		if v := stored["compression"]; v != "" && v != compressionNames[grocksdb.SnappyCompression] {
+			add("compression", v, compressionNames[grocksdb.SnappyCompression], "new data is written with the default compression")
+		}
+	}
+	return mismatches
+}
+
+// loadedOptions holds the per-CF options chosen for opening a database
+type loadedOptions struct {
+	file         string
+	plugins      map[string]CFPluginConfig
+	latest       *grocksdb.LatestOptions
+	env          *grocksdb.Env
+	cache        *grocksdb.Cache
+	dbOpts       *grocksdb.Options
+	cfOpts       []*grocksdb.Options
+	describe     map[string]*CFOptions
+	mergePlugins map[string]*MergeOperatorPlugin
+	mismatches   []OptionMismatch
+}
+
+// loadOptions loads the latest OPTIONS file of the database at path and picks
+// options for every column family in cfNames. Column families without stored
+// options, or all of them when the file cannot be loaded, get RocksDB defaults
+// and a mismatch entry explaining why. Comparators and merge operators from
+// plugins, or matching the stored names, are installed on top.
+func loadOptions(path string, cfNames []string, plugins map[string]CFPluginConfig) (*loadedOptions, error) {
+	lo := &loadedOptions{plugins: plugins}
+
+	file, err := latestOptionsFile(path)
+	if err != nil || file == "" {
+		return lo, lo.useDefaults(cfNames, nil)
+	}
+	lo.file = file
+
+	parsed, err := parseOptionsFile(file)
+	if err != nil {
+		lo.mismatches = append(lo.mismatches, OptionMismatch{
+			ColumnFamily: "*",
+			Option:       "OPTIONS",
+			Stored:       file,
+			Actual:       optionsSourceDefaults,
+			Message:      fmt.Sprintf("failed to read OPTIONS file: %v", err),
+		})
+		return lo, lo.useDefaults(cfNames, nil)
+	}
+
+	lo.env = grocksdb.NewDefaultEnv()
+	lo.cache = grocksdb.NewLRUCache(blockCacheSize)
+	latest, err := grocksdb.LoadLatestOptions(path, lo.env, true, lo.cache)
+	if err != nil {
+		lo.mismatches = append(lo.mismatches, OptionMismatch{
+			ColumnFamily: "*",
+			Option:       "OPTIONS",
+			Stored:       file,
+			Actual:       optionsSourceDefaults,
+			Message:      fmt.Sprintf("failed to load OPTIONS file: %v", err),
+		})
+		return lo, lo.useDefaults(cfNames, parsed)
+	}
+	lo.latest = latest
+
+	byName := make(map[string]*grocksdb.Options)
+	cfOpts := latest.ColumnFamilyOpts()
+	for i, name := range latest.ColumnFamilyNames() {
+		byName[name] = &cfOpts[i]
+	}
+
+	lo.dbOpts = latest.Options()
+	lo.cfOpts = make([]*grocksdb.Options, len(cfNames))
+	lo.describe = make(map[string]*CFOptions)
+	lo.mergePlugins = make(map[string]*MergeOperatorPlugin)
+	for i, name := range cfNames {
+		opts, ok := byName[name]
+		if !ok {
+			lo.cfOpts[i] = grocksdb.NewDefaultOptions()
+			applied, err := lo.install(name, lo.cfOpts[i], nil)
+			if err != nil {
+				return lo, err
+			}
+			lo.describe[name] = withPlugins(describeOptions(name, optionsSourceDefaults, lo.cfOpts[i]), applied)
+			lo.mismatches = append(lo.mismatches, OptionMismatch{
+				ColumnFamily: name,
+				Option:       "CFOptions",
+				Actual:       optionsSourceDefaults,
+				Message:      "column family is missing from the OPTIONS file",
+			})
+			continue
+		}
+
+		lo.cfOpts[i] = opts
+		stored := parsed.CFOptions[name]
+		applied, err := lo.install(name, opts, stored)
+		if err != nil {
+			return lo, err
+		}
+		lo.describe[name] = withPlugins(parsed.describeStored(name, describeOptions(name, optionsSourceFile, opts)), applied)
+		lo.mismatches = append(lo.mismatches, compareOptions(name, stored, true, applied)...)
+	}
+	return lo, nil
+}
+
+// install applies the plugins for one column family and remembers its merge operator
+func (lo *loadedOptions) install(cf string, opts *grocksdb.Options, stored map[string]string) (appliedPlugins, error) {
+	applied, err := applyPlugins(opts, lo.plugins[cf], stored)
+	if err != nil {
+		return applied, fmt.Errorf("column family '%s': %w", cf, err)
+	}
+	if applied.merge != nil {
+		lo.mergePlugins[cf] = applied.merge
+	}
+	return applied, nil
+}
+
+// withPlugins reports the installed plugins instead of the stored names
+func withPlugins(desc *CFOptions, applied appliedPlugins) *CFOptions {
+	if applied.Comparator != "" {
+		desc.Comparator = applied.Comparator
+	}
+	if applied.MergeOperator != "" {
+		desc.MergeOperator = applied.MergeOperator
+	}
+	return desc
+}
+
+// useDefaults opens every column family with RocksDB defaults, reporting any
+// stored option that is lost in the process
+func (lo *loadedOptions) useDefaults(cfNames []string, parsed *optionsFile) error {
+	lo.dbOpts = grocksdb.NewDefaultOptions()
+	lo.cfOpts = make([]*grocksdb.Options, len(cfNames))
+	lo.describe = make(map[string]*CFOptions)
+	lo.mergePlugins = make(map[string]*MergeOperatorPlugin)
+	for i, name := range cfNames {
+		lo.cfOpts[i] = grocksdb.NewDefaultOptions()
+		var stored map[string]string
+		if parsed != nil {
+			stored = parsed.CFOptions[name]
+		}
+		applied, err := lo.install(name, lo.cfOpts[i], stored)
+		if err != nil {
+			return err
+		}
+		desc := withPlugins(describeOptions(name, optionsSourceDefaults, lo.cfOpts[i]), applied)
+		if parsed != nil {
+			desc.Raw = stored
+			desc.TableOptions = parsed.TableOptions[name]
+			lo.mismatches = append(lo.mismatches, compareOptions(name, stored, false, applied)...)
+		}
+		lo.describe[name] = desc
+	}
+	return nil
+}
+
+// fallback discards options loaded from the OPTIONS file after RocksDB refused
+// to open the database with them
+func (lo *loadedOptions) fallback(cfNames []string, openErr error) error {
+	parsed, _ := parseOptionsFile(lo.file)
+	lo.release()
+	lo.mismatches = []OptionMismatch{{
+		ColumnFamily: "*",
+		Option:       "OPTIONS",
+		Stored:       lo.file,
+		Actual:       optionsSourceDefaults,
+		Message:      fmt.Sprintf("failed to open with stored options: %v", openErr),
+	}}
+	return lo.useDefaults(cfNames, parsed)
+}
+
+// fromFile reports whether the options came from a successfully loaded OPTIONS file
+func (lo *loadedOptions) fromFile() bool {
+	return lo.latest != nil
+}
+
+// release frees the loaded OPTIONS file. The env and block cache are kept
+// because the open database still references them.
+func (lo *loadedOptions) release() {
+	if lo.latest != nil {
+		lo.latest.Destroy()
+		lo.latest = nil
+	}
+}
+
+// destroy frees everything, including the env and block cache
+func (lo *loadedOptions) destroy() {
+	lo.release()
+	if lo.cache != nil {
+		lo.cache.Destroy()
+		lo.cache = nil
+	}
+	if lo.env != nil {
+		lo.env.Destroy()
+		lo.env = nil
+	}
+}
+
+// GetCFOptions returns the options a column family is running with
+func (d *DB) GetCFOptions(cf string) (*CFOptions, error) {
+	if _, ok := d.cfHandles[cf]; !ok {
+		return nil, ErrColumnFamilyNotFound
+	}
+	d.optionsMux.RLock()
+	defer d.optionsMux.RUnlock()
+	if desc, ok := d.cfOptions[cf]; ok {
+		return desc, nil
+	}
+	return &CFOptions{Name: cf, Source: optionsSourceDefaults, Comparator: BytewiseComparator}, nil
+}
+
+// OptionsFile returns the OPTIONS file the database was opened with, or "" if none was used
+func (d *DB) OptionsFile() string {
+	return d.optionsFile
+}
+
+// OptionMismatches returns the stored options that could not be applied on open
+func (d *DB) OptionMismatches() []OptionMismatch {
+	d.optionsMux.RLock()
+	defer d.optionsMux.RUnlock()
+	out := make([]OptionMismatch, len(d.mismatches))
+	copy(out, d.mismatches)
+	return out
+}
+
+// CreateCFWithProfile creates a column family tuned with a named profile
+func (d *DB) CreateCFWithProfile(cf, profile string) error {
+	if d.readOnly {
+		return ErrReadOnlyMode
+	}
+	if _, exists := d.cfHandles[cf]; exists {
+		return ErrColumnFamilyExists
+	}
+	p, ok := GetCFProfile(profile)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownProfile, profile)
+	}
+	opts, err := p.newOptions()
+	if err != nil {
+		return err
+	}
+	return d.createCF(cf, opts, func() *CFOptions { return p.describe(cf, opts) })
+}
+
+// createCF creates a column family with opts plus any plugins configured for
+// it, and records how it was configured. opts is not destroyed: a comparator
+// installed on it must outlive the column family.
+func (d *DB) createCF(cf string, opts *grocksdb.Options, describe func() *CFOptions) error {
+	applied, err := applyPlugins(opts, d.plugins[cf], nil)
+	if err != nil {
+		return err
+	}
+	h, err := d.db.CreateColumnFamily(opts, cf)
+	if err != nil {
+		return err
+	}
+	d.cfHandles[cf] = h
+
+	d.optionsMux.Lock()
+	d.cfOptions[cf] = withPlugins(describe(), applied)
+	if applied.merge != nil {
+		d.mergePlugins[cf] = applied.merge
+	}
+	d.optionsMux.Unlock()
+	return nil
+}
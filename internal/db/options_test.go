@@ -0,0 +1,230 @@
+package db
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const sampleOptionsFile = `# This is a RocksDB option file.
+[Version]
+  rocksdb_version=8.11.3
+  options_file_version=1.1
+
+[DBOptions]
+  create_if_missing=true
+
+[CFOptions "default"]
+  comparator=leveldb.BytewiseComparator
+  merge_operator=nullptr
+  compression=kSnappyCompression
+  prefix_extractor=nullptr
+  ttl=2592000
+
+[TableOptions/BlockBasedTable "default"]
+  block_size=4096
+  filter_policy=nullptr
+
+[CFOptions "counters"]
+  comparator=my.Uint64Comparator
+  merge_operator={id=MyCounterMerge;}
+  compression=kZSTD
+  prefix_extractor=rocksdb.FixedPrefix.8
+
+[TableOptions/BlockBasedTable "counters"]
+  block_size=16384
+  filter_policy=bloomfilter:10:false
+`
+
+func writeOptionsFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	return path
+}
+
+func TestLatestOptionsFile(t *testing.T) {
+	dir := t.TempDir()
+
+	got, err := latestOptionsFile(dir)
+	if err != nil {
+		t.Fatalf("latestOptionsFile failed: %v", err)
+	}
+	if got != "" {
+		t.Errorf("latestOptionsFile() = %q, want empty for a directory without OPTIONS files", got)
+	}
+
+	writeOptionsFile(t, dir, "OPTIONS-000007", "")
+	want := writeOptionsFile(t, dir, "OPTIONS-000012", "")
+	writeOptionsFile(t, dir, "OPTIONS-000013.dbtmp", "")
+	writeOptionsFile(t, dir, "OPTIONS-backup", "")
+
+	got, err = latestOptionsFile(dir)
+	if err != nil {
+		t.Fatalf("latestOptionsFile failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("latestOptionsFile() = %q, want %q", got, want)
+	}
+}
+
+func TestParseOptionsFile(t *testing.T) {
+	path := writeOptionsFile(t, t.TempDir(), "OPTIONS-000005", sampleOptionsFile)
+
+	of, err := parseOptionsFile(path)
+	if err != nil {
+		t.Fatalf("parseOptionsFile failed: %v", err)
+	}
+	if of.RocksDBVersion != "8.11.3" {
+		t.Errorf("RocksDBVersion = %q, want 8.11.3", of.RocksDBVersion)
+	}
+	if len(of.CFOptions) != 2 {
+		t.Fatalf("expected 2 CFOptions sections, got %d", len(of.CFOptions))
+	}
+	if got := of.CFOptions["counters"]["merge_operator"]; got != "{id=MyCounterMerge;}" {
+		t.Errorf("counters merge_operator = %q", got)
+	}
+	if got := of.TableOptions["counters"]["table_factory"]; got != "BlockBasedTable" {
+		t.Errorf("counters table_factory = %q, want BlockBasedTable", got)
+	}
+
+	desc := of.describeStored("counters", &CFOptions{Name: "counters", Source: optionsSourceFile, Comparator: BytewiseComparator})
+	if desc.Comparator != "my.Uint64Comparator" {
+		t.Errorf("Comparator = %q", desc.Comparator)
+	}
+	if desc.MergeOperator != "MyCounterMerge" {
+		t.Errorf("MergeOperator = %q, want MyCounterMerge", desc.MergeOperator)
+	}
+	if desc.BlockSize != 16384 {
+		t.Errorf("BlockSize = %d, want 16384", desc.BlockSize)
+	}
+	if desc.FilterPolicy != "bloomfilter:10:false" {
+		t.Errorf("FilterPolicy = %q", desc.FilterPolicy)
+	}
+}
+
+func TestCompareOptions(t *testing.T) {
+	of, err := parseOptionsFile(writeOptionsFile(t, t.TempDir(), "OPTIONS-000005", sampleOptionsFile))
+	if err != nil {
+		t.Fatalf("parseOptionsFile failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		cf      string
+		loaded  bool
+		applied appliedPlugins
+		options []string
+	}{
+		{name: "default options loaded", cf: "default", loaded: true},
+		{name: "default options on defaults", cf: "default", loaded: false},
+		{name: "custom options loaded", cf: "counters", loaded: true, options: []string{"comparator", "merge_operator"}},
+		{name: "custom options on defaults", cf: "counters", loaded: false, options: []string{"comparator", "merge_operator", "prefix_extractor", "compression"}},
+		{
+			name:    "custom options satisfied by plugins",
+			cf:      "counters",
+			loaded:  true,
+			applied: appliedPlugins{Comparator: "my.Uint64Comparator", MergeOperator: "MyCounterMerge"},
+		},
+		{
+			name:    "plugin with a different name",
+			cf:      "counters",
+			loaded:  true,
+			applied: appliedPlugins{Comparator: "rocksdb.Uint64Comparator"},
+			options: []string{"comparator", "merge_operator"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mismatches := compareOptions(tt.cf, of.CFOptions[tt.cf], tt.loaded, tt.applied)
+			if len(mismatches) != len(tt.options) {
+				t.Fatalf("expected %d mismatches, got %v", len(tt.options), mismatches)
+			}
+			for i, m := range mismatches {
+				if m.Option != tt.options[i] || m.ColumnFamily != tt.cf {
+					t.Errorf("mismatch %d = %+v, want option %q", i, m, tt.options[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRequireBytewise(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *CFOptions
+		ok   bool
+	}{
+		{name: "bytewise", opts: &CFOptions{Comparator: BytewiseComparator}, ok: true},
+		{name: "unknown comparator", opts: &CFOptions{}, ok: true},
+		{name: "unreadable options", opts: nil, ok: true},
+		{name: "reverse bytewise", opts: &CFOptions{Comparator: "rocksdb.ReverseBytewiseComparator"}},
+		{name: "uint64", opts: &CFOptions{Comparator: "rocksdb.Uint64Comparator"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RequireBytewise(tt.opts, "events")
+			if tt.ok && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrNotBytewise) {
+				t.Errorf("expected ErrNotBytewise, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDB_CFOptions(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "testdb")
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := db.CreateCFWithProfile("events", "ephemeral"); err != nil {
+		t.Fatalf("CreateCFWithProfile failed: %v", err)
+	}
+	if err := db.CreateCFWithProfile("other", "bogus"); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+
+	opts, err := db.GetCFOptions("events")
+	if err != nil {
+		t.Fatalf("GetCFOptions failed: %v", err)
+	}
+	if opts.Source != optionsSourceProfile || opts.Profile != "ephemeral" || opts.TTL != 24*60*60 {
+		t.Errorf("unexpected options for profile CF: %+v", opts)
+	}
+	db.Close()
+
+	// Reopen: options now come from the OPTIONS file RocksDB wrote
+	db, err = OpenReadOnly(dbPath)
+	if err != nil {
+		t.Fatalf("OpenReadOnly failed: %v", err)
+	}
+	defer db.Close()
+
+	if db.OptionsFile() == "" {
+		t.Error("expected an OPTIONS file to be loaded")
+	}
+	if m := db.OptionMismatches(); len(m) != 0 {
+		t.Errorf("expected no mismatches, got %v", m)
+	}
+	opts, err = db.GetCFOptions("events")
+	if err != nil {
+		t.Fatalf("GetCFOptions failed: %v", err)
+	}
+	if opts.Source != optionsSourceFile {
+		t.Errorf("Source = %q, want %q", opts.Source, optionsSourceFile)
+	}
+	if opts.TTL != 24*60*60 {
+		t.Errorf("TTL = %d, want %d", opts.TTL, 24*60*60)
+	}
+	if _, err := db.GetCFOptions("missing"); err != ErrColumnFamilyNotFound {
+		t.Errorf("expected ErrColumnFamilyNotFound, got %v", err)
+	}
+}
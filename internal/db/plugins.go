@@ -0,0 +1,403 @@
+package db
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/linxGnu/grocksdb"
+)
+
+// ErrNoMergeOperator is returned when merging into a column family without a merge operator
+var ErrNoMergeOperator = errors.New("column family has no merge operator")
+
+// CFPluginConfig selects the comparator and merge operator for one column family.
+// Comparator and MergeOperator are registry names such as "reverse-bytewise" or
+// "uint64add". ComparatorName and MergeOperatorName override the name reported
+// to RocksDB, which must match the name the owning application used.
+type CFPluginConfig struct {
+	Comparator        string `yaml:"comparator,omitempty" json:"comparator,omitempty"`
+	ComparatorName    string `yaml:"comparator_name,omitempty" json:"comparator_name,omitempty"`
+	MergeOperator     string `yaml:"merge_operator,omitempty" json:"merge_operator,omitempty"`
+	MergeOperatorName string `yaml:"merge_operator_name,omitempty" json:"merge_operator_name,omitempty"`
+	Delimiter         string `yaml:"delimiter,omitempty" json:"delimiter,omitempty"` // string append delimiter, default ","
+}
+
+// ComparatorPlugin is a comparator available by name in CF config
+type ComparatorPlugin struct {
+	Name        string // registry name used in config
+	RocksDBName string // default name reported to RocksDB
+	Description string
+	Compare     func(a, b []byte) int // nil for comparators built into RocksDB
+}
+
+// MergeOperatorPlugin is a merge operator available by name in CF config
+type MergeOperatorPlugin struct {
+	Name        string // registry name used in config
+	RocksDBName string // default name reported to RocksDB
+	Description string
+	// New builds the operator; rocksdbName is the name to report to RocksDB
+	New func(rocksdbName string, cfg CFPluginConfig) grocksdb.MergeOperator
+	// EncodeOperand converts user input into a merge operand. Nil means the
+	// input is used as-is.
+	EncodeOperand func(input string) ([]byte, error)
+}
+
+var (
+	pluginsMux     sync.RWMutex
+	comparators    = make(map[string]ComparatorPlugin)
+	mergeOperators = make(map[string]MergeOperatorPlugin)
+)
+
+// RegisterComparator adds a comparator to the registry, replacing any with the same name
+func RegisterComparator(p ComparatorPlugin) {
+	pluginsMux.Lock()
+	defer pluginsMux.Unlock()
+	comparators[p.Name] = p
+}
+
+// RegisterMergeOperator adds a merge operator to the registry, replacing any with the same name
+func RegisterMergeOperator(p MergeOperatorPlugin) {
+	pluginsMux.Lock()
+	defer pluginsMux.Unlock()
+	mergeOperators[p.Name] = p
+}
+
+// Comparators returns all registered comparators sorted by name
+func Comparators() []ComparatorPlugin {
+	pluginsMux.RLock()
+	defer pluginsMux.RUnlock()
+	out := make([]ComparatorPlugin, 0, len(comparators))
+	for _, p := range comparators {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// MergeOperators returns all registered merge operators sorted by name
+func MergeOperators() []MergeOperatorPlugin {
+	pluginsMux.RLock()
+	defer pluginsMux.RUnlock()
+	out := make([]MergeOperatorPlugin, 0, len(mergeOperators))
+	for _, p := range mergeOperators {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// findComparator looks a comparator up by registry name, or by RocksDB name
+// when name is what an OPTIONS file recorded
+func findComparator(name string) (ComparatorPlugin, bool) {
+	pluginsMux.RLock()
+	defer pluginsMux.RUnlock()
+	if p, ok := comparators[name]; ok {
+		return p, true
+	}
+	for _, p := range comparators {
+		if p.RocksDBName == name {
+			return p, true
+		}
+	}
+	return ComparatorPlugin{}, false
+}
+
+// findMergeOperator looks a merge operator up by registry name or RocksDB name
+func findMergeOperator(name string) (MergeOperatorPlugin, bool) {
+	pluginsMux.RLock()
+	defer pluginsMux.RUnlock()
+	if p, ok := mergeOperators[name]; ok {
+		return p, true
+	}
+	for _, p := range mergeOperators {
+		if p.RocksDBName == name {
+			return p, true
+		}
+	}
+	return MergeOperatorPlugin{}, false
+}
+
+// appliedPlugins records the RocksDB names of the plugins installed on a column family
+type appliedPlugins struct {
+	Comparator    string
+	MergeOperator string
+	merge         *MergeOperatorPlugin
+}
+
+// applyPlugins installs the configured comparator and merge operator on opts.
+// Without explicit config, plugins are picked by the names stored in the
+// OPTIONS file so databases written with a registered plugin open as-is.
+func applyPlugins(opts *grocksdb.Options, cfg CFPluginConfig, stored map[string]string) (appliedPlugins, error) {
+	var applied appliedPlugins
+
+	cmpName := cfg.Comparator
+	if cmpName == "" {
+		cmpName = normalizeOptionValue(stored["comparator"])
+	}
+	if cmpName != "" {
+		p, ok := findComparator(cmpName)
+		switch {
+		case ok && p.Compare != nil:
+			name := p.RocksDBName
+			if cfg.ComparatorName != "" {
+				name = cfg.ComparatorName
+			}
+			opts.SetComparator(grocksdb.NewComparator(name, p.Compare))
+			applied.Comparator = name
+		case ok:
+			// Built into RocksDB, nothing to install
+			applied.Comparator = p.RocksDBName
+		case cfg.Comparator != "":
+			return applied, fmt.Errorf("unknown comparator %q", cfg.Comparator)
+		}
+	}
+
+	mergeName := cfg.MergeOperator
+	if mergeName == "" {
+		mergeName = normalizeOptionValue(stored["merge_operator"])
+	}
+	if mergeName != "" {
+		p, ok := findMergeOperator(mergeName)
+		switch {
+		case ok:
+			name := p.RocksDBName
+			if cfg.MergeOperatorName != "" {
+				name = cfg.MergeOperatorName
+			}
+			opts.SetMergeOperator(p.New(name, cfg))
+			applied.MergeOperator = name
+			applied.merge = &p
+		case cfg.MergeOperator != "":
+			return applied, fmt.Errorf("unknown merge operator %q", cfg.MergeOperator)
+		}
+	}
+	return applied, nil
+}
+
+func init() {
+	RegisterComparator(ComparatorPlugin{
+		Name:        "bytewise",
+		RocksDBName: BytewiseComparator,
+		Description: "Lexicographic byte order (RocksDB default)",
+	})
+	RegisterComparator(ComparatorPlugin{
+		Name:        "reverse-bytewise",
+		RocksDBName: "rocksdb.ReverseBytewiseComparator",
+		Description: "Reverse lexicographic byte order",
+		Compare:     func(a, b []byte) int { return bytes.Compare(b, a) },
+	})
+	RegisterComparator(ComparatorPlugin{
+		Name:        "uint64",
+		RocksDBName: "rocksdb.Uint64Comparator",
+		Description: "8-byte little-endian unsigned integers; other keys sort after them bytewise",
+		Compare:     compareUint64Keys,
+	})
+
+	RegisterMergeOperator(MergeOperatorPlugin{
+		Name:          "uint64add",
+		RocksDBName:   "UInt64AddOperator",
+		Description:   "Adds 8-byte little-endian counters (operand: decimal integer)",
+		New:           func(name string, _ CFPluginConfig) grocksdb.MergeOperator { return &uint64AddOperator{name: name} },
+		EncodeOperand: encodeUint64Operand,
+	})
+	RegisterMergeOperator(MergeOperatorPlugin{
+		Name:        "stringappend",
+		RocksDBName: "StringAppendOperator",
+		Description: "Appends operands separated by a delimiter (default \",\")",
+		New: func(name string, cfg CFPluginConfig) grocksdb.MergeOperator {
+			delim := cfg.Delimiter
+			if delim == "" {
+				delim = ","
+			}
+			return &stringAppendOperator{name: name, delimiter: []byte(delim)}
+		},
+	})
+	RegisterMergeOperator(MergeOperatorPlugin{
+		Name:        "jsonmerge",
+		RocksDBName: "rocksdb-cli.JSONMergePatch",
+		Description: "Applies operands as RFC 7396 JSON merge patches",
+		New:         func(name string, _ CFPluginConfig) grocksdb.MergeOperator { return &jsonMergePatchOperator{name: name} },
+		EncodeOperand: func(input string) ([]byte, error) {
+			if !json.Valid([]byte(input)) {
+				return nil, fmt.Errorf("merge patch must be valid JSON")
+			}
+			return []byte(input), nil
+		},
+	})
+}
+
+// compareUint64Keys orders 8-byte keys numerically as little-endian uint64
+// and places all other keys after them in bytewise order
+func compareUint64Keys(a, b []byte) int {
+	switch {
+	case len(a) == 8 && len(b) == 8:
+		x, y := binary.LittleEndian.Uint64(a), binary.LittleEndian.Uint64(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case len(a) == 8:
+		return -1
+	case len(b) == 8:
+		return 1
+	}
+	return bytes.Compare(a, b)
+}
+
+// encodeUint64Operand accepts signed values so counters can be decremented
+func encodeUint64Operand(input string) ([]byte, error) {
+	var v uint64
+	if n, err := strconv.ParseInt(input, 10, 64); err == nil {
+		v = uint64(n)
+	} else if u, err := strconv.ParseUint(input, 10, 64); err == nil {
+		v = u
+	} else {
+		return nil, fmt.Errorf("uint64add operand must be an integer: %q", input)
+	}
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, v)
+	return buf, nil
+}
+
+// uint64AddOperator matches RocksDB's UInt64AddOperator: values that are not
+// 8 bytes long count as zero
+type uint64AddOperator struct {
+	name string
+}
+
+func (o *uint64AddOperator) Name() string { return o.name }
+
+func (o *uint64AddOperator) FullMerge(key, existingValue []byte, operands [][]byte) ([]byte, bool) {
+	sum := decodeUint64(existingValue)
+	for _, op := range operands {
+		sum += decodeUint64(op)
+	}
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, sum)
+	return buf, true
+}
+
+func (o *uint64AddOperator) PartialMerge(key, leftOperand, rightOperand []byte) ([]byte, bool) {
+	return o.FullMerge(key, leftOperand, [][]byte{rightOperand})
+}
+
+func decodeUint64(b []byte) uint64 {
+	if len(b) != 8 {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+// stringAppendOperator matches RocksDB's StringAppendOperator
+type stringAppendOperator struct {
+	name      string
+	delimiter []byte
+}
+
+func (o *stringAppendOperator) Name() string { return o.name }
+
+func (o *stringAppendOperator) FullMerge(key, existingValue []byte, operands [][]byte) ([]byte, bool) {
+	parts := make([][]byte, 0, len(operands)+1)
+	if existingValue != nil {
+		parts = append(parts, existingValue)
+	}
+	parts = append(parts, operands...)
+	return bytes.Join(parts, o.delimiter), true
+}
+
+func (o *stringAppendOperator) PartialMerge(key, leftOperand, rightOperand []byte) ([]byte, bool) {
+	return bytes.Join([][]byte{leftOperand, rightOperand}, o.delimiter), true
+}
+
+// jsonMergePatchOperator applies each operand to the existing document as an
+// RFC 7396 merge patch
+type jsonMergePatchOperator struct {
+	name string
+}
+
+func (o *jsonMergePatchOperator) Name() string { return o.name }
+
+func (o *jsonMergePatchOperator) FullMerge(key, existingValue []byte, operands [][]byte) ([]byte, bool) {
+	var doc interface{}
+	if len(existingValue) > 0 {
+		if err := json.Unmarshal(existingValue, &doc); err != nil {
+			return nil, false
+		}
+	}
+	for _, op := range operands {
+		var patch interface{}
+		if err := json.Unmarshal(op, &patch); err != nil {
+			return nil, false
+		}
+		doc = mergePatch(doc, patch)
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+// mergePatch implements the MergePatch algorithm from RFC 7396 section 2
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+	for k, v := range patchObj {
+		if v == nil {
+			delete(targetObj, k)
+			continue
+		}
+		targetObj[k] = mergePatch(targetObj[k], v)
+	}
+	return targetObj
+}
+
+// MergeCF issues a merge operation. The operand is encoded by the column
+// family's merge operator, e.g. "5" becomes an 8-byte counter for uint64add.
+func (d *DB) MergeCF(cf, key, operand string) error {
+	if d.readOnly {
+		return ErrReadOnlyMode
+	}
+	h, ok := d.cfHandles[cf]
+	if !ok {
+		return ErrColumnFamilyNotFound
+	}
+	if d.ttl != nil {
+		// DBWithTTL timestamps every operand and strips them in its own
+		// merge operator wrapper, which plugins here do not replicate
+		return ErrTTLNotSupported
+	}
+
+	d.optionsMux.RLock()
+	merge, hasMerge := d.mergePlugins[cf]
+	desc := d.cfOptions[cf]
+	d.optionsMux.RUnlock()
+	if !hasMerge && (desc == nil || desc.MergeOperator == "") {
+		return ErrNoMergeOperator
+	}
+
+	value := []byte(operand)
+	if hasMerge && merge.EncodeOperand != nil {
+		encoded, err := merge.EncodeOperand(operand)
+		if err != nil {
+			return err
+		}
+		value = encoded
+	}
+	return d.db.MergeCF(d.wo, h, []byte(key), value)
+}
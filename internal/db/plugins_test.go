@@ -0,0 +1,183 @@
+package db
+
+import (
+	"encoding/binary"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"rocksdb-cli/internal/keytree"
+)
+
+func uint64Bytes(v uint64) []byte {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, v)
+	return buf
+}
+
+func TestMergeOperators(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   string
+		cfg      CFPluginConfig
+		existing []byte
+		operands [][]byte
+		want     string
+	}{
+		{
+			name:     "uint64 add without existing value",
+			plugin:   "uint64add",
+			operands: [][]byte{uint64Bytes(2), uint64Bytes(3)},
+			want:     string(uint64Bytes(5)),
+		},
+		{
+			name:     "uint64 add treats malformed values as zero",
+			plugin:   "uint64add",
+			existing: []byte("bad"),
+			operands: [][]byte{uint64Bytes(7)},
+			want:     string(uint64Bytes(7)),
+		},
+		{
+			name:     "string append default delimiter",
+			plugin:   "stringappend",
+			existing: []byte("a"),
+			operands: [][]byte{[]byte("b"), []byte("c")},
+			want:     "a,b,c",
+		},
+		{
+			name:     "string append custom delimiter",
+			plugin:   "stringappend",
+			cfg:      CFPluginConfig{Delimiter: "|"},
+			operands: [][]byte{[]byte("x"), []byte("y")},
+			want:     "x|y",
+		},
+		{
+			name:     "json merge patch",
+			plugin:   "jsonmerge",
+			existing: []byte(`{"name":"alice","tags":["a"],"address":{"city":"x","zip":"1"}}`),
+			operands: [][]byte{[]byte(`{"tags":["b"],"address":{"zip":null}}`), []byte(`{"age":3}`)},
+			want:     `{"address":{"city":"x"},"age":3,"name":"alice","tags":["b"]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := findMergeOperator(tt.plugin)
+			if !ok {
+				t.Fatalf("merge operator %q not registered", tt.plugin)
+			}
+			op := p.New(p.RocksDBName, tt.cfg)
+			got, ok := op.FullMerge([]byte("k"), tt.existing, tt.operands)
+			if !ok {
+				t.Fatal("FullMerge failed")
+			}
+			if string(got) != tt.want {
+				t.Errorf("FullMerge() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindPluginByRocksDBName(t *testing.T) {
+	if p, ok := findComparator("rocksdb.ReverseBytewiseComparator"); !ok || p.Name != "reverse-bytewise" {
+		t.Errorf("findComparator by RocksDB name = %+v, %v", p, ok)
+	}
+	if p, ok := findMergeOperator("UInt64AddOperator"); !ok || p.Name != "uint64add" {
+		t.Errorf("findMergeOperator by RocksDB name = %+v, %v", p, ok)
+	}
+	if _, ok := findMergeOperator("nope"); ok {
+		t.Error("expected unknown merge operator")
+	}
+}
+
+func TestCompareUint64Keys(t *testing.T) {
+	keys := [][]byte{uint64Bytes(1), uint64Bytes(256), []byte("a"), []byte("b")}
+	for i := 0; i < len(keys)-1; i++ {
+		if compareUint64Keys(keys[i], keys[i+1]) >= 0 {
+			t.Errorf("expected %x < %x", keys[i], keys[i+1])
+		}
+		if compareUint64Keys(keys[i+1], keys[i]) <= 0 {
+			t.Errorf("expected %x > %x", keys[i+1], keys[i])
+		}
+	}
+}
+
+func TestDB_MergeCF(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "testdb")
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := db.CreateCF("counters"); err != nil {
+		t.Fatalf("CreateCF failed: %v", err)
+	}
+	if err := db.MergeCF("counters", "hits", "1"); !errors.Is(err, ErrNoMergeOperator) {
+		t.Errorf("expected ErrNoMergeOperator, got %v", err)
+	}
+	db.Close()
+
+	plugins := map[string]CFPluginConfig{
+		"counters": {MergeOperator: "uint64add"},
+		"reversed": {Comparator: "reverse-bytewise"},
+	}
+	db, err = OpenWithPlugins(dbPath, false, plugins)
+	if err != nil {
+		t.Fatalf("OpenWithPlugins failed: %v", err)
+	}
+	defer db.Close()
+
+	for _, v := range []string{"5", "10", "-3"} {
+		if err := db.MergeCF("counters", "hits", v); err != nil {
+			t.Fatalf("MergeCF(%s) failed: %v", v, err)
+		}
+	}
+	if err := db.MergeCF("counters", "hits", "abc"); err == nil {
+		t.Error("expected error for non-integer operand")
+	}
+	got, err := db.GetCF("counters", "hits")
+	if err != nil {
+		t.Fatalf("GetCF failed: %v", err)
+	}
+	if got != string(uint64Bytes(12)) {
+		t.Errorf("merged counter = %x, want %x", got, uint64Bytes(12))
+	}
+
+	// Column families created later pick up their configured comparator
+	if err := db.CreateCF("reversed"); err != nil {
+		t.Fatalf("CreateCF failed: %v", err)
+	}
+	opts, err := db.GetCFOptions("reversed")
+	if err != nil {
+		t.Fatalf("GetCFOptions failed: %v", err)
+	}
+	if opts.Comparator != "rocksdb.ReverseBytewiseComparator" {
+		t.Errorf("Comparator = %q, want rocksdb.ReverseBytewiseComparator", opts.Comparator)
+	}
+	for _, k := range []string{"a", "b", "c"} {
+		if err := db.PutCF("reversed", k, k); err != nil {
+			t.Fatalf("PutCF failed: %v", err)
+		}
+	}
+	key, _, err := db.GetLastCF("reversed")
+	if err != nil {
+		t.Fatalf("GetLastCF failed: %v", err)
+	}
+	if key != "a" {
+		t.Errorf("last key with reverse comparator = %q, want a", key)
+	}
+	if _, err := db.GetKeyTree("reversed", keytree.Options{}); !errors.Is(err, ErrNotBytewise) {
+		t.Errorf("GetKeyTree with reverse comparator: expected ErrNotBytewise, got %v", err)
+	}
+	if _, err := db.GetCFStatsWithOptions("reversed", StatsOptions{Prefix: "a"}); !errors.Is(err, ErrNotBytewise) {
+		t.Errorf("GetCFStatsWithOptions by prefix with reverse comparator: expected ErrNotBytewise, got %v", err)
+	}
+	if stats, err := db.GetCFStatsWithOptions("reversed", StatsOptions{}); err != nil || stats.KeyCount != 3 {
+		t.Errorf("GetCFStatsWithOptions without prefix with reverse comparator = %v, %v; want 3 keys", stats, err)
+	}
+
+	if _, err := OpenWithPlugins(filepath.Join(t.TempDir(), "other"), false, map[string]CFPluginConfig{
+		"default": {MergeOperator: "bogus"},
+	}); err == nil {
+		t.Error("expected error for unknown merge operator")
+	}
+}
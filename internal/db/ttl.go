@@ -0,0 +1,317 @@
+package db
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"rocksdb-cli/internal/util"
+)
+
+// DBWithTTL appends the write time to every value as a little-endian int32
+// of seconds since the epoch. Values are stale once write time + TTL has
+// passed; they stay readable until a compaction drops them.
+const (
+	ttlTimestampSize = 4
+	ttlMinTimestamp  = 1368146402 // rocksdb kMinTimestamp, anything older is not a timestamp
+	ttlMaxTimestamp  = math.MaxInt32
+)
+
+var (
+	ErrTTLModeDisabled = errors.New("database is not opened in TTL mode")
+	ErrTTLNotSupported = errors.New("operation not supported in TTL mode")
+)
+
+// TTLConfig enables TTL mode and holds the TTL each column family was
+// opened with in DBWithTTL. A zero duration means values never expire.
+type TTLConfig struct {
+	Default        time.Duration
+	ColumnFamilies map[string]time.Duration
+}
+
+// ParseTTLSpec parses a TTL mode spec such as "24h" or "1h,events=30m,logs=604800".
+// Entries without a column family set the default; durations are Go
+// durations or plain seconds. An empty spec disables TTL mode.
+func ParseTTLSpec(spec string) (*TTLConfig, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	cfg := &TTLConfig{ColumnFamilies: make(map[string]time.Duration)}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		cf, value, hasCF := strings.Cut(entry, "=")
+		if !hasCF {
+			value = cf
+		}
+		d, err := ParseTTLDuration(value)
+		if err != nil {
+			return nil, err
+		}
+		if hasCF {
+			cfg.ColumnFamilies[strings.TrimSpace(cf)] = d
+		} else {
+			cfg.Default = d
+		}
+	}
+	return cfg, nil
+}
+
+// ParseTTLDuration parses a Go duration ("90m") or a number of seconds ("3600")
+func ParseTTLDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if secs < 0 {
+			return 0, fmt.Errorf("invalid TTL %q: must not be negative", s)
+		}
+		return time.Duration(secs) * time.Second, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid TTL %q: %w", s, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid TTL %q: must not be negative", s)
+	}
+	return d, nil
+}
+
+// TTLFor returns the TTL of a column family
+func (c *TTLConfig) TTLFor(cf string) time.Duration {
+	if d, ok := c.ColumnFamilies[cf]; ok {
+		return d
+	}
+	return c.Default
+}
+
+// TTLInfo is the decoded write timestamp of a TTL mode value
+type TTLInfo struct {
+	WriteTime time.Time  `json:"write_time"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // nil if the column family has no TTL
+	Expired   bool       `json:"expired"`
+}
+
+// String formats the expiry for display, e.g. "expires 2024-05-01T10:00:00Z (in 2h0m0s)"
+func (t *TTLInfo) String() string {
+	if t.ExpiresAt == nil {
+		return "never expires"
+	}
+	at := t.ExpiresAt.UTC().Format(time.RFC3339)
+	if t.Expired {
+		return fmt.Sprintf("expired %s (%s ago, pending compaction)", at, time.Since(*t.ExpiresAt).Truncate(time.Second))
+	}
+	return fmt.Sprintf("expires %s (in %s)", at, time.Until(*t.ExpiresAt).Truncate(time.Second))
+}
+
+func decodeTTLTimestamp(raw []byte) (int64, bool) {
+	if len(raw) < ttlTimestampSize {
+		return 0, false
+	}
+	ts := int64(int32(binary.LittleEndian.Uint32(raw[len(raw)-ttlTimestampSize:])))
+	return ts, ts >= ttlMinTimestamp
+}
+
+func newTTLInfo(ts int64, ttl time.Duration, now time.Time) *TTLInfo {
+	info := &TTLInfo{WriteTime: time.Unix(ts, 0).UTC()}
+	if ttl > 0 {
+		expires := info.WriteTime.Add(ttl)
+		info.ExpiresAt = &expires
+		info.Expired = expires.Before(now)
+	}
+	return info
+}
+
+// decodeValue strips the TTL timestamp from a raw value. Outside TTL mode,
+// or when the value carries no valid timestamp, the value is returned as is
+// with nil info.
+func (d *DB) decodeValue(cf string, raw []byte) ([]byte, *TTLInfo) {
+	if d.ttl == nil {
+		return raw, nil
+	}
+	ts, ok := decodeTTLTimestamp(raw)
+	if !ok {
+		return raw, nil
+	}
+	return raw[:len(raw)-ttlTimestampSize], newTTLInfo(ts, d.ttl.TTLFor(cf), time.Now())
+}
+
+// valueString is decodeValue for callers that only need the value
+func (d *DB) valueString(cf string, raw []byte) string {
+	v, _ := d.decodeValue(cf, raw)
+	return string(v)
+}
+
+// encodeValue appends the TTL timestamp in TTL mode. A positive ttl makes
+// the value expire ttl from now instead of after the column family TTL, by
+// shifting the stored write time.
+func (d *DB) encodeValue(cf, value string, ttl time.Duration) ([]byte, error) {
+	if d.ttl == nil {
+		if ttl > 0 {
+			return nil, ErrTTLModeDisabled
+		}
+		return []byte(value), nil
+	}
+	ts := time.Now().Unix()
+	if ttl > 0 {
+		cfTTL := d.ttl.TTLFor(cf)
+		if cfTTL <= 0 {
+			return nil, fmt.Errorf("column family '%s' has no TTL, values never expire", cf)
+		}
+		ts += int64((ttl - cfTTL) / time.Second)
+	}
+	if ts < ttlMinTimestamp || ts > ttlMaxTimestamp {
+		return nil, fmt.Errorf("TTL %s is out of range for column family '%s'", ttl, cf)
+	}
+	buf := make([]byte, len(value)+ttlTimestampSize)
+	copy(buf, value)
+	binary.LittleEndian.PutUint32(buf[len(value):], uint32(ts))
+	return buf, nil
+}
+
+// PutCFWithTTL writes a value that expires ttl from now. ttl 0 uses the
+// column family TTL, as PutCF does.
+func (d *DB) PutCFWithTTL(cf, key, value string, ttl time.Duration) error {
+	if d.readOnly {
+		return ErrReadOnlyMode
+	}
+	h, ok := d.cfHandles[cf]
+	if !ok {
+		return ErrColumnFamilyNotFound
+	}
+	data, err := d.encodeValue(cf, value, ttl)
+	if err != nil {
+		return err
+	}
+	return d.db.PutCF(d.wo, h, []byte(key), data)
+}
+
+// GetCFWithTTL is SmartGetCF that also returns the decoded expiry. The info
+// is nil outside TTL mode.
+func (d *DB) GetCFWithTTL(cf, key string) (string, *TTLInfo, error) {
+	binaryKey, err := util.ConvertStringToKey(key, d.getKeyFormat(cf))
+	if err != nil {
+		binaryKey = []byte(key)
+	}
+	h, ok := d.cfHandles[cf]
+	if !ok {
+		return "", nil, ErrColumnFamilyNotFound
+	}
+	val, err := d.db.GetCF(d.ro, h, binaryKey)
+	if err != nil {
+		return "", nil, err
+	}
+	defer val.Free()
+	if !val.Exists() {
+		return "", nil, ErrKeyNotFound
+	}
+	v, info := d.decodeValue(cf, val.Data())
+	return string(v), info, nil
+}
+
+// TTLMode returns whether values are decoded as DBWithTTL values
+func (d *DB) TTLMode() bool {
+	return d.ttl != nil
+}
+
+// Expiry buckets for TTLStats.ExpiryDistribution, in display order
+var TTLBuckets = []string{"expired", "< 1h", "1h - 1d", "1d - 7d", "7d - 30d", "> 30d", "never"}
+
+// TTLStats describes the expiry distribution of a column family in TTL mode
+type TTLStats struct {
+	Name               string           `json:"name"`
+	TTL                string           `json:"ttl"` // column family TTL, "none" if values never expire
+	KeyCount           int64            `json:"key_count"`
+	ExpiredCount       int64            `json:"expired_count"` // stale values not yet compacted away
+	InvalidCount       int64            `json:"invalid_count"` // values without a valid timestamp
+	ExpiryDistribution map[string]int64 `json:"expiry_distribution"`
+	OldestWrite        *time.Time       `json:"oldest_write,omitempty"`
+	NewestWrite        *time.Time       `json:"newest_write,omitempty"`
+	NextExpiry         *time.Time       `json:"next_expiry,omitempty"`
+	LastUpdated        time.Time        `json:"last_updated"`
+}
+
+func ttlBucket(info *TTLInfo, now time.Time) string {
+	if info.ExpiresAt == nil {
+		return "never"
+	}
+	if info.Expired {
+		return "expired"
+	}
+	switch remaining := info.ExpiresAt.Sub(now); {
+	case remaining < time.Hour:
+		return "< 1h"
+	case remaining < 24*time.Hour:
+		return "1h - 1d"
+	case remaining < 7*24*time.Hour:
+		return "1d - 7d"
+	case remaining < 30*24*time.Hour:
+		return "7d - 30d"
+	default:
+		return "> 30d"
+	}
+}
+
+// GetTTLStats scans a column family and buckets its values by time to expiry
+func (d *DB) GetTTLStats(cf string) (*TTLStats, error) {
+	if d.ttl == nil {
+		return nil, ErrTTLModeDisabled
+	}
+	h, ok := d.cfHandles[cf]
+	if !ok {
+		return nil, ErrColumnFamilyNotFound
+	}
+
+	now := time.Now()
+	ttl := d.ttl.TTLFor(cf)
+	stats := &TTLStats{
+		Name:               cf,
+		TTL:                "none",
+		ExpiryDistribution: make(map[string]int64),
+		LastUpdated:        now,
+	}
+	if ttl > 0 {
+		stats.TTL = ttl.String()
+	}
+
+	it := d.db.NewIteratorCF(d.ro, h)
+	defer it.Close()
+
+	for it.SeekToFirst(); it.Valid(); it.Next() {
+		v := it.Value()
+		ts, ok := decodeTTLTimestamp(v.Data())
+		v.Free()
+
+		stats.KeyCount++
+		if !ok {
+			stats.InvalidCount++
+			continue
+		}
+		info := newTTLInfo(ts, ttl, now)
+		if info.Expired {
+			stats.ExpiredCount++
+		}
+		stats.ExpiryDistribution[ttlBucket(info, now)]++
+
+		written := info.WriteTime
+		if stats.OldestWrite == nil || written.Before(*stats.OldestWrite) {
+			stats.OldestWrite = &written
+		}
+		if stats.NewestWrite == nil || written.After(*stats.NewestWrite) {
+			stats.NewestWrite = &written
+		}
+		if info.ExpiresAt != nil && !info.Expired && (stats.NextExpiry == nil || info.ExpiresAt.Before(*stats.NextExpiry)) {
+			stats.NextExpiry = info.ExpiresAt
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
@@ -0,0 +1,167 @@
+package db
+
+import (
+	"encoding/binary"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func ttlValue(value string, ts int64) string {
+	buf := make([]byte, len(value)+ttlTimestampSize)
+	copy(buf, value)
+	binary.LittleEndian.PutUint32(buf[len(value):], uint32(ts))
+	return string(buf)
+}
+
+func TestParseTTLSpec(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    *TTLConfig
+		wantErr bool
+	}{
+		{spec: "", want: nil},
+		{spec: "0", want: &TTLConfig{ColumnFamilies: map[string]time.Duration{}}},
+		{spec: "24h", want: &TTLConfig{Default: 24 * time.Hour, ColumnFamilies: map[string]time.Duration{}}},
+		{
+			spec: "3600, events=30m ,logs=60",
+			want: &TTLConfig{Default: time.Hour, ColumnFamilies: map[string]time.Duration{"events": 30 * time.Minute, "logs": time.Minute}},
+		},
+		{spec: "-5", wantErr: true},
+		{spec: "events=soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseTTLSpec(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTTLSpec(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (got == nil) != (tt.want == nil) {
+				t.Fatalf("ParseTTLSpec(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+			if got == nil {
+				return
+			}
+			if got.Default != tt.want.Default || len(got.ColumnFamilies) != len(tt.want.ColumnFamilies) {
+				t.Errorf("ParseTTLSpec(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+			for cf, d := range tt.want.ColumnFamilies {
+				if got.TTLFor(cf) != d {
+					t.Errorf("TTLFor(%q) = %v, want %v", cf, got.TTLFor(cf), d)
+				}
+			}
+		})
+	}
+}
+
+func TestDB_TTLMode(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "testdb")
+	now := time.Now().Unix()
+
+	// Write values the way DBWithTTL stores them
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := db.CreateCF("events"); err != nil {
+		t.Fatalf("CreateCF failed: %v", err)
+	}
+	db.PutCF("events", "fresh", ttlValue(`{"id":1}`, now))
+	db.PutCF("events", "stale", ttlValue(`{"id":2}`, now-2*3600))
+	db.PutCF("events", "short", "ab")
+	if _, _, err := db.GetCFWithTTL("events", "fresh"); err != nil {
+		t.Fatalf("GetCFWithTTL failed: %v", err)
+	}
+	if err := db.PutCFWithTTL("events", "k", "v", time.Hour); !errors.Is(err, ErrTTLModeDisabled) {
+		t.Errorf("expected ErrTTLModeDisabled, got %v", err)
+	}
+	db.Close()
+
+	ttl, _ := ParseTTLSpec("0,events=1h")
+	db, err = OpenWithConfig(dbPath, OpenConfig{TTL: ttl})
+	if err != nil {
+		t.Fatalf("OpenWithConfig failed: %v", err)
+	}
+	defer db.Close()
+
+	value, info, err := db.GetCFWithTTL("events", "fresh")
+	if err != nil {
+		t.Fatalf("GetCFWithTTL failed: %v", err)
+	}
+	if value != `{"id":1}` {
+		t.Errorf("value = %q, want timestamp stripped", value)
+	}
+	if info == nil || info.ExpiresAt == nil || info.Expired || info.WriteTime.Unix() != now {
+		t.Errorf("unexpected TTL info for fresh value: %+v", info)
+	}
+
+	if _, info, _ = db.GetCFWithTTL("events", "stale"); info == nil || !info.Expired {
+		t.Errorf("expected stale value to be expired, got %+v", info)
+	}
+	if value, info, _ = db.GetCFWithTTL("events", "short"); value != "ab" || info != nil {
+		t.Errorf("value without timestamp = %q, %+v, want it unchanged", value, info)
+	}
+
+	// Values written in TTL mode round-trip and honour a per-put TTL
+	if err := db.PutCFWithTTL("events", "custom", "x", 10*time.Minute); err != nil {
+		t.Fatalf("PutCFWithTTL failed: %v", err)
+	}
+	value, info, _ = db.GetCFWithTTL("events", "custom")
+	if value != "x" || info == nil || info.ExpiresAt == nil {
+		t.Fatalf("custom TTL value = %q, %+v", value, info)
+	}
+	if remaining := time.Until(*info.ExpiresAt); remaining < 9*time.Minute || remaining > 11*time.Minute {
+		t.Errorf("custom TTL expires in %v, want about 10m", remaining)
+	}
+	if err := db.PutCFWithTTL("default", "k", "v", time.Hour); err == nil {
+		t.Error("expected error for per-put TTL on a column family without TTL")
+	}
+	if err := db.MergeCF("events", "k", "v"); !errors.Is(err, ErrTTLNotSupported) {
+		t.Errorf("expected ErrTTLNotSupported, got %v", err)
+	}
+
+	page, err := db.ScanCFPage("events", nil, nil, ScanOptions{Values: true})
+	if err != nil {
+		t.Fatalf("ScanCFPage failed: %v", err)
+	}
+	for _, kv := range page.ResultsV2 {
+		if kv.Key == "fresh" && (kv.Value != `{"id":1}` || kv.TTL == nil) {
+			t.Errorf("scan result = %+v, want decoded value and TTL", kv)
+		}
+	}
+
+	results, err := db.SearchCF("events", SearchOptions{ValuePattern: `*"id"*`})
+	if err != nil {
+		t.Fatalf("SearchCF failed: %v", err)
+	}
+	if results.Total != 2 {
+		t.Errorf("search matched %d values, want 2", results.Total)
+	}
+
+	cfStats, err := db.GetCFStats("events")
+	if err != nil {
+		t.Fatalf("GetCFStats failed: %v", err)
+	}
+	if cfStats.DataTypeDistribution[DataTypeJSON] != 2 {
+		t.Errorf("JSON values = %d, want 2 once timestamps are stripped", cfStats.DataTypeDistribution[DataTypeJSON])
+	}
+
+	stats, err := db.GetTTLStats("events")
+	if err != nil {
+		t.Fatalf("GetTTLStats failed: %v", err)
+	}
+	if stats.KeyCount != 4 || stats.ExpiredCount != 1 || stats.InvalidCount != 1 {
+		t.Errorf("unexpected TTL stats: %+v", stats)
+	}
+	if stats.ExpiryDistribution["< 1h"] != 2 || stats.ExpiryDistribution["expired"] != 1 {
+		t.Errorf("unexpected expiry distribution: %v", stats.ExpiryDistribution)
+	}
+	if _, err := db.GetTTLStats("missing"); !errors.Is(err, ErrColumnFamilyNotFound) {
+		t.Errorf("expected ErrColumnFamilyNotFound, got %v", err)
+	}
+}
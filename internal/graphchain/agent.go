@@ -74,6 +74,7 @@ type Agent struct {
 	executor         ExecutorInterface
 	tools            []tools.Tool
 	database         *db.DB
+	toolsDB          db.KeyValueDB // database used by the tools, see SetAuditedDB
 	memory           *ConversationMemory
 	capability       ModelCapability
 	timeouts         TimeoutConfig
@@ -102,6 +103,17 @@ func NewAgent(database *db.DB) *Agent {
 	}
 }
 
+// SetAuditedDB makes the agent's tools operate through database, typically
+// an audit wrapper around the agent's own database. Call before Initialize.
+func (a *Agent) SetAuditedDB(database db.KeyValueDB) {
+	a.toolsDB = database
+}
+
+// ReadOnly reports whether the agent's database tools cannot write
+func (a *Agent) ReadOnly() bool {
+	return a.database == nil || a.database.IsReadOnly()
+}
+
 // Initialize sets up the agent with configuration
 func (a *Agent) Initialize(ctx context.Context, config *Config) error {
 	a.config = config
@@ -195,11 +207,16 @@ func (a *Agent) initializeExecutor() (ExecutorInterface, error) {
 
 // createDatabaseTools creates all database tools with standard tools.Tool interface
 func (a *Agent) createDatabaseTools() []tools.Tool {
+	var database db.KeyValueDB = a.database
+	if a.toolsDB != nil {
+		database = a.toolsDB
+	}
+
 	// Create services
-	dbService := service.NewDatabaseService(a.database)
-	scanService := service.NewScanService(a.database)
-	searchService := service.NewSearchService(a.database)
-	statsService := service.NewStatsService(a.database)
+	dbService := service.NewDatabaseService(database)
+	scanService := service.NewScanService(database)
+	searchService := service.NewSearchService(database)
+	statsService := service.NewStatsService(database)
 
 	return []tools.Tool{
 		NewGetValueTool(dbService),
@@ -0,0 +1,295 @@
+// Package inspect explains binary values: it renders a hex dump with offsets
+// and lists the decodings the bytes could plausibly have, such as varints,
+// fixed-width integers, UTF-8 runs, embedded JSON, protobuf fields and .NET
+// ticks. The decodings are candidates, not facts; the reader picks the ones
+// that make sense for the data.
+package inspect
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"rocksdb-cli/internal/util"
+)
+
+// Limits keep the report of large values readable
+const (
+	// maxIntOffsets is the number of offsets fixed-width integers are read at
+	maxIntOffsets = 16
+	// maxCandidates caps the varints, strings and JSON documents reported
+	maxCandidates = 32
+	// minStringRun is the number of characters a UTF-8 run needs to be reported
+	minStringRun = 4
+	// maxProtobufDepth is how deep length-delimited fields are parsed as messages
+	maxProtobufDepth = 3
+)
+
+// Ticks outside these years are not reported as .NET ticks
+var (
+	minTicks = util.TimeToTicks(time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC))
+	maxTicks = util.TimeToTicks(time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC))
+)
+
+// Report is the hex dump of a value and its candidate decodings
+type Report struct {
+	Size     int         `json:"size"`
+	Hexdump  []string    `json:"hexdump"`
+	Varints  []Varint    `json:"varints,omitempty"`
+	Integers []Integer   `json:"integers,omitempty"`
+	Strings  []Run       `json:"strings,omitempty"`
+	JSON     []JSONValue `json:"json,omitempty"`
+	Protobuf []Field     `json:"protobuf,omitempty"`
+	Ticks    []Ticks     `json:"ticks,omitempty"`
+}
+
+// Varint is a base-128 varint of the sequence read from the start of the value
+type Varint struct {
+	Offset   int    `json:"offset"`
+	Length   int    `json:"length"`
+	Unsigned uint64 `json:"unsigned"`
+	Zigzag   int64  `json:"zigzag"` // the value as a zigzag-encoded signed int
+}
+
+// Integer is a fixed-width integer read at an offset in both byte orders
+type Integer struct {
+	Offset       int    `json:"offset"`
+	Width        int    `json:"width"` // 2, 4 or 8 bytes
+	BigEndian    uint64 `json:"big_endian"`
+	LittleEndian uint64 `json:"little_endian"`
+	SignedBig    int64  `json:"signed_big_endian"`
+	SignedLittle int64  `json:"signed_little_endian"`
+}
+
+// Run is a run of printable UTF-8 text
+type Run struct {
+	Offset int    `json:"offset"`
+	Length int    `json:"length"` // in bytes
+	Text   string `json:"text"`
+}
+
+// JSONValue is a JSON object or array embedded in the value
+type JSONValue struct {
+	Offset int             `json:"offset"`
+	Length int             `json:"length"`
+	Value  json.RawMessage `json:"value"`
+}
+
+// Field is a protobuf field guessed from the wire format
+type Field struct {
+	Offset   int     `json:"offset"`
+	Number   int     `json:"number"`
+	WireType string  `json:"wire_type"` // varint, fixed64, bytes or fixed32
+	Value    string  `json:"value"`     // the number, or the bytes as text or hex
+	Message  []Field `json:"message,omitempty"`
+}
+
+// Ticks is an 8-byte integer that reads as a plausible .NET ticks time
+type Ticks struct {
+	Offset    int    `json:"offset"`
+	ByteOrder string `json:"byte_order"`
+	Ticks     int64  `json:"ticks"`
+	Time      string `json:"time"`
+}
+
+// Inspect returns the hex dump of data and its candidate decodings
+func Inspect(data []byte) *Report {
+	r := &Report{Size: len(data)}
+	if dump := strings.TrimRight(hex.Dump(data), "\n"); dump != "" {
+		r.Hexdump = strings.Split(dump, "\n")
+	}
+	r.Varints = varints(data)
+	r.Integers = integers(data)
+	r.Strings = stringRuns(data)
+	r.JSON = embeddedJSON(data)
+	r.Protobuf, _ = parseMessage(data, 0)
+	r.Ticks = ticks(data)
+	return r
+}
+
+// varints reads varints from the start of data until one does not parse
+func varints(data []byte) []Varint {
+	var out []Varint
+	for off := 0; off < len(data) && len(out) < maxCandidates; {
+		v, n := binary.Uvarint(data[off:])
+		if n <= 0 {
+			break
+		}
+		out = append(out, Varint{Offset: off, Length: n, Unsigned: v, Zigzag: zigzag(v)})
+		off += n
+	}
+	return out
+}
+
+func zigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// integers reads 2, 4 and 8-byte integers at the first offsets of data
+func integers(data []byte) []Integer {
+	var out []Integer
+	for off := 0; off < len(data) && off < maxIntOffsets; off++ {
+		for _, width := range []int{2, 4, 8} {
+			if off+width > len(data) {
+				break
+			}
+			b := data[off : off+width]
+			i := Integer{Offset: off, Width: width}
+			switch width {
+			case 2:
+				i.BigEndian, i.LittleEndian = uint64(binary.BigEndian.Uint16(b)), uint64(binary.LittleEndian.Uint16(b))
+				i.SignedBig, i.SignedLittle = int64(int16(i.BigEndian)), int64(int16(i.LittleEndian))
+			case 4:
+				i.BigEndian, i.LittleEndian = uint64(binary.BigEndian.Uint32(b)), uint64(binary.LittleEndian.Uint32(b))
+				i.SignedBig, i.SignedLittle = int64(int32(i.BigEndian)), int64(int32(i.LittleEndian))
+			case 8:
+				i.BigEndian, i.LittleEndian = binary.BigEndian.Uint64(b), binary.LittleEndian.Uint64(b)
+				i.SignedBig, i.SignedLittle = int64(i.BigEndian), int64(i.LittleEndian)
+			}
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// stringRuns returns the runs of printable UTF-8 text in data
+func stringRuns(data []byte) []Run {
+	var out []Run
+	start, count := 0, 0
+	flush := func(end int) {
+		if count >= minStringRun && len(out) < maxCandidates {
+			out = append(out, Run{Offset: start, Length: end - start, Text: string(data[start:end])})
+		}
+		count = 0
+	}
+	for off := 0; off < len(data); {
+		r, size := utf8.DecodeRune(data[off:])
+		if (r == utf8.RuneError && size <= 1) || !(unicode.IsPrint(r) || r == '\t') {
+			flush(off)
+			off += max(size, 1)
+			start = off
+			continue
+		}
+		count++
+		off += size
+	}
+	flush(len(data))
+	return out
+}
+
+// embeddedJSON returns the JSON objects and arrays found in data
+func embeddedJSON(data []byte) []JSONValue {
+	var out []JSONValue
+	for off := 0; off < len(data) && len(out) < maxCandidates; off++ {
+		if data[off] != '{' && data[off] != '[' {
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(data[off:]))
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			continue
+		}
+		n := int(dec.InputOffset())
+		var compact bytes.Buffer
+		if json.Compact(&compact, raw) == nil {
+			raw = compact.Bytes()
+		}
+		out = append(out, JSONValue{Offset: off, Length: n, Value: raw})
+		off += n - 1
+	}
+	return out
+}
+
+// parseMessage parses data as protobuf fields; it fails unless every byte
+// belongs to a field
+func parseMessage(data []byte, depth int) ([]Field, bool) {
+	var fields []Field
+	for off := 0; off < len(data); {
+		tag, n := binary.Uvarint(data[off:])
+		if n <= 0 {
+			return nil, false
+		}
+		number, wire := tag>>3, tag&7
+		if number == 0 || number > 1<<29-1 {
+			return nil, false
+		}
+		f := Field{Offset: off, Number: int(number)}
+		off += n
+
+		switch wire {
+		case 0:
+			v, n := binary.Uvarint(data[off:])
+			if n <= 0 {
+				return nil, false
+			}
+			f.WireType, f.Value = "varint", strconv.FormatUint(v, 10)
+			off += n
+		case 1:
+			if off+8 > len(data) {
+				return nil, false
+			}
+			f.WireType, f.Value = "fixed64", strconv.FormatUint(binary.LittleEndian.Uint64(data[off:]), 10)
+			off += 8
+		case 5:
+			if off+4 > len(data) {
+				return nil, false
+			}
+			f.WireType, f.Value = "fixed32", strconv.FormatUint(uint64(binary.LittleEndian.Uint32(data[off:])), 10)
+			off += 4
+		case 2:
+			length, n := binary.Uvarint(data[off:])
+			if n <= 0 || length > uint64(len(data)-off-n) {
+				return nil, false
+			}
+			off += n
+			payload := data[off : off+int(length)]
+			off += int(length)
+			f.WireType = "bytes"
+			if util.IsPrintable(payload) {
+				f.Value = strconv.Quote(string(payload))
+			} else {
+				f.Value = util.ToHexString(payload)
+				if depth < maxProtobufDepth {
+					if message, ok := parseMessage(payload, depth+1); ok {
+						f.Message = message
+					}
+				}
+			}
+		default:
+			// Groups (3, 4) are deprecated and 6, 7 are not wire types
+			return nil, false
+		}
+		fields = append(fields, f)
+	}
+	return fields, len(fields) > 0
+}
+
+// ticks returns the 8-byte integers of data that read as .NET ticks between
+// 1970 and 2200
+func ticks(data []byte) []Ticks {
+	var out []Ticks
+	for off := 0; off+8 <= len(data) && off < maxIntOffsets; off++ {
+		for _, order := range []struct {
+			name string
+			bo   binary.ByteOrder
+		}{{"big-endian", binary.BigEndian}, {"little-endian", binary.LittleEndian}} {
+			t := int64(order.bo.Uint64(data[off:]))
+			if t < minTicks || t >= maxTicks {
+				continue
+			}
+			out = append(out, Ticks{
+				Offset:    off,
+				ByteOrder: order.name,
+				Ticks:     t,
+				Time:      util.TicksToTime(t).Format(time.RFC3339Nano),
+			})
+		}
+	}
+	return out
+}
@@ -0,0 +1,105 @@
+package inspect
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"rocksdb-cli/internal/util"
+)
+
+func TestProtobuf(t *testing.T) {
+	// field 1 = 150, field 2 = "hello", field 3 = {field 1 = 1}
+	data := []byte("\x08\x96\x01\x12\x05hello\x1a\x02\x08\x01")
+	r := Inspect(data)
+
+	want := []Field{
+		{Offset: 0, Number: 1, WireType: "varint", Value: "150"},
+		{Offset: 3, Number: 2, WireType: "bytes", Value: `"hello"`},
+		{Offset: 10, Number: 3, WireType: "bytes", Value: "0801", Message: []Field{
+			{Offset: 0, Number: 1, WireType: "varint", Value: "1"},
+		}},
+	}
+	if !reflect.DeepEqual(r.Protobuf, want) {
+		t.Errorf("Protobuf = %+v, want %+v", r.Protobuf, want)
+	}
+	if len(r.Strings) != 1 || r.Strings[0] != (Run{Offset: 5, Length: 5, Text: "hello"}) {
+		t.Errorf("Strings = %+v, want hello at 5", r.Strings)
+	}
+
+	// Text does not parse as a message to its end
+	if fields, ok := parseMessage([]byte("hello world"), 0); ok {
+		t.Errorf("text should not parse as protobuf, got %+v", fields)
+	}
+}
+
+func TestIntegersAndVarints(t *testing.T) {
+	r := Inspect([]byte{0xff, 0xfe, 0x01, 0x00})
+
+	want := []Integer{
+		{Offset: 0, Width: 2, BigEndian: 0xfffe, LittleEndian: 0xfeff, SignedBig: -2, SignedLittle: -257},
+		{Offset: 0, Width: 4, BigEndian: 0xfffe0100, LittleEndian: 0x0001feff, SignedBig: -130816, SignedLittle: 130815},
+		{Offset: 1, Width: 2, BigEndian: 0xfe01, LittleEndian: 0x01fe, SignedBig: -511, SignedLittle: 510},
+		{Offset: 2, Width: 2, BigEndian: 0x0100, LittleEndian: 0x0001, SignedBig: 256, SignedLittle: 1},
+	}
+	if !reflect.DeepEqual(r.Integers, want) {
+		t.Errorf("Integers = %+v, want %+v", r.Integers, want)
+	}
+
+	wantVarints := []Varint{
+		{Offset: 0, Length: 3, Unsigned: 0x7f | 0x7e<<7 | 1<<14, Zigzag: -16320},
+		{Offset: 3, Length: 1, Unsigned: 0, Zigzag: 0},
+	}
+	if !reflect.DeepEqual(r.Varints, wantVarints) {
+		t.Errorf("Varints = %+v, want %+v", r.Varints, wantVarints)
+	}
+}
+
+func TestEmbeddedJSON(t *testing.T) {
+	r := Inspect([]byte("\x00\x01{\"a\": [1, 2]}\xff[x"))
+	if len(r.JSON) != 1 || r.JSON[0].Offset != 2 || r.JSON[0].Length != 13 || string(r.JSON[0].Value) != `{"a":[1,2]}` {
+		t.Errorf("JSON = %+v, want the object at 2", r.JSON)
+	}
+}
+
+func TestTicks(t *testing.T) {
+	when := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	data := binary.BigEndian.AppendUint64([]byte{0x00}, uint64(util.TimeToTicks(when)))
+	r := Inspect(data)
+
+	if len(r.Ticks) != 1 {
+		t.Fatalf("Ticks = %+v, want one", r.Ticks)
+	}
+	if got := r.Ticks[0]; got.Offset != 1 || got.ByteOrder != "big-endian" || got.Time != "2024-03-01T12:30:00Z" {
+		t.Errorf("Ticks = %+v, want big-endian 2024-03-01T12:30:00Z at 1", got)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	var buf bytes.Buffer
+	Inspect([]byte("\x08\x96\x01\x12\x05hello")).WriteText(&buf)
+	out := buf.String()
+
+	for _, want := range []string{
+		"Size: 10 bytes",
+		"00000000  08 96 01 12 05 68 65 6c  6c 6f",
+		"|.....hello|",
+		`@5        5 bytes  "hello"`,
+		"@0     field 1  varint   150",
+		"@1     150 (zigzag 75)",
+		"0       2      2198  ",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	Inspect(nil).WriteText(&buf)
+	if buf.String() != "Size: 0 bytes\n" {
+		t.Errorf("an empty value should only show its size, got %q", buf.String())
+	}
+}
@@ -0,0 +1,84 @@
+package inspect
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// WriteText writes the report for people, one section per kind of decoding;
+// empty sections are left out
+func (r *Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "Size: %d bytes\n", r.Size)
+	if r.Size == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "\nHex dump:")
+	for _, line := range r.Hexdump {
+		fmt.Fprintf(w, "  %s\n", line)
+	}
+
+	if len(r.Strings) > 0 {
+		fmt.Fprintln(w, "\nUTF-8 strings:")
+		for _, s := range r.Strings {
+			fmt.Fprintf(w, "  @%-5d %4d bytes  %s\n", s.Offset, s.Length, strconv.Quote(s.Text))
+		}
+	}
+
+	if len(r.JSON) > 0 {
+		fmt.Fprintln(w, "\nEmbedded JSON:")
+		for _, j := range r.JSON {
+			fmt.Fprintf(w, "  @%-5d %4d bytes  %s\n", j.Offset, j.Length, j.Value)
+		}
+	}
+
+	if len(r.Protobuf) > 0 {
+		fmt.Fprintln(w, "\nProtobuf (guessed from the wire format):")
+		writeFields(w, r.Protobuf, "  ")
+	}
+
+	if len(r.Ticks) > 0 {
+		fmt.Fprintln(w, "\n.NET ticks:")
+		for _, t := range r.Ticks {
+			fmt.Fprintf(w, "  @%-5d %-13s  %d = %s\n", t.Offset, t.ByteOrder, t.Ticks, t.Time)
+		}
+	}
+
+	if len(r.Varints) > 0 {
+		fmt.Fprintln(w, "\nVarints from offset 0:")
+		for _, v := range r.Varints {
+			fmt.Fprintf(w, "  @%-5d %d (zigzag %d)\n", v.Offset, v.Unsigned, v.Zigzag)
+		}
+	}
+
+	if len(r.Integers) > 0 {
+		fmt.Fprintln(w, "\nFixed-width integers:")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  offset\twidth\tbig-endian\tlittle-endian")
+		for _, i := range r.Integers {
+			fmt.Fprintf(tw, "  %d\t%d\t%s\t%s\n", i.Offset, i.Width,
+				integerText(i.BigEndian, i.SignedBig), integerText(i.LittleEndian, i.SignedLittle))
+		}
+		tw.Flush()
+	}
+}
+
+// integerText shows an integer unsigned, and signed too when that differs
+func integerText(unsigned uint64, signed int64) string {
+	if signed < 0 {
+		return fmt.Sprintf("%d (%d)", unsigned, signed)
+	}
+	return strconv.FormatUint(unsigned, 10)
+}
+
+func writeFields(w io.Writer, fields []Field, indent string) {
+	for _, f := range fields {
+		fmt.Fprintf(w, "%s@%-5d field %d  %-7s  %s\n", indent, f.Offset, f.Number, f.WireType, f.Value)
+		if len(f.Message) > 0 {
+			writeFields(w, f.Message, indent+strings.Repeat(" ", 4))
+		}
+	}
+}
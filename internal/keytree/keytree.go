@@ -0,0 +1,246 @@
+// Package keytree builds a hierarchical view of a key space: a trie of key
+// prefixes with the number and size of the keys under each of them.
+//
+// Keys are split into levels at delimiters ("user:1:name" becomes "user:",
+// "1:" and "name") or every Width bytes. Only prefixes that have more key
+// bytes after them become nodes; the last part of a key is counted in its
+// parent, so a column family of a million "user:<id>" keys is one node with
+// a million keys rather than a million nodes.
+package keytree
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+
+	"rocksdb-cli/internal/util"
+)
+
+// Defaults of Options
+const (
+	DefaultDelimiters  = ":/|"
+	DefaultMaxDepth    = 4
+	DefaultMaxChildren = 100
+)
+
+// maxNodes caps the size of a tree; prefixes found once it is reached are
+// counted in the other node of their parent
+const maxNodes = 10000
+
+// OtherLabel is the label of the node counting the children of a node past
+// Options.MaxChildren
+const OtherLabel = "(other)"
+
+// Options configures how keys are split into the levels of the tree
+type Options struct {
+	// Prefix limits the tree to the keys under it; it is the root of the tree
+	Prefix string `json:"prefix,omitempty"`
+	// Delimiters are the bytes a level ends with, DefaultDelimiters when empty
+	Delimiters string `json:"delimiters,omitempty"`
+	// Width splits keys every Width bytes instead of at delimiters
+	Width int `json:"width,omitempty"`
+	// MaxDepth is the number of levels below the root, DefaultMaxDepth when 0
+	MaxDepth int `json:"max_depth,omitempty"`
+	// MaxChildren is the number of children kept per node, the first ones
+	// found; the keys of the others are counted in one OtherLabel node.
+	// DefaultMaxChildren when 0
+	MaxChildren int `json:"max_children,omitempty"`
+}
+
+// Validate checks the options and fills in the defaults
+func (o *Options) Validate() error {
+	if o.Width < 0 || o.MaxDepth < 0 || o.MaxChildren < 0 {
+		return errors.New("width, depth and max children cannot be negative")
+	}
+	if o.Width == 0 && o.Delimiters == "" {
+		o.Delimiters = DefaultDelimiters
+	}
+	if o.MaxDepth == 0 {
+		o.MaxDepth = DefaultMaxDepth
+	}
+	if o.MaxChildren == 0 {
+		o.MaxChildren = DefaultMaxChildren
+	}
+	return nil
+}
+
+// Node is a key prefix and the keys under it
+type Node struct {
+	// Prefix is the whole key prefix of the node, Label the part of it
+	// added to the prefix of the parent
+	Prefix string `json:"prefix"`
+	Label  string `json:"label"`
+	// Keys counts the keys under the prefix, including the keys of the
+	// children; KeyBytes and ValueBytes are their sizes
+	Keys       int64 `json:"keys"`
+	KeyBytes   int64 `json:"key_bytes"`
+	ValueBytes int64 `json:"value_bytes"`
+	// ApproximateSize is the space the prefix takes on disk as estimated
+	// by RocksDB, 0 when unknown
+	ApproximateSize uint64 `json:"approximate_size"`
+	// Other marks the node counting the children past Options.MaxChildren
+	Other    bool    `json:"other,omitempty"`
+	Children []*Node `json:"children,omitempty"`
+
+	index map[string]*Node // children by label, while building
+	other *Node
+}
+
+// MarshalJSON writes binary prefixes and their labels as hex, flagged with
+// "binary" like binary keys in scan results
+func (n *Node) MarshalJSON() ([]byte, error) {
+	type node Node // without this method
+	out := struct {
+		node
+		Binary bool `json:"binary,omitempty"`
+	}{node: node(*n)}
+	if _, binary := Encode(n.Prefix); binary {
+		out.Prefix = util.ToHexString([]byte(n.Prefix))
+		out.Label = util.ToHexString([]byte(n.Label))
+		out.Binary = true
+	}
+	return json.Marshal(out)
+}
+
+// Encode returns a prefix or label as shown: binary ones as hex, reported
+// with true
+func Encode(s string) (string, bool) {
+	if util.IsPrintable([]byte(s)) {
+		return s, false
+	}
+	return util.ToHexString([]byte(s)), true
+}
+
+// Size is the bytes of the keys and values under the node
+func (n *Node) Size() int64 {
+	return n.KeyBytes + n.ValueBytes
+}
+
+// Find returns the node of prefix in the tree of n, or nil
+func (n *Node) Find(prefix string) *Node {
+	if prefix == n.Prefix {
+		return n
+	}
+	for _, child := range n.Children {
+		if !child.Other && strings.HasPrefix(prefix, child.Prefix) {
+			return child.Find(prefix)
+		}
+	}
+	return nil
+}
+
+// Walk calls fn for n and every node under it, parents first
+func (n *Node) Walk(fn func(*Node)) {
+	fn(n)
+	for _, child := range n.Children {
+		child.Walk(fn)
+	}
+}
+
+// Builder builds a tree from keys added in any order
+type Builder struct {
+	opts  Options
+	root  *Node
+	nodes int
+}
+
+// NewBuilder returns a builder of a tree split as opts says; opts must
+// have been validated
+func NewBuilder(opts Options) *Builder {
+	return &Builder{opts: opts, root: &Node{Prefix: opts.Prefix, Label: opts.Prefix}, nodes: 1}
+}
+
+// Add counts a key and the size of its value. Keys outside the prefix of
+// the tree are ignored.
+func (b *Builder) Add(key []byte, valueSize int) {
+	if !bytes.HasPrefix(key, []byte(b.opts.Prefix)) {
+		return
+	}
+	node := b.root
+	b.count(node, key, valueSize)
+
+	rest := key[len(b.opts.Prefix):]
+	for depth := 0; depth < b.opts.MaxDepth && !node.Other; depth++ {
+		n := b.segment(rest)
+		if n == 0 || n >= len(rest) {
+			// The last part of the key is not a prefix of other keys
+			return
+		}
+		node = b.child(node, string(rest[:n]))
+		b.count(node, key, valueSize)
+		rest = rest[n:]
+	}
+}
+
+// segment returns the length of the first level of rest
+func (b *Builder) segment(rest []byte) int {
+	if b.opts.Width > 0 {
+		return min(b.opts.Width, len(rest))
+	}
+	if i := bytes.IndexAny(rest, b.opts.Delimiters); i >= 0 {
+		return i + 1
+	}
+	return len(rest)
+}
+
+func (b *Builder) count(node *Node, key []byte, valueSize int) {
+	node.Keys++
+	node.KeyBytes += int64(len(key))
+	node.ValueBytes += int64(valueSize)
+}
+
+// child returns the child of node with label, created unless node has too
+// many children
+func (b *Builder) child(node *Node, label string) *Node {
+	if c, ok := node.index[label]; ok {
+		return c
+	}
+	if len(node.index) >= b.opts.MaxChildren || b.nodes >= maxNodes {
+		if node.other == nil {
+			node.other = &Node{Prefix: node.Prefix, Label: OtherLabel, Other: true}
+			b.nodes++
+		}
+		return node.other
+	}
+	if node.index == nil {
+		node.index = make(map[string]*Node)
+	}
+	c := &Node{Prefix: node.Prefix + label, Label: label}
+	node.index[label] = c
+	b.nodes++
+	return c
+}
+
+// Tree returns the tree of the keys added, children in key order with the
+// other node last
+func (b *Builder) Tree() *Node {
+	b.root.Walk(func(n *Node) {
+		n.Children = n.Children[:0]
+		for _, c := range n.index {
+			n.Children = append(n.Children, c)
+		}
+		sort.Slice(n.Children, func(i, j int) bool { return n.Children[i].Label < n.Children[j].Label })
+		if n.other != nil {
+			n.Children = append(n.Children, n.other)
+		}
+		if len(n.Children) == 0 {
+			n.Children = nil
+		}
+	})
+	return b.root
+}
+
+// PrefixEnd returns the first key after all the keys starting with prefix,
+// or nil when there is none
+func PrefixEnd(prefix []byte) []byte {
+	end := bytes.Clone(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package keytree
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func build(t *testing.T, opts Options, keys ...string) *Node {
+	t.Helper()
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	b := NewBuilder(opts)
+	for _, key := range keys {
+		b.Add([]byte(key), 10)
+	}
+	return b.Tree()
+}
+
+// shape returns the labels and key counts of a tree, children in brackets
+func shape(n *Node) string {
+	s := fmt.Sprintf("%s=%d", n.Label, n.Keys)
+	if len(n.Children) > 0 {
+		s += "["
+		for i, c := range n.Children {
+			if i > 0 {
+				s += " "
+			}
+			s += shape(c)
+		}
+		s += "]"
+	}
+	return s
+}
+
+func TestDelimiters(t *testing.T) {
+	root := build(t, Options{},
+		"order/2024/01", "order/2024/02", "order/2025/01",
+		"user:1:name", "user:1:email", "user:2:name", "user:3", "version")
+
+	want := "=8[order/=3[2024/=2 2025/=1] user:=4[1:=2 2:=1]]"
+	if got := shape(root); got != want {
+		t.Errorf("tree = %s, want %s", got, want)
+	}
+	if root.KeyBytes != 86 || root.ValueBytes != 80 || root.Size() != 166 {
+		t.Errorf("root sizes = %d+%d, want 86+80", root.KeyBytes, root.ValueBytes)
+	}
+
+	user := root.Find("user:1:")
+	if user == nil || user.Prefix != "user:1:" || user.Keys != 2 {
+		t.Fatalf("Find(user:1:) = %+v", user)
+	}
+	if root.Find("user:1") != nil || root.Find("nosuch:") != nil {
+		t.Errorf("Find should only return nodes of the tree")
+	}
+}
+
+func TestWidthDepthAndPrefix(t *testing.T) {
+	keys := []string{"\x00\x01\x00\x01", "\x00\x01\x00\x02", "\x00\x02\x00\x01", "\x01\x00\x00\x00"}
+	root := build(t, Options{Width: 2}, keys...)
+	if got, want := shape(root), "=4[\x00\x01=2 \x00\x02=1 \x01\x00=1]"; got != want {
+		t.Errorf("tree = %q, want %q", got, want)
+	}
+
+	root = build(t, Options{MaxDepth: 1, Prefix: "a:"}, "a:b:c:d", "a:b:c:e", "a:x", "b:y")
+	if got, want := shape(root), "a:=3[b:=2]"; got != want {
+		t.Errorf("tree = %s, want %s", got, want)
+	}
+}
+
+func TestMaxChildren(t *testing.T) {
+	var keys []string
+	for i := 0; i < 5; i++ {
+		keys = append(keys, fmt.Sprintf("t%d:x", i), fmt.Sprintf("t%d:y", i))
+	}
+	root := build(t, Options{MaxChildren: 3}, keys...)
+	if got, want := shape(root), "=10[t0:=2 t1:=2 t2:=2 (other)=4]"; got != want {
+		t.Errorf("tree = %s, want %s", got, want)
+	}
+	if other := root.Children[3]; !other.Other || other.Children != nil {
+		t.Errorf("the other node should not have children: %+v", other)
+	}
+
+	if err := (&Options{Width: -1}).Validate(); err == nil {
+		t.Errorf("a negative width should be rejected")
+	}
+}
+
+func TestPrefixEnd(t *testing.T) {
+	tests := []struct{ prefix, want []byte }{
+		{[]byte("user:"), []byte("user;")},
+		{[]byte{0x01, 0xff}, []byte{0x02}},
+		{[]byte{0xff, 0xff}, nil},
+		{nil, nil},
+	}
+	for _, tt := range tests {
+		if got := PrefixEnd(tt.prefix); !bytes.Equal(got, tt.want) || (got == nil) != (tt.want == nil) {
+			t.Errorf("PrefixEnd(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	root := build(t, Options{Width: 2}, "\x00\x01ab", "\x00\x01cd", "ab:cd")
+	data, err := json.Marshal(root)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	for _, want := range []string{
+		`"prefix":"0001","label":"0001","keys":2`,
+		`"binary":true`,
+		`"prefix":"ab","label":"ab","keys":1`,
+	} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("JSON should contain %s: %s", want, data)
+		}
+	}
+	if bytes.Count(data, []byte(`"binary"`)) != 1 {
+		t.Errorf("only the binary prefix should be flagged: %s", data)
+	}
+}
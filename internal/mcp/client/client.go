@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sync"
 
@@ -120,6 +121,59 @@ func (bc *BaseClient) CheckInitialized() error {
 	return nil
 }
 
+// newInitializeRequest builds the initialize request of a client
+func newInitializeRequest(cfg *config.MCPClientConfig) protocol.InitializeRequest {
+	return protocol.InitializeRequest{
+		ProtocolVersion: protocol.MCPProtocolVersion,
+		ClientInfo: protocol.ClientInfo{
+			Name:    cfg.Name,
+			Version: "1.0.0",
+		},
+		Capabilities: protocol.Capabilities{},
+	}
+}
+
+// responseID returns the ID of a response to one of our requests, which
+// are numbered from 1, or false for notifications and foreign IDs
+func responseID(id interface{}) (int64, bool) {
+	switch v := id.(type) {
+	case float64:
+		return int64(v), true
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// decodeResponse returns the error of resp, or converts its result into
+// result
+func decodeResponse(resp *protocol.JSONRPCResponse, result interface{}) error {
+	if resp.Error != nil {
+		return &protocol.MCPError{
+			Code:    resp.Error.Code,
+			Message: resp.Error.Message,
+			Data:    resp.Error.Data,
+		}
+	}
+
+	if result != nil {
+		// Re-marshal and unmarshal to convert interface{} to typed struct
+		data, err := json.Marshal(resp.Result)
+		if err != nil {
+			return fmt.Errorf("failed to marshal result: %w", err)
+		}
+
+		if err := json.Unmarshal(data, result); err != nil {
+			return fmt.Errorf("failed to unmarshal result: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // Default implementations that return errors (to be overridden by actual implementations)
 
 func (bc *BaseClient) Connect(ctx context.Context) error {
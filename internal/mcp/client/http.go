@@ -0,0 +1,273 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"rocksdb-cli/internal/config"
+	"rocksdb-cli/internal/mcp/protocol"
+)
+
+// sessionHeader carries the session ID of the Streamable HTTP transport
+const sessionHeader = "Mcp-Session-Id"
+
+// StreamableHTTPClient implements an MCP client using the Streamable HTTP
+// transport: every request is POSTed to the endpoint and answered with a
+// JSON body or an SSE stream carrying the response.
+type StreamableHTTPClient struct {
+	*BaseClient
+
+	httpClient *http.Client
+	url        string
+
+	// Session assigned by the server on initialize
+	sessionMu sync.Mutex
+	sessionID string
+
+	requestID atomic.Int64
+}
+
+// NewStreamableHTTPClient creates a new Streamable HTTP MCP client posting to
+// http://Host:Port/Path
+func NewStreamableHTTPClient(name string, cfg *config.MCPClientConfig) *StreamableHTTPClient {
+	path := cfg.Path
+	if path == "" {
+		path = "/mcp"
+	}
+	return &StreamableHTTPClient{
+		BaseClient: NewBaseClient(name, cfg),
+		httpClient: &http.Client{},
+		url:        fmt.Sprintf("http://%s:%d%s", cfg.Host, cfg.Port, path),
+	}
+}
+
+// Connect prepares the client; the HTTP transport has no connection of its
+// own, so the server is first contacted by Initialize
+func (hc *StreamableHTTPClient) Connect(ctx context.Context) error {
+	if hc.IsConnected() {
+		return protocol.NewConnectionError("already connected", nil)
+	}
+
+	hc.SetConnected(true)
+	return nil
+}
+
+// Disconnect ends the session on the server
+func (hc *StreamableHTTPClient) Disconnect(ctx context.Context) error {
+	if !hc.IsConnected() {
+		return nil
+	}
+
+	hc.SetConnected(false)
+	hc.SetInitialized(false)
+
+	hc.sessionMu.Lock()
+	sessionID := hc.sessionID
+	hc.sessionID = ""
+	hc.sessionMu.Unlock()
+
+	if sessionID != "" {
+		// Best effort: the session also expires on the server
+		req, err := http.NewRequestWithContext(ctx, http.MethodDelete, hc.url, nil)
+		if err == nil {
+			req.Header.Set(sessionHeader, sessionID)
+			if resp, err := hc.httpClient.Do(req); err == nil {
+				resp.Body.Close()
+			}
+		}
+	}
+
+	return nil
+}
+
+// Initialize sends the initialize request and starts a session
+func (hc *StreamableHTTPClient) Initialize(ctx context.Context) (*protocol.InitializeResult, error) {
+	if err := hc.CheckConnected(); err != nil {
+		return nil, err
+	}
+
+	hc.sessionMu.Lock()
+	hc.sessionID = ""
+	hc.sessionMu.Unlock()
+
+	var result protocol.InitializeResult
+	if err := hc.sendRequest(ctx, protocol.MethodInitialize, newInitializeRequest(hc.GetConfig()), &result); err != nil {
+		return nil, err
+	}
+
+	hc.SetInitialized(true)
+	hc.SetServerInfo(&result.ServerInfo)
+
+	return &result, nil
+}
+
+// Ping sends a ping request
+func (hc *StreamableHTTPClient) Ping(ctx context.Context) error {
+	if err := hc.CheckConnected(); err != nil {
+		return err
+	}
+
+	var result interface{}
+	return hc.sendRequest(ctx, protocol.MethodPing, nil, &result)
+}
+
+// ListTools lists available tools
+func (hc *StreamableHTTPClient) ListTools(ctx context.Context, cursor string) (*protocol.ListToolsResult, error) {
+	if err := hc.CheckInitialized(); err != nil {
+		return nil, err
+	}
+
+	var result protocol.ListToolsResult
+	if err := hc.sendRequest(ctx, protocol.MethodListTools, protocol.ListToolsRequest{Cursor: cursor}, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// CallTool calls a tool with given arguments
+func (hc *StreamableHTTPClient) CallTool(ctx context.Context, name string, arguments map[string]interface{}) (*protocol.ToolCallResult, error) {
+	if err := hc.CheckInitialized(); err != nil {
+		return nil, err
+	}
+
+	req := protocol.CallToolRequest{
+		Name:      name,
+		Arguments: arguments,
+	}
+
+	var result protocol.ToolCallResult
+	if err := hc.sendRequest(ctx, protocol.MethodCallTool, req, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// sendRequest POSTs a JSON-RPC request and waits for its response
+func (hc *StreamableHTTPClient) sendRequest(ctx context.Context, method string, params interface{}, result interface{}) error {
+	id := hc.requestID.Add(1)
+	body, err := json.Marshal(protocol.JSONRPCRequest{
+		JSONRPC: protocol.JSONRPCVersion,
+		ID:      id,
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	timeout := hc.GetConfig().Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctxWithTimeout, http.MethodPost, hc.url, bytes.NewReader(body))
+	if err != nil {
+		return protocol.NewConnectionError("invalid request", map[string]string{"error": err.Error()})
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	hc.sessionMu.Lock()
+	if hc.sessionID != "" {
+		req.Header.Set(sessionHeader, hc.sessionID)
+	}
+	hc.sessionMu.Unlock()
+
+	resp, err := hc.httpClient.Do(req)
+	if err != nil {
+		if ctxWithTimeout.Err() == context.DeadlineExceeded {
+			return protocol.NewConnectionTimeoutError("request timeout")
+		}
+		return protocol.NewConnectionError("failed to send request", map[string]string{
+			"error":  err.Error(),
+			"method": method,
+		})
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound && req.Header.Get(sessionHeader) != "":
+		// The session expired; the client must initialize again
+		hc.SetInitialized(false)
+		return protocol.NewConnectionError("session expired", map[string]string{"method": method})
+	case resp.StatusCode != http.StatusOK:
+		return protocol.NewConnectionError("unexpected HTTP status", map[string]string{
+			"status": resp.Status,
+			"method": method,
+		})
+	}
+
+	if sessionID := resp.Header.Get(sessionHeader); sessionID != "" && method == protocol.MethodInitialize {
+		hc.sessionMu.Lock()
+		hc.sessionID = sessionID
+		hc.sessionMu.Unlock()
+	}
+
+	var response *protocol.JSONRPCResponse
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		response, err = readEventStreamResponse(resp.Body, id)
+	} else {
+		response = &protocol.JSONRPCResponse{}
+		err = json.NewDecoder(resp.Body).Decode(response)
+	}
+	if err != nil {
+		if ctxWithTimeout.Err() == context.DeadlineExceeded {
+			return protocol.NewConnectionTimeoutError("request timeout")
+		}
+		return protocol.NewConnectionError("failed to read response", map[string]string{
+			"error":  err.Error(),
+			"method": method,
+		})
+	}
+
+	return decodeResponse(response, result)
+}
+
+// readEventStreamResponse reads SSE events until the response to request id
+// arrives; notifications sent before it are skipped
+func readEventStreamResponse(body io.Reader, id int64) (*protocol.JSONRPCResponse, error) {
+	reader := bufio.NewReader(body)
+	var data strings.Builder
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			if err == io.EOF {
+				return nil, fmt.Errorf("stream ended without a response")
+			}
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if strings.HasPrefix(line, "data:") {
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+			continue
+		}
+		if line != "" || data.Len() == 0 {
+			continue // other fields, or a blank line without data
+		}
+
+		// A blank line ends the event
+		var resp protocol.JSONRPCResponse
+		decodeErr := json.Unmarshal([]byte(data.String()), &resp)
+		data.Reset()
+		if decodeErr != nil {
+			continue
+		}
+		if respID, ok := responseID(resp.ID); ok && respID == id {
+			return &resp, nil
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"rocksdb-cli/internal/mcp/protocol"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockHTTPServer is a mock MCP Streamable HTTP server. It answers tool calls
+// with an SSE stream and everything else with a JSON body.
+type mockHTTPServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	sessions map[string]bool
+	nextID   int
+	deleted  []string
+}
+
+func startMockMCPHTTPServer(t *testing.T) *mockHTTPServer {
+	s := &mockHTTPServer{sessions: make(map[string]bool)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *mockHTTPServer) handle(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/mcp" {
+		http.NotFound(w, r)
+		return
+	}
+
+	sessionID := r.Header.Get(sessionHeader)
+	s.mu.Lock()
+	known := s.sessions[sessionID]
+	s.mu.Unlock()
+
+	if r.Method == http.MethodDelete {
+		s.mu.Lock()
+		delete(s.sessions, sessionID)
+		s.deleted = append(s.deleted, sessionID)
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var req protocol.JSONRPCRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Method == protocol.MethodInitialize {
+		s.mu.Lock()
+		s.nextID++
+		sessionID = fmt.Sprintf("session-%d", s.nextID)
+		s.sessions[sessionID] = true
+		s.mu.Unlock()
+		w.Header().Set(sessionHeader, sessionID)
+	} else if !known {
+		http.NotFound(w, r)
+		return
+	}
+
+	resp := mockMCPResponse(req)
+	if req.Method == protocol.MethodCallTool {
+		// Stream a progress notification ahead of the response
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\",\"params\":{}}\n\n")
+		data, _ := json.Marshal(resp)
+		fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// expireSessions forgets all sessions, as a restarted server would
+func (s *mockHTTPServer) expireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = make(map[string]bool)
+}
+
+// Test Streamable HTTP client full lifecycle
+func TestStreamableHTTPClient_FullLifecycle(t *testing.T) {
+	server := startMockMCPHTTPServer(t)
+	cfg := httpTestServerConfig(t, server.Server)
+	cfg.Transport = "streamable-http"
+	client := NewStreamableHTTPClient("http-lifecycle", cfg)
+	ctx := context.Background()
+
+	require.NoError(t, client.Connect(ctx))
+	assert.True(t, client.IsConnected())
+
+	// Requests need a session
+	assert.Error(t, client.Ping(ctx))
+
+	result, err := client.Initialize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.MCPProtocolVersion, result.ProtocolVersion)
+	assert.Equal(t, "session-1", client.sessionID)
+
+	require.NoError(t, client.Ping(ctx))
+
+	tools, err := client.ListTools(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, tools.Tools, 1)
+
+	// Tool calls are answered over SSE
+	toolResult, err := client.CallTool(ctx, "echo", map[string]interface{}{"message": "hello"})
+	require.NoError(t, err)
+	require.Len(t, toolResult.Content, 1)
+	assert.Equal(t, "success", toolResult.Content[0].Text)
+
+	require.NoError(t, client.Disconnect(ctx))
+	assert.False(t, client.IsConnected())
+	assert.Equal(t, []string{"session-1"}, server.deleted)
+}
+
+// Test Streamable HTTP client losing its session
+func TestStreamableHTTPClient_SessionExpired(t *testing.T) {
+	server := startMockMCPHTTPServer(t)
+	cfg := httpTestServerConfig(t, server.Server)
+	cfg.Transport = "streamable-http"
+	client := NewStreamableHTTPClient("http-expired", cfg)
+	ctx := context.Background()
+
+	require.NoError(t, client.Connect(ctx))
+	_, err := client.Initialize(ctx)
+	require.NoError(t, err)
+
+	server.expireSessions()
+	err = client.Ping(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "session expired")
+	assert.False(t, client.IsInitialized())
+
+	// A new initialize starts a new session
+	_, err = client.Initialize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "session-2", client.sessionID)
+	assert.NoError(t, client.Ping(ctx))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"rocksdb-cli/internal/config"
 	"rocksdb-cli/internal/mcp/protocol"
@@ -11,26 +12,43 @@ import (
 
 // Manager manages multiple MCP clients
 type Manager struct {
-	mu      sync.RWMutex
-	clients map[string]Client
-	config  *config.Config
+	mu          sync.RWMutex
+	clients     map[string]Client
+	config      *config.Config
+	supervisors map[string]*supervision
+	onReconnect []func(ctx context.Context, name string)
+}
+
+// supervision is a running supervisor
+type supervision struct {
+	supervisor *Supervisor
+	cancel     context.CancelFunc
+	done       chan struct{}
 }
 
 // ClientStatus represents the status of a client
 type ClientStatus struct {
 	Name        string
-	State       string // "connected", "disconnected", "error"
+	State       string // "connected", "disconnected", "reconnecting", "failed"
 	Connected   bool
 	Initialized bool
 	ServerInfo  *protocol.ServerInfo
 	Error       string
+
+	// Set for clients supervised with Supervise
+	Supervised        bool
+	Reconnects        int       // successful reconnections
+	ReconnectAttempts int       // failed attempts since the connection was lost
+	LastPing          time.Time // last successful health check
+	NextRetry         time.Time // next reconnection attempt, zero if none is due
 }
 
 // NewManager creates a new client manager
 func NewManager(cfg *config.Config) *Manager {
 	m := &Manager{
-		clients: make(map[string]Client),
-		config:  cfg,
+		clients:     make(map[string]Client),
+		config:      cfg,
+		supervisors: make(map[string]*supervision),
 	}
 
 	// Load clients from configuration
@@ -61,6 +79,12 @@ func (m *Manager) loadClients() {
 			client = NewStdioClient(name, clientCfg)
 		case "tcp":
 			client = NewTCPClient(name, clientCfg)
+		case "unix":
+			client = NewUnixClient(name, clientCfg)
+		case "websocket":
+			client = NewWebSocketClient(name, clientCfg)
+		case "streamable-http":
+			client = NewStreamableHTTPClient(name, clientCfg)
 		default:
 			// Skip unsupported transport types
 			continue
@@ -120,16 +144,24 @@ func (m *Manager) StartClient(ctx context.Context, name string) error {
 	return nil
 }
 
-// StopClient stops a specific client
+// StopClient stops a specific client and its supervisor
 func (m *Manager) StopClient(ctx context.Context, name string) error {
-	m.mu.RLock()
+	m.mu.Lock()
 	client, ok := m.clients[name]
-	m.mu.RUnlock()
+	sup := m.supervisors[name]
+	delete(m.supervisors, name)
+	m.mu.Unlock()
 
 	if !ok {
 		return fmt.Errorf("client %s not found", name)
 	}
 
+	// Stop the supervisor first so it doesn't restart the client
+	if sup != nil {
+		sup.cancel()
+		<-sup.done
+	}
+
 	// Disconnect is idempotent, so it's safe to call multiple times
 	return client.Disconnect(ctx)
 }
@@ -212,10 +244,66 @@ func (m *Manager) StopAll(ctx context.Context) error {
 	return nil
 }
 
+// OnReconnect registers fn to be called after a supervisor has reconnected
+// a client
+func (m *Manager) OnReconnect(fn func(ctx context.Context, name string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onReconnect = append(m.onReconnect, fn)
+}
+
+func (m *Manager) reconnected(ctx context.Context, name string) {
+	m.mu.RLock()
+	hooks := m.onReconnect
+	m.mu.RUnlock()
+	for _, fn := range hooks {
+		fn(ctx, name)
+	}
+}
+
+// Supervise starts a supervisor for every client that has none, so dead
+// connections are detected and reconnected until ctx is done or the client
+// is stopped. Clients that are not connected yet are connected at their
+// first health check.
+func (m *Manager) Supervise(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for name, client := range m.clients {
+		if _, ok := m.supervisors[name]; ok {
+			continue
+		}
+		supCtx, cancel := context.WithCancel(ctx)
+		sup := &supervision{
+			supervisor: NewSupervisor(client, m.reconnected),
+			cancel:     cancel,
+			done:       make(chan struct{}),
+		}
+		m.supervisors[name] = sup
+		go func() {
+			defer close(sup.done)
+			sup.supervisor.Run(supCtx)
+		}()
+	}
+}
+
+// Supervisor returns the supervisor of a client, or nil if it is not
+// supervised
+func (m *Manager) Supervisor(name string) *Supervisor {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if sup, ok := m.supervisors[name]; ok {
+		return sup.supervisor
+	}
+	return nil
+}
+
 // GetClientStatus returns the status of a specific client
 func (m *Manager) GetClientStatus(name string) ClientStatus {
 	m.mu.RLock()
 	client, ok := m.clients[name]
+	sup := m.supervisors[name]
 	m.mu.RUnlock()
 
 	status := ClientStatus{
@@ -235,15 +323,17 @@ func (m *Manager) GetClientStatus(name string) ClientStatus {
 	if status.Connected {
 		status.State = "connected"
 		// Get server info if available
-		if bc, ok := client.(*StdioClient); ok {
-			status.ServerInfo = bc.GetServerInfo()
-		} else if tc, ok := client.(*TCPClient); ok {
-			status.ServerInfo = tc.GetServerInfo()
+		if c, ok := client.(interface{ GetServerInfo() *protocol.ServerInfo }); ok {
+			status.ServerInfo = c.GetServerInfo()
 		}
 	} else {
 		status.State = "disconnected"
 	}
 
+	if sup != nil {
+		sup.supervisor.fillStatus(&status)
+	}
+
 	return status
 }
 
@@ -468,6 +468,26 @@ func TestManager_DifferentTransports(t *testing.T) {
 				Command:   mockServerPath,
 				Timeout:   5 * time.Second,
 			},
+			"unix-client": {
+				Name:       "unix-client",
+				Enabled:    true,
+				Transport:  "unix",
+				SocketPath: "/tmp/mcp.sock",
+			},
+			"ws-client": {
+				Name:      "ws-client",
+				Enabled:   true,
+				Transport: "websocket",
+				Host:      "localhost",
+				Port:      8090,
+			},
+			"http-client": {
+				Name:      "http-client",
+				Enabled:   true,
+				Transport: "streamable-http",
+				Host:      "localhost",
+				Port:      8090,
+			},
 		},
 	}
 
@@ -482,6 +502,21 @@ func TestManager_DifferentTransports(t *testing.T) {
 		_, ok := client.(*StdioClient)
 		assert.True(t, ok, "Client should be of type *StdioClient")
 	})
+
+	t.Run("creates Unix socket client", func(t *testing.T) {
+		_, ok := manager.GetClient("unix-client").(*UnixClient)
+		assert.True(t, ok, "Client should be of type *UnixClient")
+	})
+
+	t.Run("creates WebSocket client", func(t *testing.T) {
+		_, ok := manager.GetClient("ws-client").(*WebSocketClient)
+		assert.True(t, ok, "Client should be of type *WebSocketClient")
+	})
+
+	t.Run("creates Streamable HTTP client", func(t *testing.T) {
+		_, ok := manager.GetClient("http-client").(*StreamableHTTPClient)
+		assert.True(t, ok, "Client should be of type *StreamableHTTPClient")
+	})
 }
 
 // Test graceful shutdown
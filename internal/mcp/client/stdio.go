@@ -20,22 +20,23 @@ import (
 type StdioClient struct {
 	*BaseClient
 
-	// Process management
+	// Process management; guarded by procMu as a restart replaces them
+	procMu sync.Mutex
 	cmd    *exec.Cmd
 	stdin  io.WriteCloser
 	stdout io.ReadCloser
 	stderr io.ReadCloser
+	exited chan struct{} // closed when the process has been waited for
 
 	// Communication
 	encoder *json.Encoder
-	decoder *json.Decoder
 
 	// Request tracking
 	requestID  atomic.Int64
 	responseCh map[int64]chan *protocol.JSONRPCResponse
 	responseMu sync.RWMutex
 
-	// Cleanup
+	// Cleanup; done is closed when the reader of the current process exits
 	cancel context.CancelFunc
 	done   chan struct{}
 }
@@ -45,7 +46,6 @@ func NewStdioClient(name string, cfg *config.MCPClientConfig) *StdioClient {
 	return &StdioClient{
 		BaseClient: NewBaseClient(name, cfg),
 		responseCh: make(map[int64]chan *protocol.JSONRPCResponse),
-		done:       make(chan struct{}),
 	}
 }
 
@@ -59,9 +59,8 @@ func (sc *StdioClient) Connect(ctx context.Context) error {
 
 	// Create command
 	cmdCtx, cancel := context.WithCancel(context.Background())
-	sc.cancel = cancel
 
-	sc.cmd = exec.CommandContext(cmdCtx, cfg.Command, cfg.Args...)
+	cmd := exec.CommandContext(cmdCtx, cfg.Command, cfg.Args...)
 
 	// Set environment variables
 	if len(cfg.Env) > 0 {
@@ -69,20 +68,19 @@ func (sc *StdioClient) Connect(ctx context.Context) error {
 		for key, value := range cfg.Env {
 			env = append(env, fmt.Sprintf("%s=%s", key, value))
 		}
-		sc.cmd.Env = env
+		cmd.Env = env
 	}
 
 	// Setup pipes
-	stdin, err := sc.cmd.StdinPipe()
+	stdin, err := cmd.StdinPipe()
 	if err != nil {
 		cancel()
 		return protocol.NewConnectionError("failed to create stdin pipe", map[string]string{
 			"error": err.Error(),
 		})
 	}
-	sc.stdin = stdin
 
-	stdout, err := sc.cmd.StdoutPipe()
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		stdin.Close()
 		cancel()
@@ -90,9 +88,8 @@ func (sc *StdioClient) Connect(ctx context.Context) error {
 			"error": err.Error(),
 		})
 	}
-	sc.stdout = stdout
 
-	stderr, err := sc.cmd.StderrPipe()
+	stderr, err := cmd.StderrPipe()
 	if err != nil {
 		stdin.Close()
 		stdout.Close()
@@ -101,10 +98,9 @@ func (sc *StdioClient) Connect(ctx context.Context) error {
 			"error": err.Error(),
 		})
 	}
-	sc.stderr = stderr
 
 	// Start the process
-	if err := sc.cmd.Start(); err != nil {
+	if err := cmd.Start(); err != nil {
 		stdin.Close()
 		stdout.Close()
 		stderr.Close()
@@ -115,18 +111,24 @@ func (sc *StdioClient) Connect(ctx context.Context) error {
 		})
 	}
 
-	// Setup encoder/decoder
-	sc.encoder = json.NewEncoder(sc.stdin)
-	sc.decoder = json.NewDecoder(bufio.NewReader(sc.stdout))
+	done := make(chan struct{})
+	exited := make(chan struct{})
+
+	sc.procMu.Lock()
+	sc.cmd, sc.cancel = cmd, cancel
+	sc.stdin, sc.stdout, sc.stderr = stdin, stdout, stderr
+	sc.encoder = json.NewEncoder(stdin)
+	sc.done, sc.exited = done, exited
+	sc.procMu.Unlock()
 
 	// Start response reader
-	go sc.readResponses()
+	go sc.readResponses(json.NewDecoder(bufio.NewReader(stdout)), done)
 
 	// Start stderr reader
-	go sc.readStderr()
+	go sc.readStderr(stderr)
 
 	// Monitor process exit
-	go sc.monitorProcess()
+	go sc.monitorProcess(cmd, exited)
 
 	sc.SetConnected(true)
 	return nil
@@ -141,23 +143,23 @@ func (sc *StdioClient) Disconnect(ctx context.Context) error {
 	sc.SetConnected(false)
 	sc.SetInitialized(false)
 
+	sc.procMu.Lock()
+	cmd, cancel, exited := sc.cmd, sc.cancel, sc.exited
+	stdin, stdout, stderr := sc.stdin, sc.stdout, sc.stderr
+	sc.procMu.Unlock()
+
 	// Cancel context to signal shutdown
-	if sc.cancel != nil {
-		sc.cancel()
+	if cancel != nil {
+		cancel()
 	}
 
 	// Close stdin to signal process to exit
-	if sc.stdin != nil {
-		sc.stdin.Close()
+	if stdin != nil {
+		stdin.Close()
 	}
 
 	// Wait for process to exit (with timeout)
-	if sc.cmd != nil && sc.cmd.Process != nil {
-		done := make(chan error, 1)
-		go func() {
-			done <- sc.cmd.Wait()
-		}()
-
+	if cmd != nil && cmd.Process != nil {
 		// Give process 1 second to exit gracefully
 		timeout := time.NewTimer(1 * time.Second)
 		defer timeout.Stop()
@@ -165,43 +167,42 @@ func (sc *StdioClient) Disconnect(ctx context.Context) error {
 		select {
 		case <-timeout.C:
 			// Timeout - force kill
-			if sc.cmd.Process != nil {
-				sc.cmd.Process.Kill()
-			}
+			cmd.Process.Kill()
 			// Give it 500ms to die after kill
 			select {
-			case <-done:
+			case <-exited:
 				// Killed successfully
 			case <-time.After(500 * time.Millisecond):
 				// Process still won't die, give up
 			}
 		case <-ctx.Done():
 			// Context cancelled - force kill
-			if sc.cmd.Process != nil {
-				sc.cmd.Process.Kill()
-			}
+			cmd.Process.Kill()
 			// Brief wait after kill
 			select {
-			case <-done:
+			case <-exited:
 			case <-time.After(500 * time.Millisecond):
 			}
-		case <-done:
+		case <-exited:
 			// Process exited normally
 		}
 	}
 
 	// Close remaining pipes
-	if sc.stdout != nil {
-		sc.stdout.Close()
+	if stdout != nil {
+		stdout.Close()
 	}
-	if sc.stderr != nil {
-		sc.stderr.Close()
+	if stderr != nil {
+		stderr.Close()
 	}
 
-	// Clear response channels
+	// Fail pending requests
 	sc.responseMu.Lock()
 	for _, ch := range sc.responseCh {
-		close(ch)
+		select {
+		case ch <- nil:
+		default:
+		}
 	}
 	sc.responseCh = make(map[int64]chan *protocol.JSONRPCResponse)
 	sc.responseMu.Unlock()
@@ -302,7 +303,6 @@ func (sc *StdioClient) sendRequest(ctx context.Context, method string, params in
 		sc.responseMu.Lock()
 		delete(sc.responseCh, id)
 		sc.responseMu.Unlock()
-		close(respCh)
 	}()
 
 	// Create request
@@ -314,12 +314,20 @@ func (sc *StdioClient) sendRequest(ctx context.Context, method string, params in
 	}
 
 	// Send request
+	sc.procMu.Lock()
+	if sc.encoder == nil {
+		sc.procMu.Unlock()
+		return protocol.NewConnectionError("connection closed", nil)
+	}
 	if err := sc.encoder.Encode(request); err != nil {
+		sc.procMu.Unlock()
 		return protocol.NewConnectionError("failed to send request", map[string]string{
 			"error":  err.Error(),
 			"method": method,
 		})
 	}
+	done := sc.done
+	sc.procMu.Unlock()
 
 	// Wait for response with timeout
 	cfg := sc.GetConfig()
@@ -364,18 +372,19 @@ func (sc *StdioClient) sendRequest(ctx context.Context, method string, params in
 	case <-ctxWithTimeout.Done():
 		return protocol.NewConnectionTimeoutError("request timeout")
 
-	case <-sc.done:
+	case <-done:
 		return protocol.NewConnectionError("connection closed", nil)
 	}
 }
 
-// readResponses reads responses from stdout
-func (sc *StdioClient) readResponses() {
-	defer close(sc.done)
+// readResponses reads responses from the stdout of one process until it
+// fails, then closes done
+func (sc *StdioClient) readResponses(decoder *json.Decoder, done chan struct{}) {
+	defer close(done)
 
 	for {
 		var resp protocol.JSONRPCResponse
-		if err := sc.decoder.Decode(&resp); err != nil {
+		if err := decoder.Decode(&resp); err != nil {
 			if err == io.EOF {
 				return
 			}
@@ -421,8 +430,8 @@ func (sc *StdioClient) readResponses() {
 }
 
 // readStderr reads and logs stderr output
-func (sc *StdioClient) readStderr() {
-	scanner := bufio.NewScanner(sc.stderr)
+func (sc *StdioClient) readStderr(stderr io.Reader) {
+	scanner := bufio.NewScanner(stderr)
 	for scanner.Scan() {
 		// Log stderr output (in production, use proper logger)
 		// For now, we just consume it to prevent blocking
@@ -430,16 +439,17 @@ func (sc *StdioClient) readStderr() {
 	}
 }
 
-// monitorProcess monitors the process and handles unexpected exits
-func (sc *StdioClient) monitorProcess() {
-	if sc.cmd == nil || sc.cmd.Process == nil {
-		return
-	}
-
-	sc.cmd.Wait()
-
-	// Process exited, clean up
-	if sc.IsConnected() {
+// monitorProcess waits for the process, closes exited and handles
+// unexpected exits
+func (sc *StdioClient) monitorProcess(cmd *exec.Cmd, exited chan struct{}) {
+	cmd.Wait()
+	close(exited)
+
+	// Process exited, clean up unless it has already been replaced
+	sc.procMu.Lock()
+	current := sc.cmd == cmd
+	sc.procMu.Unlock()
+	if current && sc.IsConnected() {
 		sc.SetConnected(false)
 		sc.SetInitialized(false)
 	}
@@ -0,0 +1,223 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"rocksdb-cli/internal/config"
+)
+
+// DefaultHealthCheckInterval is how often a supervised client is pinged when
+// its configuration sets no interval
+const DefaultHealthCheckInterval = 30 * time.Second
+
+// Client states reported in ClientStatus
+const (
+	StateConnected    = "connected"
+	StateDisconnected = "disconnected"
+	StateReconnecting = "reconnecting"
+	StateFailed       = "failed"
+)
+
+// Supervisor keeps a client connected. It pings the client every health
+// check interval; when the ping fails or a stdio process has exited, it
+// disconnects the client, which stops the process, and reconnects with the
+// backoff of the client's retry configuration, re-running initialize. After
+// MaxAttempts failed attempts the client is marked failed and retried at the
+// next health check.
+type Supervisor struct {
+	client      Client
+	retry       config.RetryConfig
+	interval    time.Duration
+	onReconnect func(ctx context.Context, name string)
+
+	mu         sync.Mutex
+	state      string
+	attempts   int // failed attempts since the connection was lost
+	reconnects int
+	lastError  string
+	lastPing   time.Time
+	nextRetry  time.Time
+}
+
+// NewSupervisor creates a supervisor for c using the retry and health check
+// settings of its configuration. onReconnect, if not nil, is called after
+// every successful reconnection.
+func NewSupervisor(c Client, onReconnect func(ctx context.Context, name string)) *Supervisor {
+	s := &Supervisor{
+		client:      c,
+		interval:    DefaultHealthCheckInterval,
+		onReconnect: onReconnect,
+		state:       StateDisconnected,
+	}
+	if cfg := c.GetConfig(); cfg != nil {
+		s.retry = cfg.Retry
+		if cfg.HealthCheckInterval > 0 {
+			s.interval = cfg.HealthCheckInterval
+		}
+	}
+	if c.IsConnected() && c.IsInitialized() {
+		s.state = StateConnected
+	}
+	return s
+}
+
+// Run checks the client every health check interval until ctx is done
+func (s *Supervisor) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Check(ctx)
+		}
+	}
+}
+
+// Check pings the client and reconnects it if it is not healthy
+func (s *Supervisor) Check(ctx context.Context) error {
+	if s.client.IsConnected() && s.client.IsInitialized() {
+		err := s.client.Ping(ctx)
+		if err == nil {
+			s.mu.Lock()
+			s.state = StateConnected
+			s.lastPing = time.Now()
+			s.mu.Unlock()
+			return nil
+		}
+		s.setError(err)
+	}
+	return s.reconnect(ctx)
+}
+
+// reconnect restarts the client, retrying with backoff
+func (s *Supervisor) reconnect(ctx context.Context) error {
+	name := s.client.GetName()
+	maxAttempts := s.retry.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+
+	s.mu.Lock()
+	s.state = StateReconnecting
+	s.attempts = 0
+	s.mu.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		s.client.Disconnect(ctx)
+		err := s.connect(ctx)
+		if err == nil {
+			s.mu.Lock()
+			s.state = StateConnected
+			s.attempts = 0
+			s.reconnects++
+			s.lastError = ""
+			s.lastPing = time.Now()
+			s.nextRetry = time.Time{}
+			s.mu.Unlock()
+
+			if s.onReconnect != nil {
+				s.onReconnect(ctx, name)
+			}
+			return nil
+		}
+		s.setError(err)
+
+		if attempt >= maxAttempts {
+			s.mu.Lock()
+			s.state = StateFailed
+			s.attempts = attempt
+			s.nextRetry = time.Time{}
+			s.mu.Unlock()
+			return fmt.Errorf("failed to reconnect client %s after %d attempts: %w", name, attempt, err)
+		}
+
+		wait := Backoff(s.retry, attempt)
+		s.mu.Lock()
+		s.attempts = attempt
+		s.nextRetry = time.Now().Add(wait)
+		s.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// connect connects and initializes the client
+func (s *Supervisor) connect(ctx context.Context) error {
+	if err := s.client.Connect(ctx); err != nil {
+		return err
+	}
+	if _, err := s.client.Initialize(ctx); err != nil {
+		s.client.Disconnect(ctx)
+		return err
+	}
+	return nil
+}
+
+func (s *Supervisor) setError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastError = err.Error()
+}
+
+// fillStatus adds the supervision state to status
+func (s *Supervisor) fillStatus(status *ClientStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status.Supervised = true
+	if s.state == StateReconnecting || s.state == StateFailed {
+		status.State = s.state
+	}
+	status.ReconnectAttempts = s.attempts
+	status.Reconnects = s.reconnects
+	status.LastPing = s.lastPing
+	status.NextRetry = s.nextRetry
+	if s.lastError != "" {
+		status.Error = s.lastError
+	}
+}
+
+// Backoff returns how long to wait after the given failed attempt, counted
+// from 1: InitialWait for constant backoff, InitialWait times attempt for
+// linear backoff and InitialWait doubled per attempt for exponential backoff,
+// never more than MaxWait
+func Backoff(retry config.RetryConfig, attempt int) time.Duration {
+	initial := retry.InitialWait
+	if initial <= 0 {
+		initial = time.Second
+	}
+	maxWait := retry.MaxWait
+	if maxWait <= 0 {
+		maxWait = 30 * time.Second
+	}
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	wait := initial
+	switch retry.Backoff {
+	case "constant":
+	case "linear":
+		wait = initial * time.Duration(attempt)
+	default: // exponential
+		for i := 1; i < attempt && wait < maxWait; i++ {
+			wait *= 2
+		}
+	}
+	if wait > maxWait || wait <= 0 {
+		wait = maxWait
+	}
+	return wait
+}
@@ -0,0 +1,226 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"rocksdb-cli/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		backoff string
+		attempt int
+		want    time.Duration
+	}{
+		{"constant", 1, 100 * time.Millisecond},
+		{"constant", 5, 100 * time.Millisecond},
+		{"linear", 1, 100 * time.Millisecond},
+		{"linear", 3, 300 * time.Millisecond},
+		{"linear", 20, time.Second},
+		{"exponential", 1, 100 * time.Millisecond},
+		{"exponential", 3, 400 * time.Millisecond},
+		{"exponential", 10, time.Second},
+		{"", 2, 200 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.backoff, tt.attempt), func(t *testing.T) {
+			retry := config.RetryConfig{Backoff: tt.backoff, InitialWait: 100 * time.Millisecond, MaxWait: time.Second}
+			assert.Equal(t, tt.want, Backoff(retry, tt.attempt))
+		})
+	}
+
+	assert.Equal(t, time.Second, Backoff(config.RetryConfig{}, 1), "defaults to a 1s initial wait")
+}
+
+// dropServer is a mock MCP TCP server whose connections can be dropped
+type dropServer struct {
+	listener net.Listener
+	mu       sync.Mutex
+	conns    []net.Conn
+}
+
+func startDropServer(t *testing.T, addr string) *dropServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", addr)
+	require.NoError(t, err)
+
+	s := &dropServer{listener: listener}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns = append(s.conns, conn)
+			s.mu.Unlock()
+			go handleMCPConnection(conn)
+		}
+	}()
+	t.Cleanup(s.stop)
+	return s
+}
+
+func (s *dropServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+// dropConnections closes the open connections from the server side
+func (s *dropServer) dropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+func (s *dropServer) stop() {
+	s.listener.Close()
+	s.dropConnections()
+}
+
+func supervisedTCPConfig(port int) *config.MCPClientConfig {
+	return &config.MCPClientConfig{
+		Name:      "remote",
+		Enabled:   true,
+		Transport: "tcp",
+		Host:      "localhost",
+		Port:      port,
+		Timeout:   time.Second,
+		Retry: config.RetryConfig{
+			MaxAttempts: 2,
+			Backoff:     "constant",
+			InitialWait: 10 * time.Millisecond,
+			MaxWait:     10 * time.Millisecond,
+		},
+	}
+}
+
+func TestSupervisor_ReconnectsDroppedConnection(t *testing.T) {
+	server := startDropServer(t, "localhost:0")
+	manager := NewManager(&config.Config{
+		MCPClients: config.MCPClientsConfig{"remote": supervisedTCPConfig(server.port())},
+	})
+
+	var reconnected []string
+	var mu sync.Mutex
+	manager.OnReconnect(func(ctx context.Context, name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		reconnected = append(reconnected, name)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, manager.StartClient(ctx, "remote"))
+	defer manager.Shutdown(context.Background())
+	manager.Supervise(ctx)
+
+	sup := manager.Supervisor("remote")
+	require.NotNil(t, sup)
+
+	// A healthy client is only pinged
+	require.NoError(t, sup.Check(ctx))
+	status := manager.GetClientStatus("remote")
+	assert.True(t, status.Supervised)
+	assert.Equal(t, StateConnected, status.State)
+	assert.Zero(t, status.Reconnects)
+	assert.False(t, status.LastPing.IsZero())
+
+	// A dropped connection fails the ping and is replaced
+	server.dropConnections()
+	require.NoError(t, sup.Check(ctx))
+
+	status = manager.GetClientStatus("remote")
+	assert.Equal(t, StateConnected, status.State)
+	assert.True(t, status.Initialized)
+	assert.Equal(t, 1, status.Reconnects)
+	assert.NoError(t, manager.GetClient("remote").Ping(ctx))
+
+	mu.Lock()
+	assert.Equal(t, []string{"remote"}, reconnected)
+	mu.Unlock()
+
+	// Stopping the client stops its supervisor
+	require.NoError(t, manager.StopClient(ctx, "remote"))
+	assert.Nil(t, manager.Supervisor("remote"))
+	assert.False(t, manager.GetClientStatus("remote").Supervised)
+}
+
+func TestSupervisor_FailsAfterMaxAttempts(t *testing.T) {
+	server := startDropServer(t, "localhost:0")
+	port := server.port()
+	manager := NewManager(&config.Config{
+		MCPClients: config.MCPClientsConfig{"remote": supervisedTCPConfig(port)},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, manager.StartClient(ctx, "remote"))
+	defer manager.Shutdown(context.Background())
+	manager.Supervise(ctx)
+	sup := manager.Supervisor("remote")
+
+	server.stop()
+	err := sup.Check(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "after 2 attempts")
+
+	status := manager.GetClientStatus("remote")
+	assert.Equal(t, StateFailed, status.State)
+	assert.False(t, status.Connected)
+	assert.Equal(t, 2, status.ReconnectAttempts)
+	assert.NotEmpty(t, status.Error)
+
+	// The next health check reconnects once the server is back
+	startDropServer(t, fmt.Sprintf("localhost:%d", port))
+	require.NoError(t, sup.Check(ctx))
+	status = manager.GetClientStatus("remote")
+	assert.Equal(t, StateConnected, status.State)
+	assert.Zero(t, status.ReconnectAttempts)
+	assert.Empty(t, status.Error)
+}
+
+func TestSupervisor_RestartsStdioProcess(t *testing.T) {
+	cfg := &config.MCPClientConfig{
+		Name:      "stdio-supervised",
+		Transport: "stdio",
+		Command:   createTestMCPServer(t),
+		Timeout:   5 * time.Second,
+		Retry:     config.RetryConfig{MaxAttempts: 2, Backoff: "constant", InitialWait: 10 * time.Millisecond},
+	}
+	client := NewStdioClient("stdio-supervised", cfg)
+	ctx := context.Background()
+
+	require.NoError(t, client.Connect(ctx))
+	_, err := client.Initialize(ctx)
+	require.NoError(t, err)
+	defer client.Disconnect(ctx)
+
+	sup := NewSupervisor(client, nil)
+
+	client.procMu.Lock()
+	process := client.cmd.Process
+	client.procMu.Unlock()
+	process.Kill()
+	require.Eventually(t, func() bool { return !client.IsConnected() }, 2*time.Second, 10*time.Millisecond,
+		"process exit is noticed")
+
+	require.NoError(t, sup.Check(ctx))
+	assert.True(t, client.IsInitialized())
+	assert.NoError(t, client.Ping(ctx))
+
+	client.procMu.Lock()
+	assert.NotEqual(t, process.Pid, client.cmd.Process.Pid, "a new process was started")
+	client.procMu.Unlock()
+}
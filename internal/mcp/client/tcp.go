@@ -21,18 +21,18 @@ type TCPClient struct {
 	// Connection management
 	conn     net.Conn
 	connMu   sync.Mutex
+	network  string // "tcp", or "unix" for UnixClient
 	dialAddr string
 
 	// Communication
 	encoder *json.Encoder
-	decoder *json.Decoder
 
 	// Request tracking
 	requestID  atomic.Int64
 	responseCh map[int64]chan *protocol.JSONRPCResponse
 	responseMu sync.RWMutex
 
-	// Cleanup
+	// Cleanup; closed when the reader of the current connection exits
 	done chan struct{}
 }
 
@@ -40,9 +40,9 @@ type TCPClient struct {
 func NewTCPClient(name string, cfg *config.MCPClientConfig) *TCPClient {
 	return &TCPClient{
 		BaseClient: NewBaseClient(name, cfg),
+		network:    "tcp",
 		dialAddr:   fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
 		responseCh: make(map[int64]chan *protocol.JSONRPCResponse),
-		done:       make(chan struct{}),
 	}
 }
 
@@ -67,7 +67,7 @@ func (tc *TCPClient) Connect(ctx context.Context) error {
 		Timeout: timeout,
 	}
 
-	conn, err := dialer.DialContext(ctx, "tcp", tc.dialAddr)
+	conn, err := dialer.DialContext(ctx, tc.network, tc.dialAddr)
 	if err != nil {
 		return protocol.NewConnectionError("failed to connect", map[string]string{
 			"error":   err.Error(),
@@ -77,12 +77,12 @@ func (tc *TCPClient) Connect(ctx context.Context) error {
 
 	tc.conn = conn
 
-	// Setup encoder/decoder
+	// Setup encoder; each connection has its own reader
 	tc.encoder = json.NewEncoder(tc.conn)
-	tc.decoder = json.NewDecoder(tc.conn)
+	tc.done = make(chan struct{})
 
 	// Start response reader
-	go tc.readResponses()
+	go tc.readResponses(json.NewDecoder(tc.conn), tc.done)
 
 	tc.SetConnected(true)
 	return nil
@@ -104,10 +104,13 @@ func (tc *TCPClient) Disconnect(ctx context.Context) error {
 	}
 	tc.connMu.Unlock()
 
-	// Clear response channels
+	// Fail pending requests
 	tc.responseMu.Lock()
 	for _, ch := range tc.responseCh {
-		close(ch)
+		select {
+		case ch <- nil:
+		default:
+		}
 	}
 	tc.responseCh = make(map[int64]chan *protocol.JSONRPCResponse)
 	tc.responseMu.Unlock()
@@ -205,7 +208,6 @@ func (tc *TCPClient) sendRequest(ctx context.Context, method string, params inte
 		tc.responseMu.Lock()
 		delete(tc.responseCh, id)
 		tc.responseMu.Unlock()
-		close(respCh)
 	}()
 
 	// Create request
@@ -229,6 +231,7 @@ func (tc *TCPClient) sendRequest(ctx context.Context, method string, params inte
 			"method": method,
 		})
 	}
+	done := tc.done
 	tc.connMu.Unlock()
 
 	// Wait for response with timeout
@@ -274,18 +277,19 @@ func (tc *TCPClient) sendRequest(ctx context.Context, method string, params inte
 	case <-ctxWithTimeout.Done():
 		return protocol.NewConnectionTimeoutError("request timeout")
 
-	case <-tc.done:
+	case <-done:
 		return protocol.NewConnectionError("connection closed", nil)
 	}
 }
 
-// readResponses reads responses from the TCP connection
-func (tc *TCPClient) readResponses() {
-	defer close(tc.done)
+// readResponses reads responses of one connection until it fails, then
+// closes done
+func (tc *TCPClient) readResponses(decoder *json.Decoder, done chan struct{}) {
+	defer close(done)
 
 	for {
 		var resp protocol.JSONRPCResponse
-		if err := tc.decoder.Decode(&resp); err != nil {
+		if err := decoder.Decode(&resp); err != nil {
 			if err == io.EOF {
 				return
 			}
@@ -358,63 +358,68 @@ func handleMCPConnection(conn net.Conn) {
 			return
 		}
 
-		var resp protocol.JSONRPCResponse
-		resp.JSONRPC = protocol.JSONRPCVersion
-		resp.ID = req.ID
-
-		switch req.Method {
-		case protocol.MethodInitialize:
-			resp.Result = protocol.InitializeResult{
-				ProtocolVersion: protocol.MCPProtocolVersion,
-				ServerInfo: protocol.ServerInfo{
-					Name:    "test-tcp-server",
-					Version: "1.0.0",
-				},
-				Capabilities: protocol.Capabilities{
-					Tools: &protocol.ToolsCapability{
-						ListChanged: true,
-					},
-				},
-			}
+		if err := encoder.Encode(mockMCPResponse(req)); err != nil {
+			return
+		}
+	}
+}
 
-		case protocol.MethodPing:
-			resp.Result = map[string]interface{}{}
-
-		case protocol.MethodListTools:
-			resp.Result = protocol.ListToolsResult{
-				Tools: []protocol.Tool{
-					{
-						Name:        "echo",
-						Description: "Echo tool",
-						InputSchema: map[string]interface{}{
-							"type": "object",
-						},
-					},
+// Helper: Build the mock server's response to an MCP request
+func mockMCPResponse(req protocol.JSONRPCRequest) protocol.JSONRPCResponse {
+	var resp protocol.JSONRPCResponse
+	resp.JSONRPC = protocol.JSONRPCVersion
+	resp.ID = req.ID
+
+	switch req.Method {
+	case protocol.MethodInitialize:
+		resp.Result = protocol.InitializeResult{
+			ProtocolVersion: protocol.MCPProtocolVersion,
+			ServerInfo: protocol.ServerInfo{
+				Name:    "test-tcp-server",
+				Version: "1.0.0",
+			},
+			Capabilities: protocol.Capabilities{
+				Tools: &protocol.ToolsCapability{
+					ListChanged: true,
 				},
-			}
+			},
+		}
 
-		case protocol.MethodCallTool:
-			resp.Result = protocol.ToolCallResult{
-				Content: []protocol.Content{
-					{
-						Type: "text",
-						Text: "success",
+	case protocol.MethodPing:
+		resp.Result = map[string]interface{}{}
+
+	case protocol.MethodListTools:
+		resp.Result = protocol.ListToolsResult{
+			Tools: []protocol.Tool{
+				{
+					Name:        "echo",
+					Description: "Echo tool",
+					InputSchema: map[string]interface{}{
+						"type": "object",
 					},
 				},
-				IsError: false,
-			}
+			},
+		}
 
-		default:
-			resp.Error = &protocol.JSONRPCError{
-				Code:    protocol.MethodNotFound,
-				Message: "Method not found",
-			}
+	case protocol.MethodCallTool:
+		resp.Result = protocol.ToolCallResult{
+			Content: []protocol.Content{
+				{
+					Type: "text",
+					Text: "success",
+				},
+			},
+			IsError: false,
 		}
 
-		if err := encoder.Encode(resp); err != nil {
-			return
+	default:
+		resp.Error = &protocol.JSONRPCError{
+			Code:    protocol.MethodNotFound,
+			Message: "Method not found",
 		}
 	}
+
+	return resp
 }
 
 // Benchmark TCP client operations
@@ -0,0 +1,21 @@
+package client
+
+import (
+	"rocksdb-cli/internal/config"
+)
+
+// UnixClient implements an MCP client over a Unix domain socket. Messages
+// are newline-delimited JSON-RPC as on TCP, so it shares TCPClient's
+// implementation.
+type UnixClient struct {
+	*TCPClient
+}
+
+// NewUnixClient creates a new Unix-socket-based MCP client connecting to
+// cfg.SocketPath
+func NewUnixClient(name string, cfg *config.MCPClientConfig) *UnixClient {
+	tc := NewTCPClient(name, cfg)
+	tc.network = "unix"
+	tc.dialAddr = cfg.SocketPath
+	return &UnixClient{TCPClient: tc}
+}
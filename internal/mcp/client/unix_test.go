@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"rocksdb-cli/internal/config"
+	"rocksdb-cli/internal/mcp/protocol"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Unix socket client full lifecycle
+func TestUnixClient_FullLifecycle(t *testing.T) {
+	socketPath := startMockMCPUnixServer(t)
+
+	cfg := &config.MCPClientConfig{
+		Name:       "unix-lifecycle",
+		Transport:  "unix",
+		SocketPath: socketPath,
+		Timeout:    5 * time.Second,
+	}
+
+	client := NewUnixClient("unix-lifecycle", cfg)
+	ctx := context.Background()
+
+	require.NoError(t, client.Connect(ctx))
+	assert.True(t, client.IsConnected())
+
+	result, err := client.Initialize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.MCPProtocolVersion, result.ProtocolVersion)
+	assert.Equal(t, "test-tcp-server", client.GetServerInfo().Name)
+
+	require.NoError(t, client.Ping(ctx))
+
+	tools, err := client.ListTools(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, tools.Tools, 1)
+	assert.Equal(t, "echo", tools.Tools[0].Name)
+
+	toolResult, err := client.CallTool(ctx, "echo", map[string]interface{}{"message": "hello"})
+	require.NoError(t, err)
+	assert.False(t, toolResult.IsError)
+
+	require.NoError(t, client.Disconnect(ctx))
+	assert.False(t, client.IsConnected())
+}
+
+// Test Unix socket client with a missing socket
+func TestUnixClient_ConnectionFailure(t *testing.T) {
+	cfg := &config.MCPClientConfig{
+		Name:       "unix-missing",
+		Transport:  "unix",
+		SocketPath: filepath.Join(t.TempDir(), "missing.sock"),
+		Timeout:    time.Second,
+	}
+
+	client := NewUnixClient("unix-missing", cfg)
+	assert.Error(t, client.Connect(context.Background()))
+	assert.False(t, client.IsConnected())
+}
+
+// Helper: Start a mock MCP server on a Unix socket
+func startMockMCPUnixServer(t *testing.T) string {
+	socketPath := filepath.Join(t.TempDir(), "mcp.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go handleMCPConnection(conn)
+		}
+	}()
+
+	return socketPath
+}
@@ -0,0 +1,284 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"rocksdb-cli/internal/config"
+	"rocksdb-cli/internal/mcp/protocol"
+
+	"golang.org/x/net/websocket"
+)
+
+// WebSocketClient implements an MCP client using WebSocket transport. Each
+// JSON-RPC message is sent as one text frame.
+type WebSocketClient struct {
+	*BaseClient
+
+	// Connection management
+	conn   *websocket.Conn
+	connMu sync.Mutex
+	url    string
+	origin string
+
+	// Request tracking
+	requestID  atomic.Int64
+	responseCh map[int64]chan *protocol.JSONRPCResponse
+	responseMu sync.RWMutex
+
+	// Cleanup; closed when the reader of the current connection exits
+	done chan struct{}
+}
+
+// NewWebSocketClient creates a new WebSocket-based MCP client connecting to
+// ws://Host:Port/Path
+func NewWebSocketClient(name string, cfg *config.MCPClientConfig) *WebSocketClient {
+	path := cfg.Path
+	if path == "" {
+		// The path of rocksdb-mcp-server --transport websocket
+		path = "/ws"
+	}
+	return &WebSocketClient{
+		BaseClient: NewBaseClient(name, cfg),
+		url:        fmt.Sprintf("ws://%s:%d%s", cfg.Host, cfg.Port, path),
+		origin:     fmt.Sprintf("http://%s:%d", cfg.Host, cfg.Port),
+		responseCh: make(map[int64]chan *protocol.JSONRPCResponse),
+	}
+}
+
+// Connect opens the WebSocket connection to the MCP server
+func (wc *WebSocketClient) Connect(ctx context.Context) error {
+	if wc.IsConnected() {
+		return protocol.NewConnectionError("already connected", nil)
+	}
+
+	cfg := wc.GetConfig()
+
+	// Establish connection with timeout
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	dialCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	wsConfig, err := websocket.NewConfig(wc.url, wc.origin)
+	if err != nil {
+		return protocol.NewConnectionError("invalid WebSocket URL", map[string]string{
+			"error": err.Error(),
+			"url":   wc.url,
+		})
+	}
+	conn, err := wsConfig.DialContext(dialCtx)
+	if err != nil {
+		return protocol.NewConnectionError("failed to connect", map[string]string{
+			"error": err.Error(),
+			"url":   wc.url,
+		})
+	}
+
+	wc.connMu.Lock()
+	wc.conn = conn
+	wc.done = make(chan struct{})
+	go wc.readResponses(conn, wc.done)
+	wc.connMu.Unlock()
+
+	wc.SetConnected(true)
+	return nil
+}
+
+// Disconnect closes the WebSocket connection and cleans up
+func (wc *WebSocketClient) Disconnect(ctx context.Context) error {
+	if !wc.IsConnected() {
+		return nil
+	}
+
+	wc.SetConnected(false)
+	wc.SetInitialized(false)
+
+	wc.connMu.Lock()
+	if wc.conn != nil {
+		wc.conn.Close()
+		wc.conn = nil
+	}
+	wc.connMu.Unlock()
+
+	// Fail pending requests
+	wc.responseMu.Lock()
+	for _, ch := range wc.responseCh {
+		select {
+		case ch <- nil:
+		default:
+		}
+	}
+	wc.responseCh = make(map[int64]chan *protocol.JSONRPCResponse)
+	wc.responseMu.Unlock()
+
+	return nil
+}
+
+// Initialize sends the initialize request
+func (wc *WebSocketClient) Initialize(ctx context.Context) (*protocol.InitializeResult, error) {
+	if err := wc.CheckConnected(); err != nil {
+		return nil, err
+	}
+
+	var result protocol.InitializeResult
+	if err := wc.sendRequest(ctx, protocol.MethodInitialize, newInitializeRequest(wc.GetConfig()), &result); err != nil {
+		return nil, err
+	}
+
+	wc.SetInitialized(true)
+	wc.SetServerInfo(&result.ServerInfo)
+
+	return &result, nil
+}
+
+// Ping sends a ping request
+func (wc *WebSocketClient) Ping(ctx context.Context) error {
+	if err := wc.CheckConnected(); err != nil {
+		return err
+	}
+
+	var result interface{}
+	return wc.sendRequest(ctx, protocol.MethodPing, nil, &result)
+}
+
+// ListTools lists available tools
+func (wc *WebSocketClient) ListTools(ctx context.Context, cursor string) (*protocol.ListToolsResult, error) {
+	if err := wc.CheckInitialized(); err != nil {
+		return nil, err
+	}
+
+	var result protocol.ListToolsResult
+	if err := wc.sendRequest(ctx, protocol.MethodListTools, protocol.ListToolsRequest{Cursor: cursor}, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// CallTool calls a tool with given arguments
+func (wc *WebSocketClient) CallTool(ctx context.Context, name string, arguments map[string]interface{}) (*protocol.ToolCallResult, error) {
+	if err := wc.CheckInitialized(); err != nil {
+		return nil, err
+	}
+
+	req := protocol.CallToolRequest{
+		Name:      name,
+		Arguments: arguments,
+	}
+
+	var result protocol.ToolCallResult
+	if err := wc.sendRequest(ctx, protocol.MethodCallTool, req, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// sendRequest sends a JSON-RPC request and waits for response
+func (wc *WebSocketClient) sendRequest(ctx context.Context, method string, params interface{}, result interface{}) error {
+	// Generate request ID
+	id := wc.requestID.Add(1)
+
+	// Create response channel
+	respCh := make(chan *protocol.JSONRPCResponse, 1)
+	wc.responseMu.Lock()
+	wc.responseCh[id] = respCh
+	wc.responseMu.Unlock()
+
+	// Cleanup channel when done
+	defer func() {
+		wc.responseMu.Lock()
+		delete(wc.responseCh, id)
+		wc.responseMu.Unlock()
+	}()
+
+	request := protocol.JSONRPCRequest{
+		JSONRPC: protocol.JSONRPCVersion,
+		ID:      id,
+		Method:  method,
+		Params:  params,
+	}
+
+	// Send request
+	wc.connMu.Lock()
+	if wc.conn == nil {
+		wc.connMu.Unlock()
+		return protocol.NewConnectionError("connection closed", nil)
+	}
+	if err := websocket.JSON.Send(wc.conn, request); err != nil {
+		wc.connMu.Unlock()
+		return protocol.NewConnectionError("failed to send request", map[string]string{
+			"error":  err.Error(),
+			"method": method,
+		})
+	}
+	done := wc.done
+	wc.connMu.Unlock()
+
+	// Wait for response with timeout
+	timeout := wc.GetConfig().Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	select {
+	case resp := <-respCh:
+		if resp == nil {
+			return protocol.NewConnectionError("connection closed", nil)
+		}
+		return decodeResponse(resp, result)
+
+	case <-ctxWithTimeout.Done():
+		return protocol.NewConnectionTimeoutError("request timeout")
+
+	case <-done:
+		return protocol.NewConnectionError("connection closed", nil)
+	}
+}
+
+// readResponses reads responses of one connection until it fails, then
+// closes done. Notifications from the server are ignored.
+func (wc *WebSocketClient) readResponses(conn *websocket.Conn, done chan struct{}) {
+	defer close(done)
+
+	for {
+		var resp protocol.JSONRPCResponse
+		if err := websocket.JSON.Receive(conn, &resp); err != nil {
+			// Connection error, fail all pending requests
+			wc.responseMu.RLock()
+			for _, ch := range wc.responseCh {
+				select {
+				case ch <- nil:
+				default:
+				}
+			}
+			wc.responseMu.RUnlock()
+			return
+		}
+
+		id, ok := responseID(resp.ID)
+		if !ok {
+			continue
+		}
+
+		wc.responseMu.RLock()
+		ch, ok := wc.responseCh[id]
+		wc.responseMu.RUnlock()
+
+		if ok {
+			select {
+			case ch <- &resp:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"rocksdb-cli/internal/config"
+	"rocksdb-cli/internal/mcp/protocol"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+// Test WebSocket client full lifecycle
+func TestWebSocketClient_FullLifecycle(t *testing.T) {
+	server, _ := startMockMCPWebSocketServer(t)
+	client := NewWebSocketClient("ws-lifecycle", httpTestServerConfig(t, server))
+	ctx := context.Background()
+
+	require.NoError(t, client.Connect(ctx))
+	assert.True(t, client.IsConnected())
+	assert.Error(t, client.Connect(ctx), "double connect fails")
+
+	result, err := client.Initialize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.MCPProtocolVersion, result.ProtocolVersion)
+	assert.Equal(t, "test-tcp-server", client.GetServerInfo().Name)
+
+	require.NoError(t, client.Ping(ctx))
+
+	tools, err := client.ListTools(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, tools.Tools, 1)
+	assert.Equal(t, "echo", tools.Tools[0].Name)
+
+	toolResult, err := client.CallTool(ctx, "echo", map[string]interface{}{"message": "hello"})
+	require.NoError(t, err)
+	assert.False(t, toolResult.IsError)
+
+	// Unknown methods surface the JSON-RPC error
+	var unknown interface{}
+	assert.Error(t, client.sendRequest(ctx, "unknown/method", nil, &unknown))
+
+	require.NoError(t, client.Disconnect(ctx))
+	assert.False(t, client.IsConnected())
+}
+
+// Test WebSocket client noticing a closed connection
+func TestWebSocketClient_ConnectionLoss(t *testing.T) {
+	server, dropConnections := startMockMCPWebSocketServer(t)
+	client := NewWebSocketClient("ws-loss", httpTestServerConfig(t, server))
+	ctx := context.Background()
+
+	require.NoError(t, client.Connect(ctx))
+	_, err := client.Initialize(ctx)
+	require.NoError(t, err)
+
+	dropConnections()
+	assert.Error(t, client.Ping(ctx))
+
+	// The client reconnects after a disconnect
+	require.NoError(t, client.Disconnect(ctx))
+	require.NoError(t, client.Connect(ctx))
+	_, err = client.Initialize(ctx)
+	require.NoError(t, err)
+	assert.NoError(t, client.Ping(ctx))
+	client.Disconnect(ctx)
+}
+
+// Helper: Start a mock MCP WebSocket server; the returned function closes
+// its open connections from the server side
+func startMockMCPWebSocketServer(t *testing.T) (*httptest.Server, func()) {
+	var mu sync.Mutex
+	var conns []*websocket.Conn
+
+	handler := websocket.Handler(func(conn *websocket.Conn) {
+		defer conn.Close()
+		mu.Lock()
+		conns = append(conns, conn)
+		mu.Unlock()
+		for {
+			var req protocol.JSONRPCRequest
+			if err := websocket.JSON.Receive(conn, &req); err != nil {
+				return
+			}
+			if err := websocket.JSON.Send(conn, mockMCPResponse(req)); err != nil {
+				return
+			}
+		}
+	})
+
+	dropConnections := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, conn := range conns {
+			conn.Close()
+		}
+		conns = nil
+	}
+
+	// Served at the default path of rocksdb-mcp-server
+	mux := http.NewServeMux()
+	mux.Handle("/ws", handler)
+	server := httptest.NewServer(mux)
+	t.Cleanup(func() {
+		dropConnections()
+		server.Close()
+	})
+	return server, dropConnections
+}
+
+// Helper: Client configuration pointing at a test server
+func httpTestServerConfig(t *testing.T, server *httptest.Server) *config.MCPClientConfig {
+	host, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+
+	return &config.MCPClientConfig{
+		Name:      "ws",
+		Transport: "websocket",
+		Host:      host,
+		Port:      port,
+		Timeout:   5 * time.Second,
+	}
+}
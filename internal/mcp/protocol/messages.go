@@ -129,6 +129,9 @@ const (
 	MethodSubscribe         = "resources/subscribe"
 	MethodUnsubscribe       = "resources/unsubscribe"
 	MethodSetLoggingLevel   = "logging/setLevel"
+	MethodComplete          = "completion/complete"
+	MethodCancelled         = "notifications/cancelled"
+	MethodProgress          = "notifications/progress"
 	MethodResourcesListChanged = "notifications/resources/list_changed"
 	MethodToolsListChanged  = "notifications/tools/list_changed"
 	MethodPromptsListChanged = "notifications/prompts/list_changed"
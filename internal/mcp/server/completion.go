@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"rocksdb-cli/internal/completion"
+	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/mcp/protocol"
+)
+
+// Completion reference types of completion/complete. ref/tool is an
+// extension for clients that complete tool arguments the same way.
+const (
+	refPrompt   = "ref/prompt"
+	refResource = "ref/resource"
+	refTool     = "ref/tool"
+)
+
+// promptArgumentChoices are the fixed values of prompt arguments
+var promptArgumentChoices = map[string]map[string][]string{
+	"rocksdb_data_analysis":   {"analysis_type": {"overview", "patterns", "statistics"}},
+	"rocksdb_query_generator": {"operation": {"get", "prefix", "scan"}},
+	"rocksdb_troubleshooting": {"issue_type": {"data", "errors", "performance"}},
+}
+
+// CompletionManager serves completion/complete: column family names and key
+// prefixes for prompt arguments, resource template variables and tool
+// arguments. Suggestions come from the same code as REPL tab completion.
+type CompletionManager struct {
+	databases *Databases
+	config    *Config
+}
+
+// NewCompletionManager creates a new completion manager for a single database
+func NewCompletionManager(database db.KeyValueDB, config *Config) *CompletionManager {
+	return NewCompletionManagerWithDatabases(SingleDatabase(database, config.DatabasePath), config)
+}
+
+// NewCompletionManagerWithDatabases creates a new completion manager that
+// suggests from the database of the database argument or the session's default
+func NewCompletionManagerWithDatabases(databases *Databases, config *Config) *CompletionManager {
+	return &CompletionManager{
+		databases: databases,
+		config:    config,
+	}
+}
+
+// Register serves completion/complete on tm and advertises the completions
+// capability
+func (cm *CompletionManager) Register(tm *TransportManager) {
+	tm.HandleMethod(protocol.MethodComplete, cm.handleComplete)
+	tm.AdvertiseCapability("completions", struct{}{})
+}
+
+type completeParams struct {
+	Ref struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+		URI  string `json:"uri"`
+	} `json:"ref"`
+	Argument struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"argument"`
+	Context struct {
+		Arguments map[string]string `json:"arguments"`
+	} `json:"context"`
+}
+
+type completeResult struct {
+	Completion struct {
+		Values  []string `json:"values"`
+		Total   int      `json:"total,omitempty"`
+		HasMore bool     `json:"hasMore,omitempty"`
+	} `json:"completion"`
+}
+
+func (cm *CompletionManager) handleComplete(ctx context.Context, sessionID string, params json.RawMessage) (interface{}, error) {
+	var p completeParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, invalidParams("invalid params: %v", err)
+	}
+	switch p.Ref.Type {
+	case refPrompt, refTool:
+		if p.Ref.Name == "" {
+			return nil, invalidParams("ref.name is required")
+		}
+	case refResource:
+		if p.Ref.URI == "" {
+			return nil, invalidParams("ref.uri is required")
+		}
+	default:
+		return nil, invalidParams("unsupported ref type %q", p.Ref.Type)
+	}
+	if p.Argument.Name == "" {
+		return nil, invalidParams("argument.name is required")
+	}
+
+	database, _, err := cm.databases.ForSession(sessionID, p.Context.Arguments["database"])
+	if err != nil {
+		return nil, invalidParams("%v", err)
+	}
+	r, err := cm.complete(database, p)
+	if err != nil {
+		return nil, err
+	}
+
+	var result completeResult
+	result.Completion.Values = r.Values
+	result.Completion.Total = r.Total
+	result.Completion.HasMore = r.HasMore
+	return result, nil
+}
+
+// complete returns the suggestions of database for one argument. Arguments
+// that take no database names get none.
+func (cm *CompletionManager) complete(database db.KeyValueDB, p completeParams) (completion.Result, error) {
+	name, value := p.Argument.Name, p.Argument.Value
+
+	if p.Ref.Type == refPrompt {
+		if choices, ok := promptArgumentChoices[p.Ref.Name][name]; ok {
+			return completion.Match(choices, value), nil
+		}
+	}
+
+	switch name {
+	case "column_family", "cf":
+		return completion.ColumnFamilies(database, value)
+	case "name":
+		// The column family of a template or of the drop tool, but not a
+		// new column family
+		if p.Ref.Type == refTool && p.Ref.Name == "rocksdb_create_column_family" {
+			break
+		}
+		return completion.ColumnFamilies(database, value)
+	case "key", "prefix", "start_key", "end_key":
+		r, err := completion.KeyPrefixes(database, currentCF(p.Context.Arguments), value)
+		if errors.Is(err, db.ErrColumnFamilyNotFound) {
+			return completion.Match(nil, value), nil
+		}
+		return r, err
+	}
+	return completion.Match(nil, value), nil
+}
+
+// currentCF returns the column family already chosen for a completion
+func currentCF(arguments map[string]string) string {
+	for _, name := range []string{"column_family", "cf"} {
+		if cf := arguments[name]; cf != "" {
+			return cf
+		}
+	}
+	return "default"
+}
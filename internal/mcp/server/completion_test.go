@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// complete calls completion/complete with params and returns the result
+func complete(t *testing.T, cm *CompletionManager, params string) (completeResult, error) {
+	t.Helper()
+	result, err := cm.handleComplete(context.Background(), "session", json.RawMessage(params))
+	if err != nil {
+		return completeResult{}, err
+	}
+	return result.(completeResult), nil
+}
+
+func TestCompletionManager_Complete(t *testing.T) {
+	mockDB := NewMockKeyValueDB()
+	require.NoError(t, mockDB.CreateCF("users"))
+	require.NoError(t, mockDB.CreateCF("user_events"))
+	for _, key := range []string{"user:1:name", "user:2:name", "order:1"} {
+		require.NoError(t, mockDB.PutCF("users", key, "v"))
+	}
+	cm := NewCompletionManager(mockDB, DefaultConfig())
+
+	result, err := complete(t, cm, `{"ref":{"type":"ref/resource","uri":"rocksdb://data/{column_family}/{key}"},
+		"argument":{"name":"column_family","value":"user"}}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user_events", "users"}, result.Completion.Values)
+
+	result, err = complete(t, cm, `{"ref":{"type":"ref/resource","uri":"rocksdb://data/{column_family}/{key}"},
+		"argument":{"name":"key","value":"us"},"context":{"arguments":{"column_family":"users"}}}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user:"}, result.Completion.Values)
+
+	result, err = complete(t, cm, `{"ref":{"type":"ref/tool","name":"rocksdb_prefix_scan"},
+		"argument":{"name":"prefix","value":"x"},"context":{"arguments":{"column_family":"missing"}}}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, result.Completion.Values, "unknown column families have no keys")
+
+	result, err = complete(t, cm, `{"ref":{"type":"ref/tool","name":"rocksdb_create_column_family"},
+		"argument":{"name":"name","value":"u"}}`)
+	require.NoError(t, err)
+	assert.Empty(t, result.Completion.Values, "new column family names are not completed")
+
+	result, err = complete(t, cm, `{"ref":{"type":"ref/prompt","name":"rocksdb_data_analysis"},
+		"argument":{"name":"analysis_type","value":"p"}}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"patterns"}, result.Completion.Values)
+}
+
+func TestCompletionManager_InvalidParams(t *testing.T) {
+	cm := NewCompletionManager(NewMockKeyValueDB(), DefaultConfig())
+
+	for _, params := range []string{
+		`{"ref":{"type":"ref/unknown","name":"x"},"argument":{"name":"cf","value":""}}`,
+		`{"ref":{"type":"ref/prompt"},"argument":{"name":"cf","value":""}}`,
+		`{"ref":{"type":"ref/resource","uri":"rocksdb://stats"},"argument":{"value":""}}`,
+		`not json`,
+	} {
+		_, err := complete(t, cm, params)
+		var rpcErr *RPCError
+		require.ErrorAs(t, err, &rpcErr, params)
+	}
+}
+
+// TestStreamableHTTPTransport_Completion tests that initialize advertises
+// completions and completion/complete is served
+func TestStreamableHTTPTransport_Completion(t *testing.T) {
+	baseURL, tm, _, _ := startHTTPTestTransport(t, transportStreamableHTTP, 10)
+	NewCompletionManager(NewMockKeyValueDB(), DefaultConfig()).Register(tm)
+	url := baseURL + defaultStreamableHTTPPath
+
+	resp := postMCP(t, url, "", initializeRequest)
+	var initialize struct {
+		Result struct {
+			Capabilities map[string]interface{} `json:"capabilities"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&initialize))
+	resp.Body.Close()
+	assert.Contains(t, initialize.Result.Capabilities, "completions")
+	assert.Contains(t, initialize.Result.Capabilities, "tools", "existing capabilities are kept")
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	require.NotEmpty(t, sessionID)
+
+	resp = postMCP(t, url, sessionID, `{"jsonrpc":"2.0","id":2,"method":"completion/complete",
+		"params":{"ref":{"type":"ref/resource","uri":"rocksdb://column-family/{name}"},"argument":{"name":"name","value":"def"}}}`)
+	var response struct {
+		Result completeResult `json:"result"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	resp.Body.Close()
+	assert.Equal(t, []string{"default"}, response.Result.Completion.Values)
+}
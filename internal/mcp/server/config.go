@@ -32,6 +32,11 @@ type Config struct {
 
 	// Resource configuration
 	EnableResources bool
+	PageSize        int // resources per listing page and default page of resource reads
+
+	// Gateway configuration
+	Gateway             bool          // re-export the tools of the MCP clients
+	GatewaySyncInterval time.Duration // how often remote tools are re-synced
 
 	// Logging configuration
 	LogLevel string
@@ -39,15 +44,19 @@ type Config struct {
 
 // TransportConfig defines the transport layer configuration
 type TransportConfig struct {
-	Type string // stdio, tcp, websocket, unix
+	Type string // stdio, tcp, websocket, streamable-http, unix
 
 	// For TCP transport
 	Host string
 	Port int
 
-	// For WebSocket transport
+	// For WebSocket and Streamable HTTP transports; default /ws or /mcp
 	Path string
 
+	// Origins of web pages allowed besides the listen host, such as
+	// https://app.example.com; requests without an Origin are always allowed
+	AllowedOrigins []string
+
 	// For Unix Socket transport
 	SocketPath string
 
@@ -69,17 +78,41 @@ func NewConfigFromUnified(cfg *config.Config) *Config {
 		EnabledTools:          []string{},
 		DisabledTools:         []string{},
 		EnableResources:       true,
+		PageSize:              DefaultPageSize,
+		GatewaySyncInterval:   DefaultGatewaySyncInterval,
 		LogLevel:              cfg.LogLevel,
 	}
 
+	// Write tools are offered if any hosted database is writable
+	if cfg.MCPServer != nil {
+		for _, database := range cfg.MCPServer.Databases {
+			serverConfig.ReadOnly = serverConfig.ReadOnly && database.ReadOnly
+		}
+		serverConfig.Gateway = cfg.MCPServer.Gateway
+		if cfg.MCPServer.GatewaySyncInterval > 0 {
+			serverConfig.GatewaySyncInterval = cfg.MCPServer.GatewaySyncInterval
+		}
+	}
+
 	// Convert MCP server transport config if enabled
 	if cfg.MCPServer != nil && cfg.MCPServer.Enabled {
 		serverConfig.Transport = TransportConfig{
-			Type:       cfg.MCPServer.Transport.Type,
-			Host:       cfg.MCPServer.Transport.Host,
-			Port:       cfg.MCPServer.Transport.Port,
-			SocketPath: cfg.MCPServer.Transport.SocketPath,
-			Timeout:    30 * time.Second,
+			Type:           cfg.MCPServer.Transport.Type,
+			Host:           cfg.MCPServer.Transport.Host,
+			Port:           cfg.MCPServer.Transport.Port,
+			Path:           cfg.MCPServer.Transport.Path,
+			AllowedOrigins: cfg.MCPServer.Transport.AllowedOrigins,
+			SocketPath:     cfg.MCPServer.Transport.SocketPath,
+			Timeout:        30 * time.Second,
+		}
+		if cfg.MCPServer.MaxConcurrentSessions > 0 {
+			serverConfig.MaxConcurrentSessions = cfg.MCPServer.MaxConcurrentSessions
+		}
+		if cfg.MCPServer.SessionTimeout > 0 {
+			serverConfig.SessionTimeout = cfg.MCPServer.SessionTimeout
+		}
+		if cfg.MCPServer.PageSize > 0 {
+			serverConfig.PageSize = cfg.MCPServer.PageSize
 		}
 	} else {
 		// Default to stdio
@@ -110,6 +143,8 @@ func DefaultConfig() *Config {
 		EnabledTools:          []string{},
 		DisabledTools:         []string{},
 		EnableResources:       true,
+		PageSize:              DefaultPageSize,
+		GatewaySyncInterval:   DefaultGatewaySyncInterval,
 		LogLevel:              "info",
 	}
 }
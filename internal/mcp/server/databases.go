@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"rocksdb-cli/internal/db"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// DefaultDatabase is the name of the database given by the database section
+// of the configuration or --db
+const DefaultDatabase = "default"
+
+// DatabaseOpener opens the named database
+type DatabaseOpener func(name string) (db.KeyValueDB, error)
+
+// DatabaseInfo describes a hosted database
+type DatabaseInfo struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	ReadOnly bool   `json:"read_only"`
+	Open     bool   `json:"open"`
+	Current  bool   `json:"current"` // the session's default database
+}
+
+type hostedDatabase struct {
+	path     string
+	readOnly bool
+	db       db.KeyValueDB
+}
+
+// Databases hosts the named databases of the MCP server. A database is
+// opened when first used, and each session selects its own default database
+// with open_database.
+type Databases struct {
+	mu          sync.Mutex
+	entries     map[string]*hostedDatabase
+	defaultName string
+	sessions    map[string]string // session ID -> database name
+	open        DatabaseOpener
+}
+
+// NewDatabases creates a set of databases opened with open. defaultName is
+// used by sessions that have not selected a database.
+func NewDatabases(defaultName string, open DatabaseOpener) *Databases {
+	return &Databases{
+		entries:     make(map[string]*hostedDatabase),
+		defaultName: defaultName,
+		sessions:    make(map[string]string),
+		open:        open,
+	}
+}
+
+// SingleDatabase hosts database alone as DefaultDatabase
+func SingleDatabase(database db.KeyValueDB, path string) *Databases {
+	d := NewDatabases(DefaultDatabase, nil)
+	d.AddOpen(DefaultDatabase, path, database)
+	return d
+}
+
+// Add declares a database to open when first used
+func (d *Databases) Add(name, path string, readOnly bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.entries[name] = &hostedDatabase{path: path, readOnly: readOnly}
+}
+
+// AddOpen adds an open database
+func (d *Databases) AddOpen(name, path string, database db.KeyValueDB) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.entries[name] = &hostedDatabase{path: path, readOnly: database.IsReadOnly(), db: database}
+}
+
+// Get returns the named database, opening it if needed
+func (d *Databases) Get(name string) (db.KeyValueDB, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.get(name)
+}
+
+func (d *Databases) get(name string) (db.KeyValueDB, error) {
+	entry, ok := d.entries[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown database '%s'", name)
+	}
+	if entry.db == nil {
+		if d.open == nil {
+			return nil, fmt.Errorf("database '%s' cannot be opened", name)
+		}
+		database, err := d.open(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open database '%s': %w", name, err)
+		}
+		entry.db = database
+	}
+	return entry.db, nil
+}
+
+// ForSession returns the named database, or the session's default database
+// if name is empty, and the name used
+func (d *Databases) ForSession(sessionID, name string) (db.KeyValueDB, string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if name == "" {
+		name = d.sessionDefault(sessionID)
+	}
+	database, err := d.get(name)
+	return database, name, err
+}
+
+func (d *Databases) sessionDefault(sessionID string) string {
+	if name, ok := d.sessions[sessionID]; ok && sessionID != "" {
+		return name
+	}
+	return d.defaultName
+}
+
+// Select opens the named database and makes it the session's default
+func (d *Databases) Select(sessionID, name string) (DatabaseInfo, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, err := d.get(name); err != nil {
+		return DatabaseInfo{}, err
+	}
+	if sessionID != "" {
+		d.sessions[sessionID] = name
+	}
+	return d.info(name, true), nil
+}
+
+// List describes the databases, marking the session's default as current
+func (d *Databases) List(sessionID string) []DatabaseInfo {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	current := d.sessionDefault(sessionID)
+	infos := make([]DatabaseInfo, 0, len(d.entries))
+	for name := range d.entries {
+		infos = append(infos, d.info(name, name == current))
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+func (d *Databases) info(name string, current bool) DatabaseInfo {
+	entry := d.entries[name]
+	return DatabaseInfo{
+		Name:     name,
+		Path:     entry.path,
+		ReadOnly: entry.readOnly,
+		Open:     entry.db != nil,
+		Current:  current,
+	}
+}
+
+// Path returns the path of the named database, or "" if it is not hosted
+func (d *Databases) Path(name string) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if entry, ok := d.entries[name]; ok {
+		return entry.path
+	}
+	return ""
+}
+
+// RemoveSession forgets the database selected by a session
+func (d *Databases) RemoveSession(sessionID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.sessions, sessionID)
+}
+
+// Register forgets the selection of sessions of tm when they end
+func (d *Databases) Register(tm *TransportManager) {
+	tm.OnSessionClosed(d.RemoveSession)
+}
+
+// Close closes the opened databases
+func (d *Databases) Close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, entry := range d.entries {
+		if entry.db != nil {
+			entry.db.Close()
+			entry.db = nil
+		}
+	}
+}
+
+// contextSessionID returns the ID of the MCP session serving ctx, or "" if none
+func contextSessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
@@ -0,0 +1,196 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"rocksdb-cli/internal/db"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newTestDatabases hosts an open default database and a read-only
+// "analytics" database opened on first use
+func newTestDatabases(t *testing.T) (*Databases, map[string]*MockKeyValueDB, *int) {
+	t.Helper()
+	mocks := map[string]*MockKeyValueDB{
+		DefaultDatabase: NewMockKeyValueDB(),
+		"analytics":     NewMockKeyValueDB(),
+	}
+	mocks[DefaultDatabase].PutCF("default", "k", "from default")
+	mocks["analytics"].PutCF("default", "k", "from analytics")
+	mocks["analytics"].SetReadOnly(true)
+
+	opened := 0
+	databases := NewDatabases(DefaultDatabase, func(name string) (db.KeyValueDB, error) {
+		mock, ok := mocks[name]
+		if !ok {
+			return nil, errors.New("no such mock")
+		}
+		opened++
+		return mock, nil
+	})
+	databases.AddOpen(DefaultDatabase, "/data/main", mocks[DefaultDatabase])
+	databases.Add("analytics", "/data/analytics", true)
+	databases.Add("broken", "/data/broken", false)
+	return databases, mocks, &opened
+}
+
+func TestDatabases_OpenLazily(t *testing.T) {
+	databases, mocks, opened := newTestDatabases(t)
+
+	infos := databases.List("")
+	if len(infos) != 3 || infos[0].Name != "analytics" || infos[1].Name != "broken" || infos[2].Name != DefaultDatabase {
+		t.Fatalf("Expected sorted databases, got %+v", infos)
+	}
+	if infos[0].Open || !infos[2].Open || !infos[2].Current {
+		t.Errorf("Unexpected flags: %+v", infos)
+	}
+
+	for i := 0; i < 2; i++ {
+		database, err := databases.Get("analytics")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if database != db.KeyValueDB(mocks["analytics"]) {
+			t.Error("Expected the analytics database")
+		}
+	}
+	if *opened != 1 {
+		t.Errorf("Expected analytics to be opened once, got %d", *opened)
+	}
+
+	if _, err := databases.Get("broken"); err == nil || !strings.Contains(err.Error(), "failed to open database 'broken'") {
+		t.Errorf("Expected open error, got %v", err)
+	}
+	if _, err := databases.Get("missing"); err == nil || !strings.Contains(err.Error(), "unknown database 'missing'") {
+		t.Errorf("Expected unknown database error, got %v", err)
+	}
+}
+
+func TestDatabases_SessionSelection(t *testing.T) {
+	databases, mocks, _ := newTestDatabases(t)
+
+	if _, err := databases.Select("s1", "analytics"); err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if _, err := databases.Select("s1", "missing"); err == nil {
+		t.Error("Expected error selecting an unknown database")
+	}
+
+	database, name, _ := databases.ForSession("s1", "")
+	if name != "analytics" || database != db.KeyValueDB(mocks["analytics"]) {
+		t.Errorf("Expected s1 to use analytics, got %s", name)
+	}
+	if _, name, _ = databases.ForSession("s2", ""); name != DefaultDatabase {
+		t.Errorf("Expected s2 to use the default database, got %s", name)
+	}
+	if _, name, _ = databases.ForSession("s1", DefaultDatabase); name != DefaultDatabase {
+		t.Errorf("Expected an explicit name to win, got %s", name)
+	}
+
+	for _, info := range databases.List("s1") {
+		if info.Current != (info.Name == "analytics") {
+			t.Errorf("Unexpected current flag: %+v", info)
+		}
+	}
+
+	databases.RemoveSession("s1")
+	if _, name, _ = databases.ForSession("s1", ""); name != DefaultDatabase {
+		t.Errorf("Expected the default database after the session ended, got %s", name)
+	}
+}
+
+func TestToolManager_DatabaseArgument(t *testing.T) {
+	databases, mocks, _ := newTestDatabases(t)
+	tm := NewToolManagerWithDatabases(databases, DefaultConfig())
+
+	mcpServer := server.NewMCPServer("Test Server", "1.0.0")
+	ctx := mcpServer.WithContext(context.Background(), newConnSession("s1", func([]byte) error { return nil }))
+
+	call := func(handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := handler(ctx, request)
+		if err != nil {
+			t.Fatalf("Tool returned error: %v", err)
+		}
+		return result
+	}
+	text := func(result *mcp.CallToolResult) string {
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	if got := text(call(tm.handleGetTool, map[string]any{"key": "k"})); !strings.Contains(got, "from default") {
+		t.Errorf("Expected the default database, got %q", got)
+	}
+	if got := text(call(tm.handleGetTool, map[string]any{"key": "k", "database": "analytics"})); !strings.Contains(got, "from analytics") {
+		t.Errorf("Expected the analytics database, got %q", got)
+	}
+
+	// Writes to a read-only database are refused
+	result := call(tm.handlePutTool, map[string]any{"key": "k", "value": "v", "database": "analytics"})
+	if !result.IsError || !strings.Contains(text(result), "database 'analytics' is read-only") {
+		t.Errorf("Expected read-only error, got %+v", result.Content)
+	}
+	if value, _ := mocks["analytics"].GetCF("default", "k"); value != "from analytics" {
+		t.Errorf("Read-only database was modified: %q", value)
+	}
+
+	// Opening a database makes it the session's default
+	result = call(tm.handleOpenDatabaseTool, map[string]any{"name": "analytics"})
+	if result.IsError || !strings.Contains(text(result), "Opened database 'analytics' at /data/analytics (read-only, open, current)") {
+		t.Errorf("Unexpected open result: %+v", result.Content)
+	}
+	if got := text(call(tm.handleGetTool, map[string]any{"key": "k"})); !strings.Contains(got, "from analytics") {
+		t.Errorf("Expected the selected database, got %q", got)
+	}
+	if got := text(call(tm.handleListDatabasesTool, nil)); !strings.Contains(got, "- analytics: /data/analytics (read-only, open, current)") ||
+		!strings.Contains(got, "- default: /data/main (open)\n") {
+		t.Errorf("Unexpected listing: %q", got)
+	}
+
+	result = call(tm.handleOpenDatabaseTool, map[string]any{"name": "missing"})
+	if !result.IsError {
+		t.Error("Expected error opening an unknown database")
+	}
+}
+
+func TestPromptsAndCompletion_SessionDatabase(t *testing.T) {
+	databases, mocks, _ := newTestDatabases(t)
+	mocks["analytics"].SetReadOnly(false)
+	mocks["analytics"].CreateCF("events")
+	mocks["analytics"].SetReadOnly(true)
+	if _, err := databases.Select("session", "analytics"); err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+
+	mcpServer := server.NewMCPServer("Test Server", "1.0.0")
+	ctx := mcpServer.WithContext(context.Background(), newConnSession("session", func([]byte) error { return nil }))
+	pm := NewPromptManagerWithDatabases(databases, DefaultConfig())
+	request := mcp.GetPromptRequest{}
+	request.Params.Arguments = map[string]string{"issue_type": "data"}
+	result, err := pm.handleTroubleshootingPrompt(ctx, request)
+	if err != nil {
+		t.Fatalf("Prompt failed: %v", err)
+	}
+	text := result.Messages[0].Content.(mcp.TextContent).Text
+	if !strings.Contains(text, "Database path: /data/analytics") || !strings.Contains(text, "events") {
+		t.Errorf("Expected the session's database in the prompt, got %q", text)
+	}
+
+	cm := NewCompletionManagerWithDatabases(databases, DefaultConfig())
+	r, err := complete(t, cm, `{"ref":{"type":"ref/tool","name":"rocksdb_scan"},"argument":{"name":"column_family","value":"ev"}}`)
+	if err != nil || len(r.Completion.Values) != 1 || r.Completion.Values[0] != "events" {
+		t.Errorf("Expected column families of the session's database, got %v, %v", r.Completion.Values, err)
+	}
+	r, err = complete(t, cm, `{"ref":{"type":"ref/tool","name":"rocksdb_scan"},"argument":{"name":"column_family","value":"ev"},
+		"context":{"arguments":{"database":"default"}}}`)
+	if err != nil || len(r.Completion.Values) != 0 {
+		t.Errorf("Expected the database argument to win, got %v, %v", r.Completion.Values, err)
+	}
+}
@@ -0,0 +1,268 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// MethodHandler serves a JSON-RPC method that mcp-go does not implement. The
+// returned value becomes the result of the response.
+type MethodHandler func(ctx context.Context, sessionID string, params json.RawMessage) (interface{}, error)
+
+// RPCError is an error with a JSON-RPC error code, returned by a MethodHandler
+type RPCError struct {
+	Code    int
+	Message string
+}
+
+func (e *RPCError) Error() string {
+	return e.Message
+}
+
+// invalidParams returns an RPCError for bad request parameters
+func invalidParams(format string, args ...interface{}) error {
+	return &RPCError{Code: mcp.INVALID_PARAMS, Message: fmt.Sprintf(format, args...)}
+}
+
+// methodRouter holds the methods served by the transport layer and the
+// capabilities they add to the initialize result
+type methodRouter struct {
+	mu           sync.RWMutex
+	handlers     map[string]MethodHandler
+	capabilities map[string]interface{}
+}
+
+// handler returns the handler of method, if any
+func (r *methodRouter) handler(method string) (MethodHandler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	h, ok := r.handlers[method]
+	return h, ok
+}
+
+// has reports whether method has a handler
+func (r *methodRouter) has(method string) bool {
+	_, ok := r.handler(method)
+	return ok
+}
+
+// HandleMethod serves method with h instead of the MCP server. Register
+// handlers before starting the transport.
+func (tm *TransportManager) HandleMethod(method string, h MethodHandler) {
+	tm.methods.mu.Lock()
+	defer tm.methods.mu.Unlock()
+	if tm.methods.handlers == nil {
+		tm.methods.handlers = make(map[string]MethodHandler)
+	}
+	tm.methods.handlers[method] = h
+}
+
+// AdvertiseCapability adds a server capability, such as one for a method
+// served with HandleMethod, to the initialize result
+func (tm *TransportManager) AdvertiseCapability(name string, value interface{}) {
+	tm.methods.mu.Lock()
+	defer tm.methods.mu.Unlock()
+	if tm.methods.capabilities == nil {
+		tm.methods.capabilities = make(map[string]interface{})
+	}
+	tm.methods.capabilities[name] = value
+}
+
+// withCapabilities returns an initialize result with the advertised
+// capabilities added
+func (r *methodRouter) withCapabilities(result interface{}) interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.capabilities) == 0 {
+		return result
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return result
+	}
+	var patched map[string]interface{}
+	if err := json.Unmarshal(data, &patched); err != nil {
+		return result
+	}
+	capabilities, _ := patched["capabilities"].(map[string]interface{})
+	if capabilities == nil {
+		capabilities = make(map[string]interface{})
+	}
+	for name, value := range r.capabilities {
+		capabilities[name] = value
+	}
+	patched["capabilities"] = capabilities
+	return patched
+}
+
+// hasCapabilities reports whether any capability was advertised
+func (r *methodRouter) hasCapabilities() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.capabilities) > 0
+}
+
+// OnSessionClosed registers fn to run when any session ends
+func (tm *TransportManager) OnSessionClosed(fn func(sessionID string)) {
+	tm.sessions.OnSessionClosed(fn)
+}
+
+// handleMessage answers one JSON-RPC message from sessionID, or returns nil
+// for notifications
+func (tm *TransportManager) handleMessage(ctx context.Context, sessionID string, message []byte) mcp.JSONRPCMessage {
+	var request struct {
+		ID     *mcp.RequestId  `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(message, &request); err != nil {
+		return mcp.NewJSONRPCError(mcp.NewRequestId(nil), mcp.PARSE_ERROR, "Parse error", nil)
+	}
+
+	if request.ID != nil && request.Method != string(mcp.MethodInitialize) {
+		var done func()
+		ctx, done = tm.requests.track(ctx, sessionID, *request.ID)
+		defer done()
+	}
+
+	h, ok := tm.methods.handler(request.Method)
+	if !ok {
+		response := tm.server.HandleMessage(ctx, message)
+		if r, isResult := response.(mcp.JSONRPCResponse); isResult && request.Method == string(mcp.MethodInitialize) {
+			r.Result = tm.methods.withCapabilities(r.Result)
+			return r
+		}
+		return response
+	}
+
+	result, err := h(ctx, sessionID, request.Params)
+	if request.ID == nil {
+		return nil
+	}
+	if err != nil {
+		code := mcp.INTERNAL_ERROR
+		var rpcErr *RPCError
+		if errors.As(err, &rpcErr) {
+			code = rpcErr.Code
+		}
+		return mcp.NewJSONRPCError(*request.ID, code, err.Error(), nil)
+	}
+	if result == nil {
+		result = struct{}{}
+	}
+	return mcp.JSONRPCResponse{JSONRPC: mcp.JSONRPC_VERSION, ID: *request.ID, Result: result}
+}
+
+// connSession is the mcp-go client session of a connection that carries one
+// JSON-RPC message per frame or line
+type connSession struct {
+	id            string
+	write         func([]byte) error
+	writeMu       sync.Mutex
+	notifications chan mcp.JSONRPCNotification
+	initialized   atomic.Bool
+}
+
+func newConnSession(id string, write func([]byte) error) *connSession {
+	return &connSession{
+		id:            id,
+		write:         write,
+		notifications: make(chan mcp.JSONRPCNotification, 100),
+	}
+}
+
+func (s *connSession) SessionID() string {
+	return s.id
+}
+
+func (s *connSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+func (s *connSession) Initialize() {
+	s.initialized.Store(true)
+}
+
+func (s *connSession) Initialized() bool {
+	return s.initialized.Load()
+}
+
+// send writes one message
+func (s *connSession) send(message interface{}) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.write(data)
+}
+
+// serveSession runs session until receive fails or the session is closed.
+// Requests are handled concurrently, so responses may arrive out of order;
+// notifications for the session are sent as they occur. closeConn must
+// unblock receive.
+func (tm *TransportManager) serveSession(ctx context.Context, session *connSession, receive func() ([]byte, error), closeConn func()) {
+	defer tm.sessions.Close(session.id)
+	tm.sessions.OnClose(session.id, closeConn)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if err := tm.server.RegisterSession(ctx, session); err != nil {
+		log.Printf("Failed to register MCP session: %v", err)
+		return
+	}
+	defer tm.server.UnregisterSession(ctx, session.id)
+	ctx = tm.server.WithContext(ctx, session)
+
+	// Forward notifications until the session ends
+	go func() {
+		for {
+			select {
+			case n := <-session.notifications:
+				if err := session.send(n); err != nil {
+					closeConn()
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var requests sync.WaitGroup
+	defer requests.Wait()
+
+	for {
+		message, err := receive()
+		if err != nil {
+			return
+		}
+		if len(message) == 0 {
+			continue
+		}
+		if !tm.sessions.Acquire(session.id) {
+			return // expired while waiting for the message
+		}
+
+		requests.Add(1)
+		go func() {
+			defer requests.Done()
+			defer tm.sessions.Release(session.id)
+
+			if response := tm.handleMessage(ctx, session.id, message); response != nil {
+				session.send(response)
+			}
+		}()
+	}
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"rocksdb-cli/internal/mcp/protocol"
+	"rocksdb-cli/internal/mcp/tools"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// DefaultGatewaySyncInterval is how often a gateway re-syncs remote tools
+const DefaultGatewaySyncInterval = 30 * time.Second
+
+// Gateway re-exports the tools of a tools.Registry, including the namespaced
+// tools of remote MCP servers, next to the server's own tools. Calls to
+// remote tools are forwarded through the RemoteProxy.
+type Gateway struct {
+	registry *tools.Registry
+	proxy    *tools.RemoteProxy
+
+	mu       sync.Mutex
+	server   *server.MCPServer
+	exported map[string]protocol.Tool // namespaced name -> tool
+}
+
+// NewGateway creates a gateway for the tools of registry
+func NewGateway(registry *tools.Registry, proxy *tools.RemoteProxy) *Gateway {
+	return &Gateway{
+		registry: registry,
+		proxy:    proxy,
+		exported: make(map[string]protocol.Tool),
+	}
+}
+
+// Register adds the registry's tools to s and keeps them in sync as the
+// proxy syncs remote tools. s sends tools/list_changed to its clients
+// whenever the exported tools change.
+func (g *Gateway) Register(s *server.MCPServer) {
+	g.mu.Lock()
+	g.server = s
+	g.mu.Unlock()
+
+	g.proxy.OnToolsChanged(func(string) { g.Sync() })
+	g.Sync()
+}
+
+// Sync adds the registry's new and changed tools to the server and removes
+// the tools that are gone
+func (g *Gateway) Sync() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.server == nil {
+		return
+	}
+
+	current := make(map[string]protocol.Tool)
+	for _, tool := range g.registry.ListTools("") {
+		current[tool.Name] = tool
+	}
+
+	var added []server.ServerTool
+	for name, tool := range current {
+		if previous, ok := g.exported[name]; ok && reflect.DeepEqual(previous, tool) {
+			continue
+		}
+		added = append(added, server.ServerTool{Tool: gatewayTool(tool), Handler: g.handler(name)})
+	}
+	var removed []string
+	for name := range g.exported {
+		if _, ok := current[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+
+	if len(added) > 0 {
+		g.server.AddTools(added...)
+	}
+	if len(removed) > 0 {
+		g.server.DeleteTools(removed...)
+	}
+	g.exported = current
+}
+
+// gatewayTool converts a registry tool to an mcp-go tool, keeping its input
+// schema as is
+func gatewayTool(tool protocol.Tool) mcp.Tool {
+	schema := tool.InputSchema
+	if schema == nil {
+		schema = map[string]interface{}{"type": "object"}
+	}
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		raw = json.RawMessage(`{"type":"object"}`)
+	}
+	return mcp.NewToolWithRawSchema(tool.Name, tool.Description, raw)
+}
+
+// handler executes a local registry tool, or forwards a remote one
+func (g *Gateway) handler(name string) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+
+		var result *protocol.ToolCallResult
+		var err error
+		if strings.HasPrefix(name, "local.") {
+			result, err = g.registry.Execute(ctx, name, arguments)
+		} else {
+			result, err = g.proxy.Execute(ctx, name, arguments)
+		}
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return gatewayResult(result), nil
+	}
+}
+
+// gatewayResult converts the result of a registry tool to an mcp-go result
+func gatewayResult(result *protocol.ToolCallResult) *mcp.CallToolResult {
+	converted := &mcp.CallToolResult{IsError: result.IsError}
+	for _, content := range result.Content {
+		switch content.Type {
+		case "image":
+			converted.Content = append(converted.Content, mcp.NewImageContent(content.Data, content.MIMEType))
+		default:
+			converted.Content = append(converted.Content, mcp.NewTextContent(content.Text))
+		}
+	}
+	return converted
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"rocksdb-cli/internal/config"
+	"rocksdb-cli/internal/mcp/client"
+	"rocksdb-cli/internal/mcp/protocol"
+	"rocksdb-cli/internal/mcp/tools"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+// startRemoteMCPServer serves remote over the TCP transport and returns its
+// port
+func startRemoteMCPServer(t *testing.T, remote *server.MCPServer) int {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	tm := NewTransportManager(&Config{
+		Transport: TransportConfig{Type: "tcp", Host: "localhost", Port: port, Timeout: 5 * time.Second},
+	}, remote)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go tm.StartTransport(ctx)
+
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}, 2*time.Second, 20*time.Millisecond)
+	return port
+}
+
+func echoTool(name string) server.ServerTool {
+	return server.ServerTool{
+		Tool: mcp.NewTool(name, mcp.WithString("message", mcp.Required())),
+		Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(name + ": " + request.GetString("message", "")), nil
+		},
+	}
+}
+
+func TestGateway_ForwardsRemoteTools(t *testing.T) {
+	remote := server.NewMCPServer("remote", "1.0.0", server.WithToolCapabilities(true))
+	remote.AddTools(echoTool("echo"), echoTool("shutdown"))
+	port := startRemoteMCPServer(t, remote)
+
+	manager := client.NewManager(&config.Config{
+		MCPClients: config.MCPClientsConfig{
+			"remote": {
+				Name:          "remote",
+				Enabled:       true,
+				Transport:     "tcp",
+				Host:          "localhost",
+				Port:          port,
+				Timeout:       5 * time.Second,
+				DisabledTools: []string{"shutdown"},
+			},
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, manager.StartAll(ctx))
+	defer manager.Shutdown(context.Background())
+
+	registry := tools.NewRegistry()
+	require.NoError(t, registry.RegisterLocal(protocol.Tool{Name: "version", InputSchema: map[string]interface{}{"type": "object"}},
+		func(ctx context.Context, arguments map[string]interface{}) (*protocol.ToolCallResult, error) {
+			return &protocol.ToolCallResult{Content: []protocol.Content{{Type: "text", Text: "1.0.0"}}}, nil
+		}))
+	proxy := tools.NewRemoteProxy(registry, manager)
+	require.NoError(t, proxy.SyncAllTools(ctx))
+
+	baseURL, tm, _, _ := startHTTPTestTransport(t, transportWebSocket, 10)
+	NewGateway(registry, proxy).Register(tm.server)
+	proxy.EnableAutoSync()
+	go proxy.Run(ctx, 20*time.Millisecond)
+
+	ws := dialWebSocket(t, baseURL)
+	defer ws.Close()
+	ws.SetDeadline(time.Now().Add(5 * time.Second))
+
+	var response map[string]interface{}
+	require.NoError(t, websocket.Message.Send(ws, initializeRequest))
+	require.NoError(t, websocket.JSON.Receive(ws, &response))
+	require.NoError(t, websocket.Message.Send(ws, `{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+
+	// Remote tools are namespaced and filtered
+	require.NoError(t, websocket.Message.Send(ws, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`))
+	response = nil
+	require.NoError(t, websocket.JSON.Receive(ws, &response))
+	var names []string
+	for _, tool := range response["result"].(map[string]interface{})["tools"].([]interface{}) {
+		names = append(names, tool.(map[string]interface{})["name"].(string))
+	}
+	assert.ElementsMatch(t, []string{"local.version", "remote.echo"}, names)
+
+	// Calls are forwarded to the remote server
+	require.NoError(t, websocket.Message.Send(ws,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"remote.echo","arguments":{"message":"hi"}}}`))
+	response = nil
+	require.NoError(t, websocket.JSON.Receive(ws, &response))
+	content := response["result"].(map[string]interface{})["content"].([]interface{})
+	assert.Equal(t, "echo: hi", content[0].(map[string]interface{})["text"])
+
+	require.NoError(t, websocket.Message.Send(ws,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"local.version"}}`))
+	response = nil
+	require.NoError(t, websocket.JSON.Receive(ws, &response))
+	content = response["result"].(map[string]interface{})["content"].([]interface{})
+	assert.Equal(t, "1.0.0", content[0].(map[string]interface{})["text"])
+
+	// A tool added on the remote server reaches clients as tools/list_changed
+	remote.AddTools(echoTool("reverse"))
+	response = nil
+	require.NoError(t, websocket.JSON.Receive(ws, &response))
+	assert.Equal(t, "notifications/tools/list_changed", response["method"])
+	assert.NotNil(t, registry.GetTool("remote.reverse"))
+}
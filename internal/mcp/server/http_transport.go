@@ -0,0 +1,389 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	transportWebSocket      = "websocket"
+	transportStreamableHTTP = "streamable-http"
+
+	defaultWebSocketPath      = "/ws"
+	defaultStreamableHTTPPath = "/mcp"
+
+	// shutdownTimeout bounds how long in-flight requests may finish on shutdown
+	shutdownTimeout = 5 * time.Second
+
+	// maxMessageSize caps a single JSON-RPC message from a client
+	maxMessageSize = 32 << 20
+)
+
+// endpointPath returns the configured endpoint path or the transport default
+func (tm *TransportManager) endpointPath() string {
+	if tm.config.Transport.Path != "" {
+		return tm.config.Transport.Path
+	}
+	if tm.config.Transport.Type == transportWebSocket {
+		return defaultWebSocketPath
+	}
+	return defaultStreamableHTTPPath
+}
+
+// startWebSocketTransport starts the WebSocket transport: one session per
+// connection, one JSON-RPC message per text frame
+func (tm *TransportManager) startWebSocketTransport(ctx context.Context) error {
+	var conns sync.WaitGroup
+	handler := websocket.Server{
+		Config: websocket.Config{},
+		// A refused handshake is answered with 403 Forbidden
+		Handshake: func(_ *websocket.Config, r *http.Request) error {
+			if !tm.allowedOrigin(r.Header.Get("Origin")) {
+				return fmt.Errorf("origin %q not allowed", r.Header.Get("Origin"))
+			}
+			return nil
+		},
+		Handler: func(ws *websocket.Conn) {
+			conns.Add(1)
+			defer conns.Done()
+			tm.serveWebSocket(ws)
+		},
+	}
+	return tm.serveHTTP(ctx, handler, conns.Wait)
+}
+
+// startStreamableHTTPTransport starts the MCP Streamable HTTP transport:
+// requests are POSTed and answered with JSON or an SSE stream, and GET opens
+// a stream for server notifications
+func (tm *TransportManager) startStreamableHTTPTransport(ctx context.Context) error {
+	streamable := server.NewStreamableHTTPServer(tm.server,
+		server.WithEndpointPath(tm.endpointPath()),
+		server.WithSessionIdManager(&httpSessionIDs{sessions: tm.sessions}),
+	)
+	return tm.serveHTTP(ctx, tm.limitStreamableHTTP(streamable), nil)
+}
+
+// serveHTTP serves endpoint and /health until ctx is done, then closes every
+// session and waits up to shutdownTimeout for requests to finish. wait, if
+// set, waits for connections the HTTP server does not track.
+func (tm *TransportManager) serveHTTP(ctx context.Context, endpoint http.Handler, wait func()) error {
+	addr := fmt.Sprintf("%s:%d", tm.config.Transport.Host, tm.config.Transport.Port)
+	path := tm.endpointPath()
+
+	mux := http.NewServeMux()
+	mux.Handle(path, endpoint)
+	mux.HandleFunc("/health", tm.handleHealth)
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to start %s listener on %s: %w", tm.config.Transport.Type, addr, err)
+	}
+
+	httpServer := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("MCP server listening on %s %s%s", tm.config.Transport.Type, listener.Addr(), path)
+
+	sessionCtx, stopSessions := context.WithCancel(ctx)
+	defer stopSessions()
+	go tm.sessions.Run(sessionCtx)
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-errChan:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("%s transport failed: %w", tm.config.Transport.Type, err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	// Graceful shutdown: stop accepting, end streams and connections, then
+	// let in-flight requests finish
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	tm.sessions.CloseAll()
+	if err = httpServer.Shutdown(shutdownCtx); err != nil {
+		// Connections still open after the timeout are dropped
+		httpServer.Close()
+	}
+	if wait != nil {
+		done := make(chan struct{})
+		go func() {
+			wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-shutdownCtx.Done():
+		}
+	}
+	return err
+}
+
+// allowedOrigin reports whether a request with the Origin header origin may
+// use the endpoint. Editors and agents send no Origin and are accepted. Web
+// pages are accepted from the listen host, from loopback names when the
+// server listens on a loopback or unspecified address, and from the
+// configured AllowedOrigins, so other sites cannot reach the server through
+// the browser (cross-site WebSocket hijacking, DNS rebinding).
+func (tm *TransportManager) allowedOrigin(origin string) bool {
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range tm.config.Transport.AllowedOrigins {
+		if strings.EqualFold(strings.TrimSuffix(strings.TrimSpace(allowed), "/"), origin) {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Hostname() == "" {
+		return false
+	}
+	host := tm.config.Transport.Host
+	if strings.EqualFold(u.Hostname(), host) {
+		return true
+	}
+	if ip := net.ParseIP(host); host == "" || isLoopback(host) || (ip != nil && ip.IsUnspecified()) {
+		return isLoopback(u.Hostname())
+	}
+	return false
+}
+
+// isLoopback reports whether host names the local machine
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// handleHealth reports the transport and its sessions
+func (tm *TransportManager) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":       "healthy",
+		"service":      "rocksdb-mcp-server",
+		"transport":    tm.config.Transport.Type,
+		"sessions":     tm.sessions.Count(),
+		"max_sessions": tm.config.MaxConcurrentSessions,
+	})
+}
+
+// limitStreamableHTTP applies the session limit and idle tracking to the
+// Streamable HTTP handler
+func (tm *TransportManager) limitStreamableHTTP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !tm.allowedOrigin(r.Header.Get("Origin")) {
+			http.Error(w, "Origin not allowed", http.StatusForbidden)
+			return
+		}
+		sessionID := r.Header.Get("Mcp-Session-Id")
+
+		switch r.Method {
+		case http.MethodPost:
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize+1))
+			if err != nil {
+				http.Error(w, "Failed to read request body", http.StatusBadRequest)
+				return
+			}
+			if len(body) > maxMessageSize {
+				http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			var message struct {
+				ID     interface{} `json:"id"`
+				Method string      `json:"method"`
+			}
+			if json.Unmarshal(body, &message) == nil && message.Method == string(mcp.MethodInitialize) {
+				if tm.sessions.Full() {
+					writeSessionLimitError(w, message.ID, tm.config.MaxConcurrentSessions)
+					return
+				}
+				if tm.methods.hasCapabilities() {
+					tm.serveInitialize(w, r, next)
+					return
+				}
+			} else if tm.sessions.Acquire(sessionID) {
+				defer tm.sessions.Release(sessionID)
+			}
+
+			if tm.methods.has(message.Method) {
+				tm.serveHTTPMethod(w, r, sessionID, body)
+				return
+			}
+			if message.ID != nil && sessionID != "" {
+				ctx, done := tm.requests.track(r.Context(), sessionID, mcp.NewRequestId(message.ID))
+				defer done()
+				r = r.WithContext(ctx)
+			}
+
+		case http.MethodGet:
+			// A notification stream belongs to a session and keeps it alive
+			if sessionID == "" {
+				http.Error(w, "Missing Mcp-Session-Id header", http.StatusBadRequest)
+				return
+			}
+			if !tm.sessions.Acquire(sessionID) {
+				http.Error(w, "Session not found", http.StatusNotFound)
+				return
+			}
+			defer tm.sessions.Release(sessionID)
+
+			ctx, cancel := context.WithCancel(r.Context())
+			defer cancel()
+			defer tm.sessions.OnClose(sessionID, cancel)()
+			r = r.WithContext(ctx)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serveHTTPMethod answers a POSTed message whose method is served by the
+// transport layer rather than mcp-go
+func (tm *TransportManager) serveHTTPMethod(w http.ResponseWriter, r *http.Request, sessionID string, body []byte) {
+	if sessionID == "" {
+		http.Error(w, "Missing Mcp-Session-Id header", http.StatusBadRequest)
+		return
+	}
+	if !tm.sessions.Exists(sessionID) {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	response := tm.handleMessage(r.Context(), sessionID, body)
+	if response == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Mcp-Session-Id", sessionID)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// serveInitialize serves an initialize request with next and adds the
+// advertised capabilities to its JSON response
+func (tm *TransportManager) serveInitialize(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	captured := &capturedResponse{header: w.Header(), status: http.StatusOK}
+	next.ServeHTTP(captured, r)
+
+	body := captured.body.Bytes()
+	var response struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      interface{}     `json:"id"`
+		Result  json.RawMessage `json:"result"`
+	}
+	if captured.status == http.StatusOK && json.Unmarshal(body, &response) == nil && response.Result != nil {
+		if patched, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": response.JSONRPC,
+			"id":      response.ID,
+			"result":  tm.methods.withCapabilities(response.Result),
+		}); err == nil {
+			body = patched
+		}
+	}
+
+	w.Header().Del("Content-Length")
+	w.WriteHeader(captured.status)
+	w.Write(body)
+}
+
+// capturedResponse buffers a response so it can be rewritten
+type capturedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (c *capturedResponse) Header() http.Header {
+	return c.header
+}
+
+func (c *capturedResponse) WriteHeader(status int) {
+	c.status = status
+}
+
+func (c *capturedResponse) Write(data []byte) (int, error) {
+	return c.body.Write(data)
+}
+
+func writeSessionLimitError(w http.ResponseWriter, id interface{}, limit int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(sessionLimitError(id, fmt.Errorf("%w (limit %d)", ErrTooManySessions, limit)))
+}
+
+// sessionLimitError is the JSON-RPC error sent when a session cannot be opened
+func sessionLimitError(id interface{}, err error) map[string]interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error": map[string]interface{}{
+			"code":    mcp.INTERNAL_ERROR,
+			"message": err.Error(),
+		},
+	}
+}
+
+// serveWebSocket runs one WebSocket session, one JSON-RPC message per text
+// frame
+func (tm *TransportManager) serveWebSocket(ws *websocket.Conn) {
+	defer ws.Close()
+	ws.MaxPayloadBytes = maxMessageSize
+
+	sessionID, err := tm.sessions.Open(transportWebSocket)
+	if err != nil {
+		websocket.JSON.Send(ws, sessionLimitError(nil, err))
+		return
+	}
+
+	writeTimeout := tm.config.Transport.Timeout
+	session := newConnSession(sessionID, func(data []byte) error {
+		if writeTimeout > 0 {
+			ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+		}
+		return websocket.Message.Send(ws, string(data))
+	})
+
+	ctx := ws.Request().Context()
+	receive := func() ([]byte, error) {
+		var message []byte
+		if err := websocket.Message.Receive(ws, &message); err != nil {
+			if err != io.EOF && ctx.Err() == nil && tm.sessions.Exists(sessionID) {
+				log.Printf("WebSocket session %s closed: %v", sessionID, err)
+			}
+			return nil, err
+		}
+		return message, nil
+	}
+
+	tm.serveSession(ctx, session, receive, func() { ws.Close() })
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"rocksdb-cli/internal/mcp/protocol"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// inflightRequests tracks the requests being served so notifications/cancelled
+// can stop them. Cancelling closes the request's signal rather than its
+// context, so the handler can still return partial results.
+type inflightRequests struct {
+	mu       sync.Mutex
+	requests map[string]chan struct{}
+}
+
+type cancelSignalKey struct{}
+
+func requestKey(sessionID string, id mcp.RequestId) string {
+	return sessionID + "/" + id.String()
+}
+
+// track registers request id of sessionID and returns ctx carrying its
+// cancel signal, and a func to call when the request is answered
+func (r *inflightRequests) track(ctx context.Context, sessionID string, id mcp.RequestId) (context.Context, func()) {
+	key := requestKey(sessionID, id)
+	signal := make(chan struct{})
+
+	r.mu.Lock()
+	if r.requests == nil {
+		r.requests = make(map[string]chan struct{})
+	}
+	r.requests[key] = signal
+	r.mu.Unlock()
+
+	return context.WithValue(ctx, cancelSignalKey{}, (<-chan struct{})(signal)), func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.requests[key] == signal {
+			delete(r.requests, key)
+		}
+	}
+}
+
+// cancel signals request id of sessionID and reports whether it was in flight
+func (r *inflightRequests) cancel(sessionID string, id mcp.RequestId) bool {
+	key := requestKey(sessionID, id)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	signal, ok := r.requests[key]
+	if ok {
+		close(signal)
+		delete(r.requests, key)
+	}
+	return ok
+}
+
+// handleCancelled serves notifications/cancelled. Requests that already
+// finished or are unknown are ignored, as the notification may race the
+// response.
+func (tm *TransportManager) handleCancelled(ctx context.Context, sessionID string, params json.RawMessage) (interface{}, error) {
+	var p protocol.CancelledNotification
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, invalidParams("invalid params: %v", err)
+	}
+	if p.RequestID == nil {
+		return nil, invalidParams("requestId is required")
+	}
+	tm.requests.cancel(sessionID, mcp.NewRequestId(p.RequestID))
+	return nil, nil
+}
+
+// operation is a long-running tool call. It reports progress to the client
+// when the request carries a progress token, and its context is cancelled
+// when the client cancels the request.
+type operation struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	server *server.MCPServer
+	token  mcp.ProgressToken
+	name   string
+}
+
+// startOperation starts the operation name of request; call done when it
+// finishes
+func startOperation(ctx context.Context, request mcp.CallToolRequest, name string) (*operation, func()) {
+	op := &operation{server: server.ServerFromContext(ctx), name: name}
+	if request.Params.Meta != nil {
+		op.token = request.Params.Meta.ProgressToken
+	}
+	op.ctx, op.cancel = context.WithCancel(ctx)
+
+	if signal, ok := ctx.Value(cancelSignalKey{}).(<-chan struct{}); ok {
+		go func() {
+			select {
+			case <-signal:
+				op.cancel()
+			case <-op.ctx.Done():
+			}
+		}()
+	}
+	return op, op.cancel
+}
+
+// Progress reports the keys visited so far and returns an error once the
+// operation is cancelled. It is a db.ProgressFunc.
+func (op *operation) Progress(visited int64, lastKey string) error {
+	op.report(visited, fmt.Sprintf("%s: %d keys", op.name, visited))
+	return op.ctx.Err()
+}
+
+// Cancelled reports whether the client cancelled the operation
+func (op *operation) Cancelled() bool {
+	return op.ctx.Err() != nil
+}
+
+// report sends notifications/progress if the client asked for it
+func (op *operation) report(progress int64, message string) {
+	if op.token == nil || op.server == nil {
+		return
+	}
+	op.server.SendNotificationToClient(op.ctx, protocol.MethodProgress, map[string]any{
+		"progressToken": op.token,
+		"progress":      progress,
+		"message":       message,
+	})
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"rocksdb-cli/internal/db"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+// endlessExportDB exports a column family that never ends, until its
+// progress callback stops it
+type endlessExportDB struct {
+	*MockKeyValueDB
+}
+
+func (e *endlessExportDB) ExportToCSVWithOptions(cf, filePath string, opts db.ExportOptions) (*db.ExportResult, error) {
+	result := &db.ExportResult{}
+	for result.Rows < 5000 {
+		result.Rows++
+		key := "key" + strings.Repeat("0", int(result.Rows%3))
+		if opts.Progress(result.Rows, key) != nil {
+			result.NextCursor = base64.StdEncoding.EncodeToString([]byte(key))
+			result.HasMore = true
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return result, nil
+}
+
+func TestInflightRequests(t *testing.T) {
+	var requests inflightRequests
+
+	ctx, done := requests.track(context.Background(), "s1", mcp.NewRequestId(int64(7)))
+	signal := ctx.Value(cancelSignalKey{}).(<-chan struct{})
+
+	assert.False(t, requests.cancel("s2", mcp.NewRequestId(int64(7))), "requests belong to a session")
+	assert.True(t, requests.cancel("s1", mcp.NewRequestId(float64(7))), "JSON numbers match int64 IDs")
+	select {
+	case <-signal:
+	default:
+		t.Fatal("cancel closes the request's signal")
+	}
+	assert.NoError(t, ctx.Err(), "the request context stays usable for partial results")
+	done()
+
+	_, done = requests.track(context.Background(), "s1", mcp.NewRequestId("a"))
+	done()
+	assert.False(t, requests.cancel("s1", mcp.NewRequestId("a")), "answered requests are forgotten")
+}
+
+// TestWebSocketTransport_ProgressAndCancel tests that a long-running tool
+// reports progress and returns partial results when cancelled
+func TestWebSocketTransport_ProgressAndCancel(t *testing.T) {
+	baseURL, tm, _, _ := startHTTPTestTransport(t, transportWebSocket, 10)
+	tools := NewToolManager(&endlessExportDB{NewMockKeyValueDB()}, DefaultConfig())
+	require.NoError(t, tools.RegisterTools(tm.server))
+
+	ws := dialWebSocket(t, baseURL)
+	defer ws.Close()
+	ws.SetDeadline(time.Now().Add(10 * time.Second))
+
+	var response map[string]interface{}
+	require.NoError(t, websocket.Message.Send(ws, initializeRequest))
+	require.NoError(t, websocket.JSON.Receive(ws, &response))
+
+	require.NoError(t, websocket.Message.Send(ws, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{
+		"name":"rocksdb_export_to_csv","arguments":{"column_family":"default","output_file":"out.csv"},
+		"_meta":{"progressToken":"export-1"}}}`))
+
+	response = nil
+	require.NoError(t, websocket.JSON.Receive(ws, &response))
+	require.Equal(t, "notifications/progress", response["method"], "progress is reported: %v", response)
+	params := response["params"].(map[string]interface{})
+	assert.Equal(t, "export-1", params["progressToken"])
+	assert.Equal(t, float64(1), params["progress"])
+
+	require.NoError(t, websocket.Message.Send(ws, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":2,"reason":"user"}}`))
+
+	var last float64
+	for {
+		response = nil
+		require.NoError(t, websocket.JSON.Receive(ws, &response))
+		if response["method"] == "notifications/progress" {
+			progress := response["params"].(map[string]interface{})["progress"].(float64)
+			assert.Greater(t, progress, last, "progress increases")
+			last = progress
+			continue
+		}
+		break
+	}
+
+	assert.Equal(t, float64(2), response["id"])
+	result := response["result"].(map[string]interface{})
+	text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+	assert.Contains(t, text, "was cancelled after")
+	assert.Contains(t, text, "Resume with cursor: ")
+	assert.Less(t, last, float64(5000), "the export stops before the end")
+}
+
+// TestStreamableHTTPTransport_Cancel tests that notifications/cancelled
+// stops a tool call posted on another request
+func TestStreamableHTTPTransport_Cancel(t *testing.T) {
+	baseURL, tm, _, _ := startHTTPTestTransport(t, transportStreamableHTTP, 10)
+	tools := NewToolManager(&endlessExportDB{NewMockKeyValueDB()}, DefaultConfig())
+	require.NoError(t, tools.RegisterTools(tm.server))
+	url := baseURL + defaultStreamableHTTPPath
+
+	resp := postMCP(t, url, "", initializeRequest)
+	resp.Body.Close()
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	require.NotEmpty(t, sessionID)
+
+	body := make(chan string, 1)
+	go func() {
+		resp := postMCP(t, url, sessionID, `{"jsonrpc":"2.0","id":"export","method":"tools/call","params":{
+			"name":"rocksdb_export_to_csv","arguments":{"column_family":"default","output_file":"out.csv"}}}`)
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		body <- string(data)
+	}()
+
+	require.Eventually(t, func() bool {
+		tm.requests.mu.Lock()
+		defer tm.requests.mu.Unlock()
+		return len(tm.requests.requests) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	resp = postMCP(t, url, sessionID, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"export"}}`)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	select {
+	case text := <-body:
+		assert.Contains(t, text, "was cancelled after")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the tool call was not cancelled")
+	}
+}
@@ -13,16 +13,31 @@ import (
 
 // PromptManager manages MCP prompts for RocksDB operations
 type PromptManager struct {
-	db     db.KeyValueDB
-	config *Config
+	databases *Databases
+	config    *Config
 }
 
-// NewPromptManager creates a new prompt manager
+// NewPromptManager creates a new prompt manager for a single database
 func NewPromptManager(database db.KeyValueDB, config *Config) *PromptManager {
+	return NewPromptManagerWithDatabases(SingleDatabase(database, config.DatabasePath), config)
+}
+
+// NewPromptManagerWithDatabases creates a new prompt manager whose prompts
+// describe the session's default database
+func NewPromptManagerWithDatabases(databases *Databases, config *Config) *PromptManager {
 	return &PromptManager{
-		db:     database,
-		config: config,
+		databases: databases,
+		config:    config,
+	}
+}
+
+// database returns the session's default database and its path
+func (pm *PromptManager) database(ctx context.Context) (db.KeyValueDB, string, error) {
+	database, name, err := pm.databases.ForSession(contextSessionID(ctx), "")
+	if err != nil {
+		return nil, "", err
 	}
+	return database, pm.databases.Path(name), nil
 }
 
 // RegisterPrompts registers all available prompts with the MCP server
@@ -67,11 +82,15 @@ func (pm *PromptManager) RegisterPrompts(s *server.MCPServer) error {
 // Prompt handlers
 
 func (pm *PromptManager) handleDataAnalysisPrompt(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	database, path, err := pm.database(ctx)
+	if err != nil {
+		return nil, err
+	}
 	cf := pm.getStringArg(req.Params.Arguments, "column_family", "default")
 	analysisType := pm.getStringArg(req.Params.Arguments, "analysis_type", "overview")
 
 	// Get basic info about the column family
-	columnFamilies, _ := pm.db.ListCFs()
+	columnFamilies, _ := database.ListCFs()
 	cfExists := false
 	for _, existingCF := range columnFamilies {
 		if existingCF == cf {
@@ -88,12 +107,12 @@ func (pm *PromptManager) handleDataAnalysisPrompt(ctx context.Context, req mcp.G
 
 		if cfExists {
 			prompt.WriteString("Available information:\n")
-			prompt.WriteString(fmt.Sprintf("- Database path: %s\n", pm.config.DatabasePath))
-			prompt.WriteString(fmt.Sprintf("- Read-only mode: %v\n", pm.db.IsReadOnly()))
+			prompt.WriteString(fmt.Sprintf("- Database path: %s\n", path))
+			prompt.WriteString(fmt.Sprintf("- Read-only mode: %v\n", database.IsReadOnly()))
 			prompt.WriteString(fmt.Sprintf("- Column families: %v\n", columnFamilies))
 
 			// Try to get sample data
-			if sampleData, err := pm.db.ScanCF(cf, nil, nil, db.ScanOptions{Limit: 10, Values: true}); err == nil {
+			if sampleData, err := database.ScanCF(cf, nil, nil, db.ScanOptions{Limit: 10, Values: true}); err == nil {
 				prompt.WriteString(fmt.Sprintf("- Sample data (first 10 entries): %v\n", sampleData))
 			}
 		} else {
@@ -138,6 +157,10 @@ func (pm *PromptManager) handleDataAnalysisPrompt(ctx context.Context, req mcp.G
 }
 
 func (pm *PromptManager) handleQueryGenerationPrompt(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	database, _, err := pm.database(ctx)
+	if err != nil {
+		return nil, err
+	}
 	operation := pm.getStringArg(req.Params.Arguments, "operation", "get")
 	cf := pm.getStringArg(req.Params.Arguments, "column_family", "default")
 	useCase := pm.getStringArg(req.Params.Arguments, "use_case", "general data access")
@@ -147,10 +170,10 @@ func (pm *PromptManager) handleQueryGenerationPrompt(ctx context.Context, req mc
 	prompt.WriteString(fmt.Sprintf("Target column family: %s\n", cf))
 
 	// Get database context
-	columnFamilies, _ := pm.db.ListCFs()
+	columnFamilies, _ := database.ListCFs()
 	prompt.WriteString(fmt.Sprintf("Available column families: %v\n", columnFamilies))
 	prompt.WriteString(fmt.Sprintf("Database mode: %s\n\n", func() string {
-		if pm.db.IsReadOnly() {
+		if database.IsReadOnly() {
 			return "read-only"
 		}
 		return "read-write"
@@ -194,6 +217,10 @@ func (pm *PromptManager) handleQueryGenerationPrompt(ctx context.Context, req mc
 }
 
 func (pm *PromptManager) handleTroubleshootingPrompt(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	database, path, err := pm.database(ctx)
+	if err != nil {
+		return nil, err
+	}
 	issueType := pm.getStringArg(req.Params.Arguments, "issue_type", "general")
 	symptoms := pm.getStringArg(req.Params.Arguments, "symptoms", "unspecified")
 
@@ -201,10 +228,10 @@ func (pm *PromptManager) handleTroubleshootingPrompt(ctx context.Context, req mc
 	prompt.WriteString(fmt.Sprintf("Troubleshoot RocksDB %s issues with symptoms: %s\n\n", issueType, symptoms))
 
 	// Add database context
-	columnFamilies, _ := pm.db.ListCFs()
+	columnFamilies, _ := database.ListCFs()
 	prompt.WriteString("Database context:\n")
-	prompt.WriteString(fmt.Sprintf("- Database path: %s\n", pm.config.DatabasePath))
-	prompt.WriteString(fmt.Sprintf("- Read-only mode: %v\n", pm.db.IsReadOnly()))
+	prompt.WriteString(fmt.Sprintf("- Database path: %s\n", path))
+	prompt.WriteString(fmt.Sprintf("- Read-only mode: %v\n", database.IsReadOnly()))
 	prompt.WriteString(fmt.Sprintf("- Column families: %v\n\n", columnFamilies))
 
 	switch issueType {
@@ -253,6 +280,10 @@ func (pm *PromptManager) handleTroubleshootingPrompt(ctx context.Context, req mc
 }
 
 func (pm *PromptManager) handleSchemaDesignPrompt(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	database, _, err := pm.database(ctx)
+	if err != nil {
+		return nil, err
+	}
 	dataType := pm.getStringArg(req.Params.Arguments, "data_type", "general")
 	accessPatterns := pm.getStringArg(req.Params.Arguments, "access_patterns", "mixed")
 	requirements := pm.getStringArg(req.Params.Arguments, "requirements", "standard")
@@ -264,10 +295,10 @@ func (pm *PromptManager) handleSchemaDesignPrompt(ctx context.Context, req mcp.G
 	prompt.WriteString(fmt.Sprintf("Requirements: %s\n\n", requirements))
 
 	// Add current database context
-	columnFamilies, _ := pm.db.ListCFs()
+	columnFamilies, _ := database.ListCFs()
 	prompt.WriteString("Current database state:\n")
 	prompt.WriteString(fmt.Sprintf("- Existing column families: %v\n", columnFamilies))
-	prompt.WriteString(fmt.Sprintf("- Read-only mode: %v\n\n", pm.db.IsReadOnly()))
+	prompt.WriteString(fmt.Sprintf("- Read-only mode: %v\n\n", database.IsReadOnly()))
 
 	prompt.WriteString("Please provide:\n")
 	prompt.WriteString("1. Column family design recommendations\n")
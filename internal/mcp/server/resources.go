@@ -2,22 +2,42 @@ package server
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 
 	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/keytree"
+	"rocksdb-cli/internal/util"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
-// ResourceManager manages MCP resources for RocksDB operations
+const (
+	// DefaultPageSize is the page size of resource listings and paginated
+	// resource reads
+	DefaultPageSize = 100
+
+	// maxPageSize caps the limit a client may request in a resource URI
+	maxPageSize = 1000
+
+	uriColumnFamilyPrefix = "rocksdb://column-family/"
+)
+
+// ResourceManager manages MCP resources for RocksDB operations. Resource URIs
+// name no database, so resources always read the default database whatever
+// database a session has opened.
 type ResourceManager struct {
 	db     db.KeyValueDB
 	config *Config
+
+	mu     sync.Mutex
+	server *server.MCPServer
+	listed map[string]bool // column families with a listed resource
 }
 
 // NewResourceManager creates a new resource manager
@@ -25,10 +45,13 @@ func NewResourceManager(database db.KeyValueDB, config *Config) *ResourceManager
 	return &ResourceManager{
 		db:     database,
 		config: config,
+		listed: make(map[string]bool),
 	}
 }
 
-// RegisterResources registers all available resources with the MCP server
+// RegisterResources registers all available resources with the MCP server.
+// Keys and other template variables are percent-encoded in resource URIs,
+// so a key may contain any byte, including '/'.
 func (rm *ResourceManager) RegisterResources(s *server.MCPServer) error {
 	if !rm.config.EnableResources {
 		return nil
@@ -43,15 +66,6 @@ func (rm *ResourceManager) RegisterResources(s *server.MCPServer) error {
 	)
 	s.AddResource(columnFamiliesResource, rm.handleColumnFamiliesResource)
 
-	// Column Family Data resource - access data from a specific column family
-	columnFamilyDataResource := mcp.NewResource(
-		"rocksdb://column-family/{name}",
-		"Column Family Data",
-		mcp.WithResourceDescription("Data from a specific column family"),
-		mcp.WithMIMEType("application/json"),
-	)
-	s.AddResource(columnFamilyDataResource, rm.handleColumnFamilyDataResource)
-
 	// Database Stats resource
 	databaseStatsResource := mcp.NewResource(
 		"rocksdb://stats",
@@ -61,24 +75,76 @@ func (rm *ResourceManager) RegisterResources(s *server.MCPServer) error {
 	)
 	s.AddResource(databaseStatsResource, rm.handleDatabaseStatsResource)
 
-	// Key-Value Pair resource - access a specific key
-	keyValueResource := mcp.NewResource(
+	// Column Family Data template - one page of a column family
+	columnFamilyDataTemplate := mcp.NewResourceTemplate(
+		"rocksdb://column-family/{name}{?cursor,limit}",
+		"Column Family Data",
+		mcp.WithTemplateDescription("Keys and values of a column family, a page at a time; pass next_cursor as cursor for the next page"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+	s.AddResourceTemplate(columnFamilyDataTemplate, rm.handleColumnFamilyDataResource)
+
+	// Key-Value Pair template - access a specific key
+	keyValueTemplate := mcp.NewResourceTemplate(
 		"rocksdb://data/{column_family}/{key}",
 		"Key-Value Pair",
-		mcp.WithResourceDescription("Access a specific key-value pair"),
-		mcp.WithMIMEType("text/plain"),
+		mcp.WithTemplateDescription("The value of a key; binary values are returned as a base64 blob"),
 	)
-	s.AddResource(keyValueResource, rm.handleKeyValueResource)
+	s.AddResourceTemplate(keyValueTemplate, rm.handleKeyValueResource)
 
-	// Prefix Scan resource - scan keys with a prefix
-	prefixScanResource := mcp.NewResource(
-		"rocksdb://prefix/{column_family}/{prefix}",
+	// Prefix Scan template - scan keys with a prefix
+	prefixScanTemplate := mcp.NewResourceTemplate(
+		"rocksdb://prefix/{column_family}/{prefix}{?cursor,limit}",
 		"Prefix Scan",
-		mcp.WithResourceDescription("Scan keys with a specific prefix"),
-		mcp.WithMIMEType("application/json"),
+		mcp.WithTemplateDescription("Keys with a specific prefix, a page at a time; pass next_cursor as cursor for the next page"),
+		mcp.WithTemplateMIMEType("application/json"),
 	)
-	s.AddResource(prefixScanResource, rm.handlePrefixScanResource)
+	s.AddResourceTemplate(prefixScanTemplate, rm.handlePrefixScanResource)
 
+	rm.mu.Lock()
+	rm.server = s
+	rm.mu.Unlock()
+	return rm.SyncColumnFamilies()
+}
+
+// SyncColumnFamilies lists a resource for each column family, adding and
+// removing resources as column families are created and dropped
+func (rm *ResourceManager) SyncColumnFamilies() error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if rm.server == nil {
+		return nil
+	}
+
+	cfs, err := rm.db.ListCFs()
+	if err != nil {
+		return fmt.Errorf("failed to list column families: %w", err)
+	}
+
+	current := make(map[string]bool, len(cfs))
+	for _, cf := range cfs {
+		current[cf] = true
+		if rm.listed[cf] {
+			continue
+		}
+		name := cf
+		resource := mcp.NewResource(
+			columnFamilyURI(cf),
+			"Column Family: "+cf,
+			mcp.WithResourceDescription(fmt.Sprintf("First page of the %s column family", cf)),
+			mcp.WithMIMEType("application/json"),
+		)
+		rm.server.AddResource(resource, func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return rm.readColumnFamily(req.Params.URI, name, "", "")
+		})
+		rm.listed[cf] = true
+	}
+	for cf := range rm.listed {
+		if !current[cf] {
+			rm.server.RemoveResource(columnFamilyURI(cf))
+			delete(rm.listed, cf)
+		}
+	}
 	return nil
 }
 
@@ -97,57 +163,44 @@ func (rm *ResourceManager) handleColumnFamiliesResource(ctx context.Context, req
 		"read_only":       rm.db.IsReadOnly(),
 	}
 
-	jsonData, err := json.Marshal(response)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal column families: %w", err)
-	}
-
-	return []mcp.ResourceContents{
-		mcp.TextResourceContents{
-			URI:      req.Params.URI,
-			MIMEType: "application/json",
-			Text:     string(jsonData),
-		},
-	}, nil
+	return jsonContents(req.Params.URI, response)
 }
 
 func (rm *ResourceManager) handleColumnFamilyDataResource(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
-	// Extract column family name from URI
-	cfName := rm.extractPathParam(req.Params.URI, "name")
+	cfName := templateArg(req, "name")
 	if cfName == "" {
 		return nil, fmt.Errorf("column family name is required")
 	}
+	return rm.readColumnFamily(req.Params.URI, cfName, templateArg(req, "cursor"), templateArg(req, "limit"))
+}
+
+// readColumnFamily returns one page of cfName starting after cursor
+func (rm *ResourceManager) readColumnFamily(uri, cfName, cursor, limitParam string) ([]mcp.ResourceContents, error) {
+	limit, err := rm.pageLimit(limitParam)
+	if err != nil {
+		return nil, err
+	}
 
-	// Get sample data from the column family (first 100 keys)
-	sampleData, err := rm.db.ScanCF(cfName, nil, nil, db.ScanOptions{
-		Limit:   100,
-		Reverse: false,
-		Values:  true,
+	page, err := rm.db.ScanCFPage(cfName, nil, nil, db.ScanOptions{
+		Limit:      limit,
+		Values:     true,
+		StartAfter: cursor,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan column family '%s': %w", cfName, err)
 	}
 
-	// Create JSON response
 	response := map[string]interface{}{
 		"column_family": cfName,
-		"sample_data":   sampleData,
-		"sample_size":   len(sampleData),
+		"entries":       pageEntries(page),
+		"count":         len(page.ResultsV2),
+		"limit":         limit,
+		"has_more":      page.HasMore,
+		"next_cursor":   page.NextCursor,
 		"read_only":     rm.db.IsReadOnly(),
 	}
 
-	jsonData, err := json.Marshal(response)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal column family data: %w", err)
-	}
-
-	return []mcp.ResourceContents{
-		mcp.TextResourceContents{
-			URI:      req.Params.URI,
-			MIMEType: "application/json",
-			Text:     string(jsonData),
-		},
-	}, nil
+	return jsonContents(uri, response)
 }
 
 func (rm *ResourceManager) handleDatabaseStatsResource(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
@@ -167,136 +220,155 @@ func (rm *ResourceManager) handleDatabaseStatsResource(ctx context.Context, req
 		"transport_type":  rm.config.Transport.Type,
 	}
 
-	jsonData, err := json.Marshal(response)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal database stats: %w", err)
-	}
-
-	return []mcp.ResourceContents{
-		mcp.TextResourceContents{
-			URI:      req.Params.URI,
-			MIMEType: "application/json",
-			Text:     string(jsonData),
-		},
-	}, nil
+	return jsonContents(req.Params.URI, response)
 }
 
 func (rm *ResourceManager) handleKeyValueResource(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
-	// Extract parameters from URI
-	cfName := rm.extractPathParam(req.Params.URI, "column_family")
-	key := rm.extractPathParam(req.Params.URI, "key")
-
+	cfName := templateArg(req, "column_family")
+	key := templateArg(req, "key")
 	if cfName == "" || key == "" {
 		return nil, fmt.Errorf("both column_family and key are required")
 	}
 
-	// Decode URL-encoded key
-	decodedKey, err := url.QueryUnescape(key)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode key: %w", err)
-	}
-
-	// Get the value
-	value, err := rm.db.GetCF(cfName, decodedKey)
+	value, err := rm.db.GetCF(cfName, key)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get key '%s' from CF '%s': %w", decodedKey, cfName, err)
+		return nil, fmt.Errorf("failed to get key '%s' from CF '%s': %w", key, cfName, err)
 	}
 
-	return []mcp.ResourceContents{
-		mcp.TextResourceContents{
-			URI:      req.Params.URI,
-			MIMEType: "text/plain",
-			Text:     value,
-		},
-	}, nil
+	return []mcp.ResourceContents{valueContents(req.Params.URI, value)}, nil
 }
 
 func (rm *ResourceManager) handlePrefixScanResource(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
-	// Extract parameters from URI
-	cfName := rm.extractPathParam(req.Params.URI, "column_family")
-	prefix := rm.extractPathParam(req.Params.URI, "prefix")
-
+	cfName := templateArg(req, "column_family")
+	prefix := templateArg(req, "prefix")
 	if cfName == "" || prefix == "" {
 		return nil, fmt.Errorf("both column_family and prefix are required")
 	}
 
-	// Decode URL-encoded prefix
-	decodedPrefix, err := url.QueryUnescape(prefix)
+	limit, err := rm.pageLimit(templateArg(req, "limit"))
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode prefix: %w", err)
+		return nil, err
 	}
 
-	// Parse query parameters for limit
-	u, err := url.Parse(req.Params.URI)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse URI: %w", err)
+	// The key range of a prefix assumes bytewise key order
+	cfOpts, _ := rm.db.GetCFOptions(cfName)
+	if err := db.RequireBytewise(cfOpts, cfName); err != nil {
+		return nil, err
 	}
 
-	limit := 100 // Default limit
-	if limitStr := u.Query().Get("limit"); limitStr != "" {
-		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
-			limit = parsedLimit
-		}
-	}
-
-	// Perform prefix scan
-	results, err := rm.db.PrefixScanCF(cfName, decodedPrefix, limit)
+	page, err := rm.db.ScanCFPage(cfName, []byte(prefix), keytree.PrefixEnd([]byte(prefix)), db.ScanOptions{
+		Limit:      limit,
+		Values:     true,
+		StartAfter: templateArg(req, "cursor"),
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to prefix scan CF '%s': %w", cfName, err)
 	}
 
-	// Create JSON response
 	response := map[string]interface{}{
 		"column_family": cfName,
-		"prefix":        decodedPrefix,
-		"results":       results,
-		"count":         len(results),
+		"prefix":        prefix,
+		"entries":       pageEntries(page),
+		"count":         len(page.ResultsV2),
 		"limit":         limit,
+		"has_more":      page.HasMore,
+		"next_cursor":   page.NextCursor,
+	}
+
+	return jsonContents(req.Params.URI, response)
+}
+
+// pageLimit parses the limit of a paginated read, defaulting to the page size
+func (rm *ResourceManager) pageLimit(param string) (int, error) {
+	if param == "" {
+		if rm.config.PageSize > 0 {
+			return rm.config.PageSize, nil
+		}
+		return DefaultPageSize, nil
+	}
+	limit, err := strconv.Atoi(param)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("invalid limit %q: must be a positive integer", param)
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	return limit, nil
+}
+
+// templateArg returns a decoded URI template variable of req
+func templateArg(req mcp.ReadResourceRequest, name string) string {
+	switch v := req.Params.Arguments[name].(type) {
+	case []string:
+		if len(v) > 0 {
+			return v[0]
+		}
+	case string:
+		return v
+	}
+	return ""
+}
+
+// pageEntries returns the entries of page; binary keys and values are
+// hex-encoded and flagged
+func pageEntries(page db.ScanPageResult) []db.KeyValue {
+	if page.ResultsV2 == nil {
+		return []db.KeyValue{}
+	}
+	return page.ResultsV2
+}
+
+// valueContents returns value as text, or as a base64 blob if it is not
+// printable text
+func valueContents(uri, value string) mcp.ResourceContents {
+	data := []byte(value)
+	if !util.IsPrintable(data) {
+		return mcp.BlobResourceContents{
+			URI:      uri,
+			MIMEType: "application/octet-stream",
+			Blob:     base64.StdEncoding.EncodeToString(data),
+		}
 	}
 
+	mimeType := "text/plain"
+	if trimmed := strings.TrimSpace(value); (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid(data) {
+		mimeType = "application/json"
+	}
+	return mcp.TextResourceContents{URI: uri, MIMEType: mimeType, Text: value}
+}
+
+func jsonContents(uri string, response interface{}) ([]mcp.ResourceContents, error) {
 	jsonData, err := json.Marshal(response)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal prefix scan results: %w", err)
+		return nil, fmt.Errorf("failed to marshal resource: %w", err)
 	}
 
 	return []mcp.ResourceContents{
 		mcp.TextResourceContents{
-			URI:      req.Params.URI,
+			URI:      uri,
 			MIMEType: "application/json",
 			Text:     string(jsonData),
 		},
 	}, nil
 }
 
-// Helper method to extract path parameters from URI
-func (rm *ResourceManager) extractPathParam(uri, paramName string) string {
-	// Simple path parameter extraction for rocksdb://type/param1/param2 format
-	parts := strings.Split(uri, "/")
-
-	switch paramName {
-	case "name":
-		// For rocksdb://column-family/{name}
-		if len(parts) >= 3 && parts[0] == "rocksdb:" && parts[2] == "column-family" {
-			if len(parts) >= 4 {
-				return parts[3]
-			}
-		}
-	case "column_family":
-		// For rocksdb://data/{column_family}/{key} or rocksdb://prefix/{column_family}/{prefix}
-		if len(parts) >= 4 && parts[0] == "rocksdb:" {
-			return parts[3]
-		}
-	case "key":
-		// For rocksdb://data/{column_family}/{key}
-		if len(parts) >= 5 && parts[0] == "rocksdb:" && parts[2] == "data" {
-			return parts[4]
-		}
-	case "prefix":
-		// For rocksdb://prefix/{column_family}/{prefix}
-		if len(parts) >= 5 && parts[0] == "rocksdb:" && parts[2] == "prefix" {
-			return parts[4]
+// columnFamilyURI returns the resource URI of the first page of cf
+func columnFamilyURI(cf string) string {
+	return uriColumnFamilyPrefix + EscapeURIComponent(cf)
+}
+
+// EscapeURIComponent percent-encodes s for a URI template variable: every
+// byte except the RFC 3986 unreserved characters is encoded
+func EscapeURIComponent(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+			continue
 		}
+		fmt.Fprintf(&b, "%%%02X", c)
 	}
-
-	return ""
+	return b.String()
 }
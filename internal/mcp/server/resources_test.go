@@ -0,0 +1,208 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"rocksdb-cli/internal/db"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newResourceTestServer returns an MCP server with the resources of mockDB
+func newResourceTestServer(t *testing.T, mockDB db.KeyValueDB, pageSize int) (*server.MCPServer, *ResourceManager) {
+	t.Helper()
+	config := DefaultConfig()
+	config.PageSize = pageSize
+	s := server.NewMCPServer("test-server", "1.0.0",
+		server.WithResourceCapabilities(true, true),
+		server.WithPaginationLimit(pageSize),
+	)
+	rm := NewResourceManager(mockDB, config)
+	require.NoError(t, rm.RegisterResources(s))
+	return s, rm
+}
+
+// callResources sends one JSON-RPC request to s and decodes its result
+func callResources(t *testing.T, s *server.MCPServer, method string, params interface{}, result interface{}) *mcp.JSONRPCError {
+	t.Helper()
+	message, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	require.NoError(t, err)
+
+	switch response := s.HandleMessage(context.Background(), message).(type) {
+	case mcp.JSONRPCResponse:
+		data, err := json.Marshal(response.Result)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, result))
+		return nil
+	case mcp.JSONRPCError:
+		return &response
+	default:
+		t.Fatalf("unexpected response %T", response)
+		return nil
+	}
+}
+
+// readResource reads uri and returns its single content
+func readResource(t *testing.T, s *server.MCPServer, uri string) map[string]interface{} {
+	t.Helper()
+	var result struct {
+		Contents []map[string]interface{} `json:"contents"`
+	}
+	rpcErr := callResources(t, s, "resources/read", map[string]string{"uri": uri}, &result)
+	require.Nil(t, rpcErr, "reading %s", uri)
+	require.Len(t, result.Contents, 1)
+	return result.Contents[0]
+}
+
+func TestResourceTemplates(t *testing.T) {
+	s, _ := newResourceTestServer(t, NewMockKeyValueDB(), 10)
+
+	var result struct {
+		ResourceTemplates []struct {
+			URITemplate string `json:"uriTemplate"`
+		} `json:"resourceTemplates"`
+	}
+	require.Nil(t, callResources(t, s, "resources/templates/list", map[string]string{}, &result))
+
+	var templates []string
+	for _, tmpl := range result.ResourceTemplates {
+		templates = append(templates, tmpl.URITemplate)
+	}
+	assert.ElementsMatch(t, []string{
+		"rocksdb://column-family/{name}{?cursor,limit}",
+		"rocksdb://data/{column_family}/{key}",
+		"rocksdb://prefix/{column_family}/{prefix}{?cursor,limit}",
+	}, templates)
+}
+
+func TestKeyValueResource(t *testing.T) {
+	mockDB := NewMockKeyValueDB()
+	require.NoError(t, mockDB.PutCF("default", "user:1/name", "alice"))
+	require.NoError(t, mockDB.PutCF("default", "user:1/profile", `{"age":30}`))
+	require.NoError(t, mockDB.PutCF("default", "blob", "\x00\xff\x10"))
+	s, _ := newResourceTestServer(t, mockDB, 10)
+
+	uri := "rocksdb://data/default/" + EscapeURIComponent("user:1/name")
+	assert.Equal(t, "rocksdb://data/default/user%3A1%2Fname", uri)
+	content := readResource(t, s, uri)
+	assert.Equal(t, "alice", content["text"])
+	assert.Equal(t, "text/plain", content["mimeType"])
+
+	content = readResource(t, s, "rocksdb://data/default/user%3A1%2Fprofile")
+	assert.Equal(t, "application/json", content["mimeType"])
+
+	content = readResource(t, s, "rocksdb://data/default/blob")
+	assert.Equal(t, "application/octet-stream", content["mimeType"])
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("\x00\xff\x10")), content["blob"])
+	assert.NotContains(t, content, "text")
+}
+
+func TestColumnFamilyResourcePagination(t *testing.T) {
+	mockDB := NewMockKeyValueDB()
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		require.NoError(t, mockDB.PutCF("default", key, "v-"+key))
+	}
+	s, _ := newResourceTestServer(t, mockDB, 2)
+
+	var keys []string
+	uri := "rocksdb://column-family/default"
+	for pages := 0; pages < 5; pages++ {
+		var page struct {
+			Entries []struct {
+				Key string `json:"key"`
+			} `json:"entries"`
+			HasMore    bool   `json:"has_more"`
+			NextCursor string `json:"next_cursor"`
+		}
+		content := readResource(t, s, uri)
+		require.NoError(t, json.Unmarshal([]byte(content["text"].(string)), &page))
+		for _, e := range page.Entries {
+			keys = append(keys, e.Key)
+		}
+		if !page.HasMore {
+			break
+		}
+		uri = "rocksdb://column-family/default?cursor=" + EscapeURIComponent(page.NextCursor)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, keys)
+
+	content := readResource(t, s, "rocksdb://prefix/default/c?limit=10")
+	assert.Contains(t, content["text"], `"key":"c"`)
+	assert.NotContains(t, content["text"], `"key":"d"`)
+}
+
+// reversedDB reports the reverse bytewise comparator for every column family
+type reversedDB struct{ *MockKeyValueDB }
+
+func (r reversedDB) GetCFOptions(cf string) (*db.CFOptions, error) {
+	return &db.CFOptions{Name: cf, Comparator: "rocksdb.ReverseBytewiseComparator"}, nil
+}
+
+func TestPrefixResourceNeedsBytewiseOrder(t *testing.T) {
+	mockDB := NewMockKeyValueDB()
+	require.NoError(t, mockDB.PutCF("default", "c", "v"))
+	s, _ := newResourceTestServer(t, reversedDB{mockDB}, 10)
+
+	var result struct{}
+	rpcErr := callResources(t, s, "resources/read", map[string]string{"uri": "rocksdb://prefix/default/c"}, &result)
+	require.NotNil(t, rpcErr)
+	assert.Contains(t, rpcErr.Error.Message, "bytewise comparator")
+}
+
+func TestColumnFamilyResourceListing(t *testing.T) {
+	mockDB := NewMockKeyValueDB()
+	for _, cf := range []string{"orders", "users", "logs/2024"} {
+		require.NoError(t, mockDB.CreateCF(cf))
+	}
+	s, rm := newResourceTestServer(t, mockDB, 3)
+
+	listAll := func() []string {
+		var uris []string
+		cursor := ""
+		for pages := 0; pages < 10; pages++ {
+			var result struct {
+				Resources []struct {
+					URI string `json:"uri"`
+				} `json:"resources"`
+				NextCursor string `json:"nextCursor"`
+			}
+			params := map[string]string{}
+			if cursor != "" {
+				params["cursor"] = cursor
+			}
+			require.Nil(t, callResources(t, s, "resources/list", params, &result))
+			for _, r := range result.Resources {
+				uris = append(uris, r.URI)
+			}
+			if result.NextCursor == "" {
+				break
+			}
+			cursor = result.NextCursor
+		}
+		return uris
+	}
+
+	assert.ElementsMatch(t, []string{
+		"rocksdb://column-families",
+		"rocksdb://stats",
+		"rocksdb://column-family/default",
+		"rocksdb://column-family/orders",
+		"rocksdb://column-family/users",
+		"rocksdb://column-family/logs%2F2024",
+	}, listAll())
+
+	require.NoError(t, mockDB.DropCF("orders"))
+	require.NoError(t, rm.SyncColumnFamilies())
+	assert.NotContains(t, listAll(), "rocksdb://column-family/orders")
+}
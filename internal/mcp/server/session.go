@@ -0,0 +1,322 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrTooManySessions is returned when MaxConcurrentSessions sessions are open
+	ErrTooManySessions = errors.New("too many concurrent sessions")
+	// ErrShuttingDown is returned for sessions opened after CloseAll
+	ErrShuttingDown = errors.New("server is shutting down")
+)
+
+// SessionInfo describes an open network session
+type SessionInfo struct {
+	ID         string    `json:"id"`
+	Transport  string    `json:"transport"`
+	Created    time.Time `json:"created"`
+	LastActive time.Time `json:"last_active"`
+	InFlight   int       `json:"in_flight"`
+}
+
+// trackedSession is a session with the work and resources tied to it
+type trackedSession struct {
+	info    SessionInfo
+	closers map[int]func()
+	nextFn  int
+}
+
+// SessionManager tracks the sessions of the network transports. It caps the
+// number of open sessions and closes sessions idle for longer than the
+// timeout; a session with a request in flight or an open stream is never idle.
+type SessionManager struct {
+	mu       sync.Mutex
+	max      int
+	timeout  time.Duration
+	sessions map[string]*trackedSession
+	closed   bool
+	hooks    []func(id string)
+	now      func() time.Time
+}
+
+// NewSessionManager creates a session manager. max <= 0 allows any number of
+// sessions and timeout <= 0 never expires them.
+func NewSessionManager(max int, timeout time.Duration) *SessionManager {
+	return &SessionManager{
+		max:      max,
+		timeout:  timeout,
+		sessions: make(map[string]*trackedSession),
+		now:      time.Now,
+	}
+}
+
+// OnSessionClosed registers fn to run after any session is closed or expires
+func (m *SessionManager) OnSessionClosed(fn func(id string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, fn)
+}
+
+// Open starts a new session over transport and returns its ID
+func (m *SessionManager) Open(transport string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return "", ErrShuttingDown
+	}
+	if m.fullLocked() {
+		return "", fmt.Errorf("%w (limit %d)", ErrTooManySessions, m.max)
+	}
+	now := m.now()
+	id := uuid.New().String()
+	m.sessions[id] = &trackedSession{
+		info:    SessionInfo{ID: id, Transport: transport, Created: now, LastActive: now},
+		closers: make(map[int]func()),
+	}
+	return id, nil
+}
+
+// Full reports whether no further session can be opened
+func (m *SessionManager) Full() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.fullLocked()
+}
+
+func (m *SessionManager) fullLocked() bool {
+	return m.closed || (m.max > 0 && len(m.sessions) >= m.max)
+}
+
+// Exists reports whether id is an open session
+func (m *SessionManager) Exists(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.sessions[id]
+	return ok
+}
+
+// Acquire marks work in flight on session id until the matching Release. It
+// returns false if the session is not open.
+func (m *SessionManager) Acquire(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[id]
+	if !ok {
+		return false
+	}
+	s.info.InFlight++
+	s.info.LastActive = m.now()
+	return true
+}
+
+// Release ends work started by Acquire
+func (m *SessionManager) Release(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.sessions[id]; ok {
+		if s.info.InFlight > 0 {
+			s.info.InFlight--
+		}
+		s.info.LastActive = m.now()
+	}
+}
+
+// OnClose registers fn to run when session id is closed or expires. The
+// returned function unregisters it. fn runs immediately if the session is
+// not open.
+func (m *SessionManager) OnClose(id string, fn func()) (remove func()) {
+	m.mu.Lock()
+	s, ok := m.sessions[id]
+	if !ok {
+		m.mu.Unlock()
+		fn()
+		return func() {}
+	}
+	key := s.nextFn
+	s.nextFn++
+	s.closers[key] = fn
+	m.mu.Unlock()
+
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(s.closers, key)
+	}
+}
+
+// Close ends session id, running its close functions. It returns false if
+// the session was not open.
+func (m *SessionManager) Close(id string) bool {
+	m.mu.Lock()
+	s, ok := m.sessions[id]
+	var closers []func()
+	if ok {
+		delete(m.sessions, id)
+		closers = takeClosers(s)
+	}
+	hooks := m.hooks
+	m.mu.Unlock()
+
+	if ok {
+		runAll(closers)
+		runHooks(hooks, []string{id})
+	}
+	return ok
+}
+
+// CloseAll ends every session and refuses new ones
+func (m *SessionManager) CloseAll() {
+	m.mu.Lock()
+	m.closed = true
+	var ids []string
+	var closers []func()
+	for id, s := range m.sessions {
+		ids = append(ids, id)
+		closers = append(closers, takeClosers(s)...)
+	}
+	m.sessions = make(map[string]*trackedSession)
+	hooks := m.hooks
+	m.mu.Unlock()
+
+	runAll(closers)
+	runHooks(hooks, ids)
+}
+
+// ExpireIdle closes the sessions idle since before now minus the timeout and
+// returns their IDs
+func (m *SessionManager) ExpireIdle() []string {
+	if m.timeout <= 0 {
+		return nil
+	}
+
+	m.mu.Lock()
+	cutoff := m.now().Add(-m.timeout)
+	var ids []string
+	var closers []func()
+	for id, s := range m.sessions {
+		if s.info.InFlight == 0 && s.info.LastActive.Before(cutoff) {
+			ids = append(ids, id)
+			closers = append(closers, takeClosers(s)...)
+			delete(m.sessions, id)
+		}
+	}
+	hooks := m.hooks
+	m.mu.Unlock()
+
+	runAll(closers)
+	runHooks(hooks, ids)
+	return ids
+}
+
+// Run expires idle sessions until ctx is done
+func (m *SessionManager) Run(ctx context.Context) {
+	if m.timeout <= 0 {
+		return
+	}
+	interval := m.timeout / 4
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, id := range m.ExpireIdle() {
+				log.Printf("MCP session %s expired after %v idle", id, m.timeout)
+			}
+		}
+	}
+}
+
+// Count returns the number of open sessions
+func (m *SessionManager) Count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sessions)
+}
+
+// Sessions returns the open sessions, oldest first
+func (m *SessionManager) Sessions() []SessionInfo {
+	m.mu.Lock()
+	infos := make([]SessionInfo, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		infos = append(infos, s.info)
+	}
+	m.mu.Unlock()
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Created.Before(infos[j].Created)
+	})
+	return infos
+}
+
+// takeClosers removes and returns the close functions of s; the caller
+// holds the lock and runs them after releasing it
+func takeClosers(s *trackedSession) []func() {
+	fns := make([]func(), 0, len(s.closers))
+	for key, fn := range s.closers {
+		fns = append(fns, fn)
+		delete(s.closers, key)
+	}
+	return fns
+}
+
+func runAll(fns []func()) {
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+func runHooks(hooks []func(id string), ids []string) {
+	for _, id := range ids {
+		for _, hook := range hooks {
+			hook(id)
+		}
+	}
+}
+
+// httpSessionIDs issues and checks Streamable HTTP session IDs against the
+// session manager
+type httpSessionIDs struct {
+	sessions *SessionManager
+}
+
+// Generate opens a session for an initialize request. The transport handler
+// has already checked the session limit, so a failure here is a lost race
+// and the request is served without a session.
+func (h *httpSessionIDs) Generate() string {
+	id, err := h.sessions.Open(transportStreamableHTTP)
+	if err != nil {
+		return ""
+	}
+	return id
+}
+
+// Validate rejects requests without a session and reports closed or expired
+// sessions as terminated, which tells the client to initialize again
+func (h *httpSessionIDs) Validate(sessionID string) (isTerminated bool, err error) {
+	if sessionID == "" {
+		return false, errors.New("missing session ID")
+	}
+	return !h.sessions.Exists(sessionID), nil
+}
+
+// Terminate closes the session on the client's DELETE
+func (h *httpSessionIDs) Terminate(sessionID string) (isNotAllowed bool, err error) {
+	h.sessions.Close(sessionID)
+	return false, nil
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionManager_Limit(t *testing.T) {
+	m := NewSessionManager(2, 0)
+
+	a, err := m.Open(transportWebSocket)
+	require.NoError(t, err)
+	_, err = m.Open(transportStreamableHTTP)
+	require.NoError(t, err)
+	assert.True(t, m.Full())
+
+	_, err = m.Open(transportWebSocket)
+	assert.True(t, errors.Is(err, ErrTooManySessions))
+
+	assert.True(t, m.Close(a))
+	assert.False(t, m.Close(a), "closing twice reports the session gone")
+	_, err = m.Open(transportWebSocket)
+	assert.NoError(t, err, "closing a session frees a slot")
+	assert.Len(t, m.Sessions(), 2)
+}
+
+func TestSessionManager_ExpireIdle(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewSessionManager(0, time.Minute)
+	m.now = func() time.Time { return now }
+
+	idle, err := m.Open(transportStreamableHTTP)
+	require.NoError(t, err)
+	busy, err := m.Open(transportWebSocket)
+	require.NoError(t, err)
+
+	closed := false
+	m.OnClose(idle, func() { closed = true })
+	require.True(t, m.Acquire(busy))
+
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, []string{idle}, m.ExpireIdle())
+	assert.True(t, closed, "close functions run on expiry")
+	assert.False(t, m.Exists(idle))
+	assert.True(t, m.Exists(busy), "a session with work in flight is not idle")
+
+	m.Release(busy)
+	now = now.Add(30 * time.Second)
+	assert.Empty(t, m.ExpireIdle(), "release counts as activity")
+	assert.False(t, m.Acquire(idle))
+}
+
+func TestSessionManager_OnCloseAndCloseAll(t *testing.T) {
+	m := NewSessionManager(0, 0)
+	id, err := m.Open(transportWebSocket)
+	require.NoError(t, err)
+
+	calls := 0
+	remove := m.OnClose(id, func() { calls++ })
+	remove()
+	m.OnClose(id, func() { calls += 10 })
+
+	m.CloseAll()
+	assert.Equal(t, 10, calls, "removed close functions do not run")
+	assert.Zero(t, m.Count())
+
+	_, err = m.Open(transportWebSocket)
+	assert.True(t, errors.Is(err, ErrShuttingDown))
+
+	ran := false
+	m.OnClose(id, func() { ran = true })
+	assert.True(t, ran, "registering on a closed session runs immediately")
+}
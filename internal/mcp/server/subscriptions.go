@@ -0,0 +1,257 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"rocksdb-cli/internal/mcp/protocol"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	uriColumnFamilies = "rocksdb://column-families"
+	uriDataPrefix     = "rocksdb://data/"
+	uriPrefixPrefix   = "rocksdb://prefix/"
+)
+
+// watchedResource is a resource URI that can be subscribed to
+type watchedResource struct {
+	uri    string
+	cf     string
+	key    string // exact key for data URIs
+	prefix string // key prefix for prefix URIs
+	isCFs  bool   // the column family list
+}
+
+// parseWatchedResource parses a subscribable resource URI. Column family
+// names, keys and prefixes are percent-decoded as in resource templates; a
+// query string is ignored.
+func parseWatchedResource(uri string) (watchedResource, error) {
+	r := watchedResource{uri: uri}
+
+	path := uri
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	if path == uriColumnFamilies {
+		r.isCFs = true
+		return r, nil
+	}
+
+	var rest string
+	isPrefix := false
+	switch {
+	case strings.HasPrefix(path, uriDataPrefix):
+		rest = strings.TrimPrefix(path, uriDataPrefix)
+	case strings.HasPrefix(path, uriPrefixPrefix):
+		rest = strings.TrimPrefix(path, uriPrefixPrefix)
+		isPrefix = true
+	default:
+		return r, fmt.Errorf("resource %s does not support subscriptions", uri)
+	}
+
+	parts := strings.SplitN(rest, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return r, fmt.Errorf("invalid resource URI %s: expected a column family and a key", uri)
+	}
+	cf, err := url.PathUnescape(parts[0])
+	if err != nil {
+		return r, fmt.Errorf("failed to decode column family: %w", err)
+	}
+	key, err := url.PathUnescape(parts[1])
+	if err != nil {
+		return r, fmt.Errorf("failed to decode key: %w", err)
+	}
+
+	r.cf = cf
+	if isPrefix {
+		r.prefix = key
+	} else {
+		r.key = key
+	}
+	return r, nil
+}
+
+// matchesKey reports whether a write to key in cf changes the resource
+func (r watchedResource) matchesKey(cf, key string) bool {
+	if r.isCFs || r.cf != cf {
+		return false
+	}
+	if r.prefix != "" {
+		return strings.HasPrefix(key, r.prefix)
+	}
+	return r.key == key
+}
+
+// SubscriptionManager tracks resources/subscribe requests per session and
+// sends notifications/resources/updated when a subscribed resource changes.
+// Changes are reported by the database wrapper returned by Watch.
+type SubscriptionManager struct {
+	mu        sync.RWMutex
+	bySession map[string]map[string]watchedResource
+	cfHooks   []func()
+	notify    func(sessionID, uri string) error
+}
+
+// NewSubscriptionManager creates a subscription manager that notifies the
+// clients of mcpServer
+func NewSubscriptionManager(mcpServer *server.MCPServer) *SubscriptionManager {
+	return &SubscriptionManager{
+		bySession: make(map[string]map[string]watchedResource),
+		notify: func(sessionID, uri string) error {
+			return mcpServer.SendNotificationToSpecificClient(sessionID,
+				mcp.MethodNotificationResourceUpdated, map[string]any{"uri": uri})
+		},
+	}
+}
+
+// Register serves resources/subscribe and resources/unsubscribe on tm and
+// drops the subscriptions of sessions as they end
+func (sm *SubscriptionManager) Register(tm *TransportManager) {
+	tm.HandleMethod(protocol.MethodSubscribe, sm.handleSubscribe)
+	tm.HandleMethod(protocol.MethodUnsubscribe, sm.handleUnsubscribe)
+	tm.OnSessionClosed(sm.RemoveSession)
+}
+
+func (sm *SubscriptionManager) handleSubscribe(ctx context.Context, sessionID string, params json.RawMessage) (interface{}, error) {
+	uri, err := subscriptionURI(params)
+	if err != nil {
+		return nil, err
+	}
+	if err := sm.Subscribe(sessionID, uri); err != nil {
+		return nil, invalidParams("%v", err)
+	}
+	return nil, nil
+}
+
+func (sm *SubscriptionManager) handleUnsubscribe(ctx context.Context, sessionID string, params json.RawMessage) (interface{}, error) {
+	uri, err := subscriptionURI(params)
+	if err != nil {
+		return nil, err
+	}
+	sm.Unsubscribe(sessionID, uri)
+	return nil, nil
+}
+
+func subscriptionURI(params json.RawMessage) (string, error) {
+	var p struct {
+		URI string `json:"uri"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return "", invalidParams("invalid params: %v", err)
+		}
+	}
+	if p.URI == "" {
+		return "", invalidParams("uri is required")
+	}
+	return p.URI, nil
+}
+
+// Subscribe subscribes sessionID to uri
+func (sm *SubscriptionManager) Subscribe(sessionID, uri string) error {
+	if sessionID == "" {
+		return fmt.Errorf("subscriptions require a session")
+	}
+	r, err := parseWatchedResource(uri)
+	if err != nil {
+		return err
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	subs, ok := sm.bySession[sessionID]
+	if !ok {
+		subs = make(map[string]watchedResource)
+		sm.bySession[sessionID] = subs
+	}
+	subs[uri] = r
+	return nil
+}
+
+// Unsubscribe removes the subscription of sessionID to uri, if any
+func (sm *SubscriptionManager) Unsubscribe(sessionID, uri string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if subs, ok := sm.bySession[sessionID]; ok {
+		delete(subs, uri)
+		if len(subs) == 0 {
+			delete(sm.bySession, sessionID)
+		}
+	}
+}
+
+// RemoveSession drops every subscription of sessionID
+func (sm *SubscriptionManager) RemoveSession(sessionID string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	delete(sm.bySession, sessionID)
+}
+
+// Subscriptions returns the URIs sessionID is subscribed to, sorted
+func (sm *SubscriptionManager) Subscriptions(sessionID string) []string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	uris := make([]string, 0, len(sm.bySession[sessionID]))
+	for uri := range sm.bySession[sessionID] {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	return uris
+}
+
+// KeyChanged notifies the subscribers of resources that cover key in cf
+func (sm *SubscriptionManager) KeyChanged(cf, key string) {
+	sm.notifyMatching(func(r watchedResource) bool { return r.matchesKey(cf, key) })
+}
+
+// OnColumnFamiliesChanged registers fn to run after a column family is
+// created or dropped
+func (sm *SubscriptionManager) OnColumnFamiliesChanged(fn func()) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.cfHooks = append(sm.cfHooks, fn)
+}
+
+// ColumnFamilyChanged notifies the subscribers of the column family list. A
+// dropped column family also changes every resource within it.
+func (sm *SubscriptionManager) ColumnFamilyChanged(cf string, dropped bool) {
+	sm.mu.RLock()
+	hooks := sm.cfHooks
+	sm.mu.RUnlock()
+	for _, hook := range hooks {
+		hook()
+	}
+
+	sm.notifyMatching(func(r watchedResource) bool {
+		return r.isCFs || (dropped && r.cf == cf)
+	})
+}
+
+func (sm *SubscriptionManager) notifyMatching(match func(watchedResource) bool) {
+	type target struct{ sessionID, uri string }
+
+	sm.mu.RLock()
+	var targets []target
+	for sessionID, subs := range sm.bySession {
+		for uri, r := range subs {
+			if match(r) {
+				targets = append(targets, target{sessionID, uri})
+			}
+		}
+	}
+	sm.mu.RUnlock()
+
+	// A session without a notification stream, e.g. Streamable HTTP before
+	// its GET, misses the update; the subscription stays in place
+	for _, t := range targets {
+		sm.notify(t.sessionID, t.uri)
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// notification is one resources/updated notification sent by a test manager
+type notification struct {
+	sessionID string
+	uri       string
+}
+
+// newTestSubscriptionManager returns a subscription manager that records
+// notifications instead of sending them
+func newTestSubscriptionManager() (*SubscriptionManager, func() []notification) {
+	var mu sync.Mutex
+	var sent []notification
+
+	sm := NewSubscriptionManager(nil)
+	sm.notify = func(sessionID, uri string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, notification{sessionID, uri})
+		return nil
+	}
+	return sm, func() []notification {
+		mu.Lock()
+		defer mu.Unlock()
+		out := sent
+		sent = nil
+		return out
+	}
+}
+
+func TestParseWatchedResource(t *testing.T) {
+	tests := []struct {
+		uri     string
+		want    watchedResource
+		wantErr bool
+	}{
+		{uri: "rocksdb://column-families", want: watchedResource{isCFs: true}},
+		{uri: "rocksdb://data/users/user:1", want: watchedResource{cf: "users", key: "user:1"}},
+		{uri: "rocksdb://data/users/a%2Fb%20c", want: watchedResource{cf: "users", key: "a/b c"}},
+		{uri: "rocksdb://data/users/a/b", want: watchedResource{cf: "users", key: "a/b"}},
+		{uri: "rocksdb://data/users/a+b", want: watchedResource{cf: "users", key: "a+b"}},
+		{uri: "rocksdb://prefix/logs/2024-?limit=10", want: watchedResource{cf: "logs", prefix: "2024-"}},
+		{uri: "rocksdb://data/users", wantErr: true},
+		{uri: "rocksdb://prefix/logs/", wantErr: true},
+		{uri: "rocksdb://data/users/%zz", wantErr: true},
+		{uri: "rocksdb://stats", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			got, err := parseWatchedResource(tt.uri)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			tt.want.uri = tt.uri
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSubscriptionManager_Notify(t *testing.T) {
+	sm, sent := newTestSubscriptionManager()
+
+	require.NoError(t, sm.Subscribe("a", "rocksdb://data/users/user:1"))
+	require.NoError(t, sm.Subscribe("a", "rocksdb://column-families"))
+	require.NoError(t, sm.Subscribe("b", "rocksdb://prefix/users/user:"))
+	assert.Error(t, sm.Subscribe("", "rocksdb://column-families"), "subscriptions need a session")
+	assert.Error(t, sm.Subscribe("a", "rocksdb://stats"))
+
+	sm.KeyChanged("users", "user:1")
+	assert.ElementsMatch(t, []notification{
+		{"a", "rocksdb://data/users/user:1"},
+		{"b", "rocksdb://prefix/users/user:"},
+	}, sent())
+
+	sm.KeyChanged("users", "user:2")
+	assert.Equal(t, []notification{{"b", "rocksdb://prefix/users/user:"}}, sent())
+
+	sm.KeyChanged("orders", "user:1")
+	assert.Empty(t, sent(), "writes to other column families are not reported")
+
+	sm.ColumnFamilyChanged("orders", false)
+	assert.Equal(t, []notification{{"a", "rocksdb://column-families"}}, sent())
+
+	sm.ColumnFamilyChanged("users", true)
+	assert.ElementsMatch(t, []notification{
+		{"a", "rocksdb://column-families"},
+		{"a", "rocksdb://data/users/user:1"},
+		{"b", "rocksdb://prefix/users/user:"},
+	}, sent(), "dropping a column family changes its resources")
+
+	sm.Unsubscribe("a", "rocksdb://data/users/user:1")
+	assert.Equal(t, []string{"rocksdb://column-families"}, sm.Subscriptions("a"))
+	sm.RemoveSession("b")
+	assert.Empty(t, sm.Subscriptions("b"))
+
+	sm.KeyChanged("users", "user:1")
+	assert.Empty(t, sent())
+}
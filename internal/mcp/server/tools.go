@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"rocksdb-cli/internal/db"
 	"rocksdb-cli/internal/jsonutil"
+	"rocksdb-cli/internal/schema"
+	"rocksdb-cli/internal/timeseries"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -15,22 +18,55 @@ import (
 
 // ToolManager manages MCP tools for RocksDB operations
 type ToolManager struct {
-	db     db.KeyValueDB
-	config *Config
+	databases *Databases
+	config    *Config
 }
 
-// NewToolManager creates a new tool manager
+// NewToolManager creates a new tool manager for a single database
 func NewToolManager(database db.KeyValueDB, config *Config) *ToolManager {
+	return NewToolManagerWithDatabases(SingleDatabase(database, config.DatabasePath), config)
+}
+
+// NewToolManagerWithDatabases creates a new tool manager whose tools address
+// the databases by their database argument or the session's default
+func NewToolManagerWithDatabases(databases *Databases, config *Config) *ToolManager {
 	return &ToolManager{
-		db:     database,
-		config: config,
+		databases: databases,
+		config:    config,
+	}
+}
+
+// newTool creates a tool that takes the optional database argument
+func newTool(name string, opts ...mcp.ToolOption) mcp.Tool {
+	opts = append(opts, mcp.WithString("database",
+		mcp.Description("Database name from rocksdb_list_databases (defaults to the session's database)"),
+	))
+	return mcp.NewTool(name, opts...)
+}
+
+// database returns the database a tool call addresses
+func (tm *ToolManager) database(ctx context.Context, request mcp.CallToolRequest) (db.KeyValueDB, error) {
+	database, _, err := tm.databases.ForSession(contextSessionID(ctx), request.GetString("database", ""))
+	return database, err
+}
+
+// writableDatabase returns the database a writing tool call addresses, or an
+// error if that database is read-only
+func (tm *ToolManager) writableDatabase(ctx context.Context, request mcp.CallToolRequest) (db.KeyValueDB, error) {
+	database, name, err := tm.databases.ForSession(contextSessionID(ctx), request.GetString("database", ""))
+	if err != nil {
+		return nil, err
+	}
+	if database.IsReadOnly() {
+		return nil, fmt.Errorf("database '%s' is read-only", name)
 	}
+	return database, nil
 }
 
 // RegisterTools registers all available tools with the MCP server
 func (tm *ToolManager) RegisterTools(s *server.MCPServer) error {
 	// RocksDB Get Tool
-	getRocksDBTool := mcp.NewTool("rocksdb_get",
+	getRocksDBTool := newTool("rocksdb_get",
 		mcp.WithDescription("Get a value by key from RocksDB"),
 		mcp.WithString("key",
 			mcp.Required(),
@@ -47,7 +83,7 @@ func (tm *ToolManager) RegisterTools(s *server.MCPServer) error {
 
 	// RocksDB Put Tool (only if not read-only)
 	if !tm.config.ReadOnly {
-		putRocksDBTool := mcp.NewTool("rocksdb_put",
+		putRocksDBTool := newTool("rocksdb_put",
 			mcp.WithDescription("Put a key-value pair into RocksDB"),
 			mcp.WithString("key",
 				mcp.Required(),
@@ -62,10 +98,26 @@ func (tm *ToolManager) RegisterTools(s *server.MCPServer) error {
 			),
 		)
 		s.AddTool(putRocksDBTool, tm.handlePutTool)
+
+		mergeRocksDBTool := newTool("rocksdb_merge",
+			mcp.WithDescription("Apply a merge operand to a key using the column family's merge operator (e.g. uint64add counters, stringappend, jsonmerge)"),
+			mcp.WithString("key",
+				mcp.Required(),
+				mcp.Description("The key to merge into"),
+			),
+			mcp.WithString("operand",
+				mcp.Required(),
+				mcp.Description("The merge operand, e.g. an integer for uint64add or a JSON patch for jsonmerge"),
+			),
+			mcp.WithString("column_family",
+				mcp.Description("Column family name (defaults to 'default')"),
+			),
+		)
+		s.AddTool(mergeRocksDBTool, tm.handleMergeTool)
 	}
 
 	// RocksDB Scan Tool
-	scanRocksDBTool := mcp.NewTool("rocksdb_scan",
+	scanRocksDBTool := newTool("rocksdb_scan",
 		mcp.WithDescription("Scan a range of keys from RocksDB"),
 		mcp.WithString("column_family",
 			mcp.Description("Column family name (defaults to 'default')"),
@@ -89,7 +141,7 @@ func (tm *ToolManager) RegisterTools(s *server.MCPServer) error {
 	s.AddTool(scanRocksDBTool, tm.handleScanTool)
 
 	// RocksDB Prefix Scan Tool
-	prefixScanTool := mcp.NewTool("rocksdb_prefix_scan",
+	prefixScanTool := newTool("rocksdb_prefix_scan",
 		mcp.WithDescription("Scan keys with a specific prefix from RocksDB"),
 		mcp.WithString("prefix",
 			mcp.Required(),
@@ -105,14 +157,14 @@ func (tm *ToolManager) RegisterTools(s *server.MCPServer) error {
 	s.AddTool(prefixScanTool, tm.handlePrefixScanTool)
 
 	// List Column Families Tool
-	listCFTool := mcp.NewTool("rocksdb_list_column_families",
+	listCFTool := newTool("rocksdb_list_column_families",
 		mcp.WithDescription("List all column families in the database"),
 	)
 	s.AddTool(listCFTool, tm.handleListCFTool)
 
 	// Create Column Family Tool (only if not read-only)
 	if !tm.config.ReadOnly {
-		createCFTool := mcp.NewTool("rocksdb_create_column_family",
+		createCFTool := newTool("rocksdb_create_column_family",
 			mcp.WithDescription("Create a new column family"),
 			mcp.WithString("name",
 				mcp.Required(),
@@ -122,7 +174,7 @@ func (tm *ToolManager) RegisterTools(s *server.MCPServer) error {
 		s.AddTool(createCFTool, tm.handleCreateCFTool)
 
 		// Drop Column Family Tool
-		dropCFTool := mcp.NewTool("rocksdb_drop_column_family",
+		dropCFTool := newTool("rocksdb_drop_column_family",
 			mcp.WithDescription("Drop an existing column family"),
 			mcp.WithString("name",
 				mcp.Required(),
@@ -133,7 +185,7 @@ func (tm *ToolManager) RegisterTools(s *server.MCPServer) error {
 	}
 
 	// Export to CSV Tool
-	exportCSVTool := mcp.NewTool("rocksdb_export_to_csv",
+	exportCSVTool := newTool("rocksdb_export_to_csv",
 		mcp.WithDescription("Export column family data to CSV file"),
 		mcp.WithString("column_family",
 			mcp.Required(),
@@ -143,11 +195,14 @@ func (tm *ToolManager) RegisterTools(s *server.MCPServer) error {
 			mcp.Required(),
 			mcp.Description("Output CSV file path"),
 		),
+		mcp.WithString("cursor",
+			mcp.Description("Resume a stopped export after this cursor, appending to the file"),
+		),
 	)
 	s.AddTool(exportCSVTool, tm.handleExportCSVTool)
 
 	// JSON Query Tool
-	jsonQueryTool := mcp.NewTool("rocksdb_json_query",
+	jsonQueryTool := newTool("rocksdb_json_query",
 		mcp.WithDescription("Query JSON values by field"),
 		mcp.WithString("column_family",
 			mcp.Description("Column family name (defaults to 'default')"),
@@ -167,7 +222,7 @@ func (tm *ToolManager) RegisterTools(s *server.MCPServer) error {
 	s.AddTool(jsonQueryTool, tm.handleJSONQueryTool)
 
 	// Get Last Tool
-	getLastTool := mcp.NewTool("rocksdb_get_last",
+	getLastTool := newTool("rocksdb_get_last",
 		mcp.WithDescription("Get the last key-value pair from a column family"),
 		mcp.WithString("column_family",
 			mcp.Description("Column family name (defaults to 'default')"),
@@ -179,7 +234,7 @@ func (tm *ToolManager) RegisterTools(s *server.MCPServer) error {
 	s.AddTool(getLastTool, tm.handleGetLastTool)
 
 	// RocksDB Search Tool
-	searchTool := mcp.NewTool("rocksdb_search",
+	searchTool := newTool("rocksdb_search",
 		mcp.WithDescription("Fuzzy search for keys and values in RocksDB"),
 		mcp.WithString("column_family",
 			mcp.Description("Column family name (defaults to 'default')"),
@@ -202,6 +257,9 @@ func (tm *ToolManager) RegisterTools(s *server.MCPServer) error {
 		mcp.WithNumber("limit",
 			mcp.Description("Maximum number of results (default: 50)"),
 		),
+		mcp.WithString("cursor",
+			mcp.Description("Continue a limited or stopped search after this cursor"),
+		),
 		mcp.WithBoolean("pretty",
 			mcp.Description("Pretty print JSON values"),
 		),
@@ -209,7 +267,7 @@ func (tm *ToolManager) RegisterTools(s *server.MCPServer) error {
 	s.AddTool(searchTool, tm.handleSearchTool)
 
 	// RocksDB Statistics Tool
-	statsTool := mcp.NewTool("rocksdb_get_stats",
+	statsTool := newTool("rocksdb_get_stats",
 		mcp.WithDescription("Get database or column family statistics"),
 		mcp.WithString("column_family",
 			mcp.Description("Column family name (omit for database-wide stats)"),
@@ -217,18 +275,146 @@ func (tm *ToolManager) RegisterTools(s *server.MCPServer) error {
 		mcp.WithBoolean("detailed",
 			mcp.Description("Show detailed statistics"),
 		),
+		mcp.WithString("cursor",
+			mcp.Description("Count only the keys after this cursor of a stopped column family scan"),
+		),
 		mcp.WithBoolean("pretty",
 			mcp.Description("Pretty print JSON format"),
 		),
 	)
 	s.AddTool(statsTool, tm.handleStatsTool)
 
+	// Time-Series Tools
+	timeParams := []mcp.ToolOption{
+		mcp.WithString("encoding",
+			mcp.Description("How keys start with a time: ticks, ns, us, ms, s or iso (detected from the keys by default)"),
+		),
+		mcp.WithString("prefix",
+			mcp.Description("Key prefix the time follows"),
+		),
+	}
+	timeRangeTool := newTool("rocksdb_time_range", append([]mcp.ToolOption{
+		mcp.WithDescription("Get the entries of a column family whose keys start with a time (.NET ticks, Unix s/ms/us/ns timestamps or ISO 8601 text) between two times, oldest first. Call again with the returned cursor for the next page or for entries written since."),
+		mcp.WithString("column_family",
+			mcp.Description("Column family name (defaults to 'default')"),
+		),
+		mcp.WithString("from",
+			mcp.Description("Start time, included: RFC 3339, 2006-01-02T15:04 (UTC), a date, now or an age like 1h or 7d"),
+		),
+		mcp.WithString("to",
+			mcp.Description("End time, excluded, in the same formats"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of entries (default: 100)"),
+		),
+		mcp.WithBoolean("reverse",
+			mcp.Description("Newest entries first"),
+		),
+		mcp.WithBoolean("keys_only",
+			mcp.Description("Return only keys, not values"),
+		),
+		mcp.WithString("cursor",
+			mcp.Description("Continue after this cursor of a previous call"),
+		),
+		mcp.WithBoolean("pretty",
+			mcp.Description("Pretty print JSON values"),
+		),
+	}, timeParams...)...)
+	s.AddTool(timeRangeTool, tm.handleTimeRangeTool)
+
+	latestTool := newTool("rocksdb_latest", append([]mcp.ToolOption{
+		mcp.WithDescription("Get the N newest entries of a column family whose keys start with a time, newest first"),
+		mcp.WithString("column_family",
+			mcp.Description("Column family name (defaults to 'default')"),
+		),
+		mcp.WithNumber("n",
+			mcp.Description("Number of entries (default: 10)"),
+		),
+		mcp.WithBoolean("keys_only",
+			mcp.Description("Return only keys, not values"),
+		),
+		mcp.WithBoolean("pretty",
+			mcp.Description("Pretty print JSON values"),
+		),
+	}, timeParams...)...)
+	s.AddTool(latestTool, tm.handleLatestTool)
+
+	timeHistogramTool := newTool("rocksdb_time_histogram", append([]mcp.ToolOption{
+		mcp.WithDescription("Count the entries of a column family whose keys start with a time per time bucket, such as events per hour"),
+		mcp.WithString("column_family",
+			mcp.Description("Column family name (defaults to 'default')"),
+		),
+		mcp.WithString("bucket",
+			mcp.Description("Bucket width: a duration such as 15m or 1h, or days such as 1d (default: 1h)"),
+		),
+		mcp.WithString("from",
+			mcp.Description("Start time, included: RFC 3339, 2006-01-02T15:04 (UTC), a date, now or an age like 1h or 7d"),
+		),
+		mcp.WithString("to",
+			mcp.Description("End time, excluded, in the same formats"),
+		),
+	}, timeParams...)...)
+	s.AddTool(timeHistogramTool, tm.handleTimeHistogramTool)
+
+	// Schema Tools
+	schemaInferTool := newTool("rocksdb_schema_infer",
+		mcp.WithDescription("Infer a JSON Schema from a sample of the JSON values of a column family: the fields with the share of values having them, type unions, enum candidates and examples. Pass the schema to rocksdb_schema_check to find values that drifted from it."),
+		mcp.WithString("column_family",
+			mcp.Description("Column family name (defaults to 'default')"),
+		),
+		mcp.WithString("prefix",
+			mcp.Description("Only values of keys with this prefix"),
+		),
+		mcp.WithNumber("sample",
+			mcp.Description("Number of JSON values sampled (default: 1000)"),
+		),
+	)
+	s.AddTool(schemaInferTool, tm.handleSchemaInferTool)
+
+	schemaCheckTool := newTool("rocksdb_schema_check",
+		mcp.WithDescription("Check the values of a column family against a JSON Schema, such as one from rocksdb_schema_infer: lists the values violating it with the path and reason, and the fields it does not know"),
+		mcp.WithString("schema",
+			mcp.Required(),
+			mcp.Description("The JSON Schema as a JSON string"),
+		),
+		mcp.WithString("column_family",
+			mcp.Description("Column family name (defaults to the one the schema was inferred from, or 'default')"),
+		),
+		mcp.WithString("prefix",
+			mcp.Description("Only values of keys with this prefix (defaults to the prefix the schema was inferred from)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of violations listed; all are counted (default: 100)"),
+		),
+	)
+	s.AddTool(schemaCheckTool, tm.handleSchemaCheckTool)
+
+	// Database Selection Tools
+	listDatabasesTool := mcp.NewTool("rocksdb_list_databases",
+		mcp.WithDescription("List the databases hosted by this server"),
+	)
+	s.AddTool(listDatabasesTool, tm.handleListDatabasesTool)
+
+	openDatabaseTool := mcp.NewTool("rocksdb_open_database",
+		mcp.WithDescription("Open a database and make it the default of this session"),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Database name from rocksdb_list_databases"),
+		),
+	)
+	s.AddTool(openDatabaseTool, tm.handleOpenDatabaseTool)
+
 	return nil
 }
 
 // Tool handlers
 
 func (tm *ToolManager) handleGetTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	database, err := tm.database(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	key, err := request.RequireString("key")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
@@ -238,7 +424,7 @@ func (tm *ToolManager) handleGetTool(ctx context.Context, request mcp.CallToolRe
 
 	pretty := request.GetBool("pretty", false)
 
-	value, err := tm.db.GetCF(cf, key)
+	value, err := database.GetCF(cf, key)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get key '%s' from CF '%s': %v", key, cf, err)), nil
 	}
@@ -248,7 +434,13 @@ func (tm *ToolManager) handleGetTool(ctx context.Context, request mcp.CallToolRe
 		result = tm.formatJSONValue(value)
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Key: %s\nValue: %s", key, result)), nil
+	text := fmt.Sprintf("Key: %s\nValue: %s", key, result)
+	if database.TTLMode() {
+		if _, info, err := database.GetCFWithTTL(cf, key); err == nil && info != nil {
+			text += fmt.Sprintf("\nTTL: %s", info)
+		}
+	}
+	return mcp.NewToolResultText(text), nil
 }
 
 func (tm *ToolManager) handlePutTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -256,6 +448,11 @@ func (tm *ToolManager) handlePutTool(ctx context.Context, request mcp.CallToolRe
 		return mcp.NewToolResultError("Write operations are not allowed in read-only mode"), nil
 	}
 
+	database, err := tm.writableDatabase(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	key, err := request.RequireString("key")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
@@ -268,14 +465,48 @@ func (tm *ToolManager) handlePutTool(ctx context.Context, request mcp.CallToolRe
 
 	cf := request.GetString("column_family", "default")
 
-	if err := tm.db.PutCF(cf, key, value); err != nil {
+	if err := database.PutCF(cf, key, value); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to put key '%s' to CF '%s': %v", key, cf, err)), nil
 	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Successfully stored key '%s' in column family '%s'", key, cf)), nil
 }
 
+func (tm *ToolManager) handleMergeTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if tm.config.ReadOnly {
+		return mcp.NewToolResultError("Write operations are not allowed in read-only mode"), nil
+	}
+
+	database, err := tm.writableDatabase(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	key, err := request.RequireString("key")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	operand, err := request.RequireString("operand")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cf := request.GetString("column_family", "default")
+
+	if err := database.MergeCF(cf, key, operand); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to merge key '%s' in CF '%s': %v", key, cf, err)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully merged operand into key '%s' in column family '%s'", key, cf)), nil
+}
+
 func (tm *ToolManager) handleScanTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	database, err := tm.database(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	cf := request.GetString("column_family", "default")
 
 	startKey := request.GetString("start_key", "")
@@ -295,7 +526,7 @@ func (tm *ToolManager) handleScanTool(ctx context.Context, request mcp.CallToolR
 		Values:  !valuesOnly,
 	}
 
-	results, err := tm.db.ScanCF(cf, []byte(startKey), []byte(endKey), opts)
+	results, err := database.ScanCF(cf, []byte(startKey), []byte(endKey), opts)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to scan CF '%s': %v", cf, err)), nil
 	}
@@ -319,6 +550,11 @@ func (tm *ToolManager) handleScanTool(ctx context.Context, request mcp.CallToolR
 }
 
 func (tm *ToolManager) handlePrefixScanTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	database, err := tm.database(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	prefix, err := request.RequireString("prefix")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
@@ -332,7 +568,7 @@ func (tm *ToolManager) handlePrefixScanTool(ctx context.Context, request mcp.Cal
 		limit = 100 // Default limit
 	}
 
-	results, err := tm.db.PrefixScanCF(cf, prefix, limit)
+	results, err := database.PrefixScanCF(cf, prefix, limit)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to prefix scan CF '%s': %v", cf, err)), nil
 	}
@@ -352,7 +588,12 @@ func (tm *ToolManager) handlePrefixScanTool(ctx context.Context, request mcp.Cal
 }
 
 func (tm *ToolManager) handleListCFTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	cfs, err := tm.db.ListCFs()
+	database, err := tm.database(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cfs, err := database.ListCFs()
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to list column families: %v", err)), nil
 	}
@@ -375,12 +616,17 @@ func (tm *ToolManager) handleCreateCFTool(ctx context.Context, request mcp.CallT
 		return mcp.NewToolResultError("Write operations are not allowed in read-only mode"), nil
 	}
 
+	database, err := tm.writableDatabase(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	name, err := request.RequireString("name")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	if err := tm.db.CreateCF(name); err != nil {
+	if err := database.CreateCF(name); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to create column family '%s': %v", name, err)), nil
 	}
 
@@ -392,12 +638,17 @@ func (tm *ToolManager) handleDropCFTool(ctx context.Context, request mcp.CallToo
 		return mcp.NewToolResultError("Write operations are not allowed in read-only mode"), nil
 	}
 
+	database, err := tm.writableDatabase(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	name, err := request.RequireString("name")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	if err := tm.db.DropCF(name); err != nil {
+	if err := database.DropCF(name); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to drop column family '%s': %v", name, err)), nil
 	}
 
@@ -405,6 +656,11 @@ func (tm *ToolManager) handleDropCFTool(ctx context.Context, request mcp.CallToo
 }
 
 func (tm *ToolManager) handleExportCSVTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	database, err := tm.database(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	cf, err := request.RequireString("column_family")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
@@ -415,14 +671,31 @@ func (tm *ToolManager) handleExportCSVTool(ctx context.Context, request mcp.Call
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	if err := tm.db.ExportToCSV(cf, outputFile, ","); err != nil {
+	op, done := startOperation(ctx, request, "export")
+	defer done()
+
+	result, err := database.ExportToCSVWithOptions(cf, outputFile, db.ExportOptions{
+		Sep:      ",",
+		After:    request.GetString("cursor", ""),
+		Progress: op.Progress,
+	})
+	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to export CF '%s' to '%s': %v", cf, outputFile, err)), nil
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Successfully exported column family '%s' to '%s'", cf, outputFile)), nil
+	if result.HasMore {
+		return mcp.NewToolResultText(fmt.Sprintf("Export of column family '%s' to '%s' was cancelled after %d rows\nResume with cursor: %s",
+			cf, outputFile, result.Rows, result.NextCursor)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully exported column family '%s' to '%s' (%d rows)", cf, outputFile, result.Rows)), nil
 }
 
 func (tm *ToolManager) handleJSONQueryTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	database, err := tm.database(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	cf := request.GetString("column_family", "default")
 
 	field, err := request.RequireString("field")
@@ -437,7 +710,7 @@ func (tm *ToolManager) handleJSONQueryTool(ctx context.Context, request mcp.Call
 
 	pretty := request.GetBool("pretty", false)
 
-	results, err := tm.db.JSONQueryCF(cf, field, value)
+	results, err := database.JSONQueryCF(cf, field, value)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to query JSON field '%s' in CF '%s': %v", field, cf, err)), nil
 	}
@@ -461,11 +734,16 @@ func (tm *ToolManager) handleJSONQueryTool(ctx context.Context, request mcp.Call
 }
 
 func (tm *ToolManager) handleGetLastTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	database, err := tm.database(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	cf := request.GetString("column_family", "default")
 
 	pretty := request.GetBool("pretty", false)
 
-	key, value, err := tm.db.GetLastCF(cf)
+	key, value, err := database.GetLastCF(cf)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get last entry from CF '%s': %v", cf, err)), nil
 	}
@@ -480,6 +758,11 @@ func (tm *ToolManager) handleGetLastTool(ctx context.Context, request mcp.CallTo
 
 // handleSearchTool performs fuzzy search in RocksDB
 func (tm *ToolManager) handleSearchTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	database, err := tm.database(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	cf := request.GetString("column_family", "default")
 	keyPattern := request.GetString("key_pattern", "")
 	valuePattern := request.GetString("value_pattern", "")
@@ -507,22 +790,37 @@ func (tm *ToolManager) handleSearchTool(ctx context.Context, request mcp.CallToo
 		CaseSensitive: caseSensitive,
 		KeysOnly:      keysOnly,
 		Limit:         limit,
+		After:         request.GetString("cursor", ""),
 	}
 
+	op, done := startOperation(ctx, request, "search")
+	defer done()
+	searchOpts.Progress = op.Progress
+
 	// Execute search
-	results, err := tm.db.SearchCF(cf, searchOpts)
+	results, err := database.SearchCF(cf, searchOpts)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to search CF '%s': %v", cf, err)), nil
 	}
 
+	cursorText := ""
+	if results.HasMore {
+		cursorText = fmt.Sprintf("\nNext cursor: %s", results.NextCursor)
+	}
+
 	if len(results.Results) == 0 {
+		if op.Cancelled() {
+			return mcp.NewToolResultText(fmt.Sprintf("Search in column family '%s' was cancelled before any match%s", cf, cursorText)), nil
+		}
 		return mcp.NewToolResultText(fmt.Sprintf("No matches found in column family '%s'\nQuery took: %s", cf, results.QueryTime)), nil
 	}
 
 	// Format output
 	var output strings.Builder
 	limitedText := ""
-	if results.Limited {
+	if op.Cancelled() {
+		limitedText = " (cancelled, partial)"
+	} else if results.Limited {
 		limitedText = " (limited)"
 	}
 
@@ -545,23 +843,35 @@ func (tm *ToolManager) handleSearchTool(ctx context.Context, request mcp.CallToo
 			}
 			output.WriteString(fmt.Sprintf("    Value: %s\n", resultValue))
 		}
+		if result.TTL != nil {
+			output.WriteString(fmt.Sprintf("    TTL: %s\n", result.TTL))
+		}
 		if i < len(results.Results)-1 {
 			output.WriteString("\n")
 		}
 	}
+	output.WriteString(cursorText)
 
 	return mcp.NewToolResultText(output.String()), nil
 }
 
 // handleStatsTool gets database or column family statistics
 func (tm *ToolManager) handleStatsTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	database, err := tm.database(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	cf := request.GetString("column_family", "")
 	detailed := request.GetBool("detailed", false)
 	pretty := request.GetBool("pretty", false)
 
+	op, done := startOperation(ctx, request, "stats")
+	defer done()
+
 	if cf == "" {
 		// Database-wide statistics
-		stats, err := tm.db.GetDatabaseStats()
+		stats, err := tm.databaseStats(database, op)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to get database stats: %v", err)), nil
 		}
@@ -578,6 +888,9 @@ func (tm *ToolManager) handleStatsTool(ctx context.Context, request mcp.CallTool
 		// Format human-readable output
 		var output strings.Builder
 		output.WriteString("=== Database Statistics ===\n")
+		if op.Cancelled() {
+			output.WriteString(fmt.Sprintf("Partial: cancelled after %d of %d column families\n", len(stats.ColumnFamilies), stats.ColumnFamilyCount))
+		}
 		output.WriteString(fmt.Sprintf("Column Families: %d\n", stats.ColumnFamilyCount))
 		output.WriteString(fmt.Sprintf("Total Keys: %s\n", tm.formatNumber(stats.TotalKeyCount)))
 		output.WriteString(fmt.Sprintf("Total Size: %s\n", tm.formatBytes(stats.TotalSize)))
@@ -595,7 +908,10 @@ func (tm *ToolManager) handleStatsTool(ctx context.Context, request mcp.CallTool
 		return mcp.NewToolResultText(output.String()), nil
 	} else {
 		// Column family specific statistics
-		stats, err := tm.db.GetCFStats(cf)
+		stats, err := database.GetCFStatsWithOptions(cf, db.StatsOptions{
+			After:    request.GetString("cursor", ""),
+			Progress: op.Progress,
+		})
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to get stats for CF '%s': %v", cf, err)), nil
 		}
@@ -612,6 +928,9 @@ func (tm *ToolManager) handleStatsTool(ctx context.Context, request mcp.CallTool
 		// Format human-readable output
 		var output strings.Builder
 		output.WriteString(fmt.Sprintf("=== Column Family: %s ===\n", stats.Name))
+		if stats.HasMore {
+			output.WriteString(fmt.Sprintf("Partial: cancelled after %s keys\nResume with cursor: %s\n", tm.formatNumber(stats.KeyCount), stats.NextCursor))
+		}
 		output.WriteString(fmt.Sprintf("Keys: %s\n", tm.formatNumber(stats.KeyCount)))
 		output.WriteString(fmt.Sprintf("Total Key Size: %s\n", tm.formatBytes(stats.TotalKeySize)))
 		output.WriteString(fmt.Sprintf("Total Value Size: %s\n", tm.formatBytes(stats.TotalValueSize)))
@@ -652,6 +971,341 @@ func (tm *ToolManager) handleStatsTool(ctx context.Context, request mcp.CallTool
 	}
 }
 
+// handleTimeRangeTool returns the entries of a time-keyed column family
+// between two times
+func (tm *ToolManager) handleTimeRangeTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	database, err := tm.database(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	q, err := timeQuery(database, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	limit := int(request.GetFloat("limit", 0))
+	if limit <= 0 {
+		limit = 100
+	}
+	keysOnly := request.GetBool("keys_only", false)
+
+	op, done := startOperation(ctx, request, "range")
+	defer done()
+
+	page, err := timeseries.Range(database, q, timeseries.Options{
+		Limit:    limit,
+		Reverse:  request.GetBool("reverse", false),
+		Values:   !keysOnly,
+		After:    request.GetString("cursor", ""),
+		Progress: op.Progress,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read range of CF '%s': %v", q.CF, err)), nil
+	}
+
+	var output strings.Builder
+	if op.Cancelled() {
+		output.WriteString(fmt.Sprintf("Range of column family '%s' was cancelled\n", q.CF))
+	}
+	output.WriteString(fmt.Sprintf("%d entries of column family '%s' (keys: %s):\n", len(page.Entries), q.CF, q.Codec))
+	tm.writeTimeEntries(&output, page.Entries, keysOnly, request.GetBool("pretty", false))
+	if page.HasMore {
+		output.WriteString(fmt.Sprintf("More entries: continue with cursor %s\n", page.NextCursor))
+	} else if page.NextCursor != "" {
+		output.WriteString(fmt.Sprintf("Entries written later: call again with cursor %s\n", page.NextCursor))
+	}
+	return mcp.NewToolResultText(output.String()), nil
+}
+
+// handleLatestTool returns the newest entries of a time-keyed column family
+func (tm *ToolManager) handleLatestTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	database, err := tm.database(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	q, err := timeQuery(database, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	n := int(request.GetFloat("n", 0))
+	if n <= 0 {
+		n = 10
+	}
+	keysOnly := request.GetBool("keys_only", false)
+
+	page, err := timeseries.Latest(database, q, n, !keysOnly)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read latest entries of CF '%s': %v", q.CF, err)), nil
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("%d newest entries of column family '%s' (keys: %s):\n", len(page.Entries), q.CF, q.Codec))
+	tm.writeTimeEntries(&output, page.Entries, keysOnly, request.GetBool("pretty", false))
+	return mcp.NewToolResultText(output.String()), nil
+}
+
+// handleTimeHistogramTool counts the entries of a time-keyed column family
+// per time bucket
+func (tm *ToolManager) handleTimeHistogramTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	database, err := tm.database(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	bucket, err := timeseries.ParseBucket(request.GetString("bucket", "1h"))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	q, err := timeQuery(database, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	op, done := startOperation(ctx, request, "histogram")
+	defer done()
+
+	hist, err := timeseries.NewHistogram(database, q, bucket, op.Progress)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to build histogram of CF '%s': %v", q.CF, err)), nil
+	}
+
+	var output strings.Builder
+	if hist.HasMore {
+		output.WriteString("Partial: cancelled before the end of the range\n")
+	}
+	output.WriteString(fmt.Sprintf("%s entries of column family '%s' in %s buckets (keys: %s):\n", tm.formatNumber(hist.Total), q.CF, bucket, q.Codec))
+	for _, b := range hist.Buckets {
+		output.WriteString(fmt.Sprintf("%s  %s\n", b.Start.Format(time.RFC3339), tm.formatNumber(b.Count)))
+	}
+	return mcp.NewToolResultText(output.String()), nil
+}
+
+// timeQuery reads the column family, time bounds, encoding and prefix
+// arguments of a time-series tool and detects the time encoding of the keys
+func timeQuery(database db.KeyValueDB, request mcp.CallToolRequest) (timeseries.Query, error) {
+	q := timeseries.Query{CF: request.GetString("column_family", "default")}
+	enc, err := timeseries.ParseEncoding(request.GetString("encoding", ""))
+	if err != nil {
+		return q, err
+	}
+	now := time.Now()
+	for _, p := range []struct {
+		name   string
+		target *time.Time
+	}{{"from", &q.From}, {"to", &q.To}} {
+		if v := request.GetString(p.name, ""); v != "" {
+			if *p.target, err = timeseries.ParseTime(v, now); err != nil {
+				return q, fmt.Errorf("invalid %s: %w", p.name, err)
+			}
+		}
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		return q, fmt.Errorf("from must be before to")
+	}
+
+	if q.Codec, err = timeseries.Detect(database, q.CF, request.GetString("prefix", ""), enc); err != nil {
+		return q, fmt.Errorf("failed to detect the time encoding of CF '%s': %w", q.CF, err)
+	}
+	return q, nil
+}
+
+// writeTimeEntries writes entries as "<time>  <key>: <value>"
+func (tm *ToolManager) writeTimeEntries(output *strings.Builder, entries []db.KeyValue, keysOnly, pretty bool) {
+	for _, kv := range entries {
+		switch {
+		case keysOnly:
+			output.WriteString(fmt.Sprintf("%s  %s\n", kv.Timestamp, kv.Key))
+		case pretty:
+			output.WriteString(fmt.Sprintf("%s  %s: %s\n", kv.Timestamp, kv.Key, tm.formatJSONValue(kv.Value)))
+		default:
+			output.WriteString(fmt.Sprintf("%s  %s: %s\n", kv.Timestamp, kv.Key, kv.Value))
+		}
+	}
+}
+
+// handleSchemaInferTool infers the JSON Schema of the JSON values of a
+// column family
+func (tm *ToolManager) handleSchemaInferTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	database, err := tm.database(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cf := request.GetString("column_family", "default")
+	op, done := startOperation(ctx, request, "schema infer")
+	defer done()
+
+	inferred, stats, err := schema.InferCF(database, cf, request.GetString("prefix", ""), int(request.GetFloat("sample", 0)), op.Progress)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to infer the schema of CF '%s': %v", cf, err)), nil
+	}
+
+	var output strings.Builder
+	src := inferred.Source
+	if stats.HasMore {
+		output.WriteString(fmt.Sprintf("Partial: cancelled after %s keys\n", tm.formatNumber(stats.KeyCount)))
+	}
+	output.WriteString(fmt.Sprintf("Schema of column family '%s' from %s of %s JSON values (%s keys):\n",
+		cf, tm.formatNumber(src.Sampled), tm.formatNumber(src.JSONValues), tm.formatNumber(src.Keys)))
+	for _, f := range inferred.Fields() {
+		output.WriteString(fmt.Sprintf("- %s: %s, %g%% present", f.Path, f.Type, f.Presence))
+		if f.Required {
+			output.WriteString(", required")
+		}
+		if len(f.Enum) > 0 {
+			output.WriteString(", one of " + schema.FormatValues(f.Enum))
+		} else if len(f.Examples) > 0 {
+			output.WriteString(", e.g. " + schema.FormatValues(f.Examples))
+		}
+		output.WriteString("\n")
+	}
+	data, _ := json.MarshalIndent(inferred, "", "  ")
+	output.WriteString("\nJSON Schema:\n")
+	output.Write(data)
+	return mcp.NewToolResultText(output.String()), nil
+}
+
+// handleSchemaCheckTool checks the values of a column family against a
+// JSON Schema
+func (tm *ToolManager) handleSchemaCheckTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	database, err := tm.database(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	text, err := request.RequireString("schema")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	s, err := schema.Parse([]byte(text))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Omitted arguments default to the values the schema was inferred from
+	cf, prefix := "default", ""
+	if s.Source != nil {
+		cf, prefix = s.Source.ColumnFamily, s.Source.Prefix
+	}
+	cf = request.GetString("column_family", cf)
+	prefix = request.GetString("prefix", prefix)
+
+	op, done := startOperation(ctx, request, "schema check")
+	defer done()
+
+	c := schema.NewChecker(s, int(request.GetFloat("limit", 0)))
+	if err := schema.CheckCF(database, cf, prefix, c, op.Progress); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to check CF '%s': %v", cf, err)), nil
+	}
+	report := c.Report()
+
+	var output strings.Builder
+	if report.HasMore {
+		output.WriteString("Partial: cancelled before the end of the column family\n")
+	}
+	output.WriteString(fmt.Sprintf("Checked %s values of column family '%s': %s violate the schema (%s violations), %d new fields\n",
+		tm.formatNumber(report.Checked), cf, tm.formatNumber(report.Violating), tm.formatNumber(report.ViolationCount), len(report.NewFields)))
+	if len(report.Violations) > 0 {
+		output.WriteString("\nViolations:\n")
+		for _, v := range report.Violations {
+			output.WriteString(fmt.Sprintf("- %s %s: %s\n", v.Key, v.Path, v.Message))
+		}
+		if n := int64(len(report.Violations)); report.ViolationCount > n {
+			output.WriteString(fmt.Sprintf("(first %d listed, raise limit to see more)\n", n))
+		}
+	}
+	if len(report.NewFields) > 0 {
+		output.WriteString("\nNew fields:\n")
+		for _, f := range report.NewFields {
+			output.WriteString(fmt.Sprintf("- %s (%s) in %s values, e.g. %s\n", f.Path, f.Type, tm.formatNumber(f.Count), f.ExampleKey))
+		}
+	}
+	return mcp.NewToolResultText(output.String()), nil
+}
+
+func (tm *ToolManager) handleListDatabasesTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	databases := tm.databases.List(contextSessionID(ctx))
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Databases (%d total):\n", len(databases)))
+	for _, info := range databases {
+		output.WriteString(fmt.Sprintf("- %s: %s%s\n", info.Name, info.Path, databaseFlags(info)))
+	}
+
+	return mcp.NewToolResultText(output.String()), nil
+}
+
+func (tm *ToolManager) handleOpenDatabaseTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, err := request.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	info, err := tm.databases.Select(contextSessionID(ctx), name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Opened database '%s' at %s%s; it is now the default of this session",
+		info.Name, info.Path, databaseFlags(info))), nil
+}
+
+// databaseFlags describes the state of a database for listings
+func databaseFlags(info DatabaseInfo) string {
+	var flags []string
+	if info.ReadOnly {
+		flags = append(flags, "read-only")
+	}
+	if info.Open {
+		flags = append(flags, "open")
+	}
+	if info.Current {
+		flags = append(flags, "current")
+	}
+	if len(flags) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(flags, ", ") + ")"
+}
+
+// databaseStats gets database-wide statistics like GetDatabaseStats, one
+// column family at a time, and stops early when op is cancelled
+func (tm *ToolManager) databaseStats(database db.KeyValueDB, op *operation) (*db.DatabaseStats, error) {
+	cfs, err := database.ListCFs()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &db.DatabaseStats{
+		ColumnFamilies:    make([]db.CFStats, 0, len(cfs)),
+		ColumnFamilyCount: len(cfs),
+		LastUpdated:       time.Now(),
+	}
+
+	for _, cf := range cfs {
+		if op.Cancelled() {
+			break
+		}
+		// Progress counts the keys of all column families
+		counted := stats.TotalKeyCount
+		cfStats, err := database.GetCFStatsWithOptions(cf, db.StatsOptions{
+			Progress: func(visited int64, lastKey string) error {
+				return op.Progress(counted+visited, lastKey)
+			},
+		})
+		if err != nil {
+			// Continue with other CFs even if one fails
+			continue
+		}
+
+		stats.ColumnFamilies = append(stats.ColumnFamilies, *cfStats)
+		stats.TotalKeyCount += cfStats.KeyCount
+		stats.TotalSize += cfStats.TotalKeySize + cfStats.TotalValueSize
+	}
+
+	return stats, nil
+}
+
 // formatJSONValue formats JSON values with recursive nested JSON expansion using jsonutil
 func (tm *ToolManager) formatJSONValue(value string) string {
 	return jsonutil.PrettyPrintWithNestedExpansion(value)
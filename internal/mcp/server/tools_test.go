@@ -1,13 +1,21 @@
 package server
 
 import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"rocksdb-cli/internal/db"
 	"rocksdb-cli/internal/jsonutil"
+	"rocksdb-cli/internal/keytree"
 	"rocksdb-cli/internal/util"
 
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
@@ -103,6 +111,43 @@ func (m *MockKeyValueDB) ExportToCSV(cf, filePath, sep string) error {
 	return nil
 }
 
+// ExportToCSVWithOptions visits the keys in order without writing a file and
+// calls opts.Progress after every key
+func (m *MockKeyValueDB) ExportToCSVWithOptions(cf, filePath string, opts db.ExportOptions) (*db.ExportResult, error) {
+	cfData, exists := m.data[cf]
+	if !exists {
+		return nil, db.ErrColumnFamilyNotFound
+	}
+
+	after := ""
+	if opts.After != "" {
+		decoded, err := base64.StdEncoding.DecodeString(opts.After)
+		if err != nil {
+			return nil, err
+		}
+		after = string(decoded)
+	}
+
+	keys := make([]string, 0, len(cfData))
+	for key := range cfData {
+		if opts.After == "" || key > after {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	result := &db.ExportResult{}
+	for _, key := range keys {
+		result.Rows++
+		if opts.Progress != nil && opts.Progress(result.Rows, key) != nil {
+			result.NextCursor = base64.StdEncoding.EncodeToString([]byte(key))
+			result.HasMore = true
+			break
+		}
+	}
+	return result, nil
+}
+
 func (m *MockKeyValueDB) ExportSearchResultsToCSV(cf, filePath, sep string, opts db.SearchOptions) error {
 	if _, exists := m.data[cf]; !exists {
 		return db.ErrColumnFamilyNotFound
@@ -212,6 +257,37 @@ func (m *MockKeyValueDB) GetCFStats(cf string) (*db.CFStats, error) {
 	return stats, nil
 }
 
+func (m *MockKeyValueDB) GetCFStatsWithOptions(cf string, opts db.StatsOptions) (*db.CFStats, error) {
+	if opts.Prefix == "" && opts.JSONSamples == 0 {
+		return m.GetCFStats(cf)
+	}
+	if _, exists := m.data[cf]; !exists {
+		return nil, db.ErrColumnFamilyNotFound
+	}
+	// Keys under the prefix, with the first JSON values as the sample
+	stats := &db.CFStats{Name: cf, DataTypeDistribution: make(map[db.DataType]int64)}
+	var keys []string
+	for key := range m.data[cf] {
+		if strings.HasPrefix(key, opts.Prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		stats.KeyCount++
+		value := m.data[cf][key]
+		if !strings.HasPrefix(value, "{") && !strings.HasPrefix(value, "[") {
+			stats.DataTypeDistribution[db.DataTypeString]++
+			continue
+		}
+		stats.DataTypeDistribution[db.DataTypeJSON]++
+		if len(stats.JSONSamples) < opts.JSONSamples {
+			stats.JSONSamples = append(stats.JSONSamples, value)
+		}
+	}
+	return stats, nil
+}
+
 func (m *MockKeyValueDB) GetDatabaseStats() (*db.DatabaseStats, error) {
 	stats := &db.DatabaseStats{
 		ColumnFamilies:    make([]db.CFStats, 0),
@@ -230,6 +306,20 @@ func (m *MockKeyValueDB) GetDatabaseStats() (*db.DatabaseStats, error) {
 	return stats, nil
 }
 
+func (m *MockKeyValueDB) GetKeyTree(cf string, opts keytree.Options) (*keytree.Node, error) {
+	if _, ok := m.data[cf]; !ok {
+		return nil, db.ErrColumnFamilyNotFound
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	b := keytree.NewBuilder(opts)
+	for key, value := range m.data[cf] {
+		b.Add([]byte(key), len(value))
+	}
+	return b.Tree(), nil
+}
+
 func (m *MockKeyValueDB) SearchCF(cf string, opts db.SearchOptions) (*db.SearchResults, error) {
 	if _, exists := m.data[cf]; !exists {
 		return nil, db.ErrColumnFamilyNotFound
@@ -329,6 +419,51 @@ func (m *MockKeyValueDB) GetKeyFormatInfo(cf string) (util.KeyFormat, string) {
 	return util.KeyFormatString, "Printable string keys"
 }
 
+func (m *MockKeyValueDB) CreateCFWithProfile(cf, profile string) error {
+	return m.CreateCF(cf)
+}
+
+func (m *MockKeyValueDB) GetCFOptions(cf string) (*db.CFOptions, error) {
+	if m.data[cf] == nil {
+		return nil, db.ErrColumnFamilyNotFound
+	}
+	return &db.CFOptions{Name: cf, Comparator: db.BytewiseComparator}, nil
+}
+
+func (m *MockKeyValueDB) OptionMismatches() []db.OptionMismatch {
+	return nil
+}
+
+func (m *MockKeyValueDB) MergeCF(cf, key, operand string) error {
+	if m.readOnly {
+		return db.ErrReadOnlyMode
+	}
+	if m.data[cf] == nil {
+		return db.ErrColumnFamilyNotFound
+	}
+	if existing, ok := m.data[cf][key]; ok {
+		operand = existing + "," + operand
+	}
+	m.data[cf][key] = operand
+	return nil
+}
+
+func (m *MockKeyValueDB) PutCFWithTTL(cf, key, value string, ttl time.Duration) error {
+	return db.ErrTTLModeDisabled
+}
+
+func (m *MockKeyValueDB) GetCFWithTTL(cf, key string) (string, *db.TTLInfo, error) {
+	return "", nil, db.ErrTTLModeDisabled
+}
+
+func (m *MockKeyValueDB) GetTTLStats(cf string) (*db.TTLStats, error) {
+	return nil, db.ErrTTLModeDisabled
+}
+
+func (m *MockKeyValueDB) TTLMode() bool {
+	return false
+}
+
 func TestNewToolManager(t *testing.T) {
 	mockDB := NewMockKeyValueDB()
 	config := DefaultConfig()
@@ -435,6 +570,179 @@ func TestToolManagerReadOnlyMode(t *testing.T) {
 	}
 }
 
+func TestHandleMergeTool(t *testing.T) {
+	mockDB := NewMockKeyValueDB()
+	mockDB.PutCF("default", "tags", "go")
+	config := DefaultConfig()
+	tm := NewToolManager(mockDB, config)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "rocksdb_merge"
+	request.Params.Arguments = map[string]any{"key": "tags", "operand": "mcp"}
+
+	result, err := tm.handleMergeTool(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleMergeTool returned error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected success, got error result: %+v", result.Content)
+	}
+	if value, _ := mockDB.GetCF("default", "tags"); value != "go,mcp" {
+		t.Errorf("Expected merged value 'go,mcp', got '%s'", value)
+	}
+
+	// Missing CF is reported as a tool error
+	request.Params.Arguments = map[string]any{"key": "tags", "operand": "x", "column_family": "missing"}
+	result, err = tm.handleMergeTool(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handleMergeTool returned error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Expected error result for missing column family")
+	}
+
+	// Read-only mode refuses merges
+	config.ReadOnly = true
+	result, _ = tm.handleMergeTool(context.Background(), request)
+	if !result.IsError {
+		t.Error("Expected error result in read-only mode")
+	}
+}
+
+func TestTimeSeriesTools(t *testing.T) {
+	mockDB := NewMockKeyValueDB()
+	mockDB.CreateCF("events")
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		at := start.Add(time.Duration(i) * 30 * time.Minute)
+		mockDB.PutCF("events", strconv.FormatInt(at.UnixMilli(), 10), fmt.Sprintf("e%d", i))
+	}
+	tm := NewToolManager(mockDB, DefaultConfig())
+
+	call := func(handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]any) (string, bool) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := handler(context.Background(), request)
+		if err != nil {
+			t.Fatalf("handler returned error: %v", err)
+		}
+		return result.Content[0].(mcp.TextContent).Text, result.IsError
+	}
+
+	text, isError := call(tm.handleTimeRangeTool, map[string]any{"column_family": "events", "from": "2024-03-01T09:30", "to": "2024-03-01T10:30"})
+	if isError || !strings.Contains(text, "2 entries") || !strings.Contains(text, "2024-03-01T09:30:00Z  1709285400000: e1\n2024-03-01T10:00:00Z  1709287200000: e2\n") {
+		t.Errorf("rocksdb_time_range = %q", text)
+	}
+
+	text, isError = call(tm.handleLatestTool, map[string]any{"column_family": "events", "n": float64(1)})
+	if isError || !strings.Contains(text, "2024-03-01T10:30:00Z  1709289000000: e3\n") || strings.Contains(text, "e2") {
+		t.Errorf("rocksdb_latest = %q", text)
+	}
+
+	text, isError = call(tm.handleTimeHistogramTool, map[string]any{"column_family": "events", "bucket": "1h"})
+	if isError || !strings.Contains(text, "2024-03-01T09:00:00Z  2\n2024-03-01T10:00:00Z  2\n") {
+		t.Errorf("rocksdb_time_histogram = %q", text)
+	}
+
+	for _, args := range []map[string]any{
+		{"column_family": "events", "from": "soon"},
+		{"column_family": "events", "encoding": "days"},
+		{"column_family": "missing"},
+	} {
+		if text, isError := call(tm.handleTimeRangeTool, args); !isError {
+			t.Errorf("rocksdb_time_range(%v) = %q, want an error", args, text)
+		}
+	}
+
+	// Cancelled calls stop after the first batch of keys
+	for i := 0; i < 1500; i++ {
+		mockDB.PutCF("events", strconv.FormatInt(start.Add(time.Duration(4+i)*time.Second).UnixMilli(), 10), "x")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"column_family": "events", "bucket": "1h"}
+	result, _ := tm.handleTimeHistogramTool(ctx, request)
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "Partial: cancelled") || !strings.Contains(text, "1.0K entries") {
+		t.Errorf("cancelled rocksdb_time_histogram = %q", text)
+	}
+	request.Params.Arguments = map[string]any{"column_family": "events", "limit": float64(5000)}
+	result, _ = tm.handleTimeRangeTool(ctx, request)
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "was cancelled") || !strings.Contains(text, "1000 entries") || !strings.Contains(text, "continue with cursor") {
+		t.Errorf("cancelled rocksdb_time_range = %.200q", text)
+	}
+}
+
+func TestSchemaTools(t *testing.T) {
+	mockDB := NewMockKeyValueDB()
+	mockDB.CreateCF("users")
+	mockDB.PutCF("users", "user:1", `{"id": 1, "status": "active"}`)
+	mockDB.PutCF("users", "user:2", `{"id": 2, "status": "active", "email": "b@example.com"}`)
+	mockDB.PutCF("users", "user:3", `{"id": 3, "status": "inactive"}`)
+	mockDB.PutCF("users", "user:4", `{"id": 4, "status": "active"}`)
+	tm := NewToolManager(mockDB, DefaultConfig())
+
+	call := func(handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]any) (string, bool) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := handler(context.Background(), request)
+		if err != nil {
+			t.Fatalf("handler returned error: %v", err)
+		}
+		return result.Content[0].(mcp.TextContent).Text, result.IsError
+	}
+
+	text, isError := call(tm.handleSchemaInferTool, map[string]any{"column_family": "users", "prefix": "user:"})
+	for _, want := range []string{
+		"from 4 of 4 JSON values (4 keys)",
+		"- $.email: string, 25% present, e.g. \"b@example.com\"\n",
+		"- $.status: string, 100% present, required, one of \"active\", \"inactive\"\n",
+	} {
+		if isError || !strings.Contains(text, want) {
+			t.Errorf("rocksdb_schema_infer = %q, want it to contain %q", text, want)
+		}
+	}
+	_, inferred, _ := strings.Cut(text, "JSON Schema:\n")
+
+	mockDB.PutCF("users", "user:5", `{"id": "5", "status": "active", "phone": "555"}`)
+	text, isError = call(tm.handleSchemaCheckTool, map[string]any{"schema": inferred})
+	for _, want := range []string{
+		"Checked 5 values of column family 'users': 1 violate the schema (1 violations), 1 new fields",
+		"- user:5 $.id: is string, want integer\n",
+		"- $.phone (string) in 1 values, e.g. user:5\n",
+	} {
+		if isError || !strings.Contains(text, want) {
+			t.Errorf("rocksdb_schema_check = %q, want it to contain %q", text, want)
+		}
+	}
+
+	for _, tc := range []struct {
+		handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)
+		args    map[string]any
+	}{
+		{tm.handleSchemaInferTool, map[string]any{"column_family": "missing"}},
+		{tm.handleSchemaCheckTool, map[string]any{"schema": "{not json"}},
+		{tm.handleSchemaCheckTool, map[string]any{}},
+	} {
+		if text, isError := call(tc.handler, tc.args); !isError {
+			t.Errorf("call(%v) = %q, want an error", tc.args, text)
+		}
+	}
+
+	// A cancelled check stops after the first batch of values
+	for i := 0; i < 1500; i++ {
+		mockDB.PutCF("users", fmt.Sprintf("user:x%04d", i), `{"id": 1, "status": "active"}`)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"schema": inferred}
+	result, _ := tm.handleSchemaCheckTool(ctx, request)
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "Partial: cancelled") || !strings.Contains(text, "Checked 1.0K values") {
+		t.Errorf("cancelled rocksdb_schema_check = %q", text)
+	}
+}
+
 func TestPrefixScan(t *testing.T) {
 	mockDB := NewMockKeyValueDB()
 
@@ -650,11 +958,49 @@ func TestIsJSONString(t *testing.T) {
 }
 
 func (m *MockKeyValueDB) ScanCFPage(cf string, start, end []byte, opts db.ScanOptions) (db.ScanPageResult, error) {
-	result, err := m.ScanCF(cf, start, end, opts)
-	if err != nil {
-		return db.ScanPageResult{}, err
+	cfData, exists := m.data[cf]
+	if !exists {
+		return db.ScanPageResult{}, db.ErrColumnFamilyNotFound
 	}
-	return db.ScanPageResult{Results: result, NextCursor: "", HasMore: false}, nil
+
+	startAfter := opts.StartAfter
+	if decoded, err := base64.StdEncoding.DecodeString(startAfter); err == nil {
+		startAfter = string(decoded)
+	}
+
+	keys := make([]string, 0, len(cfData))
+	for key := range cfData {
+		if (len(start) > 0 && key < string(start)) || (len(end) > 0 && key >= string(end)) {
+			continue
+		}
+		if startAfter != "" && key <= startAfter {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if opts.Reverse {
+		sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	}
+
+	page := db.ScanPageResult{Results: make(map[string]string)}
+	for i, key := range keys {
+		if opts.Limit > 0 && i >= opts.Limit {
+			page.HasMore = true
+			page.NextCursor = base64.StdEncoding.EncodeToString([]byte(keys[i-1]))
+			break
+		}
+		keyEncoded, keyIsBinary := util.EncodeValue([]byte(key))
+		valueEncoded, valueIsBinary := util.EncodeValue([]byte(cfData[key]))
+		page.Results[key] = cfData[key]
+		page.ResultsV2 = append(page.ResultsV2, db.KeyValue{
+			Key:           keyEncoded,
+			Value:         valueEncoded,
+			KeyIsBinary:   keyIsBinary,
+			ValueIsBinary: valueIsBinary,
+		})
+	}
+	return page, nil
 }
 
 func (m *MockKeyValueDB) SmartScanCFPage(cf string, start, end string, opts db.ScanOptions) (db.ScanPageResult, error) {
@@ -7,26 +7,38 @@ import (
 	"fmt"
 	"io"
 	"net"
-	"net/http"
 	"os"
 	"path/filepath"
 	"time"
 
+	"rocksdb-cli/internal/mcp/protocol"
+
 	"github.com/mark3labs/mcp-go/server"
 )
 
 // TransportManager manages different transport types for MCP communication
 type TransportManager struct {
-	config *Config
-	server *server.MCPServer
+	config   *Config
+	server   *server.MCPServer
+	sessions *SessionManager
+	methods  methodRouter
+	requests inflightRequests
 }
 
 // NewTransportManager creates a new transport manager
 func NewTransportManager(config *Config, mcpServer *server.MCPServer) *TransportManager {
-	return &TransportManager{
-		config: config,
-		server: mcpServer,
+	tm := &TransportManager{
+		config:   config,
+		server:   mcpServer,
+		sessions: NewSessionManager(config.MaxConcurrentSessions, config.SessionTimeout),
 	}
+	tm.HandleMethod(protocol.MethodCancelled, tm.handleCancelled)
+	return tm
+}
+
+// Sessions returns the sessions of all transports
+func (tm *TransportManager) Sessions() *SessionManager {
+	return tm.sessions
 }
 
 // StartTransport starts the configured transport type
@@ -36,8 +48,10 @@ func (tm *TransportManager) StartTransport(ctx context.Context) error {
 		return tm.startStdioTransport(ctx)
 	case "tcp":
 		return tm.startTCPTransport(ctx)
-	case "websocket":
+	case transportWebSocket:
 		return tm.startWebSocketTransport(ctx)
+	case transportStreamableHTTP:
+		return tm.startStreamableHTTPTransport(ctx)
 	case "unix":
 		return tm.startUnixTransport(ctx)
 	default:
@@ -47,11 +61,22 @@ func (tm *TransportManager) StartTransport(ctx context.Context) error {
 
 // startStdioTransport starts stdio transport (standard input/output)
 func (tm *TransportManager) startStdioTransport(ctx context.Context) error {
-	// Start stdio server - this will block until the connection is closed
-	if err := server.ServeStdio(tm.server); err != nil {
-		return fmt.Errorf("stdio transport failed: %w", err)
+	done := make(chan error, 1)
+	go func() {
+		done <- tm.serveStream(ctx, "stdio", os.Stdin, os.Stdout, func() {}, nil)
+	}()
+
+	// Reading stdin cannot be interrupted, so return on shutdown without
+	// waiting for the session
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("stdio transport failed: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return nil
 	}
-	return nil
 }
 
 // startTCPTransport starts TCP transport
@@ -66,11 +91,46 @@ func (tm *TransportManager) startTCPTransport(ctx context.Context) error {
 
 	fmt.Printf("MCP server listening on TCP %s\n", addr)
 
-	// Create a channel for shutdown signal
+	return tm.acceptConnections(ctx, listener, "tcp")
+}
+
+// startUnixTransport starts Unix socket transport
+func (tm *TransportManager) startUnixTransport(ctx context.Context) error {
+	socketPath := tm.config.Transport.SocketPath
+
+	// Remove existing socket file if it exists
+	if err := os.RemoveAll(socketPath); err != nil {
+		return fmt.Errorf("failed to remove existing socket file: %w", err)
+	}
+
+	// Create directory if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0755); err != nil {
+		return fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to start Unix socket listener on %s: %w", socketPath, err)
+	}
+	defer listener.Close()
+	defer os.RemoveAll(socketPath) // Clean up socket file
+
+	fmt.Printf("MCP server listening on Unix socket %s\n", socketPath)
+
+	return tm.acceptConnections(ctx, listener, "unix")
+}
+
+// acceptConnections serves each connection accepted on listener as a session
+// until ctx is done, then closes every session
+func (tm *TransportManager) acceptConnections(ctx context.Context, listener net.Listener, transport string) error {
+	go tm.sessions.Run(ctx)
+
+	// Close listener to unblock Accept() on shutdown
 	shutdown := make(chan struct{})
 	go func() {
 		<-ctx.Done()
-		listener.Close() // Close listener to unblock Accept()
+		listener.Close()
+		tm.sessions.CloseAll()
 		close(shutdown)
 	}()
 
@@ -85,243 +145,92 @@ func (tm *TransportManager) startTCPTransport(ctx context.Context) error {
 			default:
 				// Only log error if not shutting down
 				if ctx.Err() == nil {
-					fmt.Printf("Error accepting connection: %v\n", err)
+					fmt.Printf("Error accepting %s connection: %v\n", transport, err)
 				}
 				continue
 			}
 		}
 
 		// Handle connection in a goroutine
-		go tm.handleTCPConnection(conn)
+		go tm.handleConnection(ctx, conn, transport)
 	}
 }
 
-// handleTCPConnection handles a single TCP connection
-func (tm *TransportManager) handleTCPConnection(conn net.Conn) {
+// handleConnection handles a single TCP or Unix socket connection
+func (tm *TransportManager) handleConnection(ctx context.Context, conn net.Conn, transport string) {
 	defer conn.Close()
 
-	// Set connection timeout (initial timeout for the connection)
-	// We'll update it for each read/write operation
+	fmt.Printf("Handling %s connection from %s\n", transport, conn.RemoteAddr())
+
+	// The timeout applies to each read, so an idle connection is closed
+	var beforeRead func()
 	if tm.config.Transport.Timeout > 0 {
-		conn.SetDeadline(time.Now().Add(tm.config.Transport.Timeout))
+		beforeRead = func() {
+			conn.SetReadDeadline(time.Now().Add(tm.config.Transport.Timeout))
+		}
 	}
 
-	fmt.Printf("Handling TCP connection from %s\n", conn.RemoteAddr())
-
-	// Serve MCP protocol over this TCP connection
-	// We use the connection as both reader and writer for JSON-RPC messages
-	if err := tm.serveMCPConn(conn, conn); err != nil && err != io.EOF {
-		fmt.Printf("Error serving TCP connection: %v\n", err)
+	if err := tm.serveStream(ctx, transport, conn, conn, func() { conn.Close() }, beforeRead); err != nil && err != io.EOF {
+		fmt.Printf("Error serving %s connection: %v\n", transport, err)
 	}
 }
 
-// serveMCPConn serves MCP protocol over a connection (implements JSON-RPC 2.0 over streams)
-func (tm *TransportManager) serveMCPConn(reader io.Reader, writer io.Writer) error {
-	// Create buffered reader and writer for JSON streaming
-	bufReader := bufio.NewReader(reader)
-	bufWriter := bufio.NewWriter(writer)
-
-	// Create JSON decoder and encoder
-	decoder := json.NewDecoder(bufReader)
-	encoder := json.NewEncoder(bufWriter)
-
-	// Process messages in a loop
-	for {
-		// Read JSON-RPC request
-		var request map[string]interface{}
-		if err := decoder.Decode(&request); err != nil {
-			if err == io.EOF {
-				return nil // Normal connection close
-			}
-			return fmt.Errorf("failed to decode request: %w", err)
-		}
-
-		// Process the request through MCP server
-		// Note: This is a simplified implementation
-		// The actual mcp-go library handles this internally
-		// For now, we'll create a basic response
-		response := tm.processRequest(request)
-
-		// Write response (skip if nil, which means it's a notification with no response)
-		if response != nil {
-			if err := encoder.Encode(response); err != nil {
-				return fmt.Errorf("failed to encode response: %w", err)
-			}
-
-			// Flush the writer to ensure response is sent
-			if err := bufWriter.Flush(); err != nil {
-				return fmt.Errorf("failed to flush response: %w", err)
-			}
-		}
+// serveStream serves one session over a stream of JSON-RPC messages, one
+// JSON value per message, and writes each response and notification as a
+// line. closeConn must unblock reads from reader.
+func (tm *TransportManager) serveStream(ctx context.Context, transport string, reader io.Reader, writer io.Writer, closeConn func(), beforeRead func()) error {
+	sessionID, err := tm.sessions.Open(transport)
+	if err != nil {
+		json.NewEncoder(writer).Encode(sessionLimitError(nil, err))
+		return err
 	}
-}
 
-// processRequest processes a JSON-RPC request and returns a response
-func (tm *TransportManager) processRequest(request map[string]interface{}) map[string]interface{} {
-	// Extract request fields
-	id := request["id"]
-	method, _ := request["method"].(string)
-
-	// Handle different methods
-	switch method {
-	case "initialize":
-		// Return initialize response
-		return map[string]interface{}{
-			"jsonrpc": "2.0",
-			"id":      id,
-			"result": map[string]interface{}{
-				"protocolVersion": "2024-11-05",
-				"capabilities": map[string]interface{}{
-					"tools": map[string]interface{}{},
-				},
-				"serverInfo": map[string]string{
-					"name":    tm.config.Name,
-					"version": tm.config.Version,
-				},
-			},
-		}
-	case "initialized":
-		// No response needed for initialized notification
-		return nil
-	default:
-		// Return error for unknown method
-		return map[string]interface{}{
-			"jsonrpc": "2.0",
-			"id":      id,
-			"error": map[string]interface{}{
-				"code":    -32601,
-				"message": "Method not found",
-			},
+	bufWriter := bufio.NewWriter(writer)
+	session := newConnSession(sessionID, func(data []byte) error {
+		if _, err := bufWriter.Write(append(data, '\n')); err != nil {
+			return err
 		}
-	}
-}
-
-// startWebSocketTransport starts WebSocket transport
-func (tm *TransportManager) startWebSocketTransport(ctx context.Context) error {
-	addr := fmt.Sprintf("%s:%d", tm.config.Transport.Host, tm.config.Transport.Port)
-
-	mux := http.NewServeMux()
-
-	// Handle WebSocket connections
-	mux.HandleFunc(tm.config.Transport.Path, tm.handleWebSocketConnection)
-
-	// Handle health check
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status":"healthy","service":"rocksdb-mcp-server"}`))
+		return bufWriter.Flush()
 	})
 
-	httpServer := &http.Server{
-		Addr:    addr,
-		Handler: mux,
-	}
-
-	fmt.Printf("MCP server listening on WebSocket %s%s\n", addr, tm.config.Transport.Path)
-
-	// Start server in a goroutine
-	go func() {
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			fmt.Printf("WebSocket server error: %v\n", err)
+	decoder := json.NewDecoder(bufio.NewReader(reader))
+	var readErr error
+	receive := func() ([]byte, error) {
+		if beforeRead != nil {
+			beforeRead()
 		}
-	}()
-
-	// Wait for context cancellation
-	<-ctx.Done()
-
-	// Graceful shutdown
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	return httpServer.Shutdown(shutdownCtx)
-}
-
-// handleWebSocketConnection handles WebSocket connections
-func (tm *TransportManager) handleWebSocketConnection(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement WebSocket connection handling
-	// This would require implementing WebSocket support for mcp-go
-	fmt.Printf("WebSocket connection from %s\n", r.RemoteAddr)
-
-	// For now, return a 501 Not Implemented
-	http.Error(w, "WebSocket transport not yet implemented", http.StatusNotImplemented)
-}
-
-// startUnixTransport starts Unix socket transport
-func (tm *TransportManager) startUnixTransport(ctx context.Context) error {
-	socketPath := tm.config.Transport.SocketPath
-
-	// Remove existing socket file if it exists
-	if err := os.RemoveAll(socketPath); err != nil {
-		return fmt.Errorf("failed to remove existing socket file: %w", err)
-	}
-
-	// Create directory if it doesn't exist
-	if err := os.MkdirAll(filepath.Dir(socketPath), 0755); err != nil {
-		return fmt.Errorf("failed to create socket directory: %w", err)
-	}
-
-	listener, err := net.Listen("unix", socketPath)
-	if err != nil {
-		return fmt.Errorf("failed to start Unix socket listener on %s: %w", socketPath, err)
-	}
-	defer listener.Close()
-	defer os.RemoveAll(socketPath) // Clean up socket file
-
-	fmt.Printf("MCP server listening on Unix socket %s\n", socketPath)
-
-	// Handle incoming connections
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-			conn, err := listener.Accept()
-			if err != nil {
-				if ctx.Err() != nil {
-					return ctx.Err()
-				}
-				fmt.Printf("Error accepting Unix socket connection: %v\n", err)
-				continue
+		var message json.RawMessage
+		if err := decoder.Decode(&message); err != nil {
+			if err != io.EOF {
+				readErr = fmt.Errorf("failed to decode request: %w", err)
 			}
-
-			// Handle connection in a goroutine
-			go tm.handleUnixConnection(conn)
+			return nil, err
 		}
-	}
-}
-
-// handleUnixConnection handles a single Unix socket connection
-func (tm *TransportManager) handleUnixConnection(conn net.Conn) {
-	defer conn.Close()
-
-	// Set connection timeout
-	if tm.config.Transport.Timeout > 0 {
-		conn.SetDeadline(time.Now().Add(tm.config.Transport.Timeout))
+		return message, nil
 	}
 
-	// TODO: Implement Unix socket connection handling with MCP server
-	fmt.Printf("Handling Unix socket connection\n")
-
-	// For now, we'll just close the connection
-	// In a full implementation, we would need to:
-	// 1. Create a custom transport that uses the Unix socket connection
-	// 2. Start the MCP server with this transport
+	tm.serveSession(ctx, session, receive, closeConn)
+	return readErr
 }
 
 // GetTransportInfo returns information about the current transport configuration
 func (tm *TransportManager) GetTransportInfo() map[string]interface{} {
 	info := map[string]interface{}{
-		"type":    tm.config.Transport.Type,
-		"timeout": tm.config.Transport.Timeout.String(),
+		"type":     tm.config.Transport.Type,
+		"timeout":  tm.config.Transport.Timeout.String(),
+		"sessions": tm.sessions.Count(),
 	}
 
 	switch tm.config.Transport.Type {
-	case "tcp", "websocket":
+	case "tcp":
 		info["host"] = tm.config.Transport.Host
 		info["port"] = tm.config.Transport.Port
-		if tm.config.Transport.Type == "websocket" {
-			info["path"] = tm.config.Transport.Path
-		}
+	case transportWebSocket, transportStreamableHTTP:
+		info["host"] = tm.config.Transport.Host
+		info["port"] = tm.config.Transport.Port
+		info["path"] = tm.endpointPath()
+		info["max_sessions"] = tm.config.MaxConcurrentSessions
+		info["session_timeout"] = tm.config.SessionTimeout.String()
 	case "unix":
 		info["socket_path"] = tm.config.Transport.SocketPath
 	}
@@ -6,12 +6,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
 )
 
 // TestTCPTransport_BasicConnection tests basic TCP connection
@@ -310,3 +313,387 @@ func TestTCPTransport_InvalidPort(t *testing.T) {
 	err := tm.StartTransport(ctx)
 	assert.Error(t, err, "Should fail to bind to privileged port")
 }
+
+// testHTTPClient does not keep connections alive: a spare keep-alive
+// connection that never sent a request delays http.Server.Shutdown
+var testHTTPClient = &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+const initializeRequest = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test-client","version":"1.0.0"}}}`
+
+// startHTTPTestTransport starts a WebSocket or Streamable HTTP transport on a
+// free port and returns its base URL
+func startHTTPTestTransport(t *testing.T, transport string, maxSessions int) (string, *TransportManager, context.CancelFunc, <-chan error) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	mcpServer := server.NewMCPServer("test-server", "1.0.0", server.WithToolCapabilities(true))
+	config := &Config{
+		Name:    "test-server",
+		Version: "1.0.0",
+		Transport: TransportConfig{
+			Type:    transport,
+			Host:    "localhost",
+			Port:    port,
+			Timeout: 5 * time.Second,
+		},
+		MaxConcurrentSessions: maxSessions,
+		SessionTimeout:        time.Minute,
+	}
+	tm := NewTransportManager(config, mcpServer)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- tm.StartTransport(ctx)
+	}()
+
+	baseURL := fmt.Sprintf("http://localhost:%d", port)
+	require.Eventually(t, func() bool {
+		resp, err := testHTTPClient.Get(baseURL + "/health")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 2*time.Second, 20*time.Millisecond)
+
+	t.Cleanup(cancel)
+	return baseURL, tm, cancel, errChan
+}
+
+func dialWebSocket(t *testing.T, baseURL string) *websocket.Conn {
+	t.Helper()
+	ws, err := websocket.Dial(strings.Replace(baseURL, "http", "ws", 1)+defaultWebSocketPath, "", baseURL)
+	require.NoError(t, err)
+	return ws
+}
+
+// TestWebSocketTransport_MCPProtocol tests JSON-RPC over a WebSocket session
+func TestWebSocketTransport_MCPProtocol(t *testing.T) {
+	baseURL, tm, _, _ := startHTTPTestTransport(t, transportWebSocket, 10)
+
+	ws := dialWebSocket(t, baseURL)
+	defer ws.Close()
+	ws.SetDeadline(time.Now().Add(5 * time.Second))
+
+	require.NoError(t, websocket.Message.Send(ws, initializeRequest))
+	var response map[string]interface{}
+	require.NoError(t, websocket.JSON.Receive(ws, &response))
+	assert.Equal(t, float64(1), response["id"])
+	result, ok := response["result"].(map[string]interface{})
+	require.True(t, ok, "initialize returns a result: %v", response)
+	assert.Equal(t, "test-server", result["serverInfo"].(map[string]interface{})["name"])
+
+	require.NoError(t, websocket.Message.Send(ws, `{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	require.NoError(t, websocket.Message.Send(ws, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`))
+	response = nil
+	require.NoError(t, websocket.JSON.Receive(ws, &response))
+	assert.Equal(t, float64(2), response["id"])
+	assert.NotNil(t, response["result"])
+
+	assert.Equal(t, 1, tm.Sessions().Count())
+	ws.Close()
+	assert.Eventually(t, func() bool { return tm.Sessions().Count() == 0 }, 2*time.Second, 20*time.Millisecond,
+		"closing the connection ends the session")
+}
+
+// TestWebSocketTransport_SessionLimit tests MaxConcurrentSessions
+func TestWebSocketTransport_SessionLimit(t *testing.T) {
+	baseURL, _, _, _ := startHTTPTestTransport(t, transportWebSocket, 1)
+
+	first := dialWebSocket(t, baseURL)
+	defer first.Close()
+	first.SetDeadline(time.Now().Add(5 * time.Second))
+	require.NoError(t, websocket.Message.Send(first, initializeRequest))
+	var response map[string]interface{}
+	require.NoError(t, websocket.JSON.Receive(first, &response))
+
+	second := dialWebSocket(t, baseURL)
+	defer second.Close()
+	second.SetDeadline(time.Now().Add(5 * time.Second))
+	response = nil
+	require.NoError(t, websocket.JSON.Receive(second, &response))
+	errObj, ok := response["error"].(map[string]interface{})
+	require.True(t, ok, "expected an error response: %v", response)
+	assert.Contains(t, errObj["message"], "too many concurrent sessions")
+}
+
+// TestWebSocketTransport_Shutdown tests that open connections do not block shutdown
+func TestWebSocketTransport_Shutdown(t *testing.T) {
+	baseURL, _, cancel, errChan := startHTTPTestTransport(t, transportWebSocket, 10)
+
+	ws := dialWebSocket(t, baseURL)
+	defer ws.Close()
+
+	cancel()
+	select {
+	case err := <-errChan:
+		assert.NoError(t, err)
+	case <-time.After(shutdownTimeout):
+		t.Fatal("shutdown did not finish")
+	}
+
+	var message []byte
+	ws.SetReadDeadline(time.Now().Add(time.Second))
+	assert.Error(t, websocket.Message.Receive(ws, &message), "connection is closed on shutdown")
+}
+
+func postMCP(t *testing.T, url, sessionID, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", sessionID)
+	}
+	resp, err := testHTTPClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+// TestHTTPTransport_Origin tests that web pages of other hosts cannot use
+// the endpoints unless their origin is allowed
+func TestHTTPTransport_Origin(t *testing.T) {
+	tm := &TransportManager{config: &Config{Transport: TransportConfig{Host: "localhost", AllowedOrigins: []string{"https://app.example.com/"}}}}
+	for origin, want := range map[string]bool{
+		"":                        true,
+		"http://localhost:3000":   true,
+		"http://127.0.0.1:8080":   true,
+		"https://app.example.com": true,
+		"https://evil.example":    false,
+		"http://localhost.evil":   false,
+		"null":                    false,
+	} {
+		assert.Equal(t, want, tm.allowedOrigin(origin), "origin %q", origin)
+	}
+	tm.config.Transport.Host = "db.internal"
+	assert.True(t, tm.allowedOrigin("https://db.internal"))
+	assert.False(t, tm.allowedOrigin("http://localhost:3000"), "loopback pages cannot reach a server on another host")
+
+	baseURL, _, _, _ := startHTTPTestTransport(t, transportWebSocket, 10)
+	req, err := http.NewRequest(http.MethodGet, baseURL+defaultWebSocketPath, nil)
+	require.NoError(t, err)
+	for name, value := range map[string]string{
+		"Connection": "Upgrade", "Upgrade": "websocket", "Origin": "https://evil.example",
+		"Sec-WebSocket-Version": "13", "Sec-WebSocket-Key": "dGhlIHNhbXBsZSBub25jZQ==",
+	} {
+		req.Header.Set(name, value)
+	}
+	resp, err := testHTTPClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, "a foreign origin cannot open a WebSocket")
+
+	baseURL, _, _, _ = startHTTPTestTransport(t, transportStreamableHTTP, 10)
+	req, err = http.NewRequest(http.MethodPost, baseURL+defaultStreamableHTTPPath, strings.NewReader(initializeRequest))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Origin", "https://evil.example")
+	resp, err = testHTTPClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, "a foreign origin cannot use Streamable HTTP")
+}
+
+// TestStreamableHTTPTransport_Sessions tests the session lifecycle of the
+// Streamable HTTP transport
+func TestStreamableHTTPTransport_Sessions(t *testing.T) {
+	baseURL, tm, _, _ := startHTTPTestTransport(t, transportStreamableHTTP, 10)
+	url := baseURL + defaultStreamableHTTPPath
+
+	resp := postMCP(t, url, "", initializeRequest)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	require.NotEmpty(t, sessionID)
+	assert.Equal(t, 1, tm.Sessions().Count())
+
+	resp = postMCP(t, url, sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	var response map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotNil(t, response["result"])
+
+	resp = postMCP(t, url, "", `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "requests need a session")
+
+	resp, err := testHTTPClient.Get(baseURL + "/health")
+	require.NoError(t, err)
+	var health map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&health))
+	resp.Body.Close()
+	assert.Equal(t, "healthy", health["status"])
+	assert.Equal(t, float64(1), health["sessions"])
+
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Mcp-Session-Id", sessionID)
+	resp, err = testHTTPClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = postMCP(t, url, sessionID, `{"jsonrpc":"2.0","id":4,"method":"tools/list"}`)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "terminated sessions are gone")
+}
+
+// TestStreamableHTTPTransport_SessionLimit tests MaxConcurrentSessions
+func TestStreamableHTTPTransport_SessionLimit(t *testing.T) {
+	baseURL, _, _, _ := startHTTPTestTransport(t, transportStreamableHTTP, 1)
+	url := baseURL + defaultStreamableHTTPPath
+
+	resp := postMCP(t, url, "", initializeRequest)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = postMCP(t, url, "", initializeRequest)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+// TestStreamableHTTPTransport_NotificationStream tests that an open GET stream
+// ends on shutdown
+func TestStreamableHTTPTransport_NotificationStream(t *testing.T) {
+	baseURL, _, cancel, errChan := startHTTPTestTransport(t, transportStreamableHTTP, 10)
+	url := baseURL + defaultStreamableHTTPPath
+
+	resp := postMCP(t, url, "", initializeRequest)
+	resp.Body.Close()
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Mcp-Session-Id", "unknown")
+	resp, err = testHTTPClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	req.Header.Set("Mcp-Session-Id", sessionID)
+	stream, err := testHTTPClient.Do(req)
+	require.NoError(t, err)
+	defer stream.Body.Close()
+	assert.Equal(t, "text/event-stream", stream.Header.Get("Content-Type"))
+
+	cancel()
+	select {
+	case err := <-errChan:
+		assert.NoError(t, err)
+	case <-time.After(shutdownTimeout):
+		t.Fatal("open stream blocked shutdown")
+	}
+}
+
+// TestWebSocketTransport_Subscriptions tests resources/subscribe and change
+// notifications over a WebSocket session
+func TestWebSocketTransport_Subscriptions(t *testing.T) {
+	baseURL, tm, _, _ := startHTTPTestTransport(t, transportWebSocket, 10)
+	subs := NewSubscriptionManager(tm.server)
+	subs.Register(tm)
+
+	ws := dialWebSocket(t, baseURL)
+	defer ws.Close()
+	ws.SetDeadline(time.Now().Add(5 * time.Second))
+
+	var response map[string]interface{}
+	require.NoError(t, websocket.Message.Send(ws, initializeRequest))
+	require.NoError(t, websocket.JSON.Receive(ws, &response))
+
+	const uri = "rocksdb://data/users/user%3A1"
+	require.NoError(t, websocket.Message.Send(ws, `{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"`+uri+`"}}`))
+	response = nil
+	require.NoError(t, websocket.JSON.Receive(ws, &response))
+	assert.Equal(t, float64(2), response["id"])
+	assert.Equal(t, map[string]interface{}{}, response["result"])
+
+	require.NoError(t, websocket.Message.Send(ws, `{"jsonrpc":"2.0","id":3,"method":"resources/subscribe","params":{"uri":"rocksdb://stats"}}`))
+	response = nil
+	require.NoError(t, websocket.JSON.Receive(ws, &response))
+	errObj, ok := response["error"].(map[string]interface{})
+	require.True(t, ok, "expected an error response: %v", response)
+	assert.Equal(t, float64(-32602), errObj["code"])
+
+	subs.KeyChanged("users", "user:1")
+	response = nil
+	require.NoError(t, websocket.JSON.Receive(ws, &response))
+	assert.Equal(t, "notifications/resources/updated", response["method"])
+	assert.Equal(t, map[string]interface{}{"uri": uri}, response["params"])
+
+	ws.Close()
+	assert.Eventually(t, func() bool {
+		subs.mu.RLock()
+		defer subs.mu.RUnlock()
+		return len(subs.bySession) == 0
+	}, 2*time.Second, 20*time.Millisecond, "subscriptions end with the session")
+}
+
+// TestStreamableHTTPTransport_Subscriptions tests that change notifications
+// arrive on the session's GET stream
+func TestStreamableHTTPTransport_Subscriptions(t *testing.T) {
+	baseURL, tm, _, _ := startHTTPTestTransport(t, transportStreamableHTTP, 10)
+	subs := NewSubscriptionManager(tm.server)
+	subs.Register(tm)
+	url := baseURL + defaultStreamableHTTPPath
+
+	resp := postMCP(t, url, "", initializeRequest)
+	resp.Body.Close()
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	require.NotEmpty(t, sessionID)
+
+	const subscribe = `{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"rocksdb://prefix/logs/2024-"}}`
+	resp = postMCP(t, url, "unknown", subscribe)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = postMCP(t, url, sessionID, subscribe)
+	var response map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, float64(2), response["id"])
+	assert.NotNil(t, response["result"])
+	assert.Equal(t, []string{"rocksdb://prefix/logs/2024-"}, subs.Subscriptions(sessionID))
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Mcp-Session-Id", sessionID)
+	stream, err := testHTTPClient.Do(req)
+	require.NoError(t, err)
+	defer stream.Body.Close()
+
+	// The stream registers with the MCP server once its headers are sent
+	lines := make(chan string, 10)
+	go func() {
+		scanner := bufio.NewScanner(stream.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	deadline := time.After(5 * time.Second)
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case line, ok := <-lines:
+			require.True(t, ok, "stream ended before the notification")
+			if strings.HasPrefix(line, "data: ") {
+				assert.Contains(t, line, `"method":"notifications/resources/updated"`)
+				assert.Contains(t, line, `"uri":"rocksdb://prefix/logs/2024-"`)
+				return
+			}
+		case <-ticker.C:
+			subs.KeyChanged("logs", "2024-01-01")
+		case <-deadline:
+			t.Fatal("no notification on the stream")
+		}
+	}
+}
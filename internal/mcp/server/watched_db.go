@@ -0,0 +1,64 @@
+package server
+
+import (
+	"time"
+
+	"rocksdb-cli/internal/db"
+)
+
+// watchedDB reports successful writes made through it to a
+// SubscriptionManager. Reads pass straight through to the wrapped database.
+type watchedDB struct {
+	db.KeyValueDB
+	subs *SubscriptionManager
+}
+
+// Watch wraps database so that writes made through it notify the
+// subscribers of the affected resources. Writes made by other processes are
+// not seen.
+func (sm *SubscriptionManager) Watch(database db.KeyValueDB) db.KeyValueDB {
+	return &watchedDB{KeyValueDB: database, subs: sm}
+}
+
+// Unwrap returns the wrapped database
+func (w *watchedDB) Unwrap() db.KeyValueDB {
+	return w.KeyValueDB
+}
+
+func (w *watchedDB) PutCF(cf, key, value string) error {
+	return w.keyWritten(cf, key, w.KeyValueDB.PutCF(cf, key, value))
+}
+
+func (w *watchedDB) PutCFWithTTL(cf, key, value string, ttl time.Duration) error {
+	return w.keyWritten(cf, key, w.KeyValueDB.PutCFWithTTL(cf, key, value, ttl))
+}
+
+func (w *watchedDB) MergeCF(cf, key, operand string) error {
+	return w.keyWritten(cf, key, w.KeyValueDB.MergeCF(cf, key, operand))
+}
+
+func (w *watchedDB) CreateCF(cf string) error {
+	return w.cfChanged(cf, false, w.KeyValueDB.CreateCF(cf))
+}
+
+func (w *watchedDB) CreateCFWithProfile(cf, profile string) error {
+	return w.cfChanged(cf, false, w.KeyValueDB.CreateCFWithProfile(cf, profile))
+}
+
+func (w *watchedDB) DropCF(cf string) error {
+	return w.cfChanged(cf, true, w.KeyValueDB.DropCF(cf))
+}
+
+func (w *watchedDB) keyWritten(cf, key string, err error) error {
+	if err == nil {
+		w.subs.KeyChanged(cf, key)
+	}
+	return err
+}
+
+func (w *watchedDB) cfChanged(cf string, dropped bool, err error) error {
+	if err == nil {
+		w.subs.ColumnFamilyChanged(cf, dropped)
+	}
+	return err
+}
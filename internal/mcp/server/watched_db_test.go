@@ -0,0 +1,35 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchedDB(t *testing.T) {
+	sm, sent := newTestSubscriptionManager()
+	require.NoError(t, sm.Subscribe("s", "rocksdb://data/default/k"))
+	require.NoError(t, sm.Subscribe("s", "rocksdb://column-families"))
+
+	database := sm.Watch(NewMockKeyValueDB())
+
+	require.NoError(t, database.PutCF("default", "k", "v"))
+	assert.Equal(t, []notification{{"s", "rocksdb://data/default/k"}}, sent())
+
+	value, err := database.GetCF("default", "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v", value)
+	assert.Empty(t, sent(), "reads are not reported")
+
+	require.NoError(t, database.CreateCF("users"))
+	assert.Equal(t, []notification{{"s", "rocksdb://column-families"}}, sent())
+
+	assert.Error(t, database.DropCF("default"))
+	assert.Empty(t, sent(), "failed writes are not reported")
+
+	readOnly := NewMockKeyValueDB()
+	readOnly.readOnly = true
+	assert.Error(t, sm.Watch(readOnly).PutCF("default", "k", "v"))
+	assert.Empty(t, sent())
+}
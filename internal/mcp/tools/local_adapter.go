@@ -11,12 +11,12 @@ import (
 // LocalAdapter provides adapters for rocksdb-cli tools to work with MCP
 type LocalAdapter struct {
 	registry *Registry
-	db       *db.DB
+	db       db.KeyValueDB
 }
 
 // NewLocalAdapter creates a new local tool adapter
 // The db parameter is optional and can be nil
-func NewLocalAdapter(registry *Registry, database *db.DB) *LocalAdapter {
+func NewLocalAdapter(registry *Registry, database db.KeyValueDB) *LocalAdapter {
 	return &LocalAdapter{
 		registry: registry,
 		db:       database,
@@ -30,15 +30,8 @@ func (la *LocalAdapter) RegisterAll() error {
 		return fmt.Errorf("failed to register database tools: %w", err)
 	}
 
-	// Register GraphChain tools
-	if err := la.registerGraphChainTools(); err != nil {
-		return fmt.Errorf("failed to register GraphChain tools: %w", err)
-	}
-
-	// Register transform tools
-	if err := la.registerTransformTools(); err != nil {
-		return fmt.Errorf("failed to register transform tools: %w", err)
-	}
+	// GraphChain and transform tools are not registered until they are
+	// connected to the agent and the transform processor
 
 	return nil
 }
@@ -97,29 +90,7 @@ func (la *LocalAdapter) registerDatabaseTools() error {
 		return err
 	}
 
-	// Register 'delete' tool
-	deleteTool := protocol.Tool{
-		Name:        "db-delete",
-		Description: "Delete a key from RocksDB",
-		InputSchema: map[string]interface{}{
-			"type": "object",
-			"properties": map[string]interface{}{
-				"key": map[string]interface{}{
-					"type":        "string",
-					"description": "The key to delete",
-				},
-				"column_family": map[string]interface{}{
-					"type":        "string",
-					"description": "Column family name (optional)",
-				},
-			},
-			"required": []string{"key"},
-		},
-	}
-
-	if err := la.registry.RegisterLocal(deleteTool, la.handleDBDelete); err != nil {
-		return err
-	}
+	// There is no 'delete' tool: KeyValueDB has no way to delete a key yet
 
 	// Register 'list' tool
 	listTool := protocol.Tool{
@@ -151,68 +122,6 @@ func (la *LocalAdapter) registerDatabaseTools() error {
 	return nil
 }
 
-// registerGraphChainTools registers GraphChain tools
-func (la *LocalAdapter) registerGraphChainTools() error {
-	// Register 'graphchain-query' tool
-	queryTool := protocol.Tool{
-		Name:        "graphchain-query",
-		Description: "Query GraphChain knowledge graph",
-		InputSchema: map[string]interface{}{
-			"type": "object",
-			"properties": map[string]interface{}{
-				"query": map[string]interface{}{
-					"type":        "string",
-					"description": "Natural language query",
-				},
-				"max_results": map[string]interface{}{
-					"type":        "integer",
-					"description": "Maximum number of results (default: 10)",
-				},
-			},
-			"required": []string{"query"},
-		},
-	}
-
-	if err := la.registry.RegisterLocal(queryTool, la.handleGraphChainQuery); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// registerTransformTools registers data transformation tools
-func (la *LocalAdapter) registerTransformTools() error {
-	// Register 'transform-keys' tool
-	transformTool := protocol.Tool{
-		Name:        "transform-keys",
-		Description: "Transform keys in RocksDB using patterns",
-		InputSchema: map[string]interface{}{
-			"type": "object",
-			"properties": map[string]interface{}{
-				"pattern": map[string]interface{}{
-					"type":        "string",
-					"description": "Source key pattern",
-				},
-				"replacement": map[string]interface{}{
-					"type":        "string",
-					"description": "Target key pattern",
-				},
-				"dry_run": map[string]interface{}{
-					"type":        "boolean",
-					"description": "Preview changes without applying (default: true)",
-				},
-			},
-			"required": []string{"pattern", "replacement"},
-		},
-	}
-
-	if err := la.registry.RegisterLocal(transformTool, la.handleTransform); err != nil {
-		return err
-	}
-
-	return nil
-}
-
 // Tool handlers
 
 func (la *LocalAdapter) handleDBGet(ctx context.Context, args map[string]interface{}) (*protocol.ToolCallResult, error) {
@@ -273,27 +182,6 @@ func (la *LocalAdapter) handleDBPut(ctx context.Context, args map[string]interfa
 	return successResult(fmt.Sprintf("Successfully stored key '%s' in column family '%s'", key, cf)), nil
 }
 
-func (la *LocalAdapter) handleDBDelete(ctx context.Context, args map[string]interface{}) (*protocol.ToolCallResult, error) {
-	key, ok := args["key"].(string)
-	if !ok {
-		return errorResult("key must be a string"), nil
-	}
-
-	if la.db == nil {
-		return errorResult("database not initialized"), nil
-	}
-
-	// Get column family (default to "default")
-	cf := "default"
-	if cfArg, ok := args["column_family"].(string); ok && cfArg != "" {
-		cf = cfArg
-	}
-
-	// Note: Delete operation would be implemented here
-	// For now, return a placeholder message
-	return successResult(fmt.Sprintf("Delete operation for key '%s' in column family '%s'\n(Integration pending)", key, cf)), nil
-}
-
 func (la *LocalAdapter) handleDBList(ctx context.Context, args map[string]interface{}) (*protocol.ToolCallResult, error) {
 	if la.db == nil {
 		return errorResult("database not initialized"), nil
@@ -313,42 +201,6 @@ func (la *LocalAdapter) handleDBList(ctx context.Context, args map[string]interf
 	return successResult(result), nil
 }
 
-func (la *LocalAdapter) handleGraphChainQuery(ctx context.Context, args map[string]interface{}) (*protocol.ToolCallResult, error) {
-	query, ok := args["query"].(string)
-	if !ok {
-		return errorResult("query must be a string"), nil
-	}
-
-	// Placeholder for GraphChain integration
-	// This would integrate with the actual GraphChain implementation
-	return successResult(fmt.Sprintf("GraphChain query: %s\n(Integration pending)", query)), nil
-}
-
-func (la *LocalAdapter) handleTransform(ctx context.Context, args map[string]interface{}) (*protocol.ToolCallResult, error) {
-	pattern, ok := args["pattern"].(string)
-	if !ok {
-		return errorResult("pattern must be a string"), nil
-	}
-
-	replacement, ok := args["replacement"].(string)
-	if !ok {
-		return errorResult("replacement must be a string"), nil
-	}
-
-	dryRun := true
-	if dr, ok := args["dry_run"].(bool); ok {
-		dryRun = dr
-	}
-
-	// Placeholder for transform integration
-	mode := "DRY RUN"
-	if !dryRun {
-		mode = "APPLIED"
-	}
-
-	return successResult(fmt.Sprintf("Transform %s: %s → %s\n(Integration pending)", mode, pattern, replacement)), nil
-}
-
 // Helper functions
 
 func successResult(text string) *protocol.ToolCallResult {
@@ -90,6 +90,16 @@ func (r *Registry) UnregisterRemote(clientName string) error {
 	return nil
 }
 
+// RemoteTools returns the namespaced tools registered for a remote client,
+// and whether the client has registered tools
+func (r *Registry) RemoteTools(clientName string) ([]protocol.Tool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tools, exists := r.remoteTools[clientName]
+	return append([]protocol.Tool(nil), tools...), exists
+}
+
 // ListTools returns all tools, optionally filtered by namespace
 func (r *Registry) ListTools(namespace string) []protocol.Tool {
 	r.mu.RLock()
@@ -3,7 +3,9 @@ package tools
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"sync"
+	"time"
 
 	"rocksdb-cli/internal/mcp/client"
 	"rocksdb-cli/internal/mcp/protocol"
@@ -15,18 +17,26 @@ type RemoteProxy struct {
 	manager   *client.Manager
 	mu        sync.RWMutex
 	autoSync  bool
+	onChanged []func(clientName string)
 }
 
-// NewRemoteProxy creates a new remote tool proxy
+// NewRemoteProxy creates a new remote tool proxy. Tools of clients
+// reconnected by the manager's supervisors are synced again.
 func NewRemoteProxy(registry *Registry, manager *client.Manager) *RemoteProxy {
-	return &RemoteProxy{
+	rp := &RemoteProxy{
 		registry: registry,
 		manager:  manager,
 		autoSync: false,
 	}
+	manager.OnReconnect(func(ctx context.Context, clientName string) {
+		rp.SyncTools(ctx, clientName)
+	})
+	return rp
 }
 
-// EnableAutoSync enables automatic tool synchronization when clients connect
+// EnableAutoSync enables automatic tool synchronization: while enabled, Run
+// re-syncs the tools of connected clients and drops those of disconnected
+// clients
 func (rp *RemoteProxy) EnableAutoSync() {
 	rp.mu.Lock()
 	defer rp.mu.Unlock()
@@ -40,7 +50,61 @@ func (rp *RemoteProxy) DisableAutoSync() {
 	rp.autoSync = false
 }
 
-// SyncTools synchronizes tools from a specific remote client
+// OnToolsChanged registers fn to be called when the tools registered for a
+// client change
+func (rp *RemoteProxy) OnToolsChanged(fn func(clientName string)) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	rp.onChanged = append(rp.onChanged, fn)
+}
+
+func (rp *RemoteProxy) toolsChanged(clientName string) {
+	rp.mu.RLock()
+	hooks := rp.onChanged
+	rp.mu.RUnlock()
+	for _, fn := range hooks {
+		fn(clientName)
+	}
+}
+
+// Run checks the remote clients every interval while auto-sync is enabled,
+// until ctx is done
+func (rp *RemoteProxy) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rp.mu.RLock()
+			autoSync := rp.autoSync
+			rp.mu.RUnlock()
+			if autoSync {
+				rp.syncConnected(ctx)
+			}
+		}
+	}
+}
+
+// syncConnected syncs the tools of connected clients and drops those of
+// clients that are no longer connected
+func (rp *RemoteProxy) syncConnected(ctx context.Context) {
+	for _, clientName := range rp.manager.ListClients() {
+		mcpClient := rp.manager.GetClient(clientName)
+		if mcpClient != nil && mcpClient.IsConnected() && mcpClient.IsInitialized() {
+			rp.SyncTools(ctx, clientName)
+			continue
+		}
+		if rp.registry.UnregisterRemote(clientName) == nil {
+			rp.toolsChanged(clientName)
+		}
+	}
+}
+
+// SyncTools synchronizes tools from a specific remote client. Tools disabled
+// by the client's enabled_tools/disabled_tools filters are left out.
 func (rp *RemoteProxy) SyncTools(ctx context.Context, clientName string) error {
 	// Get the client
 	mcpClient := rp.manager.GetClient(clientName)
@@ -59,11 +123,24 @@ func (rp *RemoteProxy) SyncTools(ctx context.Context, clientName string) error {
 		return fmt.Errorf("failed to list tools from %s: %w", clientName, err)
 	}
 
+	tools := make([]protocol.Tool, 0, len(toolsResult.Tools))
+	for _, tool := range toolsResult.Tools {
+		if cfg := mcpClient.GetConfig(); cfg == nil || cfg.IsToolEnabled(tool.Name) {
+			tools = append(tools, tool)
+		}
+	}
+
+	previous, registered := rp.registry.RemoteTools(clientName)
+
 	// Register tools in the registry
-	if err := rp.registry.RegisterRemote(clientName, toolsResult.Tools); err != nil {
+	if err := rp.registry.RegisterRemote(clientName, tools); err != nil {
 		return fmt.Errorf("failed to register tools from %s: %w", clientName, err)
 	}
 
+	if current, _ := rp.registry.RemoteTools(clientName); !registered || !reflect.DeepEqual(previous, current) {
+		rp.toolsChanged(clientName)
+	}
+
 	return nil
 }
 
@@ -110,6 +187,11 @@ func (rp *RemoteProxy) Execute(ctx context.Context, namespacedName string, argum
 		return nil, fmt.Errorf("client %s is not connected", clientName)
 	}
 
+	// Refuse tools filtered out of the client's tools
+	if cfg := mcpClient.GetConfig(); cfg != nil && !cfg.IsToolEnabled(toolName) {
+		return nil, fmt.Errorf("tool %s is disabled for client %s", toolName, clientName)
+	}
+
 	// Execute the tool on the remote client
 	result, err := mcpClient.CallTool(ctx, toolName, arguments)
 	if err != nil {
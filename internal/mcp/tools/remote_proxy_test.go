@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"net"
+	"sync"
 	"testing"
 	"time"
 
@@ -201,6 +202,155 @@ func TestRemoteProxy_AutoSync(t *testing.T) {
 	})
 }
 
+// Test that auto-sync picks up remote tool changes and disconnections
+func TestRemoteProxy_AutoSyncDetectsChanges(t *testing.T) {
+	registry := NewRegistry()
+
+	var mu sync.Mutex
+	remoteTools := []protocol.Tool{{Name: "echo", InputSchema: map[string]interface{}{"type": "object"}}}
+	server, port := startMockMCPTCPServerWithTools(t, func() []protocol.Tool {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]protocol.Tool(nil), remoteTools...)
+	})
+	defer server.Close()
+
+	cfg := &config.Config{
+		MCPClients: config.MCPClientsConfig{
+			"test-server": {
+				Name:      "test-server",
+				Enabled:   true,
+				Transport: "tcp",
+				Host:      "localhost",
+				Port:      port,
+				Timeout:   5 * time.Second,
+			},
+		},
+	}
+	manager := client.NewManager(cfg)
+	proxy := NewRemoteProxy(registry, manager)
+
+	changes := make(chan string, 10)
+	proxy.OnToolsChanged(func(clientName string) { changes <- clientName })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, manager.StartClient(ctx, "test-server"))
+	require.NoError(t, proxy.SyncTools(ctx, "test-server"))
+	assert.Equal(t, "test-server", <-changes)
+
+	// Syncing unchanged tools reports nothing
+	require.NoError(t, proxy.SyncTools(ctx, "test-server"))
+	assert.Empty(t, changes)
+
+	proxy.EnableAutoSync()
+	go proxy.Run(ctx, 20*time.Millisecond)
+
+	mu.Lock()
+	remoteTools = append(remoteTools, protocol.Tool{Name: "reverse", InputSchema: map[string]interface{}{"type": "object"}})
+	mu.Unlock()
+
+	select {
+	case name := <-changes:
+		assert.Equal(t, "test-server", name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("remote tool change not detected")
+	}
+	assert.NotNil(t, registry.GetTool("test-server.reverse"))
+
+	// Tools of a disconnected client are dropped
+	require.NoError(t, manager.StopClient(ctx, "test-server"))
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("disconnection not detected")
+	}
+	assert.Empty(t, registry.ListTools("test-server"))
+}
+
+// Test that tools are synced again after a supervisor reconnects a client
+func TestRemoteProxy_ResyncOnReconnect(t *testing.T) {
+	registry := NewRegistry()
+
+	server, port := startMockMCPTCPServer(t)
+	defer server.Close()
+
+	cfg := &config.Config{
+		MCPClients: config.MCPClientsConfig{
+			"test-server": {
+				Name:      "test-server",
+				Enabled:   true,
+				Transport: "tcp",
+				Host:      "localhost",
+				Port:      port,
+				Timeout:   5 * time.Second,
+			},
+		},
+	}
+	manager := client.NewManager(cfg)
+	proxy := NewRemoteProxy(registry, manager)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, manager.StartClient(ctx, "test-server"))
+	defer manager.Shutdown(context.Background())
+	require.NoError(t, proxy.SyncTools(ctx, "test-server"))
+
+	manager.Supervise(ctx)
+	require.NoError(t, registry.UnregisterRemote("test-server"))
+	require.NoError(t, manager.GetClient("test-server").Disconnect(ctx))
+
+	require.NoError(t, manager.Supervisor("test-server").Check(ctx))
+	assert.NotNil(t, registry.GetTool("test-server.echo"))
+	assert.Equal(t, 1, manager.GetClientStatus("test-server").Reconnects)
+}
+
+// Test that a client's enabled/disabled tool filters apply to sync and execution
+func TestRemoteProxy_ToolFilters(t *testing.T) {
+	registry := NewRegistry()
+
+	server, port := startMockMCPTCPServerWithTools(t, func() []protocol.Tool {
+		return []protocol.Tool{
+			{Name: "echo", InputSchema: map[string]interface{}{"type": "object"}},
+			{Name: "delete_all", InputSchema: map[string]interface{}{"type": "object"}},
+		}
+	})
+	defer server.Close()
+
+	cfg := &config.Config{
+		MCPClients: config.MCPClientsConfig{
+			"test-server": {
+				Name:          "test-server",
+				Enabled:       true,
+				Transport:     "tcp",
+				Host:          "localhost",
+				Port:          port,
+				Timeout:       5 * time.Second,
+				DisabledTools: []string{"delete_all"},
+			},
+		},
+	}
+	manager := client.NewManager(cfg)
+	proxy := NewRemoteProxy(registry, manager)
+
+	ctx := context.Background()
+	require.NoError(t, manager.StartClient(ctx, "test-server"))
+	defer manager.StopClient(ctx, "test-server")
+	require.NoError(t, proxy.SyncTools(ctx, "test-server"))
+
+	tools := registry.ListTools("test-server")
+	require.Len(t, tools, 1)
+	assert.Equal(t, "test-server.echo", tools[0].Name)
+
+	_, err := proxy.Execute(ctx, "test-server.delete_all", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "disabled")
+
+	_, err = proxy.Execute(ctx, "test-server.echo", nil)
+	assert.NoError(t, err)
+}
+
 // Test tool refresh
 func TestRemoteProxy_RefreshTools(t *testing.T) {
 	registry := NewRegistry()
@@ -304,6 +454,21 @@ func TestRemoteProxy_ConcurrentExecution(t *testing.T) {
 // Helper function to start mock MCP TCP server
 // Duplicated from client tests for convenience
 func startMockMCPTCPServer(t testing.TB) (net.Listener, int) {
+	return startMockMCPTCPServerWithTools(t, func() []protocol.Tool {
+		return []protocol.Tool{
+			{
+				Name:        "echo",
+				Description: "Echo tool",
+				InputSchema: map[string]interface{}{
+					"type": "object",
+				},
+			},
+		}
+	})
+}
+
+// Helper: Start a mock MCP TCP server listing the tools returned by listTools
+func startMockMCPTCPServerWithTools(t testing.TB, listTools func() []protocol.Tool) (net.Listener, int) {
 	listener, err := net.Listen("tcp", "localhost:0")
 	if err != nil {
 		t.Fatal(err)
@@ -317,7 +482,7 @@ func startMockMCPTCPServer(t testing.TB) (net.Listener, int) {
 			if err != nil {
 				return
 			}
-			go handleMCPConnection(conn)
+			go handleMCPConnection(conn, listTools)
 		}
 	}()
 
@@ -325,7 +490,7 @@ func startMockMCPTCPServer(t testing.TB) (net.Listener, int) {
 }
 
 // Helper: Handle MCP connection
-func handleMCPConnection(conn net.Conn) {
+func handleMCPConnection(conn net.Conn, listTools func() []protocol.Tool) {
 	defer conn.Close()
 
 	decoder := json.NewDecoder(conn)
@@ -361,15 +526,7 @@ func handleMCPConnection(conn net.Conn) {
 
 		case protocol.MethodListTools:
 			resp.Result = protocol.ListToolsResult{
-				Tools: []protocol.Tool{
-					{
-						Name:        "echo",
-						Description: "Echo tool",
-						InputSchema: map[string]interface{}{
-							"type": "object",
-						},
-					},
-				},
+				Tools: listTools(),
 			}
 
 		case protocol.MethodCallTool:
@@ -0,0 +1,277 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"rocksdb-cli/internal/mcp/protocol"
+)
+
+// Argument describes one property of a tool's input schema
+type Argument struct {
+	Name        string
+	Type        string // JSON schema type, empty if not declared
+	Description string
+	Required    bool
+	Enum        []interface{}
+}
+
+// Arguments returns the properties of the tool's input schema, required
+// arguments first, then sorted by name
+func Arguments(tool protocol.Tool) []Argument {
+	properties, _ := tool.InputSchema["properties"].(map[string]interface{})
+	required := make(map[string]bool)
+	for _, name := range schemaList(tool.InputSchema["required"]) {
+		if s, ok := name.(string); ok {
+			required[s] = true
+		}
+	}
+
+	args := make([]Argument, 0, len(properties))
+	for name, raw := range properties {
+		property, _ := raw.(map[string]interface{})
+		arg := Argument{Name: name, Required: required[name], Enum: schemaList(property["enum"])}
+		arg.Type = strings.Join(schemaTypes(property), "|")
+		arg.Description, _ = property["description"].(string)
+		args = append(args, arg)
+	}
+
+	sort.Slice(args, func(i, j int) bool {
+		if args[i].Required != args[j].Required {
+			return args[i].Required
+		}
+		return args[i].Name < args[j].Name
+	})
+	return args
+}
+
+// ParseArguments converts name=value pairs into tool arguments. Each value is
+// converted to the type its property declares: numbers and booleans are
+// parsed, arrays and objects are read as JSON, anything else stays a string.
+// The arguments are not validated; see ValidateArguments.
+func ParseArguments(tool protocol.Tool, pairs []string) (map[string]interface{}, error) {
+	properties, _ := tool.InputSchema["properties"].(map[string]interface{})
+
+	arguments := make(map[string]interface{}, len(pairs))
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("argument %q must be name=value", pair)
+		}
+
+		property, _ := properties[name].(map[string]interface{})
+		converted, err := parseValue(schemaTypes(property), value)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %w", name, err)
+		}
+		arguments[name] = converted
+	}
+	return arguments, nil
+}
+
+// parseValue converts value to the first of types it parses as
+func parseValue(types []string, value string) (interface{}, error) {
+	if len(types) == 0 {
+		return value, nil
+	}
+
+	var lastErr error
+	for _, typ := range types {
+		switch typ {
+		case "string":
+			return value, nil
+		case "integer", "number":
+			// Numbers are float64, as when the arguments are decoded from JSON
+			f, err := strconv.ParseFloat(value, 64)
+			if err == nil && typ == "integer" && f != math.Trunc(f) {
+				err = fmt.Errorf("%q is not an integer", value)
+			}
+			if err != nil {
+				lastErr = fmt.Errorf("%q is not a valid %s", value, typ)
+				continue
+			}
+			return f, nil
+		case "boolean":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				lastErr = fmt.Errorf("%q is not a valid boolean", value)
+				continue
+			}
+			return b, nil
+		case "array", "object":
+			var v interface{}
+			if err := json.Unmarshal([]byte(value), &v); err != nil {
+				lastErr = fmt.Errorf("%q is not a valid JSON %s", value, typ)
+				continue
+			}
+			return v, nil
+		case "null":
+			if value == "null" {
+				return nil, nil
+			}
+			lastErr = fmt.Errorf("%q is not null", value)
+		default:
+			return value, nil
+		}
+	}
+	return nil, lastErr
+}
+
+// ValidateArguments checks arguments against the tool's input schema: all
+// required arguments must be present, each argument must match the type and
+// enum of its property, and arguments without a property are refused when
+// the schema sets additionalProperties to false
+func ValidateArguments(tool protocol.Tool, arguments map[string]interface{}) error {
+	properties, _ := tool.InputSchema["properties"].(map[string]interface{})
+
+	for _, arg := range Arguments(tool) {
+		if _, ok := arguments[arg.Name]; arg.Required && !ok {
+			return fmt.Errorf("missing required argument %s", arg.Name)
+		}
+	}
+
+	names := make([]string, 0, len(arguments))
+	for name := range arguments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := arguments[name]
+		raw, known := properties[name]
+		if !known {
+			if additional, ok := tool.InputSchema["additionalProperties"].(bool); ok && !additional {
+				return fmt.Errorf("unknown argument %s", name)
+			}
+			continue
+		}
+		property, _ := raw.(map[string]interface{})
+
+		if types := schemaTypes(property); len(types) > 0 && !matchesType(types, value) {
+			return fmt.Errorf("argument %s must be of type %s", name, strings.Join(types, " or "))
+		}
+		if enum := schemaList(property["enum"]); len(enum) > 0 && !inEnum(enum, value) {
+			return fmt.Errorf("argument %s must be one of %s", name, formatEnum(enum))
+		}
+	}
+	return nil
+}
+
+// matchesType reports whether value is of one of the JSON schema types
+func matchesType(types []string, value interface{}) bool {
+	for _, typ := range types {
+		switch v := value.(type) {
+		case string:
+			if typ == "string" {
+				return true
+			}
+		case bool:
+			if typ == "boolean" {
+				return true
+			}
+		case float64:
+			if typ == "number" || typ == "integer" && v == math.Trunc(v) {
+				return true
+			}
+		case int, int32, int64:
+			if typ == "number" || typ == "integer" {
+				return true
+			}
+		case []interface{}:
+			if typ == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if typ == "object" {
+				return true
+			}
+		case nil:
+			if typ == "null" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// inEnum reports whether value is one of enum, comparing numbers by value
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if reflect.DeepEqual(allowed, value) {
+			return true
+		}
+		if a, ok := toFloat(allowed); ok {
+			if v, ok := toFloat(value); ok && a == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func formatEnum(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, v := range enum {
+		data, _ := json.Marshal(v)
+		values[i] = string(data)
+	}
+	return strings.Join(values, ", ")
+}
+
+// schemaTypes returns the types a property declares; "type" may be a string
+// or a list of strings
+func schemaTypes(property map[string]interface{}) []string {
+	switch t := property["type"].(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// schemaList returns a schema list as []interface{}. Schemas built in Go use
+// typed slices such as []string; schemas decoded from JSON use []interface{}.
+func schemaList(v interface{}) []interface{} {
+	switch list := v.(type) {
+	case []interface{}:
+		return list
+	case nil:
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return nil
+	}
+	list := make([]interface{}, rv.Len())
+	for i := range list {
+		list[i] = rv.Index(i).Interface()
+	}
+	return list
+}
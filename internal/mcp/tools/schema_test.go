@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"rocksdb-cli/internal/mcp/protocol"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func schemaTestTool() protocol.Tool {
+	return protocol.Tool{
+		Name: "scan",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"cf":      map[string]interface{}{"type": "string", "description": "Column family"},
+				"limit":   map[string]interface{}{"type": "integer"},
+				"ratio":   map[string]interface{}{"type": "number"},
+				"reverse": map[string]interface{}{"type": "boolean"},
+				"keys":    map[string]interface{}{"type": "array"},
+				"order":   map[string]interface{}{"type": "string", "enum": []string{"asc", "desc"}},
+				"raw":     map[string]interface{}{},
+			},
+			"required":             []string{"cf"},
+			"additionalProperties": false,
+		},
+	}
+}
+
+func TestArguments(t *testing.T) {
+	args := Arguments(schemaTestTool())
+	require.Len(t, args, 7)
+
+	assert.Equal(t, Argument{Name: "cf", Type: "string", Description: "Column family", Required: true}, args[0])
+	assert.Equal(t, "keys", args[1].Name, "optional arguments follow, sorted")
+	assert.Equal(t, []interface{}{"asc", "desc"}, args[3].Enum)
+	assert.Equal(t, "", args[5].Type, "raw declares no type")
+}
+
+func TestParseArguments(t *testing.T) {
+	tool := schemaTestTool()
+
+	args, err := ParseArguments(tool, []string{
+		"cf=users", "limit=10", "ratio=0.5", "reverse=true", `keys=["a","b"]`, "raw=a=b", "extra=1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"cf":      "users",
+		"limit":   float64(10),
+		"ratio":   0.5,
+		"reverse": true,
+		"keys":    []interface{}{"a", "b"},
+		"raw":     "a=b",
+		"extra":   "1",
+	}, args)
+
+	tests := []struct {
+		pair string
+		err  string
+	}{
+		{"cf", "must be name=value"},
+		{"=users", "must be name=value"},
+		{"limit=1.5", "not a valid integer"},
+		{"limit=ten", "not a valid integer"},
+		{"reverse=maybe", "not a valid boolean"},
+		{"keys=a,b", "not a valid JSON array"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pair, func(t *testing.T) {
+			_, err := ParseArguments(tool, []string{tt.pair})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestValidateArguments(t *testing.T) {
+	tool := schemaTestTool()
+
+	assert.NoError(t, ValidateArguments(tool, map[string]interface{}{"cf": "users", "limit": float64(5), "order": "asc"}))
+
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		err  string
+	}{
+		{"missing required", map[string]interface{}{"limit": float64(5)}, "missing required argument cf"},
+		{"wrong type", map[string]interface{}{"cf": true}, "argument cf must be of type string"},
+		{"fractional integer", map[string]interface{}{"cf": "users", "limit": 2.5}, "argument limit must be of type integer"},
+		{"not in enum", map[string]interface{}{"cf": "users", "order": "random"}, `argument order must be one of "asc", "desc"`},
+		{"unknown", map[string]interface{}{"cf": "users", "extra": "1"}, "unknown argument extra"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateArguments(tool, tt.args)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestValidateArguments_DecodedSchema(t *testing.T) {
+	// Remote tools carry schemas decoded from JSON
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"path": {"type": "string"},
+			"depth": {"type": ["integer", "null"], "enum": [1, 2, null]}
+		},
+		"required": ["path"]
+	}`), &schema))
+	tool := protocol.Tool{Name: "read", InputSchema: schema}
+
+	args, err := ParseArguments(tool, []string{"path=/tmp", "depth=2", "other=x"})
+	require.NoError(t, err)
+	assert.NoError(t, ValidateArguments(tool, args), "additional properties are allowed by default")
+
+	args, err = ParseArguments(tool, []string{"path=/tmp", "depth=null"})
+	require.NoError(t, err)
+	assert.NoError(t, ValidateArguments(tool, args))
+
+	assert.Error(t, ValidateArguments(tool, map[string]interface{}{"path": "/tmp", "depth": float64(3)}))
+	assert.Error(t, ValidateArguments(tool, map[string]interface{}{"depth": float64(1)}))
+}
@@ -0,0 +1,199 @@
+// Package output writes command results in machine-readable formats.
+//
+// Commands describe a result once as a Result and Write renders it in the
+// selected Format. JSON, JSONL and YAML share one schema, the JSON encoding
+// of the result types in package db, so scripts can rely on the same field
+// names in every format.
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format selects how results are written
+type Format string
+
+const (
+	Text  Format = "text"  // the command's usual human-readable output
+	Table Format = "table" // aligned columns with a header
+	JSON  Format = "json"  // one indented JSON document
+	JSONL Format = "jsonl" // one JSON document per item and line
+	YAML  Format = "yaml"  // YAML with the field names of the JSON schema
+	CSV   Format = "csv"   // comma-separated columns with a header
+	Raw   Format = "raw"   // values only, one per line, unquoted
+)
+
+// Formats lists the supported formats
+var Formats = []Format{Text, Table, JSON, JSONL, YAML, CSV, Raw}
+
+// FormatNames returns the names of the supported formats
+func FormatNames() []string {
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return names
+}
+
+// ParseFormat parses a format name; the empty name selects Text
+func ParseFormat(name string) (Format, error) {
+	if name == "" {
+		return Text, nil
+	}
+	for _, f := range Formats {
+		if string(f) == strings.ToLower(name) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q (use %s)", name, strings.Join(FormatNames(), ", "))
+}
+
+// Result is a command result in the shapes the formats need
+type Result struct {
+	// Data is the complete result, written by json and yaml
+	Data interface{}
+	// Items are written by jsonl, one per line; Data is written when nil
+	Items []interface{}
+	// Columns and Rows are written by table and csv
+	Columns []string
+	Rows    [][]string
+	// Raw lines are written by raw
+	Raw []string
+}
+
+// Write writes r to w in format. Text is not handled here: commands print
+// their usual output themselves.
+func Write(w io.Writer, format Format, r Result) error {
+	switch format {
+	case JSON:
+		return writeJSON(w, r.Data, "  ")
+	case JSONL:
+		items := r.Items
+		if items == nil {
+			items = []interface{}{r.Data}
+		}
+		for _, item := range items {
+			if err := writeJSON(w, item, ""); err != nil {
+				return err
+			}
+		}
+		return nil
+	case YAML:
+		return writeYAML(w, r.Data)
+	case CSV:
+		cw := csv.NewWriter(w)
+		cw.Write(r.Columns)
+		cw.WriteAll(r.Rows)
+		return cw.Error()
+	case Table:
+		return writeTable(w, r.Columns, r.Rows)
+	case Raw:
+		for _, line := range r.Raw {
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("output format %q cannot be written", format)
+}
+
+func writeJSON(w io.Writer, v interface{}, indent string) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	return enc.Encode(v)
+}
+
+// writeYAML writes v as YAML through its JSON encoding, keeping the JSON
+// field names and order
+func writeYAML(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	// JSON is YAML; decoding it to a node keeps the order of the fields
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// blockStyle clears the flow and quoting styles the JSON syntax left on the
+// nodes, so the encoder writes block YAML and quotes only where needed
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+func writeTable(w io.Writer, columns []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = strings.ToUpper(column)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			// Keep each row on one line
+			cells[i] = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ").Replace(cell)
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// fields returns the top-level fields of the JSON encoding of v in order,
+// strings unquoted and other values as compact JSON
+func fields(v interface{}) ([][]string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return [][]string{{"value", string(data)}}, nil
+	}
+
+	var rows [][]string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		rows = append(rows, []string{tok.(string), scalar(raw)})
+	}
+	return rows, nil
+}
+
+// scalar returns a JSON string unquoted and any other JSON value as is
+func scalar(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	return string(raw)
+}
@@ -0,0 +1,239 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/keytree"
+	"rocksdb-cli/internal/schema"
+	"rocksdb-cli/internal/service"
+	"rocksdb-cli/internal/timeseries"
+)
+
+func TestParseFormat(t *testing.T) {
+	for _, name := range []string{"", "text", "table", "json", "jsonl", "yaml", "csv", "raw", "JSON"} {
+		if _, err := ParseFormat(name); err != nil {
+			t.Errorf("ParseFormat(%q): %v", name, err)
+		}
+	}
+	if f, _ := ParseFormat(""); f != Text {
+		t.Errorf("ParseFormat(\"\") = %q, want %q", f, Text)
+	}
+	if _, err := ParseFormat("xml"); err == nil || !strings.Contains(err.Error(), "json, jsonl") {
+		t.Errorf("ParseFormat(\"xml\") should fail listing the formats, got %v", err)
+	}
+}
+
+func scanPage() db.ScanPageResult {
+	expires := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	return db.ScanPageResult{
+		Results: map[string]string{"user:1": `{"name":"Alice"}`},
+		ResultsV2: []db.KeyValue{
+			Entry("user:1", `{"name":"Alice"}`),
+			{Key: "user:2", Value: "line one\nline two", TTL: &db.TTLInfo{ExpiresAt: &expires}},
+		},
+		NextCursor: "user:2",
+		HasMore:    true,
+	}
+}
+
+func write(t *testing.T, format Format, r Result) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, format, r); err != nil {
+		t.Fatalf("Write(%s): %v", format, err)
+	}
+	return buf.String()
+}
+
+func TestWriteScan(t *testing.T) {
+	r := Entries(scanPage(), true)
+
+	var decoded struct {
+		Results    []db.KeyValue `json:"results"`
+		NextCursor string        `json:"next_cursor"`
+		HasMore    bool          `json:"has_more"`
+	}
+	out := write(t, JSON, r)
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("json output is not JSON: %v\n%s", err, out)
+	}
+	if len(decoded.Results) != 2 || decoded.NextCursor != "user:2" || !decoded.HasMore {
+		t.Errorf("unexpected json output:\n%s", out)
+	}
+	if strings.Contains(out, "Results\"") || strings.Contains(out, "ResultsV2") {
+		t.Errorf("json output should use the documented field names:\n%s", out)
+	}
+
+	lines := strings.Split(strings.TrimSpace(write(t, JSONL, r)), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], `{"key":"user:1"`) {
+		t.Errorf("jsonl should write one entry per line, got %q", lines)
+	}
+
+	yml := write(t, YAML, r)
+	for _, want := range []string{"results:\n", "  - key: user:1\n", "next_cursor: user:2\n", "has_more: true\n"} {
+		if !strings.Contains(yml, want) {
+			t.Errorf("yaml output lacks %q:\n%s", want, yml)
+		}
+	}
+
+	csv := write(t, CSV, r)
+	if want := "key,value,expires_at\nuser:1,\"{\"\"name\"\":\"\"Alice\"\"}\",\n"; !strings.HasPrefix(csv, want) {
+		t.Errorf("csv output = %q, want prefix %q", csv, want)
+	}
+
+	table := write(t, Table, r)
+	if !strings.HasPrefix(table, "KEY") || !strings.Contains(table, "line one line two  2024-05-01T10:00:00Z") {
+		t.Errorf("unexpected table output:\n%s", table)
+	}
+
+	if raw := write(t, Raw, r); raw != "{\"name\":\"Alice\"}\nline one\nline two\n" {
+		t.Errorf("raw output = %q", raw)
+	}
+	if raw := write(t, Raw, Entries(scanPage(), false)); raw != "user:1\nuser:2\n" {
+		t.Errorf("raw output without values = %q, want the keys", raw)
+	}
+}
+
+func TestWriteEmpty(t *testing.T) {
+	r := Entries(db.ScanPageResult{}, true)
+	if out := write(t, JSON, r); !strings.Contains(out, `"results": []`) {
+		t.Errorf("empty results should be an empty array:\n%s", out)
+	}
+	if out := write(t, JSONL, r); out != "" {
+		t.Errorf("jsonl of no entries = %q, want nothing", out)
+	}
+	if out := write(t, CSV, r); out != "key,value\n" {
+		t.Errorf("csv of no entries = %q, want the header", out)
+	}
+}
+
+func TestObject(t *testing.T) {
+	stats := db.CFStats{Name: "users", KeyCount: 3, SampleKeys: []string{"a", "b"}}
+	r := Object(stats)
+
+	if r.Rows[0][0] != "name" || r.Rows[0][1] != "users" || r.Rows[1][0] != "key_count" || r.Rows[1][1] != "3" {
+		t.Errorf("rows should follow the JSON fields in order, got %q", r.Rows[:2])
+	}
+	if out := write(t, Table, r); !strings.Contains(out, "\nsample_keys  ") || !strings.Contains(out, ` ["a","b"]`) {
+		t.Errorf("unexpected table output:\n%s", out)
+	}
+	if out := write(t, YAML, r); !strings.HasPrefix(out, "name: users\nkey_count: 3\n") {
+		t.Errorf("unexpected yaml output:\n%s", out)
+	}
+
+	done := Done(Status{Operation: "put", ColumnFamily: "users", Key: "k"})
+	if out := write(t, JSONL, done); out != `{"status":"ok","operation":"put","column_family":"users","key":"k"}`+"\n" {
+		t.Errorf("jsonl status = %q", out)
+	}
+	if out := write(t, Raw, done); out != "OK\n" {
+		t.Errorf("raw status = %q", out)
+	}
+}
+
+func TestKeyTree(t *testing.T) {
+	opts := keytree.Options{}
+	opts.Validate()
+	b := keytree.NewBuilder(opts)
+	for _, key := range []string{"user:1:a", "user:2:a", "\x00\x01:x"} {
+		b.Add([]byte(key), 1)
+	}
+	r := KeyTree(b.Tree())
+
+	if out := write(t, JSONL, r); !strings.HasPrefix(out, `{"prefix":"","keys":3,"key_bytes":20,"value_bytes":3,"approximate_size":0}`+"\n") {
+		t.Errorf("unexpected jsonl output:\n%s", out)
+	}
+	if out := write(t, Raw, r); out != "\t3\n00013a\t1\nuser:\t2\nuser:1:\t1\nuser:2:\t1\n" {
+		t.Errorf("raw tree = %q", out)
+	}
+	if out := write(t, JSON, r); !strings.Contains(out, `"children": [`) {
+		t.Errorf("json should write the tree:\n%s", out)
+	}
+}
+
+func TestSeriesAndHistogram(t *testing.T) {
+	page := &timeseries.Page{
+		Codec:   timeseries.Codec{Encoding: timeseries.Millis},
+		Entries: []db.KeyValue{{Key: "1709287200000", Value: "a", Timestamp: "2024-03-01T10:00:00Z"}},
+	}
+	if out := write(t, CSV, Series(page, true)); out != "time,key,value\n2024-03-01T10:00:00Z,1709287200000,a\n" {
+		t.Errorf("csv series = %q", out)
+	}
+	if out := write(t, Raw, Series(page, false)); out != "1709287200000\n" {
+		t.Errorf("raw series without values = %q", out)
+	}
+
+	h := &timeseries.Histogram{Bucket: "1h0m0s", Total: 2, Buckets: []timeseries.Bucket{
+		{Start: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), Count: 2},
+		{Start: time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC)},
+	}}
+	if out := write(t, Raw, Histogram(h)); out != "2024-03-01T10:00:00Z\t2\n2024-03-01T11:00:00Z\t0\n" {
+		t.Errorf("raw histogram = %q", out)
+	}
+	if out := write(t, JSON, Histogram(h)); !strings.Contains(out, `"total": 2`) {
+		t.Errorf("json should write the whole histogram:\n%s", out)
+	}
+}
+
+func TestSchemaResults(t *testing.T) {
+	s := &schema.Schema{Type: schema.Types{schema.TypeObject}, Required: []string{"status"}, Properties: map[string]*schema.Schema{
+		"status": {Type: schema.Types{schema.TypeString, schema.TypeNull}, Presence: 100, Enum: []any{"active", nil}},
+		"age":    {Type: schema.Types{schema.TypeInteger}, Presence: 50, Examples: []any{31, 25}},
+	}}
+	want := "path,type,presence,required,enum,examples\n$,object,100,true,,\n$.age,integer,50,false,,\"31, 25\"\n$.status,string|null,100,true,\"\"\"active\"\", null\",\n"
+	if out := write(t, CSV, Schema(s)); out != want {
+		t.Errorf("csv schema = %q, want %q", out, want)
+	}
+	if out := write(t, Raw, Schema(s)); !strings.HasPrefix(out, `{"type":"object"`) {
+		t.Errorf("raw schema should be the compact schema: %q", out)
+	}
+
+	report := &schema.Report{
+		Checked:    2,
+		Violations: []schema.Violation{{Key: "u1", Path: "$.age", Message: "is string, want integer"}},
+		NewFields:  []schema.NewField{{Path: "$.email", Count: 2, Type: schema.Types{schema.TypeString}, ExampleKey: "u2"}},
+	}
+	if out := write(t, Raw, SchemaReport(report)); out != "u1\t$.age\tis string, want integer\nu2\t$.email\tnew field (string) in 2 documents\n" {
+		t.Errorf("raw report = %q", out)
+	}
+	if out := write(t, JSON, SchemaReport(report)); !strings.Contains(out, `"checked": 2`) {
+		t.Errorf("json should write the whole report:\n%s", out)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	result := &service.TransformResult{
+		Processed: 3,
+		Modified:  1,
+		Skipped:   1,
+		Errors:    []service.TransformError{{Key: "c", OriginalValue: "x", Error: "bad value"}},
+		Preview: []service.TransformPreview{
+			{OriginalKey: "a", OriginalValue: "foo", TransformedValue: "FOO", WillModify: true},
+			{OriginalKey: "b", OriginalValue: "bar", Skipped: true},
+		},
+	}
+	want := "key,status,value,result\na,modify,foo,FOO\nb,skipped,bar,\nc,error,x,bad value\n"
+	if out := write(t, CSV, Transform(result)); out != want {
+		t.Errorf("csv transform = %q, want %q", out, want)
+	}
+	if out := write(t, JSON, Transform(result)); !strings.Contains(out, `"processed": 3`) || !strings.Contains(out, `"modified": 1`) {
+		t.Errorf("json should write the counts:\n%s", out)
+	}
+}
+
+func TestJSONValue(t *testing.T) {
+	r := JSONValue(`["go","db"]`)
+	if out := write(t, Raw, r); out != "go\ndb\n" {
+		t.Errorf("raw output of an array = %q", out)
+	}
+	if out := write(t, JSON, r); out != "[\n  \"go\",\n  \"db\"\n]\n" {
+		t.Errorf("json output = %q", out)
+	}
+	if out := write(t, Raw, JSONValue(`{"a":1}`)); out != "{\"a\":1}\n" {
+		t.Errorf("raw output of an object = %q", out)
+	}
+}
@@ -0,0 +1,372 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/keytree"
+	"rocksdb-cli/internal/schema"
+	"rocksdb-cli/internal/service"
+	"rocksdb-cli/internal/timeseries"
+	"rocksdb-cli/internal/util"
+)
+
+// Status is the result of a command that changes the database
+type Status struct {
+	Status       string `json:"status"` // always "ok"; failures are reported as errors
+	Operation    string `json:"operation"`
+	ColumnFamily string `json:"column_family,omitempty"`
+	Key          string `json:"key,omitempty"`
+	File         string `json:"file,omitempty"`
+}
+
+// KeyFormat is the detected key format of a column family
+type KeyFormat struct {
+	ColumnFamily string `json:"column_family"`
+	Format       string `json:"format"` // string, uint64, hex or mixed
+	Description  string `json:"description"`
+}
+
+// NewKeyFormat returns the key format of cf as detected by the db package
+func NewKeyFormat(cf string, format util.KeyFormat, description string) KeyFormat {
+	names := map[util.KeyFormat]string{
+		util.KeyFormatString:   "string",
+		util.KeyFormatUint64BE: "uint64",
+		util.KeyFormatHex:      "hex",
+		util.KeyFormatMixed:    "mixed",
+	}
+	return KeyFormat{ColumnFamily: cf, Format: names[format], Description: description}
+}
+
+// Entry returns a key-value pair as in the scan and search results of the
+// db package: binary keys and values are hex encoded and flagged
+func Entry(key, value string) db.KeyValue {
+	k, keyIsBinary := util.EncodeValue([]byte(key))
+	v, valueIsBinary := util.EncodeValue([]byte(value))
+	return db.KeyValue{
+		Key:           k,
+		Value:         v,
+		KeyIsBinary:   keyIsBinary,
+		ValueIsBinary: valueIsBinary,
+		Timestamp:     util.ParseTimestamp(k),
+	}
+}
+
+// EntriesOf returns the key-value pairs of m sorted by key
+func EntriesOf(m map[string]string) []db.KeyValue {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entries := make([]db.KeyValue, len(keys))
+	for i, key := range keys {
+		entries[i] = Entry(key, m[key])
+	}
+	return entries
+}
+
+// Value returns the result of a single key lookup
+func Value(kv db.KeyValue) Result {
+	withTTL := kv.TTL != nil
+	return Result{
+		Data:    kv,
+		Columns: entryColumns(true, withTTL),
+		Rows:    [][]string{entryRow(kv.Key, kv.Value, kv.TTL, true, withTTL)},
+		Raw:     []string{kv.Value},
+	}
+}
+
+// Entries returns the result of a scan. Data is the page itself; without
+// values, table and csv show only the keys and raw writes the keys.
+func Entries(page db.ScanPageResult, values bool) Result {
+	if page.ResultsV2 == nil {
+		page.ResultsV2 = []db.KeyValue{}
+	}
+	withTTL := false
+	for _, kv := range page.ResultsV2 {
+		withTTL = withTTL || kv.TTL != nil
+	}
+
+	r := Result{Data: page, Items: []interface{}{}, Columns: entryColumns(values, withTTL)}
+	for _, kv := range page.ResultsV2 {
+		r.Items = append(r.Items, kv)
+		r.Rows = append(r.Rows, entryRow(kv.Key, kv.Value, kv.TTL, values, withTTL))
+		r.Raw = append(r.Raw, rawEntry(kv.Key, kv.Value, values))
+	}
+	return r
+}
+
+// Search returns the result of a search, written like a scan
+func Search(results *db.SearchResults, values bool) Result {
+	if results.Results == nil {
+		results.Results = []db.SearchResult{}
+	}
+	withTTL := false
+	for _, sr := range results.Results {
+		withTTL = withTTL || sr.TTL != nil
+	}
+
+	r := Result{Data: results, Items: []interface{}{}, Columns: entryColumns(values, withTTL)}
+	for _, sr := range results.Results {
+		r.Items = append(r.Items, sr)
+		r.Rows = append(r.Rows, entryRow(sr.Key, sr.Value, sr.TTL, values, withTTL))
+		r.Raw = append(r.Raw, rawEntry(sr.Key, sr.Value, values))
+	}
+	return r
+}
+
+func entryColumns(values, withTTL bool) []string {
+	columns := []string{"key"}
+	if values {
+		columns = append(columns, "value")
+	}
+	if withTTL {
+		columns = append(columns, "expires_at")
+	}
+	return columns
+}
+
+func entryRow(key, value string, ttl *db.TTLInfo, values, withTTL bool) []string {
+	row := []string{key}
+	if values {
+		row = append(row, value)
+	}
+	if withTTL {
+		expires := ""
+		if ttl != nil && ttl.ExpiresAt != nil {
+			expires = ttl.ExpiresAt.Format(time.RFC3339)
+		}
+		row = append(row, expires)
+	}
+	return row
+}
+
+func rawEntry(key, value string, values bool) string {
+	if values {
+		return value
+	}
+	return key
+}
+
+// DatabaseStats returns the database statistics with one table row per
+// column family
+func DatabaseStats(stats *db.DatabaseStats) Result {
+	r := Result{
+		Data:    stats,
+		Items:   []interface{}{},
+		Columns: []string{"name", "key_count", "total_key_size", "total_value_size"},
+		Raw:     []string{compact(stats)},
+	}
+	for _, cf := range stats.ColumnFamilies {
+		r.Items = append(r.Items, cf)
+		r.Rows = append(r.Rows, []string{
+			cf.Name,
+			strconv.FormatInt(cf.KeyCount, 10),
+			strconv.FormatInt(cf.TotalKeySize, 10),
+			strconv.FormatInt(cf.TotalValueSize, 10),
+		})
+	}
+	return r
+}
+
+// Object returns the result of a command showing a single object, such as
+// column family statistics. table and csv show one row per top-level field
+// of its JSON encoding; raw writes it as one line of JSON.
+func Object(v interface{}) Result {
+	rows, _ := fields(v)
+	return Result{
+		Data:    v,
+		Columns: []string{"field", "value"},
+		Rows:    rows,
+		Raw:     []string{compact(v)},
+	}
+}
+
+// treeNode is a node of a key tree without its children
+type treeNode struct {
+	Prefix          string `json:"prefix"`
+	Keys            int64  `json:"keys"`
+	KeyBytes        int64  `json:"key_bytes"`
+	ValueBytes      int64  `json:"value_bytes"`
+	ApproximateSize uint64 `json:"approximate_size"`
+}
+
+// KeyTree returns a tree of key prefixes. json and yaml write the tree; jsonl,
+// table and csv write one node per line, parents first, and raw writes the
+// prefixes with their key counts.
+func KeyTree(root *keytree.Node) Result {
+	r := Result{Data: root, Items: []interface{}{}, Columns: []string{"prefix", "keys", "key_bytes", "value_bytes", "approximate_size"}}
+	root.Walk(func(n *keytree.Node) {
+		prefix, _ := keytree.Encode(n.Prefix)
+		if n.Other {
+			prefix += keytree.OtherLabel
+		}
+		r.Items = append(r.Items, treeNode{prefix, n.Keys, n.KeyBytes, n.ValueBytes, n.ApproximateSize})
+		r.Rows = append(r.Rows, []string{
+			prefix,
+			strconv.FormatInt(n.Keys, 10),
+			strconv.FormatInt(n.KeyBytes, 10),
+			strconv.FormatInt(n.ValueBytes, 10),
+			strconv.FormatUint(n.ApproximateSize, 10),
+		})
+		r.Raw = append(r.Raw, prefix+"\t"+strconv.FormatInt(n.Keys, 10))
+	})
+	return r
+}
+
+// Series returns entries read by time. Data is the page with its codec and
+// cursor; table and csv show the time of each key before it.
+func Series(page *timeseries.Page, values bool) Result {
+	columns := append([]string{"time"}, entryColumns(values, false)...)
+	r := Result{Data: page, Items: []interface{}{}, Columns: columns}
+	for _, kv := range page.Entries {
+		r.Items = append(r.Items, kv)
+		r.Rows = append(r.Rows, append([]string{kv.Timestamp}, entryRow(kv.Key, kv.Value, nil, values, false)...))
+		r.Raw = append(r.Raw, rawEntry(kv.Key, kv.Value, values))
+	}
+	return r
+}
+
+// Histogram returns the entry counts per time bucket, one bucket per line
+func Histogram(h *timeseries.Histogram) Result {
+	r := Result{Data: h, Items: []interface{}{}, Columns: []string{"start", "count"}}
+	for _, b := range h.Buckets {
+		start := b.Start.Format(time.RFC3339)
+		r.Items = append(r.Items, b)
+		r.Rows = append(r.Rows, []string{start, strconv.FormatInt(b.Count, 10)})
+		r.Raw = append(r.Raw, start+"\t"+strconv.FormatInt(b.Count, 10))
+	}
+	return r
+}
+
+// Schema returns an inferred JSON Schema. json and yaml write the schema and
+// raw writes it as one line of JSON; jsonl, table and csv write one field per
+// line.
+func Schema(s *schema.Schema) Result {
+	r := Result{Data: s, Items: []interface{}{}, Columns: []string{"path", "type", "presence", "required", "enum", "examples"}, Raw: []string{compact(s)}}
+	for _, f := range s.Fields() {
+		r.Items = append(r.Items, f)
+		r.Rows = append(r.Rows, []string{
+			f.Path,
+			f.Type.String(),
+			strconv.FormatFloat(f.Presence, 'f', -1, 64),
+			strconv.FormatBool(f.Required),
+			schema.FormatValues(f.Enum),
+			schema.FormatValues(f.Examples),
+		})
+	}
+	return r
+}
+
+// schemaProblem is a violation or a new field of a schema check
+type schemaProblem struct {
+	Key     string `json:"key"`
+	Path    string `json:"path"`
+	Problem string `json:"problem"`
+}
+
+// SchemaReport returns the result of a schema check. json and yaml write the
+// report; jsonl, table, csv and raw write the listed violations, then the
+// new fields with the key of a document having them.
+func SchemaReport(report *schema.Report) Result {
+	r := Result{Data: report, Items: []interface{}{}, Columns: []string{"key", "path", "problem"}}
+	add := func(p schemaProblem) {
+		r.Items = append(r.Items, p)
+		r.Rows = append(r.Rows, []string{p.Key, p.Path, p.Problem})
+		r.Raw = append(r.Raw, p.Key+"\t"+p.Path+"\t"+p.Problem)
+	}
+	for _, v := range report.Violations {
+		add(schemaProblem{v.Key, v.Path, v.Message})
+	}
+	for _, f := range report.NewFields {
+		add(schemaProblem{f.ExampleKey, f.Path, fmt.Sprintf("new field (%s) in %d documents", f.Type, f.Count)})
+	}
+	return r
+}
+
+// transformEntry is a previewed entry or an error of a transformation
+type transformEntry struct {
+	Key    string `json:"key"`
+	Status string `json:"status"` // modify, unchanged, skipped or error
+	Value  string `json:"value"`
+	Result string `json:"result,omitempty"` // the transformed value or the error
+}
+
+// Transform returns the result of a transformation. json and yaml write the
+// counts with the dry-run preview and the errors; jsonl, table, csv and raw
+// write the previewed entries, then the errors.
+func Transform(result *service.TransformResult) Result {
+	r := Result{Data: result, Items: []interface{}{}, Columns: []string{"key", "status", "value", "result"}}
+	add := func(e transformEntry) {
+		r.Items = append(r.Items, e)
+		r.Rows = append(r.Rows, []string{e.Key, e.Status, e.Value, e.Result})
+		r.Raw = append(r.Raw, e.Key+"\t"+e.Status+"\t"+e.Result)
+	}
+	for _, p := range result.Preview {
+		switch {
+		case p.WillModify:
+			add(transformEntry{p.OriginalKey, "modify", p.OriginalValue, p.TransformedValue})
+		case p.Skipped:
+			add(transformEntry{Key: p.OriginalKey, Status: "skipped", Value: p.OriginalValue})
+		default:
+			add(transformEntry{Key: p.OriginalKey, Status: "unchanged", Value: p.OriginalValue})
+		}
+	}
+	for _, e := range result.Errors {
+		add(transformEntry{e.Key, "error", e.OriginalValue, e.Error})
+	}
+	return r
+}
+
+// Done returns the result of a command that changed the database
+func Done(s Status) Result {
+	s.Status = "ok"
+	r := Object(s)
+	r.Raw = []string{"OK"}
+	return r
+}
+
+// List returns a list of names, such as column families
+func List(column string, names []string) Result {
+	if names == nil {
+		names = []string{}
+	}
+	r := Result{Data: names, Items: []interface{}{}, Columns: []string{column}, Raw: names}
+	for _, name := range names {
+		r.Items = append(r.Items, name)
+		r.Rows = append(r.Rows, []string{name})
+	}
+	return r
+}
+
+// JSONValue returns a JSON document, such as the result of a JSONPath query.
+// The elements of an array are written as separate items, rows and lines.
+func JSONValue(doc string) Result {
+	raw := json.RawMessage(doc)
+	r := Result{Data: raw, Items: []interface{}{}, Columns: []string{"value"}}
+
+	var elements []json.RawMessage
+	if json.Unmarshal(raw, &elements) != nil {
+		elements = []json.RawMessage{raw}
+	}
+	for _, element := range elements {
+		r.Items = append(r.Items, element)
+		r.Rows = append(r.Rows, []string{scalar(element)})
+		r.Raw = append(r.Raw, scalar(element))
+	}
+	return r
+}
+
+func compact(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
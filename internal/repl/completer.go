@@ -0,0 +1,272 @@
+package repl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"rocksdb-cli/internal/command"
+	"rocksdb-cli/internal/completion"
+	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/mcp/protocol"
+	"rocksdb-cli/internal/mcp/tools"
+
+	prompt "github.com/c-bata/go-prompt"
+)
+
+// newCompleter completes command names, the flags of each command and its
+// arguments as described by command.Specs: column family names, key
+// prefixes of the command's column family, and JSON fields and paths sampled
+// from its values, using the same suggestions as MCP completion/complete.
+// The query and set commands complete saved query names and output settings.
+// The tools command completes tool names and arguments from registry.
+func newCompleter(rdb db.KeyValueDB, state *command.ReplState, registry *tools.Registry) prompt.Completer {
+	return func(d prompt.Document) []prompt.Suggest {
+		args := strings.Fields(d.TextBeforeCursor())
+		word := d.GetWordBeforeCursor()
+		if word == "" {
+			// The cursor starts a new argument
+			args = append(args, "")
+		}
+		return suggest(rdb, state, registry, args, word)
+	}
+}
+
+// suggest completes word, the last of args
+func suggest(rdb db.KeyValueDB, state *command.ReplState, registry *tools.Registry, args []string, word string) []prompt.Suggest {
+	if len(args) == 1 {
+		return commandSuggestions(word)
+	}
+	name := strings.ToLower(args[0])
+	if name == "tools" {
+		return toolSuggestions(registry, args, word)
+	}
+	spec, ok := command.LookupSpec(name)
+	if !ok {
+		return []prompt.Suggest{}
+	}
+
+	var given []string
+	used := make(map[string]bool)
+	for _, arg := range args[1 : len(args)-1] {
+		if strings.HasPrefix(arg, "--") {
+			flag, _, _ := strings.Cut(arg[2:], "=")
+			used[flag] = true
+		} else {
+			given = append(given, arg)
+		}
+	}
+
+	if strings.HasPrefix(word, "-") {
+		return flagSuggestions(spec, used, word)
+	}
+	return argSuggestions(rdb, state, spec, given, word)
+}
+
+// commandSuggestions completes command names
+func commandSuggestions(word string) []prompt.Suggest {
+	s := make([]prompt.Suggest, 0, len(command.Specs))
+	for _, spec := range command.Specs {
+		s = append(s, prompt.Suggest{Text: spec.Name, Description: spec.Description})
+	}
+	return prompt.FilterHasPrefix(s, word, true)
+}
+
+// flagSuggestions completes the flags of a command not given yet, and the
+// values of a flag once --name= is typed
+func flagSuggestions(spec command.Spec, used map[string]bool, word string) []prompt.Suggest {
+	var s []prompt.Suggest
+	if name, _, typed := strings.Cut(strings.TrimPrefix(word, "--"), "="); typed {
+		for _, flag := range spec.Flags {
+			if flag.Name != name {
+				continue
+			}
+			for _, value := range flag.Values {
+				s = append(s, prompt.Suggest{Text: "--" + flag.Name + "=" + value})
+			}
+		}
+	} else {
+		for _, flag := range spec.Flags {
+			if used[flag.Name] {
+				continue
+			}
+			text := "--" + flag.Name
+			if flag.Value != "" {
+				text += "="
+			}
+			s = append(s, prompt.Suggest{Text: text, Description: flag.Value})
+		}
+	}
+	if s == nil {
+		return []prompt.Suggest{}
+	}
+	return prompt.FilterHasPrefix(s, word, false)
+}
+
+// argSuggestions completes the positional argument after the given ones.
+// For commands taking [<cf>], a first argument naming a column family
+// selects the column family whose keys and values are suggested; in the
+// first position column families are suggested next to the first argument.
+func argSuggestions(rdb db.KeyValueDB, state *command.ReplState, spec command.Spec, given []string, word string) []prompt.Suggest {
+	cf := state.CurrentCF
+	var s []prompt.Suggest
+	if spec.OptionalCF {
+		if len(given) == 0 {
+			if r, err := completion.ColumnFamilies(rdb, word); err == nil {
+				for _, value := range r.Values {
+					s = append(s, prompt.Suggest{Text: value, Description: "column family"})
+				}
+			}
+		} else if len(given) <= len(spec.Args) && isColumnFamily(rdb, given[0]) {
+			cf = given[0]
+			given = given[1:]
+		}
+	}
+
+	if len(given) >= len(spec.Args) {
+		if s == nil {
+			return []prompt.Suggest{}
+		}
+		return s
+	}
+
+	var r completion.Result
+	var err error
+	switch spec.Args[len(given)] {
+	case command.ArgCF:
+		r, err = completion.ColumnFamilies(rdb, word)
+	case command.ArgKey:
+		r, err = completion.KeyPrefixes(rdb, cf, word)
+	case command.ArgJSONField:
+		r, err = completion.JSONFields(rdb, cf, word)
+	case command.ArgJSONPath:
+		key := ""
+		if len(given) > 0 {
+			key = given[len(given)-1]
+		}
+		r, err = completion.JSONPaths(rdb, cf, key, word)
+	case command.ArgQuerySubcommand:
+		r = filterPrefix(command.QuerySubcommands, word)
+	case command.ArgQueryName:
+		if given[0] == "run" || given[0] == "delete" {
+			r = filterPrefix(command.QueryNames(state), word)
+		}
+	case command.ArgSchemaSubcommand:
+		r = filterPrefix(command.SchemaSubcommands, word)
+	case command.ArgSetting:
+		var settings []string
+		for name := range command.Settings {
+			settings = append(settings, name)
+		}
+		sort.Strings(settings)
+		r = filterPrefix(settings, word)
+	case command.ArgSettingValue:
+		r = filterPrefix(command.Settings[given[0]], word)
+	case command.ArgOnErrorMode:
+		r = filterPrefix(command.OnErrorModes, word)
+	}
+	if err == nil {
+		for _, value := range r.Values {
+			s = append(s, prompt.Suggest{Text: value})
+		}
+	}
+	if s == nil {
+		return []prompt.Suggest{}
+	}
+	return s
+}
+
+// filterPrefix returns the values starting with prefix
+func filterPrefix(values []string, prefix string) completion.Result {
+	var r completion.Result
+	for _, value := range values {
+		if strings.HasPrefix(value, prefix) {
+			r.Values = append(r.Values, value)
+		}
+	}
+	return r
+}
+
+// isColumnFamily reports whether name is an existing column family
+func isColumnFamily(rdb db.KeyValueDB, name string) bool {
+	cfs, err := rdb.ListCFs()
+	if err != nil {
+		return false
+	}
+	for _, cf := range cfs {
+		if cf == name {
+			return true
+		}
+	}
+	return false
+}
+
+// toolSuggestions completes the subcommands of the tools command, namespaces,
+// tool names and the argument names and enum values of a tool's input schema
+func toolSuggestions(registry *tools.Registry, args []string, word string) []prompt.Suggest {
+	var s []prompt.Suggest
+	switch {
+	case len(args) == 2:
+		for _, sub := range command.ToolsSubcommands {
+			s = append(s, prompt.Suggest{Text: sub})
+		}
+	case registry == nil:
+	case len(args) == 3 && args[1] == "list":
+		for _, namespace := range command.ToolNamespaces(registry) {
+			s = append(s, prompt.Suggest{Text: namespace})
+		}
+	case len(args) == 3 && (args[1] == "describe" || args[1] == "call"):
+		for _, tool := range registry.ListTools("") {
+			s = append(s, prompt.Suggest{Text: tool.Name, Description: tool.Description})
+		}
+	case len(args) > 3 && args[1] == "call":
+		tool := registry.GetTool(args[2])
+		if tool == nil {
+			tool = registry.GetTool("local." + args[2])
+		}
+		if tool == nil {
+			return []prompt.Suggest{}
+		}
+		s = argumentSuggestions(*tool, args[3:len(args)-1], word)
+	}
+	if s == nil {
+		return []prompt.Suggest{}
+	}
+	return prompt.FilterHasPrefix(s, word, false)
+}
+
+// argumentSuggestions suggests name= for the arguments not given yet and,
+// once the name is typed, name=value for enum and boolean arguments
+func argumentSuggestions(tool protocol.Tool, given []string, word string) []prompt.Suggest {
+	used := make(map[string]bool)
+	for _, pair := range given {
+		name, _, _ := strings.Cut(pair, "=")
+		used[name] = true
+	}
+
+	var s []prompt.Suggest
+	for _, arg := range tools.Arguments(tool) {
+		if name, _, typed := strings.Cut(word, "="); typed {
+			if name != arg.Name {
+				continue
+			}
+			values := arg.Enum
+			if len(values) == 0 && arg.Type == "boolean" {
+				values = []interface{}{true, false}
+			}
+			for _, v := range values {
+				s = append(s, prompt.Suggest{Text: fmt.Sprintf("%s=%v", arg.Name, v)})
+			}
+			continue
+		}
+		if used[arg.Name] {
+			continue
+		}
+		description := arg.Description
+		if arg.Required {
+			description = strings.TrimSpace("(required) " + description)
+		}
+		s = append(s, prompt.Suggest{Text: arg.Name + "=", Description: description})
+	}
+	return s
+}
@@ -0,0 +1,186 @@
+package repl
+
+import (
+	"strings"
+	"testing"
+
+	"rocksdb-cli/internal/command"
+	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/mcp/protocol"
+	"rocksdb-cli/internal/mcp/tools"
+	"rocksdb-cli/internal/util"
+
+	prompt "github.com/c-bata/go-prompt"
+)
+
+// completionDB serves the reads completion uses
+type completionDB struct {
+	db.KeyValueDB
+	data map[string]map[string]string // cf -> key -> value
+}
+
+func newCompletionDB() *completionDB {
+	return &completionDB{data: map[string]map[string]string{
+		"default": {"user:1": `{"name":"Alice","profile":{"email":"a@example.com"}}`, "user:2": `{"name":"Bob"}`},
+		"orders":  {"order:1": `{"total":10}`, "order:2": `{"total":20,"items":[{"sku":"a"}]}`},
+	}}
+}
+
+func (c *completionDB) ListCFs() ([]string, error) {
+	return []string{"default", "orders"}, nil
+}
+
+func (c *completionDB) GetKeyFormatInfo(cf string) (util.KeyFormat, string) {
+	return util.KeyFormatString, ""
+}
+
+func (c *completionDB) PrefixScanCF(cf, prefix string, limit int) (map[string]string, error) {
+	values, ok := c.data[cf]
+	if !ok {
+		return nil, db.ErrColumnFamilyNotFound
+	}
+	result := make(map[string]string)
+	for key, value := range values {
+		if strings.HasPrefix(key, prefix) {
+			result[key] = value
+		}
+	}
+	return result, nil
+}
+
+func (c *completionDB) ScanCF(cf string, start, end []byte, opts db.ScanOptions) (map[string]string, error) {
+	return c.PrefixScanCF(cf, "", opts.Limit)
+}
+
+func (c *completionDB) SmartGetCF(cf, key string) (string, error) {
+	value, ok := c.data[cf][key]
+	if !ok {
+		return "", db.ErrKeyNotFound
+	}
+	return value, nil
+}
+
+// splitInput splits the text before the cursor like newCompleter
+func splitInput(input string) ([]string, string) {
+	args := strings.Fields(input)
+	if input == "" || strings.HasSuffix(input, " ") {
+		return append(args, ""), ""
+	}
+	return args, args[len(args)-1]
+}
+
+func texts(s []prompt.Suggest) string {
+	var out []string
+	for _, suggest := range s {
+		out = append(out, suggest.Text)
+	}
+	return strings.Join(out, " ")
+}
+
+// TestSuggest tests completion of commands, flags and arguments
+func TestSuggest(t *testing.T) {
+	rdb := newCompletionDB()
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		// Commands
+		{"us", "usecf"},
+		{"js", "jsonpath jsonquery"},
+		{"unknown ", ""},
+		// Column families
+		{"usecf ", "default orders"},
+		{"dropcf o", "orders"},
+		// Keys of the current column family, next to column families for [<cf>]
+		{"get ", "default orders user:"},
+		{"get user:", "user:1 user:2"},
+		{"put user:1 ", ""},
+		// Keys of a named column family
+		{"get orders ", "order:"},
+		{"scan orders order:1 ", "order:"},
+		{"stats ", "default orders"},
+		{"stats orders ", ""},
+		// Flags
+		{"scan --l", "--limit="},
+		{"get --", "--pretty --smart= --output="},
+		{"get --pretty --", "--smart= --output="},
+		{"get --output=j", "--output=json --output=jsonl"},
+		{"get --smart=", "--smart=true --smart=false"},
+		{"createcf new --profile=point", "--profile=point-lookup"},
+		{"usecf --", ""},
+		// JSON fields and paths
+		{"jsonquery ", "default orders name profile"},
+		{"jsonquery orders ", "items total"},
+		{"jpath user:1 $.", "$.name $.profile"},
+		{"jpath user:1 $.profile", "$.profile $.profile.email"},
+		{"jpath orders order:2 $.items", "$.items $.items[*] $.items[0]"},
+		// Named queries and settings
+		{"query ", "save run list delete"},
+		{"query run ", "admins orders"},
+		{"query delete o", "orders"},
+		{"query save ", ""},
+		{"set ", "output pretty"},
+		{"set pretty ", "on off"},
+		{"set output y", "yaml"},
+		{"on-error ", "stop continue"},
+	}
+
+	state := &command.ReplState{
+		CurrentCF: "default",
+		Queries:   map[string]string{"admins": "search --value=admin", "orders": "scan orders"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			args, word := splitInput(tt.input)
+			if got := texts(suggest(rdb, state, nil, args, word)); got != tt.want {
+				t.Errorf("suggest(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestToolSuggestions tests completion of the tools command
+func TestToolSuggestions(t *testing.T) {
+	registry := tools.NewRegistry()
+	registry.RegisterLocal(protocol.Tool{
+		Name: "scan",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"cf":      map[string]interface{}{"type": "string", "description": "Column family"},
+				"order":   map[string]interface{}{"type": "string", "enum": []string{"asc", "desc"}},
+				"reverse": map[string]interface{}{"type": "boolean"},
+			},
+			"required": []string{"cf"},
+		},
+	}, nil)
+	registry.RegisterRemote("fs", []protocol.Tool{{Name: "read_file"}})
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"tools ", "list describe call"},
+		{"tools d", "describe"},
+		{"tools list ", "fs local"},
+		{"tools call lo", "local.scan"},
+		{"tools describe fs.", "fs.read_file"},
+		{"tools call local.scan ", "cf= order= reverse="},
+		{"tools call scan cf=users ", "order= reverse="},
+		{"tools call local.scan order=", "order=asc order=desc"},
+		{"tools call local.scan reverse=t", "reverse=true"},
+		{"tools call fs.read_file ", ""},
+		{"tools call missing ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			args, word := splitInput(tt.input)
+			if got := texts(toolSuggestions(registry, args, word)); got != tt.want {
+				t.Errorf("toolSuggestions(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -13,8 +13,11 @@ import (
 	"os/exec"
 	"rocksdb-cli/internal/command"
 	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/mcp/tools"
+	"rocksdb-cli/internal/output"
 	"rocksdb-cli/internal/util"
 	"runtime"
+	"strings"
 	"sync"
 
 	prompt "github.com/c-bata/go-prompt"
@@ -26,12 +29,33 @@ var (
 	exitMutex sync.Mutex
 )
 
-func Start(rdb db.KeyValueDB) {
+// Options configures optional REPL features
+type Options struct {
+	// Tools offered by the tools command. The local tools over the database
+	// are registered in it; a new registry is used when nil.
+	Tools *tools.Registry
+	// RemoteTools executes the remote tools of Tools, nil without MCP clients
+	RemoteTools *tools.RemoteProxy
+	// DBPath identifies the database whose history and session are restored
+	// and saved. Sessions are disabled when empty.
+	DBPath string
+	// SessionDir stores the sessions, DefaultSessionDir when empty
+	SessionDir string
+	// Output is the default output format of commands, overriding the
+	// session's setting when not empty
+	Output output.Format
+}
+
+func Start(rdb db.KeyValueDB, opts Options) {
 	// Enable color highlighting for interactive mode
 	util.EnableColor()
 
 	state := &command.ReplState{CurrentCF: "default"}
-	handler := &command.Handler{DB: rdb, State: state}
+	handler := newHandler(rdb, state, &opts)
+	session := restoreSession(rdb, state, opts)
+	if opts.Output != "" {
+		state.SetOutput(opts.Output)
+	}
 
 	if rdb.IsReadOnly() {
 		fmt.Println("Welcome to rocksdb-cli with column family support (READ-ONLY MODE).")
@@ -57,13 +81,41 @@ func Start(rdb db.KeyValueDB) {
 		os.Exit(0)
 	}
 
+	search := &reverseSearch{}
 	p := prompt.New(
 		func(in string) {
-			if !handler.Execute(in) {
+			line := strings.TrimSpace(in)
+			expanded, err := state.ExpandHistory(line)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			if expanded != line {
+				fmt.Println(expanded)
+			}
+
+			count := len(state.History)
+			state.AddHistory(expanded)
+			keep := handler.Execute(expanded)
+
+			// Save after every command, exit does not return
+			if session != nil {
+				if len(state.History) > count {
+					err = session.AppendHistory(expanded)
+				}
+				if err == nil {
+					err = session.Save(state)
+				}
+				if err != nil {
+					fmt.Printf("Warning: session will not be saved: %v\n", err)
+					session = nil
+				}
+			}
+			if !keep {
 				exitHandler()
 			}
 		},
-		completer,
+		newCompleter(rdb, state, opts.Tools),
 		prompt.OptionLivePrefix(func() (string, bool) {
 			readOnlyFlag := ""
 			if rdb.IsReadOnly() {
@@ -71,12 +123,21 @@ func Start(rdb db.KeyValueDB) {
 			}
 			return fmt.Sprintf("rocksdb%s[%s]> ", readOnlyFlag, state.CurrentCF), true
 		}),
+		prompt.OptionHistory(state.History),
 		prompt.OptionAddKeyBind(prompt.KeyBind{
 			Key: prompt.ControlC,
 			Fn: func(buf *prompt.Buffer) {
 				exitHandler()
 			},
 		}),
+		prompt.OptionAddKeyBind(prompt.KeyBind{
+			Key: prompt.ControlR,
+			Fn: func(buf *prompt.Buffer) {
+				if match, ok := search.next(buf.Text(), state.History); ok {
+					replaceBuffer(buf, match)
+				}
+			},
+		}),
 	)
 
 	// Ensure terminal is always restored on exit
@@ -98,6 +159,85 @@ func Start(rdb db.KeyValueDB) {
 	p.Run()
 }
 
+// newHandler creates the command handler of a session, registering the
+// local tools over rdb in opts.Tools
+func newHandler(rdb db.KeyValueDB, state *command.ReplState, opts *Options) *command.Handler {
+	if opts.Tools == nil {
+		opts.Tools = tools.NewRegistry()
+	}
+	if err := tools.NewLocalAdapter(opts.Tools, rdb).RegisterAll(); err != nil {
+		fmt.Printf("Warning: failed to register local tools: %v\n", err)
+	}
+	return &command.Handler{DB: rdb, State: state, Tools: opts.Tools, RemoteTools: opts.RemoteTools}
+}
+
+// restoreSession loads the history and saved state of the database's session
+// into state. It returns nil when sessions are disabled or cannot be used.
+func restoreSession(rdb db.KeyValueDB, state *command.ReplState, opts Options) *Session {
+	if opts.DBPath == "" {
+		return nil
+	}
+	dir := opts.SessionDir
+	if dir == "" {
+		var err error
+		if dir, err = DefaultSessionDir(); err != nil {
+			fmt.Printf("Warning: sessions disabled: %v\n", err)
+			return nil
+		}
+	}
+	session, err := OpenSession(dir, opts.DBPath)
+	if err != nil {
+		fmt.Printf("Warning: sessions disabled: %v\n", err)
+		return nil
+	}
+
+	if state.History, err = session.LoadHistory(); err != nil {
+		fmt.Printf("Warning: history not restored: %v\n", err)
+	}
+	if err := session.Load(state); err != nil {
+		fmt.Printf("Warning: session not restored: %v\n", err)
+	}
+	if state.CurrentCF != "default" && !isColumnFamily(rdb, state.CurrentCF) {
+		fmt.Printf("Column family '%s' of the last session no longer exists, using 'default'\n", state.CurrentCF)
+		state.CurrentCF = "default"
+	}
+	return session
+}
+
+// reverseSearch implements Ctrl-R: the first press searches the history for
+// the most recent command containing the input, each further press for an
+// older one. Editing the found command starts a new search.
+type reverseSearch struct {
+	query string
+	index int    // index in the history of match
+	match string // the last command found
+}
+
+// next returns the next older command containing the query, or false when
+// there is none
+func (r *reverseSearch) next(text string, history []string) (string, bool) {
+	if text != r.match || r.index > len(history) {
+		r.query = text
+		r.index = len(history)
+	}
+	for i := r.index - 1; i >= 0; i-- {
+		if history[i] != text && strings.Contains(history[i], r.query) {
+			r.index = i
+			r.match = history[i]
+			return r.match, true
+		}
+	}
+	return "", false
+}
+
+// replaceBuffer replaces the input with text, leaving the cursor at its end
+func replaceBuffer(buf *prompt.Buffer, text string) {
+	d := buf.Document()
+	buf.DeleteBeforeCursor(len([]rune(d.TextBeforeCursor())))
+	buf.Delete(len([]rune(d.TextAfterCursor())))
+	buf.InsertText(text, false, true)
+}
+
 // isWSL checks if we're running in Windows Subsystem for Linux
 func isWSL() bool {
 	return os.Getenv("WSL_DISTRO_NAME") != "" || os.Getenv("WSLENV") != ""
@@ -133,8 +273,3 @@ func restoreTerminal() {
 	fmt.Print("\033[?25h") // Show cursor
 	fmt.Print("\033[0m")   // Reset attributes
 }
-
-func completer(d prompt.Document) []prompt.Suggest {
-	s := []prompt.Suggest{}
-	return prompt.FilterHasPrefix(s, d.GetWordBeforeCursor(), true)
-}
@@ -0,0 +1,31 @@
+package repl
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"rocksdb-cli/internal/command"
+	"rocksdb-cli/internal/db"
+)
+
+// RunScript runs the REPL commands read from r without a prompt, colors or
+// session, and returns the exit code for the process: 0 when every command
+// succeeded, 1 when a command failed or the script could not be read.
+// name identifies the script in failure messages, which go to stderr.
+func RunScript(rdb db.KeyValueDB, opts Options, r io.Reader, name string) int {
+	state := &command.ReplState{CurrentCF: "default"}
+	state.SetOutput(opts.Output)
+	handler := newHandler(rdb, state, &opts)
+
+	result, err := handler.RunScript(r, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if result.Failed > 0 {
+		fmt.Fprintf(os.Stderr, "%s: %d of %d command(s) failed\n", name, result.Failed, result.Commands)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,25 @@
+package repl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunScriptExitCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   int
+	}{
+		{"success", "listcf\nusecf orders\n", 0},
+		{"failure", "usecf\nlistcf\n", 1},
+		{"exit after failure with on-error continue", "on-error continue\nbogus\nexit\n", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RunScript(newCompletionDB(), Options{}, strings.NewReader(tt.script), "test.rdb"); got != tt.want {
+				t.Errorf("RunScript exit code = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
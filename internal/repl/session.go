@@ -0,0 +1,144 @@
+package repl
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"rocksdb-cli/internal/command"
+)
+
+// MaxHistory is the number of commands kept in a history file
+const MaxHistory = 1000
+
+// Session stores the history and state of the REPL sessions of one database
+// as <name>.history, one command per line, and <name>.json, where name is
+// derived from the database path
+type Session struct {
+	historyFile string
+	stateFile   string
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// DefaultSessionDir returns the directory sessions are stored in by default
+func DefaultSessionDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "rocksdb-cli", "sessions"), nil
+}
+
+// OpenSession returns the session of the database at dbPath stored in dir,
+// creating dir if needed. Databases with the same base name in different
+// directories get different sessions.
+func OpenSession(dir, dbPath string) (*Session, error) {
+	abs, err := filepath.Abs(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve database path: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	sum := sha1.Sum([]byte(abs))
+	base := strings.Trim(unsafeFileChars.ReplaceAllString(filepath.Base(abs), "_"), "_")
+	if base == "" {
+		base = "db"
+	}
+	name := base + "-" + hex.EncodeToString(sum[:])[:8]
+
+	return &Session{
+		historyFile: filepath.Join(dir, name+".history"),
+		stateFile:   filepath.Join(dir, name+".json"),
+	}, nil
+}
+
+// LoadHistory returns the saved commands, oldest first. A history file
+// longer than MaxHistory is trimmed to the most recent commands.
+func (s *Session) LoadHistory() ([]string, error) {
+	f, err := os.Open(s.historyFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	var history []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			history = append(history, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	if len(history) > MaxHistory {
+		history = history[len(history)-MaxHistory:]
+		data := strings.Join(history, "\n") + "\n"
+		if err := os.WriteFile(s.historyFile, []byte(data), 0o600); err != nil {
+			return nil, fmt.Errorf("failed to trim history: %w", err)
+		}
+	}
+	return history, nil
+}
+
+// AppendHistory adds a command to the history file
+func (s *Session) AppendHistory(line string) error {
+	f, err := os.OpenFile(s.historyFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// Load restores the saved column family, named queries and output settings
+// into state. A missing state file leaves state unchanged.
+func (s *Session) Load(state *command.ReplState) error {
+	data, err := os.ReadFile(s.stateFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read session: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return fmt.Errorf("failed to parse session %s: %w", s.stateFile, err)
+	}
+	return nil
+}
+
+// Save writes the column family, named queries and output settings of state
+func (s *Session) Save(state *command.ReplState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	// Write a temporary file first so an interrupted save keeps the old session
+	tmp := s.stateFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	if err := os.Rename(tmp, s.stateFile); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package repl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"rocksdb-cli/internal/command"
+)
+
+func TestOpenSession(t *testing.T) {
+	dir := t.TempDir()
+
+	a, err := OpenSession(dir, "/data/one/my db")
+	if err != nil {
+		t.Fatalf("OpenSession: %v", err)
+	}
+	b, _ := OpenSession(dir, "/data/two/my db")
+	again, _ := OpenSession(dir, "/data/one/my db/")
+
+	if a.historyFile == b.historyFile {
+		t.Error("databases with the same base name share a session")
+	}
+	if a.historyFile != again.historyFile {
+		t.Errorf("same database, different sessions: %s and %s", a.historyFile, again.historyFile)
+	}
+	if name := filepath.Base(a.historyFile); !strings.HasPrefix(name, "my_db-") || filepath.Ext(name) != ".history" {
+		t.Errorf("unexpected history file name %s", name)
+	}
+}
+
+func TestSessionHistory(t *testing.T) {
+	session, err := OpenSession(t.TempDir(), "/data/db")
+	if err != nil {
+		t.Fatalf("OpenSession: %v", err)
+	}
+
+	history, err := session.LoadHistory()
+	if err != nil || history != nil {
+		t.Fatalf("LoadHistory of a new session = %q, %v", history, err)
+	}
+
+	for _, line := range []string{"usecf users", "get user:1"} {
+		if err := session.AppendHistory(line); err != nil {
+			t.Fatalf("AppendHistory: %v", err)
+		}
+	}
+	history, err = session.LoadHistory()
+	if err != nil {
+		t.Fatalf("LoadHistory: %v", err)
+	}
+	if want := []string{"usecf users", "get user:1"}; !reflect.DeepEqual(history, want) {
+		t.Errorf("LoadHistory = %q, want %q", history, want)
+	}
+}
+
+func TestSessionHistoryTrimmed(t *testing.T) {
+	session, _ := OpenSession(t.TempDir(), "/data/db")
+	for i := 0; i < MaxHistory+10; i++ {
+		session.AppendHistory(fmt.Sprintf("get key%d", i))
+	}
+
+	history, err := session.LoadHistory()
+	if err != nil {
+		t.Fatalf("LoadHistory: %v", err)
+	}
+	if len(history) != MaxHistory || history[0] != "get key10" {
+		t.Fatalf("LoadHistory returned %d commands starting with %q", len(history), history[0])
+	}
+
+	data, _ := os.ReadFile(session.historyFile)
+	if lines := strings.Count(string(data), "\n"); lines != MaxHistory {
+		t.Errorf("history file has %d lines after trimming, want %d", lines, MaxHistory)
+	}
+}
+
+func TestSessionState(t *testing.T) {
+	session, _ := OpenSession(t.TempDir(), "/data/db")
+
+	state := &command.ReplState{CurrentCF: "default"}
+	if err := session.Load(state); err != nil || state.CurrentCF != "default" {
+		t.Fatalf("Load of a new session changed state: %+v, %v", state, err)
+	}
+
+	saved := &command.ReplState{
+		CurrentCF: "users",
+		Queries:   map[string]string{"admins": "search --value=admin"},
+		Pretty:    true,
+		Output:    "jsonl",
+		History:   []string{"not saved"},
+	}
+	if err := session.Save(saved); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	restored := &command.ReplState{CurrentCF: "default"}
+	if err := session.Load(restored); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := &command.ReplState{CurrentCF: "users", Queries: saved.Queries, Pretty: true, Output: "jsonl"}
+	if !reflect.DeepEqual(restored, want) {
+		t.Errorf("Load = %+v, want %+v", restored, want)
+	}
+}
+
+func TestRestoreSession(t *testing.T) {
+	dir := t.TempDir()
+	rdb := newCompletionDB()
+
+	session, _ := OpenSession(dir, "/data/db")
+	session.AppendHistory("usecf gone")
+	session.Save(&command.ReplState{CurrentCF: "gone", Pretty: true})
+
+	state := &command.ReplState{CurrentCF: "default"}
+	if restoreSession(rdb, state, Options{SessionDir: dir}) != nil {
+		t.Error("sessions should be disabled without a database path")
+	}
+
+	if restoreSession(rdb, state, Options{DBPath: "/data/db", SessionDir: dir}) == nil {
+		t.Fatal("restoreSession returned nil")
+	}
+	if state.CurrentCF != "default" {
+		t.Errorf("CurrentCF = %q, want the default for a dropped column family", state.CurrentCF)
+	}
+	if !state.Pretty || !reflect.DeepEqual(state.History, []string{"usecf gone"}) {
+		t.Errorf("state not restored: %+v", state)
+	}
+}
+
+func TestReverseSearch(t *testing.T) {
+	history := []string{"get user:1", "scan a b", "get user:2", "put x y"}
+	search := &reverseSearch{}
+
+	steps := []struct {
+		text   string
+		want   string
+		wantOK bool
+	}{
+		{"get", "get user:2", true},
+		{"get user:2", "get user:1", true},
+		{"get user:1", "", false},
+		// Editing the match starts a new search
+		{"sca", "scan a b", true},
+		{"", "put x y", true},
+	}
+	for _, step := range steps {
+		got, ok := search.next(step.text, history)
+		if got != step.want || ok != step.wantOK {
+			t.Fatalf("next(%q) = %q, %v, want %q, %v", step.text, got, ok, step.want, step.wantOK)
+		}
+	}
+}
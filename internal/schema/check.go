@@ -0,0 +1,236 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/keytree"
+	"rocksdb-cli/internal/util"
+)
+
+// DefaultLimit is the number of violations a report lists without a limit;
+// all of them are counted
+const DefaultLimit = 100
+
+// batchSize is the number of values read per scan
+const batchSize = 1000
+
+// Scanner reads the values of a column family; db.KeyValueDB is one
+type Scanner interface {
+	ScanCFPage(cf string, start, end []byte, opts db.ScanOptions) (db.ScanPageResult, error)
+}
+
+// optionsReader reports the options of a column family; db.KeyValueDB is one
+type optionsReader interface {
+	GetCFOptions(cf string) (*db.CFOptions, error)
+}
+
+// Violation is a way a document does not match a schema
+type Violation struct {
+	Key     string `json:"key"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// NewField is a field of the documents that the schema does not know.
+// Only the outermost new field of a path is reported.
+type NewField struct {
+	Path       string `json:"path"`
+	Count      int64  `json:"count"` // documents having it
+	Type       Types  `json:"type"`  // types seen
+	ExampleKey string `json:"example_key"`
+}
+
+// Report is the result of checking documents against a schema
+type Report struct {
+	Checked        int64       `json:"checked"`
+	Violating      int64       `json:"violating"` // documents with at least one violation
+	ViolationCount int64       `json:"violation_count"`
+	Violations     []Violation `json:"violations"` // the first violations, up to the limit
+	NewFields      []NewField  `json:"new_fields"`
+	HasMore        bool        `json:"has_more,omitempty"` // True if the check was stopped before the end
+}
+
+// Checker checks documents against a schema and collects a report
+type Checker struct {
+	schema    *Schema
+	limit     int
+	report    Report
+	newFields map[string]*NewField
+}
+
+// NewChecker creates a Checker listing up to limit violations, or
+// DefaultLimit when limit is not positive
+func NewChecker(s *Schema, limit int) *Checker {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	return &Checker{schema: s, limit: limit, newFields: make(map[string]*NewField)}
+}
+
+// document is the check of one document
+type document struct {
+	key        string
+	violations int
+	newFields  map[string]bool
+}
+
+// Check checks the value of key
+func (c *Checker) Check(key, value string) {
+	c.report.Checked++
+	doc := &document{key: display(key), newFields: make(map[string]bool)}
+	v, err := decode(value)
+	if err != nil {
+		c.violation(doc, "$", "not a JSON document: "+err.Error())
+	} else {
+		c.validate(doc, c.schema, v, "$")
+	}
+	if doc.violations > 0 {
+		c.report.Violating++
+	}
+}
+
+// CheckCF checks the values of cf under prefix, in key order. progress, if
+// not nil, is called after each batch of values; an error stops the check,
+// whose report then covers the values checked so far with HasMore set.
+func CheckCF(s Scanner, cf, prefix string, c *Checker, progress db.ProgressFunc) error {
+	if err := checkComparator(s, cf); err != nil {
+		return err
+	}
+	var start, end []byte
+	if prefix != "" {
+		start, end = []byte(prefix), keytree.PrefixEnd([]byte(prefix))
+	}
+	for {
+		page, err := s.ScanCFPage(cf, start, end, db.ScanOptions{Limit: batchSize, Values: true})
+		if err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(page.Results))
+		for key := range page.Results {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			c.Check(key, page.Results[key])
+		}
+		if !page.HasMore || len(keys) == 0 {
+			return nil
+		}
+		last := keys[len(keys)-1]
+		if progress != nil && progress(c.report.Checked, last) != nil {
+			c.report.HasMore = true
+			return nil
+		}
+		// Moving the start past the last key works with any cursor format
+		start = append([]byte(last), 0)
+	}
+}
+
+// checkComparator returns db.ErrNotBytewise if the scanner reports a
+// comparator other than the bytewise one for cf: the key range of a prefix
+// and the batches after the last key read assume bytewise key order
+func checkComparator(s Scanner, cf string) error {
+	r, ok := s.(optionsReader)
+	if !ok {
+		return nil
+	}
+	// Unknown column families fail in the scan
+	opts, _ := r.GetCFOptions(cf)
+	return db.RequireBytewise(opts, cf)
+}
+
+// Report returns the report of the documents checked so far, new fields
+// by path
+func (c *Checker) Report() *Report {
+	r := c.report
+	if r.Violations == nil {
+		r.Violations = []Violation{}
+	}
+	r.NewFields = make([]NewField, 0, len(c.newFields))
+	for _, f := range c.newFields {
+		r.NewFields = append(r.NewFields, *f)
+	}
+	sort.Slice(r.NewFields, func(i, j int) bool { return r.NewFields[i].Path < r.NewFields[j].Path })
+	return &r
+}
+
+func (c *Checker) validate(doc *document, s *Schema, v any, path string) {
+	t := typeOf(v)
+	if len(s.Type) > 0 && !s.Type.Has(t) {
+		c.violation(doc, path, fmt.Sprintf("is %s, want %s", t, s.Type))
+		return
+	}
+	if str, ok := v.(string); ok && len(s.Enum) > 0 && !inEnum(s.Enum, str) {
+		c.violation(doc, path, fmt.Sprintf("%s is not one of %s", strconv.Quote(shorten(str)), FormatValues(s.Enum)))
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				c.violation(doc, path+"."+name, "required field is missing")
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := v[name]
+			if ps, ok := s.Properties[name]; ok {
+				c.validate(doc, ps, child, path+"."+name)
+			} else {
+				c.newField(doc, path+"."+name, typeOf(child))
+			}
+		}
+	case []any:
+		if s.Items != nil {
+			for _, item := range v {
+				c.validate(doc, s.Items, item, path+"[]")
+			}
+		}
+	}
+}
+
+func (c *Checker) violation(doc *document, path, message string) {
+	doc.violations++
+	c.report.ViolationCount++
+	if len(c.report.Violations) < c.limit {
+		c.report.Violations = append(c.report.Violations, Violation{Key: doc.key, Path: path, Message: message})
+	}
+}
+
+// newField counts a field the schema does not know, once per document
+func (c *Checker) newField(doc *document, path, t string) {
+	f, ok := c.newFields[path]
+	if !ok {
+		f = &NewField{Path: path, ExampleKey: doc.key}
+		c.newFields[path] = f
+	}
+	if !f.Type.Has(t) {
+		f.Type = append(f.Type, t)
+	}
+	if !doc.newFields[path] {
+		doc.newFields[path] = true
+		f.Count++
+	}
+}
+
+func inEnum(enum []any, s string) bool {
+	for _, e := range enum {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// display shows a key in a report, binary ones as hex
+func display(key string) string {
+	encoded, _ := util.EncodeValue([]byte(key))
+	return encoded
+}
@@ -0,0 +1,240 @@
+package schema
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"unicode/utf8"
+
+	"rocksdb-cli/internal/db"
+)
+
+const (
+	// DefaultSamples is the number of values sampled by InferCF without a
+	// sample size
+	DefaultSamples = 1000
+	// maxEnum is the most distinct strings a field can have to become an
+	// enum candidate
+	maxEnum = 10
+	// maxExamples is the number of example values kept per path
+	maxExamples = 3
+	// maxExampleLength is the length strings are cut to in examples
+	maxExampleLength = 60
+)
+
+// ErrNoJSONValues is returned by InferCF when no value under the prefix is a
+// JSON document
+var ErrNoJSONValues = errors.New("no JSON values to infer a schema from")
+
+// Sampler samples the JSON values of a column family; db.KeyValueDB is one
+type Sampler interface {
+	GetCFStatsWithOptions(cf string, opts db.StatsOptions) (*db.CFStats, error)
+}
+
+// InferCF infers the schema of the JSON values of cf under prefix from a
+// uniform sample of n of them, sampled by the column family statistics.
+// progress, if not nil, is called as keys are visited; an error stops the
+// sampling, and the schema is inferred from the values sampled so far with
+// HasMore set in the returned statistics.
+func InferCF(s Sampler, cf, prefix string, n int, progress db.ProgressFunc) (*Schema, *db.CFStats, error) {
+	if n <= 0 {
+		n = DefaultSamples
+	}
+	stats, err := s.GetCFStatsWithOptions(cf, db.StatsOptions{Prefix: prefix, JSONSamples: n, Progress: progress})
+	if err != nil {
+		return nil, nil, err
+	}
+	in := NewInferrer()
+	for _, value := range stats.JSONSamples {
+		// Values that are not JSON documents were not sampled
+		_ = in.Add(value)
+	}
+	if in.count == 0 {
+		return nil, stats, ErrNoJSONValues
+	}
+	schema := in.Schema()
+	schema.Title = cf
+	if prefix != "" {
+		schema.Title += " " + prefix
+	}
+	schema.Source = &Source{
+		ColumnFamily: cf,
+		Prefix:       prefix,
+		Keys:         stats.KeyCount,
+		JSONValues:   stats.DataTypeDistribution[db.DataTypeJSON],
+		Sampled:      in.count,
+		InferredAt:   stats.LastUpdated,
+	}
+	return schema, stats, nil
+}
+
+// Inferrer merges JSON documents into one schema
+type Inferrer struct {
+	root  *node
+	count int64
+}
+
+// node accumulates the values seen at a path
+type node struct {
+	count    int64
+	types    map[string]int64
+	objects  int64 // object values, which the presence of props is a share of
+	props    map[string]*node
+	items    *node
+	strings  map[string]int64 // distinct strings, nil once more than maxEnum
+	examples []any
+}
+
+func newNode() *node {
+	return &node{types: make(map[string]int64), strings: make(map[string]int64)}
+}
+
+// NewInferrer creates an Inferrer without documents
+func NewInferrer() *Inferrer {
+	return &Inferrer{root: newNode()}
+}
+
+// Add merges a JSON document into the schema
+func (in *Inferrer) Add(value string) error {
+	v, err := decode(value)
+	if err != nil {
+		return err
+	}
+	in.root.add(v)
+	in.count++
+	return nil
+}
+
+func (n *node) add(v any) {
+	n.count++
+	t := typeOf(v)
+	n.types[t]++
+	switch v := v.(type) {
+	case map[string]any:
+		n.objects++
+		if n.props == nil {
+			n.props = make(map[string]*node)
+		}
+		for name, child := range v {
+			p, ok := n.props[name]
+			if !ok {
+				p = newNode()
+				n.props[name] = p
+			}
+			p.add(child)
+		}
+	case []any:
+		if n.items == nil {
+			n.items = newNode()
+		}
+		for _, item := range v {
+			n.items.add(item)
+		}
+	case string:
+		if n.strings != nil {
+			n.strings[v]++
+			if len(n.strings) > maxEnum {
+				n.strings = nil
+			}
+		}
+		n.example(shorten(v))
+	default:
+		if v != nil {
+			n.example(v)
+		}
+	}
+}
+
+// example keeps up to maxExamples distinct example values
+func (n *node) example(v any) {
+	if len(n.examples) == maxExamples {
+		return
+	}
+	for _, e := range n.examples {
+		if e == v {
+			return
+		}
+	}
+	n.examples = append(n.examples, v)
+}
+
+// shorten cuts long strings for examples
+func shorten(s string) string {
+	if utf8.RuneCountInString(s) <= maxExampleLength {
+		return s
+	}
+	return string([]rune(s)[:maxExampleLength]) + "…"
+}
+
+// Schema returns the merged schema of the documents added so far
+func (in *Inferrer) Schema() *Schema {
+	s := in.root.schema()
+	s.Schema = Draft
+	return s
+}
+
+func (n *node) schema() *Schema {
+	s := &Schema{Count: n.count}
+	for _, t := range typeOrder {
+		if n.types[t] > 0 {
+			s.Type = append(s.Type, t)
+		}
+	}
+	// Integers are numbers, so a union of both is number
+	if n.types[TypeInteger] > 0 && n.types[TypeNumber] > 0 {
+		merged := s.Type[:0]
+		for _, t := range s.Type {
+			if t != TypeInteger {
+				merged = append(merged, t)
+			}
+		}
+		s.Type = merged
+	}
+	if len(n.types) > 1 {
+		s.TypePercent = make(map[string]float64, len(n.types))
+		for t, count := range n.types {
+			s.TypePercent[t] = percent(count, n.count)
+		}
+	}
+
+	if n.props != nil {
+		s.Properties = make(map[string]*Schema, len(n.props))
+		for name, p := range n.props {
+			ps := p.schema()
+			ps.Presence = percent(p.count, n.objects)
+			s.Properties[name] = ps
+			if p.count == n.objects {
+				s.Required = append(s.Required, name)
+			}
+		}
+		sort.Strings(s.Required)
+	}
+	if n.items != nil && n.items.count > 0 {
+		s.Items = n.items.schema()
+	}
+
+	// Strings repeating few values are enum candidates, with null when the
+	// field can be null
+	strs := n.types[TypeString]
+	onlyStrings := strs+n.types[TypeNull] == n.count
+	if n.strings != nil && strs > 0 && onlyStrings && int64(len(n.strings))*2 <= strs {
+		for v := range n.strings {
+			s.Enum = append(s.Enum, v)
+		}
+		sort.Slice(s.Enum, func(i, j int) bool { return s.Enum[i].(string) < s.Enum[j].(string) })
+		if n.types[TypeNull] > 0 {
+			s.Enum = append(s.Enum, nil)
+		}
+	} else {
+		s.Examples = n.examples
+	}
+	return s
+}
+
+// percent returns part of total as a percentage rounded to one decimal
+func percent(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)*1000/float64(total)) / 10
+}
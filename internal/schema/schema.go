@@ -0,0 +1,200 @@
+// Package schema infers JSON Schemas from JSON values and checks values
+// against them. An Inferrer merges sampled documents into one schema with
+// the share of the documents having each field, the union of the types seen
+// at each path, enum candidates and example values; a Checker reports the
+// documents that violate a schema and the fields it does not know.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema version of inferred schemas
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSON Schema type names
+const (
+	TypeNull    = "null"
+	TypeBoolean = "boolean"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeString  = "string"
+	TypeArray   = "array"
+	TypeObject  = "object"
+)
+
+// typeOrder sorts the types of a union
+var typeOrder = []string{TypeObject, TypeArray, TypeString, TypeInteger, TypeNumber, TypeBoolean, TypeNull}
+
+// Types is the type keyword of a schema: one type name or a union of them
+type Types []string
+
+// MarshalJSON writes a single type as a string, as JSON Schema does
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON reads a type name or a list of them
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = Types{name}
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return fmt.Errorf("type must be a string or a list of strings")
+	}
+	*t = names
+	return nil
+}
+
+// Has reports whether values of type name are allowed; integers are numbers
+func (t Types) Has(name string) bool {
+	for _, n := range t {
+		if n == name || (n == TypeNumber && name == TypeInteger) {
+			return true
+		}
+	}
+	return false
+}
+
+// String joins the types with |, as in "string|null"
+func (t Types) String() string {
+	return strings.Join(t, "|")
+}
+
+// Schema is a JSON Schema. The x- keywords, which validators ignore, keep
+// what inference saw: how many values each path had, the share of the
+// parent objects having a field and the share of each type of a union.
+type Schema struct {
+	Schema      string             `json:"$schema,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Type        Types              `json:"type,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Enum        []any              `json:"enum,omitempty"`
+	Examples    []any              `json:"examples,omitempty"`
+	Count       int64              `json:"x-count,omitempty"`
+	Presence    float64            `json:"x-presence,omitempty"`
+	TypePercent map[string]float64 `json:"x-type-percent,omitempty"`
+	Source      *Source            `json:"x-source,omitempty"`
+}
+
+// Source describes the values a schema was inferred from
+type Source struct {
+	ColumnFamily string    `json:"column_family"`
+	Prefix       string    `json:"prefix,omitempty"`
+	Keys         int64     `json:"keys"`
+	JSONValues   int64     `json:"json_values"`
+	Sampled      int64     `json:"sampled"`
+	InferredAt   time.Time `json:"inferred_at"`
+}
+
+// Parse reads a schema saved as JSON
+func Parse(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	if len(s.Type) == 0 && s.Properties == nil {
+		return nil, fmt.Errorf("invalid schema: no type or properties")
+	}
+	return &s, nil
+}
+
+// Field is a path of a schema, flattened for listings
+type Field struct {
+	Path     string  `json:"path"`
+	Type     Types   `json:"type"`
+	Presence float64 `json:"presence"` // percentage of the parent objects having the field
+	Required bool    `json:"required"`
+	Enum     []any   `json:"enum,omitempty"`
+	Examples []any   `json:"examples,omitempty"`
+}
+
+// Fields lists the paths of s depth first, fields in name order. The root
+// is $, fields are $.name and array items $.name[].
+func (s *Schema) Fields() []Field {
+	var fields []Field
+	var walk func(s *Schema, path string, presence float64, required bool)
+	walk = func(s *Schema, path string, presence float64, required bool) {
+		fields = append(fields, Field{Path: path, Type: s.Type, Presence: presence, Required: required, Enum: s.Enum, Examples: s.Examples})
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			walk(s.Properties[name], path+"."+name, s.Properties[name].Presence, contains(s.Required, name))
+		}
+		if s.Items != nil {
+			walk(s.Items, path+"[]", 100, true)
+		}
+	}
+	walk(s, "$", 100, true)
+	return fields
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatValues writes enum or example values as JSON separated by commas, as
+// in "active", 1, null
+func FormatValues(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		data, _ := json.Marshal(v)
+		parts[i] = string(data)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// typeOf returns the JSON Schema type of a value decoded with UseNumber
+func typeOf(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return TypeNull
+	case bool:
+		return TypeBoolean
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			return TypeNumber
+		}
+		return TypeInteger
+	case string:
+		return TypeString
+	case []any:
+		return TypeArray
+	default:
+		return TypeObject
+	}
+}
+
+// decode parses a JSON value, keeping numbers as json.Number
+func decode(value string) (any, error) {
+	d := json.NewDecoder(strings.NewReader(value))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	if d.More() {
+		return nil, fmt.Errorf("extra data after the JSON value")
+	}
+	return v, nil
+}
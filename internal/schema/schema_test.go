@@ -0,0 +1,228 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"rocksdb-cli/internal/db"
+)
+
+// fakeDB samples and scans sorted keys
+type fakeDB map[string]string
+
+func (f fakeDB) keys(prefix string) []string {
+	var keys []string
+	for k := range f {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (f fakeDB) GetCFStatsWithOptions(cf string, opts db.StatsOptions) (*db.CFStats, error) {
+	stats := &db.CFStats{Name: cf, DataTypeDistribution: map[db.DataType]int64{}}
+	for _, k := range f.keys(opts.Prefix) {
+		stats.KeyCount++
+		if strings.HasPrefix(f[k], "{") {
+			stats.DataTypeDistribution[db.DataTypeJSON]++
+			if len(stats.JSONSamples) < opts.JSONSamples {
+				stats.JSONSamples = append(stats.JSONSamples, f[k])
+			}
+		}
+	}
+	return stats, nil
+}
+
+func (f fakeDB) ScanCFPage(cf string, start, end []byte, opts db.ScanOptions) (db.ScanPageResult, error) {
+	page := db.ScanPageResult{Results: map[string]string{}}
+	for _, k := range f.keys("") {
+		if (len(start) > 0 && k < string(start)) || (len(end) > 0 && k >= string(end)) {
+			continue
+		}
+		if len(page.Results) == opts.Limit {
+			page.HasMore = true
+			break
+		}
+		page.Results[k] = f[k]
+	}
+	return page, nil
+}
+
+// reversedDB is a fakeDB reporting the reverse bytewise comparator
+type reversedDB struct{ fakeDB }
+
+func (r reversedDB) GetCFOptions(cf string) (*db.CFOptions, error) {
+	return &db.CFOptions{Name: cf, Comparator: "rocksdb.ReverseBytewiseComparator"}, nil
+}
+
+var users = []string{
+	`{"id": 1, "name": "Alice", "status": "active", "age": 31, "tags": ["a"], "address": {"city": "Oslo"}}`,
+	`{"id": 2, "name": "Bob", "status": "inactive", "age": null, "tags": []}`,
+	`{"id": 3, "name": "Carol", "status": "active", "age": 40.5, "address": {"city": "Rome", "zip": "00100"}}`,
+	`{"id": 4, "name": "Dave", "status": "active", "age": 25}`,
+}
+
+func TestInferrer(t *testing.T) {
+	in := NewInferrer()
+	for _, u := range users {
+		if err := in.Add(u); err != nil {
+			t.Fatalf("Add(%s) error = %v", u, err)
+		}
+	}
+	if err := in.Add("{not json"); err == nil {
+		t.Error("Add should refuse invalid JSON")
+	}
+	s := in.Schema()
+
+	if s.Schema != Draft || !reflect.DeepEqual(s.Type, Types{TypeObject}) || s.Count != 4 {
+		t.Errorf("root = %+v", s)
+	}
+	if want := []string{"age", "id", "name", "status"}; !reflect.DeepEqual(s.Required, want) {
+		t.Errorf("Required = %v, want %v", s.Required, want)
+	}
+
+	age := s.Properties["age"]
+	if !reflect.DeepEqual(age.Type, Types{TypeNumber, TypeNull}) || age.Presence != 100 {
+		t.Errorf("age = %+v, want number|null in every document", age)
+	}
+	if age.TypePercent[TypeInteger] != 50 || age.TypePercent[TypeNull] != 25 {
+		t.Errorf("age type percent = %v", age.TypePercent)
+	}
+	if status := s.Properties["status"]; !reflect.DeepEqual(status.Enum, []any{"active", "inactive"}) || status.Examples != nil {
+		t.Errorf("status = %+v, want the enum active, inactive", status)
+	}
+	if name := s.Properties["name"]; name.Enum != nil || len(name.Examples) != maxExamples {
+		t.Errorf("name = %+v, want examples and no enum", name)
+	}
+	address := s.Properties["address"]
+	if address.Presence != 50 || address.Properties["zip"].Presence != 50 || !reflect.DeepEqual(address.Required, []string{"city"}) {
+		t.Errorf("address = %+v", address)
+	}
+	if tags := s.Properties["tags"]; tags.Items == nil || !reflect.DeepEqual(tags.Items.Type, Types{TypeString}) {
+		t.Errorf("tags = %+v, want string items", tags)
+	}
+
+	var paths []string
+	for _, f := range s.Fields() {
+		paths = append(paths, f.Path)
+	}
+	want := "$ $.address $.address.city $.address.zip $.age $.id $.name $.status $.tags $.tags[]"
+	if got := strings.Join(paths, " "); got != want {
+		t.Errorf("Fields() = %s, want %s", got, want)
+	}
+}
+
+func TestSchemaJSON(t *testing.T) {
+	in := NewInferrer()
+	for _, u := range users {
+		in.Add(u)
+	}
+	data, err := json.Marshal(in.Schema())
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"type":"object"`) || !strings.Contains(string(data), `"type":["number","null"]`) {
+		t.Errorf("schema JSON = %s", data)
+	}
+	s, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !reflect.DeepEqual(s.Properties["age"].Type, Types{TypeNumber, TypeNull}) || s.Properties["status"].Enum[1] != "inactive" {
+		t.Errorf("parsed schema = %+v", s)
+	}
+
+	for _, bad := range []string{`[]`, `{}`, `{"type": 1}`} {
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("Parse(%s) should fail", bad)
+		}
+	}
+}
+
+func TestChecker(t *testing.T) {
+	in := NewInferrer()
+	for _, u := range users {
+		in.Add(u)
+	}
+	c := NewChecker(in.Schema(), 4)
+	for i, u := range users {
+		c.Check(string(rune('a'+i)), u)
+	}
+	c.Check("e", `{"id": "5", "name": "Eve", "status": "archived", "email": "e@x", "address": {"city": "Paris", "geo": {"lat": 1}}}`)
+	c.Check("f", `{"name": "Frank", "status": "active", "email": null, "tags": [1]}`)
+	c.Check("g", `plain text`)
+
+	r := c.Report()
+	if r.Checked != 7 || r.Violating != 3 || r.ViolationCount != 7 {
+		t.Errorf("report = %+v, want 7 checked, 3 violating, 7 violations", r)
+	}
+	want := []Violation{
+		{Key: "e", Path: "$.age", Message: "required field is missing"},
+		{Key: "e", Path: "$.id", Message: "is string, want integer"},
+		{Key: "e", Path: "$.status", Message: `"archived" is not one of "active", "inactive"`},
+		{Key: "f", Path: "$.age", Message: "required field is missing"},
+	}
+	if !reflect.DeepEqual(r.Violations, want) {
+		t.Errorf("Violations = %+v, want %+v", r.Violations, want)
+	}
+	wantFields := []NewField{
+		{Path: "$.address.geo", Count: 1, Type: Types{TypeObject}, ExampleKey: "e"},
+		{Path: "$.email", Count: 2, Type: Types{TypeString, TypeNull}, ExampleKey: "e"},
+	}
+	if !reflect.DeepEqual(r.NewFields, wantFields) {
+		t.Errorf("NewFields = %+v, want %+v", r.NewFields, wantFields)
+	}
+}
+
+func TestInferAndCheckCF(t *testing.T) {
+	f := fakeDB{"cfg": "v1"}
+	for i, u := range users {
+		f["user:"+string(rune('1'+i))] = u
+	}
+	s, stats, err := InferCF(f, "users", "user:", 0, nil)
+	if err != nil {
+		t.Fatalf("InferCF() error = %v", err)
+	}
+	want := Source{ColumnFamily: "users", Prefix: "user:", Keys: 4, JSONValues: 4, Sampled: 4}
+	if s.Source == nil || *s.Source != want || s.Title != "users user:" || len(stats.JSONSamples) != 4 {
+		t.Errorf("InferCF() = %+v, source %+v", s, s.Source)
+	}
+
+	if _, _, err := InferCF(f, "users", "cfg", 0, nil); !errors.Is(err, ErrNoJSONValues) {
+		t.Errorf("InferCF() without JSON values error = %v, want ErrNoJSONValues", err)
+	}
+
+	// Scans continue past their batch
+	for i := 0; i < batchSize; i++ {
+		f[fmt.Sprintf("user:x%04d", i)] = users[0]
+	}
+	c := NewChecker(s, 0)
+	if err := CheckCF(f, "users", "user:", c, nil); err != nil {
+		t.Fatalf("CheckCF() error = %v", err)
+	}
+	if r := c.Report(); r.Checked != int64(len(f)-1) || r.Violating != 0 {
+		t.Errorf("CheckCF() report = %+v, want %d valid documents", r, len(f)-1)
+	}
+
+	// A failing progress function stops the check after a batch
+	c = NewChecker(s, 0)
+	stop := func(visited int64, lastKey string) error { return errors.New("stop") }
+	if err := CheckCF(f, "users", "user:", c, stop); err != nil {
+		t.Fatalf("stopped CheckCF() error = %v", err)
+	}
+	if r := c.Report(); r.Checked != batchSize || !r.HasMore {
+		t.Errorf("stopped CheckCF() report = %+v, want the first batch", r)
+	}
+
+	// Prefixes and batches are key ranges only in bytewise order
+	if err := CheckCF(reversedDB{f}, "users", "user:", NewChecker(s, 0), nil); !errors.Is(err, db.ErrNotBytewise) {
+		t.Errorf("CheckCF() with a reverse comparator error = %v, want ErrNotBytewise", err)
+	}
+}
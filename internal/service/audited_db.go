@@ -0,0 +1,205 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"rocksdb-cli/internal/audit"
+	"rocksdb-cli/internal/db"
+)
+
+// ErrAuditCFProtected is returned for writes to the audit column family
+var ErrAuditCFProtected = errors.New("the audit column family cannot be modified")
+
+// AuditSource describes who performs the operations of an AuditedDB
+type AuditSource struct {
+	Actor     string
+	Remote    string
+	Interface audit.Interface
+	Database  string
+	Details   map[string]string // added to every event, e.g. a transform expression
+}
+
+// AuditedDB records every mutation made through it in an audit log. Reads
+// pass straight through to the wrapped database.
+type AuditedDB struct {
+	db.KeyValueDB
+	log     *audit.Log
+	source  AuditSource
+	ownsLog bool // Close closes log
+}
+
+// NewAuditedDB wraps database so its mutations are recorded in log on behalf
+// of source. A nil log returns database unchanged.
+func NewAuditedDB(database db.KeyValueDB, log *audit.Log, source AuditSource) db.KeyValueDB {
+	if log == nil {
+		return database
+	}
+	return &AuditedDB{KeyValueDB: database, log: log, source: source}
+}
+
+// NewAuditedDBClosingLog is NewAuditedDB for a log opened for database
+// alone: closing the AuditedDB closes the log after the database
+func NewAuditedDBClosingLog(database db.KeyValueDB, log *audit.Log, source AuditSource) db.KeyValueDB {
+	if log == nil {
+		return database
+	}
+	return &AuditedDB{KeyValueDB: database, log: log, source: source, ownsLog: true}
+}
+
+// AuditStore lets an audit log keep its events in a column family of
+// database
+func AuditStore(database db.KeyValueDB) audit.Store {
+	return auditStore{database}
+}
+
+type auditStore struct {
+	db.KeyValueDB
+}
+
+func (s auditStore) ScanCFReverse(cf string, start, end []byte, limit int) (audit.Page, error) {
+	page, err := s.ScanCFPage(cf, start, end, db.ScanOptions{Limit: limit, Reverse: true, Values: true})
+	if err != nil {
+		return audit.Page{}, err
+	}
+	return audit.Page{Results: page.Results, HasMore: page.HasMore}, nil
+}
+
+// Close closes the wrapped database, then the audit log if it was opened
+// for this database alone, so the events recorded are written out
+func (a *AuditedDB) Close() {
+	a.KeyValueDB.Close()
+	if a.ownsLog {
+		if err := a.log.Close(); err != nil {
+			log.Printf("Warning: failed to close the audit log: %v", err)
+		}
+	}
+}
+
+// Unwrap returns the wrapped database
+func (a *AuditedDB) Unwrap() db.KeyValueDB {
+	return a.KeyValueDB
+}
+
+// WithInterface returns a copy recording operations as made through iface
+func (a *AuditedDB) WithInterface(iface audit.Interface) *AuditedDB {
+	c := *a
+	c.source.Interface = iface
+	c.ownsLog = false // the log is closed with a
+	return &c
+}
+
+// UnwrapDB returns the database under any audit wrapper
+func UnwrapDB(database db.KeyValueDB) db.KeyValueDB {
+	if a, ok := database.(*AuditedDB); ok {
+		return a.Unwrap()
+	}
+	return database
+}
+
+func (a *AuditedDB) PutCF(cf, key, value string) error {
+	return a.put(cf, key, value, 0)
+}
+
+func (a *AuditedDB) PutCFWithTTL(cf, key, value string, ttl time.Duration) error {
+	return a.put(cf, key, value, ttl)
+}
+
+func (a *AuditedDB) put(cf, key, value string, ttl time.Duration) error {
+	old := a.current(cf, key)
+
+	var err error
+	switch {
+	case a.protected(cf):
+		err = ErrAuditCFProtected
+	case ttl > 0:
+		err = a.KeyValueDB.PutCFWithTTL(cf, key, value, ttl)
+	default:
+		err = a.KeyValueDB.PutCF(cf, key, value)
+	}
+
+	var details map[string]string
+	if ttl > 0 {
+		details = map[string]string{"ttl": ttl.String()}
+	}
+	a.record(audit.OpPut, cf, key, audit.Values{Old: old, New: &value}, details, err)
+	return err
+}
+
+func (a *AuditedDB) MergeCF(cf, key, operand string) error {
+	old := a.current(cf, key)
+
+	err := ErrAuditCFProtected
+	if !a.protected(cf) {
+		err = a.KeyValueDB.MergeCF(cf, key, operand)
+	}
+	a.record(audit.OpMerge, cf, key, audit.Values{Old: old, New: &operand}, nil, err)
+	return err
+}
+
+func (a *AuditedDB) CreateCF(cf string) error {
+	err := a.KeyValueDB.CreateCF(cf)
+	a.record(audit.OpCreateCF, cf, "", audit.Values{}, nil, err)
+	return err
+}
+
+func (a *AuditedDB) CreateCFWithProfile(cf, profile string) error {
+	err := a.KeyValueDB.CreateCFWithProfile(cf, profile)
+	a.record(audit.OpCreateCF, cf, "", audit.Values{}, map[string]string{"profile": profile}, err)
+	return err
+}
+
+func (a *AuditedDB) DropCF(cf string) error {
+	err := ErrAuditCFProtected
+	if !a.protected(cf) {
+		err = a.KeyValueDB.DropCF(cf)
+	}
+	a.record(audit.OpDropCF, cf, "", audit.Values{}, nil, err)
+	return err
+}
+
+// protected reports whether cf holds the audit log itself
+func (a *AuditedDB) protected(cf string) bool {
+	auditCF := a.log.ColumnFamily()
+	return auditCF != "" && cf == auditCF
+}
+
+// current returns the value a write is about to replace, if any
+func (a *AuditedDB) current(cf, key string) *string {
+	value, err := a.KeyValueDB.GetCF(cf, key)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
+func (a *AuditedDB) record(op, cf, key string, values audit.Values, details map[string]string, opErr error) {
+	event := audit.Event{
+		Actor:     a.source.Actor,
+		Remote:    a.source.Remote,
+		Interface: a.source.Interface,
+		Operation: op,
+		Database:  a.source.Database,
+		CF:        cf,
+		Key:       key,
+	}
+	if len(a.source.Details) > 0 || len(details) > 0 {
+		event.Details = make(map[string]string, len(a.source.Details)+len(details))
+		for k, v := range a.source.Details {
+			event.Details[k] = v
+		}
+		for k, v := range details {
+			event.Details[k] = v
+		}
+	}
+	if opErr != nil {
+		event.Result = audit.ResultError
+		event.Error = opErr.Error()
+	}
+
+	// A failing audit log must not fail the operation it describes
+	if err := a.log.Record(event, values); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"rocksdb-cli/internal/audit"
+)
+
+func TestAuditedDB_RecordsWrites(t *testing.T) {
+	l, err := audit.New(audit.Config{File: filepath.Join(t.TempDir(), "audit.log")}, nil)
+	if err != nil {
+		t.Fatalf("audit.New failed: %v", err)
+	}
+	defer l.Close()
+
+	mockDB := NewMockDB()
+	mockDB.data["users"] = map[string]string{}
+	audited := NewAuditedDB(mockDB, l, AuditSource{Actor: "alice", Interface: audit.InterfaceREST, Database: "/data/db"})
+	svc := NewDatabaseService(audited)
+
+	if err := svc.PutValue("users", "u1", "v1"); err != nil {
+		t.Fatalf("PutValue failed: %v", err)
+	}
+	if err := audited.DropCF("missing"); err == nil {
+		t.Fatal("expected DropCF of a missing column family to fail")
+	}
+
+	events, err := l.Query(audit.Filter{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	put := events[0]
+	if put.Actor != "alice" || put.Interface != audit.InterfaceREST || put.Operation != audit.OpPut ||
+		put.CF != "users" || put.Key != "u1" || put.Database != "/data/db" {
+		t.Errorf("unexpected put event: %+v", put)
+	}
+	if put.NewHash == "" || put.Result != audit.ResultOK {
+		t.Errorf("expected a successful put with a value hash, got %+v", put)
+	}
+	if events[1].Operation != audit.OpDropCF || events[1].Result != audit.ResultError {
+		t.Errorf("expected a failed dropcf event, got %+v", events[1])
+	}
+}
+
+func TestAuditedDB_ProtectsAuditColumnFamily(t *testing.T) {
+	mockDB := NewMockDB()
+	l, err := audit.New(audit.Config{ColumnFamily: "__audit__"}, AuditStore(mockDB))
+	if err != nil {
+		t.Fatalf("audit.New failed: %v", err)
+	}
+	audited := NewAuditedDB(mockDB, l, AuditSource{Actor: "bob", Interface: audit.InterfaceCLI})
+
+	if err := audited.PutCF("__audit__", "forged", "x"); !errors.Is(err, ErrAuditCFProtected) {
+		t.Errorf("expected ErrAuditCFProtected for put, got %v", err)
+	}
+	if err := audited.DropCF("__audit__"); !errors.Is(err, ErrAuditCFProtected) {
+		t.Errorf("expected ErrAuditCFProtected for dropcf, got %v", err)
+	}
+	if _, ok := mockDB.data["__audit__"]["forged"]; ok {
+		t.Error("write to the audit column family reached the database")
+	}
+	if UnwrapDB(audited) != mockDB {
+		t.Error("UnwrapDB should return the wrapped database")
+	}
+}
+
+func TestNewAuditedDB_NilLog(t *testing.T) {
+	mockDB := NewMockDB()
+	if NewAuditedDB(mockDB, nil, AuditSource{}) != mockDB {
+		t.Error("a nil log should return the database unchanged")
+	}
+}
+
+func TestAuditedDB_CloseClosesOwnedLog(t *testing.T) {
+	l, err := audit.New(audit.Config{File: filepath.Join(t.TempDir(), "audit.log")}, nil)
+	if err != nil {
+		t.Fatalf("audit.New failed: %v", err)
+	}
+	owned := NewAuditedDBClosingLog(NewMockDB(), l, AuditSource{Interface: audit.InterfaceCLI})
+
+	// Copies and wrappers of a shared log leave it open
+	owned.(*AuditedDB).WithInterface(audit.InterfaceAI).Close()
+	NewAuditedDB(NewMockDB(), l, AuditSource{}).Close()
+	if err := l.Record(audit.Event{Operation: audit.OpPut}, audit.Values{}); err != nil {
+		t.Fatalf("the log should still be open: %v", err)
+	}
+
+	owned.Close()
+	if err := l.Record(audit.Event{Operation: audit.OpPut}, audit.Values{}); err == nil {
+		t.Error("closing the database should close the log opened for it")
+	}
+}
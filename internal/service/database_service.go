@@ -1,6 +1,8 @@
 package service
 
 import (
+	"time"
+
 	"rocksdb-cli/internal/db"
 	"rocksdb-cli/internal/util"
 )
@@ -21,6 +23,11 @@ func (s *DatabaseService) GetValue(cf, key string) (string, error) {
 	return s.db.SmartGetCF(cf, key)
 }
 
+// GetValueWithTTL is GetValue that also returns the decoded expiry in TTL mode
+func (s *DatabaseService) GetValueWithTTL(cf, key string) (string, *db.TTLInfo, error) {
+	return s.db.GetCFWithTTL(cf, key)
+}
+
 // PutValue writes or updates a key-value pair in a column family
 func (s *DatabaseService) PutValue(cf, key, value string) error {
 	if s.db.IsReadOnly() {
@@ -29,6 +36,22 @@ func (s *DatabaseService) PutValue(cf, key, value string) error {
 	return s.db.PutCF(cf, key, value)
 }
 
+// PutValueWithTTL writes a value that expires ttl from now (TTL mode only)
+func (s *DatabaseService) PutValueWithTTL(cf, key, value string, ttl time.Duration) error {
+	if s.db.IsReadOnly() {
+		return db.ErrReadOnlyMode
+	}
+	return s.db.PutCFWithTTL(cf, key, value, ttl)
+}
+
+// MergeValue applies a merge operand to a key using the column family's merge operator
+func (s *DatabaseService) MergeValue(cf, key, operand string) error {
+	if s.db.IsReadOnly() {
+		return db.ErrReadOnlyMode
+	}
+	return s.db.MergeCF(cf, key, operand)
+}
+
 // DeleteValue deletes a key from a column family
 func (s *DatabaseService) DeleteValue(cf, key string) error {
 	if s.db.IsReadOnly() {
@@ -2,7 +2,9 @@ package service
 
 import (
 	"testing"
+	"time"
 	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/keytree"
 	"rocksdb-cli/internal/util"
 )
 
@@ -73,6 +75,51 @@ func (m *MockDB) GetKeyFormatInfo(cf string) (util.KeyFormat, string) {
 	return util.KeyFormatString, "example"
 }
 
+func (m *MockDB) CreateCFWithProfile(cf, profile string) error {
+	return m.CreateCF(cf)
+}
+
+func (m *MockDB) GetCFOptions(cf string) (*db.CFOptions, error) {
+	if m.data[cf] == nil {
+		return nil, db.ErrColumnFamilyNotFound
+	}
+	return &db.CFOptions{Name: cf, Comparator: db.BytewiseComparator}, nil
+}
+
+func (m *MockDB) OptionMismatches() []db.OptionMismatch {
+	return nil
+}
+
+func (m *MockDB) MergeCF(cf, key, operand string) error {
+	if m.readOnly {
+		return db.ErrReadOnlyMode
+	}
+	if m.data[cf] == nil {
+		return db.ErrColumnFamilyNotFound
+	}
+	if existing, ok := m.data[cf][key]; ok {
+		operand = existing + "," + operand
+	}
+	m.data[cf][key] = operand
+	return nil
+}
+
+func (m *MockDB) PutCFWithTTL(cf, key, value string, ttl time.Duration) error {
+	return db.ErrTTLModeDisabled
+}
+
+func (m *MockDB) GetCFWithTTL(cf, key string) (string, *db.TTLInfo, error) {
+	return "", nil, db.ErrTTLModeDisabled
+}
+
+func (m *MockDB) GetTTLStats(cf string) (*db.TTLStats, error) {
+	return nil, db.ErrTTLModeDisabled
+}
+
+func (m *MockDB) TTLMode() bool {
+	return false
+}
+
 func (m *MockDB) IsReadOnly() bool {
 	return m.readOnly
 }
@@ -92,6 +139,15 @@ func (m *MockDB) SearchCF(cf string, opts db.SearchOptions) (*db.SearchResults,
 func (m *MockDB) ExportSearchResultsToCSV(cf, filePath, sep string, opts db.SearchOptions) error { return nil }
 func (m *MockDB) GetCFStats(cf string) (*db.CFStats, error) { return nil, nil }
 func (m *MockDB) GetDatabaseStats() (*db.DatabaseStats, error) { return nil, nil }
+func (m *MockDB) GetKeyTree(cf string, opts keytree.Options) (*keytree.Node, error) {
+	return nil, nil
+}
+func (m *MockDB) ExportToCSVWithOptions(cf, filePath string, opts db.ExportOptions) (*db.ExportResult, error) {
+	return nil, nil
+}
+func (m *MockDB) GetCFStatsWithOptions(cf string, opts db.StatsOptions) (*db.CFStats, error) {
+	return nil, nil
+}
 func (m *MockDB) Close() {}
 func (m *MockDB) SmartPrefixScanCF(cf, prefix string, limit int) (map[string]string, error) { return nil, nil }
 func (m *MockDB) SmartScanCF(cf string, start, end string, opts db.ScanOptions) (map[string]string, error) { return nil, nil }
@@ -139,6 +195,30 @@ func TestDatabaseService_PutValue(t *testing.T) {
 	}
 }
 
+func TestDatabaseService_MergeValue(t *testing.T) {
+	mockDB := NewMockDB()
+	mockDB.data["tags"] = map[string]string{"post:1": "go"}
+
+	service := NewDatabaseService(mockDB)
+
+	if err := service.MergeValue("tags", "post:1", "rocksdb"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	value, err := service.GetValue("tags", "post:1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if value != "go,rocksdb" {
+		t.Errorf("Expected value go,rocksdb, got %s", value)
+	}
+
+	mockDB.readOnly = true
+	if err := service.MergeValue("tags", "post:1", "x"); err != db.ErrReadOnlyMode {
+		t.Errorf("Expected ErrReadOnlyMode, got %v", err)
+	}
+}
+
 func TestDatabaseService_ListColumnFamilies(t *testing.T) {
 	mockDB := NewMockDB()
 	mockDB.data["users"] = make(map[string]string)
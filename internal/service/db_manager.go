@@ -24,6 +24,7 @@ type DatabaseInfo struct {
 type DBManager struct {
 	currentDB   db.KeyValueDB
 	currentInfo *DatabaseInfo
+	openConfig  db.OpenConfig
 	mu          sync.RWMutex
 }
 
@@ -32,6 +33,12 @@ func NewDBManager() *DBManager {
 	return &DBManager{}
 }
 
+// NewDBManagerWithConfig creates a database manager that opens databases with
+// the column family plugins and TTL mode of cfg; read-only mode is enforced
+func NewDBManagerWithConfig(cfg db.OpenConfig) *DBManager {
+	return &DBManager{openConfig: cfg}
+}
+
 // GetCurrentDB returns the current database connection (thread-safe read)
 func (m *DBManager) GetCurrentDB() (db.KeyValueDB, error) {
 	m.mu.RLock()
@@ -82,7 +89,9 @@ func (m *DBManager) Connect(dbPath string) (*DatabaseInfo, error) {
 	}
 
 	// Open new database in read-only mode
-	newDB, err := db.OpenReadOnly(dbPath)
+	cfg := m.openConfig
+	cfg.ReadOnly = true
+	newDB, err := db.OpenWithConfig(dbPath, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -394,6 +394,34 @@ func TestDBManager_ReadOnlyEnforcement(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestDBManager_ConnectWithConfig(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "testdb")
+	ttl, err := db.ParseTTLSpec("1h")
+	require.NoError(t, err)
+
+	// Write a value the way DBWithTTL stores it
+	testDB, err := db.OpenWithConfig(dbPath, db.OpenConfig{TTL: ttl})
+	require.NoError(t, err)
+	require.NoError(t, testDB.PutCFWithTTL("default", "session", "v", time.Hour))
+	testDB.Close()
+
+	// A writable config still connects read-only
+	manager := NewDBManagerWithConfig(db.OpenConfig{TTL: ttl})
+	info, err := manager.Connect(dbPath)
+	require.NoError(t, err)
+	defer manager.Disconnect()
+	assert.True(t, info.ReadOnly)
+
+	current, err := manager.GetCurrentDB()
+	require.NoError(t, err)
+	assert.True(t, current.IsReadOnly())
+	value, ttlInfo, err := current.GetCFWithTTL("default", "session")
+	require.NoError(t, err)
+	assert.Equal(t, "v", value, "the TTL timestamp should be stripped")
+	require.NotNil(t, ttlInfo)
+	assert.NotNil(t, ttlInfo.ExpiresAt)
+}
+
 func TestDBManager_InfoImmutability(t *testing.T) {
 	manager := NewDBManager()
 	dbPath := createTestDB(t)
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/schema"
+)
+
+// SchemaService infers JSON Schemas from the JSON values of column families
+// and checks values against them
+type SchemaService struct {
+	db db.KeyValueDB
+}
+
+// NewSchemaService creates a new SchemaService instance
+func NewSchemaService(database db.KeyValueDB) *SchemaService {
+	return &SchemaService{db: database}
+}
+
+// Infer infers the schema of the JSON values of cf under prefix from a
+// sample of n of them, schema.DefaultSamples when n is not positive
+func (s *SchemaService) Infer(cf, prefix string, n int) (*schema.Schema, error) {
+	inferred, _, err := schema.InferCF(s.db, cf, prefix, n, nil)
+	return inferred, err
+}
+
+// Check checks the values of cf under prefix against sch, listing up to
+// limit violations
+func (s *SchemaService) Check(cf, prefix string, sch *schema.Schema, limit int) (*schema.Report, error) {
+	c := schema.NewChecker(sch, limit)
+	if err := schema.CheckCF(s.db, cf, prefix, c, nil); err != nil {
+		return nil, err
+	}
+	return c.Report(), nil
+}
@@ -34,12 +34,14 @@ type SearchResult struct {
 
 // SearchResultItem represents a single search result
 type SearchResultItem struct {
-	Key           string   `json:"key"`
-	Value         string   `json:"value"`
-	KeyIsBinary   bool     `json:"key_is_binary"`   // true if key is hex encoded
-	ValueIsBinary bool     `json:"value_is_binary"` // true if value is hex encoded
-	Timestamp     string   `json:"timestamp"`       // parsed timestamp if key is a timestamp
-	MatchedFields []string `json:"matched_fields"`  // Which fields matched (key, value, both)
+	Key           string      `json:"key"`
+	Value         string      `json:"value"`
+	KeyIsBinary   bool        `json:"key_is_binary"`   // true if key is hex encoded
+	ValueIsBinary bool        `json:"value_is_binary"` // true if value is hex encoded
+	Timestamp     string      `json:"timestamp"`       // parsed timestamp if key is a timestamp
+	MatchedFields []string    `json:"matched_fields"`  // Which fields matched (key, value, both)
+	TTL           *db.TTLInfo `json:"ttl,omitempty"`   // decoded expiry in TTL mode
+	RawKey        string      `json:"-"`               // the key as stored, which Key encodes
 }
 
 // JSONQueryResult contains the results of a JSON field query
@@ -85,6 +87,8 @@ func (s *SearchService) Search(cf string, opts SearchOptions) (*SearchResult, er
 			ValueIsBinary: r.ValueIsBinary,
 			Timestamp:     r.Timestamp,
 			MatchedFields: r.MatchedFields,
+			TTL:           r.TTL,
+			RawKey:        r.RawKey,
 		})
 	}
 
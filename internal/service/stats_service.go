@@ -2,6 +2,7 @@ package service
 
 import (
 	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/keytree"
 )
 
 // StatsService provides database statistics operations
@@ -76,6 +77,16 @@ func (s *StatsService) GetDatabaseStats() (*DatabaseStats, error) {
 	}, nil
 }
 
+// GetTTLStats retrieves the expiry distribution of a column family in TTL mode
+func (s *StatsService) GetTTLStats(cf string) (*db.TTLStats, error) {
+	return s.db.GetTTLStats(cf)
+}
+
+// GetKeyTree returns the key prefixes of a column family as a tree
+func (s *StatsService) GetKeyTree(cf string, opts keytree.Options) (*keytree.Node, error) {
+	return s.db.GetKeyTree(cf, opts)
+}
+
 // GetColumnFamilyStats retrieves statistics for a specific column family
 func (s *StatsService) GetColumnFamilyStats(cf string) (*ColumnFamilyStats, error) {
 	cfStats, err := s.db.GetCFStats(cf)
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"time"
+
+	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/timeseries"
+)
+
+// TimeSeriesService reads column families whose keys start with a time
+type TimeSeriesService struct {
+	db db.KeyValueDB
+}
+
+// NewTimeSeriesService creates a new TimeSeriesService instance
+func NewTimeSeriesService(database db.KeyValueDB) *TimeSeriesService {
+	return &TimeSeriesService{db: database}
+}
+
+// Detect returns how the keys of cf encode their time after prefix;
+// enc forces an encoding, "" detects it
+func (s *TimeSeriesService) Detect(cf, prefix string, enc timeseries.Encoding) (timeseries.Codec, error) {
+	return timeseries.Detect(s.db, cf, prefix, enc)
+}
+
+// Range returns the entries of q in time order
+func (s *TimeSeriesService) Range(q timeseries.Query, opts timeseries.Options) (*timeseries.Page, error) {
+	return timeseries.Range(s.db, q, opts)
+}
+
+// Latest returns the n newest entries of q, newest first
+func (s *TimeSeriesService) Latest(q timeseries.Query, n int, values bool) (*timeseries.Page, error) {
+	return timeseries.Latest(s.db, q, n, values)
+}
+
+// Histogram counts the entries of q per time bucket
+func (s *TimeSeriesService) Histogram(q timeseries.Query, bucket time.Duration) (*timeseries.Histogram, error) {
+	return timeseries.NewHistogram(s.db, q, bucket, nil)
+}
@@ -80,6 +80,12 @@ func (s *TransformService) Transform(opts TransformOptions) (*TransformResult, e
 		return nil, err
 	}
 
+	return NewTransformResult(result), nil
+}
+
+// NewTransformResult converts the result of a transform processor to the
+// result of the service
+func NewTransformResult(result *transform.TransformResult) *TransformResult {
 	// Convert transform errors
 	errors := make([]TransformError, 0, len(result.Errors))
 	for _, e := range result.Errors {
@@ -111,5 +117,5 @@ func (s *TransformService) Transform(opts TransformOptions) (*TransformResult, e
 		Errors:    errors,
 		Preview:   preview,
 		Duration:  result.Duration.String(),
-	}, nil
+	}
 }
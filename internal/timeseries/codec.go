@@ -0,0 +1,319 @@
+// Package timeseries reads column families keyed by time. A Codec turns
+// times into key bounds for keys that start with .NET ticks, Unix timestamps
+// or ISO 8601 text, and the queries of this package use it to read the
+// entries between two times, the latest ones, the entries written after a
+// cursor and the number of entries per time bucket.
+package timeseries
+
+import (
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"rocksdb-cli/internal/util"
+)
+
+// Encoding is how the time at the start of a key is written
+type Encoding string
+
+const (
+	Ticks   Encoding = "ticks" // .NET ticks, 100ns intervals since 0001-01-01
+	Nanos   Encoding = "ns"    // Unix nanoseconds
+	Micros  Encoding = "us"    // Unix microseconds
+	Millis  Encoding = "ms"    // Unix milliseconds
+	Seconds Encoding = "s"     // Unix seconds
+	ISO     Encoding = "iso"   // ISO 8601 text such as 2024-03-01T10:00:00Z
+)
+
+// Encodings lists the encodings in the order they are tried by DetectKey
+var Encodings = []Encoding{ISO, Ticks, Nanos, Micros, Millis, Seconds}
+
+// ParseEncoding returns the encoding named s, or "" to detect it when s is
+// empty or "auto"
+func ParseEncoding(s string) (Encoding, error) {
+	if s == "" || s == "auto" {
+		return "", nil
+	}
+	for _, e := range Encodings {
+		if string(e) == s {
+			return e, nil
+		}
+	}
+	return "", fmt.Errorf("unknown time encoding %q (use %s)", s, encodingNames())
+}
+
+func encodingNames() string {
+	names := make([]string, len(Encodings))
+	for i, e := range Encodings {
+		names[i] = string(e)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Detected integer encodings must read as a time between these; the lower
+// bound keeps milliseconds from reading as nanoseconds of January 1970
+var (
+	detectMin = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+	detectMax = time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// minDigits is the length of the shortest decimal timestamp: Unix seconds
+// have 9 digits from 1973 to 2001. Shorter runs of digits at the start of a
+// key are read as binary.
+const minDigits = 9
+
+// isoPattern matches the ISO 8601 time at the start of a key
+var isoPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(?:[T ]\d{2}:\d{2}(?::\d{2}(?:\.\d+)?)?)?(?:Z|[+-]\d{2}:?\d{2})?`)
+
+// isoLayouts parse the times matched by isoPattern; times without a zone
+// are UTC
+var isoLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04Z07:00",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02 15:04:05.999999999Z0700",
+	"2006-01-02",
+}
+
+// Codec reads and writes the time at the start of keys, after Prefix. The
+// rest of a key, an id making it unique for example, is ignored.
+//
+// Decimal keys sort by time only while they have the same number of digits,
+// which holds for ticks and for Unix times of the same era. ISO keys sort by
+// time when they are all in UTC.
+type Codec struct {
+	Encoding Encoding `json:"encoding"`
+	// Binary integers are 8-byte big-endian, others decimal text
+	Binary bool   `json:"binary"`
+	Prefix string `json:"prefix,omitempty"`
+	// Layout writes the bounds of ISO keys, with the separator of the keys
+	Layout string `json:"layout,omitempty"`
+}
+
+// String describes the codec, as in "ticks (8-byte big-endian)"
+func (c Codec) String() string {
+	s := string(c.Encoding)
+	switch {
+	case c.Encoding == ISO:
+		s += " (text)"
+	case c.Binary:
+		s += " (8-byte big-endian)"
+	default:
+		s += " (decimal)"
+	}
+	if c.Prefix != "" {
+		prefix, _ := util.EncodeValue([]byte(c.Prefix))
+		s += fmt.Sprintf(" after %q", prefix)
+	}
+	return s
+}
+
+// DetectKey returns the codec of key, whose time follows prefix. With enc
+// set only that encoding is tried; otherwise integers are read as the first
+// of ticks, ns, us, ms and s giving a time between 1980 and 2200.
+func DetectKey(key, prefix string, enc Encoding) (Codec, error) {
+	rest, ok := strings.CutPrefix(key, prefix)
+	if !ok {
+		return Codec{}, fmt.Errorf("key %s does not start with prefix %q", display(key), prefix)
+	}
+	codec := Codec{Encoding: enc, Prefix: prefix}
+
+	if enc == "" || enc == ISO {
+		if m := isoPattern.FindString(rest); m != "" {
+			if _, ok := parseISO(m); ok {
+				codec.Encoding = ISO
+				switch {
+				case len(m) == len("2006-01-02"):
+					codec.Layout = "2006-01-02"
+				case m[10] == ' ':
+					codec.Layout = "2006-01-02 15:04:05.999999999"
+				default:
+					codec.Layout = "2006-01-02T15:04:05.999999999"
+				}
+				return codec, nil
+			}
+		}
+		if enc == ISO {
+			return Codec{}, fmt.Errorf("key %s does not start with an ISO 8601 time", display(key))
+		}
+	}
+
+	v, isBinary, ok := readInt(rest)
+	if !ok {
+		return Codec{}, fmt.Errorf("cannot read a time from key %s; set the encoding (%s)", display(key), encodingNames())
+	}
+	codec.Binary = isBinary
+	if enc != "" {
+		return codec, nil
+	}
+	for _, e := range Encodings[1:] {
+		if t := fromInt(e, v); !t.Before(detectMin) && t.Before(detectMax) {
+			codec.Encoding = e
+			return codec, nil
+		}
+	}
+	return Codec{}, fmt.Errorf("key %s does not read as a time between 1980 and 2200; set the encoding (%s)", display(key), encodingNames())
+}
+
+// Key returns the key of time t, to use as the bound of a range
+func (c Codec) Key(t time.Time) []byte {
+	key := []byte(c.Prefix)
+	if c.Encoding == ISO {
+		return append(key, t.UTC().Format(c.Layout)...)
+	}
+	v := max(toInt(c.Encoding, t), 0)
+	if c.Binary {
+		return binary.BigEndian.AppendUint64(key, uint64(v))
+	}
+	return strconv.AppendInt(key, v, 10)
+}
+
+// Time returns the time at the start of key
+func (c Codec) Time(key string) (time.Time, bool) {
+	rest, ok := strings.CutPrefix(key, c.Prefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	if c.Encoding == ISO {
+		return parseISO(isoPattern.FindString(rest))
+	}
+	var v int64
+	if c.Binary {
+		if len(rest) < 8 {
+			return time.Time{}, false
+		}
+		v = int64(binary.BigEndian.Uint64([]byte(rest[:8])))
+	} else {
+		n := digits(rest)
+		if n == 0 {
+			return time.Time{}, false
+		}
+		var err error
+		if v, err = strconv.ParseInt(rest[:n], 10, 64); err != nil {
+			return time.Time{}, false
+		}
+	}
+	if v < 0 {
+		return time.Time{}, false
+	}
+	return fromInt(c.Encoding, v), true
+}
+
+// readInt reads the decimal or 8-byte big-endian integer at the start of s
+func readInt(s string) (v int64, isBinary bool, ok bool) {
+	if n := digits(s); n >= minDigits {
+		v, err := strconv.ParseInt(s[:n], 10, 64)
+		return v, false, err == nil
+	}
+	// Binary times have control or invalid UTF-8 bytes; text is not read
+	// as one
+	if len(s) < 8 || util.IsPrintable([]byte(s[:8])) {
+		return 0, false, false
+	}
+	v = int64(binary.BigEndian.Uint64([]byte(s[:8])))
+	return v, true, v > 0
+}
+
+// digits returns the number of decimal digits s starts with
+func digits(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
+
+func parseISO(s string) (time.Time, bool) {
+	if s == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range isoLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func toInt(e Encoding, t time.Time) int64 {
+	switch e {
+	case Ticks:
+		return util.TimeToTicks(t)
+	case Nanos:
+		return t.UnixNano()
+	case Micros:
+		return t.UnixMicro()
+	case Millis:
+		return t.UnixMilli()
+	default:
+		return t.Unix()
+	}
+}
+
+func fromInt(e Encoding, v int64) time.Time {
+	switch e {
+	case Ticks:
+		return util.TicksToTime(v)
+	case Nanos:
+		return time.Unix(0, v).UTC()
+	case Micros:
+		return time.UnixMicro(v).UTC()
+	case Millis:
+		return time.UnixMilli(v).UTC()
+	default:
+		return time.Unix(v, 0).UTC()
+	}
+}
+
+// display shows a key in an error message, binary ones as hex
+func display(key string) string {
+	encoded, _ := util.EncodeValue([]byte(key))
+	return strconv.Quote(encoded)
+}
+
+// ParseTime parses the bound of a query: "now", an RFC 3339 or ISO 8601
+// time (UTC when it has no zone, as in 2024-03-01T10:00), a date, or an age
+// such as "90m", "24h" or "7d" counted back from now
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	if s == "now" {
+		return now, nil
+	}
+	if m := isoPattern.FindString(s); m == s {
+		if t, ok := parseISO(s); ok {
+			return t, nil
+		}
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (want RFC 3339, 2006-01-02T15:04, a date, now or an age like 24h or 7d)", s)
+}
+
+// ParseBucket parses the width of histogram buckets: a Go duration such as
+// "15m" or "1h", or a number of days such as "7d"
+func ParseBucket(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid bucket %q (want a duration such as 1m, 1h or 1d)", s)
+	}
+	return d, nil
+}
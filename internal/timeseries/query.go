@@ -0,0 +1,365 @@
+package timeseries
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/keytree"
+	"rocksdb-cli/internal/util"
+)
+
+// Scanner reads the keys of a column family; db.KeyValueDB is one
+type Scanner interface {
+	ScanCFPage(cf string, start, end []byte, opts db.ScanOptions) (db.ScanPageResult, error)
+}
+
+// optionsReader reports the options of a column family; db.KeyValueDB is one
+type optionsReader interface {
+	GetCFOptions(cf string) (*db.CFOptions, error)
+}
+
+// batchSize is the number of keys read per scan
+const batchSize = 1000
+
+// maxBuckets caps the buckets of a histogram
+const maxBuckets = 10000
+
+// ErrNoKeys is returned by Detect for a column family without keys to
+// detect the codec from
+var ErrNoKeys = errors.New("no keys to detect the time encoding from")
+
+// ErrInvalidCursor is returned for an after cursor that is not base64
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// errStopped is returned by each when its progress function stops it
+var errStopped = errors.New("stopped")
+
+// Query selects the entries of a column family between two times
+type Query struct {
+	CF    string
+	Codec Codec
+	From  time.Time // inclusive, zero for no bound
+	To    time.Time // exclusive, zero for no bound
+}
+
+// Options are the options of Range
+type Options struct {
+	Limit   int  // 0 for all the entries
+	Reverse bool // newest first
+	Values  bool
+	After   string // cursor of an entry: only the entries after it are read
+	// Progress is called after each batch of keys read; an error stops the
+	// range, which returns the entries read so far with HasMore set
+	Progress db.ProgressFunc
+}
+
+// Page is entries in time order with the cursor of the last one, which
+// continues the range or, once HasMore is false, follows it
+type Page struct {
+	Codec Codec `json:"codec"`
+	// Timestamp of the entries is the time of their key in RFC 3339
+	Entries    []db.KeyValue `json:"entries"`
+	NextCursor string        `json:"next_cursor"`
+	HasMore    bool          `json:"has_more"`
+}
+
+// Bucket counts the entries from Start to the start of the next bucket
+type Bucket struct {
+	Start time.Time `json:"start"`
+	Count int64     `json:"count"`
+}
+
+// Histogram is the number of entries per time bucket, empty buckets
+// included
+type Histogram struct {
+	Codec   Codec    `json:"codec"`
+	Bucket  string   `json:"bucket"`
+	Buckets []Bucket `json:"buckets"`
+	Total   int64    `json:"total"`
+	HasMore bool     `json:"has_more,omitempty"` // True if the scan was stopped before the end
+}
+
+// Detect returns the codec of the keys of cf after prefix, read from the
+// first key or, when it has no time, from the last one
+func Detect(s Scanner, cf, prefix string, enc Encoding) (Codec, error) {
+	var start, end []byte
+	if prefix != "" {
+		start, end = []byte(prefix), keytree.PrefixEnd([]byte(prefix))
+	}
+	var firstErr error
+	for _, reverse := range []bool{false, true} {
+		scanStart := start
+		if reverse && end == nil {
+			scanStart = nil
+		}
+		page, err := s.ScanCFPage(cf, scanStart, end, db.ScanOptions{Limit: 1, Reverse: reverse})
+		if err != nil {
+			return Codec{}, err
+		}
+		for key := range page.Results {
+			codec, err := DetectKey(key, prefix, enc)
+			if err == nil {
+				return codec, nil
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if firstErr == nil {
+		return Codec{}, ErrNoKeys
+	}
+	return Codec{}, firstErr
+}
+
+// Range returns the entries of q in time order, oldest first unless
+// reversed. Keys without a time, or outside the prefix of the codec, are
+// skipped.
+func Range(s Scanner, q Query, opts Options) (*Page, error) {
+	page := &Page{Codec: q.Codec, Entries: []db.KeyValue{}, NextCursor: opts.After}
+	err := q.each(s, opts, func(key, value string, t time.Time) bool {
+		if opts.Limit > 0 && len(page.Entries) == opts.Limit {
+			page.HasMore = true
+			return false
+		}
+		page.Entries = append(page.Entries, entry(key, value, t))
+		page.NextCursor = Cursor(key)
+		return true
+	})
+	if errors.Is(err, errStopped) {
+		page.HasMore = true
+		return page, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// Latest returns the n newest entries of q, newest first
+func Latest(s Scanner, q Query, n int, values bool) (*Page, error) {
+	return Range(s, q, Options{Limit: n, Reverse: true, Values: values})
+}
+
+// NewHistogram counts the entries of q per bucket. Buckets are aligned on
+// multiples of the bucket width since the zero time, so 1h buckets start on
+// the hour and 24h ones at midnight UTC; they span From to To, or the first
+// to the last entry for open bounds. progress, if not nil, is called after
+// each batch of keys read; an error stops the scan, and the entries counted
+// so far are returned with HasMore set.
+func NewHistogram(s Scanner, q Query, bucket time.Duration, progress db.ProgressFunc) (*Histogram, error) {
+	if bucket <= 0 {
+		return nil, errors.New("bucket must be positive")
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && q.To.Sub(q.From)/bucket >= maxBuckets {
+		return nil, fmt.Errorf("more than %d buckets of %s from %s to %s; use a larger bucket", maxBuckets, bucket, q.From.Format(time.RFC3339), q.To.Format(time.RFC3339))
+	}
+
+	counts := make(map[time.Time]int64)
+	var first, last time.Time
+	h := &Histogram{Codec: q.Codec, Bucket: bucket.String(), Buckets: []Bucket{}}
+	err := q.each(s, Options{Progress: progress}, func(key, value string, t time.Time) bool {
+		start := t.Truncate(bucket)
+		if h.Total == 0 || start.Before(first) {
+			first = start
+		}
+		if h.Total == 0 || start.After(last) {
+			last = start
+		}
+		counts[start]++
+		h.Total++
+		return true
+	})
+	if errors.Is(err, errStopped) {
+		h.HasMore = true
+	} else if err != nil {
+		return nil, err
+	}
+
+	if !q.From.IsZero() {
+		first = q.From.UTC().Truncate(bucket)
+	}
+	if !q.To.IsZero() {
+		last = q.To.UTC().Add(-1).Truncate(bucket)
+	}
+	if h.Total == 0 && (q.From.IsZero() || q.To.IsZero()) {
+		return h, nil
+	}
+	if last.Sub(first)/bucket >= maxBuckets {
+		return nil, fmt.Errorf("more than %d buckets of %s from %s to %s; use a larger bucket", maxBuckets, bucket, first.Format(time.RFC3339), last.Format(time.RFC3339))
+	}
+	for start := first; !start.After(last); start = start.Add(bucket) {
+		h.Buckets = append(h.Buckets, Bucket{Start: start, Count: counts[start]})
+	}
+	return h, nil
+}
+
+// Follow calls fn with the entries of q written after the entry of the
+// cursor after, in time order, checking for new ones every interval until
+// ctx is done or fn fails. An empty cursor starts after the current newest
+// entry.
+func Follow(ctx context.Context, s Scanner, q Query, after string, interval time.Duration, values bool, fn func(db.KeyValue) error) error {
+	if after == "" {
+		newest, err := Latest(s, q, 1, false)
+		if err != nil {
+			return err
+		}
+		after = newest.NextCursor
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		page, err := Range(s, q, Options{Values: values, After: after})
+		if err != nil {
+			return err
+		}
+		for _, e := range page.Entries {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+		after = page.NextCursor
+	}
+}
+
+// Cursor returns the cursor of the entry of key, base64 like the cursors of
+// scans
+func Cursor(key string) string {
+	return base64.StdEncoding.EncodeToString([]byte(key))
+}
+
+// bounds returns the keys the entries of q are between
+func (q Query) bounds() (start, end []byte) {
+	prefix := []byte(q.Codec.Prefix)
+	if !q.From.IsZero() {
+		start = q.Codec.Key(q.From)
+	} else if len(prefix) > 0 {
+		start = prefix
+	}
+	if !q.To.IsZero() {
+		end = q.Codec.Key(q.To)
+	} else if len(prefix) > 0 {
+		end = keytree.PrefixEnd(prefix)
+	}
+	return start, end
+}
+
+func (q Query) contains(t time.Time) bool {
+	return (q.From.IsZero() || !t.Before(q.From)) && (q.To.IsZero() || t.Before(q.To))
+}
+
+// each calls fn with the entries of q in key order, reversed with
+// opts.Reverse, until fn returns false or opts.Progress fails, which returns
+// errStopped. Scans move their bounds past the
+// keys already read rather than passing cursors, so they work with any
+// cursor format of the scanner.
+func (q Query) each(s Scanner, opts Options, fn func(key, value string, t time.Time) bool) error {
+	if err := checkComparator(s, q.CF); err != nil {
+		return err
+	}
+	start, end := q.bounds()
+	if opts.After != "" {
+		after, err := base64.StdEncoding.DecodeString(opts.After)
+		if err != nil {
+			return fmt.Errorf("%w %q", ErrInvalidCursor, opts.After)
+		}
+		if opts.Reverse {
+			if end == nil || bytes.Compare(after, end) < 0 {
+				end = after
+			}
+		} else if next := append(after, 0); bytes.Compare(next, start) > 0 {
+			start = next
+		}
+	}
+
+	limit := batchSize
+	if opts.Limit > 0 {
+		// One more to tell whether there are more
+		limit = min(limit, opts.Limit+1)
+	}
+	var visited int64
+	for {
+		// Reversed scans without an end seek before their start, so the
+		// start is checked here
+		scanStart := start
+		if opts.Reverse && end == nil {
+			scanStart = nil
+		}
+		page, err := s.ScanCFPage(q.CF, scanStart, end, db.ScanOptions{Limit: limit, Reverse: opts.Reverse, Values: opts.Values})
+		if err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(page.Results))
+		for key := range page.Results {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		if opts.Reverse {
+			sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+		}
+
+		for _, key := range keys {
+			if opts.Reverse && start != nil && key < string(start) {
+				return nil
+			}
+			t, ok := q.Codec.Time(key)
+			if !ok || !q.contains(t) {
+				continue
+			}
+			if !fn(key, page.Results[key], t) {
+				return nil
+			}
+		}
+		if !page.HasMore || len(keys) == 0 {
+			return nil
+		}
+		last := keys[len(keys)-1]
+		visited += int64(len(keys))
+		if opts.Progress != nil && opts.Progress(visited, last) != nil {
+			return errStopped
+		}
+		if opts.Reverse {
+			end = []byte(last)
+		} else {
+			start = append([]byte(last), 0)
+		}
+	}
+}
+
+// checkComparator returns db.ErrNotBytewise if the scanner reports a
+// comparator other than the bytewise one for cf, like the reverse-bytewise
+// and uint64 plugins: time bounds and cursors are computed in bytewise order
+func checkComparator(s Scanner, cf string) error {
+	r, ok := s.(optionsReader)
+	if !ok {
+		return nil
+	}
+	// Unknown column families fail in the scan
+	opts, _ := r.GetCFOptions(cf)
+	return db.RequireBytewise(opts, cf)
+}
+
+// entry is the entry of key like in scan results, with the time of the key
+func entry(key, value string, t time.Time) db.KeyValue {
+	k, keyIsBinary := util.EncodeValue([]byte(key))
+	v, valueIsBinary := util.EncodeValue([]byte(value))
+	return db.KeyValue{
+		Key:           k,
+		Value:         v,
+		KeyIsBinary:   keyIsBinary,
+		ValueIsBinary: valueIsBinary,
+		Timestamp:     t.Format(time.RFC3339Nano),
+	}
+}
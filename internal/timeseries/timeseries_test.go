@@ -0,0 +1,305 @@
+package timeseries
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/util"
+)
+
+// fakeScanner scans sorted keys like RocksDB does
+type fakeScanner map[string]string
+
+func (f fakeScanner) ScanCFPage(cf string, start, end []byte, opts db.ScanOptions) (db.ScanPageResult, error) {
+	keys := make([]string, 0, len(f))
+	for k := range f {
+		if (len(start) == 0 || k >= string(start)) && (len(end) == 0 || k < string(end)) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	if opts.Reverse {
+		sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	}
+	page := db.ScanPageResult{Results: map[string]string{}}
+	if opts.Limit > 0 && len(keys) > opts.Limit {
+		keys, page.HasMore = keys[:opts.Limit], true
+	}
+	for _, k := range keys {
+		page.Results[k] = f[k]
+	}
+	return page, nil
+}
+
+var base = time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+// events has a decimal millisecond key every 10 minutes from 09:00 to
+// 11:50, and keys of another kind
+func events() fakeScanner {
+	f := fakeScanner{"meta:version": "1"}
+	for i := 0; i < 18; i++ {
+		t := base.Add(time.Duration(i) * 10 * time.Minute)
+		f["ev:"+strconv.FormatInt(t.UnixMilli(), 10)+":"+strconv.Itoa(i)] = strconv.Itoa(i)
+	}
+	return f
+}
+
+func TestDetectKey(t *testing.T) {
+	when := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	be := func(v int64) string { return string(binary.BigEndian.AppendUint64(nil, uint64(v))) }
+
+	tests := []struct {
+		name   string
+		key    string
+		prefix string
+		enc    Encoding
+		want   Codec
+		at     time.Time // when is the time of the key if zero
+	}{
+		{name: "binary ticks", key: be(util.TimeToTicks(when)), want: Codec{Encoding: Ticks, Binary: true}},
+		{name: "decimal ticks", key: strconv.FormatInt(util.TimeToTicks(when), 10), want: Codec{Encoding: Ticks}},
+		{name: "binary ms with suffix", key: be(when.UnixMilli()) + "\x01", want: Codec{Encoding: Millis, Binary: true}},
+		{name: "decimal ns", key: strconv.FormatInt(when.UnixNano(), 10), want: Codec{Encoding: Nanos}},
+		{name: "decimal s", key: strconv.FormatInt(when.Unix(), 10) + ":a", want: Codec{Encoding: Seconds}},
+		{name: "prefix", key: "ev:" + strconv.FormatInt(when.UnixMilli(), 10), prefix: "ev:", want: Codec{Encoding: Millis, Prefix: "ev:"}},
+		{name: "iso", key: "2024-03-01T10:30:00Z#1", want: Codec{Encoding: ISO, Layout: "2006-01-02T15:04:05.999999999"}},
+		{name: "iso with space", key: "2024-03-01 10:30:00.5", want: Codec{Encoding: ISO, Layout: "2006-01-02 15:04:05.999999999"}, at: when.Add(time.Second / 2)},
+		{name: "date", key: "2024-03-01/x", want: Codec{Encoding: ISO, Layout: "2006-01-02"}, at: when.Truncate(24 * time.Hour)},
+		{name: "forced encoding", key: strconv.FormatInt(when.UnixMilli(), 10), enc: Micros, want: Codec{Encoding: Micros}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectKey(tt.key, tt.prefix, tt.enc)
+			if err != nil {
+				t.Fatalf("DetectKey() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DetectKey() = %+v, want %+v", got, tt.want)
+			}
+			want := tt.at
+			if want.IsZero() {
+				want = when
+			}
+			if at, ok := got.Time(tt.key); tt.enc == "" && (!ok || !at.Equal(want)) {
+				t.Errorf("Time(%q) = %v, %v, want %v", tt.key, at, ok, want)
+			}
+		})
+	}
+
+	for _, key := range []string{"user:1", "12345", "2024-13-45T00:00:00Z"} {
+		if c, err := DetectKey(key, "", ""); err == nil {
+			t.Errorf("DetectKey(%q) = %+v, want an error", key, c)
+		}
+	}
+	if _, err := DetectKey("user:1", "", ISO); err == nil {
+		t.Error("DetectKey should fail for a key without the forced encoding")
+	}
+}
+
+func TestCodecKey(t *testing.T) {
+	when := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	for _, c := range []Codec{
+		{Encoding: Ticks, Binary: true},
+		{Encoding: Millis},
+		{Encoding: Nanos, Binary: true, Prefix: "ev:"},
+		{Encoding: ISO, Layout: "2006-01-02T15:04:05.999999999"},
+	} {
+		key := string(c.Key(when))
+		if got, ok := c.Time(key); !ok || !got.Equal(when) {
+			t.Errorf("%s: Time(Key(t)) = %v, %v, want %v", c, got, ok, when)
+		}
+	}
+	if got := string(Codec{Encoding: Seconds, Prefix: "a:"}.Key(when)); got != "a:1709289000" {
+		t.Errorf("Key() = %q, want a:1709289000", got)
+	}
+}
+
+func TestRange(t *testing.T) {
+	s := events()
+	codec, err := Detect(s, "events", "ev:", "")
+	if err != nil || codec != (Codec{Encoding: Millis, Prefix: "ev:"}) {
+		t.Fatalf("Detect() = %+v, %v", codec, err)
+	}
+	q := Query{CF: "events", Codec: codec, From: base.Add(time.Hour), To: base.Add(2 * time.Hour)}
+
+	page, err := Range(s, q, Options{Values: true})
+	if err != nil {
+		t.Fatalf("Range() error = %v", err)
+	}
+	if len(page.Entries) != 6 || page.HasMore {
+		t.Fatalf("Range() = %d entries, has more %v, want the 6 from 10:00 to 10:50", len(page.Entries), page.HasMore)
+	}
+	if first := page.Entries[0]; first.Value != "6" || first.Timestamp != "2024-03-01T10:00:00Z" {
+		t.Errorf("first entry = %+v, want 6 at 10:00", first)
+	}
+
+	// Pages continue after their cursor
+	page, _ = Range(s, q, Options{Limit: 4, Values: true})
+	if len(page.Entries) != 4 || !page.HasMore {
+		t.Fatalf("limited Range() = %d entries, has more %v", len(page.Entries), page.HasMore)
+	}
+	page, _ = Range(s, q, Options{Limit: 4, Values: true, After: page.NextCursor})
+	if len(page.Entries) != 2 || page.HasMore || page.Entries[0].Value != "10" {
+		t.Errorf("next page = %+v, want 10 and 11", page.Entries)
+	}
+
+	// Reversed without an end
+	page, _ = Range(s, Query{CF: "events", Codec: codec, From: base.Add(150 * time.Minute)}, Options{Reverse: true, Values: true})
+	if len(page.Entries) != 3 || page.Entries[0].Value != "17" || page.Entries[2].Value != "15" {
+		t.Errorf("reversed Range() = %+v, want 17, 16 and 15", page.Entries)
+	}
+
+	latest, _ := Latest(s, Query{CF: "events", Codec: codec}, 2, true)
+	if len(latest.Entries) != 2 || latest.Entries[0].Value != "17" || !latest.HasMore {
+		t.Errorf("Latest() = %+v, want 17 and 16", latest)
+	}
+
+	if _, err := Detect(fakeScanner{}, "empty", "", ""); !errors.Is(err, ErrNoKeys) {
+		t.Errorf("Detect() on no keys error = %v, want ErrNoKeys", err)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	s := events()
+	codec := Codec{Encoding: Millis, Prefix: "ev:"}
+
+	h, err := NewHistogram(s, Query{CF: "events", Codec: codec}, time.Hour, nil)
+	if err != nil {
+		t.Fatalf("NewHistogram() error = %v", err)
+	}
+	if h.Total != 18 || len(h.Buckets) != 3 || h.Buckets[0] != (Bucket{Start: base, Count: 6}) {
+		t.Errorf("NewHistogram() = %+v, want 3 buckets of 6", h)
+	}
+
+	// Bounds add empty buckets
+	q := Query{CF: "events", Codec: codec, From: base.Add(-time.Hour), To: base.Add(4 * time.Hour).In(time.Local)}
+	h, _ = NewHistogram(s, q, time.Hour, nil)
+	if len(h.Buckets) != 5 || h.Buckets[0].Count != 0 || h.Buckets[4].Count != 0 || h.Total != 18 {
+		t.Errorf("bounded NewHistogram() = %+v, want 5 buckets", h.Buckets)
+	}
+
+	q = Query{CF: "events", Codec: codec, From: base, To: base.Add(24 * time.Hour)}
+	if _, err := NewHistogram(s, q, time.Second, nil); err == nil {
+		t.Error("NewHistogram() should refuse too many buckets")
+	}
+}
+
+func TestProgressStops(t *testing.T) {
+	// Three batches of keys, one per second
+	s := fakeScanner{}
+	for i := 0; i < 2500; i++ {
+		s[strconv.FormatInt(base.Add(time.Duration(i)*time.Second).Unix(), 10)] = strconv.Itoa(i)
+	}
+	q := Query{CF: "events", Codec: Codec{Encoding: Seconds}}
+	stop := errors.New("stop")
+	var calls []int64
+	progress := func(visited int64, lastKey string) error {
+		calls = append(calls, visited)
+		return stop
+	}
+
+	page, err := Range(s, q, Options{Progress: progress})
+	if err != nil {
+		t.Fatalf("Range() error = %v", err)
+	}
+	if len(page.Entries) != batchSize || !page.HasMore || page.NextCursor != Cursor(strconv.FormatInt(base.Add(999*time.Second).Unix(), 10)) {
+		t.Errorf("stopped Range() = %d entries, has more %v, want the first batch", len(page.Entries), page.HasMore)
+	}
+	page, _ = Range(s, q, Options{After: page.NextCursor})
+	if len(page.Entries) != 1500 || page.Entries[0].Value != "1000" {
+		t.Errorf("resumed Range() = %d entries, want the 1500 after the first batch", len(page.Entries))
+	}
+
+	h, err := NewHistogram(s, q, time.Hour, progress)
+	if err != nil || !h.HasMore || h.Total != batchSize {
+		t.Errorf("stopped NewHistogram() = %+v, %v, want the first batch counted", h, err)
+	}
+	if len(calls) != 2 || calls[0] != batchSize {
+		t.Errorf("progress calls = %v, want one per stopped scan", calls)
+	}
+}
+
+// reverseScanner reports the reverse-bytewise comparator for its keys
+type reverseScanner struct {
+	fakeScanner
+}
+
+func (r reverseScanner) GetCFOptions(cf string) (*db.CFOptions, error) {
+	return &db.CFOptions{Name: cf, Comparator: "rocksdb.ReverseBytewiseComparator"}, nil
+}
+
+func TestReverseComparator(t *testing.T) {
+	s := reverseScanner{events()}
+	q := Query{CF: "events", Codec: Codec{Encoding: Millis, Prefix: "ev:"}, From: base.Add(time.Hour)}
+
+	if _, err := Range(s, q, Options{}); !errors.Is(err, db.ErrNotBytewise) || !strings.Contains(err.Error(), "'events' uses rocksdb.ReverseBytewiseComparator") {
+		t.Errorf("Range() error = %v, want ErrNotBytewise", err)
+	}
+	if _, err := NewHistogram(s, q, time.Hour, nil); !errors.Is(err, db.ErrNotBytewise) {
+		t.Errorf("NewHistogram() error = %v, want ErrNotBytewise", err)
+	}
+	if _, err := Range(events(), q, Options{}); err != nil {
+		t.Errorf("Range() without options error = %v", err)
+	}
+}
+
+func TestFollow(t *testing.T) {
+	s := events()
+	q := Query{CF: "events", Codec: Codec{Encoding: Millis, Prefix: "ev:"}}
+	page, _ := Range(s, q, Options{Limit: 15})
+
+	errStop := errors.New("stop")
+	var got []string
+	err := Follow(context.Background(), s, q, page.NextCursor, time.Millisecond, true, func(e db.KeyValue) error {
+		got = append(got, e.Value)
+		if len(got) == 3 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) || len(got) != 3 || got[0] != "15" || got[2] != "17" {
+		t.Errorf("Follow() = %v, %v, want 15, 16 and 17", got, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Follow(ctx, s, q, "", time.Millisecond, false, func(db.KeyValue) error { return errStop }); err != nil {
+		t.Errorf("Follow() after cancel = %v, want nil", err)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := map[string]time.Time{
+		"now":                       now,
+		"2024-03-01T10:00":          time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		"2024-03-01T10:00:00+02:00": time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC),
+		"2024-02-28":                time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC),
+		"90m":                       now.Add(-90 * time.Minute),
+		"7d":                        now.AddDate(0, 0, -7),
+	}
+	for in, want := range tests {
+		if got, err := ParseTime(in, now); err != nil || !got.Equal(want) {
+			t.Errorf("ParseTime(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseTime("yesterday", now); err == nil {
+		t.Error("ParseTime(yesterday) should fail")
+	}
+
+	if d, err := ParseBucket("7d"); err != nil || d != 7*24*time.Hour {
+		t.Errorf("ParseBucket(7d) = %v, %v", d, err)
+	}
+	for _, in := range []string{"0s", "-1h", "xd", "soon"} {
+		if _, err := ParseBucket(in); err == nil {
+			t.Errorf("ParseBucket(%q) should fail", in)
+		}
+	}
+}
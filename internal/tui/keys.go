@@ -0,0 +1,96 @@
+package tui
+
+import "unicode/utf8"
+
+// KeyCode identifies a key that is not a printable character
+type KeyCode int
+
+const (
+	KeyRune KeyCode = iota // a printable character, see Key.Rune
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyPgUp
+	KeyPgDn
+	KeyHome
+	KeyEnd
+	KeyEnter
+	KeyTab
+	KeyBacktab
+	KeyBackspace
+	KeyEsc
+	KeyCtrlC
+	KeyCtrlU
+)
+
+// Key is a key press
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+// escapes maps the escape sequences of common terminals to keys
+var escapes = map[string]KeyCode{
+	"[A": KeyUp, "[B": KeyDown, "[C": KeyRight, "[D": KeyLeft,
+	"OA": KeyUp, "OB": KeyDown, "OC": KeyRight, "OD": KeyLeft,
+	"[5~": KeyPgUp, "[6~": KeyPgDn,
+	"[H": KeyHome, "[F": KeyEnd, "OH": KeyHome, "OF": KeyEnd,
+	"[1~": KeyHome, "[4~": KeyEnd, "[7~": KeyHome, "[8~": KeyEnd,
+	"[Z": KeyBacktab,
+}
+
+// decodeKeys returns the keys in input read from a terminal in raw mode.
+// An escape that does not start a sequence is the Esc key; sequences of
+// keys the browser does not use are dropped.
+func decodeKeys(input []byte) []Key {
+	var keys []Key
+	for len(input) > 0 {
+		b := input[0]
+		switch {
+		case b == 0x1b:
+			key, n, ok := decodeEscape(input[1:])
+			if ok {
+				keys = append(keys, key)
+			}
+			input = input[1+n:]
+			continue
+		case b == '\r' || b == '\n':
+			keys = append(keys, Key{Code: KeyEnter})
+		case b == '\t':
+			keys = append(keys, Key{Code: KeyTab})
+		case b == 0x7f || b == 0x08:
+			keys = append(keys, Key{Code: KeyBackspace})
+		case b == 0x03:
+			keys = append(keys, Key{Code: KeyCtrlC})
+		case b == 0x15:
+			keys = append(keys, Key{Code: KeyCtrlU})
+		case b < 0x20:
+			// Other control keys are ignored
+		default:
+			r, n := utf8.DecodeRune(input)
+			keys = append(keys, Key{Code: KeyRune, Rune: r})
+			input = input[n:]
+			continue
+		}
+		input = input[1:]
+	}
+	return keys
+}
+
+// decodeEscape decodes the sequence after an escape and returns how many
+// bytes of input it used, with ok false for unknown sequences
+func decodeEscape(input []byte) (Key, int, bool) {
+	if len(input) < 2 || (input[0] != '[' && input[0] != 'O') {
+		return Key{Code: KeyEsc}, 0, true
+	}
+	// A sequence ends at its first letter or ~
+	for n := 1; n < len(input) && n < 8; n++ {
+		c := input[n]
+		if c == '~' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') {
+			code, ok := escapes[string(input[:n+1])]
+			return Key{Code: code}, n + 1, ok
+		}
+	}
+	return Key{Code: KeyEsc}, 0, true
+}
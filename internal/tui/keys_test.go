@@ -0,0 +1,26 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeKeys(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []Key
+	}{
+		{"jq", []Key{{Code: KeyRune, Rune: 'j'}, {Code: KeyRune, Rune: 'q'}}},
+		{"\x1b[A\x1b[B\x1bOC", []Key{{Code: KeyUp}, {Code: KeyDown}, {Code: KeyRight}}},
+		{"\x1b[5~\x1b[6~\x1b[H\x1b[4~", []Key{{Code: KeyPgUp}, {Code: KeyPgDn}, {Code: KeyHome}, {Code: KeyEnd}}},
+		{"\x1b", []Key{{Code: KeyEsc}}},
+		{"\x1bx", []Key{{Code: KeyEsc}, {Code: KeyRune, Rune: 'x'}}},
+		{"\r\t\x1b[Z\x7f\x03\x15", []Key{{Code: KeyEnter}, {Code: KeyTab}, {Code: KeyBacktab}, {Code: KeyBackspace}, {Code: KeyCtrlC}, {Code: KeyCtrlU}}},
+		{"é\x1b[15~a", []Key{{Code: KeyRune, Rune: 'é'}, {Code: KeyRune, Rune: 'a'}}},
+	}
+	for _, tt := range tests {
+		if got := decodeKeys([]byte(tt.input)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("decodeKeys(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,553 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+
+	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/util"
+)
+
+// pageSize is the number of keys loaded at a time; more pages are loaded as
+// the selection reaches the end of the loaded keys
+const pageSize = 100
+
+// pane is a part of the screen that takes the arrow keys
+type pane int
+
+const (
+	cfPane pane = iota
+	keyPane
+	valuePane
+	paneCount
+)
+
+// entry is a key of the key list
+type entry struct {
+	key     string // raw key, as stored
+	display string // as shown, with binary keys encoded like scan does
+}
+
+// searchPage is a page of search results read in the background, so typing
+// in the search bar does not wait for SearchCF
+type searchPage struct {
+	id      int // pages of searches replaced since are dropped
+	cf      string
+	opts    db.SearchOptions
+	cancel  chan struct{} // closed to stop the search
+	visited atomic.Int64  // keys visited so far
+}
+
+// searchResult is the answer to a searchPage
+type searchResult struct {
+	id      int
+	results *db.SearchResults
+	err     error
+}
+
+// errSearchCancelled stops a search that is no longer wanted
+var errSearchCancelled = errors.New("search cancelled")
+
+// runSearch reads p, stopping early once p.cancel is closed
+func runSearch(database db.KeyValueDB, p *searchPage) searchResult {
+	opts := p.opts
+	opts.Progress = func(visited int64, _ string) error {
+		p.visited.Store(visited)
+		select {
+		case <-p.cancel:
+			return errSearchCancelled
+		default:
+			return nil
+		}
+	}
+	results, err := database.SearchCF(p.cf, opts)
+	return searchResult{id: p.id, results: results, err: err}
+}
+
+// prompt reads a line of input at the bottom of the screen
+type prompt struct {
+	label string
+	text  []rune
+	done  func(m *model, text string)
+}
+
+// model is the state of the browser; update changes it and view draws it
+type model struct {
+	db db.KeyValueDB
+
+	cfs   []string
+	cf    int // index of the current column family in cfs
+	focus pane
+
+	// entries is the part of the key list loaded so far
+	entries  []entry
+	next     string // cursor of the next page
+	hasMore  bool
+	selected int
+	top      int // first key shown
+
+	// search filters the key list with SearchCF, by key or by value
+	search      []rune
+	searchValue bool
+	searching   bool // the search bar takes the keys
+	searchID    int  // counts the pages asked for, see searchPage
+	// searchWanted is the page Run starts next, searchRunning the one it
+	// waits for. One search runs at a time; a new one cancels the last.
+	searchWanted  *searchPage
+	searchRunning *searchPage
+
+	value    string // value of the selected key
+	valueErr error
+	hex      bool // show values as a hex dump
+	valueTop int  // first line of the value shown
+
+	stats        *db.CFStats
+	statsErr     error
+	refreshStats bool // the stats of the current column family are wanted
+
+	prompt *prompt
+	status string
+	width  int
+	height int
+	quit   bool
+}
+
+// newModel returns a browser of database showing the keys of cf, the first
+// column family when cf is empty
+func newModel(database db.KeyValueDB, cf string, width, height int) (*model, error) {
+	cfs, err := database.ListCFs()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(cfs)
+
+	m := &model{db: database, cfs: cfs, focus: keyPane, width: width, height: height, refreshStats: true}
+	if cf != "" {
+		m.cf = sort.SearchStrings(cfs, cf)
+		if m.cf == len(cfs) || cfs[m.cf] != cf {
+			return nil, fmt.Errorf("column family '%s' does not exist", cf)
+		}
+	}
+	m.reload()
+	return m, nil
+}
+
+// currentCF returns the name of the column family shown
+func (m *model) currentCF() string {
+	if len(m.cfs) == 0 {
+		return ""
+	}
+	return m.cfs[m.cf]
+}
+
+// resize sets the size of the screen
+func (m *model) resize(width, height int) {
+	m.width, m.height = width, height
+	m.scrollToSelected()
+}
+
+// listHeight is the number of keys shown at a time
+func (m *model) listHeight() int {
+	return max(m.height-4, 1)
+}
+
+// reload loads the first page of the key list again
+func (m *model) reload() {
+	m.cancelSearch()
+	m.entries, m.next, m.hasMore = nil, "", false
+	m.selected, m.top = 0, 0
+	m.loadMore()
+	m.loadValue()
+}
+
+// loadMore loads the next page of the key list from a scan, or asks for the
+// next page of the search, which is added by setSearch
+func (m *model) loadMore() {
+	cf := m.currentCF()
+	if cf == "" {
+		return
+	}
+
+	if len(m.search) > 0 {
+		if m.searchPending() {
+			return
+		}
+		opts := db.SearchOptions{Limit: pageSize, KeysOnly: true, After: m.next}
+		if m.searchValue {
+			opts.ValuePattern = string(m.search)
+		} else {
+			opts.KeyPattern = string(m.search)
+		}
+		m.searchID++
+		m.searchWanted = &searchPage{id: m.searchID, cf: cf, opts: opts, cancel: make(chan struct{})}
+		return
+	}
+
+	page, err := m.db.ScanCFPage(cf, nil, nil, db.ScanOptions{Limit: pageSize, StartAfter: m.next})
+	if err != nil {
+		m.status = fmt.Sprintf("Scan failed: %v", err)
+		m.hasMore = false
+		return
+	}
+	m.next, m.hasMore = page.NextCursor, page.HasMore
+	m.entries = append(m.entries, rawEntries(page)...)
+}
+
+// rawEntries pairs the entries of a scan page with the raw keys Results is
+// keyed by. An 8-byte key can be shown like a printable key, such as
+// "0 (0x0)"; keys shown alike are taken in bytewise order.
+func rawEntries(page db.ScanPageResult) []entry {
+	raw := make(map[string][]string, len(page.Results))
+	for key := range page.Results {
+		encoded, _ := util.EncodeValue([]byte(key))
+		raw[encoded] = append(raw[encoded], key)
+	}
+	for _, keys := range raw {
+		sort.Strings(keys)
+	}
+
+	entries := make([]entry, 0, len(page.ResultsV2))
+	for _, kv := range page.ResultsV2 {
+		keys := raw[kv.Key]
+		if len(keys) == 0 {
+			continue
+		}
+		entries = append(entries, entry{key: keys[0], display: kv.Key})
+		raw[kv.Key] = keys[1:]
+	}
+	return entries
+}
+
+// searchPending reports whether a page of the current search is on its way
+func (m *model) searchPending() bool {
+	return m.searchWanted != nil || (m.searchRunning != nil && m.searchRunning.id == m.searchID)
+}
+
+// cancelSearch drops the pages of the search asked for so far and stops
+// the one being read; it stays in searchRunning until Run gets its result
+func (m *model) cancelSearch() {
+	m.searchID++
+	m.searchWanted = nil
+	if p := m.searchRunning; p != nil {
+		select {
+		case <-p.cancel:
+		default:
+			close(p.cancel)
+		}
+	}
+}
+
+// startSearch returns the page of the search to read in the background, nil
+// when there is none or another page is still being read
+func (m *model) startSearch() *searchPage {
+	if m.searchWanted == nil || m.searchRunning != nil {
+		return nil
+	}
+	m.searchRunning, m.searchWanted = m.searchWanted, nil
+	return m.searchRunning
+}
+
+// setSearch adds a page of search results read in the background, unless
+// the search was replaced or cancelled since
+func (m *model) setSearch(r searchResult) {
+	m.searchRunning = nil
+	if r.id != m.searchID {
+		return
+	}
+	if r.err != nil {
+		m.status = fmt.Sprintf("Search failed: %v", r.err)
+		m.hasMore = false
+		return
+	}
+	first := len(m.entries) == 0
+	for _, sr := range r.results.Results {
+		m.entries = append(m.entries, entry{key: sr.RawKey, display: sr.Key})
+	}
+	m.next, m.hasMore = r.results.NextCursor, r.results.HasMore
+	if first {
+		m.loadValue()
+	}
+}
+
+// selectedEntry returns the selected key, false when the list is empty
+func (m *model) selectedEntry() (entry, bool) {
+	if m.selected >= len(m.entries) {
+		return entry{}, false
+	}
+	return m.entries[m.selected], true
+}
+
+// loadValue reads the value of the selected key
+func (m *model) loadValue() {
+	m.valueTop = 0
+	e, ok := m.selectedEntry()
+	if !ok {
+		m.value, m.valueErr = "", nil
+		return
+	}
+	m.value, m.valueErr = m.db.GetCF(m.currentCF(), e.key)
+}
+
+// selectCF shows the keys and stats of column family i
+func (m *model) selectCF(i int) {
+	if i < 0 || i >= len(m.cfs) || i == m.cf {
+		return
+	}
+	m.cf = i
+	m.search, m.searching = nil, false
+	m.stats, m.statsErr, m.refreshStats = nil, nil, true
+	m.reload()
+}
+
+// setStats shows the stats of cf, computed in the background
+func (m *model) setStats(cf string, stats *db.CFStats, err error) {
+	if cf != m.currentCF() {
+		return
+	}
+	m.stats, m.statsErr = stats, err
+}
+
+// moveKey moves the selection of the key list by delta keys, loading more
+// pages when it reaches the end of the loaded keys
+func (m *model) moveKey(delta int) {
+	target := m.selected + delta
+	for target >= len(m.entries) && m.hasMore {
+		loaded := len(m.entries)
+		m.loadMore()
+		if len(m.entries) == loaded {
+			break
+		}
+	}
+	target = max(min(target, len(m.entries)-1), 0)
+	if target == m.selected {
+		return
+	}
+	m.selected = target
+	m.scrollToSelected()
+	m.loadValue()
+}
+
+func (m *model) scrollToSelected() {
+	height := m.listHeight()
+	if m.selected < m.top {
+		m.top = m.selected
+	} else if m.selected >= m.top+height {
+		m.top = m.selected - height + 1
+	}
+}
+
+// move moves within the focused pane
+func (m *model) move(delta int) {
+	switch m.focus {
+	case cfPane:
+		m.selectCF(max(min(m.cf+delta, len(m.cfs)-1), 0))
+	case keyPane:
+		m.moveKey(delta)
+	case valuePane:
+		m.valueTop = max(m.valueTop+delta, 0)
+	}
+}
+
+// update handles a key press
+func (m *model) update(key Key) {
+	if key.Code == KeyCtrlC {
+		m.quit = true
+		return
+	}
+	if m.prompt != nil {
+		m.updatePrompt(key)
+		return
+	}
+	if m.searching {
+		m.updateSearch(key)
+		return
+	}
+	m.status = ""
+
+	page := m.listHeight()
+	switch key.Code {
+	case KeyUp:
+		m.move(-1)
+	case KeyDown:
+		m.move(1)
+	case KeyPgUp:
+		m.move(-page)
+	case KeyPgDn:
+		m.move(page)
+	case KeyHome:
+		m.moveToStart()
+	case KeyEnd:
+		m.moveToEnd()
+	case KeyTab, KeyRight:
+		m.focus = (m.focus + 1) % paneCount
+	case KeyBacktab, KeyLeft:
+		m.focus = (m.focus + paneCount - 1) % paneCount
+	case KeyEnter:
+		if m.focus < valuePane {
+			m.focus++
+		}
+	case KeyEsc:
+		if len(m.search) > 0 {
+			m.search = nil
+			m.reload()
+		}
+	case KeyRune:
+		m.updateRune(key.Rune)
+	}
+}
+
+// moveToStart moves to the start of the focused pane
+func (m *model) moveToStart() {
+	switch m.focus {
+	case cfPane:
+		m.selectCF(0)
+	case keyPane:
+		m.moveKey(-m.selected)
+	case valuePane:
+		m.valueTop = 0
+	}
+}
+
+// moveToEnd moves to the last loaded key; the key list is not read to its
+// end, which could take long
+func (m *model) moveToEnd() {
+	switch m.focus {
+	case cfPane:
+		m.selectCF(len(m.cfs) - 1)
+	case keyPane:
+		m.moveKey(len(m.entries) - 1 - m.selected)
+	case valuePane:
+		m.valueTop = max(len(m.valueLines(m.valueWidth()))-m.listHeight(), 0)
+	}
+}
+
+func (m *model) updateRune(r rune) {
+	switch r {
+	case 'q':
+		m.quit = true
+	case 'j':
+		m.update(Key{Code: KeyDown})
+	case 'k':
+		m.update(Key{Code: KeyUp})
+	case 'l':
+		m.update(Key{Code: KeyRight})
+	case 'h':
+		m.update(Key{Code: KeyLeft})
+	case 'g':
+		m.update(Key{Code: KeyHome})
+	case 'G':
+		m.update(Key{Code: KeyEnd})
+	case '/', '?':
+		m.search, m.searchValue, m.searching = nil, r == '?', true
+		m.focus = keyPane
+	case 'x':
+		m.hex = !m.hex
+		m.valueTop = 0
+	case 'r':
+		m.reload()
+		m.refreshStats = true
+	case 's':
+		m.refreshStats = true
+		m.status = "Refreshing stats..."
+	case 'e':
+		m.editValue()
+	case 'a':
+		m.addKey()
+	}
+}
+
+// updateSearch edits the search pattern; the key list follows each change
+func (m *model) updateSearch(key Key) {
+	switch key.Code {
+	case KeyRune:
+		m.search = append(m.search, key.Rune)
+	case KeyBackspace:
+		if len(m.search) == 0 {
+			return
+		}
+		m.search = m.search[:len(m.search)-1]
+	case KeyCtrlU:
+		m.search = nil
+	case KeyEnter:
+		m.searching = false
+		return
+	case KeyEsc:
+		m.searching = false
+		m.search = nil
+	default:
+		return
+	}
+	m.reload()
+}
+
+func (m *model) updatePrompt(key Key) {
+	p := m.prompt
+	switch key.Code {
+	case KeyRune:
+		p.text = append(p.text, key.Rune)
+	case KeyBackspace:
+		if len(p.text) > 0 {
+			p.text = p.text[:len(p.text)-1]
+		}
+	case KeyCtrlU:
+		p.text = nil
+	case KeyEsc:
+		m.prompt = nil
+		m.status = "Cancelled"
+	case KeyEnter:
+		m.prompt = nil
+		p.done(m, string(p.text))
+	}
+}
+
+// writable reports whether the database takes writes, and says why not
+func (m *model) writable() bool {
+	if m.db.IsReadOnly() {
+		m.status = "Read-only mode: editing is disabled"
+		return false
+	}
+	return true
+}
+
+// editValue edits the value of the selected key in a prompt
+func (m *model) editValue() {
+	e, ok := m.selectedEntry()
+	if !ok || !m.writable() {
+		return
+	}
+	if m.valueErr != nil || !util.IsPrintable([]byte(m.value)) {
+		m.status = "Only text values can be edited"
+		return
+	}
+	m.prompt = &prompt{label: "Value of " + e.display + ": ", text: []rune(m.value), done: func(m *model, value string) {
+		m.put(e.key, e.display, value)
+	}}
+}
+
+// addKey reads a key and its value in prompts and puts them
+func (m *model) addKey() {
+	if !m.writable() {
+		return
+	}
+	m.prompt = &prompt{label: "New key: ", done: func(m *model, key string) {
+		if key == "" {
+			m.status = "Cancelled"
+			return
+		}
+		m.prompt = &prompt{label: "Value of " + key + ": ", done: func(m *model, value string) {
+			m.put(key, key, value)
+			m.reload()
+		}}
+	}}
+}
+
+func (m *model) put(key, display, value string) {
+	if err := m.db.PutCF(m.currentCF(), key, value); err != nil {
+		m.status = fmt.Sprintf("Put failed: %v", err)
+		return
+	}
+	m.status = fmt.Sprintf("Saved %s", display)
+	m.loadValue()
+}
@@ -0,0 +1,313 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"rocksdb-cli/internal/db"
+	"rocksdb-cli/internal/util"
+)
+
+// fakeDB implements the methods of db.KeyValueDB the browser uses
+type fakeDB struct {
+	db.KeyValueDB
+	data     map[string]map[string]string
+	readOnly bool
+}
+
+func newFakeDB() *fakeDB {
+	f := &fakeDB{data: map[string]map[string]string{"default": {}, "users": {}}}
+	for i := 1; i <= 250; i++ {
+		f.data["users"][fmt.Sprintf("user:%03d", i)] = fmt.Sprintf(`{"id":%d,"profile":"{\"city\":\"Oslo\"}"}`, i)
+	}
+	f.data["default"]["\x00\x00\x00\x00\x00\x00\x01\x00"] = "\xff\xfe"
+	return f
+}
+
+func (f *fakeDB) ListCFs() ([]string, error) {
+	var cfs []string
+	for cf := range f.data {
+		cfs = append(cfs, cf)
+	}
+	return cfs, nil
+}
+
+func (f *fakeDB) sortedKeys(cf string) []string {
+	var keys []string
+	for k := range f.data[cf] {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (f *fakeDB) ScanCFPage(cf string, start, end []byte, opts db.ScanOptions) (db.ScanPageResult, error) {
+	page := db.ScanPageResult{Results: map[string]string{}}
+	for _, k := range f.sortedKeys(cf) {
+		if k <= opts.StartAfter {
+			continue
+		}
+		if len(page.ResultsV2) == opts.Limit {
+			page.HasMore = true
+			break
+		}
+		key, binary := util.EncodeValue([]byte(k))
+		page.Results[k] = ""
+		page.ResultsV2 = append(page.ResultsV2, db.KeyValue{Key: key, KeyIsBinary: binary})
+		page.NextCursor = k
+	}
+	return page, nil
+}
+
+func (f *fakeDB) SearchCF(cf string, opts db.SearchOptions) (*db.SearchResults, error) {
+	results := &db.SearchResults{}
+	for _, k := range f.sortedKeys(cf) {
+		matched := (opts.KeyPattern != "" && strings.Contains(k, opts.KeyPattern)) ||
+			(opts.ValuePattern != "" && strings.Contains(f.data[cf][k], opts.ValuePattern))
+		if k <= opts.After || !matched {
+			continue
+		}
+		if len(results.Results) == opts.Limit {
+			results.HasMore = true
+			break
+		}
+		key, binary := util.EncodeValue([]byte(k))
+		results.Results = append(results.Results, db.SearchResult{Key: key, KeyIsBinary: binary, RawKey: k})
+		results.NextCursor = k
+	}
+	return results, nil
+}
+
+func (f *fakeDB) GetCF(cf, key string) (string, error) {
+	value, ok := f.data[cf][key]
+	if !ok {
+		return "", db.ErrKeyNotFound
+	}
+	return value, nil
+}
+
+func (f *fakeDB) PutCF(cf, key, value string) error {
+	if f.readOnly {
+		return db.ErrReadOnlyMode
+	}
+	f.data[cf][key] = value
+	return nil
+}
+
+func (f *fakeDB) IsReadOnly() bool { return f.readOnly }
+
+var ansi = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// screen returns the screen of m without styles
+func screen(m *model) string {
+	return ansi.ReplaceAllString(strings.Join(m.view(), "\n"), "")
+}
+
+func typeKeys(m *model, keys ...Key) {
+	for _, key := range keys {
+		m.update(key)
+	}
+}
+
+func typeText(m *model, text string) {
+	for _, r := range text {
+		m.update(Key{Code: KeyRune, Rune: r})
+	}
+}
+
+// finishSearch reads the pages of the search asked for, which Run does in
+// the background
+func finishSearch(m *model) {
+	for p := m.startSearch(); p != nil; p = m.startSearch() {
+		m.setSearch(runSearch(m.db, p))
+	}
+}
+
+func newTestModel(t *testing.T, f *fakeDB) *model {
+	t.Helper()
+	m, err := newModel(f, "users", 120, 30)
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	return m
+}
+
+func TestBrowse(t *testing.T) {
+	m := newTestModel(t, newFakeDB())
+
+	out := screen(m)
+	if !strings.Contains(out, "Keys (100+)") || !strings.Contains(out, " user:001") {
+		t.Errorf("the first page of keys should be loaded:\n%s", out)
+	}
+	if !strings.Contains(out, `"city": "Oslo"`) {
+		t.Errorf("the value should be shown with nested JSON expanded:\n%s", out)
+	}
+	for _, line := range m.view() {
+		if width := len([]rune(ansi.ReplaceAllString(line, ""))); width != 120 {
+			t.Fatalf("every line should be 120 columns wide, got %d: %q", width, line)
+		}
+	}
+
+	// Moving past the loaded keys loads the next pages
+	typeKeys(m, Key{Code: KeyPgDn}, Key{Code: KeyPgDn}, Key{Code: KeyPgDn}, Key{Code: KeyPgDn})
+	if m.selected != 104 || len(m.entries) != 200 {
+		t.Errorf("selected %d with %d keys loaded, want 104 of 200", m.selected, len(m.entries))
+	}
+	if out := screen(m); !strings.Contains(out, " user:105") || !strings.Contains(out, `"id": 105`) {
+		t.Errorf("the selected key should be shown with its value:\n%s", out)
+	}
+	typeText(m, "g")
+	if m.selected != 0 || m.top != 0 {
+		t.Errorf("g should go to the first key, got %d", m.selected)
+	}
+}
+
+func TestColumnFamilies(t *testing.T) {
+	m := newTestModel(t, newFakeDB())
+	m.setStats("users", &db.CFStats{Name: "users", KeyCount: 250, TotalValueSize: 4096}, nil)
+	if out := screen(m); !strings.Contains(out, " 250 keys") || !strings.Contains(out, "values 4.0 KB") {
+		t.Errorf("the stats should be shown:\n%s", out)
+	}
+
+	// Focus the column families and select default
+	m.refreshStats = false
+	typeKeys(m, Key{Code: KeyLeft}, Key{Code: KeyUp})
+	if m.currentCF() != "default" || !m.refreshStats || m.stats != nil {
+		t.Fatalf("up should select default and ask for its stats, got %s", m.currentCF())
+	}
+	m.setStats("users", &db.CFStats{Name: "users"}, nil)
+	if m.stats != nil {
+		t.Errorf("stats of another column family should be ignored")
+	}
+
+	// The binary key is shown as a number, and its value as hex
+	out := screen(m)
+	if !strings.Contains(out, " 256 (0x100)") || !strings.Contains(out, "00000000  ff fe") {
+		t.Errorf("binary keys and values should be readable:\n%s", out)
+	}
+	if m.value != "\xff\xfe" {
+		t.Errorf("the value of the binary key should be read with the raw key, got %q", m.value)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	m := newTestModel(t, newFakeDB())
+
+	typeText(m, "/user:24")
+	finishSearch(m)
+	if len(m.entries) != 10 || m.entries[0].display != "user:240" {
+		t.Errorf("the key list should follow the search, got %d keys", len(m.entries))
+	}
+	typeKeys(m, Key{Code: KeyBackspace})
+	finishSearch(m)
+	if len(m.entries) != 51 {
+		t.Errorf("/user:2 should match 51 keys, got %d", len(m.entries))
+	}
+	typeKeys(m, Key{Code: KeyEnter})
+	if m.searching || len(m.search) == 0 {
+		t.Fatalf("Enter should keep the search and close the bar")
+	}
+	if out := screen(m); !strings.Contains(out, `Keys with key matching "user:2" (51)`) {
+		t.Errorf("the title should show the search:\n%s", out)
+	}
+	typeKeys(m, Key{Code: KeyEsc})
+	if len(m.search) != 0 || len(m.entries) != 100 {
+		t.Errorf("Esc should clear the search, got %d keys", len(m.entries))
+	}
+
+	typeText(m, `?"id":7,`)
+	finishSearch(m)
+	if len(m.entries) != 1 || m.entries[0].display != "user:007" {
+		t.Errorf("? should search values, got %v", m.entries)
+	}
+}
+
+func TestSearchInBackground(t *testing.T) {
+	m := newTestModel(t, newFakeDB())
+
+	typeText(m, "/user:1")
+	p := m.startSearch()
+	if p == nil {
+		t.Fatal("typing should ask for a search")
+	}
+	if out := screen(m); !strings.Contains(out, "(searching, Esc cancels)") {
+		t.Errorf("the key list should show the search is running:\n%s", out)
+	}
+
+	typeText(m, "0")
+	if m.startSearch() != nil {
+		t.Errorf("a search should not start while another is read")
+	}
+	select {
+	case <-p.cancel:
+	default:
+		t.Errorf("typing should cancel the running search")
+	}
+	m.setSearch(runSearch(m.db, p))
+	if len(m.entries) != 0 {
+		t.Errorf("the results of a replaced search should be dropped, got %d keys", len(m.entries))
+	}
+
+	finishSearch(m)
+	if len(m.entries) != 10 || m.entries[0].display != "user:100" {
+		t.Errorf("/user:10 should match 10 keys, got %d", len(m.entries))
+	}
+}
+
+func TestEdit(t *testing.T) {
+	f := newFakeDB()
+	m := newTestModel(t, f)
+
+	typeText(m, "e")
+	typeKeys(m, Key{Code: KeyCtrlU})
+	typeText(m, `{"id":1}`)
+	typeKeys(m, Key{Code: KeyEnter})
+	if f.data["users"]["user:001"] != `{"id":1}` || m.status != "Saved user:001" {
+		t.Errorf("e should put the edited value, got %q (%s)", f.data["users"]["user:001"], m.status)
+	}
+
+	typeText(m, "anew")
+	typeKeys(m, Key{Code: KeyEnter})
+	typeText(m, "v")
+	typeKeys(m, Key{Code: KeyEnter})
+	if f.data["users"]["new"] != "v" {
+		t.Errorf("a should put a new key, got %v", f.data["users"]["new"])
+	}
+
+	typeText(m, "e")
+	typeKeys(m, Key{Code: KeyEsc})
+	if m.prompt != nil || m.status != "Cancelled" {
+		t.Errorf("Esc should cancel the edit")
+	}
+
+	f.readOnly = true
+	typeText(m, "e")
+	if m.prompt != nil || !strings.Contains(screen(m), "Read-only mode") {
+		t.Errorf("editing should be disabled in read-only mode:\n%s", screen(m))
+	}
+	if out := screen(m); !strings.Contains(out, "[read-only]") {
+		t.Errorf("the header should show read-only mode:\n%s", out)
+	}
+}
+
+func TestRawEntries(t *testing.T) {
+	f := newFakeDB()
+	keys := []string{"user:1", "\x00\x00\x00\x00\x00\x00\x00\x00", "0 (0x0)", "\xff\x00\x01", "12345678"}
+	for _, key := range keys {
+		f.data["default"][key] = "v"
+	}
+	page, _ := f.ScanCFPage("default", nil, nil, db.ScanOptions{Limit: pageSize})
+
+	entries := rawEntries(page)
+	if len(entries) != len(page.ResultsV2) {
+		t.Fatalf("expected %d entries, got %d", len(page.ResultsV2), len(entries))
+	}
+	for i, e := range entries {
+		if e.key != f.sortedKeys("default")[i] || e.display != page.ResultsV2[i].Key {
+			t.Errorf("entry %d = %q shown as %q, want %q", i, e.key, e.display, f.sortedKeys("default")[i])
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Package tui is a full-screen terminal browser of a database.
+//
+// The screen shows the column families, the keys of the current column
+// family and the value of the selected key. Keys are loaded a page at a time
+// with ScanCFPage as the selection moves down, so large column families open
+// at once. A search bar filters the keys with SearchCF as you type. The
+// search and the stats of the column family run in the background, and
+// values can be edited unless the database is read-only.
+package tui
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"rocksdb-cli/internal/db"
+)
+
+// Options configures the browser
+type Options struct {
+	// CF is the column family shown first, the first one in name order when empty
+	CF string
+	// StatsInterval is how often the stats of the column family are
+	// computed again; 0 computes them only on start, on a column family
+	// change and with s
+	StatsInterval time.Duration
+}
+
+// statsResult is the stats of a column family computed in the background
+type statsResult struct {
+	cf    string
+	stats *db.CFStats
+	err   error
+}
+
+// resizeInterval is how often the size of the terminal is checked
+const resizeInterval = 250 * time.Millisecond
+
+// errStopped stops a background read when the browser quits
+var errStopped = errors.New("browser stopped")
+
+// stopOn returns a ProgressFunc that stops a read once stop is closed
+func stopOn(stop <-chan struct{}) db.ProgressFunc {
+	return func(int64, string) error {
+		select {
+		case <-stop:
+			return errStopped
+		default:
+			return nil
+		}
+	}
+}
+
+// Run shows the browser on the terminal until q is pressed
+func Run(database db.KeyValueDB, opts Options) error {
+	in, out := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+	if !term.IsTerminal(in) || !term.IsTerminal(out) {
+		return errors.New("tui needs a terminal")
+	}
+	width, height, err := term.GetSize(out)
+	if err != nil {
+		return err
+	}
+	m, err := newModel(database, opts.CF, width, height)
+	if err != nil {
+		return err
+	}
+
+	state, err := term.MakeRaw(in)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(in, state)
+	// Use the alternate screen and hide the cursor while browsing
+	os.Stdout.WriteString("\x1b[?1049h\x1b[?25l")
+	defer os.Stdout.WriteString("\x1b[?25h\x1b[?1049l")
+
+	keys := make(chan []Key)
+	go readKeys(keys)
+
+	stats := make(chan statsResult, 1)
+	computing := false
+	stop := make(chan struct{}) // closed on quit to stop the background reads
+	searches := make(chan searchResult, 1)
+	var statsTick <-chan time.Time
+	if opts.StatsInterval > 0 {
+		ticker := time.NewTicker(opts.StatsInterval)
+		defer ticker.Stop()
+		statsTick = ticker.C
+	}
+	resize := time.NewTicker(resizeInterval)
+	defer resize.Stop()
+
+	for !m.quit {
+		if m.refreshStats && !computing {
+			m.refreshStats, computing = false, true
+			go func(cf string) {
+				s, err := database.GetCFStatsWithOptions(cf, db.StatsOptions{Progress: stopOn(stop)})
+				stats <- statsResult{cf: cf, stats: s, err: err}
+			}(m.currentCF())
+		}
+		if p := m.startSearch(); p != nil {
+			go func() { searches <- runSearch(database, p) }()
+		}
+		draw(m.view())
+
+		select {
+		case pressed, ok := <-keys:
+			if !ok {
+				m.quit = true
+			}
+			for _, key := range pressed {
+				m.update(key)
+			}
+		case r := <-stats:
+			computing = false
+			m.setStats(r.cf, r.stats, r.err)
+		case r := <-searches:
+			m.setSearch(r)
+		case <-statsTick:
+			m.refreshStats = true
+		case <-resize.C:
+			if w, h, err := term.GetSize(out); err == nil && (w != m.width || h != m.height) {
+				os.Stdout.WriteString("\x1b[2J")
+				m.resize(w, h)
+			}
+		}
+	}
+
+	// The caller closes database once Run returns, so the background reads
+	// are stopped and waited for
+	close(stop)
+	m.cancelSearch()
+	if computing {
+		<-stats
+	}
+	if m.searchRunning != nil {
+		<-searches
+	}
+	return nil
+}
+
+// readKeys sends the keys read from stdin until it is closed
+func readKeys(keys chan<- []Key) {
+	buf := make([]byte, 256)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if n > 0 {
+			keys <- decodeKeys(buf[:n])
+		}
+		if err != nil {
+			close(keys)
+			return
+		}
+	}
+}
+
+// draw writes the screen from the top left corner, clearing the rest of
+// each line
+func draw(lines []string) {
+	var buf bytes.Buffer
+	buf.WriteString("\x1b[H")
+	buf.WriteString(strings.Join(lines, "\x1b[K\r\n"))
+	buf.WriteString("\x1b[K")
+	os.Stdout.Write(buf.Bytes())
+}
@@ -0,0 +1,252 @@
+package tui
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+
+	"rocksdb-cli/internal/jsonutil"
+	"rocksdb-cli/internal/util"
+)
+
+// ANSI styles
+const (
+	styleReverse = "\x1b[7m"
+	styleBold    = "\x1b[1m"
+	styleDim     = "\x1b[2m"
+	styleReset   = "\x1b[0m"
+)
+
+const helpLine = "↑↓ move  Tab pane  / search keys  ? search values  x hex  e edit  a add  s stats  r reload  q quit"
+
+// view returns the lines of the screen, each as wide as the screen
+func (m *model) view() []string {
+	lines := make([]string, 0, m.height)
+	if m.width < 40 || m.height < 8 {
+		lines = append(lines, fit("Terminal too small", m.width))
+		for len(lines) < m.height {
+			lines = append(lines, fit("", m.width))
+		}
+		return lines
+	}
+
+	header := " rocksdb-cli  " + m.currentCF()
+	if m.db.IsReadOnly() {
+		header += "  [read-only]"
+	}
+	lines = append(lines, styled(styleReverse, fit(header, m.width)))
+
+	cfWidth, keyWidth, valueWidth := m.columns()
+	cfs := m.cfView(cfWidth)
+	keys := m.keyView(keyWidth)
+	values := m.valueView(valueWidth)
+	for i := range cfs {
+		lines = append(lines, cfs[i]+"│"+keys[i]+"│"+values[i])
+	}
+
+	lines = append(lines, styled(styleDim, fit(m.statsLine(), m.width)))
+	lines = append(lines, m.bottomLine())
+	return lines
+}
+
+// columns returns the widths of the panes, which are separated by one column
+func (m *model) columns() (cfWidth, keyWidth, valueWidth int) {
+	cfWidth = max(min(m.width/6, 24), 10)
+	rest := m.width - cfWidth - 2
+	keyWidth = rest * 2 / 5
+	return cfWidth, keyWidth, rest - keyWidth
+}
+
+func (m *model) valueWidth() int {
+	_, _, width := m.columns()
+	return width
+}
+
+// paneView returns the title and rows of a pane, listHeight rows high
+func (m *model) paneView(p pane, title string, width int, rows []string) []string {
+	style := styleBold
+	if m.focus == p {
+		style = styleReverse
+	}
+	lines := []string{styled(style, fit(" "+title, width))}
+	for i := 0; i < m.listHeight(); i++ {
+		row := ""
+		if i < len(rows) {
+			row = rows[i]
+		}
+		lines = append(lines, row)
+	}
+	return lines
+}
+
+// row formats a row of a list, highlighted when selected
+func (m *model) row(p pane, text string, width int, selected bool) string {
+	text = fit(" "+oneLine(text), width)
+	switch {
+	case selected && m.focus == p:
+		return styled(styleReverse, text)
+	case selected:
+		return styled(styleBold, text)
+	}
+	return text
+}
+
+func (m *model) cfView(width int) []string {
+	top := max(m.cf-m.listHeight()+1, 0)
+	var rows []string
+	for i := top; i < len(m.cfs); i++ {
+		rows = append(rows, m.row(cfPane, m.cfs[i], width, i == m.cf))
+	}
+	return m.fill(m.paneView(cfPane, "Column families", width, rows), width)
+}
+
+func (m *model) keyView(width int) []string {
+	more := ""
+	if m.hasMore {
+		more = "+"
+	}
+	title := fmt.Sprintf("Keys (%d%s)", len(m.entries), more)
+	if len(m.search) > 0 {
+		field := "key"
+		if m.searchValue {
+			field = "value"
+		}
+		title = fmt.Sprintf("Keys with %s matching %q (%d%s)", field, string(m.search), len(m.entries), more)
+		if m.searchPending() {
+			title += " searching"
+			if p := m.searchRunning; p != nil && p.id == m.searchID && p.visited.Load() > 0 {
+				title += fmt.Sprintf(", %d keys visited", p.visited.Load())
+			}
+		}
+	}
+
+	var rows []string
+	switch {
+	case len(m.entries) == 0 && m.searchPending():
+		rows = append(rows, fit(" (searching, Esc cancels)", width))
+	case len(m.entries) == 0:
+		rows = append(rows, fit(" (no keys)", width))
+	}
+	for i := m.top; i < len(m.entries) && i < m.top+m.listHeight(); i++ {
+		rows = append(rows, m.row(keyPane, m.entries[i].display, width, i == m.selected))
+	}
+	return m.fill(m.paneView(keyPane, title, width, rows), width)
+}
+
+func (m *model) valueView(width int) []string {
+	title := "Value"
+	if m.hex {
+		title = "Value (hex)"
+	}
+	lines := m.valueLines(width)
+	var rows []string
+	for i := m.valueTop; i < len(lines); i++ {
+		rows = append(rows, fit(lines[i], width))
+	}
+	return m.fill(m.paneView(valuePane, title, width, rows), width)
+}
+
+// fill pads the empty rows of a pane to its width
+func (m *model) fill(lines []string, width int) []string {
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = strings.Repeat(" ", width)
+		}
+	}
+	return lines
+}
+
+// valueLines returns the value of the selected key as shown: JSON pretty
+// printed with nested JSON expanded, binary values as a hex dump, long lines
+// wrapped to width
+func (m *model) valueLines(width int) []string {
+	if _, ok := m.selectedEntry(); !ok {
+		return nil
+	}
+	if m.valueErr != nil {
+		return []string{"Error: " + m.valueErr.Error()}
+	}
+
+	var text string
+	if m.hex || !util.IsPrintable([]byte(m.value)) {
+		text = hex.Dump([]byte(m.value))
+	} else {
+		text = jsonutil.PrettyPrintWithNestedExpansion(m.value)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		for runewidth.StringWidth(line) > width {
+			head := runewidth.Truncate(line, width, "")
+			if head == "" {
+				break
+			}
+			lines = append(lines, head)
+			line = line[len(head):]
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (m *model) statsLine() string {
+	switch {
+	case m.statsErr != nil:
+		return " Stats failed: " + m.statsErr.Error()
+	case m.stats == nil:
+		return " Computing stats..."
+	}
+	s := m.stats
+	return fmt.Sprintf(" %d keys  keys %s  values %s  avg value %s  updated %s",
+		s.KeyCount, formatBytes(s.TotalKeySize), formatBytes(s.TotalValueSize),
+		formatBytes(int64(s.AverageValueSize)), s.LastUpdated.Format("15:04:05"))
+}
+
+// bottomLine is the prompt, the search bar, a message or the keys
+func (m *model) bottomLine() string {
+	switch {
+	case m.prompt != nil:
+		return fit(m.prompt.label+oneLine(string(m.prompt.text))+"█", m.width)
+	case m.searching:
+		bar := "/"
+		if m.searchValue {
+			bar = "?"
+		}
+		return fit(bar+string(m.search)+"█", m.width)
+	case m.status != "":
+		return fit(" "+m.status, m.width)
+	case len(m.search) > 0:
+		return fit(" Esc clears the search  "+helpLine, m.width)
+	}
+	return fit(" "+helpLine, m.width)
+}
+
+// fit cuts or pads s to width columns
+func fit(s string, width int) string {
+	s = runewidth.Truncate(s, width, "")
+	return s + strings.Repeat(" ", width-runewidth.StringWidth(s))
+}
+
+// oneLine keeps a key or value on one row
+func oneLine(s string) string {
+	return strings.NewReplacer("\t", " ", "\r", " ", "\n", " ").Replace(s)
+}
+
+func styled(style, s string) string {
+	return style + s + styleReset
+}
+
+// formatBytes formats byte counts with appropriate units
+func formatBytes(bytes int64) string {
+	if bytes < 1024 {
+		return fmt.Sprintf("%d B", bytes)
+	} else if bytes < 1024*1024 {
+		return fmt.Sprintf("%.1f KB", float64(bytes)/1024)
+	} else if bytes < 1024*1024*1024 {
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1024*1024))
+	}
+	return fmt.Sprintf("%.1f GB", float64(bytes)/(1024*1024*1024))
+}
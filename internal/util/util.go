@@ -1,6 +1,7 @@
 package util
 
 import (
+	"encoding/base64"
 	"encoding/binary"
 	"fmt"
 	"strconv"
@@ -327,6 +328,30 @@ func FromHexString(hexStr string) ([]byte, error) {
 	return result, nil
 }
 
+// DecodeInput decodes a value typed on the command line in encoding: "hex"
+// (spaces and a 0x prefix allowed), "base64" (standard or URL alphabet, padded
+// or not) or "" for the value as is
+func DecodeInput(value, encoding string) (string, error) {
+	switch encoding {
+	case "":
+		return value, nil
+	case "hex":
+		data, err := FromHexString(strings.TrimPrefix(strings.TrimPrefix(value, "0x"), "0X"))
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	case "base64":
+		for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+			if data, err := enc.DecodeString(value); err == nil {
+				return string(data), nil
+			}
+		}
+		return "", fmt.Errorf("invalid base64 string")
+	}
+	return "", fmt.Errorf("unknown encoding '%s', use hex or base64", encoding)
+}
+
 // ConvertStringToKeyForScan converts string inputs for scan operations, handling prefixes and ranges
 func ConvertStringToKeyForScan(input string, format KeyFormat, isPrefix bool) ([]byte, error) {
 	if input == "" || input == "*" {
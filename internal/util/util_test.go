@@ -280,6 +280,38 @@ func TestFormatKey(t *testing.T) {
 	}
 }
 
+func TestDecodeInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		encoding string
+		expected string
+		wantErr  bool
+	}{
+		{"plain", "hello", "", "hello", false},
+		{"hex", "00ff10", "hex", "\x00\xff\x10", false},
+		{"hex with prefix and spaces", "0x00 ff 10", "hex", "\x00\xff\x10", false},
+		{"odd hex", "0ff", "hex", "", true},
+		{"base64", "AP8Q", "base64", "\x00\xff\x10", false},
+		{"base64 unpadded", "aGk", "base64", "hi", false},
+		{"base64 url", "_-8", "base64", "\xff\xef", false},
+		{"bad base64", "a*b", "base64", "", true},
+		{"unknown encoding", "x", "rot13", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := DecodeInput(tt.value, tt.encoding)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeInput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("DecodeInput() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
 // Helper functions for tests
 func uint64ToBytes(val uint64) []byte {
 	buf := make([]byte, 8)
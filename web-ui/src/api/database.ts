@@ -9,6 +9,8 @@ import type {
   SearchResponse,
   DatabaseStats,
   Stats,
+  KeyTreeNode,
+  KeyTreeOptions,
 } from '@/types/api';
 
 // List all column families
@@ -66,6 +68,11 @@ export const getColumnFamilyStats = (cf: string) => {
   return apiRequest<{ cf: string; stats: Stats }>(client.get(`/cf/${cf}/stats`));
 };
 
+// Get the key prefix tree of a column family
+export const getKeyTree = (cf: string, options: KeyTreeOptions = {}) => {
+  return apiRequest<{ cf: string; tree: KeyTreeNode }>(client.get(`/cf/${cf}/keytree`, { params: options }));
+};
+
 // Health check (doesn't use standard API response format)
 export const healthCheck = async () => {
   const response = await client.get('/health');
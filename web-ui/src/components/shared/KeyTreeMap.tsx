@@ -0,0 +1,259 @@
+import { useEffect, useState } from 'react';
+import { getKeyTree } from '@/api/database';
+import type { KeyTreeNode, KeyTreeOptions } from '@/types/api';
+
+interface KeyTreeMapProps {
+  isOpen: boolean;
+  onClose: () => void;
+  columnFamily: string;
+}
+
+type SizeBy = 'keys' | 'bytes' | 'disk';
+
+interface Rect {
+  node: KeyTreeNode;
+  x: number; // percentages of the map
+  y: number;
+  w: number;
+  h: number;
+  level: number;
+}
+
+// Levels of the tree drawn at once; deeper ones are reached by clicking
+const MAP_DEPTH = 2;
+
+const COLORS = [
+  'bg-blue-200 border-blue-400',
+  'bg-green-200 border-green-400',
+  'bg-yellow-200 border-yellow-400',
+  'bg-purple-200 border-purple-400',
+  'bg-pink-200 border-pink-400',
+  'bg-indigo-200 border-indigo-400',
+  'bg-teal-200 border-teal-400',
+  'bg-orange-200 border-orange-400',
+];
+
+const formatBytes = (bytes: number) => {
+  if (bytes < 1024) return `${bytes} B`;
+  const units = ['KB', 'MB', 'GB', 'TB'];
+  let value = bytes / 1024;
+  let unit = 0;
+  while (value >= 1024 && unit < units.length - 1) {
+    value /= 1024;
+    unit++;
+  }
+  return `${value.toFixed(1)} ${units[unit]}`;
+};
+
+const weight = (node: KeyTreeNode, sizeBy: SizeBy) => {
+  switch (sizeBy) {
+    case 'bytes':
+      return node.key_bytes + node.value_bytes;
+    case 'disk':
+      return node.approximate_size || node.key_bytes + node.value_bytes;
+    default:
+      return node.keys;
+  }
+};
+
+// Slice-and-dice layout: children split the rectangle of their parent in
+// proportion to their weight, across at even levels and down at odd ones
+const layout = (node: KeyTreeNode, sizeBy: SizeBy, x: number, y: number, w: number, h: number, level: number, out: Rect[]) => {
+  const children = (node.children || []).filter((c) => weight(c, sizeBy) > 0);
+  const total = children.reduce((sum, c) => sum + weight(c, sizeBy), 0);
+  if (total === 0 || level > MAP_DEPTH) return;
+
+  let offset = 0;
+  for (const child of children) {
+    const share = weight(child, sizeBy) / total;
+    const rect = level % 2 === 1
+      ? { node: child, x: x + offset * w, y, w: share * w, h, level }
+      : { node: child, x, y: y + offset * h, w, h: share * h, level };
+    out.push(rect);
+    offset += share;
+    if (!child.other) {
+      // Leave room for the label of the parent
+      layout(child, sizeBy, rect.x, rect.y + Math.min(4, rect.h / 4), rect.w, rect.h - Math.min(4, rect.h / 4), level + 1, out);
+    }
+  }
+};
+
+export default function KeyTreeMap({ isOpen, onClose, columnFamily }: KeyTreeMapProps) {
+  const [tree, setTree] = useState<KeyTreeNode | null>(null);
+  const [path, setPath] = useState<KeyTreeNode[]>([]); // breadcrumb of the prefixes drilled into
+  const [sizeBy, setSizeBy] = useState<SizeBy>('keys');
+  const [delimiters, setDelimiters] = useState('');
+  const [width, setWidth] = useState('');
+  const [loading, setLoading] = useState(false);
+  const [error, setError] = useState('');
+
+  const load = async (node: KeyTreeNode | null) => {
+    setError('');
+    setLoading(true);
+    try {
+      const options: KeyTreeOptions = { depth: MAP_DEPTH };
+      if (node) {
+        options.prefix = node.prefix;
+        if (node.binary) options.encoding = 'hex';
+      }
+      if (width.trim()) {
+        options.width = parseInt(width.trim(), 10);
+      } else if (delimiters) {
+        options.delimiters = delimiters;
+      }
+      const result = await getKeyTree(columnFamily, options);
+      setTree(result.tree);
+    } catch (err: any) {
+      const errorMsg = err.response?.data?.error || err.message || 'Failed to load key tree';
+      setError(errorMsg);
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  useEffect(() => {
+    if (isOpen && columnFamily) {
+      setPath([]);
+      load(null);
+    }
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [isOpen, columnFamily]);
+
+  const drillDown = (node: KeyTreeNode) => {
+    if (node.other || !node.children?.length) return;
+    setPath([...path, node]);
+    load(node);
+  };
+
+  const goTo = (index: number) => {
+    const next = path.slice(0, index);
+    setPath(next);
+    load(next.length > 0 ? next[next.length - 1] : null);
+  };
+
+  if (!isOpen) return null;
+
+  const rects: Rect[] = [];
+  if (tree) layout(tree, sizeBy, 0, 0, 100, 100, 1, rects);
+
+  return (
+    <div className="fixed inset-0 bg-gray-900 bg-opacity-50 flex items-center justify-center p-4 z-50">
+      <div className="bg-white rounded-lg shadow-xl p-6 max-w-5xl w-full">
+        <div className="flex items-center justify-between mb-4">
+          <h2 className="text-2xl font-bold text-gray-900">Key Space: {columnFamily}</h2>
+          <button
+            onClick={onClose}
+            className="text-gray-400 hover:text-gray-600 transition-colors"
+          >
+            <svg className="w-6 h-6" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+              <path strokeLinecap="round" strokeLinejoin="round" strokeWidth={2} d="M6 18L18 6M6 6l12 12" />
+            </svg>
+          </button>
+        </div>
+
+        <div className="flex flex-wrap items-end gap-3 mb-4">
+          <div>
+            <label className="block text-xs font-medium text-gray-700 mb-1">Size by</label>
+            <select
+              value={sizeBy}
+              onChange={(e) => setSizeBy(e.target.value as SizeBy)}
+              className="px-3 py-2 border border-gray-300 rounded-lg text-sm focus:outline-none focus:ring-2 focus:ring-blue-500"
+            >
+              <option value="keys">Keys</option>
+              <option value="bytes">Key + value bytes</option>
+              <option value="disk">Size on disk</option>
+            </select>
+          </div>
+          <div>
+            <label className="block text-xs font-medium text-gray-700 mb-1">Delimiters</label>
+            <input
+              type="text"
+              value={delimiters}
+              onChange={(e) => setDelimiters(e.target.value)}
+              placeholder=":/|"
+              className="w-24 px-3 py-2 border border-gray-300 rounded-lg text-sm font-mono focus:outline-none focus:ring-2 focus:ring-blue-500"
+            />
+          </div>
+          <div>
+            <label className="block text-xs font-medium text-gray-700 mb-1">Or byte width</label>
+            <input
+              type="number"
+              min={0}
+              value={width}
+              onChange={(e) => setWidth(e.target.value)}
+              placeholder="0"
+              className="w-24 px-3 py-2 border border-gray-300 rounded-lg text-sm focus:outline-none focus:ring-2 focus:ring-blue-500"
+            />
+          </div>
+          <button
+            onClick={() => goTo(0)}
+            disabled={loading}
+            className="px-4 py-2 bg-blue-600 text-white text-sm font-medium rounded-lg hover:bg-blue-700 disabled:opacity-50 disabled:cursor-not-allowed transition-colors"
+          >
+            {loading ? 'Loading...' : 'Apply'}
+          </button>
+        </div>
+
+        {/* Breadcrumb */}
+        <div className="flex flex-wrap items-center gap-1 mb-3 text-sm">
+          <button onClick={() => goTo(0)} className="text-blue-600 hover:text-blue-800 font-medium">
+            {columnFamily}
+          </button>
+          {path.map((node, i) => (
+            <span key={node.prefix} className="flex items-center gap-1">
+              <span className="text-gray-400">/</span>
+              <button onClick={() => goTo(i + 1)} className="text-blue-600 hover:text-blue-800 font-mono">
+                {node.label}
+              </button>
+            </span>
+          ))}
+          {tree && (
+            <span className="ml-auto text-gray-500">
+              {tree.keys.toLocaleString()} keys · {formatBytes(tree.key_bytes + tree.value_bytes)}
+              {tree.approximate_size > 0 && ` · ~${formatBytes(tree.approximate_size)} on disk`}
+            </span>
+          )}
+        </div>
+
+        {error && (
+          <div className="mb-4 p-3 bg-red-50 border border-red-200 rounded-lg text-red-700 text-sm">
+            {error}
+          </div>
+        )}
+
+        <div className="relative w-full h-[28rem] bg-gray-50 border border-gray-200 rounded-lg overflow-hidden">
+          {tree && rects.length === 0 && !loading && (
+            <div className="absolute inset-0 flex items-center justify-center text-sm text-gray-500">
+              No prefixes below this level
+            </div>
+          )}
+          {rects.map((rect, i) => {
+            const color = rect.node.other ? 'bg-gray-200 border-gray-400' : COLORS[i % COLORS.length];
+            const clickable = !rect.node.other && !!rect.node.children?.length;
+            return (
+              <div
+                key={`${rect.level}-${rect.node.prefix}-${i}`}
+                onClick={(e) => {
+                  e.stopPropagation();
+                  drillDown(rect.node);
+                }}
+                title={`${rect.node.other ? '(other)' : rect.node.prefix}\n${rect.node.keys.toLocaleString()} keys\n${formatBytes(rect.node.key_bytes + rect.node.value_bytes)}`}
+                className={`absolute border overflow-hidden ${color} ${rect.level > 1 ? 'bg-opacity-60' : ''} ${clickable ? 'cursor-pointer hover:brightness-95' : ''}`}
+                style={{ left: `${rect.x}%`, top: `${rect.y}%`, width: `${rect.w}%`, height: `${rect.h}%` }}
+              >
+                {rect.level === 1 && (
+                  <div className="px-1 text-xs font-mono text-gray-800 truncate">
+                    {rect.node.other ? '(other)' : rect.node.label} · {rect.node.keys.toLocaleString()}
+                  </div>
+                )}
+              </div>
+            );
+          })}
+        </div>
+        <p className="mt-2 text-xs text-gray-500">
+          Click a prefix to drill down; keys with no further delimiter are counted in their parent.
+        </p>
+      </div>
+    </div>
+  );
+}
@@ -12,6 +12,7 @@ import { ToastContainer } from '@/components/shared/Toast';
 import { useToast } from '@/hooks/useToast';
 import { dbHistory, type FavoriteDatabase } from '@/utils/dbHistory';
 import TimeTicksConverter from '@/components/shared/TimeTicksConverter';
+import KeyTreeMap from '@/components/shared/KeyTreeMap';
 
 export default function Dashboard() {
   const {currentCF, columnFamilies, currentDatabase, setCurrentCF, setColumnFamilies, setCurrentDatabase, disconnect} = useDbStore();
@@ -28,6 +29,7 @@ export default function Dashboard() {
   const [lastSearchParams, setLastSearchParams] = useState<SearchRequest | null>(null);
   const [showToolsMenu, setShowToolsMenu] = useState(false);
   const [showTicksConverter, setShowTicksConverter] = useState(false);
+  const [showKeyTree, setShowKeyTree] = useState(false);
   
   // Toast notifications
   const { toasts, showError, closeToast } = useToast();
@@ -455,6 +457,20 @@ export default function Dashboard() {
                       <div className="text-xs text-gray-500">DateTime ↔ .NET Ticks</div>
                     </div>
                   </button>
+                  <button
+                    onClick={() => {
+                      setShowKeyTree(true);
+                      setShowToolsMenu(false);
+                    }}
+                    disabled={!currentCF}
+                    className="w-full text-left px-4 py-2 text-sm text-gray-700 hover:bg-gray-50 transition-colors flex items-center gap-3 disabled:opacity-50 disabled:cursor-not-allowed"
+                  >
+                    <span className="text-lg">🗂️</span>
+                    <div>
+                      <div className="font-medium">Key Space</div>
+                      <div className="text-xs text-gray-500">Treemap of key prefixes</div>
+                    </div>
+                  </button>
                   {/* Future tools can be added here */}
                 </div>
               )}
@@ -979,6 +995,15 @@ export default function Dashboard() {
         isOpen={showTicksConverter}
         onClose={() => setShowTicksConverter(false)}
       />
+
+      {/* Key Space Treemap */}
+      {currentCF && (
+        <KeyTreeMap
+          isOpen={showKeyTree}
+          onClose={() => setShowKeyTree(false)}
+          columnFamily={currentCF}
+        />
+      )}
     </div>
     </>
   );
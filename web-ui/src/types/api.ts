@@ -93,6 +93,28 @@ export interface Stats {
   sample_keys: string[];
 }
 
+// Key prefix tree from /cf/:cf/keytree
+export interface KeyTreeNode {
+  prefix: string; // hex when binary
+  label: string; // part of the prefix added to the parent's
+  keys: number;
+  key_bytes: number;
+  value_bytes: number;
+  approximate_size: number; // bytes on disk estimated by RocksDB, 0 when unknown
+  other?: boolean; // counts the children past max_children
+  binary?: boolean;
+  children?: KeyTreeNode[];
+}
+
+export interface KeyTreeOptions {
+  prefix?: string;
+  encoding?: 'hex' | 'base64'; // of prefix
+  delimiters?: string;
+  width?: number;
+  depth?: number;
+  max_children?: number;
+}
+
 export interface DatabaseStats {
   column_families: Stats[];
   total_key_count: number;